@@ -21,13 +21,20 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/cmd/agent/common/signals"
 	"github.com/DataDog/datadog-agent/cmd/agent/gui"
+	aggregatordiagnostic "github.com/DataDog/datadog-agent/pkg/aggregator/diagnostic"
+	apiutil "github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
 	"github.com/DataDog/datadog-agent/pkg/collector/py"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/flare"
+	logsdiagnostic "github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/status/sections"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/stream"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
@@ -37,14 +44,23 @@ func SetupHandlers(r *mux.Router) {
 	r.HandleFunc("/hostname", getHostname).Methods("GET")
 	r.HandleFunc("/flare", makeFlare).Methods("POST")
 	r.HandleFunc("/stop", stopAgent).Methods("POST")
+	r.HandleFunc("/auth-token/rotate", rotateAuthToken).Methods("POST")
 	r.HandleFunc("/status", getStatus).Methods("GET")
 	r.HandleFunc("/status/formatted", getFormattedStatus).Methods("GET")
 	r.HandleFunc("/status/health", getHealth).Methods("GET")
+	r.HandleFunc("/status/section/{section}", getSectionStatus).Methods("GET")
+	r.HandleFunc("/stream-logs", stream.Handler(logsdiagnostic.Broadcaster, logsdiagnostic.FilterKeys...)).Methods("GET")
+	r.HandleFunc("/stream-event-platform", stream.Handler(aggregatordiagnostic.Broadcaster, aggregatordiagnostic.FilterKeys...)).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusGetterHandler).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusHandler).Methods("POST")
 	r.HandleFunc("/{component}/configs", componentConfigHandler).Methods("GET")
 	r.HandleFunc("/gui/csrf-token", getCSRFToken).Methods("GET")
 	r.HandleFunc("/config-check", getConfigCheck).Methods("GET")
+	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
+	r.HandleFunc("/tagger/tags", getTaggerTags).Methods("GET")
+	r.HandleFunc("/config", getRuntimeSettings).Methods("GET")
+	r.HandleFunc("/config/{setting}", getRuntimeSetting).Methods("GET")
+	r.HandleFunc("/config/{setting}", setRuntimeSetting).Methods("POST")
 }
 
 func stopAgent(w http.ResponseWriter, r *http.Request) {
@@ -54,6 +70,18 @@ func stopAgent(w http.ResponseWriter, r *http.Request) {
 	w.Write(j)
 }
 
+func rotateAuthToken(w http.ResponseWriter, r *http.Request) {
+	if err := apiutil.RotateAuthToken(); err != nil {
+		log.Errorf("Could not rotate the authentication token: %s", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	log.Infof("Rotated the authentication token of the IPC api")
+	w.Header().Set("Content-Type", "application/json")
+	j, _ := json.Marshal("")
+	w.Write(j)
+}
+
 func getVersion(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	av, _ := version.New(version.AgentVersion, version.Commit)
@@ -164,6 +192,33 @@ func getStatus(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonStats)
 }
 
+func getSectionStatus(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["section"]
+	log.Infof("Got a request for the %s section of the status.", name)
+	s, err := sections.Get(name)
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		code := 500
+		if _, notFound := err.(*sections.SectionNotFoundError); notFound {
+			code = 404
+		}
+		log.Errorf("Error getting the %s section of the status. Error: %v", name, err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), code)
+		return
+	}
+
+	jsonStats, err := json.Marshal(s)
+	if err != nil {
+		log.Errorf("Error marshalling the %s section of the status. Error: %v, Status: %v", name, err, s)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Write(jsonStats)
+}
+
 func getFormattedStatus(w http.ResponseWriter, r *http.Request) {
 	log.Info("Got a request for the formatted status. Making formatted status.")
 	s, err := status.GetAndFormatStatus()
@@ -179,7 +234,7 @@ func getFormattedStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 func getHealth(w http.ResponseWriter, r *http.Request) {
-	h := health.GetStatus()
+	h := health.GetReady()
 
 	jsonHealth, err := json.Marshal(h)
 	if err != nil {
@@ -207,6 +262,8 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	response.ResolveWarnings = autodiscovery.GetResolveWarnings()
 	response.ConfigErrors = autodiscovery.GetConfigErrors()
 	response.Unresolved = common.AC.GetUnresolvedTemplates()
+	response.ScheduledChecks = common.AC.GetScheduledChecks()
+	response.Services = common.AC.GetConfigServices()
 
 	json, err := json.Marshal(response)
 	if err != nil {
@@ -215,3 +272,38 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 
 	w.Write(json)
 }
+
+func getTaggerList(w http.ResponseWriter, r *http.Request) {
+	response := tagger.List(r.URL.Query().Get("entity"))
+
+	jsonTags, err := json.Marshal(response)
+	if err != nil {
+		log.Errorf("Unable to marshal tagger list response: %s", err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonTags)
+}
+
+// getTaggerTags serves the tags of an entity to the other agent processes,
+// see the pkg/tagger/remote package
+func getTaggerTags(w http.ResponseWriter, r *http.Request) {
+	entity := r.URL.Query().Get("entity")
+	cardinality, err := collectors.StringToTagCardinality(r.URL.Query().Get("cardinality"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	tags, err := tagger.Tag(entity, cardinality)
+	if err != nil {
+		log.Debugf("Unable to get the tags of %s: %s", entity, err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	j, _ := json.Marshal(tags)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(j)
+}
@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+)
+
+func getRuntimeSettings(w http.ResponseWriter, r *http.Request) {
+	resp := []response.RuntimeSettingResponse{}
+	for name, setting := range settings.RuntimeSettings() {
+		value, err := setting.Get()
+		if err != nil {
+			log.Warnf("Could not get the value of the setting %s: %s", name, err)
+		}
+		resp = append(resp, response.RuntimeSettingResponse{
+			Name:        name,
+			Description: setting.Description(),
+			Value:       value,
+		})
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		return resp[i].Name < resp[j].Name
+	})
+
+	j, _ := json.Marshal(resp)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(j)
+}
+
+func getRuntimeSetting(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["setting"]
+	value, err := settings.GetRuntimeSetting(name)
+	if err != nil {
+		writeRuntimeSettingError(w, err)
+		return
+	}
+
+	j, _ := json.Marshal(map[string]interface{}{"value": value})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(j)
+}
+
+// setRuntimeSetting changes a setting to the form value `value`, the form value
+// `duration` is the optional duration after which the setting is restored.
+func setRuntimeSetting(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["setting"]
+	var duration time.Duration
+	if d := r.FormValue("duration"); d != "" {
+		var err error
+		duration, err = time.ParseDuration(d)
+		if err != nil || duration < 0 {
+			writeRuntimeSettingError(w, fmt.Errorf("invalid duration: %s", d))
+			return
+		}
+	}
+
+	log.Infof("Got a request to change the setting %s", name)
+	if err := settings.SetRuntimeSetting(name, r.FormValue("value"), duration); err != nil {
+		writeRuntimeSettingError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	j, _ := json.Marshal("")
+	w.Write(j)
+}
+
+func writeRuntimeSettingError(w http.ResponseWriter, err error) {
+	code := 400
+	if _, ok := err.(*settings.SettingNotFoundError); ok {
+		code = 404
+	}
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	http.Error(w, string(body), code)
+}
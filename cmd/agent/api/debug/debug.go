@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package debug implements the api endpoints for the `/debug` prefix.
+// This group of endpoints serves the profiles and the expvars of the agent,
+// they are authenticated like the rest of the IPC api.
+package debug
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"strconv"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultProfileDuration = 30 * time.Second
+	maxProfileDuration     = 10 * time.Minute
+)
+
+// SetupHandlers adds the specific handlers for /debug endpoints
+func SetupHandlers(r *mux.Router) {
+	r.Handle("/vars", expvar.Handler()).Methods("GET")
+	r.HandleFunc("/pprof/profile", cpuProfile).Methods("GET")
+	r.HandleFunc("/pprof/cmdline", pprof.Cmdline).Methods("GET")
+	r.HandleFunc("/pprof/symbol", pprof.Symbol).Methods("GET", "POST")
+	// the index serves the other profiles by name: heap, goroutine, block...
+	r.PathPrefix("/pprof/").HandlerFunc(pprof.Index).Methods("GET")
+}
+
+// cpuProfile replies with the CPU profile of the agent over the number of
+// seconds of the query. The connection is hijacked since the profile usually
+// takes longer than the write timeout of the server.
+func cpuProfile(w http.ResponseWriter, r *http.Request) {
+	duration, err := profileDuration(r.URL.Query().Get("seconds"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "the server does not support long requests", http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		log.Errorf("Could not start the CPU profile: %v", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Time{})
+
+	resp := &http.Response{
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Close:      true,
+	}
+	var profile bytes.Buffer
+	if err := runtimepprof.StartCPUProfile(&profile); err != nil {
+		// a profile is already running
+		resp.StatusCode = http.StatusConflict
+		profile.WriteString(fmt.Sprintf("Could not enable the CPU profiling: %v", err))
+		resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		log.Infof("Profiling the CPU for %s", duration)
+		time.Sleep(duration)
+		runtimepprof.StopCPUProfile()
+		resp.StatusCode = http.StatusOK
+		resp.Header.Set("Content-Type", "application/octet-stream")
+		resp.Header.Set("Content-Disposition", `attachment; filename="profile"`)
+	}
+	resp.ContentLength = int64(profile.Len())
+	resp.Body = ioutil.NopCloser(&profile)
+	if err := resp.Write(conn); err != nil {
+		log.Warnf("Could not send the CPU profile: %v", err)
+	}
+}
+
+// profileDuration parses the seconds of a profile, it defaults to 30 seconds
+func profileDuration(seconds string) (time.Duration, error) {
+	if seconds == "" {
+		return defaultProfileDuration, nil
+	}
+	s, err := strconv.Atoi(seconds)
+	if err != nil || s <= 0 {
+		return 0, fmt.Errorf("invalid profile duration %q, it must be a positive number of seconds", seconds)
+	}
+	duration := time.Duration(s) * time.Second
+	if duration > maxProfileDuration {
+		return 0, fmt.Errorf("the profile duration cannot exceed %s", maxProfileDuration)
+	}
+	return duration, nil
+}
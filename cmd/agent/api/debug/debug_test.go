@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package debug
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileDuration(t *testing.T) {
+	for seconds, expected := range map[string]time.Duration{
+		"":   defaultProfileDuration,
+		"1":  time.Second,
+		"90": 90 * time.Second,
+	} {
+		duration, err := profileDuration(seconds)
+		assert.Nil(t, err, seconds)
+		assert.Equal(t, expected, duration, seconds)
+	}
+	for _, seconds := range []string{"0", "-1", "30s", "3600"} {
+		_, err := profileDuration(seconds)
+		assert.NotNil(t, err, seconds)
+	}
+}
+
+func newTestServer() *httptest.Server {
+	r := mux.NewRouter()
+	SetupHandlers(r.PathPrefix("/debug").Subrouter())
+	return httptest.NewServer(r)
+}
+
+func get(t *testing.T, url string) (int, []byte) {
+	resp, err := http.Get(url)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(t, err)
+	return resp.StatusCode, body
+}
+
+func TestCPUProfile(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	code, body := get(t, server.URL+"/debug/pprof/profile?seconds=1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, body)
+
+	code, _ = get(t, server.URL+"/debug/pprof/profile?seconds=forever")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestProfilesAndVars(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	code, body := get(t, server.URL+"/debug/pprof/heap")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, body)
+
+	code, body = get(t, server.URL+"/debug/vars")
+	assert.Equal(t, http.StatusOK, code)
+	vars := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(body, &vars))
+	assert.Contains(t, vars, "memstats")
+}
@@ -15,4 +15,23 @@ type ConfigCheckResponse struct {
 	ResolveWarnings map[string][]string           `json:"resolve_warnings"`
 	ConfigErrors    map[string]string             `json:"config_errors"`
 	Unresolved      map[string]integration.Config `json:"unresolved"`
+	ScheduledChecks map[string][]string           `json:"scheduled_checks"` // the IDs of the checks scheduled, by config digest
+	Services        map[string]string             `json:"services"`         // the services of the resolved templates, by config digest
+}
+
+// TaggerListResponse holds the tagger list response
+type TaggerListResponse struct {
+	Entities map[string]TaggerListEntity `json:"entities"`
+}
+
+// TaggerListEntity holds the tags of an entity, keyed by the collector that provided them
+type TaggerListEntity struct {
+	Tags map[string][]string `json:"tags"`
+}
+
+// RuntimeSettingResponse holds a setting that can be changed at runtime
+type RuntimeSettingResponse struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Value       interface{} `json:"value"`
 }
@@ -22,6 +22,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/cmd/agent/api/agent"
 	"github.com/DataDog/datadog-agent/cmd/agent/api/check"
+	"github.com/DataDog/datadog-agent/cmd/agent/api/debug"
 	"github.com/DataDog/datadog-agent/pkg/api/security"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -37,9 +38,11 @@ func StartServer() error {
 	// create the root HTTP router
 	r := mux.NewRouter()
 
-	// IPC REST API server
-	agent.SetupHandlers(r.PathPrefix("/agent").Subrouter())
-	check.SetupHandlers(r.PathPrefix("/check").Subrouter())
+	// IPC REST API server, versioned
+	setupHandlers(r.PathPrefix(util.IPCAPIPrefix).Subrouter())
+	// Unversioned endpoints, deprecated: they are kept for JMXFetch and the
+	// clients of the previous versions
+	setupHandlers(r)
 
 	// Validate token for every request
 	r.Use(validateToken)
@@ -104,6 +107,12 @@ func ServerAddress() *net.TCPAddr {
 	return listener.Addr().(*net.TCPAddr)
 }
 
+func setupHandlers(r *mux.Router) {
+	agent.SetupHandlers(r.PathPrefix("/agent").Subrouter())
+	check.SetupHandlers(r.PathPrefix("/check").Subrouter())
+	debug.SetupHandlers(r.PathPrefix("/debug").Subrouter())
+}
+
 func validateToken(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if err := util.Validate(w, r); err != nil {
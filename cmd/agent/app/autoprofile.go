@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autoprofile"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	log "github.com/cihub/seelog"
+)
+
+// autoProfiler captures the profiles of the agent when its usage stays above
+// the thresholds, it is nil when auto_profiling isn't enabled
+var autoProfiler *autoprofile.Profiler
+
+func startAutoProfiler() {
+	if !config.Datadog.GetBool("auto_profiling.enabled") {
+		return
+	}
+	seconds := func(key string) time.Duration {
+		return time.Duration(config.Datadog.GetInt(key)) * time.Second
+	}
+	profiler, err := autoprofile.NewProfiler(autoprofile.Config{
+		Dir:                config.Datadog.GetString("auto_profiling.dir"),
+		CPUThreshold:       config.Datadog.GetFloat64("auto_profiling.cpu_threshold"),
+		RSSThreshold:       uint64(config.Datadog.GetInt("auto_profiling.memory_threshold_mb")) * 1024 * 1024,
+		Sustained:          seconds("auto_profiling.sustained_duration"),
+		Interval:           seconds("auto_profiling.check_interval"),
+		CPUProfileDuration: seconds("auto_profiling.cpu_profile_duration"),
+		Cooldown:           seconds("auto_profiling.cooldown"),
+		MaxCaptures:        config.Datadog.GetInt("auto_profiling.max_captures"),
+	})
+	if err != nil {
+		log.Errorf("Could not start the auto profiling: %s", err)
+		return
+	}
+	autoProfiler = profiler
+	autoProfiler.Start()
+}
+
+func stopAutoProfiler() {
+	if autoProfiler != nil {
+		autoProfiler.Stop()
+		autoProfiler = nil
+	}
+}
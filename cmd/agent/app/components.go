@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+)
+
+// component is an optional part of the agent, it is registered by a file
+// only built with its build tag so a slim build doesn't link it
+type component struct {
+	name  string
+	start func(agg *aggregator.BufferedAggregator) error
+	stop  func()
+}
+
+var components []component
+
+// registerComponent adds a component started by StartAgent and stopped by
+// StopAgent, in the order of the registrations. The components feeding the
+// aggregator get its channels from the aggregator passed to start.
+func registerComponent(name string, start func(agg *aggregator.BufferedAggregator) error, stop func()) {
+	components = append(components, component{name: name, start: start, stop: stop})
+}
+
+// startComponents starts the registered components, a component failing to
+// start doesn't prevent the others from starting
+func startComponents(agg *aggregator.BufferedAggregator) {
+	for _, c := range components {
+		if err := c.start(agg); err != nil {
+			log.Errorf("Could not start %s: %s", c.name, err)
+		}
+	}
+}
+
+// stopComponents stops the registered components in the reverse order
+func stopComponents() {
+	for i := len(components) - 1; i >= 0; i-- {
+		components[i].stop()
+	}
+}
@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build log
+
+package app
+
+import (
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs"
+	"github.com/DataDog/datadog-agent/pkg/memguard"
+)
+
+func init() {
+	registerComponent("logs-agent", startLogsAgent, logs.Stop)
+	// the tailers pause only at the last stage, the logs stay in the files
+	memguard.Register("logs-agent", func(stage memguard.Stage) {
+		logs.SetPaused(stage >= memguard.StageSevere)
+	})
+}
+
+func startLogsAgent(_ *aggregator.BufferedAggregator) error {
+	if !config.Datadog.GetBool("logs_enabled") {
+		log.Info("logs-agent disabled")
+		return nil
+	}
+	return logs.Start()
+}
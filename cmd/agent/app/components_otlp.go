@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build otlp
+
+package app
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/otlp"
+)
+
+// otlpReceiver receives the metrics of the OpenTelemetry SDKs, it is nil when it is disabled
+var otlpReceiver *otlp.Receiver
+
+func init() {
+	registerComponent("otlp-receiver", startOTLPReceiver, stopOTLPReceiver)
+}
+
+func startOTLPReceiver(agg *aggregator.BufferedAggregator) error {
+	if !config.Datadog.GetBool("otlp.enabled") {
+		return nil
+	}
+	metricOut, _, _ := agg.GetChannels()
+	receiver, err := otlp.NewReceiver(metricOut)
+	if err != nil {
+		return err
+	}
+	otlpReceiver = receiver
+	return nil
+}
+
+func stopOTLPReceiver() {
+	if otlpReceiver != nil {
+		otlpReceiver.Stop()
+	}
+}
@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build snmptraps
+
+package app
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/snmptraps"
+)
+
+// trapListener receives the traps of the network devices, it is nil when it is disabled
+var trapListener *snmptraps.Listener
+
+func init() {
+	registerComponent("snmp-traps", startTrapListener, stopTrapListener)
+}
+
+func startTrapListener(agg *aggregator.BufferedAggregator) error {
+	if !config.Datadog.GetBool("snmp_traps.enabled") {
+		return nil
+	}
+	_, eventOut, serviceCheckOut := agg.GetChannels()
+	listener, err := snmptraps.NewListener(eventOut, serviceCheckOut)
+	if err != nil {
+		return err
+	}
+	trapListener = listener
+	return nil
+}
+
+func stopTrapListener() {
+	if trapListener != nil {
+		trapListener.Stop()
+	}
+}
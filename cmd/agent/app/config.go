@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/validate"
+)
+
+var settingDuration time.Duration
+
+func init() {
+	AgentCmd.AddCommand(configCommand)
+	configCommand.AddCommand(configGetCommand)
+	configCommand.AddCommand(configSetCommand)
+	configCommand.AddCommand(configValidateCommand)
+
+	configSetCommand.Flags().DurationVarP(&settingDuration, "duration", "d", 0, "restore the previous value after this duration (ex: 10m), the value is kept when not set")
+}
+
+var configCommand = &cobra.Command{
+	Use:          "config",
+	Short:        "Print the settings of a running agent that can be changed at runtime",
+	Long:         ``,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupRuntimeSettingsClient(); err != nil {
+			return err
+		}
+		body, err := util.DoGet(util.GetClient(false), runtimeSettingsURL(""))
+		if err != nil {
+			return fmt.Errorf("could not get the settings: %s", runtimeSettingsError(body, err))
+		}
+		resp := []response.RuntimeSettingResponse{}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("error unmarshalling json: %s", err)
+		}
+		for _, setting := range resp {
+			fmt.Printf("%s: %v\n    %s\n", setting.Name, setting.Value, setting.Description)
+		}
+		return nil
+	},
+}
+
+var configGetCommand = &cobra.Command{
+	Use:          "get <setting>",
+	Short:        "Print the value of a setting of a running agent",
+	Long:         ``,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("exactly one setting must be specified")
+		}
+		if err := setupRuntimeSettingsClient(); err != nil {
+			return err
+		}
+		body, err := util.DoGet(util.GetClient(false), runtimeSettingsURL(args[0]))
+		if err != nil {
+			return fmt.Errorf("could not get the setting %s: %s", args[0], runtimeSettingsError(body, err))
+		}
+		resp := map[string]interface{}{}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("error unmarshalling json: %s", err)
+		}
+		fmt.Printf("%s is set to: %v\n", args[0], resp["value"])
+		return nil
+	},
+}
+
+var configSetCommand = &cobra.Command{
+	Use:          "set <setting> <value>",
+	Short:        "Change the value of a setting of a running agent, without restarting it",
+	Long:         ``,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("exactly one setting and one value must be specified")
+		}
+		if settingDuration < 0 {
+			return fmt.Errorf("the duration can not be negative")
+		}
+		if err := setupRuntimeSettingsClient(); err != nil {
+			return err
+		}
+		form := url.Values{"value": {args[1]}}
+		if settingDuration > 0 {
+			form.Set("duration", settingDuration.String())
+		}
+		body, err := util.DoPost(util.GetClient(false), runtimeSettingsURL(args[0]), "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+		if err != nil {
+			return fmt.Errorf("could not set the setting %s: %s", args[0], runtimeSettingsError(body, err))
+		}
+		if settingDuration > 0 {
+			fmt.Printf("%s is set to %s for %s\n", args[0], args[1], settingDuration)
+		} else {
+			fmt.Printf("%s is set to %s\n", args[0], args[1])
+		}
+		return nil
+	},
+}
+
+var configValidateCommand = &cobra.Command{
+	Use:          "validate",
+	Short:        "Check datadog.yaml, its fragments and the check configuration files before deploying them",
+	Long:         `Report the unknown options, the options of an invalid type and the deprecated options of datadog.yaml, of the files of datadog.d and of the files of conf.d, the command fails when an error is found.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// the file is located even when it can not be loaded, for its syntax errors to be reported
+		setupErr := common.SetupConfig(confFilePath)
+		configFile := config.Datadog.ConfigFileUsed()
+		if configFile == "" {
+			return fmt.Errorf("unable to set up global agent configuration: %v", setupErr)
+		}
+
+		fragments, err := config.Fragments(configFile)
+		if err != nil {
+			return fmt.Errorf("unable to list the fragments of %s: %s", configFile, err)
+		}
+		issues := []validate.Issue{}
+		for _, file := range append([]string{configFile}, fragments...) {
+			fileIssues, err := validate.ValidateDatadogConfig(file)
+			if err != nil {
+				return fmt.Errorf("unable to read %s: %s", file, err)
+			}
+			issues = append(issues, fileIssues...)
+		}
+		checksIssues, err := validate.ValidateChecksConfigs(config.Datadog.GetString("confd_path"))
+		if err != nil {
+			return fmt.Errorf("unable to read the check configuration files: %s", err)
+		}
+		issues = append(issues, checksIssues...)
+
+		for _, issue := range issues {
+			if issue.Warning {
+				fmt.Printf("WARNING %s\n", issue)
+			} else {
+				fmt.Printf("ERROR %s\n", issue)
+			}
+		}
+		if validate.HasErrors(issues) {
+			return fmt.Errorf("the configuration is invalid")
+		}
+		fmt.Println("The configuration is valid")
+		return nil
+	},
+}
+
+func setupRuntimeSettingsClient() error {
+	if err := common.SetupConfig(confFilePath); err != nil {
+		return fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+	return util.SetAuthToken()
+}
+
+// runtimeSettingsURL returns the URL of a setting, or of all the settings when it is empty
+func runtimeSettingsURL(setting string) string {
+	urlstr := util.IPCURL("agent/config")
+	if setting != "" {
+		urlstr += "/" + url.PathEscape(setting)
+	}
+	return urlstr
+}
+
+// runtimeSettingsError returns the error of the agent when it returned one
+func runtimeSettingsError(body []byte, err error) error {
+	errMap := map[string]string{}
+	json.Unmarshal(body, &errMap)
+	if e, found := errMap["error"]; found {
+		return fmt.Errorf("%s", e)
+	}
+	return err
+}
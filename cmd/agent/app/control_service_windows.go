@@ -36,10 +36,11 @@ var startsvcCommand = &cobra.Command{
 }
 
 var stopsvcCommand = &cobra.Command{
-	Use:   "stopservice",
-	Short: "stops the agent within the service control manager",
-	Long:  ``,
-	RunE:  stopService,
+	Use:     "stop-service",
+	Aliases: []string{"stopservice"},
+	Short:   "stops the agent within the service control manager",
+	Long:    ``,
+	RunE:    stopService,
 }
 
 var restartsvcCommand = &cobra.Command{
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"archive/zip"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+)
+
+var (
+	profileDuration time.Duration
+	profileFilePath string
+)
+
+func init() {
+	diagnoseCommand.AddCommand(profileCommand)
+	profileCommand.Flags().DurationVarP(&profileDuration, "duration", "d", 30*time.Second, "duration of the CPU profile, in whole seconds")
+	profileCommand.Flags().StringVarP(&profileFilePath, "file", "o", "", "path of the archive of the profiles, in the temporary directory by default")
+}
+
+var profileCommand = &cobra.Command{
+	Use:   "profile",
+	Short: "Capture the CPU and the memory profiles of the running agent",
+	Long: `Capture the CPU profile of the running agent over --duration, then its heap
+and goroutine profiles and its expvars, into a zip archive to send to the support.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.SetupConfig(confFilePath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+		return requestProfiles()
+	},
+}
+
+// profileEntry is a file of the archive and the IPC endpoint its content is fetched from
+type profileEntry struct {
+	name     string
+	endpoint string
+}
+
+func requestProfiles() error {
+	seconds := int(profileDuration / time.Second)
+	if seconds <= 0 {
+		return fmt.Errorf("the duration of the profile must be at least one second")
+	}
+	if profileFilePath == "" {
+		profileFilePath = filepath.Join(os.TempDir(), fmt.Sprintf("datadog-agent-profile-%s.zip", time.Now().Format("2006-01-02-15-04-05")))
+	}
+
+	// Set session token
+	err := util.SetAuthToken()
+	if err != nil {
+		return err
+	}
+
+	entries := []profileEntry{
+		{"cpu.pprof", fmt.Sprintf("pprof/profile?seconds=%d", seconds)},
+		{"heap.pprof", "pprof/heap"},
+		{"goroutine.txt", "pprof/goroutine?debug=2"},
+		{"expvar.json", "vars"},
+	}
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	fmt.Printf("Profiling the agent for %s.\n", time.Duration(seconds)*time.Second)
+	contents, err := fetchProfiles(c, entries)
+	if err != nil {
+		fmt.Printf("Could not reach agent: %v \nMake sure the agent is running before profiling it and contact support if you continue having issues. \n", err)
+		return err
+	}
+
+	if err = writeProfiles(profileFilePath, entries, contents); err != nil {
+		return fmt.Errorf("could not write the profiles to %s: %v", profileFilePath, err)
+	}
+	fmt.Printf("The profiles are written to %s\n", profileFilePath)
+	return nil
+}
+
+func fetchProfiles(c *http.Client, entries []profileEntry) ([][]byte, error) {
+	contents := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		urlstr := util.IPCURL("debug/" + entry.endpoint)
+		content, err := util.DoGet(c, urlstr)
+		if err != nil {
+			return nil, fmt.Errorf("could not get %s: %v", entry.name, err)
+		}
+		contents = append(contents, content)
+	}
+	return contents, nil
+}
+
+func writeProfiles(path string, entries []profileEntry, contents [][]byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	archive := zip.NewWriter(f)
+	for i, entry := range entries {
+		w, err := archive.Create(entry.name)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(contents[i]); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -69,7 +69,7 @@ func requestFlare(caseID string) error {
 	fmt.Fprintln(color.Output, color.BlueString("Asking the agent to build the flare archive."))
 	var e error
 	c := util.GetClient(false) // FIX: get certificates right then make this true
-	urlstr := fmt.Sprintf("https://localhost:%v/agent/flare", config.Datadog.GetInt("cmd_port"))
+	urlstr := util.IPCURL("agent/flare")
 
 	logFile := config.Datadog.GetString("log_file")
 	if logFile == "" {
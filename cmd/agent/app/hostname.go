@@ -14,8 +14,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var hostnameVerbose bool
+
 func init() {
 	AgentCmd.AddCommand(getHostnameCommand)
+	getHostnameCommand.Flags().BoolVarP(&hostnameVerbose, "verbose", "v", false, "print the value returned by every hostname provider consulted and the one used")
 }
 
 var getHostnameCommand = &cobra.Command{
@@ -32,6 +35,9 @@ func doGetHostname(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("unable to set up global agent configuration: %v", err)
 	}
+	if hostnameVerbose {
+		return printHostnameResolution()
+	}
 	hname, err := util.GetHostname()
 	if err != nil {
 		return fmt.Errorf("Error getting the hostname: %v", err)
@@ -40,3 +46,30 @@ func doGetHostname(cmd *cobra.Command, args []string) error {
 	fmt.Println(hname)
 	return nil
 }
+
+// printHostnameResolution prints the results of the hostname providers, in
+// the order they are consulted, then the hostname used
+func printHostnameResolution() error {
+	resolution, err := util.ResolveHostname()
+	fmt.Println("Hostname providers, in the order they are consulted:")
+	for _, result := range resolution.Providers {
+		switch {
+		case result.Error != "":
+			fmt.Printf("  %s: error: %s\n", result.Provider, result.Error)
+		case result.Value == "":
+			fmt.Printf("  %s: no hostname\n", result.Provider)
+		default:
+			fmt.Printf("  %s: %s\n", result.Provider, result.Value)
+		}
+	}
+	fmt.Println()
+	if err != nil {
+		return fmt.Errorf("Error getting the hostname: %v", err)
+	}
+	if resolution.Hostname == "" {
+		fmt.Printf("No hostname is used on %s\n", resolution.Provider)
+	} else {
+		fmt.Printf("Hostname: %s, provided by: %s\n", resolution.Hostname, resolution.Provider)
+	}
+	return nil
+}
@@ -23,10 +23,11 @@ func init() {
 }
 
 var instsvcCommand = &cobra.Command{
-	Use:   "installservice",
-	Short: "Installs the agent within the service control manager",
-	Long:  ``,
-	RunE:  installService,
+	Use:     "install-service",
+	Aliases: []string{"installservice"},
+	Short:   "Installs the agent within the service control manager",
+	Long:    ``,
+	RunE:    installService,
 }
 
 func installService(cmd *cobra.Command, args []string) error {
@@ -46,7 +47,11 @@ func installService(cmd *cobra.Command, args []string) error {
 		s.Close()
 		return fmt.Errorf("service %s already exists", ServiceName)
 	}
-	s, err = m.CreateService(ServiceName, exepath, mgr.Config{DisplayName: "Datadog Agent Service"})
+	s, err = m.CreateService(ServiceName, exepath, mgr.Config{
+		DisplayName: "Datadog Agent Service",
+		Description: "Send metrics, events and service checks to Datadog",
+		StartType:   mgr.StartAutomatic,
+	})
 	if err != nil {
 		return err
 	}
@@ -51,7 +51,7 @@ func launchGui(cmd *cobra.Command, args []string) error {
 
 	// Get the CSRF token from the agent
 	c := util.GetClient(false) // FIX: get certificates right then make this true
-	urlstr := fmt.Sprintf("https://localhost:%v/agent/gui/csrf-token", config.Datadog.GetInt("cmd_port"))
+	urlstr := util.IPCURL("agent/gui/csrf-token")
 	err = util.SetAuthToken()
 	if err != nil {
 		return err
@@ -10,7 +10,6 @@ import (
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
-	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/spf13/cobra"
 )
 
@@ -35,7 +34,7 @@ var listCheckCommand = &cobra.Command{
 // query for the version
 func doListChecks() error {
 	c := util.GetClient(false) // FIX: get certificates right then make this true
-	urlstr := fmt.Sprintf("https://localhost:%v/check/", config.Datadog.GetInt("cmd_port"))
+	urlstr := util.IPCURL("check/")
 
 	body, e := util.DoGet(c, urlstr)
 	if e != nil {
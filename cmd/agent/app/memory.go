@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/memguard"
+	log "github.com/cihub/seelog"
+)
+
+// ratio of the dogstatsd metrics dropped at every stage of the load shedding
+var dogstatsdDropRates = map[memguard.Stage]float64{
+	memguard.StageNormal:   0,
+	memguard.StageLight:    0.25,
+	memguard.StageModerate: 0.5,
+	memguard.StageSevere:   0.9,
+}
+
+// number of seconds after which the dogstatsd contexts are expired from the
+// moderate stage, instead of the 300 seconds of the aggregator
+const shedContextExpiry = 30
+
+// memoryGuard keeps the agent under memory_ceiling_mb, it is nil when no
+// ceiling is set
+var memoryGuard *memguard.Guard
+
+// startMemoryGuard registers the shedders of dogstatsd and the aggregator and
+// starts checking the memory of the agent against its ceiling
+func startMemoryGuard(agg *aggregator.BufferedAggregator) {
+	ceiling := config.Datadog.GetInt("memory_ceiling_mb")
+	if ceiling <= 0 {
+		return
+	}
+
+	interval := time.Duration(config.Datadog.GetInt("memory_ceiling_check_interval")) * time.Second
+	guard, err := memguard.NewGuard(uint64(ceiling)*1024*1024, interval)
+	if err != nil {
+		log.Errorf("Could not start the memory guard: %s", err)
+		return
+	}
+
+	memguard.Register("dogstatsd", func(stage memguard.Stage) {
+		if common.DSD != nil {
+			common.DSD.SetDropRate(dogstatsdDropRates[stage])
+		}
+	})
+	memguard.Register("aggregator", func(stage memguard.Stage) {
+		if stage >= memguard.StageModerate {
+			agg.SetContextExpiry(shedContextExpiry)
+		} else {
+			agg.SetContextExpiry(0)
+		}
+	})
+
+	memoryGuard = guard
+	memoryGuard.Start()
+}
+
+// stopMemoryGuard stops checking the memory of the agent
+func stopMemoryGuard() {
+	if memoryGuard != nil {
+		memoryGuard.Stop()
+		memoryGuard = nil
+	}
+}
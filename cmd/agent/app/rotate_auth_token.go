@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rotateAuthTokenCmd = &cobra.Command{
+		Use:   "rotate-auth-token",
+		Short: "Replace the authentication token of the IPC api",
+		Long: `Replace the token of the auth token file by a new one. The previous token is
+still accepted for auth_token_rotation_grace_period seconds.`,
+		RunE: rotateAuthToken,
+	}
+)
+
+func init() {
+	// attach the command to the root
+	AgentCmd.AddCommand(rotateAuthTokenCmd)
+}
+
+func rotateAuthToken(*cobra.Command, []string) error {
+	// Global Agent configuration
+	err := common.SetupConfig(confFilePath)
+	if err != nil {
+		return fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	// Set session token
+	e := util.SetAuthToken()
+	if e != nil {
+		return e
+	}
+
+	_, e = util.DoPost(c, util.IPCURL("agent/auth-token/rotate"), "application/json", bytes.NewBuffer([]byte{}))
+	if e != nil {
+		return fmt.Errorf("Error rotating the authentication token: %v", e)
+	}
+
+	fmt.Println("Authentication token successfully rotated")
+	return nil
+}
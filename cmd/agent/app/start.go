@@ -11,27 +11,32 @@ import (
 	"syscall"
 	"time"
 
-	_ "expvar" // Blank import used because this isn't directly used in this file
+	"expvar"
 	"net/http"
-	_ "net/http/pprof" // Blank import used because this isn't directly used in this file
 
 	"os"
 	"os/signal"
+	"path/filepath"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/api"
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/cmd/agent/common/signals"
 	"github.com/DataDog/datadog-agent/cmd/agent/gui"
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/api/healthprobe"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
+	"github.com/DataDog/datadog-agent/pkg/flare"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
-	"github.com/DataDog/datadog-agent/pkg/logs"
 	"github.com/DataDog/datadog-agent/pkg/metadata"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	"github.com/DataDog/datadog-agent/pkg/pidfile"
+	"github.com/DataDog/datadog-agent/pkg/remoteconfig"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/systemd"
 	"github.com/DataDog/datadog-agent/pkg/version"
 	log "github.com/cihub/seelog"
 	"github.com/spf13/cobra"
@@ -44,7 +49,7 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system"
 
 	// register metadata providers
-	_ "github.com/DataDog/datadog-agent/pkg/collector/metadata"
+	collectormetadata "github.com/DataDog/datadog-agent/pkg/collector/metadata"
 	_ "github.com/DataDog/datadog-agent/pkg/metadata"
 )
 
@@ -61,6 +66,10 @@ var (
 	// flags variables
 	runForeground bool
 	pidfilePath   string
+
+	// watchdog pings the systemd watchdog while the agent is live, it is nil
+	// when the watchdog isn't enabled
+	watchdog *systemd.Watchdog
 )
 
 // run the host metadata collector every 14400 seconds (4 hours)
@@ -72,6 +81,13 @@ const agentChecksMetadataCollectorInterval = 600
 // run the resources metadata collector every 300 seconds (5 minutes) by default, configurable
 const defaultResourcesMetadataCollectorInterval = 300
 
+// run the inventories metadata collector every 600 seconds (10 minutes) by default, configurable
+const defaultInventoriesMetadataCollectorInterval = 600
+
+// run the host tags metadata collector every 600 seconds (10 minutes) by default when
+// the EC2 tags are collected, configurable
+const defaultHostTagsMetadataCollectorInterval = 600
+
 func init() {
 	// attach the command to the root
 	AgentCmd.AddCommand(startCmd)
@@ -154,10 +170,14 @@ func StartAgent() error {
 	}
 
 	log.Infof("Starting Datadog Agent v%v", version.AgentVersion)
+	config.LogDeprecations()
 
-	// Setup expvar server
+	// Setup expvar server, the profiles are only served by the authenticated cmd HTTP server
 	var port = config.Datadog.GetString("expvar_port")
-	go http.ListenAndServe("127.0.0.1:"+port, http.DefaultServeMux)
+	expvarMux := http.NewServeMux()
+	expvarMux.Handle("/debug/vars", expvar.Handler())
+	expvarMux.Handle("/telemetry", telemetry.Handler())
+	go http.ListenAndServe("127.0.0.1:"+port, expvarMux)
 
 	if pidfilePath != "" {
 		err = pidfile.WritePID(pidfilePath)
@@ -180,11 +200,21 @@ func StartAgent() error {
 		log.Errorf("Unable to initialize host metadata: %v", err)
 	}
 
+	// register the settings that can be changed at runtime through the cmd HTTP server
+	if err = common.RegisterRuntimeSettings(); err != nil {
+		log.Errorf("Could not register the runtime settings: %v", err)
+	}
+
 	// start the cmd HTTP server
 	if err = api.StartServer(); err != nil {
 		return log.Errorf("Error while starting api server, exiting: %v", err)
 	}
 
+	// start the health probe server
+	if err = healthprobe.Serve(config.Datadog.GetInt("health_port")); err != nil {
+		return log.Errorf("Error while starting the health probe server, exiting: %v", err)
+	}
+
 	// start the GUI server
 	guiPort := config.Datadog.GetString("GUI_port")
 	if guiPort == "-1" {
@@ -218,19 +248,33 @@ func StartAgent() error {
 	}
 	log.Debugf("statsd started")
 
-	// start logs-agent
-	if config.Datadog.GetBool("logs_enabled") || config.Datadog.GetBool("log_enabled") {
-		if config.Datadog.GetBool("log_enabled") {
-			log.Warn(`"log_enabled" is deprecated, use "logs_enabled" instead`)
-		}
-		err := logs.Start()
+	// shed the load of the components when the agent gets close to its memory ceiling
+	startMemoryGuard(agg)
+	// capture the profiles of the agent when its usage spikes
+	startAutoProfiler()
+
+	// start the remote configuration once the components it configures are running
+	if config.Datadog.GetBool("remote_configuration.enabled") {
+		interval := time.Duration(config.Datadog.GetInt("remote_configuration.refresh_interval")) * time.Second
+		common.RemoteConfig, err = remoteconfig.NewPoller(common.Forwarder, config.Datadog.GetString("remote_configuration.public_key"), interval, hostname)
 		if err != nil {
-			log.Error("Could not start logs-agent: ", err)
+			log.Errorf("Could not start the remote configuration: %s", err)
+		} else {
+			policy := remoteconfig.FlarePolicy{
+				Consent:     config.Datadog.GetString("remote_configuration.flare_consent"),
+				MinInterval: time.Duration(config.Datadog.GetInt("remote_configuration.flare_min_interval")) * time.Second,
+				HandledFile: filepath.Join(config.Datadog.GetString("remote_configuration.run_path"), "remote_flare"),
+			}
+			if err := common.RemoteConfig.HandleFlares(remoteFlareSender(logFile), policy); err != nil {
+				log.Errorf("The flares requested by the remote configuration are refused: %s", err)
+			}
+			common.RemoteConfig.Start()
 		}
-	} else {
-		log.Info("logs-agent disabled")
 	}
 
+	// start the components selected by the build tags, e.g. the logs-agent
+	startComponents(agg)
+
 	// create and setup the Autoconfig instance
 	common.SetupAutoConfig(config.Datadog.GetString("confd_path"))
 	// start the autoconfig, this will immediately run any configured check
@@ -248,12 +292,42 @@ func StartAgent() error {
 
 	// start dependent services
 	startDependentServices()
+
+	// tell systemd the agent is started, and ping its watchdog until a
+	// component is wedged so the agent is restarted
+	systemd.NotifyReady()
+	watchdog, err = systemd.NewWatchdog(isLive)
+	if err != nil {
+		log.Errorf("Could not set up the systemd watchdog: %s", err)
+	} else if watchdog != nil {
+		watchdog.Start()
+	}
 	return nil
 }
 
+// isLive returns whether every component required for the liveness is healthy
+func isLive() bool {
+	return len(health.GetLive().Unhealthy) == 0
+}
+
+// remoteFlareSender returns the sender of the flares requested by the remote configuration.
+func remoteFlareSender(logFile string) remoteconfig.FlareSender {
+	return func(caseID string, email string) (string, error) {
+		filePath, err := flare.CreateArchive(false, common.GetDistPath(), common.PyChecksPath, logFile)
+		if err != nil {
+			return "", fmt.Errorf("the flare failed to be created: %s", err)
+		}
+		defer os.Remove(filePath)
+		return flare.SendFlare(filePath, caseID, email)
+	}
+}
+
 // setupMetadataCollection initializes the metadata scheduler and its collectors based on the config
 func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 	addDefaultResourcesCollector := true
+	addDefaultInventoriesCollector := true
+	addDefaultHostTagsCollector := host.CloudTagsEnabled()
+	collectormetadata.RegisterInventoriesCollector(common.AC)
 	common.MetadataScheduler = metadata.NewScheduler(s, hostname)
 	var C []config.MetadataProviders
 	err := config.Datadog.UnmarshalKey("metadata_providers", &C)
@@ -266,6 +340,12 @@ func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 			if c.Name == "resources" {
 				addDefaultResourcesCollector = false
 			}
+			if c.Name == "inventories" {
+				addDefaultInventoriesCollector = false
+			}
+			if c.Name == "host_tags" {
+				addDefaultHostTagsCollector = false
+			}
 			if c.Interval == 0 {
 				log.Infof("Interval of metadata provider '%v' set to 0, skipping provider", c.Name)
 				continue
@@ -296,12 +376,31 @@ func setupMetadataCollection(s *serializer.Serializer, hostname string) error {
 			log.Warn("Could not add resources metadata provider: ", err)
 		}
 	}
+	if addDefaultInventoriesCollector {
+		err = common.MetadataScheduler.AddCollector("inventories", defaultInventoriesMetadataCollectorInterval*time.Second)
+		if err != nil {
+			log.Warn("Could not add inventories metadata provider: ", err)
+		}
+	}
+	if addDefaultHostTagsCollector {
+		err = common.MetadataScheduler.AddCollector("host_tags", defaultHostTagsMetadataCollectorInterval*time.Second)
+		if err != nil {
+			log.Warn("Could not add host tags metadata provider: ", err)
+		}
+	}
 
 	return nil
 }
 
 // StopAgent Tears down the agent process
 func StopAgent() {
+	systemd.NotifyStopping()
+	if watchdog != nil {
+		watchdog.Stop()
+		watchdog = nil
+	}
+	stopMemoryGuard()
+	stopAutoProfiler()
 	// gracefully shut down any component
 	if common.DSD != nil {
 		common.DSD.Stop()
@@ -312,11 +411,15 @@ func StopAgent() {
 	if common.MetadataScheduler != nil {
 		common.MetadataScheduler.Stop()
 	}
+	if common.RemoteConfig != nil {
+		common.RemoteConfig.Stop()
+	}
 	api.StopServer()
+	healthprobe.Stop()
 	if common.Forwarder != nil {
 		common.Forwarder.Stop()
 	}
-	logs.Stop()
+	stopComponents()
 	gui.StopGUIServer()
 	os.Remove(pidfilePath)
 	log.Info("See ya!")
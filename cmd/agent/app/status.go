@@ -10,10 +10,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
-	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/spf13/cobra"
 )
@@ -22,6 +22,7 @@ var (
 	jsonStatus      bool
 	prettyPrintJSON bool
 	statusFilePath  string
+	statusSection   string
 )
 
 func init() {
@@ -29,6 +30,7 @@ func init() {
 	statusCmd.Flags().BoolVarP(&jsonStatus, "json", "j", false, "print out raw json")
 	statusCmd.Flags().BoolVarP(&prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
 	statusCmd.Flags().StringVarP(&statusFilePath, "file", "o", "", "Output the status command to a file")
+	statusCmd.Flags().StringVarP(&statusSection, "section", "s", "", "print out the JSON status of a section: forwarder, collector or leader-election")
 }
 
 var statusCmd = &cobra.Command{
@@ -49,11 +51,17 @@ var statusCmd = &cobra.Command{
 }
 
 func requestStatus() error {
-	fmt.Printf("Getting the status from the agent.\n\n")
+	// the JSON outputs are kept parseable
+	if !jsonStatus && !prettyPrintJSON && statusSection == "" {
+		fmt.Printf("Getting the status from the agent.\n\n")
+	}
 	var e error
 	var s string
 	c := util.GetClient(false) // FIX: get certificates right then make this true
-	urlstr := fmt.Sprintf("https://localhost:%v/agent/status", config.Datadog.GetInt("cmd_port"))
+	urlstr := util.IPCURL("agent/status")
+	if statusSection != "" {
+		urlstr += "/section/" + url.PathEscape(statusSection)
+	}
 
 	// Set session token
 	e = util.SetAuthToken()
@@ -64,10 +72,13 @@ func requestStatus() error {
 	r, e := util.DoGet(c, urlstr)
 	if e != nil {
 		var errMap = make(map[string]string)
-		json.Unmarshal(r, errMap)
+		json.Unmarshal(r, &errMap)
 		// If the error has been marshalled into a json object, check it and return it properly
 		if err, found := errMap["error"]; found {
 			e = fmt.Errorf(err)
+			if statusSection != "" {
+				return e
+			}
 		}
 
 		fmt.Printf("Could not reach agent: %v \nMake sure the agent is running before requesting the status and contact support if you continue having issues. \n", e)
@@ -75,7 +86,8 @@ func requestStatus() error {
 	}
 
 	// The rendering is done in the client so that the agent has less work to do
-	if prettyPrintJSON {
+	// The sections are printed as JSON, indented unless --json is set
+	if prettyPrintJSON || (statusSection != "" && !jsonStatus) {
 		var prettyJSON bytes.Buffer
 		json.Indent(&prettyJSON, r, "", "  ")
 		s = prettyJSON.String()
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	aggregatordiagnostic "github.com/DataDog/datadog-agent/pkg/aggregator/diagnostic"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	logsdiagnostic "github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+)
+
+var (
+	streamLogsFilter struct {
+		name    string
+		logType string
+		source  string
+		service string
+	}
+	streamEventsFilter struct {
+		check     string
+		eventType string
+	}
+)
+
+func init() {
+	AgentCmd.AddCommand(streamLogsCmd)
+	streamLogsCmd.Flags().StringVar(&streamLogsFilter.name, "name", "", "only stream the logs of the integration with this name")
+	streamLogsCmd.Flags().StringVar(&streamLogsFilter.logType, "type", "", "only stream the logs of this type of source: file, docker, tcp...")
+	streamLogsCmd.Flags().StringVar(&streamLogsFilter.source, "source", "", "only stream the logs with this source")
+	streamLogsCmd.Flags().StringVar(&streamLogsFilter.service, "service", "", "only stream the logs with this service")
+
+	AgentCmd.AddCommand(streamEventPlatformCmd)
+	streamEventPlatformCmd.Flags().StringVar(&streamEventsFilter.check, "check", "", "only stream the events and service checks of this check, dogstatsd for the ones it receives")
+	streamEventPlatformCmd.Flags().StringVar(&streamEventsFilter.eventType, "type", "", "only stream the events or the service checks: event or service_check")
+}
+
+var streamLogsCmd = &cobra.Command{
+	Use:   "stream-logs",
+	Short: "Stream the logs processed by the running agent",
+	Long: `Stream, until interrupted, the logs processed by the running agent, once
+its processing rules are applied. The logs are dropped when they are not read
+fast enough, they are still sent to Datadog.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.SetupConfig(confFilePath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+		filter := url.Values{}
+		addFilter(filter, logsdiagnostic.FilterName, streamLogsFilter.name)
+		addFilter(filter, logsdiagnostic.FilterType, streamLogsFilter.logType)
+		addFilter(filter, logsdiagnostic.FilterSource, streamLogsFilter.source)
+		addFilter(filter, logsdiagnostic.FilterService, streamLogsFilter.service)
+		return requestStream("stream-logs", filter)
+	},
+}
+
+var streamEventPlatformCmd = &cobra.Command{
+	Use:   "stream-event-platform",
+	Short: "Stream the events and service checks submitted to the running agent",
+	Long: `Stream, until interrupted, the events and the service checks submitted to
+the running agent by its checks and by dogstatsd. They are dropped when they are
+not read fast enough, they are still sent to Datadog.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.SetupConfig(confFilePath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+		filter := url.Values{}
+		addFilter(filter, aggregatordiagnostic.FilterCheck, streamEventsFilter.check)
+		addFilter(filter, aggregatordiagnostic.FilterType, streamEventsFilter.eventType)
+		return requestStream("stream-event-platform", filter)
+	},
+}
+
+func addFilter(filter url.Values, key, value string) {
+	if value != "" {
+		filter.Set(key, value)
+	}
+}
+
+// requestStream prints the lines streamed by the agent on endpoint
+func requestStream(endpoint string, filter url.Values) error {
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	urlstr := util.IPCURL("agent/" + endpoint)
+	if len(filter) > 0 {
+		urlstr += "?" + filter.Encode()
+	}
+
+	// Set session token
+	err := util.SetAuthToken()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Streaming from the agent, press Ctrl+C to stop.\n\n")
+	err = util.DoGetStream(c, urlstr, func(line []byte) {
+		fmt.Println(string(line))
+	})
+	if err != nil {
+		fmt.Printf("Could not reach agent: %v \nMake sure the agent is running before streaming and contact support if you continue having issues. \n", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+)
+
+func init() {
+	AgentCmd.AddCommand(taggerListCommand)
+}
+
+var taggerListCommand = &cobra.Command{
+	Use:   "tagger-list [entity prefix]",
+	Short: "Print the entities known to the tagger of a running agent, with their tags",
+	Long: `Print every entity stored in the tagger of a running agent, with the tags
+attached to it and the collector that provided each tag. If an entity prefix is
+given (for example "docker://"), only the matching entities are printed.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := common.SetupConfig(confFilePath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		prefix := ""
+		if len(args) > 0 {
+			prefix = args[0]
+		}
+		return getTaggerList(color.Output, prefix)
+	},
+}
+
+func getTaggerList(w io.Writer, prefix string) error {
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	urlstr := util.IPCURL("agent/tagger-list?entity=" + url.QueryEscape(prefix))
+
+	// Set session token
+	err := util.SetAuthToken()
+	if err != nil {
+		return err
+	}
+
+	r, err := util.DoGet(c, urlstr)
+	if err != nil {
+		if r != nil && string(r) != "" {
+			fmt.Fprintln(w, fmt.Sprintf("The agent ran into an error while listing the tagger entities: %s", strings.TrimSpace(string(r))))
+		} else {
+			fmt.Fprintln(w, fmt.Sprintf("Failed to query the agent (running?): %s", err))
+		}
+		return err
+	}
+
+	tr := response.TaggerListResponse{}
+	if err = json.Unmarshal(r, &tr); err != nil {
+		return fmt.Errorf("Error unmarshalling json: %s", err)
+	}
+
+	printTaggerList(w, tr)
+	return nil
+}
+
+func printTaggerList(w io.Writer, tr response.TaggerListResponse) {
+	entities := make([]string, 0, len(tr.Entities))
+	for entity := range tr.Entities {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	for _, entity := range entities {
+		fmt.Fprintln(w, fmt.Sprintf("\n=== Entity %s ===", color.GreenString(entity)))
+
+		tags := tr.Entities[entity].Tags
+		sources := make([]string, 0, len(tags))
+		for source := range tags {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+
+		for _, source := range sources {
+			sourceTags := tags[source]
+			sort.Strings(sourceTags)
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Source"), color.CyanString(source)))
+			fmt.Fprintln(w, fmt.Sprintf("%s: [%s]", color.BlueString("Tags"), strings.Join(sourceTags, " ")))
+		}
+		fmt.Fprintln(w, "===")
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/metadata"
+	"github.com/DataDog/datadog-agent/pkg/remoteconfig"
 	"github.com/DataDog/datadog-agent/pkg/util/executable"
 )
 
@@ -35,6 +36,9 @@ var (
 	// Forwarder is the global forwarder instance
 	Forwarder forwarder.Forwarder
 
+	// RemoteConfig applies the remote configuration, it is nil when it is disabled
+	RemoteConfig *remoteconfig.Poller
+
 	// utility variables
 	_here, _ = executable.Folder()
 )
@@ -6,10 +6,13 @@
 package common
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
 )
 
 // SetupConfig fires up the configuration system
@@ -31,5 +34,79 @@ func SetupConfig(confFilePath string) error {
 	if err != nil {
 		return fmt.Errorf("unable to load Datadog config file: %s", err)
 	}
+	fragments, err := config.Fragments(config.Datadog.ConfigFileUsed())
+	if err != nil {
+		return fmt.Errorf("unable to list the fragments of Datadog config file: %s", err)
+	}
+	if err := mergeFragments(fragments, false); err != nil {
+		return err
+	}
+	if err := resolveSecrets(fragments); err != nil {
+		return err
+	}
+	config.ResolveDeprecations()
+	if err := config.ApplyProfile(); err != nil {
+		return err
+	}
+	return config.SetupScrubber()
+}
+
+// mergeFragments merges the fragments of datadog.d over the configuration, in order.
+func mergeFragments(fragments []string, decrypt bool) error {
+	for _, fragment := range fragments {
+		data, err := readConfigFile(fragment, decrypt)
+		if err != nil {
+			return err
+		}
+		if err := config.Datadog.MergeConfig(bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("unable to merge Datadog config fragment %s: %s", fragment, err)
+		}
+	}
 	return nil
 }
+
+// resolveSecrets loads the configuration and its fragments again with the secrets
+// of their ENC[] handles, they are only kept in memory.
+func resolveSecrets(fragments []string) error {
+	err := secrets.Init(secrets.Config{
+		Type:          config.Datadog.GetString("secret_backend_type"),
+		Command:       config.Datadog.GetString("secret_backend_command"),
+		Arguments:     config.Datadog.GetStringSlice("secret_backend_arguments"),
+		Timeout:       config.Datadog.GetInt("secret_backend_timeout"),
+		OutputMaxSize: config.Datadog.GetInt("secret_backend_output_max_size"),
+		AWSRegion:     config.Datadog.GetString("secret_backend_aws_region"),
+		GCPKMSKey:     config.Datadog.GetString("secret_backend_gcp_kms_key"),
+		AzureVaultURL: config.Datadog.GetString("secret_backend_azure_vault_url"),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to set up the secret backend: %s", err)
+	}
+	if !secrets.IsEnabled() {
+		return nil
+	}
+
+	data, err := readConfigFile(config.Datadog.ConfigFileUsed(), true)
+	if err != nil {
+		return err
+	}
+	if err := config.Datadog.ReadConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("unable to load the decrypted Datadog config file: %s", err)
+	}
+	return mergeFragments(fragments, true)
+}
+
+// readConfigFile returns the content of a configuration file, with its secrets when decrypt is set.
+func readConfigFile(path string, decrypt bool) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read Datadog config file %s: %s", path, err)
+	}
+	if !decrypt {
+		return data, nil
+	}
+	decrypted, err := secrets.Decrypt(data, path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the secrets of Datadog config file %s: %s", path, err)
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+
+package common
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// secretBackend returns the secrets of the handles api_key and hostname
+const secretBackend = `#!/bin/sh
+cat > /dev/null
+echo '{"api_key": {"value": "decrypted_key", "error": null}, "hostname": {"value": "decrypted_host", "error": null}}'
+`
+
+// writeConfigDir writes datadog.yaml with the secret backend, and the fragments
+// of datadog.d, it returns the path of datadog.yaml
+func writeConfigDir(t *testing.T, dir string, datadogYaml string, fragments map[string]string) string {
+	backend := filepath.Join(dir, "secret_backend")
+	require.NoError(t, ioutil.WriteFile(backend, []byte(secretBackend), 0700))
+
+	configFile := filepath.Join(dir, "datadog.yaml")
+	content := fmt.Sprintf("secret_backend_command: %s\n%s", backend, datadogYaml)
+	require.NoError(t, ioutil.WriteFile(configFile, []byte(content), 0600))
+
+	fragmentsDir := filepath.Join(dir, config.FragmentsDirName)
+	require.NoError(t, os.MkdirAll(fragmentsDir, 0700))
+	for name, fragment := range fragments {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(fragmentsDir, name), []byte(fragment), 0600))
+	}
+	return configFile
+}
+
+func TestSetupConfigMergesFragments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datadog")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	configFile := writeConfigDir(t, dir, `
+api_key: ENC[api_key]
+tags: [env:prod, role:db]
+logs_config:
+  use_http: true
+  batch_wait: 10
+`, map[string]string{
+		"20-tags.yaml": `
+tags: [env:staging]
+logs_config:
+  use_http: false
+`,
+		"10-host.yml": `
+hostname: ENC[hostname]
+tags: [env:dev]
+logs_config:
+  use_compression: false
+`,
+	})
+
+	require.NoError(t, SetupConfig(configFile))
+
+	// the secrets of datadog.yaml and of the fragments are decrypted
+	assert.Equal(t, "decrypted_key", config.Datadog.GetString("api_key"))
+	assert.Equal(t, "decrypted_host", config.Datadog.GetString("hostname"))
+	// the fragments are merged in the order of their names, the lists are replaced
+	assert.Equal(t, []string{"env:staging"}, config.Datadog.GetStringSlice("tags"))
+	// the sections are merged
+	assert.False(t, config.Datadog.GetBool("logs_config.use_http"))
+	assert.False(t, config.Datadog.GetBool("logs_config.use_compression"))
+	assert.Equal(t, 10, config.Datadog.GetInt("logs_config.batch_wait"))
+}
+
+func TestSetupConfigFragmentErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datadog")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// an invalid fragment names the fragment
+	configFile := writeConfigDir(t, dir, "api_key: foo\n", map[string]string{
+		"10-invalid.yaml": "tags: [env:prod\n",
+	})
+	err = SetupConfig(configFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to merge Datadog config fragment "+filepath.Join(dir, config.FragmentsDirName, "10-invalid.yaml"))
+
+	// a secret the backend does not return names the fragment
+	require.NoError(t, os.Remove(filepath.Join(dir, config.FragmentsDirName, "10-invalid.yaml")))
+	configFile = writeConfigDir(t, dir, "api_key: foo\n", map[string]string{
+		"20-secret.yaml": "hostname: ENC[missing]\n",
+	})
+	err = SetupConfig(configFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to decrypt the secrets of Datadog config file "+filepath.Join(dir, config.FragmentsDirName, "20-secret.yaml"))
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package common
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+)
+
+// dsdStatsRuntimeSetting enables the statistics of the packets received by dogstatsd
+type dsdStatsRuntimeSetting struct{}
+
+func (s dsdStatsRuntimeSetting) Name() string {
+	return "dogstatsd_stats"
+}
+
+func (s dsdStatsRuntimeSetting) Description() string {
+	return "Enable the statistics of the packets received by dogstatsd, valid values are: true and false"
+}
+
+func (s dsdStatsRuntimeSetting) Get() (interface{}, error) {
+	if DSD == nil {
+		return false, nil
+	}
+	return DSD.StatisticsEnabled(), nil
+}
+
+func (s dsdStatsRuntimeSetting) Set(value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	if DSD == nil {
+		return errors.New("dogstatsd is not running")
+	}
+	DSD.EnableStatistics(enabled)
+	config.Datadog.Set("dogstatsd_stats_enable", enabled)
+	return nil
+}
+
+// dsdMetricBlocklistRuntimeSetting sets the names of the metrics dropped by dogstatsd
+type dsdMetricBlocklistRuntimeSetting struct{}
+
+func (s dsdMetricBlocklistRuntimeSetting) Name() string {
+	return "dogstatsd_metric_blocklist"
+}
+
+func (s dsdMetricBlocklistRuntimeSetting) Description() string {
+	return "Drop the metrics received by dogstatsd with these names, a comma separated list"
+}
+
+func (s dsdMetricBlocklistRuntimeSetting) Get() (interface{}, error) {
+	if DSD == nil {
+		return "", nil
+	}
+	return strings.Join(DSD.MetricBlocklist(), ","), nil
+}
+
+func (s dsdMetricBlocklistRuntimeSetting) Set(value string) error {
+	if DSD == nil {
+		return errors.New("dogstatsd is not running")
+	}
+	names := []string{}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	DSD.SetMetricBlocklist(names)
+	config.Datadog.Set("dogstatsd_metric_blocklist", names)
+	return nil
+}
+
+// RegisterRuntimeSettings registers the settings that can be changed while the agent is running
+func RegisterRuntimeSettings() error {
+	for _, setting := range []settings.RuntimeSetting{
+		settings.LogLevelRuntimeSetting{},
+		settings.ProfilingRuntimeSetting{},
+		dsdStatsRuntimeSetting{},
+		dsdMetricBlocklistRuntimeSetting{},
+	} {
+		if err := settings.RegisterRuntimeSetting(setting); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -73,10 +73,17 @@ func main() {
 
 type myservice struct{}
 
+// While the agent starts, the service control manager is told every
+// startCheckPointInterval that the start progresses, it considers the start
+// failed if it is not told within startWaitHint milliseconds.
+const (
+	startCheckPointInterval = 5 * time.Second
+	startWaitHint           = 15000
+)
+
 func (m *myservice) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (ssec bool, errno uint32) {
 	const cmdsAccepted = svc.AcceptStop | svc.AcceptShutdown
-	changes <- svc.Status{State: svc.StartPending}
-	changes <- svc.Status{State: svc.Running, Accepts: cmdsAccepted}
+	changes <- svc.Status{State: svc.StartPending, WaitHint: startWaitHint}
 
 	if err := common.ImportRegistryConfig(); err != nil {
 		elog.Warning(0x80000001, err.Error())
@@ -86,7 +93,13 @@ func (m *myservice) Execute(args []string, r <-chan svc.ChangeRequest, changes c
 		elog.Warning(0x80000002, err.Error())
 		// continue running with what we have.
 	}
-	app.StartAgent()
+	if err := startAgent(r, changes); err != nil {
+		// the service control manager reports the service specific exit code
+		elog.Error(0xc0000008, err.Error())
+		changes <- svc.Status{State: svc.StopPending}
+		return true, 1
+	}
+	changes <- svc.Status{State: svc.Running, Accepts: cmdsAccepted}
 	elog.Info(0x40000003, app.ServiceName)
 loop:
 	for {
@@ -117,6 +130,33 @@ loop:
 	return
 }
 
+// startAgent starts the agent, reporting an increasing checkpoint to the service
+// control manager until it is started.
+func startAgent(r <-chan svc.ChangeRequest, changes chan<- svc.Status) error {
+	started := make(chan error, 1)
+	go func() {
+		started <- app.StartAgent()
+	}()
+
+	ticker := time.NewTicker(startCheckPointInterval)
+	defer ticker.Stop()
+	status := svc.Status{State: svc.StartPending, WaitHint: startWaitHint}
+	for {
+		select {
+		case err := <-started:
+			return err
+		case <-ticker.C:
+			status.CheckPoint++
+			changes <- status
+		case c := <-r:
+			// stop is not accepted until the agent is started
+			if c.Cmd == svc.Interrogate {
+				changes <- status
+			}
+		}
+	}
+}
+
 func runService(isDebug bool) {
 	var err error
 	if isDebug {
@@ -19,9 +19,12 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/cmd/agent/common/signals"
 	apiutil "github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/leaderproxy"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/tagstream"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/flare"
 	"github.com/DataDog/datadog-agent/pkg/status"
+	"github.com/DataDog/datadog-agent/pkg/status/sections"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	as "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/version"
@@ -32,17 +35,22 @@ var EventChecks = []string{
 	"kubernetes",
 }
 
-// SetupHandlers adds the specific handlers for cluster agent endpoints
+// SetupHandlers adds the specific handlers for cluster agent endpoints, the
+// followers forward the requests of the leader only endpoints to the leader.
 func SetupHandlers(r *mux.Router) {
 	r.HandleFunc("/version", getVersion).Methods("GET")
 	r.HandleFunc("/hostname", getHostname).Methods("GET")
 	r.HandleFunc("/flare", makeFlare).Methods("POST")
 	r.HandleFunc("/stop", stopAgent).Methods("POST")
 	r.HandleFunc("/status", getStatus).Methods("GET")
-	r.HandleFunc("/api/v1/metadata/{nodeName}/{podName}", getPodMetadata).Methods("GET")
-	r.HandleFunc("/api/v1/metadata/{nodeName}", getNodeMetadata).Methods("GET")
-	r.HandleFunc("/api/v1/metadata", getAllMetadata).Methods("GET")
-	r.HandleFunc("/api/v1/{check}/events", getCheckLatestEvents).Methods("GET")
+	r.HandleFunc("/status/section/{section}", getSectionStatus).Methods("GET")
+	r.HandleFunc("/api/v1/metadata/{nodeName}/{podName}", leaderproxy.Wrap(getPodMetadata)).Methods("GET")
+	r.HandleFunc("/api/v1/metadata/{nodeName}", leaderproxy.Wrap(getNodeMetadata)).Methods("GET")
+	r.HandleFunc("/api/v1/metadata", leaderproxy.Wrap(getAllMetadata)).Methods("GET")
+	r.HandleFunc("/api/v1/nodes/{nodeName}/labels", getNodeLabels).Methods("GET")
+	r.HandleFunc("/api/v1/pods/{namespace}/{podName}/services", getPodServices).Methods("GET")
+	r.HandleFunc("/api/v1/tags/stream/{nodeName}", streamNodeMetadata).Methods("GET")
+	r.HandleFunc("/api/v1/{check}/events", leaderproxy.Wrap(getCheckLatestEvents)).Methods("GET")
 }
 
 func getStatus(w http.ResponseWriter, r *http.Request) {
@@ -67,6 +75,37 @@ func getStatus(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonStats)
 }
 
+func getSectionStatus(w http.ResponseWriter, r *http.Request) {
+	if err := apiutil.Validate(w, r); err != nil {
+		return
+	}
+
+	name := mux.Vars(r)["section"]
+	log.Infof("Got a request for the %s section of the status.", name)
+	s, err := sections.Get(name)
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		code := 500
+		if _, notFound := err.(*sections.SectionNotFoundError); notFound {
+			code = 404
+		}
+		log.Errorf("Error getting the %s section of the status. Error: %v", name, err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), code)
+		return
+	}
+
+	jsonStats, err := json.Marshal(s)
+	if err != nil {
+		log.Errorf("Error marshalling the %s section of the status. Error: %v, Status: %v", name, err, s)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Write(jsonStats)
+}
+
 // TODO: make sure it works for DCA
 func stopAgent(w http.ResponseWriter, r *http.Request) {
 	if err := apiutil.ValidateDCARequest(w, r); err != nil {
@@ -139,6 +178,9 @@ func makeFlare(w http.ResponseWriter, r *http.Request) {
 
 // TODO: complete it
 func getCheckLatestEvents(w http.ResponseWriter, r *http.Request) {
+	if err := apiutil.ValidateDCARequest(w, r); err != nil {
+		return
+	}
 	vars := mux.Vars(r)
 	check := vars["check"]
 	supportedCheck := false
@@ -207,6 +249,131 @@ func getPodMetadata(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// getNodeLabels is used by the node agents to get the labels of their node,
+// for the kubernetes_node_labels_as_tags host tags.
+func getNodeLabels(w http.ResponseWriter, r *http.Request) {
+	/*
+		Input
+			localhost:5001/api/v1/nodes/localhost/labels
+		Outputs
+			Status: 200
+			Returns: map[string]string
+			Example: {"kubernetes.io/hostname": "localhost", "kubernetes.io/os": "linux"}
+
+			Status: 500
+			Returns: string
+			Example: "nodes "localhost" not found"
+	*/
+	if err := apiutil.ValidateDCARequest(w, r); err != nil {
+		return
+	}
+	vars := mux.Vars(r)
+	nodeName := vars["nodeName"]
+	labels, err := as.GetNodeLabels(nodeName)
+	if err != nil {
+		log.Errorf("Could not retrieve the labels of the node %s: %s", nodeName, err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	labelBytes, err := json.Marshal(labels)
+	if err != nil {
+		log.Errorf("Could not process the labels of the node %s: %s", nodeName, err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.WriteHeader(200)
+	w.Write(labelBytes)
+}
+
+// getPodServices is used by the node agents to get the services selecting a pod,
+// for the kube_service tags.
+func getPodServices(w http.ResponseWriter, r *http.Request) {
+	/*
+		Input
+			localhost:5001/api/v1/pods/default/my-nginx-5d69/services
+		Outputs
+			Status: 200
+			Returns: []string
+			Example: ["my-nginx-service"]
+
+			Status: 500
+			Returns: string
+			Example: "could not list the endpoints"
+	*/
+	if err := apiutil.ValidateDCARequest(w, r); err != nil {
+		return
+	}
+	vars := mux.Vars(r)
+	namespace := vars["namespace"]
+	podName := vars["podName"]
+	services, err := as.GetPodServices(namespace, podName)
+	if err != nil {
+		log.Errorf("Could not retrieve the services of the pod %s/%s: %s", namespace, podName, err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	servicesBytes, err := json.Marshal(services)
+	if err != nil {
+		log.Errorf("Could not process the services of the pod %s/%s: %s", namespace, podName, err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.WriteHeader(200)
+	w.Write(servicesBytes)
+}
+
+// streamNodeMetadata is used by the node agents to subscribe to the cluster level
+// tags of the pods of their node, instead of querying them for each pod.
+func streamNodeMetadata(w http.ResponseWriter, r *http.Request) {
+	/*
+		Input
+			localhost:5001/api/v1/tags/stream/localhost
+		Outputs
+			Status: 200
+			Returns: a stream of clusteragent.NodeMetadata, pushed when they change
+			Example: {"pod_services":{"default/my-nginx-5d69":["my-nginx-service"]},"cluster_tags":["kube_cluster_name:prod"]}
+
+			Status: 500
+			Returns: string
+			Example: "kubernetes apiserver support not compiled in"
+	*/
+	if err := apiutil.ValidateDCARequest(w, r); err != nil {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", 500)
+		return
+	}
+	vars := mux.Vars(r)
+	nodeName := vars["nodeName"]
+	updates, err := tagstream.Subscribe(nodeName)
+	if err != nil {
+		log.Errorf("Could not stream the metadata of the node %s: %s", nodeName, err)
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	defer tagstream.Unsubscribe(updates)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case metadata := <-updates:
+			if err := encoder.Encode(metadata); err != nil {
+				log.Debugf("The metadata stream of the node %s was interrupted: %s", nodeName, err)
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
 // getNodeMetadata has the same signature as getAllMetadata, but is only scoped on one node.
 func getNodeMetadata(w http.ResponseWriter, r *http.Request) {
 	if err := apiutil.Validate(w, r); err != nil {
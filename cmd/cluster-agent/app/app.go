@@ -19,7 +19,12 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/cmd/cluster-agent/api"
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/api/healthprobe"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/externalmetrics"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/orchestrator"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/reconciler"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
@@ -87,6 +92,13 @@ func start(cmd *cobra.Command, args []string) error {
 	if !configFound {
 		log.Infof("Config read from env variables")
 	}
+	config.ResolveDeprecations()
+	if err := config.ApplyProfile(); err != nil {
+		return err
+	}
+	if err := config.SetupScrubber(); err != nil {
+		return err
+	}
 
 	// Setup logger
 	syslogURI := config.GetSyslogURI()
@@ -113,6 +125,7 @@ func start(cmd *cobra.Command, args []string) error {
 		log.Criticalf("Unable to setup logger: %s", err)
 		return nil
 	}
+	config.LogDeprecations()
 
 	if !config.Datadog.IsSet("api_key") {
 		log.Critical("no API key configured, exiting")
@@ -131,6 +144,11 @@ func start(cmd *cobra.Command, args []string) error {
 		return log.Errorf("Error while starting api server, exiting: %v", err)
 	}
 
+	// start the health probe server
+	if err = healthprobe.Serve(config.Datadog.GetInt("health_port")); err != nil {
+		return log.Errorf("Error while starting the health probe server, exiting: %v", err)
+	}
+
 	// setup the forwarder
 	keysPerDomain, err := config.GetMultipleEndpoints()
 	if err != nil {
@@ -157,6 +175,26 @@ func start(cmd *cobra.Command, args []string) error {
 		asc.StartMetadataMapping()
 	}
 
+	// Start the External Metrics Provider for the Horizontal Pod Autoscalers.
+	if err = externalmetrics.StartServer(); err != nil {
+		log.Errorf("Could not start the External Metrics Provider: %s", err.Error())
+	}
+
+	// Start the admission webhook injecting the agent configuration in the pods.
+	if err = admission.StartServer(); err != nil {
+		log.Errorf("Could not start the admission webhook: %s", err.Error())
+	}
+
+	// Start the collection of the deployments, replicasets and pods.
+	if err = orchestrator.Start(s); err != nil {
+		log.Errorf("Could not start the collection of the cluster resources: %s", err.Error())
+	}
+
+	// Start the pruning of the cached entities of the deleted pods.
+	if err = reconciler.Start(); err != nil {
+		log.Errorf("Could not start the reconciliation of the cached entities: %s", err.Error())
+	}
+
 	// Setup a channel to catch OS signals
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
@@ -167,7 +205,9 @@ func start(cmd *cobra.Command, args []string) error {
 	// Block here until we receive the interrupt signal
 	<-signalCh
 
+	reconciler.Stop()
 	clusterAgent.Stop()
+	healthprobe.Stop()
 	log.Info("See ya!")
 	log.Flush()
 	return nil
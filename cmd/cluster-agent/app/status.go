@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 
 	log "github.com/cihub/seelog"
 	"github.com/spf13/cobra"
@@ -23,6 +24,7 @@ var (
 	jsonStatus      bool
 	prettyPrintJSON bool
 	statusFilePath  string
+	statusSection   string
 )
 
 func init() {
@@ -30,6 +32,7 @@ func init() {
 	statusCmd.Flags().BoolVarP(&jsonStatus, "json", "j", false, "print out raw json")
 	statusCmd.Flags().BoolVarP(&prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
 	statusCmd.Flags().StringVarP(&statusFilePath, "file", "o", "", "Output the status command to a file")
+	statusCmd.Flags().StringVarP(&statusSection, "section", "s", "", "print out the JSON status of a section: forwarder, collector or leader-election")
 }
 
 var statusCmd = &cobra.Command{
@@ -62,12 +65,18 @@ var statusCmd = &cobra.Command{
 }
 
 func requestStatus() error {
-	fmt.Printf("Getting the status from the agent.\n")
+	// the JSON outputs are kept parseable
+	if !jsonStatus && !prettyPrintJSON && statusSection == "" {
+		fmt.Printf("Getting the status from the agent.\n")
+	}
 	var e error
 	var s string
 	c := util.GetClient(false) // FIX: get certificates right then make this true
 	// TODO use https
 	urlstr := fmt.Sprintf("https://localhost:%v/status", config.Datadog.GetInt("cluster_agent_cmd_port"))
+	if statusSection != "" {
+		urlstr += "/section/" + url.PathEscape(statusSection)
+	}
 
 	// Set session token
 	e = util.SetAuthToken()
@@ -78,10 +87,13 @@ func requestStatus() error {
 	r, e := util.DoGet(c, urlstr)
 	if e != nil {
 		var errMap = make(map[string]string)
-		json.Unmarshal(r, errMap)
+		json.Unmarshal(r, &errMap)
 		// If the error has been marshalled into a json object, check it and return it properly
 		if err, found := errMap["error"]; found {
 			e = fmt.Errorf(err)
+			if statusSection != "" {
+				return e
+			}
 		}
 
 		fmt.Printf(`
@@ -92,7 +104,8 @@ func requestStatus() error {
 	}
 
 	// The rendering is done in the client so that the agent has less work to do
-	if prettyPrintJSON {
+	// The sections are printed as JSON, indented unless --json is set
+	if prettyPrintJSON || (statusSection != "" && !jsonStatus) {
 		var prettyJSON bytes.Buffer
 		json.Indent(&prettyJSON, r, "", "  ")
 		s = prettyJSON.String()
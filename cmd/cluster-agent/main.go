@@ -14,13 +14,15 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/network"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	log "github.com/cihub/seelog"
 
 	"github.com/DataDog/datadog-agent/cmd/cluster-agent/app"
 )
 
 func main() {
-	// go_expvar server
+	// go_expvar server, it also serves the telemetry
+	http.Handle("/telemetry", telemetry.Handler())
 	go http.ListenAndServe(
 		fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("expvar_port")),
 		http.DefaultServeMux)
@@ -21,12 +21,14 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/api/healthprobe"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/metadata"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
@@ -98,6 +100,13 @@ func start(cmd *cobra.Command, args []string) error {
 	if !configFound {
 		log.Infof("Config will be read from env variables")
 	}
+	config.ResolveDeprecations()
+	if err := config.ApplyProfile(); err != nil {
+		return err
+	}
+	if err := config.SetupScrubber(); err != nil {
+		return err
+	}
 
 	// Setup logger
 	syslogURI := config.GetSyslogURI()
@@ -125,12 +134,18 @@ func start(cmd *cobra.Command, args []string) error {
 		log.Criticalf("Unable to setup logger: %s", err)
 		return nil
 	}
+	config.LogDeprecations()
 
 	if !config.Datadog.IsSet("api_key") {
 		log.Critical("no API key configured, exiting")
 		return nil
 	}
 
+	// start the health probe server
+	if err = healthprobe.Serve(config.Datadog.GetInt("health_port")); err != nil {
+		return log.Errorf("Error while starting the health probe server, exiting: %v", err)
+	}
+
 	// setup the forwarder
 	keysPerDomain, err := config.GetMultipleEndpoints()
 	if err != nil {
@@ -189,13 +204,15 @@ func start(cmd *cobra.Command, args []string) error {
 		metaScheduler.Stop()
 	}
 	statsd.Stop()
+	healthprobe.Stop()
 	log.Info("See ya!")
 	log.Flush()
 	return nil
 }
 
 func main() {
-	// go_expvar server
+	// go_expvar server, it also serves the telemetry
+	http.Handle("/telemetry", telemetry.Handler())
 	go http.ListenAndServe(
 		fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_stats_port")),
 		http.DefaultServeMux)
@@ -53,7 +53,7 @@ func doConfigure() error {
 
 	// Get the CSRF token from the agent
 	c := util.GetClient(false) // FIX: get certificates right then make this true
-	urlstr := fmt.Sprintf("https://localhost:%v/agent/gui/csrf-token", config.Datadog.GetInt("cmd_port"))
+	urlstr := util.IPCURL("agent/gui/csrf-token")
 	err = util.SetAuthToken()
 	if err != nil {
 		return err
@@ -73,6 +73,7 @@ build do
 
             # remove windows specific configs
             delete "/etc/datadog-agent/conf.d/winproc.d"
+            delete "/etc/datadog-agent/conf.d/windows_perf_counters.d"
 
             # cleanup clutter
             delete "#{install_dir}/etc"
@@ -82,6 +83,7 @@ build do
 
             # remove windows specific configs
             delete "#{install_dir}/etc/conf.d/winproc.d"
+            delete "#{install_dir}/etc/conf.d/windows_perf_counters.d"
 
             delete "#{install_dir}/etc/trace-agent.conf.example"
 
@@ -130,6 +130,7 @@ type BufferedAggregator struct {
 	mu                 sync.Mutex // to protect the checkSamplers field
 	serializer         *serializer.Serializer
 	hostname           string
+	expiryUpdate       chan float64
 	hostnameUpdate     chan string
 	hostnameUpdateDone chan struct{}    // signals that the hostname update is finished
 	TickerChan         <-chan time.Time // For test/benchmark purposes: it allows the flush to be controlled from the outside
@@ -138,11 +139,12 @@ type BufferedAggregator struct {
 
 // NewBufferedAggregator instantiates a BufferedAggregator
 func NewBufferedAggregator(s *serializer.Serializer, hostname string, flushInterval time.Duration) *BufferedAggregator {
+	bufferSize := config.Datadog.GetInt("aggregator_buffer_size")
 	aggregator := &BufferedAggregator{
-		dogstatsdIn:        make(chan *metrics.MetricSample, 100), // TODO make buffer size configurable
-		checkMetricIn:      make(chan senderMetricSample, 100),    // TODO make buffer size configurable
-		serviceCheckIn:     make(chan metrics.ServiceCheck, 100),  // TODO make buffer size configurable
-		eventIn:            make(chan metrics.Event, 100),         // TODO make buffer size configurable
+		dogstatsdIn:        make(chan *metrics.MetricSample, bufferSize),
+		checkMetricIn:      make(chan senderMetricSample, bufferSize),
+		serviceCheckIn:     make(chan metrics.ServiceCheck, bufferSize),
+		eventIn:            make(chan metrics.Event, bufferSize),
 		sampler:            *NewTimeSampler(bucketSize, hostname),
 		checkSamplers:      make(map[check.ID]*CheckSampler),
 		distSampler:        *NewDistSampler(bucketSize, hostname),
@@ -151,6 +153,7 @@ func NewBufferedAggregator(s *serializer.Serializer, hostname string, flushInter
 		hostname:           hostname,
 		hostnameUpdate:     make(chan string),
 		hostnameUpdateDone: make(chan struct{}),
+		expiryUpdate:       make(chan float64),
 		health:             health.Register("aggregator"),
 	}
 
@@ -192,6 +195,14 @@ func (agg *BufferedAggregator) SetHostname(hostname string) {
 	<-agg.hostnameUpdateDone
 }
 
+// SetContextExpiry sets the number of seconds after which the dogstatsd
+// contexts are expired, a shorter expiry lowers the memory used by the
+// aggregator when there are many short-lived contexts. A zero expiry restores
+// the default one.
+func (agg *BufferedAggregator) SetContextExpiry(seconds float64) {
+	agg.expiryUpdate <- seconds
+}
+
 // AddAgentStartupEvent adds the startup event to the events that'll be sent on the next flush
 func (agg *BufferedAggregator) AddAgentStartupEvent(agentVersion string) {
 	event := metrics.Event{
@@ -469,6 +480,12 @@ func (agg *BufferedAggregator) run() {
 			agg.sampler.defaultHostname = h
 			agg.mu.Unlock()
 			agg.hostnameUpdateDone <- struct{}{}
+		case expiry := <-agg.expiryUpdate:
+			aggregatorExpvar.Add("ContextExpiryUpdate", 1)
+			if expiry <= 0 {
+				expiry = defaultExpiry
+			}
+			agg.sampler.contextExpiry = expiry
 		}
 	}
 }
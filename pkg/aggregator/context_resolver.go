@@ -14,6 +14,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/intern"
 )
 
 // Context holds the elements that form a context, and can be serialized into a context key
@@ -45,10 +46,11 @@ func newContextResolver() *ContextResolver {
 func (cr *ContextResolver) trackContext(metricSample *metrics.MetricSample, currentTimestamp float64) ckey.ContextKey {
 	contextKey := generateContextKey(metricSample)
 	if _, ok := cr.contextsByKey[contextKey]; !ok {
+		// the contexts are kept until they expire, their strings are interned
 		cr.contextsByKey[contextKey] = &Context{
-			Name: metricSample.Name,
-			Tags: metricSample.Tags,
-			Host: metricSample.Host,
+			Name: intern.String(metricSample.Name),
+			Tags: intern.Strings(metricSample.Tags),
+			Host: intern.String(metricSample.Host),
 		}
 	}
 	cr.lastSeenByKey[contextKey] = currentTimestamp
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package diagnostic streams the events and the service checks submitted to
+// the aggregator to the clients of the `agent stream-event-platform` command.
+package diagnostic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/stream"
+)
+
+// The fields the events and the service checks can be filtered by
+const (
+	// FilterCheck is the name of the check submitting them, dogstatsd for
+	// the ones received by dogstatsd
+	FilterCheck = "check"
+	// FilterType is TypeEvent or TypeServiceCheck
+	FilterType = "type"
+)
+
+// The types of the lines streamed
+const (
+	TypeEvent        = "event"
+	TypeServiceCheck = "service_check"
+)
+
+// DogstatsdCheck is the check name of the events and the service checks
+// received by dogstatsd
+const DogstatsdCheck = "dogstatsd"
+
+// FilterKeys are the fields the events and the service checks can be filtered by
+var FilterKeys = []string{FilterCheck, FilterType}
+
+// Broadcaster sends the events and the service checks to the clients of the stream
+var Broadcaster = stream.NewBroadcaster()
+
+// Active returns whether a client is streaming the events and the service
+// checks, the submitters check it before computing the check name.
+func Active() bool {
+	return Broadcaster.Active()
+}
+
+// HandleEvent sends an event submitted by check to the clients of the stream.
+func HandleEvent(check string, e metrics.Event) {
+	if !Broadcaster.Active() {
+		return
+	}
+	fields := map[string]string{FilterCheck: check, FilterType: TypeEvent}
+	Broadcaster.Send(fields, fmt.Sprintf("Check: %s | Type: %s | Title: %s | Priority: %s | Alert Type: %s | Source: %s | Host: %s | Tags: %s | %s",
+		check, TypeEvent, e.Title, e.Priority, e.AlertType, e.SourceTypeName, e.Host, strings.Join(e.Tags, ","), e.Text))
+}
+
+// HandleServiceCheck sends a service check submitted by check to the clients
+// of the stream.
+func HandleServiceCheck(check string, sc metrics.ServiceCheck) {
+	if !Broadcaster.Active() {
+		return
+	}
+	fields := map[string]string{FilterCheck: check, FilterType: TypeServiceCheck}
+	Broadcaster.Send(fields, fmt.Sprintf("Check: %s | Type: %s | Name: %s | Status: %s | Host: %s | Tags: %s | %s",
+		check, TypeServiceCheck, sc.CheckName, sc.Status, sc.Host, strings.Join(sc.Tags, ","), sc.Message))
+}
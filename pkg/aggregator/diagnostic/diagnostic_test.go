@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package diagnostic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/stream"
+)
+
+func TestHandle(t *testing.T) {
+	// no client is subscribed
+	assert.False(t, Active())
+	HandleEvent("http_check", metrics.Event{Title: "dropped"})
+
+	checks := Broadcaster.Subscribe(stream.Filter{FilterType: TypeServiceCheck})
+	defer Broadcaster.Unsubscribe(checks)
+	assert.True(t, Active())
+	dogstatsd := Broadcaster.Subscribe(stream.Filter{FilterCheck: DogstatsdCheck})
+	defer Broadcaster.Unsubscribe(dogstatsd)
+
+	HandleEvent("http_check", metrics.Event{Title: "ignored"})
+	HandleEvent(DogstatsdCheck, metrics.Event{Title: "deploy", Text: "v2", AlertType: metrics.EventAlertTypeInfo, Tags: []string{"env:prod"}})
+	HandleServiceCheck("http_check", metrics.ServiceCheck{CheckName: "http.can_connect", Status: metrics.ServiceCheckCritical, Message: "timeout"})
+
+	assert.Len(t, dogstatsd.C, 1)
+	assert.Equal(t, "Check: dogstatsd | Type: event | Title: deploy | Priority:  | Alert Type: info | Source:  | Host:  | Tags: env:prod | v2", <-dogstatsd.C)
+	assert.Len(t, checks.C, 1)
+	assert.Equal(t, "Check: http_check | Type: service_check | Name: http.can_connect | Status: CRITICAL | Host:  | Tags:  | timeout", <-checks.C)
+}
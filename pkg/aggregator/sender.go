@@ -13,6 +13,7 @@ import (
 
 	log "github.com/cihub/seelog"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
@@ -233,6 +234,9 @@ func (s *checkSender) Historate(metric string, value float64, hostname string, t
 // SendRawServiceCheck sends the raw service check
 // Useful for testing - submitting precomputed service check.
 func (s *checkSender) SendRawServiceCheck(sc *metrics.ServiceCheck) {
+	if diagnostic.Active() {
+		diagnostic.HandleServiceCheck(check.IDToCheckName(s.id), *sc)
+	}
 	s.serviceCheckOut <- *sc
 }
 
@@ -248,6 +252,9 @@ func (s *checkSender) ServiceCheck(checkName string, status metrics.ServiceCheck
 		Message:   message,
 	}
 
+	if diagnostic.Active() {
+		diagnostic.HandleServiceCheck(check.IDToCheckName(s.id), serviceCheck)
+	}
 	s.serviceCheckOut <- serviceCheck
 
 	s.metricStats.Lock.Lock()
@@ -259,6 +266,9 @@ func (s *checkSender) ServiceCheck(checkName string, status metrics.ServiceCheck
 func (s *checkSender) Event(e metrics.Event) {
 	log.Trace("Event submitted: ", e.Title, " for hostname: ", e.Host, " tags: ", e.Tags)
 
+	if diagnostic.Active() {
+		diagnostic.HandleEvent(check.IDToCheckName(s.id), e)
+	}
 	s.eventOut <- e
 
 	s.metricStats.Lock.Lock()
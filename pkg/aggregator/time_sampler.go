@@ -31,6 +31,7 @@ type TimeSampler struct {
 	defaultHostname             string
 	counterLastSampledByContext map[ckey.ContextKey]float64
 	lastCutOffTime              int64
+	contextExpiry               float64 // number of seconds after which contexts are expired
 }
 
 // NewTimeSampler returns a newly initialized TimeSampler
@@ -41,6 +42,7 @@ func NewTimeSampler(interval int64, defaultHostname string) *TimeSampler {
 		metricsByTimestamp:          map[int64]metrics.ContextMetrics{},
 		defaultHostname:             defaultHostname,
 		counterLastSampledByContext: map[ckey.ContextKey]float64{},
+		contextExpiry:               defaultExpiry,
 	}
 }
 
@@ -140,7 +142,7 @@ func (s *TimeSampler) flush(timestamp float64) metrics.Series {
 		}
 	}
 
-	s.contextResolver.expireContexts(timestamp - defaultExpiry)
+	s.contextResolver.expireContexts(timestamp - s.contextExpiry)
 	s.lastCutOffTime = cutoffTime
 	return result
 }
@@ -161,6 +163,10 @@ func (s *TimeSampler) flushContextMetrics(timestamp int64, contextMetrics metric
 
 func (s *TimeSampler) countersSampleZeroValue(timestamp int64, contextMetrics metrics.ContextMetrics, counterContextsToDelete map[ckey.ContextKey]struct{}) {
 	expirySeconds := config.Datadog.GetFloat64("dogstatsd_expiry_seconds")
+	// don't keep sending zeros for counters whose contexts are shed
+	if expirySeconds > s.contextExpiry {
+		expirySeconds = s.contextExpiry
+	}
 	for counterContext, lastSampled := range s.counterLastSampledByContext {
 		if expirySeconds+lastSampled > float64(timestamp) {
 			sample := &metrics.MetricSample{
@@ -141,6 +141,27 @@ func TestContextSampling(t *testing.T) {
 	metrics.AssertSerieEqual(t, expectedSerie3, series[2])
 }
 
+func TestContextExpiry(t *testing.T) {
+	sampler := NewTimeSampler(10, "default-hostname")
+	sampler.contextExpiry = 30
+
+	mSample := metrics.MetricSample{
+		Name:       "my.metric.name",
+		Value:      1,
+		Mtype:      metrics.GaugeType,
+		Tags:       []string{"foo", "bar"},
+		SampleRate: 1,
+	}
+	sampler.addSample(&mSample, 12346.0)
+
+	sampler.flush(12360.0)
+	assert.Len(t, sampler.contextResolver.contextsByKey, 1)
+
+	// the context is expired before the default expiry
+	sampler.flush(12380.0)
+	assert.Len(t, sampler.contextResolver.contextsByKey, 0)
+}
+
 func TestCounterExpirySeconds(t *testing.T) {
 	sampler := NewTimeSampler(10, "default-hostname")
 
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+/*
+Package healthprobe serves the health of the agent for the liveness and the
+readiness probes of Kubernetes. Unlike the IPC api, its endpoints are served
+in plain HTTP without authentication: they only expose the names of the
+healthy and of the unhealthy components.
+*/
+package healthprobe
+
+import (
+	"encoding/json"
+	"fmt"
+	stdLog "log"
+	"net"
+	"net/http"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+)
+
+const defaultTimeout = 5 * time.Second
+
+var listener net.Listener
+
+// Serve starts serving the `/live` and `/ready` endpoints on port, it does
+// nothing when port is 0.
+func Serve(port int) error {
+	if port == 0 {
+		return nil
+	}
+	var err error
+	listener, err = net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return fmt.Errorf("unable to start the health probe server: %v", err)
+	}
+
+	srv := &http.Server{
+		Handler:      newRouter(),
+		ErrorLog:     stdLog.New(&config.ErrorLogWriter{}, "", 0), // log errors to seelog
+		ReadTimeout:  defaultTimeout,
+		WriteTimeout: defaultTimeout,
+	}
+	go srv.Serve(listener)
+	log.Infof("Health probe server is listening at %v", listener.Addr())
+	return nil
+}
+
+// Stop closes the listener of the health probe server
+func Stop() {
+	if listener != nil {
+		listener.Close()
+	}
+}
+
+func newRouter() *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/live", statusHandler(health.GetLive)).Methods("GET")
+	r.HandleFunc("/ready", statusHandler(health.GetReady)).Methods("GET")
+	return r
+}
+
+// statusHandler writes the status returned by getStatus, with a 500 status
+// code when a component is unhealthy
+func statusHandler(getStatus func() health.Status) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := getStatus()
+		body, err := json.Marshal(status)
+		if err != nil {
+			log.Errorf("Error marshalling the health status: %v", err)
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(status.Unhealthy) > 0 {
+			log.Infof("Health check failed for the components: %v", status.Unhealthy)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write(body)
+	}
+}
@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package healthprobe
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+)
+
+func getStatus(t *testing.T, handler http.HandlerFunc) (int, health.Status) {
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+	status := health.Status{}
+	require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	return rec.Code, status
+}
+
+func TestHealthy(t *testing.T) {
+	code, status := getStatus(t, statusHandler(func() health.Status {
+		return health.Status{Healthy: []string{"healthcheck", "forwarder"}}
+	}))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"healthcheck", "forwarder"}, status.Healthy)
+}
+
+func TestUnhealthy(t *testing.T) {
+	code, status := getStatus(t, statusHandler(func() health.Status {
+		return health.Status{Healthy: []string{"healthcheck"}, Unhealthy: []string{"forwarder"}}
+	}))
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, []string{"forwarder"}, status.Unhealthy)
+}
+
+func TestRoutes(t *testing.T) {
+	r := newRouter()
+	for _, path := range []string{"/live", "/ready"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		assert.NotEqual(t, http.StatusNotFound, rec.Code, path)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -109,27 +109,13 @@ func GenerateRootCert(hosts []string, bits int) (
 // FetchAuthToken gets the authentication token from the auth token file & creates one if it doesn't exist
 // Requires that the config has been set up before calling
 func FetchAuthToken() (string, error) {
-	var authTokenFile string
-	if config.Datadog.GetString("auth_token_file_path") != "" {
-		authTokenFile = config.Datadog.GetString("auth_token_file_path")
-	} else {
-		authTokenFile = filepath.Join(filepath.Dir(config.Datadog.ConfigFileUsed()), authTokenName)
-	}
+	authTokenFile := authTokenFilePath()
 
 	// Create a new token if it doesn't exist
 	if _, e := os.Stat(authTokenFile); os.IsNotExist(e) {
-		key := make([]byte, authTokenMinimalLen)
-		_, e = rand.Read(key)
-		if e != nil {
-			return "", fmt.Errorf("error creating authentication token: %s", e)
+		if _, e = createAuthToken(authTokenFile); e != nil {
+			return "", e
 		}
-
-		// Write the auth token to the auth token file (platform-specific)
-		e = saveAuthToken(hex.EncodeToString(key), authTokenFile)
-		if e != nil {
-			return "", fmt.Errorf("error creating authentication token: %s", e)
-		}
-		log.Infof("Saved a new authentication token to %s", authTokenFile)
 	}
 
 	// Read the token
@@ -147,6 +133,40 @@ func FetchAuthToken() (string, error) {
 	return authToken, nil
 }
 
+// RotateAuthToken replaces the token of the auth token file by a new one and
+// returns it
+// Requires that the config has been set up before calling
+func RotateAuthToken() (string, error) {
+	return createAuthToken(authTokenFilePath())
+}
+
+// createAuthToken writes a new token to the auth token file and returns it
+func createAuthToken(authTokenFile string) (string, error) {
+	key := make([]byte, authTokenMinimalLen)
+	_, e := rand.Read(key)
+	if e != nil {
+		return "", fmt.Errorf("error creating authentication token: %s", e)
+	}
+
+	// Write the auth token to the auth token file (platform-specific)
+	authToken := hex.EncodeToString(key)
+	e = saveAuthToken(authToken, authTokenFile)
+	if e != nil {
+		return "", fmt.Errorf("error creating authentication token: %s", e)
+	}
+	log.Infof("Saved a new authentication token to %s", authTokenFile)
+	return authToken, nil
+}
+
+// authTokenFilePath returns the path of the auth token file, next to the
+// configuration file unless `auth_token_file_path` is set
+func authTokenFilePath() string {
+	if config.Datadog.GetString("auth_token_file_path") != "" {
+		return config.Datadog.GetString("auth_token_file_path")
+	}
+	return filepath.Join(filepath.Dir(config.Datadog.ConfigFileUsed()), authTokenName)
+}
+
 // DeleteAuthToken removes auth_token file (test clean up)
 func DeleteAuthToken() error {
 	authTokenFile := filepath.Join(filepath.Dir(config.Datadog.ConfigFileUsed()), authTokenName)
@@ -40,3 +40,23 @@ func TestFetchAuthTokenValidGen(t *testing.T) {
 	_, err = os.Stat(expectTokenPath)
 	require.Nil(t, err)
 }
+
+func TestRotateAuthToken(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "fake-datadog-etc-")
+	require.Nil(t, err, fmt.Sprintf("%v", err))
+	defer os.RemoveAll(testDir)
+
+	config.Datadog.Set("auth_token_file_path", filepath.Join(testDir, "auth_token"))
+	defer config.Datadog.Set("auth_token_file_path", "")
+
+	token, err := FetchAuthToken()
+	require.Nil(t, err, fmt.Sprintf("%v", err))
+
+	rotated, err := RotateAuthToken()
+	require.Nil(t, err, fmt.Sprintf("%v", err))
+	assert.NotEqual(t, token, rotated)
+
+	fetched, err := FetchAuthToken()
+	require.Nil(t, err, fmt.Sprintf("%v", err))
+	assert.Equal(t, rotated, fetched)
+}
@@ -6,6 +6,8 @@
 package util
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/tls"
 	"fmt"
 	"io"
@@ -26,16 +28,37 @@ func GetClient(verify bool) *http.Client {
 	return &http.Client{Transport: tr}
 }
 
-// DoGet is a wrapper around performing HTTP GET requests
-func DoGet(c *http.Client, url string) (body []byte, e error) {
-	req, e := http.NewRequest("GET", url, nil)
-	if e != nil {
-		return body, e
+// doWithAuthToken performs the request created by newRequest with the session
+// token. When it is refused, the token was likely rotated: the request is
+// sent again once with the token of the auth token file.
+func doWithAuthToken(c *http.Client, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	do := func() (*http.Response, error) {
+		req, e := newRequest()
+		if e != nil {
+			return nil, e
+		}
+		req.Header.Set("Authorization", "Bearer "+GetAuthToken())
+		return c.Do(req)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+GetAuthToken())
 
-	r, e := c.Do(req)
+	r, e := do()
+	if e != nil || r.StatusCode != http.StatusForbidden || !reloadAuthToken() {
+		return r, e
+	}
+	r.Body.Close()
+	return do()
+}
+
+// DoGet is a wrapper around performing HTTP GET requests
+func DoGet(c *http.Client, url string) (body []byte, e error) {
+	r, e := doWithAuthToken(c, func() (*http.Request, error) {
+		req, e := http.NewRequest("GET", url, nil)
+		if e != nil {
+			return nil, e
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if e != nil {
 		return body, e
 	}
@@ -51,6 +74,30 @@ func DoGet(c *http.Client, url string) (body []byte, e error) {
 
 }
 
+// DoGetStream is a wrapper around performing HTTP GET requests whose response
+// is streamed, onLine is called with every line received until the server
+// closes the stream.
+func DoGetStream(c *http.Client, url string, onLine func(line []byte)) error {
+	r, e := doWithAuthToken(c, func() (*http.Request, error) {
+		return http.NewRequest("GET", url, nil)
+	})
+	if e != nil {
+		return e
+	}
+	defer r.Body.Close()
+	if r.StatusCode >= 400 {
+		body, _ := ioutil.ReadAll(r.Body)
+		return fmt.Errorf("%s", body)
+	}
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		onLine(scanner.Bytes())
+	}
+	return scanner.Err()
+}
+
 // DoGetExternalEndpoint is a wrapper around performing HTTP GET requests designed for external endpoints.
 func DoGetExternalEndpoint(c *http.Client, url string) (body []byte, e error) {
 	req, e := http.NewRequest("GET", url, nil)
@@ -83,14 +130,21 @@ func DoGetExternalEndpoint(c *http.Client, url string) (body []byte, e error) {
 
 // DoPost is a wrapper around performing HTTP POST requests
 func DoPost(c *http.Client, url string, contentType string, body io.Reader) (resp []byte, e error) {
-	req, e := http.NewRequest("POST", url, body)
-	if e != nil {
-		return resp, e
+	// the body is read once so that it can be sent again
+	var payload []byte
+	if body != nil {
+		if payload, e = ioutil.ReadAll(body); e != nil {
+			return resp, e
+		}
 	}
-	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("Authorization", "Bearer "+GetAuthToken())
-
-	r, e := c.Do(req)
+	r, e := doWithAuthToken(c, func() (*http.Request, error) {
+		req, e := http.NewRequest("POST", url, bytes.NewReader(payload))
+		if e != nil {
+			return nil, e
+		}
+		req.Header.Set("Content-Type", contentType)
+		return req, nil
+	})
 	if e != nil {
 		return resp, e
 	}
@@ -6,28 +6,45 @@
 package util
 
 import (
+	"crypto/subtle"
 	"fmt"
-	"github.com/DataDog/datadog-agent/pkg/config"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
+// IPCAPIPrefix is the prefix of the versioned endpoints of the agent IPC api
+const IPCAPIPrefix = "/api/v1"
+
 var (
 	token    string
 	dcaToken string
+
+	// previousToken is still accepted until previousTokenExpiry after a
+	// rotation, so the clients have the time to read the new token
+	previousToken       string
+	previousTokenExpiry time.Time
+	tokenMutex          sync.RWMutex
+
+	rotationHandlers      []func()
+	rotationHandlersMutex sync.Mutex
 )
 
 // SetAuthToken sets the session token
 // Requires that the config has been set up before calling
 func SetAuthToken() error {
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+
 	// Noop if token is already set
 	if token != "" {
 		return nil
 	}
 
-	// token is only set once, no need to mutex protect
 	var err error
 	token, err = security.FetchAuthToken()
 	return err
@@ -35,9 +52,85 @@ func SetAuthToken() error {
 
 // GetAuthToken gets the session token
 func GetAuthToken() string {
+	tokenMutex.RLock()
+	defer tokenMutex.RUnlock()
 	return token
 }
 
+// RotateAuthToken replaces the session token and the auth token file by a new
+// token, the previous one is still accepted for `auth_token_rotation_grace_period`
+// seconds.
+// Requires that the config has been set up before calling
+func RotateAuthToken() error {
+	// the token file is written under the lock, a client can not read the new
+	// token before the server accepts it
+	tokenMutex.Lock()
+	newToken, err := security.RotateAuthToken()
+	if err != nil {
+		tokenMutex.Unlock()
+		return err
+	}
+	previousToken = token
+	previousTokenExpiry = time.Now().Add(config.Datadog.GetDuration("auth_token_rotation_grace_period") * time.Second)
+	token = newToken
+	tokenMutex.Unlock()
+
+	rotationHandlersMutex.Lock()
+	defer rotationHandlersMutex.Unlock()
+	for _, handler := range rotationHandlers {
+		handler()
+	}
+	return nil
+}
+
+// OnAuthTokenRotation registers a handler called after each rotation of the
+// session token, for the clients which can not read it again by themselves.
+func OnAuthTokenRotation(handler func()) {
+	rotationHandlersMutex.Lock()
+	defer rotationHandlersMutex.Unlock()
+	rotationHandlers = append(rotationHandlers, handler)
+}
+
+// reloadAuthToken reads the session token from the auth token file again, it
+// is used by the clients when the token was rotated. It returns whether the
+// token changed.
+func reloadAuthToken() bool {
+	newToken, err := security.FetchAuthToken()
+	if err != nil {
+		return false
+	}
+
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+	if newToken == token {
+		return false
+	}
+	token = newToken
+	return true
+}
+
+// isValidAuthToken checks a token against the session token and, during the
+// grace period of a rotation, against the previous one.
+func isValidAuthToken(t string) bool {
+	tokenMutex.RLock()
+	defer tokenMutex.RUnlock()
+
+	valid := token != "" && subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1
+	if previousToken != "" && time.Now().Before(previousTokenExpiry) {
+		// compare both tokens in constant time
+		if subtle.ConstantTimeCompare([]byte(t), []byte(previousToken)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// IPCURL returns the URL of an endpoint of the versioned agent IPC api, for
+// instance IPCURL("agent/status").
+func IPCURL(path string) string {
+	return fmt.Sprintf("https://localhost:%v%s/%s", config.Datadog.GetInt("cmd_port"), IPCAPIPrefix, strings.TrimPrefix(path, "/"))
+}
+
 // SetDCAAuthToken sets the session token for the Cluster Agent
 // Requires that the config has been set up before calling
 func SetDCAAuthToken() error {
@@ -76,7 +169,7 @@ func Validate(w http.ResponseWriter, r *http.Request) error {
 		return err
 	}
 
-	if len(tok) < 2 || tok[1] != GetAuthToken() {
+	if len(tok) != 2 || !isValidAuthToken(tok[1]) {
 		err = fmt.Errorf("invalid session token")
 		http.Error(w, err.Error(), 403)
 	}
@@ -103,15 +196,34 @@ func ValidateDCARequest(w http.ResponseWriter, r *http.Request) error {
 		http.Error(w, err.Error(), 401)
 		return err
 	}
-	dcaToken := config.Datadog.GetString("cluster_agent.auth_token")
-	if dcaToken == "" {
-		dcaToken = GetDCAAuthToken()
-	}
 
-	if len(tok) != 2 || tok[1] != dcaToken {
+	if len(tok) != 2 || !isValidDCAToken(tok[1]) {
 		err = fmt.Errorf("invalid session token")
 		http.Error(w, err.Error(), 403)
 	}
 
 	return err
 }
+
+// isValidDCAToken checks a token against the current token of the DCA and the
+// tokens in "cluster_agent.previous_auth_tokens", which are still accepted while
+// the node agents are rolled out with a new token.
+func isValidDCAToken(token string) bool {
+	currentToken := config.Datadog.GetString("cluster_agent.auth_token")
+	if currentToken == "" {
+		currentToken = GetDCAAuthToken()
+	}
+	validTokens := append([]string{currentToken}, config.Datadog.GetStringSlice("cluster_agent.previous_auth_tokens")...)
+
+	valid := false
+	for _, validToken := range validTokens {
+		if validToken == "" {
+			continue
+		}
+		// compare all the tokens in constant time
+		if subtle.ConstantTimeCompare([]byte(token), []byte(validToken)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
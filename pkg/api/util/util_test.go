@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package util
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestValidateDCARequest(t *testing.T) {
+	config.Datadog.Set("cluster_agent.auth_token", "01234567890123456789012345678901")
+	config.Datadog.Set("cluster_agent.previous_auth_tokens", []string{"abcdefghijabcdefghijabcdefghij01"})
+	defer config.Datadog.Set("cluster_agent.auth_token", "")
+	defer config.Datadog.Set("cluster_agent.previous_auth_tokens", []string{})
+
+	for _, tc := range []struct {
+		auth string
+		code int
+	}{
+		{"Bearer 01234567890123456789012345678901", http.StatusOK},
+		{"Bearer abcdefghijabcdefghijabcdefghij01", http.StatusOK},
+		{"Bearer 0123456789", http.StatusForbidden},
+		{"Bearer", http.StatusForbidden},
+		{"Basic 01234567890123456789012345678901", http.StatusUnauthorized},
+		{"", http.StatusUnauthorized},
+	} {
+		t.Run(tc.auth, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/metadata/node/pod", nil)
+			if tc.auth != "" {
+				r.Header.Set("Authorization", tc.auth)
+			}
+			w := httptest.NewRecorder()
+			ValidateDCARequest(w, r)
+			assert.Equal(t, tc.code, w.Code)
+		})
+	}
+}
+
+// setTestAuthToken makes the auth token file a temporary one and sets its token
+func setTestAuthToken(t *testing.T, authToken string) func() {
+	testDir, err := ioutil.TempDir("", "fake-datadog-etc-")
+	require.Nil(t, err)
+	tokenPath := filepath.Join(testDir, "auth_token")
+	require.Nil(t, ioutil.WriteFile(tokenPath, []byte(authToken), 0600))
+	config.Datadog.Set("auth_token_file_path", tokenPath)
+
+	token, previousToken, previousTokenExpiry = "", "", time.Time{}
+	require.Nil(t, SetAuthToken())
+	return func() {
+		token, previousToken, previousTokenExpiry = "", "", time.Time{}
+		config.Datadog.Set("auth_token_file_path", "")
+		os.RemoveAll(testDir)
+	}
+}
+
+func validate(authToken string) int {
+	r := httptest.NewRequest("GET", "/api/v1/agent/status", nil)
+	r.Header.Set("Authorization", "Bearer "+authToken)
+	w := httptest.NewRecorder()
+	Validate(w, r)
+	return w.Code
+}
+
+func TestValidateRotatedToken(t *testing.T) {
+	defer setTestAuthToken(t, "01234567890123456789012345678901")()
+	config.Datadog.Set("auth_token_rotation_grace_period", 60)
+	defer config.Datadog.Set("auth_token_rotation_grace_period", 60)
+
+	assert.Equal(t, http.StatusOK, validate("01234567890123456789012345678901"))
+	assert.Equal(t, http.StatusForbidden, validate("0123456789"))
+
+	require.Nil(t, RotateAuthToken())
+	assert.NotEqual(t, "01234567890123456789012345678901", GetAuthToken())
+	assert.Equal(t, http.StatusOK, validate(GetAuthToken()))
+	// the previous token is accepted during the grace period
+	assert.Equal(t, http.StatusOK, validate("01234567890123456789012345678901"))
+
+	config.Datadog.Set("auth_token_rotation_grace_period", 0)
+	require.Nil(t, RotateAuthToken())
+	assert.Equal(t, http.StatusOK, validate(GetAuthToken()))
+	assert.Equal(t, http.StatusForbidden, validate("01234567890123456789012345678901"))
+}
+
+func TestOnAuthTokenRotation(t *testing.T) {
+	defer setTestAuthToken(t, "01234567890123456789012345678901")()
+	defer func() { rotationHandlers = nil }()
+
+	var rotatedTo string
+	OnAuthTokenRotation(func() { rotatedTo = GetAuthToken() })
+
+	require.Nil(t, RotateAuthToken())
+	assert.NotEqual(t, "01234567890123456789012345678901", rotatedTo)
+	assert.Equal(t, GetAuthToken(), rotatedTo)
+}
+
+func TestDoGetReloadsRotatedToken(t *testing.T) {
+	defer setTestAuthToken(t, "01234567890123456789012345678901")()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abcdefghijabcdefghijabcdefghij01" {
+			http.Error(w, "invalid session token", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	// another process rotated the token
+	require.Nil(t, ioutil.WriteFile(config.Datadog.GetString("auth_token_file_path"), []byte("abcdefghijabcdefghijabcdefghij01"), 0600))
+
+	body, err := DoGet(ts.Client(), ts.URL)
+	require.Nil(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, "abcdefghijabcdefghijabcdefghij01", GetAuthToken())
+}
+
+func TestIPCURL(t *testing.T) {
+	config.Datadog.Set("cmd_port", 5101)
+	defer config.Datadog.Set("cmd_port", 5001)
+
+	assert.Equal(t, "https://localhost:5101/api/v1/agent/status", IPCURL("agent/status"))
+	assert.Equal(t, "https://localhost:5101/api/v1/check/", IPCURL("/check/"))
+}
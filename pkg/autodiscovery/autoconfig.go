@@ -19,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
 	"github.com/DataDog/datadog-agent/pkg/collector"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 )
 
@@ -80,6 +81,9 @@ type AutoConfig struct {
 	pollerActive      bool
 	health            *health.Handle
 	m                 sync.RWMutex
+	// checksLock protects config2checks and check2config, they are updated by
+	// the poller and by the config resolver
+	checksLock sync.RWMutex
 }
 
 // NewAutoConfig creates an AutoConfig instance.
@@ -254,8 +258,10 @@ func (ac *AutoConfig) getChecksFromConfigs(configs []integration.Config, populat
 			allChecks = append(allChecks, check)
 			if populateCache {
 				// store the checks we schedule for this config locally
+				ac.checksLock.Lock()
 				ac.config2checks[configDigest] = append(ac.config2checks[configDigest], check.ID())
 				ac.check2config[check.ID()] = configDigest
+				ac.checksLock.Unlock()
 			}
 		}
 	}
@@ -273,7 +279,9 @@ func (ac *AutoConfig) schedule(checks []check.Check) {
 			errorStats.setRunError(check.ID(), err.Error())
 			continue
 		}
+		ac.checksLock.RLock()
 		configDigest := ac.check2config[check.ID()]
+		ac.checksLock.RUnlock()
 		serviceID := ac.configResolver.config2Service[configDigest]
 
 		ac.configResolver.serviceToChecks[serviceID] = append(ac.configResolver.serviceToChecks[serviceID], id)
@@ -392,7 +400,9 @@ func (ac *AutoConfig) pollConfigs() {
 					for _, config := range removedConfigs {
 						// unschedule all the checks corresponding to this config
 						digest := config.Digest()
+						ac.checksLock.RLock()
 						ids := ac.config2checks[digest]
+						ac.checksLock.RUnlock()
 						stopped := map[check.ID]struct{}{}
 						for _, id := range ids {
 							// `StopCheck` might time out so we don't risk to block
@@ -407,9 +417,11 @@ func (ac *AutoConfig) pollConfigs() {
 						}
 
 						// remove the entry from `config2checks`
+						ac.checksLock.Lock()
 						if len(stopped) == len(ac.config2checks[digest]) {
 							// we managed to stop all the checks for this config
 							delete(ac.config2checks, digest)
+							ac.checksLock.Unlock()
 							delete(ac.configResolver.config2Service, digest)
 						} else {
 							// keep the checks we failed to stop in `config2checks`
@@ -420,6 +432,7 @@ func (ac *AutoConfig) pollConfigs() {
 								}
 							}
 							ac.config2checks[digest] = dangling
+							ac.checksLock.Unlock()
 						}
 
 						// if the config is a template, remove it from the cache
@@ -475,6 +488,11 @@ func (ac *AutoConfig) collect(pd *providerDescriptor) (new, removed []integratio
 // getChecks takes a check configuration and returns a slice of Check instances
 // along with any error it might happen during the process
 func (ac *AutoConfig) getChecks(config integration.Config) ([]check.Check, error) {
+	config, err := decryptConfig(config)
+	if err != nil {
+		errorStats.setConfigError(config.Name, err.Error())
+		return []check.Check{}, fmt.Errorf("unable to decrypt the secrets of config '%s': %s", config.Name, err)
+	}
 	for _, loader := range ac.loaders {
 		res, err := loader.Load(config)
 		if err == nil {
@@ -495,11 +513,59 @@ func (ac *AutoConfig) getChecks(config integration.Config) ([]check.Check, error
 	return []check.Check{}, fmt.Errorf("unable to load any check from config '%s'", config.Name)
 }
 
+// decryptConfig returns a copy of the config with the secrets of the ENC[] handles
+// of its init_config and instances, the loaded configs keep the handles.
+func decryptConfig(config integration.Config) (integration.Config, error) {
+	origin := fmt.Sprintf("config '%s'", config.Name)
+	initConfig, err := secrets.Decrypt(config.InitConfig, origin)
+	if err != nil {
+		return config, err
+	}
+	config.InitConfig = initConfig
+
+	instances := make([]integration.Data, 0, len(config.Instances))
+	for _, instance := range config.Instances {
+		decrypted, err := secrets.Decrypt(instance, origin)
+		if err != nil {
+			return config, err
+		}
+		instances = append(instances, decrypted)
+	}
+	config.Instances = instances
+	return config, nil
+}
+
 // GetLoadedConfigs returns configs loaded
 func (ac *AutoConfig) GetLoadedConfigs() []integration.Config {
 	return ac.loadedConfigs
 }
 
+// GetScheduledChecks returns the IDs of the checks scheduled for each loaded
+// config, by config digest
+func (ac *AutoConfig) GetScheduledChecks() map[string][]string {
+	ac.checksLock.RLock()
+	defer ac.checksLock.RUnlock()
+	scheduled := make(map[string][]string, len(ac.config2checks))
+	for digest, ids := range ac.config2checks {
+		for _, id := range ids {
+			scheduled[digest] = append(scheduled[digest], string(id))
+		}
+	}
+	return scheduled
+}
+
+// GetConfigServices returns the IDs of the services the templates were resolved
+// for, by config digest
+func (ac *AutoConfig) GetConfigServices() map[string]string {
+	ac.configResolver.m.Lock()
+	defer ac.configResolver.m.Unlock()
+	services := make(map[string]string, len(ac.configResolver.config2Service))
+	for digest, serviceID := range ac.configResolver.config2Service {
+		services[digest] = string(serviceID)
+	}
+	return services
+}
+
 // GetUnresolvedTemplates returns templates in cache yet to be resolved
 func (ac *AutoConfig) GetUnresolvedTemplates() map[string]integration.Config {
 	return ac.templateCache.GetUnresolvedTemplates()
@@ -507,6 +573,8 @@ func (ac *AutoConfig) GetUnresolvedTemplates() map[string]integration.Config {
 
 // unschedule removes the check to config cache mapping
 func (ac *AutoConfig) unschedule(id check.ID) {
+	ac.checksLock.Lock()
+	defer ac.checksLock.Unlock()
 	delete(ac.check2config, id)
 }
 
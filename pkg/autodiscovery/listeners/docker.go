@@ -441,7 +441,7 @@ func parseDockerPort(port nat.Port) ([]int, error) {
 // GetTags retrieves tags using the Tagger
 func (s *DockerService) GetTags() ([]string, error) {
 	entity := docker.ContainerIDToEntityName(string(s.ID))
-	tags, err := tagger.Tag(entity, tagger.IsFullCardinality())
+	tags, err := tagger.Tag(entity, tagger.ChecksCardinality)
 	if err != nil {
 		return []string{}, err
 	}
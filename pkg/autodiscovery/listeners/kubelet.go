@@ -238,5 +238,5 @@ func (s *PodContainerService) GetPorts() ([]int, error) {
 
 // GetTags retrieves tags using the Tagger
 func (s *PodContainerService) GetTags() ([]string, error) {
-	return tagger.Tag(string(s.ID), tagger.IsFullCardinality())
+	return tagger.Tag(string(s.ID), tagger.ChecksCardinality)
 }
@@ -0,0 +1,248 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package autoprofile captures the profiles of the agent to disk when its CPU
+// or its memory stays above a threshold, so the intermittent spikes can be
+// diagnosed after the fact. The captures are rotated and added to the flares.
+package autoprofile
+
+import (
+	"expvar"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/shirou/gopsutil/process"
+)
+
+// captureTimeFormat names the directory of a capture, the names sort in the
+// order of the captures
+const captureTimeFormat = "20060102-150405"
+
+var autoprofileExpvar = expvar.NewMap("autoprofile")
+
+// for testing purpose
+var currentUsage = processUsage
+
+// Config holds the thresholds and the retention of the captures
+type Config struct {
+	// Dir is the directory of the captures, one sub-directory per capture
+	Dir string
+	// CPUThreshold is the CPU usage in percent of one core, 0 disables it
+	CPUThreshold float64
+	// RSSThreshold is the RSS in bytes, 0 disables it
+	RSSThreshold uint64
+	// Sustained is how long a threshold must be exceeded to capture
+	Sustained time.Duration
+	// Interval is the period of the checks of the usage
+	Interval time.Duration
+	// CPUProfileDuration is the duration of the CPU profile of a capture
+	CPUProfileDuration time.Duration
+	// Cooldown is the minimum time between two captures
+	Cooldown time.Duration
+	// MaxCaptures is the number of captures kept, the oldest are removed
+	MaxCaptures int
+}
+
+// Profiler checks the usage of the agent on an interval and captures its
+// profiles when a threshold is exceeded for long enough
+type Profiler struct {
+	cfg  Config
+	stop chan struct{}
+
+	lastCPUTime float64
+	lastCheck   time.Time
+	cpuSince    time.Time // zero while the CPU is under its threshold
+	rssSince    time.Time // zero while the RSS is under its threshold
+	lastCapture time.Time
+}
+
+// NewProfiler returns a Profiler capturing to cfg.Dir
+func NewProfiler(cfg Config) (*Profiler, error) {
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("invalid check interval %s, it must be positive", cfg.Interval)
+	}
+	if cfg.MaxCaptures <= 0 {
+		return nil, fmt.Errorf("invalid number of captures kept %d, it must be positive", cfg.MaxCaptures)
+	}
+	return &Profiler{
+		cfg:  cfg,
+		stop: make(chan struct{}),
+	}, nil
+}
+
+// Start checks the usage in the background until Stop is called
+func (p *Profiler) Start() {
+	log.Infof("Capturing the profiles of the agent to %s when its usage is above the thresholds for %s", p.cfg.Dir, p.cfg.Sustained)
+	go func() {
+		ticker := time.NewTicker(p.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				if reason := p.check(now); reason != "" {
+					p.capture(now, reason)
+				}
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops checking the usage, a CPU profile being captured is cut short
+func (p *Profiler) Stop() {
+	close(p.stop)
+}
+
+// check updates the time the thresholds are exceeded since and returns the
+// reason of a capture, or an empty string when no capture is due
+func (p *Profiler) check(now time.Time) string {
+	cpuTime, rss, err := currentUsage()
+	if err != nil {
+		log.Debugf("Could not get the usage of the agent: %s", err)
+		return ""
+	}
+
+	var cpu float64
+	if !p.lastCheck.IsZero() {
+		cpu = 100 * (cpuTime - p.lastCPUTime) / now.Sub(p.lastCheck).Seconds()
+	}
+	p.lastCPUTime = cpuTime
+	p.lastCheck = now
+
+	p.cpuSince = exceededSince(p.cpuSince, now, p.cfg.CPUThreshold > 0 && cpu >= p.cfg.CPUThreshold)
+	p.rssSince = exceededSince(p.rssSince, now, p.cfg.RSSThreshold > 0 && rss >= p.cfg.RSSThreshold)
+
+	if !p.lastCapture.IsZero() && now.Sub(p.lastCapture) < p.cfg.Cooldown {
+		return ""
+	}
+	switch {
+	case !p.cpuSince.IsZero() && now.Sub(p.cpuSince) >= p.cfg.Sustained:
+		return fmt.Sprintf("the CPU usage is %.0f%%, above %.0f%% since %s", cpu, p.cfg.CPUThreshold, p.cpuSince.Format(time.RFC3339))
+	case !p.rssSince.IsZero() && now.Sub(p.rssSince) >= p.cfg.Sustained:
+		return fmt.Sprintf("the RSS is %d MB, above %d MB since %s", rss/1024/1024, p.cfg.RSSThreshold/1024/1024, p.rssSince.Format(time.RFC3339))
+	}
+	return ""
+}
+
+// exceededSince returns when a threshold started to be exceeded
+func exceededSince(since, now time.Time, exceeded bool) time.Time {
+	if !exceeded {
+		return time.Time{}
+	}
+	if since.IsZero() {
+		return now
+	}
+	return since
+}
+
+// capture writes the profiles of the agent to a new directory and removes
+// the oldest captures
+func (p *Profiler) capture(now time.Time, reason string) {
+	p.lastCapture = now
+	log.Warnf("Capturing the profiles of the agent: %s", reason)
+
+	dir := filepath.Join(p.cfg.Dir, now.Format(captureTimeFormat))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.Errorf("Could not create the directory of the profiles: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "reason.txt"), []byte(reason+"\n"), 0600); err != nil {
+		log.Warnf("Could not write the reason of the capture: %s", err)
+	}
+	for _, profile := range []struct {
+		name  string
+		write func(f *os.File) error
+	}{
+		{"heap.pprof", func(f *os.File) error { return pprof.Lookup("heap").WriteTo(f, 0) }},
+		{"goroutine.txt", func(f *os.File) error { return pprof.Lookup("goroutine").WriteTo(f, 2) }},
+		{"cpu.pprof", p.writeCPUProfile},
+	} {
+		if err := writeProfile(filepath.Join(dir, profile.name), profile.write); err != nil {
+			log.Warnf("Could not capture %s: %s", profile.name, err)
+		}
+	}
+	autoprofileExpvar.Add("Captures", 1)
+
+	if err := rotate(p.cfg.Dir, p.cfg.MaxCaptures); err != nil {
+		log.Warnf("Could not remove the oldest profiles: %s", err)
+	}
+}
+
+// writeCPUProfile profiles the CPU for the configured duration, it fails
+// when a profile is already running, e.g. requested to the api
+func (p *Profiler) writeCPUProfile(f *os.File) error {
+	if err := pprof.StartCPUProfile(f); err != nil {
+		return err
+	}
+	select {
+	case <-time.After(p.cfg.CPUProfileDuration):
+	case <-p.stop:
+	}
+	pprof.StopCPUProfile()
+	return nil
+}
+
+func writeProfile(path string, write func(f *os.File) error) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return write(f)
+}
+
+// rotate removes the oldest captures of dir to keep max of them, the
+// directories which aren't named after a capture time are left untouched
+// as dir may be shared with other files of the agent
+func rotate(dir string, max int) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var captures []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(captureTimeFormat, e.Name()); err != nil {
+			continue
+		}
+		captures = append(captures, e.Name())
+	}
+	if len(captures) <= max {
+		return nil
+	}
+	sort.Strings(captures)
+	for _, name := range captures[:len(captures)-max] {
+		if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processUsage returns the CPU time in seconds and the RSS in bytes of the agent
+func processUsage() (float64, uint64, error) {
+	p, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return 0, 0, err
+	}
+	times, err := p.Times()
+	if err != nil {
+		return 0, 0, err
+	}
+	mem, err := p.MemoryInfo()
+	if err != nil {
+		return 0, 0, err
+	}
+	return times.User + times.System, mem.RSS, nil
+}
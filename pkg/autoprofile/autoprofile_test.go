@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package autoprofile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSustainedCPU(t *testing.T) {
+	var cpuTime float64
+	currentUsage = func() (float64, uint64, error) { return cpuTime, 0, nil }
+	defer func() { currentUsage = processUsage }()
+
+	p, err := NewProfiler(Config{CPUThreshold: 80, Sustained: 30 * time.Second, Cooldown: time.Hour, Interval: 10 * time.Second, MaxCaptures: 1})
+	require.NoError(t, err)
+	start := time.Now()
+	assert.Empty(t, p.check(start))
+
+	// 90% of a core for 20 seconds, then 40 seconds
+	cpuTime += 9
+	assert.Empty(t, p.check(start.Add(10*time.Second)))
+	cpuTime += 9
+	assert.Empty(t, p.check(start.Add(20*time.Second)))
+	cpuTime += 9
+	assert.Empty(t, p.check(start.Add(30*time.Second)))
+	cpuTime += 9
+	reason := p.check(start.Add(40 * time.Second))
+	assert.Contains(t, reason, "CPU usage is 90%")
+
+	// no new capture during the cooldown
+	p.lastCapture = start.Add(40 * time.Second)
+	cpuTime += 9
+	assert.Empty(t, p.check(start.Add(50*time.Second)))
+
+	// the CPU going under the threshold resets the sustained period
+	cpuTime++
+	assert.Empty(t, p.check(start.Add(60*time.Second)))
+	assert.True(t, p.cpuSince.IsZero())
+}
+
+func TestCheckSustainedRSS(t *testing.T) {
+	rss := uint64(100 * 1024 * 1024)
+	currentUsage = func() (float64, uint64, error) { return 0, rss, nil }
+	defer func() { currentUsage = processUsage }()
+
+	p, err := NewProfiler(Config{RSSThreshold: 200 * 1024 * 1024, Sustained: 10 * time.Second, Interval: 10 * time.Second, MaxCaptures: 1})
+	require.NoError(t, err)
+	start := time.Now()
+	assert.Empty(t, p.check(start))
+
+	rss = 250 * 1024 * 1024
+	assert.Empty(t, p.check(start.Add(10*time.Second)))
+	assert.Contains(t, p.check(start.Add(20*time.Second)), "RSS is 250 MB")
+}
+
+func TestNewProfilerInvalidConfig(t *testing.T) {
+	_, err := NewProfiler(Config{Interval: 0, MaxCaptures: 1})
+	assert.Error(t, err)
+	_, err = NewProfiler(Config{Interval: time.Second, MaxCaptures: 0})
+	assert.Error(t, err)
+}
+
+func TestCaptureAndRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "autoprofile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	p, err := NewProfiler(Config{Dir: dir, CPUProfileDuration: 10 * time.Millisecond, Interval: time.Second, MaxCaptures: 2})
+	require.NoError(t, err)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		p.capture(start.Add(time.Duration(i)*time.Second), fmt.Sprintf("reason %d", i))
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, start.Add(time.Second).Format(captureTimeFormat), entries[0].Name())
+	assert.Equal(t, start.Add(2*time.Second).Format(captureTimeFormat), entries[1].Name())
+
+	for _, name := range []string{"reason.txt", "heap.pprof", "goroutine.txt", "cpu.pprof"} {
+		assert.FileExists(t, filepath.Join(dir, entries[1].Name(), name))
+	}
+	reason, err := ioutil.ReadFile(filepath.Join(dir, entries[1].Name(), "reason.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "reason 2\n", string(reason))
+}
+
+func TestRotateOnlyCaptures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "autoprofile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"20180102-150405", "20180103-150405", "20180104-150405", "checks", "0-backup"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, name), 0700))
+	}
+	require.NoError(t, rotate(dir, 1))
+
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"0-backup", "20180104-150405", "checks"}, names)
+}
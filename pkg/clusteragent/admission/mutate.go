@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const socketVolumeName = "datadog-dogstatsd-socket"
+
+// standardTagsLabels are the pod labels injected as the standard tags env vars
+var standardTagsLabels = []struct {
+	label  string
+	envVar string
+}{
+	{"tags.datadoghq.com/env", "DD_ENV"},
+	{"tags.datadoghq.com/service", "DD_SERVICE"},
+	{"tags.datadoghq.com/version", "DD_VERSION"},
+}
+
+// jsonPatchOperation is an operation of a JSON patch (RFC 6902)
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// mutator computes the patch injecting the agent configuration in a pod
+type mutator struct {
+	selector   labels.Selector
+	socketPath string // empty if the socket should not be mounted
+}
+
+// mutate returns the patch injecting the agent configuration in the containers of
+// the pod, or nil if the pod does not match the selector.
+func (m *mutator) mutate(pod *v1.Pod) []jsonPatchOperation {
+	if !m.selector.Matches(labels.Set(pod.Labels)) {
+		return nil
+	}
+
+	var patch []jsonPatchOperation
+	envVars := m.envVars(pod)
+	for i, container := range pod.Spec.Containers {
+		containerPath := fmt.Sprintf("/spec/containers/%d", i)
+		patch = append(patch, addEnvVars(containerPath, container, envVars)...)
+		if m.socketPath != "" {
+			patch = append(patch, m.addSocketMount(containerPath, container)...)
+		}
+	}
+	if m.socketPath != "" {
+		patch = append(patch, m.addSocketVolume(pod)...)
+	}
+	return patch
+}
+
+// envVars returns the env vars to inject, they are resolved by the kubelet as
+// the pod is not scheduled yet.
+func (m *mutator) envVars(pod *v1.Pod) []v1.EnvVar {
+	env := []v1.EnvVar{
+		fieldRefEnvVar("DD_AGENT_HOST", "status.hostIP"),
+		fieldRefEnvVar("DD_ENTITY_ID", "metadata.uid"),
+	}
+	for _, tag := range standardTagsLabels {
+		if _, found := pod.Labels[tag.label]; found {
+			env = append(env, fieldRefEnvVar(tag.envVar, fmt.Sprintf("metadata.labels['%s']", tag.label)))
+		}
+	}
+	if m.socketPath != "" {
+		env = append(env, v1.EnvVar{Name: "DD_DOGSTATSD_SOCKET", Value: m.socketPath})
+	}
+	return env
+}
+
+func fieldRefEnvVar(name, fieldPath string) v1.EnvVar {
+	return v1.EnvVar{
+		Name: name,
+		ValueFrom: &v1.EnvVarSource{
+			FieldRef: &v1.ObjectFieldSelector{FieldPath: fieldPath},
+		},
+	}
+}
+
+// addEnvVars adds the env vars not already defined by the container.
+func addEnvVars(containerPath string, container v1.Container, envVars []v1.EnvVar) []jsonPatchOperation {
+	defined := make(map[string]bool)
+	for _, env := range container.Env {
+		defined[env.Name] = true
+	}
+
+	var patch []jsonPatchOperation
+	first := len(container.Env) == 0
+	for _, env := range envVars {
+		if defined[env.Name] {
+			continue
+		}
+		if first {
+			// the env list must be created before appending to it
+			patch = append(patch, jsonPatchOperation{Op: "add", Path: containerPath + "/env", Value: []v1.EnvVar{env}})
+			first = false
+			continue
+		}
+		patch = append(patch, jsonPatchOperation{Op: "add", Path: containerPath + "/env/-", Value: env})
+	}
+	return patch
+}
+
+func (m *mutator) addSocketMount(containerPath string, container v1.Container) []jsonPatchOperation {
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == socketVolumeName {
+			return nil
+		}
+	}
+	mount := v1.VolumeMount{Name: socketVolumeName, MountPath: filepath.Dir(m.socketPath)}
+	if len(container.VolumeMounts) == 0 {
+		return []jsonPatchOperation{{Op: "add", Path: containerPath + "/volumeMounts", Value: []v1.VolumeMount{mount}}}
+	}
+	return []jsonPatchOperation{{Op: "add", Path: containerPath + "/volumeMounts/-", Value: mount}}
+}
+
+func (m *mutator) addSocketVolume(pod *v1.Pod) []jsonPatchOperation {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == socketVolumeName {
+			return nil
+		}
+	}
+	volume := v1.Volume{
+		Name: socketVolumeName,
+		VolumeSource: v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{Path: filepath.Dir(m.socketPath)},
+		},
+	}
+	if len(pod.Spec.Volumes) == 0 {
+		return []jsonPatchOperation{{Op: "add", Path: "/spec/volumes", Value: []v1.Volume{volume}}}
+	}
+	return []jsonPatchOperation{{Op: "add", Path: "/spec/volumes/-", Value: volume}}
+}
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func newTestMutator(t *testing.T, socketPath string) *mutator {
+	selector, err := labels.Parse("admission.datadoghq.com/enabled=true")
+	require.NoError(t, err)
+	return &mutator{selector: selector, socketPath: socketPath}
+}
+
+func TestMutateNotSelected(t *testing.T) {
+	m := newTestMutator(t, "")
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{Containers: []v1.Container{{Name: "web"}}},
+	}
+	assert.Nil(t, m.mutate(pod))
+}
+
+func TestMutate(t *testing.T) {
+	m := newTestMutator(t, "/var/run/datadog/dsd.socket")
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"admission.datadoghq.com/enabled": "true",
+				"tags.datadoghq.com/env":          "prod",
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "web"},
+				{Name: "sidecar", Env: []v1.EnvVar{{Name: "DD_AGENT_HOST", Value: "custom"}}},
+			},
+		},
+	}
+
+	patch := m.mutate(pod)
+	paths := make(map[string]int)
+	for _, op := range patch {
+		assert.Equal(t, "add", op.Op)
+		paths[op.Path]++
+	}
+
+	// the env list of the first container is created, then appended to
+	assert.Equal(t, 1, paths["/spec/containers/0/env"])
+	// DD_ENTITY_ID, DD_ENV and DD_DOGSTATSD_SOCKET
+	assert.Equal(t, 3, paths["/spec/containers/0/env/-"])
+	// DD_AGENT_HOST is already defined by the sidecar
+	assert.Equal(t, 0, paths["/spec/containers/1/env"])
+	assert.Equal(t, 3, paths["/spec/containers/1/env/-"])
+
+	assert.Equal(t, 1, paths["/spec/containers/0/volumeMounts"])
+	assert.Equal(t, 1, paths["/spec/containers/1/volumeMounts"])
+	assert.Equal(t, 1, paths["/spec/volumes"])
+
+	envList := patch[0].Value.([]v1.EnvVar)
+	require.Len(t, envList, 1)
+	assert.Equal(t, "DD_AGENT_HOST", envList[0].Name)
+	assert.Equal(t, "status.hostIP", envList[0].ValueFrom.FieldRef.FieldPath)
+}
+
+func TestWebhook(t *testing.T) {
+	wh := &webhook{mutator: newTestMutator(t, "")}
+	pod, err := json.Marshal(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "web-",
+			Labels:       map[string]string{"admission.datadoghq.com/enabled": "true"},
+		},
+		Spec: v1.PodSpec{Containers: []v1.Container{{Name: "web"}}},
+	})
+	require.NoError(t, err)
+	review, err := json.Marshal(&admissionReview{
+		Request: &admissionRequest{UID: "1234", Namespace: "default", Operation: "CREATE", Object: pod},
+	})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	wh.ServeHTTP(rec, httptest.NewRequest("POST", "/injectconfig", bytes.NewReader(review)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	response := &admissionReview{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), response))
+	require.NotNil(t, response.Response)
+	assert.Equal(t, "1234", response.Response.UID)
+	assert.True(t, response.Response.Allowed)
+	require.NotNil(t, response.Response.PatchType)
+	assert.Equal(t, "JSONPatch", *response.Response.PatchType)
+
+	var patch []jsonPatchOperation
+	require.NoError(t, json.Unmarshal(response.Response.Patch, &patch))
+	assert.Len(t, patch, 2)
+
+	rec = httptest.NewRecorder()
+	wh.ServeHTTP(rec, httptest.NewRequest("POST", "/injectconfig", bytes.NewReader([]byte("{}"))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
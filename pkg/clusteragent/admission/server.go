@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"crypto/tls"
+	"fmt"
+	stdLog "log"
+	"net"
+	"net/http"
+
+	log "github.com/cihub/seelog"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var listener net.Listener
+
+// StartServer starts the admission webhook injecting the agent configuration in the
+// pods matching `admission_controller.pod_selector`, if it is enabled.
+func StartServer() error {
+	if !config.Datadog.GetBool("admission_controller.enabled") {
+		return nil
+	}
+
+	selector, err := labels.Parse(config.Datadog.GetString("admission_controller.pod_selector"))
+	if err != nil {
+		return fmt.Errorf("invalid admission_controller.pod_selector: %s", err)
+	}
+	m := &mutator{selector: selector}
+	if config.Datadog.GetBool("admission_controller.inject_dogstatsd_socket") {
+		m.socketPath = config.Datadog.GetString("admission_controller.dogstatsd_socket")
+	}
+
+	// The API Server only calls webhooks it can verify, the certificate must
+	// be signed by the caBundle of the MutatingWebhookConfiguration.
+	cert, err := tls.LoadX509KeyPair(
+		config.Datadog.GetString("admission_controller.tls_cert_file"),
+		config.Datadog.GetString("admission_controller.tls_key_file"),
+	)
+	if err != nil {
+		return fmt.Errorf("could not load the admission webhook certificate: %s", err)
+	}
+	tlsConfig := tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/injectconfig", &webhook{mutator: m})
+
+	listener, err = net.Listen("tcp", fmt.Sprintf(":%d", config.Datadog.GetInt("admission_controller.port")))
+	if err != nil {
+		return fmt.Errorf("unable to listen for the admission webhook: %v", err)
+	}
+
+	srv := &http.Server{
+		Handler:   mux,
+		ErrorLog:  stdLog.New(&config.ErrorLogWriter{}, "", 0), // log errors to seelog
+		TLSConfig: &tlsConfig,
+	}
+	go srv.Serve(tls.NewListener(listener, &tlsConfig))
+	log.Infof("Admission webhook listening on %s", listener.Addr())
+	return nil
+}
+
+// StopServer closes the listener of the admission webhook.
+func StopServer() {
+	if listener != nil {
+		listener.Close()
+	}
+}
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package admission
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	log "github.com/cihub/seelog"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const admissionVersion = "admission.k8s.io/v1beta1"
+
+// admissionReview mirrors the AdmissionReview type of the admission.k8s.io/v1beta1 API.
+type admissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *admissionRequest  `json:"request,omitempty"`
+	Response        *admissionResponse `json:"response,omitempty"`
+}
+
+type admissionRequest struct {
+	UID       string          `json:"uid"`
+	Namespace string          `json:"namespace,omitempty"`
+	Operation string          `json:"operation"`
+	Object    json.RawMessage `json:"object,omitempty"`
+}
+
+type admissionResponse struct {
+	UID       string         `json:"uid"`
+	Allowed   bool           `json:"allowed"`
+	Result    *metav1.Status `json:"status,omitempty"`
+	Patch     []byte         `json:"patch,omitempty"`
+	PatchType *string        `json:"patchType,omitempty"`
+}
+
+// webhook answers the admission reviews of the pods creations
+type webhook struct {
+	mutator *mutator
+}
+
+// ServeHTTP reads an admission review and answers with the patch of the pod. The
+// pod is always allowed: failing to inject the configuration must not prevent it
+// from being created.
+func (wh *webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	review := &admissionReview{}
+	if err = json.Unmarshal(body, review); err != nil || review.Request == nil {
+		log.Errorf("Could not decode the admission review: %v", err)
+		http.Error(w, "invalid admission review", http.StatusBadRequest)
+		return
+	}
+
+	response := &admissionResponse{
+		UID:     review.Request.UID,
+		Allowed: true,
+	}
+	pod := &v1.Pod{}
+	if err = json.Unmarshal(review.Request.Object, pod); err != nil {
+		log.Errorf("Could not decode the pod of the admission review %s: %s", review.Request.UID, err)
+		response.Result = &metav1.Status{Message: err.Error()}
+	} else if patch := wh.mutator.mutate(pod); len(patch) > 0 {
+		response.Patch, err = json.Marshal(patch)
+		if err != nil {
+			log.Errorf("Could not encode the patch of the pod %s/%s: %s", review.Request.Namespace, podName(pod), err)
+			response.Patch = nil
+		} else {
+			patchType := "JSONPatch"
+			response.PatchType = &patchType
+			log.Debugf("Injecting the agent configuration in the pod %s/%s", review.Request.Namespace, podName(pod))
+		}
+	}
+
+	payload, err := json.Marshal(&admissionReview{
+		TypeMeta: metav1.TypeMeta{Kind: "AdmissionReview", APIVersion: admissionVersion},
+		Response: response,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(payload)
+}
+
+// podName returns the name of the pod, which is not set yet when it is generated
+func podName(pod *v1.Pod) string {
+	if pod.Name != "" {
+		return pod.Name
+	}
+	return pod.GenerateName
+}
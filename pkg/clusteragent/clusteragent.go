@@ -9,6 +9,9 @@ import (
 	log "github.com/cihub/seelog"
 
 	"github.com/DataDog/datadog-agent/cmd/cluster-agent/api"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/externalmetrics"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/orchestrator"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
@@ -35,4 +38,7 @@ func Run(mOut chan<- *metrics.MetricSample, eOut chan<- metrics.Event, scOut cha
 // Stop the cluster agent
 func (a *Agent) Stop() {
 	api.StopServer()
+	externalmetrics.StopServer()
+	admission.StopServer()
+	orchestrator.Stop()
 }
@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+)
+
+// datadogMetricPrefix is the prefix of the external metric names referencing a
+// DatadogMetric, as `datadogmetric@<namespace>:<name>`.
+const datadogMetricPrefix = "datadogmetric@"
+
+// datadogMetricsClient reads and writes the DatadogMetrics, implemented by the APIClient.
+type datadogMetricsClient interface {
+	ListDatadogMetrics() ([]apiserver.DatadogMetric, error)
+	UpdateDatadogMetricStatus(metric *apiserver.DatadogMetric) error
+}
+
+// datadogMetricController periodically resolves the queries of the DatadogMetrics
+// and writes their values to their status. Only the leader queries Datadog, every
+// replica serves the values of the statuses it lists.
+type datadogMetricController struct {
+	client        datadogClient
+	kubeClient    datadogMetricsClient
+	isLeader      func() bool
+	refreshPeriod time.Duration
+	bucketSize    time.Duration
+	maxAge        time.Duration
+
+	m      sync.RWMutex
+	values map[string]MetricValue // keyed by namespace:name
+}
+
+// run reconciles the DatadogMetrics every refresh period until stop is closed.
+func (c *datadogMetricController) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.refreshPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.reconcile(time.Now())
+		case <-stop:
+			return
+		}
+	}
+}
+
+// reconcile lists the DatadogMetrics, resolves their queries if the agent is the
+// leader, and replaces the values served to the autoscalers.
+func (c *datadogMetricController) reconcile(now time.Time) {
+	metrics, err := c.kubeClient.ListDatadogMetrics()
+	if err != nil {
+		log.Errorf("Could not list the DatadogMetrics: %s", err)
+		return
+	}
+
+	leader := c.isLeader()
+	values := make(map[string]MetricValue, len(metrics))
+	for i := range metrics {
+		metric := &metrics[i]
+		if leader {
+			c.resolve(metric, now)
+		}
+		values[metric.Namespace+":"+metric.Name] = metricValue(metric)
+	}
+
+	c.m.Lock()
+	c.values = values
+	c.m.Unlock()
+}
+
+// resolve queries the value of a DatadogMetric, its status is only written if it changed.
+func (c *datadogMetricController) resolve(metric *apiserver.DatadogMetric, now time.Time) {
+	status := c.query(metric.Spec.Query, metric.Status, now)
+	if status.Value == metric.Status.Value && status.Valid == metric.Status.Valid &&
+		status.Error == metric.Status.Error && status.UpdateTime.Time.Equal(metric.Status.UpdateTime.Time) {
+		return
+	}
+	metric.Status = status
+	if err := c.kubeClient.UpdateDatadogMetricStatus(metric); err != nil {
+		// the DatadogMetric may have been modified, it is resolved again at the next refresh
+		log.Warnf("Could not update the status of the DatadogMetric %s/%s: %s", metric.Namespace, metric.Name, err)
+	}
+}
+
+// query returns the status of a DatadogMetric from the last point of its query.
+// The previous value is kept when the query fails, until it is too old.
+func (c *datadogMetricController) query(query string, previous apiserver.DatadogMetricStatus, now time.Time) apiserver.DatadogMetricStatus {
+	status := previous
+	series, err := c.client.QueryMetrics(now.Add(-c.bucketSize).Unix(), now.Unix(), query)
+	switch {
+	case err != nil:
+		status.Error = fmt.Sprintf("could not query Datadog: %s", err)
+	case len(series) != 1:
+		status.Error = fmt.Sprintf("the query returned %d series, it must return exactly one", len(series))
+	default:
+		value, timestamp, found := lastPoint(series[0])
+		if !found {
+			status.Error = "no recent datapoint"
+			break
+		}
+		status.Value = strconv.FormatFloat(value, 'f', -1, 64)
+		status.UpdateTime = metav1.NewTime(timestamp)
+		status.Error = ""
+	}
+	if status.Error != "" {
+		log.Debugf("Could not resolve the query %q: %s", query, status.Error)
+	}
+	status.Valid = status.Value != "" && now.Sub(status.UpdateTime.Time) <= c.maxAge
+	return status
+}
+
+// get returns the value of the DatadogMetric referenced by `<namespace>:<name>`.
+// Values too old are not valid, in case the leader stopped updating them.
+func (c *datadogMetricController) get(ref string, now time.Time) MetricValue {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	value, found := c.values[ref]
+	if !found {
+		return MetricValue{MetricName: datadogMetricPrefix + ref}
+	}
+	value.Valid = value.Valid && now.Sub(value.Timestamp) <= c.maxAge
+	return value
+}
+
+// metricValue converts the status of a DatadogMetric to the value served to the autoscalers.
+func metricValue(metric *apiserver.DatadogMetric) MetricValue {
+	value := MetricValue{
+		MetricName: fmt.Sprintf("%s%s:%s", datadogMetricPrefix, metric.Namespace, metric.Name),
+		Timestamp:  metric.Status.UpdateTime.Time,
+		Valid:      metric.Status.Valid,
+	}
+	if !value.Valid {
+		return value
+	}
+	var err error
+	value.Value, err = strconv.ParseFloat(strings.TrimSpace(metric.Status.Value), 64)
+	if err != nil {
+		log.Debugf("Invalid value %q in the status of the DatadogMetric %s/%s", metric.Status.Value, metric.Namespace, metric.Name)
+		value.Valid = false
+	}
+	return value
+}
+
+// isLeader returns whether the agent resolves the DatadogMetrics. Without leader
+// election, the cluster agent is expected to run as a single replica.
+func isLeader() bool {
+	if !config.Datadog.GetBool("leader_election") {
+		return true
+	}
+	leaderEngine, err := leaderelection.GetLeaderEngine()
+	if err != nil {
+		log.Warnf("Failed to instantiate the Leader Elector, not resolving the DatadogMetrics: %s", err)
+		return false
+	}
+	if err = leaderEngine.EnsureLeaderElectionRuns(); err != nil {
+		log.Warnf("Leader Election process failed to start, not resolving the DatadogMetrics: %s", err)
+		return false
+	}
+	return leaderEngine.IsLeader()
+}
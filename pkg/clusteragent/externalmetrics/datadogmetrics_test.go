@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	datadog "gopkg.in/zorkian/go-datadog-api.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+type fakeDatadogMetricsClient struct {
+	metrics []apiserver.DatadogMetric
+	updated []apiserver.DatadogMetric
+}
+
+func (c *fakeDatadogMetricsClient) ListDatadogMetrics() ([]apiserver.DatadogMetric, error) {
+	metrics := make([]apiserver.DatadogMetric, len(c.metrics))
+	copy(metrics, c.metrics)
+	return metrics, nil
+}
+
+func (c *fakeDatadogMetricsClient) UpdateDatadogMetricStatus(metric *apiserver.DatadogMetric) error {
+	c.updated = append(c.updated, *metric)
+	for i := range c.metrics {
+		if c.metrics[i].Namespace == metric.Namespace && c.metrics[i].Name == metric.Name {
+			c.metrics[i].Status = metric.Status
+		}
+	}
+	return nil
+}
+
+func newDatadogMetric(namespace, name, query string) apiserver.DatadogMetric {
+	return apiserver.DatadogMetric{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       apiserver.DatadogMetricSpec{Query: query},
+	}
+}
+
+func TestDatadogMetricReconcile(t *testing.T) {
+	now := time.Now()
+	query := "sum:nginx.net.request_per_s{kube_service:web}"
+	kubeClient := &fakeDatadogMetricsClient{
+		metrics: []apiserver.DatadogMetric{newDatadogMetric("app", "web-requests", query)},
+	}
+	client := &fakeDatadogClient{
+		series: []datadog.Series{newSerie("nginx.net.request_per_s", "kube_service:web", now.Add(-30*time.Second), 42.5)},
+	}
+	leader := false
+	c := &datadogMetricController{
+		client:     client,
+		kubeClient: kubeClient,
+		isLeader:   func() bool { return leader },
+		bucketSize: 5 * time.Minute,
+		maxAge:     2 * time.Minute,
+	}
+
+	// only the leader queries Datadog
+	c.reconcile(now)
+	assert.Len(t, client.queries, 0)
+	assert.False(t, c.get("app:web-requests", now).Valid)
+
+	leader = true
+	c.reconcile(now)
+	assert.Equal(t, []string{query}, client.queries)
+	require.Len(t, kubeClient.updated, 1)
+	status := kubeClient.updated[0].Status
+	assert.Equal(t, "42.5", status.Value)
+	assert.True(t, status.Valid)
+	assert.Empty(t, status.Error)
+
+	value := c.get("app:web-requests", now)
+	assert.True(t, value.Valid)
+	assert.Equal(t, 42.5, value.Value)
+	assert.Equal(t, "datadogmetric@app:web-requests", value.MetricName)
+	assert.False(t, c.get("app:unknown", now).Valid)
+
+	// the status is not written again if it did not change
+	c.reconcile(now)
+	assert.Len(t, kubeClient.updated, 1)
+
+	// the previous value is kept when the query fails, until it is too old
+	client.err = errors.New("timeout")
+	c.reconcile(now.Add(time.Minute))
+	require.Len(t, kubeClient.updated, 2)
+	status = kubeClient.updated[1].Status
+	assert.Equal(t, "42.5", status.Value)
+	assert.True(t, status.Valid)
+	assert.Equal(t, "could not query Datadog: timeout", status.Error)
+
+	c.reconcile(now.Add(5 * time.Minute))
+	require.Len(t, kubeClient.updated, 3)
+	assert.False(t, kubeClient.updated[2].Status.Valid)
+	assert.False(t, c.get("app:web-requests", now.Add(5*time.Minute)).Valid)
+
+	// a query must return a single serie
+	client.err = nil
+	client.series = append(client.series, client.series[0])
+	status = c.query(query, apiserver.DatadogMetricStatus{}, now)
+	assert.False(t, status.Valid)
+	assert.Equal(t, "the query returned 2 series, it must return exactly one", status.Error)
+}
+
+func TestGetDatadogMetric(t *testing.T) {
+	now := time.Now()
+	c := &datadogMetricController{
+		maxAge: 2 * time.Minute,
+		values: map[string]MetricValue{
+			"app:web-requests": {MetricName: "datadogmetric@app:web-requests", Value: 3, Timestamp: now, Valid: true},
+		},
+	}
+	p := &provider{store: newStore(100), datadogMetrics: c}
+	r := mux.NewRouter()
+	p.setupHandlers(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/apis/external.metrics.k8s.io/v1beta1/namespaces/app/datadogmetric@app:web-requests", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	list := &externalMetricValueList{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), list))
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, int64(3000), list.Items[0].Value.MilliValue())
+
+	// DatadogMetrics are not registered in the store
+	assert.Len(t, p.store.list(), 0)
+
+	// the DatadogMetrics of other namespaces can not be read
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/apis/external.metrics.k8s.io/v1beta1/namespaces/other/datadogmetric@app:web-requests", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
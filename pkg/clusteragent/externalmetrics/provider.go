@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+const (
+	groupName    = "external.metrics.k8s.io"
+	groupVersion = groupName + "/v1beta1"
+)
+
+// externalMetricValueList mirrors the ExternalMetricValueList type of the
+// external.metrics.k8s.io/v1beta1 API.
+type externalMetricValueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []externalMetricValue `json:"items"`
+}
+
+// externalMetricValue mirrors the ExternalMetricValue type of the
+// external.metrics.k8s.io/v1beta1 API.
+type externalMetricValue struct {
+	metav1.TypeMeta `json:",inline"`
+	MetricName      string            `json:"metricName"`
+	MetricLabels    map[string]string `json:"metricLabels"`
+	Timestamp       metav1.Time       `json:"timestamp"`
+	Value           resource.Quantity `json:"value"`
+}
+
+// provider serves the external metrics API from the values kept in the store, and
+// from the statuses of the DatadogMetrics if datadogMetrics is set.
+type provider struct {
+	store          *store
+	datadogMetrics *datadogMetricController
+}
+
+func (p *provider) setupHandlers(r *mux.Router) {
+	r.HandleFunc("/apis/"+groupName, p.getAPIGroup).Methods("GET")
+	r.HandleFunc("/apis/"+groupVersion, p.getAPIResources).Methods("GET")
+	r.HandleFunc("/apis/"+groupVersion+"/namespaces/{namespace}/{metricName}", p.getExternalMetric).Methods("GET")
+}
+
+// getAPIGroup answers the discovery of the API group, the aggregation layer of
+// the API Server uses it to check that the provider is available.
+func (p *provider) getAPIGroup(w http.ResponseWriter, r *http.Request) {
+	version := metav1.GroupVersionForDiscovery{GroupVersion: groupVersion, Version: "v1beta1"}
+	writeJSON(w, http.StatusOK, &metav1.APIGroup{
+		TypeMeta:         metav1.TypeMeta{Kind: "APIGroup", APIVersion: "v1"},
+		Name:             groupName,
+		Versions:         []metav1.GroupVersionForDiscovery{version},
+		PreferredVersion: version,
+	})
+}
+
+// getAPIResources answers the discovery of the API version. External metrics
+// are not listed as they are only known once requested.
+func (p *provider) getAPIResources(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: groupVersion,
+		APIResources: []metav1.APIResource{},
+	})
+}
+
+// getExternalMetric returns the last valid value of a metric. Datadog metrics are
+// not namespaced, the namespace of the Horizontal Pod Autoscaler is ignored. The
+// metrics named `datadogmetric@<namespace>:<name>` are read from the DatadogMetrics,
+// the namespace must be the one of the Horizontal Pod Autoscaler.
+func (p *provider) getExternalMetric(w http.ResponseWriter, r *http.Request) {
+	metricName := mux.Vars(r)["metricName"]
+	metricLabels, err := parseLabelSelector(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, err.Error())
+		return
+	}
+
+	list := &externalMetricValueList{
+		TypeMeta: metav1.TypeMeta{Kind: "ExternalMetricValueList", APIVersion: groupVersion},
+		Items:    []externalMetricValue{},
+	}
+	var metric MetricValue
+	if ref := strings.TrimPrefix(metricName, datadogMetricPrefix); ref != metricName && p.datadogMetrics != nil {
+		// an autoscaler can only read the DatadogMetrics of its namespace
+		if namespace := mux.Vars(r)["namespace"]; !strings.HasPrefix(ref, namespace+":") {
+			writeStatus(w, http.StatusForbidden, metav1.StatusReasonForbidden, fmt.Sprintf("the DatadogMetric %s is not in the namespace %s", ref, namespace))
+			return
+		}
+		// the query is defined by the DatadogMetric, the selector is ignored
+		metric = p.datadogMetrics.get(ref, time.Now())
+	} else {
+		metric = p.store.get(metricName, metricLabels, time.Now())
+	}
+	if metric.Valid {
+		list.Items = append(list.Items, externalMetricValue{
+			TypeMeta:     metav1.TypeMeta{Kind: "ExternalMetricValue", APIVersion: groupVersion},
+			MetricName:   metricName,
+			MetricLabels: metricLabels,
+			Timestamp:    metav1.NewTime(metric.Timestamp),
+			Value:        *resource.NewMilliQuantity(int64(metric.Value*1000), resource.DecimalSI),
+		})
+	} else {
+		log.Debugf("No valid value yet for the external metric %s", buildQuery(metricName, metricLabels))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// parseLabelSelector converts a label selector to the tags of the query, only
+// equality requirements can be translated.
+func parseLabelSelector(labelSelector string) (map[string]string, error) {
+	metricLabels := make(map[string]string)
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	requirements, selectable := selector.Requirements()
+	if !selectable {
+		return metricLabels, nil
+	}
+	for _, requirement := range requirements {
+		values := requirement.Values().List()
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			if len(values) != 1 {
+				return nil, fmt.Errorf("label %s must have exactly one value", requirement.Key())
+			}
+			metricLabels[requirement.Key()] = values[0]
+		default:
+			return nil, fmt.Errorf("unsupported operator %s for the label %s, only equality is supported", requirement.Operator(), requirement.Key())
+		}
+	}
+	return metricLabels, nil
+}
+
+// writeStatus writes a failure status of the API.
+func writeStatus(w http.ResponseWriter, code int, reason metav1.StatusReason, message string) {
+	writeJSON(w, code, &metav1.Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusFailure,
+		Message:  message,
+		Reason:   reason,
+		Code:     int32(code),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		log.Errorf("Could not marshal the external metrics API response: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(payload)
+}
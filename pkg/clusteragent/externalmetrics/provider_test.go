@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	labels, err := parseLabelSelector("")
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+
+	labels, err = parseLabelSelector("app=web,env in (prod)")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "web", "env": "prod"}, labels)
+
+	_, err = parseLabelSelector("app!=web")
+	assert.Error(t, err)
+
+	_, err = parseLabelSelector("env in (prod,staging)")
+	assert.Error(t, err)
+}
+
+func TestGetExternalMetric(t *testing.T) {
+	s := newStore(100)
+	p := &provider{store: s}
+	r := mux.NewRouter()
+	p.setupHandlers(r)
+
+	get := func(url string) (int, *externalMetricValueList) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		list := &externalMetricValueList{}
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), list))
+		}
+		return rec.Code, list
+	}
+	url := "/apis/external.metrics.k8s.io/v1beta1/namespaces/default/requests?labelSelector=app%3Dweb"
+
+	// the first request registers the metric
+	code, list := get(url)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, list.Items, 0)
+	require.Len(t, s.list(), 1)
+
+	s.update("requests", map[string]string{"app": "web"}, 1.5, time.Now())
+	code, list = get(url)
+	assert.Equal(t, http.StatusOK, code)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "requests", list.Items[0].MetricName)
+	assert.Equal(t, map[string]string{"app": "web"}, list.Items[0].MetricLabels)
+	assert.Equal(t, int64(1500), list.Items[0].Value.MilliValue())
+
+	code, _ = get("/apis/external.metrics.k8s.io/v1beta1/namespaces/default/requests?labelSelector=app%21%3Dweb")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = get("/apis/external.metrics.k8s.io/v1beta1")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestStoreMaxSize(t *testing.T) {
+	s := newStore(1)
+	now := time.Now()
+	s.get("requests", map[string]string{"app": "web"}, now)
+	metric := s.get("errors", map[string]string{"app": "web"}, now)
+	assert.False(t, metric.Valid)
+	require.Len(t, s.list(), 1)
+	assert.Equal(t, "requests", s.list()[0].MetricName)
+
+	// the metrics already registered are still served
+	s.update("requests", map[string]string{"app": "web"}, 1.5, now)
+	assert.True(t, s.get("requests", map[string]string{"app": "web"}, now).Valid)
+}
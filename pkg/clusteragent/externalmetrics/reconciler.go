@@ -0,0 +1,157 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+	datadog "gopkg.in/zorkian/go-datadog-api.v2"
+)
+
+// datadogClient is the subset of the Datadog API client used to query metrics.
+type datadogClient interface {
+	QueryMetrics(from, to int64, query string) ([]datadog.Series, error)
+}
+
+// reconciler periodically queries Datadog for the values of the registered
+// metrics. Metrics are queried in batches to limit the number of API calls.
+type reconciler struct {
+	store         *store
+	client        datadogClient
+	refreshPeriod time.Duration
+	bucketSize    time.Duration
+	maxAge        time.Duration
+	batchSize     int
+}
+
+// run reconciles the metrics every refresh period until stop is closed.
+func (r *reconciler) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.refreshPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.reconcile(time.Now())
+		case <-stop:
+			return
+		}
+	}
+}
+
+// reconcile drops the metrics not requested anymore and updates the values of the others.
+func (r *reconciler) reconcile(now time.Time) {
+	r.store.purge(now.Add(-r.maxAge))
+
+	metrics := r.store.list()
+	for start := 0; start < len(metrics); start += r.batchSize {
+		end := start + r.batchSize
+		if end > len(metrics) {
+			end = len(metrics)
+		}
+		r.queryBatch(metrics[start:end], now)
+	}
+
+	// previous values are kept when a query fails, until they are too old
+	r.invalidateStale(now)
+}
+
+// queryBatch queries a batch of metrics in a single call, the series returned by
+// Datadog are matched to the metrics with their name and scope.
+func (r *reconciler) queryBatch(metrics []MetricValue, now time.Time) {
+	queries := make([]string, 0, len(metrics))
+	for _, metric := range metrics {
+		queries = append(queries, buildQuery(metric.MetricName, metric.Labels))
+	}
+
+	series, err := r.client.QueryMetrics(now.Add(-r.bucketSize).Unix(), now.Unix(), strings.Join(queries, ","))
+	if err != nil {
+		log.Errorf("Could not query %d external metrics from Datadog: %s", len(metrics), err)
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, serie := range series {
+		if serie.Metric == nil || serie.Scope == nil {
+			continue
+		}
+		value, timestamp, found := lastPoint(serie)
+		if !found {
+			continue
+		}
+		key := scopeKey(*serie.Metric, *serie.Scope)
+		for _, metric := range metrics {
+			if metricKey(metric.MetricName, metric.Labels) == key {
+				r.store.update(metric.MetricName, metric.Labels, value, timestamp)
+				seen[key] = true
+			}
+		}
+	}
+
+	for _, metric := range metrics {
+		if !seen[metricKey(metric.MetricName, metric.Labels)] {
+			log.Debugf("No recent value for the external metric %s", buildQuery(metric.MetricName, metric.Labels))
+		}
+	}
+}
+
+// invalidateStale invalidates the metrics which last datapoint is older than the max age,
+// the Horizontal Pod Autoscalers should not scale based on outdated values.
+func (r *reconciler) invalidateStale(now time.Time) {
+	for _, metric := range r.store.list() {
+		if metric.Valid && now.Sub(metric.Timestamp) > r.maxAge {
+			r.store.invalidate(metric.MetricName, metric.Labels)
+		}
+	}
+}
+
+// buildQuery returns the Datadog query of a metric, its labels are used as the scope.
+func buildQuery(metricName string, labels map[string]string) string {
+	tags := make([]string, 0, len(labels))
+	for k, v := range labels {
+		tags = append(tags, fmt.Sprintf("%s:%s", k, v))
+	}
+	sort.Strings(tags)
+	scope := "*"
+	if len(tags) > 0 {
+		scope = strings.Join(tags, ",")
+	}
+	return fmt.Sprintf("avg:%s{%s}", metricName, scope)
+}
+
+// scopeKey returns the store key of a serie from its metric name and scope.
+func scopeKey(metricName, scope string) string {
+	labels := make(map[string]string)
+	if scope != "*" {
+		for _, tag := range strings.Split(scope, ",") {
+			parts := strings.SplitN(tag, ":", 2)
+			if len(parts) == 2 {
+				labels[parts[0]] = parts[1]
+			} else {
+				labels[parts[0]] = ""
+			}
+		}
+	}
+	return metricKey(metricName, labels)
+}
+
+// lastPoint returns the last non-empty point of a serie.
+func lastPoint(serie datadog.Series) (float64, time.Time, bool) {
+	for i := len(serie.Points) - 1; i >= 0; i-- {
+		point := serie.Points[i]
+		if point[0] == nil || point[1] == nil {
+			continue
+		}
+		// timestamps are in milliseconds
+		return *point[1], time.Unix(0, int64(*point[0])*int64(time.Millisecond)), true
+	}
+	return 0, time.Time{}, false
+}
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	datadog "gopkg.in/zorkian/go-datadog-api.v2"
+)
+
+type fakeDatadogClient struct {
+	queries []string
+	series  []datadog.Series
+	err     error
+}
+
+func (c *fakeDatadogClient) QueryMetrics(from, to int64, query string) ([]datadog.Series, error) {
+	c.queries = append(c.queries, query)
+	return c.series, c.err
+}
+
+func newSerie(metric, scope string, ts time.Time, value float64) datadog.Series {
+	timestamp := float64(ts.UnixNano() / int64(time.Millisecond))
+	return datadog.Series{
+		Metric: &metric,
+		Scope:  &scope,
+		Points: []datadog.DataPoint{{&timestamp, &value}},
+	}
+}
+
+func TestBuildQuery(t *testing.T) {
+	assert.Equal(t, "avg:nginx.net.request_per_s{*}", buildQuery("nginx.net.request_per_s", nil))
+	assert.Equal(t, "avg:nginx.net.request_per_s{app:web,env:prod}", buildQuery("nginx.net.request_per_s", map[string]string{"env": "prod", "app": "web"}))
+}
+
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	s := newStore(100)
+	s.get("requests", map[string]string{"app": "web"}, now)
+	s.get("requests", map[string]string{"app": "api"}, now)
+	s.get("queue.size", nil, now)
+
+	client := &fakeDatadogClient{
+		series: []datadog.Series{
+			// scopes are not necessarily ordered
+			newSerie("requests", "app:web", now.Add(-30*time.Second), 42),
+			newSerie("queue.size", "*", now.Add(-10*time.Minute), 7),
+		},
+	}
+	r := &reconciler{
+		store:     s,
+		client:    client,
+		maxAge:    2 * time.Minute,
+		batchSize: 2,
+	}
+	r.reconcile(now)
+
+	// 3 metrics by batches of 2
+	require.Len(t, client.queries, 2)
+	assert.Len(t, strings.Split(client.queries[0], "},"), 2)
+
+	web := s.get("requests", map[string]string{"app": "web"}, now)
+	assert.True(t, web.Valid)
+	assert.Equal(t, 42.0, web.Value)
+
+	api := s.get("requests", map[string]string{"app": "api"}, now)
+	assert.False(t, api.Valid)
+
+	// the value is older than the max age
+	queue := s.get("queue.size", nil, now)
+	assert.False(t, queue.Valid)
+
+	// values are kept on errors until they are too old
+	client.err = errors.New("timeout")
+	r.reconcile(now.Add(time.Minute))
+	web = s.get("requests", map[string]string{"app": "web"}, now.Add(time.Minute))
+	assert.True(t, web.Valid)
+	r.reconcile(now.Add(2 * time.Minute))
+	web = s.get("requests", map[string]string{"app": "web"}, now.Add(2*time.Minute))
+	assert.False(t, web.Valid)
+}
+
+func TestReconcilePurge(t *testing.T) {
+	now := time.Now()
+	s := newStore(100)
+	s.get("requests", map[string]string{"app": "web"}, now.Add(-5*time.Minute))
+	s.get("requests", map[string]string{"app": "api"}, now)
+
+	r := &reconciler{
+		store:     s,
+		client:    &fakeDatadogClient{},
+		maxAge:    2 * time.Minute,
+		batchSize: 10,
+	}
+	r.reconcile(now)
+
+	metrics := s.list()
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]string{"app": "api"}, metrics[0].Labels)
+}
@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	stdLog "log"
+	"net"
+	"net/http"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/gorilla/mux"
+	datadog "gopkg.in/zorkian/go-datadog-api.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+var (
+	listener net.Listener
+	stop     chan struct{}
+)
+
+// StartServer starts the External Metrics Provider if it is enabled: the metrics
+// requested by the Horizontal Pod Autoscalers, and the queries of the DatadogMetrics
+// if `external_metrics_provider.use_datadogmetric_crd` is set, are queried from
+// Datadog and served on the external.metrics.k8s.io API.
+func StartServer() error {
+	if !config.Datadog.GetBool("external_metrics_provider.enabled") {
+		return nil
+	}
+	if config.Datadog.GetString("app_key") == "" {
+		return fmt.Errorf("an application key is required to query metrics from Datadog")
+	}
+
+	metricsStore := newStore(config.Datadog.GetInt("external_metrics_provider.max_metrics"))
+	rec := &reconciler{
+		store:         metricsStore,
+		client:        datadog.NewClient(config.Datadog.GetString("api_key"), config.Datadog.GetString("app_key")),
+		refreshPeriod: time.Duration(config.Datadog.GetInt64("external_metrics_provider.refresh_period")) * time.Second,
+		bucketSize:    time.Duration(config.Datadog.GetInt64("external_metrics_provider.bucket_size")) * time.Second,
+		maxAge:        time.Duration(config.Datadog.GetInt64("external_metrics_provider.max_age")) * time.Second,
+		batchSize:     config.Datadog.GetInt("external_metrics_provider.batch_size"),
+	}
+	if rec.batchSize <= 0 {
+		rec.batchSize = 1
+	}
+
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return fmt.Errorf("unable to reach the apiserver: %v", err)
+	}
+
+	p := &provider{store: metricsStore}
+	if config.Datadog.GetBool("external_metrics_provider.use_datadogmetric_crd") {
+		p.datadogMetrics = &datadogMetricController{
+			client:        rec.client,
+			kubeClient:    ac,
+			isLeader:      isLeader,
+			refreshPeriod: rec.refreshPeriod,
+			bucketSize:    rec.bucketSize,
+			maxAge:        rec.maxAge,
+		}
+	}
+
+	r := mux.NewRouter()
+	p.setupHandlers(r)
+
+	tlsConfig, err := serverTLSConfig(ac)
+	if err != nil {
+		return err
+	}
+
+	listener, err = net.Listen("tcp", fmt.Sprintf(":%d", config.Datadog.GetInt("external_metrics_provider.port")))
+	if err != nil {
+		return fmt.Errorf("unable to listen for the external metrics API: %v", err)
+	}
+
+	srv := &http.Server{
+		Handler:   r,
+		ErrorLog:  stdLog.New(&config.ErrorLogWriter{}, "", 0), // log errors to seelog
+		TLSConfig: tlsConfig,
+	}
+
+	stop = make(chan struct{})
+	go rec.run(stop)
+	if p.datadogMetrics != nil {
+		go p.datadogMetrics.run(stop)
+	}
+	go srv.Serve(tls.NewListener(listener, tlsConfig))
+	log.Infof("External Metrics Provider listening on %s", listener.Addr())
+	return nil
+}
+
+// serverTLSConfig returns the TLS configuration of the server: it serves a
+// self-signed certificate, the aggregation layer of the API Server is configured
+// to skip its verification in the APIService. The clients must present a
+// certificate of the aggregation layer, signed by the request header client CA
+// of the API Server, so that the API is only reachable through the API Server,
+// which authorizes the requests.
+func serverTLSConfig(ac *apiserver.APIClient) (*tls.Config, error) {
+	clientCAs, allowedNames, err := ac.RequestHeaderClientCA()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the client CA of the aggregation layer: %v", err)
+	}
+
+	hosts := []string{"127.0.0.1", "localhost"}
+	_, rootCertPEM, rootKey, err := security.GenerateRootCert(hosts, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate the certificate of the TLS server: %v", err)
+	}
+	rootKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rootKey),
+	})
+	rootTLSCert, err := tls.X509KeyPair(rootCertPEM, rootKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key pair: %v", err)
+	}
+
+	return &tls.Config{
+		Certificates:          []tls.Certificate{rootTLSCert},
+		ClientAuth:            tls.RequireAndVerifyClientCert,
+		ClientCAs:             clientCAs,
+		VerifyPeerCertificate: verifyAllowedNames(allowedNames),
+	}, nil
+}
+
+// verifyAllowedNames returns a verification of the common name of the client
+// certificates, any name is allowed if the list is empty.
+func verifyAllowedNames(allowedNames []string) func([][]byte, [][]*x509.Certificate) error {
+	return func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(allowedNames) == 0 {
+			return nil
+		}
+		for _, chain := range verifiedChains {
+			if len(chain) == 0 {
+				continue
+			}
+			for _, name := range allowedNames {
+				if chain[0].Subject.CommonName == name {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("the client certificate is not one of the aggregation layer")
+	}
+}
+
+// StopServer stops the reconciler and closes the listener of the External Metrics Provider.
+func StopServer() {
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+	if listener != nil {
+		listener.Close()
+	}
+}
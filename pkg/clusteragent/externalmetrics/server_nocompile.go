@@ -0,0 +1,28 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !kubeapiserver
+
+package externalmetrics
+
+import (
+	"errors"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// ErrNotCompiled is returned if kubernetes apiserver support is not compiled in.
+var ErrNotCompiled = errors.New("kubernetes apiserver support not compiled in")
+
+// StartServer returns an error if the External Metrics Provider is enabled.
+func StartServer() error {
+	if !config.Datadog.GetBool("external_metrics_provider.enabled") {
+		return nil
+	}
+	return ErrNotCompiled
+}
+
+// StopServer does nothing.
+func StopServer() {}
@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+// MetricValue is the last known value of an external metric, as queried from Datadog.
+type MetricValue struct {
+	MetricName string
+	Labels     map[string]string
+	Value      float64
+	// Timestamp is the time of the datapoint
+	Timestamp time.Time
+	// Valid is false until the metric was successfully queried
+	Valid bool
+
+	lastRequested time.Time
+}
+
+// store keeps the external metrics requested by the Horizontal Pod Autoscalers
+// and their values. Metrics are registered the first time they are requested,
+// and dropped once they are not requested anymore. At most maxSize metrics are
+// registered.
+type store struct {
+	m       sync.RWMutex
+	metrics map[string]*MetricValue
+	maxSize int
+}
+
+func newStore(maxSize int) *store {
+	return &store{
+		metrics: make(map[string]*MetricValue),
+		maxSize: maxSize,
+	}
+}
+
+// metricKey returns a key identifying a metric and its labels, labels are sorted
+// so that the key does not depend on the order of the selector.
+func metricKey(metricName string, labels map[string]string) string {
+	tags := make([]string, 0, len(labels))
+	for k, v := range labels {
+		tags = append(tags, fmt.Sprintf("%s:%s", k, v))
+	}
+	sort.Strings(tags)
+	return fmt.Sprintf("%s{%s}", metricName, strings.Join(tags, ","))
+}
+
+// get returns the value of a metric, registering it to be queried if
+// it is requested for the first time. A new metric is not registered when
+// the store is full, its value is never valid.
+func (s *store) get(metricName string, labels map[string]string, now time.Time) MetricValue {
+	key := metricKey(metricName, labels)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	metric, found := s.metrics[key]
+	if !found {
+		metric = &MetricValue{
+			MetricName: metricName,
+			Labels:     labels,
+		}
+		if len(s.metrics) >= s.maxSize {
+			log.Warnf("Not querying the external metric %s: %d metrics are already queried, see external_metrics_provider.max_metrics", key, s.maxSize)
+			return *metric
+		}
+		s.metrics[key] = metric
+	}
+	metric.lastRequested = now
+	return *metric
+}
+
+// list returns a copy of all the registered metrics.
+func (s *store) list() []MetricValue {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	metrics := make([]MetricValue, 0, len(s.metrics))
+	for _, metric := range s.metrics {
+		metrics = append(metrics, *metric)
+	}
+	return metrics
+}
+
+// update sets the value of a registered metric. A metric that was purged while
+// being queried is not registered again.
+func (s *store) update(metricName string, labels map[string]string, value float64, timestamp time.Time) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	metric, found := s.metrics[metricKey(metricName, labels)]
+	if !found {
+		return
+	}
+	metric.Value = value
+	metric.Timestamp = timestamp
+	metric.Valid = true
+}
+
+// invalidate marks a metric as not having a usable value.
+func (s *store) invalidate(metricName string, labels map[string]string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if metric, found := s.metrics[metricKey(metricName, labels)]; found {
+		metric.Valid = false
+	}
+}
+
+// purge removes the metrics that were not requested since the given time.
+func (s *store) purge(since time.Time) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for key, metric := range s.metrics {
+		if metric.lastRequested.Before(since) {
+			delete(s.metrics, key)
+		}
+	}
+}
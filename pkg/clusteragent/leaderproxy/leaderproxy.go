@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+// Package leaderproxy forwards the requests received by the followers to the
+// elected cluster agent, for the endpoints only served by the leader. The node
+// agents can then query any replica of the cluster agent behind its service.
+package leaderproxy
+
+import (
+	"crypto/tls"
+	stdLog "log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+)
+
+const (
+	// ForwardedHeader is set on the forwarded requests, so that they are served
+	// by the cluster agent receiving them even if it lost the leadership since.
+	ForwardedHeader = "X-DCA-Forwarded"
+
+	// flushInterval is short for the streams of the leader to reach the clients
+	flushInterval = 100 * time.Millisecond
+)
+
+var globalForwarder = newForwarder(leaderAddress)
+
+// forwarder proxies the requests to the leader when the cluster agent is not it.
+type forwarder struct {
+	// leaderAddress returns the host:port of the leader, or an empty string if
+	// the requests are served locally
+	leaderAddress func() (string, error)
+	transport     http.RoundTripper
+
+	m       sync.Mutex
+	proxies map[string]*httputil.ReverseProxy // leader address -> proxy
+}
+
+func newForwarder(leaderAddress func() (string, error)) *forwarder {
+	return &forwarder{
+		leaderAddress: leaderAddress,
+		transport: &http.Transport{
+			// the cluster agents serve a self-signed certificate
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+		proxies: make(map[string]*httputil.ReverseProxy),
+	}
+}
+
+// Wrap returns a handler forwarding the requests to the leader if the cluster
+// agent is a follower, and serving them with handler otherwise. The requests are
+// served locally if the leader election is disabled, if they were forwarded by
+// another replica, or if the leader cannot be resolved.
+func Wrap(handler http.HandlerFunc) http.HandlerFunc {
+	return globalForwarder.wrap(handler)
+}
+
+func (f *forwarder) wrap(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(ForwardedHeader) != "" {
+			handler(w, r)
+			return
+		}
+		address, err := f.leaderAddress()
+		if err != nil {
+			log.Debugf("Could not resolve the leader, serving %s locally: %s", r.URL.Path, err)
+		}
+		if address == "" {
+			handler(w, r)
+			return
+		}
+		log.Tracef("Forwarding %s to the leader at %s", r.URL.Path, address)
+		f.proxy(address).ServeHTTP(w, r)
+	}
+}
+
+// proxy returns the reverse proxy to the leader at address, the previous one is
+// dropped when the leader changes.
+func (f *forwarder) proxy(address string) *httputil.ReverseProxy {
+	f.m.Lock()
+	defer f.m.Unlock()
+	if proxy, found := f.proxies[address]; found {
+		return proxy
+	}
+	proxy := &httputil.ReverseProxy{
+		Director: func(r *http.Request) {
+			r.URL.Scheme = "https"
+			r.URL.Host = address
+			r.Host = address
+			r.Header.Set(ForwardedHeader, "true")
+		},
+		Transport:     f.transport,
+		FlushInterval: flushInterval,
+		ErrorLog:      stdLog.New(&config.ErrorLogWriter{}, "", 0), // log errors to seelog
+	}
+	f.proxies = map[string]*httputil.ReverseProxy{address: proxy}
+	return proxy
+}
+
+// leaderAddress returns the address of the command API of the leader, or an
+// empty string if the leader election or the forwarding is disabled, or if the
+// cluster agent is the leader.
+func leaderAddress() (string, error) {
+	if !config.Datadog.GetBool("leader_election") || !config.Datadog.GetBool("cluster_agent.forward_to_leader") {
+		return "", nil
+	}
+	leaderEngine, err := leaderelection.GetLeaderEngine()
+	if err != nil {
+		return "", err
+	}
+	if leaderEngine.IsLeader() {
+		return "", nil
+	}
+	ip, err := leaderEngine.GetLeaderIP()
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(ip, strconv.Itoa(config.Datadog.GetInt("cluster_agent_cmd_port"))), nil
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package leaderproxy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardToLeader(t *testing.T) {
+	leader := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.Header.Get(ForwardedHeader))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Write([]byte("leader " + r.URL.Path))
+	}))
+	defer leader.Close()
+
+	var address string
+	var addressErr error
+	f := newForwarder(func() (string, error) { return address, addressErr })
+	handler := f.wrap(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("local " + r.URL.Path))
+	})
+	serve := func(forwarded bool) string {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/v1/metadata/node1", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		if forwarded {
+			req.Header.Set(ForwardedHeader, "true")
+		}
+		handler(rec, req)
+		return rec.Body.String()
+	}
+
+	// the leader serves the requests locally
+	assert.Equal(t, "local /api/v1/metadata/node1", serve(false))
+
+	// the followers forward them, unless they were already forwarded
+	address = leader.Listener.Addr().String()
+	assert.Equal(t, "leader /api/v1/metadata/node1", serve(false))
+	assert.Equal(t, "local /api/v1/metadata/node1", serve(true))
+
+	// the requests are served locally if the leader is unknown
+	address, addressErr = "", errors.New("the leader is not elected yet")
+	assert.Equal(t, "local /api/v1/metadata/node1", serve(false))
+}
@@ -0,0 +1,196 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/cihub/seelog"
+	apps "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/clustername"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+)
+
+var stop chan struct{}
+
+// resourceLister lists the resources of the cluster, implemented by the APIClient
+type resourceLister interface {
+	DeploymentList() (*apps.DeploymentList, error)
+	ReplicaSetList() (*apps.ReplicaSetList, error)
+	PodList() (*v1.PodList, error)
+}
+
+// payloadSender sends the payloads to Datadog, implemented by the Serializer
+type payloadSender interface {
+	SendOrchestratorPayload(data interface{}) error
+}
+
+// clusterLister lists the resources of a cluster, its name is the detected one
+// for the cluster the agent runs in, empty if it could not be detected.
+type clusterLister struct {
+	name   string
+	lister resourceLister
+}
+
+// collector periodically sends the resources of the clusters to Datadog
+type collector struct {
+	clusters      []clusterLister
+	sender        payloadSender
+	hostname      string
+	interval      time.Duration
+	maxPerPayload int
+	groupID       int64
+}
+
+// Start starts the collection of the deployments, replicasets and pods of the
+// cluster, and of the clusters of `kubernetes_remote_clusters`, if it is enabled.
+// Only the leader sends the resources.
+func Start(s *serializer.Serializer) error {
+	if !config.Datadog.GetBool("orchestrator_collection.enabled") {
+		return nil
+	}
+	if !config.Datadog.GetBool("leader_election") {
+		return fmt.Errorf("leader election must be enabled to collect the cluster resources")
+	}
+
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return err
+	}
+	clusters := []clusterLister{{name: clustername.GetClusterName(), lister: ac}}
+	remoteClusters, err := apiserver.GetRemoteClusterNames()
+	if err != nil {
+		return err
+	}
+	for _, name := range remoteClusters {
+		remoteAC, err := apiserver.GetRemoteAPIClient(name)
+		if err != nil {
+			log.Errorf("Could not connect to the apiserver of the cluster %s, not collecting its resources: %s", name, err)
+			continue
+		}
+		clusters = append(clusters, clusterLister{name: name, lister: remoteAC})
+	}
+	hostname, err := util.GetHostname()
+	if err != nil {
+		return err
+	}
+	c := &collector{
+		clusters:      clusters,
+		sender:        s,
+		hostname:      hostname,
+		interval:      time.Duration(config.Datadog.GetInt64("orchestrator_collection.interval")) * time.Second,
+		maxPerPayload: config.Datadog.GetInt("orchestrator_collection.max_per_message"),
+	}
+	if c.maxPerPayload <= 0 {
+		c.maxPerPayload = 1
+	}
+
+	stop = make(chan struct{})
+	go c.run(stop)
+	log.Infof("Collecting the cluster resources every %s", c.interval)
+	return nil
+}
+
+// Stop stops the collection of the cluster resources.
+func Stop() {
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+}
+
+// run sends the resources every interval until stop is closed.
+func (c *collector) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !isLeader() {
+				continue
+			}
+			now := time.Now()
+			for _, cluster := range c.clusters {
+				if err := c.send(cluster, now); err != nil {
+					log.Errorf("Could not send the resources of the cluster %q: %s", cluster.name, err)
+				}
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// send lists and scrubs the resources of a cluster and sends them in a group of payloads.
+func (c *collector) send(cluster clusterLister, now time.Time) error {
+	payloads, err := collect(cluster.lister, c.maxPerPayload)
+	if err != nil {
+		return err
+	}
+	c.groupID++
+	for _, p := range payloads {
+		p.ClusterName = cluster.name
+		p.Hostname = c.hostname
+		p.Timestamp = now.Unix()
+		p.GroupID = c.groupID
+		p.GroupSize = len(payloads)
+		if err = c.sender.SendOrchestratorPayload(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func collect(lister resourceLister, maxPerPayload int) ([]*Payload, error) {
+	deploymentList, err := lister.DeploymentList()
+	if err != nil {
+		return nil, fmt.Errorf("could not list the deployments: %s", err)
+	}
+	replicaSetList, err := lister.ReplicaSetList()
+	if err != nil {
+		return nil, fmt.Errorf("could not list the replicasets: %s", err)
+	}
+	podList, err := lister.PodList()
+	if err != nil {
+		return nil, fmt.Errorf("could not list the pods: %s", err)
+	}
+
+	deployments := make([]apps.Deployment, 0, len(deploymentList.Items))
+	for i := range deploymentList.Items {
+		deployments = append(deployments, scrubDeployment(&deploymentList.Items[i]))
+	}
+	replicaSets := make([]apps.ReplicaSet, 0, len(replicaSetList.Items))
+	for i := range replicaSetList.Items {
+		replicaSets = append(replicaSets, scrubReplicaSet(&replicaSetList.Items[i]))
+	}
+	pods := make([]v1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, scrubPod(&podList.Items[i]))
+	}
+	return chunkPayloads(deployments, replicaSets, pods, maxPerPayload), nil
+}
+
+func isLeader() bool {
+	leaderEngine, err := leaderelection.GetLeaderEngine()
+	if err != nil {
+		log.Warnf("Failed to instantiate the Leader Elector, not collecting the cluster resources: %s", err)
+		return false
+	}
+	if err = leaderEngine.EnsureLeaderElectionRuns(); err != nil {
+		log.Warnf("Leader Election process failed to start, not collecting the cluster resources: %s", err)
+		return false
+	}
+	return leaderEngine.IsLeader()
+}
@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !kubeapiserver
+
+package orchestrator
+
+import (
+	"errors"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+)
+
+// ErrNotCompiled is returned if kubernetes apiserver support is not compiled in.
+var ErrNotCompiled = errors.New("kubernetes apiserver support not compiled in")
+
+// Start returns an error if the collection of the cluster resources is enabled.
+func Start(s *serializer.Serializer) error {
+	if !config.Datadog.GetBool("orchestrator_collection.enabled") {
+		return nil
+	}
+	return ErrNotCompiled
+}
+
+// Stop does nothing.
+func Stop() {}
@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apps "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeLister struct {
+	deployments *apps.DeploymentList
+	replicaSets *apps.ReplicaSetList
+	pods        *v1.PodList
+	err         error
+}
+
+func (l *fakeLister) DeploymentList() (*apps.DeploymentList, error) { return l.deployments, l.err }
+func (l *fakeLister) ReplicaSetList() (*apps.ReplicaSetList, error) { return l.replicaSets, nil }
+func (l *fakeLister) PodList() (*v1.PodList, error)                 { return l.pods, nil }
+
+type fakeSender struct {
+	payloads []*Payload
+}
+
+func (s *fakeSender) SendOrchestratorPayload(data interface{}) error {
+	s.payloads = append(s.payloads, data.(*Payload))
+	return nil
+}
+
+func newPodSpec() v1.PodSpec {
+	return v1.PodSpec{
+		InitContainers: []v1.Container{{Name: "init", Env: []v1.EnvVar{{Name: "TOKEN", Value: "secret"}}}},
+		Containers: []v1.Container{{
+			Name: "web",
+			Env: []v1.EnvVar{
+				{Name: "PASSWORD", Value: "hunter2"},
+				{Name: "EMPTY"},
+				{Name: "FROM_SECRET", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{Key: "password"}}},
+			},
+		}},
+	}
+}
+
+func TestScrubPod(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "web",
+			Annotations: map[string]string{
+				lastAppliedConfigAnnotation: "{}",
+				"team":                      "infra",
+			},
+		},
+		Spec: newPodSpec(),
+	}
+
+	scrubbed := scrubPod(pod)
+	assert.Equal(t, map[string]string{"team": "infra"}, scrubbed.Annotations)
+	assert.Equal(t, redactedValue, scrubbed.Spec.InitContainers[0].Env[0].Value)
+	env := scrubbed.Spec.Containers[0].Env
+	assert.Equal(t, redactedValue, env[0].Value)
+	assert.Equal(t, "", env[1].Value)
+	assert.Equal(t, "password", env[2].ValueFrom.SecretKeyRef.Key)
+
+	// the original pod is not modified
+	assert.Equal(t, "hunter2", pod.Spec.Containers[0].Env[0].Value)
+	assert.Len(t, pod.Annotations, 2)
+}
+
+func TestChunkPayloads(t *testing.T) {
+	deployments := make([]apps.Deployment, 3)
+	pods := make([]v1.Pod, 5)
+
+	payloads := chunkPayloads(deployments, nil, pods, 2)
+	require.Len(t, payloads, 5)
+	assert.Len(t, payloads[0].Deployments, 2)
+	assert.Len(t, payloads[1].Deployments, 1)
+	assert.Len(t, payloads[2].Pods, 2)
+	assert.Len(t, payloads[3].Pods, 2)
+	assert.Len(t, payloads[4].Pods, 1)
+	for _, p := range payloads[2:] {
+		assert.Empty(t, p.Deployments)
+		assert.Empty(t, p.ReplicaSets)
+	}
+}
+
+func TestCollectorSend(t *testing.T) {
+	lister := &fakeLister{
+		deployments: &apps.DeploymentList{Items: []apps.Deployment{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web"},
+			Spec:       apps.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: newPodSpec()}},
+		}}},
+		replicaSets: &apps.ReplicaSetList{Items: []apps.ReplicaSet{{ObjectMeta: metav1.ObjectMeta{Name: "web-1234"}}}},
+		pods:        &v1.PodList{Items: []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "web-1234-abcd"}, Spec: newPodSpec()}}},
+	}
+	sender := &fakeSender{}
+	local := clusterLister{lister: lister}
+	remote := clusterLister{name: "prod", lister: lister}
+	c := &collector{clusters: []clusterLister{local, remote}, sender: sender, hostname: "dca", maxPerPayload: 10}
+
+	now := time.Now()
+	require.NoError(t, c.send(local, now))
+	require.Len(t, sender.payloads, 3)
+	for _, p := range sender.payloads {
+		assert.Equal(t, "", p.ClusterName)
+		assert.Equal(t, "dca", p.Hostname)
+		assert.Equal(t, now.Unix(), p.Timestamp)
+		assert.Equal(t, int64(1), p.GroupID)
+		assert.Equal(t, 3, p.GroupSize)
+	}
+	assert.Equal(t, redactedValue, sender.payloads[0].Deployments[0].Spec.Template.Spec.Containers[0].Env[0].Value)
+	assert.Equal(t, redactedValue, sender.payloads[2].Pods[0].Spec.Containers[0].Env[0].Value)
+
+	require.NoError(t, c.send(remote, now))
+	assert.Equal(t, int64(2), sender.payloads[3].GroupID)
+	assert.Equal(t, "prod", sender.payloads[3].ClusterName)
+
+	lister.err = errors.New("forbidden")
+	assert.Error(t, c.send(local, now))
+	assert.Len(t, sender.payloads, 6)
+}
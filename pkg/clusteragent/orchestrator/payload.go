@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package orchestrator
+
+import (
+	apps "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	redactedValue = "********"
+	// lastAppliedConfigAnnotation holds the whole manifest of the resource, env values included
+	lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// Payload holds a chunk of the resources of a cluster. The payloads of a same
+// collection share their GroupID, GroupSize is the number of payloads in the group.
+// ClusterName is empty if the name of the cluster of the agent could not be detected.
+type Payload struct {
+	ClusterName string            `json:"cluster_name,omitempty"`
+	Hostname    string            `json:"hostname"`
+	Timestamp   int64             `json:"timestamp"`
+	GroupID     int64             `json:"group_id"`
+	GroupSize   int               `json:"group_size"`
+	Deployments []apps.Deployment `json:"deployments,omitempty"`
+	ReplicaSets []apps.ReplicaSet `json:"replicasets,omitempty"`
+	Pods        []v1.Pod          `json:"pods,omitempty"`
+}
+
+// chunkPayloads splits the resources in payloads of at most maxPerPayload
+// resources, each payload only holds one kind of resources.
+func chunkPayloads(deployments []apps.Deployment, replicaSets []apps.ReplicaSet, pods []v1.Pod, maxPerPayload int) []*Payload {
+	var payloads []*Payload
+	for start := 0; start < len(deployments); start += maxPerPayload {
+		payloads = append(payloads, &Payload{Deployments: deployments[start:chunkEnd(start, maxPerPayload, len(deployments))]})
+	}
+	for start := 0; start < len(replicaSets); start += maxPerPayload {
+		payloads = append(payloads, &Payload{ReplicaSets: replicaSets[start:chunkEnd(start, maxPerPayload, len(replicaSets))]})
+	}
+	for start := 0; start < len(pods); start += maxPerPayload {
+		payloads = append(payloads, &Payload{Pods: pods[start:chunkEnd(start, maxPerPayload, len(pods))]})
+	}
+	return payloads
+}
+
+func chunkEnd(start, size, length int) int {
+	if start+size > length {
+		return length
+	}
+	return start + size
+}
+
+// scrubDeployment removes the values of the env vars from a copy of the deployment.
+func scrubDeployment(d *apps.Deployment) apps.Deployment {
+	scrubbed := d.DeepCopy()
+	scrubObjectMeta(&scrubbed.ObjectMeta)
+	scrubPodSpec(&scrubbed.Spec.Template.Spec)
+	return *scrubbed
+}
+
+// scrubReplicaSet removes the values of the env vars from a copy of the replicaset.
+func scrubReplicaSet(rs *apps.ReplicaSet) apps.ReplicaSet {
+	scrubbed := rs.DeepCopy()
+	scrubObjectMeta(&scrubbed.ObjectMeta)
+	scrubPodSpec(&scrubbed.Spec.Template.Spec)
+	return *scrubbed
+}
+
+// scrubPod removes the values of the env vars from a copy of the pod.
+func scrubPod(p *v1.Pod) v1.Pod {
+	scrubbed := p.DeepCopy()
+	scrubObjectMeta(&scrubbed.ObjectMeta)
+	scrubPodSpec(&scrubbed.Spec)
+	return *scrubbed
+}
+
+func scrubObjectMeta(meta *metav1.ObjectMeta) {
+	delete(meta.Annotations, lastAppliedConfigAnnotation)
+}
+
+// scrubPodSpec redacts the literal values of the env vars, the references to
+// secrets and configmaps are kept as they do not hold the values themselves.
+func scrubPodSpec(spec *v1.PodSpec) {
+	for _, containers := range [][]v1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			for j := range containers[i].Env {
+				if containers[i].Env[j].Value != "" {
+					containers[i].Env[j].Value = redactedValue
+				}
+			}
+		}
+	}
+}
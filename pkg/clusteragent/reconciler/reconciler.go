@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+// Package reconciler periodically prunes the caches of the cluster agent from
+// the pods which no longer exist in the apiserver, so that they do not grow when
+// the delete events are missed.
+package reconciler
+
+import (
+	"expvar"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"k8s.io/api/core/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+)
+
+var (
+	reconcilerExpvar = expvar.NewMap("reconciler")
+	stop             chan struct{}
+)
+
+// podLister lists the pods of the cluster, implemented by the APIClient
+type podLister interface {
+	PodList() (*v1.PodList, error)
+}
+
+// reconciler prunes the entities of the pods which were deleted
+type reconciler struct {
+	lister   podLister
+	interval time.Duration
+	// pruners remove the entities of the pods missing from the list, and return
+	// how many they removed
+	pruners map[string]func(*v1.PodList) int // expvar name -> pruner
+}
+
+// Start starts the periodic reconciliation of the service mapping with the
+// pods of the apiserver, every `cluster_agent.reconcile_interval`.
+// Only the leader reconciles its caches, as the followers forward it the requests.
+func Start() error {
+	interval := time.Duration(config.Datadog.GetInt64("cluster_agent.reconcile_interval")) * time.Second
+	if interval <= 0 {
+		return nil
+	}
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return err
+	}
+	r := &reconciler{
+		lister:   ac,
+		interval: interval,
+		pruners: map[string]func(*v1.PodList) int{
+			"PrunedServiceMappings": apiserver.PruneMetadataMapping,
+		},
+	}
+
+	stop = make(chan struct{})
+	go r.run(stop)
+	log.Infof("Reconciling the cached entities with the apiserver every %s", interval)
+	return nil
+}
+
+// Stop stops the reconciliation.
+func Stop() {
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+}
+
+// run reconciles the caches every interval until stop is closed.
+func (r *reconciler) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !isLeader() {
+				continue
+			}
+			if err := r.reconcile(); err != nil {
+				log.Warnf("Could not reconcile the cached entities with the apiserver: %s", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// reconcile lists the pods and runs the pruners, pruning nothing if the pods
+// could not be listed.
+func (r *reconciler) reconcile() error {
+	podList, err := r.lister.PodList()
+	if err != nil {
+		reconcilerExpvar.Add("Errors", 1)
+		return err
+	}
+	reconcilerExpvar.Add("Runs", 1)
+	for name, prune := range r.pruners {
+		pruned := prune(podList)
+		reconcilerExpvar.Add(name, int64(pruned))
+		if pruned > 0 {
+			log.Debugf("Reconciliation with %d pods: %d %s", len(podList.Items), pruned, name)
+		}
+	}
+	return nil
+}
+
+func isLeader() bool {
+	if !config.Datadog.GetBool("leader_election") {
+		return true
+	}
+	leaderEngine, err := leaderelection.GetLeaderEngine()
+	if err != nil {
+		log.Warnf("Failed to instantiate the Leader Elector, not reconciling the cached entities: %s", err)
+		return false
+	}
+	if err = leaderEngine.EnsureLeaderElectionRuns(); err != nil {
+		log.Warnf("Leader Election process failed to start, not reconciling the cached entities: %s", err)
+		return false
+	}
+	return leaderEngine.IsLeader()
+}
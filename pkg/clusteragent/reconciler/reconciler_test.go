@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package reconciler
+
+import (
+	"errors"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeLister struct {
+	pods *v1.PodList
+	err  error
+}
+
+func (l *fakeLister) PodList() (*v1.PodList, error) { return l.pods, l.err }
+
+func expvarValue(name string) int64 {
+	v, ok := reconcilerExpvar.Get(name).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
+func TestReconcile(t *testing.T) {
+	lister := &fakeLister{pods: &v1.PodList{Items: []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "web"}}}}}
+	var seen []*v1.PodList
+	r := &reconciler{
+		lister: lister,
+		pruners: map[string]func(*v1.PodList) int{
+			"PrunedTest": func(podList *v1.PodList) int {
+				seen = append(seen, podList)
+				return 2
+			},
+		},
+	}
+	runs := expvarValue("Runs")
+
+	require.NoError(t, r.reconcile())
+	assert.Equal(t, []*v1.PodList{lister.pods}, seen)
+	assert.Equal(t, int64(2), expvarValue("PrunedTest"))
+	assert.Equal(t, runs+1, expvarValue("Runs"))
+
+	// nothing is pruned if the pods cannot be listed
+	lister.err = errors.New("apiserver unavailable")
+	errs := expvarValue("Errors")
+	assert.Error(t, r.reconcile())
+	assert.Len(t, seen, 1)
+	assert.Equal(t, errs+1, expvarValue("Errors"))
+}
@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+// Package tagstream pushes the cluster level tags of the pods to the node agents
+// subscribed to their node, so that they do not query the cluster agent for each
+// of their pods.
+package tagstream
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/util/clustername"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+var (
+	globalStreamer     *streamer
+	globalStreamerLock sync.Mutex
+)
+
+// podServicesLister is implemented by the APIClient
+type podServicesLister interface {
+	NodesPodServices() (map[string]map[string][]string, error)
+}
+
+// streamer maps the pods of all the nodes to their services periodically, and
+// pushes the mapping of a node to its subscribers when it changes.
+type streamer struct {
+	sync.Mutex
+	client      podServicesLister
+	clusterTags []string
+	nodes       map[string]map[string][]string
+	subscribers map[chan clusteragent.NodeMetadata]string // channel -> node name
+}
+
+// Subscribe returns a channel receiving the metadata of the pods of a node, the
+// current one first. The metadata is pushed when it changes, and at least every
+// NodeMetadataStreamKeepAlive. The mapping of the pods starts with the first
+// subscription.
+func Subscribe(nodeName string) (chan clusteragent.NodeMetadata, error) {
+	s, err := getStreamer()
+	if err != nil {
+		return nil, err
+	}
+	return s.subscribe(nodeName), nil
+}
+
+// Unsubscribe stops the pushes to a channel returned by Subscribe.
+func Unsubscribe(ch chan clusteragent.NodeMetadata) {
+	s, err := getStreamer()
+	if err != nil {
+		return
+	}
+	s.unsubscribe(ch)
+}
+
+func getStreamer() (*streamer, error) {
+	globalStreamerLock.Lock()
+	defer globalStreamerLock.Unlock()
+	if globalStreamer != nil {
+		return globalStreamer, nil
+	}
+
+	client, err := apiserver.GetAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	var clusterTags []string
+	if clusterName := clustername.GetClusterName(); clusterName != "" {
+		clusterTags = append(clusterTags, fmt.Sprintf("%s:%s", clustername.TagName, clusterName))
+	}
+	globalStreamer = newStreamer(client, clusterTags)
+	globalStreamer.refresh()
+	refreshPeriod := time.Duration(config.Datadog.GetInt("cluster_agent.tag_stream_refresh_period")) * time.Second
+	go globalStreamer.run(refreshPeriod)
+	return globalStreamer, nil
+}
+
+func newStreamer(client podServicesLister, clusterTags []string) *streamer {
+	return &streamer{
+		client:      client,
+		clusterTags: clusterTags,
+		nodes:       make(map[string]map[string][]string),
+		subscribers: make(map[chan clusteragent.NodeMetadata]string),
+	}
+}
+
+func (s *streamer) run(refreshPeriod time.Duration) {
+	refreshTicker := time.NewTicker(refreshPeriod)
+	keepAliveTicker := time.NewTicker(clusteragent.NodeMetadataStreamKeepAlive)
+	for {
+		select {
+		case <-refreshTicker.C:
+			s.refresh()
+		case <-keepAliveTicker.C:
+			s.keepAlive()
+		}
+	}
+}
+
+func (s *streamer) subscribe(nodeName string) chan clusteragent.NodeMetadata {
+	ch := make(chan clusteragent.NodeMetadata, 1)
+
+	s.Lock()
+	defer s.Unlock()
+	ch <- s.metadata(nodeName)
+	s.subscribers[ch] = nodeName
+	log.Debugf("The node agent of %s subscribed to the tag stream, %d subscribers", nodeName, len(s.subscribers))
+	return ch
+}
+
+func (s *streamer) unsubscribe(ch chan clusteragent.NodeMetadata) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.subscribers, ch)
+}
+
+// refresh maps the pods to their services again, and pushes the metadata of the
+// nodes which changed.
+func (s *streamer) refresh() {
+	nodes, err := s.client.NodesPodServices()
+	if err != nil {
+		log.Warnf("Could not map the pods to their services, not updating the node agents: %s", err)
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	previous := s.nodes
+	s.nodes = nodes
+	for ch, nodeName := range s.subscribers {
+		if !reflect.DeepEqual(previous[nodeName], nodes[nodeName]) {
+			push(ch, s.metadata(nodeName))
+		}
+	}
+}
+
+// keepAlive pushes the metadata to all the subscribers, so that they can detect
+// dead streams.
+func (s *streamer) keepAlive() {
+	s.Lock()
+	defer s.Unlock()
+	for ch, nodeName := range s.subscribers {
+		push(ch, s.metadata(nodeName))
+	}
+}
+
+// metadata returns the metadata of a node, the caller must hold the lock.
+func (s *streamer) metadata(nodeName string) clusteragent.NodeMetadata {
+	return clusteragent.NodeMetadata{
+		PodServices: s.nodes[nodeName],
+		ClusterTags: s.clusterTags,
+	}
+}
+
+// push replaces the metadata pending in a channel, so that slow subscribers only
+// receive the latest metadata. The caller must hold the lock, as the streamer is
+// the only sender.
+func push(ch chan clusteragent.NodeMetadata, metadata clusteragent.NodeMetadata) {
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- metadata
+}
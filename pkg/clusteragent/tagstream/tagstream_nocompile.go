@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !kubeapiserver
+
+package tagstream
+
+import (
+	"errors"
+
+	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
+)
+
+// ErrNotCompiled is returned if kubernetes apiserver support is not compiled in.
+var ErrNotCompiled = errors.New("kubernetes apiserver support not compiled in")
+
+// Subscribe returns ErrNotCompiled.
+func Subscribe(nodeName string) (chan clusteragent.NodeMetadata, error) {
+	return nil, ErrNotCompiled
+}
+
+// Unsubscribe does nothing.
+func Unsubscribe(ch chan clusteragent.NodeMetadata) {}
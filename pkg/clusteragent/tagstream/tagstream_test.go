@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package tagstream
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
+)
+
+type fakeLister struct {
+	nodes map[string]map[string][]string
+	err   error
+}
+
+func (f *fakeLister) NodesPodServices() (map[string]map[string][]string, error) {
+	return f.nodes, f.err
+}
+
+func assertPending(t *testing.T, ch chan clusteragent.NodeMetadata, expected map[string][]string) {
+	select {
+	case metadata := <-ch:
+		assert.Equal(t, expected, metadata.PodServices)
+		assert.Equal(t, []string{"kube_cluster_name:prod"}, metadata.ClusterTags)
+	default:
+		t.Fatal("no metadata was pushed")
+	}
+}
+
+func assertNothingPending(t *testing.T, ch chan clusteragent.NodeMetadata) {
+	select {
+	case metadata := <-ch:
+		t.Fatalf("unexpected push %v", metadata)
+	default:
+	}
+}
+
+func TestStreamer(t *testing.T) {
+	lister := &fakeLister{nodes: map[string]map[string][]string{
+		"node1": {"default/web-1": {"web"}},
+		"node2": {"default/web-2": {"web"}},
+	}}
+	s := newStreamer(lister, []string{"kube_cluster_name:prod"})
+	s.refresh()
+
+	// the current metadata is sent on subscription
+	node1 := s.subscribe("node1")
+	node2 := s.subscribe("node2")
+	assertPending(t, node1, map[string][]string{"default/web-1": {"web"}})
+	assertPending(t, node2, map[string][]string{"default/web-2": {"web"}})
+
+	// only the nodes which changed are pushed
+	lister.nodes = map[string]map[string][]string{
+		"node1": {"default/web-1": {"frontend", "web"}},
+		"node2": {"default/web-2": {"web"}},
+	}
+	s.refresh()
+	assertPending(t, node1, map[string][]string{"default/web-1": {"frontend", "web"}})
+	assertNothingPending(t, node2)
+
+	// the metadata is kept if the mapping fails
+	lister.err = errors.New("timeout")
+	s.refresh()
+	assertNothingPending(t, node1)
+
+	// slow subscribers only get the latest metadata
+	lister.err = nil
+	lister.nodes = map[string]map[string][]string{"node2": {}}
+	s.refresh()
+	s.keepAlive()
+	var nilServices map[string][]string
+	assertPending(t, node1, nilServices)
+	assertNothingPending(t, node1)
+	assertPending(t, node2, map[string][]string{})
+
+	s.unsubscribe(node1)
+	s.keepAlive()
+	assertNothingPending(t, node1)
+	assertPending(t, node2, map[string][]string{})
+}
@@ -10,9 +10,35 @@ import (
 	"time"
 )
 
+// recentResultsSize is the number of results of the runs kept by the stats
+const recentResultsSize = 10
+
+// The statuses of the results of the runs
+const (
+	RunStatusOK      = "ok"
+	RunStatusWarning = "warning"
+	RunStatusError   = "error"
+)
+
+// RunResult is the result of a run of a check instance
+type RunResult struct {
+	Timestamp string   `json:"timestamp" yaml:"timestamp"`
+	Status    string   `json:"status" yaml:"status"`
+	Duration  int64    `json:"duration_ms" yaml:"duration_ms"`
+	Error     string   `json:"error,omitempty" yaml:"error,omitempty"`
+	Warnings  []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// versionedCheck is implemented by the checks knowing the version of their
+// integration, like the Python checks installed from a wheel
+type versionedCheck interface {
+	Version() string
+}
+
 // Stats holds basic runtime statistics about check instances
 type Stats struct {
 	CheckName            string
+	CheckVersion         string
 	CheckID              ID
 	TotalRuns            uint64
 	TotalErrors          uint64
@@ -30,14 +56,21 @@ type Stats struct {
 	LastWarnings         []string  // warnings that occurred in the last run, if any
 	UpdateTimestamp      int64     // latest update to this instance, unix timestamp in seconds
 	m                    sync.Mutex
+
+	// circular buffer of the results of the recent runs, most recent at [(TotalRuns+9) % 10]
+	recentResults [recentResultsSize]RunResult
 }
 
 // NewStats returns a new check stats instance
 func NewStats(c Check) *Stats {
-	return &Stats{
+	stats := &Stats{
 		CheckID:   c.ID(),
 		CheckName: c.String(),
 	}
+	if v, ok := c.(versionedCheck); ok {
+		stats.CheckVersion = v.Version()
+	}
+	return stats
 }
 
 // Add tracks a new execution time
@@ -74,6 +107,21 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats m
 	}
 	cs.UpdateTimestamp = time.Now().Unix()
 
+	result := RunResult{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Status:    RunStatusOK,
+		Duration:  tms,
+		Warnings:  cs.LastWarnings,
+	}
+	if len(cs.LastWarnings) != 0 {
+		result.Status = RunStatusWarning
+	}
+	if err != nil {
+		result.Status = RunStatusError
+		result.Error = err.Error()
+	}
+	cs.recentResults[(cs.TotalRuns-1)%recentResultsSize] = result
+
 	if m, ok := metricStats["Metrics"]; ok {
 		cs.Metrics = m
 		if cs.TotalMetrics <= 1000001 {
@@ -93,3 +141,19 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats m
 		}
 	}
 }
+
+// RecentResults returns the results of the last runs, the most recent first
+func (cs *Stats) RecentResults() []RunResult {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	count := cs.TotalRuns
+	if count > recentResultsSize {
+		count = recentResultsSize
+	}
+	results := make([]RunResult, 0, count)
+	for i := uint64(1); i <= count; i++ {
+		results = append(results, cs.recentResults[(cs.TotalRuns-i)%recentResultsSize])
+	}
+	return results
+}
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package check
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentResults(t *testing.T) {
+	s := &Stats{CheckName: "foo", CheckID: "foo:1"}
+	assert.Len(t, s.RecentResults(), 0)
+
+	s.Add(10*time.Millisecond, nil, nil, nil)
+	s.Add(20*time.Millisecond, nil, []error{errors.New("deprecated option")}, nil)
+	s.Add(30*time.Millisecond, errors.New("connection refused"), nil, nil)
+
+	results := s.RecentResults()
+	require.Len(t, results, 3)
+	assert.Equal(t, RunStatusError, results[0].Status)
+	assert.Equal(t, "connection refused", results[0].Error)
+	assert.Equal(t, int64(30), results[0].Duration)
+	assert.Equal(t, RunStatusWarning, results[1].Status)
+	assert.Equal(t, []string{"deprecated option"}, results[1].Warnings)
+	assert.Equal(t, RunStatusOK, results[2].Status)
+	assert.Equal(t, int64(10), results[2].Duration)
+
+	// only the last results are kept
+	for i := 0; i < 2*recentResultsSize; i++ {
+		s.Add(time.Duration(i)*time.Millisecond, fmt.Errorf("error %d", i), nil, nil)
+	}
+	results = s.RecentResults()
+	require.Len(t, results, recentResultsSize)
+	assert.Equal(t, fmt.Sprintf("error %d", 2*recentResultsSize-1), results[0].Error)
+	assert.Equal(t, fmt.Sprintf("error %d", recentResultsSize), results[recentResultsSize-1].Error)
+}
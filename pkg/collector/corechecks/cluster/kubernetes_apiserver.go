@@ -19,6 +19,7 @@ import (
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/clustername"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	log "github.com/cihub/seelog"
 	yaml "gopkg.in/yaml.v2"
@@ -31,6 +32,7 @@ const (
 	KubeControlPaneCheck         = "kube_apiserver_controlplane.up"
 	kubernetesAPIServerCheckName = "kubernetes_apiserver"
 	eventTokenKey                = "event"
+	clusterNameTagKey            = clustername.TagName
 )
 
 // KubeASConfig is the config of the API server.
@@ -38,6 +40,13 @@ type KubeASConfig struct {
 	Tags              []string `yaml:"tags"`
 	CollectEvent      bool     `yaml:"collect_events"`
 	FilteredEventType []string `yaml:"filtered_event_types"`
+	// EventFilters drop or sample the events they match, see EventFilterRule
+	EventFilters []EventFilterRule `yaml:"event_filters"`
+	// Cluster is the name of a cluster of `kubernetes_remote_clusters` to monitor
+	// instead of the one the agent runs in.
+	Cluster string `yaml:"cluster"`
+
+	eventFilter *eventFilter
 }
 
 // KubeASCheck grabs metrics and events from the API server.
@@ -46,15 +55,32 @@ type KubeASCheck struct {
 	instance              *KubeASConfig
 	KubeAPIServerHostname string
 	latestEventToken      string
-	configMapAvailable    bool
 	ac                    *apiserver.APIClient
+	// localAC stores the event collection checkpoint in the cluster of the agent,
+	// it is the same client as ac unless a remote cluster is monitored.
+	localAC *apiserver.APIClient
+	// oshiftAPILevel is the level of the OpenShift APIs, detected on the first
+	// run the apiserver answers
+	oshiftAPILevel apiserver.OpenShiftAPILevel
+	oshiftDetected bool
 }
 
 func (c *KubeASConfig) parse(data []byte) error {
 	// default values
 	c.CollectEvent = config.Datadog.GetBool("collect_kubernetes_events")
 
-	return yaml.Unmarshal(data, c)
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	eventFilter, err := newEventFilter(c.EventFilters)
+	if err != nil {
+		return err
+	}
+	c.eventFilter = eventFilter
+	if c.Cluster != "" {
+		c.Tags = append(c.Tags, fmt.Sprintf("%s:%s", clusterNameTagKey, c.Cluster))
+	}
+	return nil
 }
 
 // Configure parses the check configuration and init the check.
@@ -65,6 +91,12 @@ func (k *KubeASCheck) Configure(config, initConfig integration.Data) error {
 		log.Error("could not parse the config for the API server")
 		return err
 	}
+	// the data of the cluster of the agent is tagged with its detected name
+	if k.instance.Cluster == "" {
+		if clusterName := clustername.GetClusterName(); clusterName != "" {
+			k.instance.Tags = append(k.instance.Tags, fmt.Sprintf("%s:%s", clusterNameTagKey, clusterName))
+		}
+	}
 
 	log.Debugf("Running config %s", config)
 	return nil
@@ -83,36 +115,55 @@ func (k *KubeASCheck) Run() error {
 		return nil
 	}
 
-	errLeader := k.runLeaderElection()
+	errLeader := runLeaderElection(&k.CheckBase)
 	if errLeader != nil {
 		if errLeader == apiserver.ErrNotLeader {
 			// Only the leader can instantiate the apiserver client.
+			// Another leader is collecting the events, we will resume from
+			// its checkpoint in the ConfigMap if we become leader again.
+			k.latestEventToken = ""
 			return nil
 		}
 		return err
 	}
 
-	if k.ac == nil {
+	if k.localAC == nil {
 		// We start the API Server Client.
-		k.ac, err = apiserver.GetAPIClient()
+		k.localAC, err = apiserver.GetAPIClient()
 		if err != nil {
 			k.Warn("Could not connect to apiserver: %s", err)
 			return err
 		}
 	}
+	if k.ac == nil {
+		if k.instance.Cluster == "" {
+			k.ac = k.localAC
+		} else {
+			k.ac, err = apiserver.GetRemoteAPIClient(k.instance.Cluster)
+			if err != nil {
+				k.Warnf("Could not connect to the apiserver of the cluster %s: %s", k.instance.Cluster, err)
+				return err
+			}
+		}
+	}
 
-	// Running the Control Plane status check.
-	componentsStatus, err := k.ac.ComponentStatuses()
-	if err != nil {
-		k.Warnf("Could not retrieve the status from the control plane's components %s", err.Error())
-	} else {
-		err = k.parseComponentStatus(sender, componentsStatus)
+	// Running the Control Plane status check, component statuses are cluster-scoped.
+	if !k.ac.IsNamespaceScoped() {
+		componentsStatus, err := k.ac.ComponentStatuses()
 		if err != nil {
-			k.Warnf("Could not collect API Server component status: %s", err.Error())
+			k.Warnf("Could not retrieve the status from the control plane's components %s", err.Error())
+		} else {
+			err = k.parseComponentStatus(sender, componentsStatus)
+			if err != nil {
+				k.Warnf("Could not collect API Server component status: %s", err.Error())
+			}
 		}
 	}
 	defer sender.Commit()
 
+	// Collecting the OpenShift resources, if the cluster serves their APIs.
+	k.collectOpenShift(sender)
+
 	// Running the event collection.
 	if !k.instance.CollectEvent {
 		return nil
@@ -151,17 +202,19 @@ func KubernetesASFactory() check.Check {
 	}
 }
 
-func (k *KubeASCheck) runLeaderElection() error {
+// runLeaderElection returns apiserver.ErrNotLeader if the agent is not the leader,
+// the cluster checks only run on the leader.
+func runLeaderElection(c *core.CheckBase) error {
 
 	leaderEngine, err := leaderelection.GetLeaderEngine()
 	if err != nil {
-		k.Warn("Failed to instantiate the Leader Elector. Not running the Kubernetes API Server check or collecting Kubernetes Events.")
+		c.Warnf("Failed to instantiate the Leader Elector. Not running the %s check.", c.String())
 		return err
 	}
 
 	err = leaderEngine.EnsureLeaderElectionRuns()
 	if err != nil {
-		k.Warn("Leader Election process failed to start")
+		c.Warn("Leader Election process failed to start")
 		return err
 	}
 
@@ -172,20 +225,31 @@ func (k *KubeASCheck) runLeaderElection() error {
 	log.Tracef("Currently Leader %q, running Kubernetes cluster related checks and collecting events", leaderEngine.CurrentLeaderName())
 	return nil
 }
+
+// eventTokenKey returns the key of the checkpoint of the event collection in the
+// ConfigMap, each monitored cluster has its own.
+func (k *KubeASCheck) eventTokenKey() string {
+	if k.instance.Cluster == "" {
+		return eventTokenKey
+	}
+	return fmt.Sprintf("%s_%s", eventTokenKey, k.instance.Cluster)
+}
+
 func (k *KubeASCheck) eventCollectionInit() {
 	if k.latestEventToken == "" {
 		// Initialization: Checking if we previously stored the latestEventToken in a configMap
-		tokenValue, found, err := k.ac.GetTokenFromConfigmap(eventTokenKey, 3600)
+		tokenValue, _, err := k.localAC.GetTokenFromConfigmap(k.eventTokenKey(), 3600)
 		switch {
 		case err == apiserver.ErrOutdated:
-			k.configMapAvailable = found
-			k.latestEventToken = "0"
+			// The resversion may be gone from the API Server, in which case
+			// LatestEvents re-lists the events more recent than it.
+			k.latestEventToken = tokenValue
 
 		case err == apiserver.ErrNotFound:
+			// No checkpoint yet, it is stored in the ConfigMap on the next update.
 			k.latestEventToken = "0"
 
 		case err == nil:
-			k.configMapAvailable = found
 			k.latestEventToken = tokenValue
 
 		default:
@@ -195,8 +259,24 @@ func (k *KubeASCheck) eventCollectionInit() {
 	}
 }
 
+// eventsClient lists the events of the monitored cluster
+type eventsClient interface {
+	LatestEvents(since string) ([]*v1.Event, []*v1.Event, string, error)
+}
+
+// tokenStore stores the checkpoint of the event collection
+type tokenStore interface {
+	UpdateTokenInConfigmap(token, tokenValue string) error
+}
+
 func (k *KubeASCheck) eventCollectionCheck() ([]*v1.Event, []*v1.Event, error) {
-	newEvents, modifiedEvents, versionToken, err := k.ac.LatestEvents(k.latestEventToken)
+	return k.collectEvents(k.ac, k.localAC)
+}
+
+// collectEvents returns the events more recent than the latestEventToken, and
+// moves the checkpoint forward.
+func (k *KubeASCheck) collectEvents(client eventsClient, store tokenStore) ([]*v1.Event, []*v1.Event, error) {
+	newEvents, modifiedEvents, versionToken, err := client.LatestEvents(k.latestEventToken)
 	if err != nil {
 		k.Warnf("Could not collect events from the api server: %s", err.Error())
 		return nil, nil, err
@@ -204,7 +284,7 @@ func (k *KubeASCheck) eventCollectionCheck() ([]*v1.Event, []*v1.Event, error) {
 
 	if versionToken == "0" {
 		// API server cache expired or no recent events to process. Resetting the Resversion token.
-		_, _, versionToken, err = k.ac.LatestEvents("0")
+		_, _, versionToken, err = client.LatestEvents("0")
 		if err != nil {
 			k.Warnf("Could not collect cached events from the api server: %s", err.Error())
 			return nil, nil, err
@@ -218,15 +298,14 @@ func (k *KubeASCheck) eventCollectionCheck() ([]*v1.Event, []*v1.Event, error) {
 		k.latestEventToken = versionToken
 	}
 
-	// We check that the resversion gotten from the API Server is more recent than the one cached in the util.
-	if len(newEvents)+len(modifiedEvents) == 0 {
-		return nil, nil, nil
-	}
-
-	k.latestEventToken = versionToken
-	if k.configMapAvailable {
-		configMapErr := k.ac.UpdateTokenInConfigmap(eventTokenKey, versionToken)
-		if configMapErr != nil {
+	// The token also moves without new events when the expired resversion is
+	// replaced by the one of a re-list, it must be kept so the next runs do not
+	// re-list again.
+	if versionToken != k.latestEventToken || len(newEvents)+len(modifiedEvents) > 0 {
+		k.latestEventToken = versionToken
+		// A failure is retried on the next update, the checkpoint is only needed
+		// when the collection is resumed.
+		if configMapErr := store.UpdateTokenInConfigmap(k.eventTokenKey(), versionToken); configMapErr != nil {
 			k.Warnf("Could not store the LastEventToken in the ConfigMap: %s", configMapErr.Error())
 		}
 	}
@@ -284,6 +363,10 @@ ITER_EVENTS:
 				continue ITER_EVENTS
 			}
 		}
+		if !k.instance.eventFilter.keep(event) {
+			filteredByType[event.Reason] = filteredByType[event.Reason] + 1
+			continue
+		}
 		bundle, found := eventsByObject[event.InvolvedObject.UID]
 		if found == false {
 			bundle = newKubernetesEventBundler(event.InvolvedObject.UID, event.Source.Component)
@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"k8s.io/api/core/v1"
 	obj "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -257,3 +258,72 @@ func TestProcessEvent(t *testing.T) {
 	mocked.AssertNotCalled(t, "Event")
 	mocked.AssertExpectations(t)
 }
+
+func TestParseRemoteCluster(t *testing.T) {
+	kubeASCheck := &KubeASCheck{instance: &KubeASConfig{}}
+	require.NoError(t, kubeASCheck.instance.parse([]byte("tags: [\"foo:bar\"]")))
+	assert.Equal(t, []string{"foo:bar"}, kubeASCheck.instance.Tags)
+	assert.Equal(t, "event", kubeASCheck.eventTokenKey())
+
+	kubeASCheck = &KubeASCheck{instance: &KubeASConfig{}}
+	require.NoError(t, kubeASCheck.instance.parse([]byte("cluster: prod\ntags: [\"foo:bar\"]")))
+	assert.Equal(t, []string{"foo:bar", "kube_cluster_name:prod"}, kubeASCheck.instance.Tags)
+	assert.Equal(t, "event_prod", kubeASCheck.eventTokenKey())
+}
+
+type mockEventsClient struct {
+	mock.Mock
+}
+
+func (m *mockEventsClient) LatestEvents(since string) ([]*v1.Event, []*v1.Event, string, error) {
+	args := m.Called(since)
+	return args.Get(0).([]*v1.Event), args.Get(1).([]*v1.Event), args.String(2), args.Error(3)
+}
+
+type mockTokenStore struct {
+	mock.Mock
+}
+
+func (m *mockTokenStore) UpdateTokenInConfigmap(token, tokenValue string) error {
+	return m.Called(token, tokenValue).Error(0)
+}
+
+func TestCollectEvents(t *testing.T) {
+	kubeASCheck := &KubeASCheck{instance: &KubeASConfig{}, latestEventToken: "1000"}
+	ev := createEvent(1, "default", "dca-789976f5d7-2ljx6", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf4", "default-scheduler", "machine-blue", "Scheduled", 709662600)
+
+	// New events move the token and the checkpoint
+	client := &mockEventsClient{}
+	client.On("LatestEvents", "1000").Return([]*v1.Event{ev}, []*v1.Event{}, "1001", nil)
+	store := &mockTokenStore{}
+	store.On("UpdateTokenInConfigmap", "event", "1001").Return(nil)
+	newEvents, _, err := kubeASCheck.collectEvents(client, store)
+	require.NoError(t, err)
+	assert.Len(t, newEvents, 1)
+	assert.Equal(t, "1001", kubeASCheck.latestEventToken)
+	store.AssertExpectations(t)
+
+	// The watch timed out without events, nothing to store
+	client = &mockEventsClient{}
+	client.On("LatestEvents", "1001").Return([]*v1.Event{}, []*v1.Event{}, "1001", nil)
+	store = &mockTokenStore{}
+	newEvents, modifiedEvents, err := kubeASCheck.collectEvents(client, store)
+	require.NoError(t, err)
+	assert.Len(t, newEvents, 0)
+	assert.Len(t, modifiedEvents, 0)
+	assert.Equal(t, "1001", kubeASCheck.latestEventToken)
+	store.AssertNotCalled(t, "UpdateTokenInConfigmap", mock.Anything, mock.Anything)
+
+	// The resversion expired and the re-list has no newer event, the resversion
+	// of the re-list is kept
+	client = &mockEventsClient{}
+	client.On("LatestEvents", "1001").Return([]*v1.Event{}, []*v1.Event{}, "5000", nil)
+	store = &mockTokenStore{}
+	store.On("UpdateTokenInConfigmap", "event", "5000").Return(nil)
+	newEvents, modifiedEvents, err = kubeASCheck.collectEvents(client, store)
+	require.NoError(t, err)
+	assert.Len(t, newEvents, 0)
+	assert.Len(t, modifiedEvents, 0)
+	assert.Equal(t, "5000", kubeASCheck.latestEventToken)
+	store.AssertExpectations(t)
+}
@@ -0,0 +1,222 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+
+	log "github.com/cihub/seelog"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/clustername"
+)
+
+const certificatesCheckName = "kube_certificates"
+
+// CertificateConfig is the config of a certificate check instance, it inspects
+// the certificate of a file or the one served by an endpoint.
+type CertificateConfig struct {
+	Name string `yaml:"name"`
+	// Path of a PEM encoded certificate, or of a chain
+	CertPath string `yaml:"cert_path"`
+	// host:port of a TLS endpoint, queried when CertPath is not set
+	Endpoint   string `yaml:"endpoint"`
+	ServerName string `yaml:"server_name"`
+	Timeout    int    `yaml:"timeout"`
+	// The service check turns to warning and critical under these many days
+	WarningDays  int `yaml:"warning_days"`
+	CriticalDays int `yaml:"critical_days"`
+	// Interval between the runs in seconds, the default one if not set
+	MinCollectionInterval int      `yaml:"min_collection_interval"`
+	Tags                  []string `yaml:"tags"`
+}
+
+// CertificatesCheck reports the days until the expiry of a control plane
+// certificate, such as the serving certificates of the apiserver, etcd or the
+// kubelet, and turns its service check to warning then critical before it expires.
+type CertificatesCheck struct {
+	core.CheckBase
+	instance *CertificateConfig
+	// now is mocked in the tests
+	now func() time.Time
+}
+
+func (c *CertificateConfig) parse(data []byte) error {
+	// default values
+	c.Timeout = 10
+	c.WarningDays = 30
+	c.CriticalDays = 7
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	if c.CertPath == "" && c.Endpoint == "" {
+		return errors.New("either cert_path or endpoint must be set")
+	}
+	if c.Name == "" {
+		c.Name = c.CertPath
+		if c.Name == "" {
+			c.Name = c.Endpoint
+		}
+	}
+	if c.CriticalDays > c.WarningDays {
+		return fmt.Errorf("critical_days (%d) must not be greater than warning_days (%d)", c.CriticalDays, c.WarningDays)
+	}
+	return nil
+}
+
+// Configure parses the check configuration.
+func (c *CertificatesCheck) Configure(data, initConfig integration.Data) error {
+	c.BuildID(data, initConfig)
+
+	err := c.instance.parse(data)
+	if err != nil {
+		log.Errorf("could not parse the config for the %s check: %s", certificatesCheckName, err)
+		return err
+	}
+	c.instance.Tags = append(c.instance.Tags, fmt.Sprintf("certificate:%s", c.instance.Name))
+	if clusterName := clustername.GetClusterName(); clusterName != "" {
+		c.instance.Tags = append(c.instance.Tags, fmt.Sprintf("%s:%s", clusterNameTagKey, clusterName))
+	}
+
+	log.Debugf("Running config %s", data)
+	return nil
+}
+
+// Interval returns the min_collection_interval of the instance if it is set, the
+// certificates do not need to be inspected every few seconds.
+func (c *CertificatesCheck) Interval() time.Duration {
+	if c.instance.MinCollectionInterval > 0 {
+		return time.Duration(c.instance.MinCollectionInterval) * time.Second
+	}
+	return c.CheckBase.Interval()
+}
+
+// Run executes the check.
+func (c *CertificatesCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	certs, err := c.certificates()
+	if err != nil {
+		sender.ServiceCheck(certificatesCheckName+".expiry", metrics.ServiceCheckUnknown, "", c.instance.Tags, err.Error())
+		c.Warnf("Could not read the certificate %s: %s", c.instance.Name, err)
+		return err
+	}
+	c.report(sender, certs)
+	return nil
+}
+
+// report submits the days until the first expiry of the chain, and the service
+// check from the thresholds.
+func (c *CertificatesCheck) report(sender aggregator.Sender, certs []*x509.Certificate) {
+	first := certs[0]
+	for _, cert := range certs[1:] {
+		if cert.NotAfter.Before(first.NotAfter) {
+			first = cert
+		}
+	}
+	days := first.NotAfter.Sub(c.now()).Hours() / 24
+	sender.Gauge(certificatesCheckName+".days_until_expiry", days, "", c.instance.Tags)
+
+	status := metrics.ServiceCheckOK
+	message := ""
+	switch {
+	case days <= 0:
+		status = metrics.ServiceCheckCritical
+		message = fmt.Sprintf("The certificate %q expired on %s", first.Subject.CommonName, first.NotAfter.UTC())
+	case days <= float64(c.instance.CriticalDays):
+		status = metrics.ServiceCheckCritical
+	case days <= float64(c.instance.WarningDays):
+		status = metrics.ServiceCheckWarning
+	}
+	if status != metrics.ServiceCheckOK && message == "" {
+		message = fmt.Sprintf("The certificate %q expires in %d days, on %s", first.Subject.CommonName, int(days), first.NotAfter.UTC())
+	}
+	sender.ServiceCheck(certificatesCheckName+".expiry", status, "", c.instance.Tags, message)
+}
+
+// certificates returns the certificates of the file, or the ones presented by
+// the endpoint in the TLS handshake. They are not verified: expired or
+// self-signed certificates must still be reported.
+func (c *CertificatesCheck) certificates() ([]*x509.Certificate, error) {
+	if c.instance.CertPath != "" {
+		data, err := ioutil.ReadFile(c.instance.CertPath)
+		if err != nil {
+			return nil, err
+		}
+		return parsePEMCertificates(data)
+	}
+
+	dialer := &net.Dialer{Timeout: time.Duration(c.instance.Timeout) * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", c.instance.Endpoint, &tls.Config{
+		ServerName:         c.instance.ServerName,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate presented by %s", c.instance.Endpoint)
+	}
+	return certs, nil
+}
+
+// parsePEMCertificates returns the certificates of the PEM blocks of data, the
+// other blocks, such as private keys, are skipped.
+func parsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return certs, nil
+}
+
+func certificatesFactory() check.Check {
+	return &CertificatesCheck{
+		CheckBase: core.NewCheckBase(certificatesCheckName),
+		instance:  &CertificateConfig{},
+		now:       time.Now,
+	}
+}
+
+func init() {
+	core.RegisterCheck(certificatesCheckName, certificatesFactory)
+}
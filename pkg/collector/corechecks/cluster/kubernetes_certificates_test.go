@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+)
+
+func createCertificatePEM(t *testing.T, commonName string, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newCertificatesCheck(t *testing.T, instance string, now time.Time) *CertificatesCheck {
+	clusterNameKey := cache.BuildAgentKey("clustername")
+	cache.Cache.Set(clusterNameKey, "", cache.NoExpiration)
+	defer cache.Cache.Delete(clusterNameKey)
+
+	c := certificatesFactory().(*CertificatesCheck)
+	c.now = func() time.Time { return now }
+	require.NoError(t, c.Configure([]byte(instance), nil))
+	return c
+}
+
+func TestCertificatesConfig(t *testing.T) {
+	c := &CertificateConfig{}
+	require.NoError(t, c.parse([]byte("endpoint: localhost:10250")))
+	assert.Equal(t, "localhost:10250", c.Name)
+	assert.Equal(t, 30, c.WarningDays)
+	assert.Equal(t, 7, c.CriticalDays)
+
+	assert.Equal(t, 0, c.MinCollectionInterval)
+
+	assert.Error(t, (&CertificateConfig{}).parse([]byte("name: apiserver")))
+	assert.Error(t, (&CertificateConfig{}).parse([]byte("cert_path: /a.crt\nwarning_days: 5\ncritical_days: 10")))
+}
+
+func TestCertificatesFromPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certificates")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+	// the intermediate certificate of the chain expires first
+	chain := append(createCertificatePEM(t, "kube-apiserver", now.Add(60*24*time.Hour)), createCertificatePEM(t, "kubernetes", now.Add(20*24*time.Hour))...)
+	path := filepath.Join(dir, "apiserver.crt")
+	require.NoError(t, ioutil.WriteFile(path, chain, 0644))
+
+	for _, tc := range []struct {
+		now    time.Time
+		days   float64
+		status metrics.ServiceCheckStatus
+	}{
+		{now, 20, metrics.ServiceCheckWarning},
+		{now.Add(-20 * 24 * time.Hour), 40, metrics.ServiceCheckOK},
+		{now.Add(15 * 24 * time.Hour), 5, metrics.ServiceCheckCritical},
+		{now.Add(21 * 24 * time.Hour), -1, metrics.ServiceCheckCritical},
+	} {
+		c := newCertificatesCheck(t, fmt.Sprintf("name: apiserver\ncert_path: %s\ntags: [\"test\"]", path), tc.now)
+		certs, err := c.certificates()
+		require.NoError(t, err)
+		require.Len(t, certs, 2)
+
+		mocked := mocksender.NewMockSender(c.ID())
+		mocked.SetupAcceptAll()
+		c.report(mocked, certs)
+		mocked.AssertMetric(t, "Gauge", "kube_certificates.days_until_expiry", tc.days, "", []string{"test", "certificate:apiserver"})
+		mocked.AssertCalled(t, "ServiceCheck", "kube_certificates.expiry", tc.status, "", mocksender.MatchTagsContains([]string{"test", "certificate:apiserver"}), mock.AnythingOfType("string"))
+	}
+
+	_, err = parsePEMCertificates([]byte("not a certificate"))
+	assert.Error(t, err)
+}
+
+func TestCertificatesFromEndpoint(t *testing.T) {
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	c := newCertificatesCheck(t, fmt.Sprintf("endpoint: %s", ts.Listener.Addr()), time.Now())
+	certs, err := c.certificates()
+	require.NoError(t, err)
+	assert.Equal(t, ts.Certificate().NotAfter, certs[0].NotAfter)
+
+	mocked := mocksender.NewMockSender(c.ID())
+	mocked.SetupAcceptAll()
+	c.report(mocked, certs)
+	mocked.AssertServiceCheck(t, "kube_certificates.expiry", metrics.ServiceCheckOK, "", []string{fmt.Sprintf("certificate:%s", ts.Listener.Addr())}, "")
+}
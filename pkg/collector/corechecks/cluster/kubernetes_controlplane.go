@@ -0,0 +1,302 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/clustername"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+// Names of the control plane checks, they are also the prefix of their metrics
+// and service checks.
+const (
+	etcdCheckName              = "kube_etcd"
+	schedulerCheckName         = "kube_scheduler"
+	controllerManagerCheckName = "kube_controller_manager"
+)
+
+type promMetricType int
+
+const (
+	promGauge promMetricType = iota
+	promMonotonicCount
+)
+
+// promMetric maps a metric of the prometheus endpoint of a component to a
+// datadog metric, its labels are submitted as tags.
+type promMetric struct {
+	name   string
+	kind   promMetricType
+	labels map[string]string // prometheus label -> tag name
+}
+
+// controlPlaneComponent describes the metrics of a component.
+type controlPlaneComponent struct {
+	checkName string
+	metrics   map[string]promMetric
+}
+
+var leaderElectionMetric = promMetric{"leader_election.is_leader", promGauge, map[string]string{"name": "lease"}}
+
+var controlPlaneComponents = map[string]controlPlaneComponent{
+	etcdCheckName: {
+		checkName: etcdCheckName,
+		metrics: map[string]promMetric{
+			"etcd_server_has_leader":                     {"server.has_leader", promGauge, nil},
+			"etcd_server_leader_changes_seen_total":      {"server.leader_changes", promMonotonicCount, nil},
+			"etcd_server_proposals_committed_total":      {"server.proposals.committed", promMonotonicCount, nil},
+			"etcd_server_proposals_failed_total":         {"server.proposals.failed", promMonotonicCount, nil},
+			"etcd_server_proposals_pending":              {"server.proposals.pending", promGauge, nil},
+			"etcd_mvcc_db_total_size_in_bytes":           {"mvcc.db_total_size", promGauge, nil},
+			"etcd_debugging_mvcc_db_total_size_in_bytes": {"mvcc.db_total_size", promGauge, nil},
+		},
+	},
+	schedulerCheckName: {
+		checkName: schedulerCheckName,
+		metrics: map[string]promMetric{
+			"scheduler_schedule_attempts_total":                   {"schedule_attempts", promMonotonicCount, map[string]string{"result": "result"}},
+			"scheduler_e2e_scheduling_latency_microseconds_sum":   {"scheduling.e2e_latency.sum", promMonotonicCount, nil},
+			"scheduler_e2e_scheduling_latency_microseconds_count": {"scheduling.e2e_latency.count", promMonotonicCount, nil},
+			"scheduler_pending_pods":                              {"pending_pods", promGauge, map[string]string{"queue": "queue"}},
+			"leader_election_master_status":                       leaderElectionMetric,
+		},
+	},
+	controllerManagerCheckName: {
+		checkName: controllerManagerCheckName,
+		metrics: map[string]promMetric{
+			"workqueue_depth":               {"queue.depth", promGauge, map[string]string{"name": "queue"}},
+			"workqueue_adds_total":          {"queue.adds", promMonotonicCount, map[string]string{"name": "queue"}},
+			"workqueue_retries_total":       {"queue.retries", promMonotonicCount, map[string]string{"name": "queue"}},
+			"leader_election_master_status": leaderElectionMetric,
+		},
+	},
+}
+
+// ControlPlaneConfig is the config of a control plane check instance.
+type ControlPlaneConfig struct {
+	HealthURL  string   `yaml:"health_url"`
+	MetricsURL string   `yaml:"metrics_url"`
+	Tags       []string `yaml:"tags"`
+	// TLS and bearer token authentication against the endpoints
+	TLSVerify       bool   `yaml:"tls_verify"`
+	CACert          string `yaml:"ssl_ca_cert"`
+	Cert            string `yaml:"ssl_cert"`
+	PrivateKey      string `yaml:"ssl_private_key"`
+	BearerTokenAuth bool   `yaml:"bearer_token_auth"`
+	BearerTokenPath string `yaml:"bearer_token_path"`
+	Timeout         int    `yaml:"timeout"`
+}
+
+// ControlPlaneCheck reports the health and the key metrics of a control plane
+// component, on the leader only as they are cluster-wide.
+type ControlPlaneCheck struct {
+	core.CheckBase
+	component controlPlaneComponent
+	instance  *ControlPlaneConfig
+	client    *http.Client
+	token     string
+}
+
+func (c *ControlPlaneConfig) parse(data []byte) error {
+	// default values
+	c.TLSVerify = true
+	c.BearerTokenPath = kubernetes.ServiceAccountTokenPath
+	c.Timeout = 10
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+	// the components mostly listen on the control plane nodes, the endpoints
+	// can't be guessed from the agent running the check
+	if c.HealthURL == "" {
+		return fmt.Errorf("health_url is required")
+	}
+	return nil
+}
+
+// Configure parses the check configuration and builds the http client of the endpoints.
+func (c *ControlPlaneCheck) Configure(data, initConfig integration.Data) error {
+	c.BuildID(data, initConfig)
+
+	err := c.instance.parse(data)
+	if err != nil {
+		log.Errorf("could not parse the config for the %s check: %s", c.component.checkName, err)
+		return err
+	}
+	if clusterName := clustername.GetClusterName(); clusterName != "" {
+		c.instance.Tags = append(c.instance.Tags, fmt.Sprintf("%s:%s", clusterNameTagKey, clusterName))
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: !c.instance.TLSVerify}
+	if c.instance.CACert != "" {
+		tlsConfig.RootCAs, err = kubernetes.GetCertificateAuthority(c.instance.CACert)
+		if err != nil {
+			return err
+		}
+	}
+	if c.instance.Cert != "" && c.instance.PrivateKey != "" {
+		tlsConfig.Certificates, err = kubernetes.GetCertificates(c.instance.Cert, c.instance.PrivateKey)
+		if err != nil {
+			return err
+		}
+	}
+	if c.instance.BearerTokenAuth {
+		c.token, err = kubernetes.GetBearerToken(c.instance.BearerTokenPath)
+		if err != nil {
+			return err
+		}
+		c.token = strings.TrimSpace(c.token)
+	}
+	c.client = &http.Client{
+		Timeout:   time.Duration(c.instance.Timeout) * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+
+	log.Debugf("Running config %s", data)
+	return nil
+}
+
+// Run executes the check.
+func (c *ControlPlaneCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	// Only run if Leader Election is enabled.
+	if !config.Datadog.GetBool("leader_election") {
+		c.Warnf("Leader Election not enabled. Not running the %s check.", c.component.checkName)
+		return nil
+	}
+	if err = runLeaderElection(&c.CheckBase); err != nil {
+		if err == apiserver.ErrNotLeader {
+			return nil
+		}
+		return err
+	}
+	defer sender.Commit()
+
+	c.reportHealth(sender)
+	if c.instance.MetricsURL == "" {
+		return nil
+	}
+	body, err := c.get(c.instance.MetricsURL)
+	if err != nil {
+		c.Warnf("Could not collect the metrics of %s: %s", c.instance.MetricsURL, err)
+		return err
+	}
+	samples, err := parsePrometheusText(body)
+	if err != nil {
+		c.Warnf("Could not parse the metrics of %s: %s", c.instance.MetricsURL, err)
+		return err
+	}
+	c.reportMetrics(sender, samples)
+	return nil
+}
+
+// reportHealth submits the `<check>.up` service check from the health endpoint,
+// etcd also reports its health in the response body.
+func (c *ControlPlaneCheck) reportHealth(sender aggregator.Sender) {
+	serviceCheck := c.component.checkName + ".up"
+	body, err := c.get(c.instance.HealthURL)
+	if err != nil {
+		sender.ServiceCheck(serviceCheck, metrics.ServiceCheckCritical, "", c.instance.Tags, err.Error())
+		return
+	}
+	if c.component.checkName == etcdCheckName {
+		health := struct {
+			Health string `json:"health"`
+		}{}
+		if err = json.Unmarshal(body, &health); err != nil || health.Health != "true" {
+			sender.ServiceCheck(serviceCheck, metrics.ServiceCheckCritical, "", c.instance.Tags, fmt.Sprintf("etcd is unhealthy: %s", body))
+			return
+		}
+	}
+	sender.ServiceCheck(serviceCheck, metrics.ServiceCheckOK, "", c.instance.Tags, "")
+}
+
+func (c *ControlPlaneCheck) reportMetrics(sender aggregator.Sender, samples []promSample) {
+	for _, sample := range samples {
+		metric, found := c.component.metrics[sample.name]
+		if !found {
+			continue
+		}
+		tags := make([]string, 0, len(c.instance.Tags)+len(metric.labels))
+		tags = append(tags, c.instance.Tags...)
+		for label, tag := range metric.labels {
+			if value, found := sample.labels[label]; found {
+				tags = append(tags, fmt.Sprintf("%s:%s", tag, value))
+			}
+		}
+		name := fmt.Sprintf("%s.%s", c.component.checkName, metric.name)
+		switch metric.kind {
+		case promGauge:
+			sender.Gauge(name, sample.value, "", tags)
+		case promMonotonicCount:
+			sender.MonotonicCount(name, sample.value, "", tags)
+		}
+	}
+}
+
+func (c *ControlPlaneCheck) get(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+func controlPlaneFactory(name string) func() check.Check {
+	return func() check.Check {
+		return &ControlPlaneCheck{
+			CheckBase: core.NewCheckBase(name),
+			component: controlPlaneComponents[name],
+			instance:  &ControlPlaneConfig{},
+		}
+	}
+}
+
+func init() {
+	for name := range controlPlaneComponents {
+		core.RegisterCheck(name, controlPlaneFactory(name))
+	}
+}
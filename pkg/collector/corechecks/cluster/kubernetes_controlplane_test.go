@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+)
+
+const schedulerMetrics = `# HELP scheduler_schedule_attempts_total Number of attempts to schedule pods, by the result.
+# TYPE scheduler_schedule_attempts_total counter
+scheduler_schedule_attempts_total{result="error"} 0
+scheduler_schedule_attempts_total{result="scheduled"} 42
+scheduler_schedule_attempts_total{result="unschedulable"} 3
+# TYPE scheduler_e2e_scheduling_latency_microseconds summary
+scheduler_e2e_scheduling_latency_microseconds{quantile="0.5"} NaN
+scheduler_e2e_scheduling_latency_microseconds_sum 123456
+scheduler_e2e_scheduling_latency_microseconds_count 45
+leader_election_master_status{name="kube-scheduler"} 1
+go_goroutines 87
+`
+
+func TestParsePrometheusText(t *testing.T) {
+	samples, err := parsePrometheusText([]byte(schedulerMetrics))
+	require.NoError(t, err)
+	require.Len(t, samples, 7)
+	assert.Equal(t, promSample{"scheduler_schedule_attempts_total", map[string]string{"result": "scheduled"}, 42}, samples[1])
+	assert.Equal(t, promSample{"go_goroutines", nil, 87}, samples[6])
+
+	samples, err = parsePrometheusText([]byte(`workqueue_depth{name="a \"quoted\", name",other="x"} 2 1395066363000`))
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, map[string]string{"name": `a "quoted", name`, "other": "x"}, samples[0].labels)
+	assert.Equal(t, 2.0, samples[0].value)
+
+	for _, invalid := range []string{`workqueue_depth{name="a" 2`, `workqueue_depth`, `workqueue_depth one`, `{name="a"} 1`} {
+		_, err = parsePrometheusText([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestControlPlaneCheck(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, schedulerMetrics)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	clusterNameKey := cache.BuildAgentKey("clustername")
+	cache.Cache.Set(clusterNameKey, "prod", cache.NoExpiration)
+	defer cache.Cache.Delete(clusterNameKey)
+
+	c := controlPlaneFactory(schedulerCheckName)().(*ControlPlaneCheck)
+	instance := fmt.Sprintf("health_url: %s/healthz\nmetrics_url: %s/metrics\ntags: [\"test\"]", ts.URL, ts.URL)
+	require.NoError(t, c.Configure([]byte(instance), nil))
+	assert.True(t, c.instance.TLSVerify)
+	assert.Equal(t, 10, c.instance.Timeout)
+	assert.Equal(t, []string{"test", "kube_cluster_name:prod"}, c.instance.Tags)
+
+	mocked := mocksender.NewMockSender(c.ID())
+	mocked.SetupAcceptAll()
+	c.reportHealth(mocked)
+	mocked.AssertServiceCheck(t, "kube_scheduler.up", metrics.ServiceCheckOK, "", []string{"test"}, "")
+
+	body, err := c.get(c.instance.MetricsURL)
+	require.NoError(t, err)
+	samples, err := parsePrometheusText(body)
+	require.NoError(t, err)
+	c.reportMetrics(mocked, samples)
+	mocked.AssertMetric(t, "MonotonicCount", "kube_scheduler.schedule_attempts", 42, "", []string{"test", "result:scheduled"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_scheduler.schedule_attempts", 3, "", []string{"test", "result:unschedulable"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_scheduler.scheduling.e2e_latency.sum", 123456, "", []string{"test"})
+	mocked.AssertMetric(t, "MonotonicCount", "kube_scheduler.scheduling.e2e_latency.count", 45, "", []string{"test"})
+	mocked.AssertMetric(t, "Gauge", "kube_scheduler.leader_election.is_leader", 1, "", []string{"test", "lease:kube-scheduler"})
+	mocked.AssertNumberOfCalls(t, "MonotonicCount", 5)
+	mocked.AssertNumberOfCalls(t, "Gauge", 1)
+}
+
+func TestEtcdHealth(t *testing.T) {
+	healthy := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"health": "%t"}`, healthy)
+	}))
+	defer ts.Close()
+
+	clusterNameKey := cache.BuildAgentKey("clustername")
+	cache.Cache.Set(clusterNameKey, "", cache.NoExpiration)
+	defer cache.Cache.Delete(clusterNameKey)
+
+	c := controlPlaneFactory(etcdCheckName)().(*ControlPlaneCheck)
+	require.NoError(t, c.Configure([]byte(fmt.Sprintf("health_url: %s/health", ts.URL)), nil))
+	assert.Empty(t, c.instance.MetricsURL)
+
+	mocked := mocksender.NewMockSender(c.ID())
+	mocked.SetupAcceptAll()
+	c.reportHealth(mocked)
+	mocked.AssertServiceCheck(t, "kube_etcd.up", metrics.ServiceCheckOK, "", nil, "")
+
+	healthy = false
+	mocked = mocksender.NewMockSender(c.ID())
+	mocked.SetupAcceptAll()
+	c.reportHealth(mocked)
+	mocked.AssertServiceCheck(t, "kube_etcd.up", metrics.ServiceCheckCritical, "", nil, `etcd is unhealthy: {"health": "false"}`)
+}
+
+func TestControlPlaneCheckRequiresHealthURL(t *testing.T) {
+	c := controlPlaneFactory(etcdCheckName)().(*ControlPlaneCheck)
+	assert.EqualError(t, c.Configure([]byte("metrics_url: https://10.0.0.1:2379/metrics"), nil), "health_url is required")
+}
@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/cihub/seelog"
+	"k8s.io/api/core/v1"
+)
+
+const (
+	eventFilterDrop   = "drop"
+	eventFilterKeep   = "keep"
+	eventFilterSample = "sample"
+)
+
+// EventFilterRule selects Kubernetes events to drop, keep or sample before they
+// are sent as Datadog events. The selectors of a rule must all match.
+type EventFilterRule struct {
+	Namespace      string `yaml:"namespace"`       // glob matched against the namespace of the involved object
+	Kind           string `yaml:"kind"`            // kind of the involved object
+	Reason         string `yaml:"reason"`          // reason of the event
+	InvolvedObject string `yaml:"involved_object"` // glob matched against the name of the involved object
+	MinCount       int32  `yaml:"min_count"`       // only match the events seen at least this many times
+	// Action is drop (default), keep or sample. SampleRate is the ratio of the
+	// matching events kept by the sample action.
+	Action     string  `yaml:"action"`
+	SampleRate float64 `yaml:"sample_rate"`
+}
+
+type eventFilterMatcher struct {
+	namespace      *regexp.Regexp
+	kind           string
+	reason         string
+	involvedObject *regexp.Regexp
+	minCount       int32
+	action         string
+	sampleRate     float64
+	// matched counts the matching events, to keep an evenly spread ratio of them
+	matched int64
+}
+
+// eventFilter applies the `event_filters` rules of the check instance, the first
+// matching rule decides of the fate of an event. Events matching no rule are kept.
+type eventFilter struct {
+	matchers []*eventFilterMatcher
+}
+
+// newEventFilter compiles the rules. Rules without any selector, that would
+// match every event, are ignored.
+func newEventFilter(rules []EventFilterRule) (*eventFilter, error) {
+	f := &eventFilter{}
+	for i, rule := range rules {
+		if rule.Namespace == "" && rule.Kind == "" && rule.Reason == "" && rule.InvolvedObject == "" && rule.MinCount <= 0 {
+			log.Warnf("Ignoring the event filter %d, it has no selector", i)
+			continue
+		}
+		m := &eventFilterMatcher{
+			kind:       rule.Kind,
+			reason:     rule.Reason,
+			minCount:   rule.MinCount,
+			action:     strings.ToLower(rule.Action),
+			sampleRate: rule.SampleRate,
+		}
+		switch m.action {
+		case "":
+			m.action = eventFilterDrop
+		case eventFilterDrop, eventFilterKeep:
+		case eventFilterSample:
+			if m.sampleRate <= 0 || m.sampleRate > 1 {
+				return nil, fmt.Errorf("the sample_rate of the event filter %d must be in ]0, 1], got %v", i, m.sampleRate)
+			}
+		default:
+			return nil, fmt.Errorf("unknown action %q for the event filter %d, must be drop, keep or sample", rule.Action, i)
+		}
+		if rule.Namespace != "" {
+			m.namespace = eventFilterGlob(rule.Namespace)
+		}
+		if rule.InvolvedObject != "" {
+			m.involvedObject = eventFilterGlob(rule.InvolvedObject)
+		}
+		f.matchers = append(f.matchers, m)
+	}
+	return f, nil
+}
+
+// keep returns whether the event should be sent.
+func (f *eventFilter) keep(event *v1.Event) bool {
+	if f == nil {
+		return true
+	}
+	for _, m := range f.matchers {
+		if !m.matches(event) {
+			continue
+		}
+		switch m.action {
+		case eventFilterKeep:
+			return true
+		case eventFilterSample:
+			// keep an event each time the count of kept ones falls behind the rate
+			m.matched++
+			return int64(float64(m.matched)*m.sampleRate) > int64(float64(m.matched-1)*m.sampleRate)
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (m *eventFilterMatcher) matches(event *v1.Event) bool {
+	if m.namespace != nil && !m.namespace.MatchString(event.InvolvedObject.Namespace) {
+		return false
+	}
+	if m.kind != "" && m.kind != event.InvolvedObject.Kind {
+		return false
+	}
+	if m.reason != "" && m.reason != event.Reason {
+		return false
+	}
+	if m.involvedObject != nil && !m.involvedObject.MatchString(event.InvolvedObject.Name) {
+		return false
+	}
+	return event.Count >= m.minCount
+}
+
+// eventFilterGlob compiles a glob pattern where `*` matches any string
+func eventFilterGlob(glob string) *regexp.Regexp {
+	pattern := strings.Replace(regexp.QuoteMeta(glob), `\*`, ".*", -1)
+	return regexp.MustCompile("^" + pattern + "$")
+}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestEventFilter(t *testing.T) {
+	instance := &KubeASConfig{}
+	require.NoError(t, instance.parse([]byte(`
+event_filters:
+  - namespace: kube-*
+    involved_object: coredns-*
+    action: keep
+  - reason: BackOff
+    action: sample
+    sample_rate: 0.25
+  - kind: Node
+    min_count: 10
+  - action: drop
+`)))
+	// the rule without selector is ignored
+	require.Len(t, instance.eventFilter.matchers, 3)
+
+	backOff := createEvent(1, "default", "web-1", "Pod", "uid-1", "kubelet", "BackOff", "Back-off restarting failed container", 709662600)
+	kept := 0
+	for i := 0; i < 8; i++ {
+		if instance.eventFilter.keep(backOff) {
+			kept++
+		}
+	}
+	assert.Equal(t, 2, kept)
+
+	coreDNSBackOff := createEvent(1, "kube-system", "coredns-5c98db65d4-2ljx6", "Pod", "uid-2", "kubelet", "BackOff", "Back-off restarting failed container", 709662600)
+	for i := 0; i < 4; i++ {
+		assert.True(t, instance.eventFilter.keep(coreDNSBackOff))
+	}
+
+	assert.True(t, instance.eventFilter.keep(createEvent(9, "", "node-1", "Node", "uid-3", "kubelet", "NodeNotReady", "", 709662600)))
+	assert.False(t, instance.eventFilter.keep(createEvent(10, "", "node-1", "Node", "uid-3", "kubelet", "NodeNotReady", "", 709662600)))
+	assert.True(t, instance.eventFilter.keep(createEvent(1, "default", "web-1", "Pod", "uid-1", "kubelet", "Started", "", 709662600)))
+
+	assert.Error(t, instance.parse([]byte("event_filters: [{reason: BackOff, action: sample}]")))
+	assert.Error(t, instance.parse([]byte("event_filters: [{reason: BackOff, action: ignore}]")))
+}
+
+func TestProcessFilteredEvents(t *testing.T) {
+	ev1 := createEvent(1, "default", "web-1", "Pod", "uid-1", "kubelet", "BackOff", "Back-off restarting failed container", 709662600)
+	ev2 := createEvent(1, "default", "web-1", "Pod", "uid-1", "kubelet", "Started", "Started container", 709662600)
+
+	kubeASCheck := &KubeASCheck{
+		instance:  &KubeASConfig{},
+		CheckBase: core.NewCheckBase(kubernetesAPIServerCheckName),
+	}
+	require.NoError(t, kubeASCheck.instance.parse([]byte("event_filters: [{reason: BackOff}]")))
+
+	mocked := mocksender.NewMockSender(kubeASCheck.ID())
+	mocked.On("Event", mock.AnythingOfType("metrics.Event"))
+	kubeASCheck.processEvents(mocked, []*v1.Event{ev1, ev2}, false)
+	mocked.AssertNumberOfCalls(t, "Event", 1)
+	res := mocked.Calls[0].Arguments.Get(0).(metrics.Event).Text
+	assert.Contains(t, res, "1 **Started**")
+	assert.NotContains(t, res, "BackOff")
+}
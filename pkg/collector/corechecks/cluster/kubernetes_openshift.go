@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	log "github.com/cihub/seelog"
+	"k8s.io/api/core/v1"
+)
+
+const (
+	clusterQuotaMetricPrefix        = "openshift.clusterquota"
+	appliedClusterQuotaMetricPrefix = "openshift.appliedclusterquota"
+	routeAdmittedMetric             = "openshift.route.admitted"
+)
+
+// collectOpenShift reports the ClusterResourceQuotas and the routes of OpenShift
+// clusters, the OpenShift APIs are detected on the first run, and on the next
+// ones until the apiserver answers.
+func (k *KubeASCheck) collectOpenShift(sender aggregator.Sender) {
+	if !k.oshiftDetected {
+		level, err := k.ac.DetectOpenShiftAPILevel()
+		if err != nil {
+			k.Warnf("Could not detect the OpenShift APIs, retrying on the next run: %s", err)
+			return
+		}
+		k.oshiftAPILevel = level
+		k.oshiftDetected = true
+		if k.oshiftAPILevel != apiserver.NotOpenShift {
+			log.Infof("OpenShift APIs detected, collecting the cluster quotas and routes")
+		}
+	}
+	if k.oshiftAPILevel == apiserver.NotOpenShift {
+		return
+	}
+
+	// ClusterResourceQuotas are cluster-scoped.
+	if !k.ac.IsNamespaceScoped() {
+		quotas, err := k.ac.ListOShiftClusterQuotas(k.oshiftAPILevel)
+		if err != nil {
+			k.Warnf("Could not collect the OpenShift cluster quotas: %s", err)
+		} else {
+			k.reportClusterQuotas(sender, quotas)
+		}
+	}
+
+	routes, err := k.ac.ListOShiftRoutes(k.oshiftAPILevel)
+	if err != nil {
+		k.Warnf("Could not collect the OpenShift routes: %s", err)
+		return
+	}
+	k.reportRoutes(sender, routes)
+}
+
+// reportClusterQuotas sends the limit, usage and remaining quantity of each resource
+// of the quotas, and the limit and usage of the namespaces the quotas apply to.
+func (k *KubeASCheck) reportClusterQuotas(sender aggregator.Sender, quotas []apiserver.ClusterResourceQuota) {
+	for _, quota := range quotas {
+		quotaTags := appendTags(k.instance.Tags, fmt.Sprintf("clusterquota:%s", quota.Name))
+		remaining := make(v1.ResourceList)
+		for resource, hard := range quota.Spec.Quota.Hard {
+			remaining[resource] = hard
+		}
+		for resource, used := range quota.Status.Total.Used {
+			if hard, found := remaining[resource]; found {
+				hard.Sub(used)
+				remaining[resource] = hard
+			}
+		}
+
+		reportQuantities(sender, clusterQuotaMetricPrefix, "limit", quota.Spec.Quota.Hard, quotaTags)
+		reportQuantities(sender, clusterQuotaMetricPrefix, "used", quota.Status.Total.Used, quotaTags)
+		reportQuantities(sender, clusterQuotaMetricPrefix, "remaining", remaining, quotaTags)
+
+		for _, namespace := range quota.Status.Namespaces {
+			namespaceTags := appendTags(quotaTags, fmt.Sprintf("kube_namespace:%s", namespace.Namespace))
+			reportQuantities(sender, appliedClusterQuotaMetricPrefix, "limit", namespace.Status.Hard, namespaceTags)
+			reportQuantities(sender, appliedClusterQuotaMetricPrefix, "used", namespace.Status.Used, namespaceTags)
+		}
+	}
+}
+
+// reportRoutes sends whether each route is admitted by a router, tagged with its
+// host and target service.
+func (k *KubeASCheck) reportRoutes(sender aggregator.Sender, routes []apiserver.Route) {
+	for _, route := range routes {
+		tags := appendTags(k.instance.Tags,
+			fmt.Sprintf("route:%s", route.Name),
+			fmt.Sprintf("kube_namespace:%s", route.Namespace),
+			fmt.Sprintf("route_host:%s", route.Spec.Host),
+		)
+		if route.Spec.To.Kind == "Service" {
+			tags = append(tags, fmt.Sprintf("kube_service:%s", route.Spec.To.Name))
+		}
+
+		admitted := 0.0
+	INGRESSES:
+		for _, ingress := range route.Status.Ingress {
+			for _, condition := range ingress.Conditions {
+				if condition.Type == "Admitted" && condition.Status == "True" {
+					admitted = 1.0
+					break INGRESSES
+				}
+			}
+		}
+		sender.Gauge(routeAdmittedMetric, admitted, "", tags)
+	}
+}
+
+func reportQuantities(sender aggregator.Sender, prefix, suffix string, quantities v1.ResourceList, tags []string) {
+	for resource, quantity := range quantities {
+		// extended resources and object counts hold slashes, e.g. count/deployments.apps
+		name := strings.Replace(string(resource), "/", "_", -1)
+		metric := fmt.Sprintf("%s.%s.%s", prefix, name, suffix)
+		sender.Gauge(metric, float64(quantity.MilliValue())/1000, "", tags)
+	}
+}
+
+// appendTags returns a copy of tags with the extra tags, so that the tags of the
+// metrics sent in a loop do not share their backing array.
+func appendTags(tags []string, extra ...string) []string {
+	result := make([]string, 0, len(tags)+len(extra))
+	result = append(result, tags...)
+	return append(result, extra...)
+}
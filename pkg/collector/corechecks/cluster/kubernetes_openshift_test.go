@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package cluster
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	obj "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+func TestReportClusterQuotas(t *testing.T) {
+	quotas := []apiserver.ClusterResourceQuota{{
+		ObjectMeta: obj.ObjectMeta{Name: "for-user"},
+		Spec: apiserver.ClusterResourceQuotaSpec{
+			Quota: v1.ResourceQuotaSpec{Hard: v1.ResourceList{
+				"pods":                         resource.MustParse("10"),
+				"limits.cpu":                   resource.MustParse("2"),
+				"count/deployments.extensions": resource.MustParse("5"),
+			}},
+		},
+		Status: apiserver.ClusterResourceQuotaStatus{
+			Total: v1.ResourceQuotaStatus{Used: v1.ResourceList{
+				"pods":       resource.MustParse("3"),
+				"limits.cpu": resource.MustParse("500m"),
+			}},
+			Namespaces: []apiserver.ResourceQuotaStatusByNamespace{{
+				Namespace: "app",
+				Status: v1.ResourceQuotaStatus{
+					Hard: v1.ResourceList{"pods": resource.MustParse("10")},
+					Used: v1.ResourceList{"pods": resource.MustParse("3")},
+				},
+			}},
+		},
+	}}
+
+	kubeASCheck := &KubeASCheck{
+		instance:  &KubeASConfig{Tags: []string{"test"}},
+		CheckBase: core.NewCheckBase(kubernetesAPIServerCheckName),
+	}
+	mocked := mocksender.NewMockSender(kubeASCheck.ID())
+	mocked.SetupAcceptAll()
+	kubeASCheck.reportClusterQuotas(mocked, quotas)
+
+	quotaTags := []string{"test", "clusterquota:for-user"}
+	mocked.AssertMetric(t, "Gauge", "openshift.clusterquota.pods.limit", 10, "", quotaTags)
+	mocked.AssertMetric(t, "Gauge", "openshift.clusterquota.pods.used", 3, "", quotaTags)
+	mocked.AssertMetric(t, "Gauge", "openshift.clusterquota.pods.remaining", 7, "", quotaTags)
+	mocked.AssertMetric(t, "Gauge", "openshift.clusterquota.limits.cpu.used", 0.5, "", quotaTags)
+	mocked.AssertMetric(t, "Gauge", "openshift.clusterquota.limits.cpu.remaining", 1.5, "", quotaTags)
+	mocked.AssertMetric(t, "Gauge", "openshift.clusterquota.count_deployments.extensions.limit", 5, "", quotaTags)
+
+	namespaceTags := []string{"test", "clusterquota:for-user", "kube_namespace:app"}
+	mocked.AssertMetric(t, "Gauge", "openshift.appliedclusterquota.pods.limit", 10, "", namespaceTags)
+	mocked.AssertMetric(t, "Gauge", "openshift.appliedclusterquota.pods.used", 3, "", namespaceTags)
+	mocked.AssertNumberOfCalls(t, "Gauge", 9)
+}
+
+func TestReportRoutes(t *testing.T) {
+	admitted := apiserver.Route{
+		ObjectMeta: obj.ObjectMeta{Name: "web", Namespace: "app"},
+		Spec: apiserver.RouteSpec{
+			Host: "web.apps.example.com",
+			To:   apiserver.RouteTargetReference{Kind: "Service", Name: "web-svc"},
+		},
+		Status: apiserver.RouteStatus{Ingress: []apiserver.RouteIngress{{
+			RouterName: "router",
+			Conditions: []apiserver.RouteIngressCondition{{Type: "Admitted", Status: "True"}},
+		}}},
+	}
+	pending := apiserver.Route{
+		ObjectMeta: obj.ObjectMeta{Name: "api", Namespace: "app"},
+		Spec:       apiserver.RouteSpec{Host: "api.apps.example.com"},
+	}
+
+	kubeASCheck := &KubeASCheck{
+		instance:  &KubeASConfig{Tags: []string{"test"}},
+		CheckBase: core.NewCheckBase(kubernetesAPIServerCheckName),
+	}
+	mocked := mocksender.NewMockSender(kubeASCheck.ID())
+	mocked.SetupAcceptAll()
+	kubeASCheck.reportRoutes(mocked, []apiserver.Route{admitted, pending})
+
+	mocked.AssertMetric(t, "Gauge", "openshift.route.admitted", 1, "", []string{"test", "route:web", "kube_namespace:app", "route_host:web.apps.example.com", "kube_service:web-svc"})
+	mocked.AssertMetric(t, "Gauge", "openshift.route.admitted", 0, "", []string{"test", "route:api", "kube_namespace:app", "route_host:api.apps.example.com"})
+}
@@ -23,6 +23,7 @@ import (
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 )
 
@@ -89,7 +90,7 @@ func updateContainerRunningCount(images map[string]*containerPerImage, c *docker
 			fmt.Sprintf("short_image:%s", short),
 		}
 	} else {
-		containerTags, err = tagger.Tag(c.EntityID, false)
+		containerTags, err = tagger.Tag(c.EntityID, collectors.LowCardinality)
 		if err != nil {
 			log.Errorf("Could not collect tags for container %s: %s", c.ID[:12], err)
 			return
@@ -171,7 +172,7 @@ func (d *DockerCheck) Run() error {
 		if c.State != docker.ContainerRunningState || c.Excluded {
 			continue
 		}
-		tags, err := tagger.Tag(c.EntityID, true)
+		tags, err := tagger.Tag(c.EntityID, collectors.HighCardinality)
 		if err != nil {
 			log.Errorf("Could not collect tags for container %s: %s", c.ID[:12], err)
 		}
@@ -18,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 )
 
@@ -62,7 +63,7 @@ func (d *DockerCheck) reportExitCodes(events []*docker.ContainerEvent, sender ag
 		if exitCodeInt != 0 {
 			status = metrics.ServiceCheckCritical
 		}
-		tags, err := tagger.Tag(ev.ContainerEntityName(), true)
+		tags, err := tagger.Tag(ev.ContainerEntityName(), collectors.HighCardinality)
 		tags = append(tags, d.instance.Tags...)
 		if err != nil {
 			log.Debugf("no tags for %s: %s", ev.ContainerID, err)
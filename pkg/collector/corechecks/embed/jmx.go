@@ -12,6 +12,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	api "github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -48,15 +49,29 @@ type JMXCheck struct {
 	running     uint32
 	stop        chan struct{}
 	stopDone    chan struct{}
+	// restart is notified when the session token is rotated, JMXFetch only
+	// reads it at launch
+	restart chan struct{}
 }
 
 var jmxLauncher = JMXCheck{
 	checks:   make(map[string]struct{}),
 	stop:     make(chan struct{}),
 	stopDone: make(chan struct{}),
+	restart:  make(chan struct{}, 1),
 	runner:   &jmxfetch.JMXFetch{},
 }
 
+func init() {
+	api.OnAuthTokenRotation(func() {
+		select {
+		case jmxLauncher.restart <- struct{}{}:
+		default:
+			// a restart is already pending
+		}
+	})
+}
+
 func (c *JMXCheck) String() string {
 	return "JMX Check"
 }
@@ -89,30 +104,39 @@ func (c *JMXCheck) run() error {
 	c.runner.LogLevel = config.Datadog.GetString("log_level")
 	c.runner.JmxExitFile = jmxExitFile
 
-	err := c.runner.Start()
-	if err != nil {
-		return retryExitError(err)
-	}
-
-	processDone := make(chan error)
-	go func() {
-		processDone <- c.runner.Wait()
-	}()
-
-	select {
-	case err = <-processDone:
-		return retryExitError(err)
-	case <-c.stop:
-		err = c.runner.Kill()
+	for {
+		err := c.runner.Start()
 		if err != nil {
-			log.Errorf("unable to stop JMX check: %s", err)
+			return retryExitError(err)
 		}
-	}
 
-	// wait for process to exit
-	err = <-processDone
-	c.stopDone <- struct{}{}
-	return err
+		processDone := make(chan error)
+		go func() {
+			processDone <- c.runner.Wait()
+		}()
+
+		select {
+		case err = <-processDone:
+			return retryExitError(err)
+		case <-c.restart:
+			log.Info("Restarting JMX with the rotated session token")
+			if err = c.runner.Kill(); err != nil {
+				log.Errorf("unable to stop JMX check: %s", err)
+			}
+			<-processDone
+			continue
+		case <-c.stop:
+			err = c.runner.Kill()
+			if err != nil {
+				log.Errorf("unable to stop JMX check: %s", err)
+			}
+		}
+
+		// wait for process to exit
+		err = <-processDone
+		c.stopDone <- struct{}{}
+		return err
+	}
 }
 
 func (c *JMXCheck) Parse(data, initConfig integration.Data) error {
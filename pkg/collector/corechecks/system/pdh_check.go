@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package system
+
+import (
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+)
+
+const pdhCheckName = "windows_perf_counters"
+
+const defaultPdhTagBy = "instance"
+
+// pdhMetric maps a counter of the counter set to a metric
+type pdhMetric struct {
+	counter    string
+	name       string
+	metricType string
+}
+
+// pdhInstanceConfig uses the format of the pdh_check integration, the
+// metrics are lists of [counter, metric name, metric type]
+type pdhInstanceConfig struct {
+	CounterSetName string     `yaml:"countersetname"`
+	InstanceName   string     `yaml:"instance_name"`
+	Metrics        [][]string `yaml:"metrics"`
+	TagBy          string     `yaml:"tag_by"`
+	Tags           []string   `yaml:"tags"`
+}
+
+type pdhConfig struct {
+	counterSetName string
+	instanceName   string
+	metrics        []pdhMetric
+	tagBy          string
+	tags           []string
+}
+
+func (c *pdhConfig) parse(data []byte) error {
+	var instance pdhInstanceConfig
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return err
+	}
+
+	if instance.CounterSetName == "" {
+		return fmt.Errorf("countersetname is required")
+	}
+	if len(instance.Metrics) == 0 {
+		return fmt.Errorf("no metrics are defined for the counter set %s", instance.CounterSetName)
+	}
+
+	c.metrics = make([]pdhMetric, 0, len(instance.Metrics))
+	for _, m := range instance.Metrics {
+		if len(m) != 3 {
+			return fmt.Errorf("invalid metric %v: expected [counter, metric name, metric type]", m)
+		}
+		switch m[2] {
+		case "gauge", "rate", "count", "monotonic_count":
+		default:
+			return fmt.Errorf("invalid type %s for the metric %s", m[2], m[1])
+		}
+		c.metrics = append(c.metrics, pdhMetric{counter: m[0], name: m[1], metricType: m[2]})
+	}
+
+	c.counterSetName = instance.CounterSetName
+	c.instanceName = instance.InstanceName
+	c.tagBy = instance.TagBy
+	if c.tagBy == "" {
+		c.tagBy = defaultPdhTagBy
+	}
+	c.tags = instance.Tags
+
+	return nil
+}
+
+// submitPdhValue sends the value of a counter with the sender method matching
+// the type of the metric, the type is checked when parsing the configuration
+func submitPdhValue(sender aggregator.Sender, m pdhMetric, value float64, tags []string) {
+	switch m.metricType {
+	case "gauge":
+		sender.Gauge(m.name, value, "", tags)
+	case "rate":
+		sender.Rate(m.name, value, "", tags)
+	case "count":
+		sender.Count(m.name, value, "", tags)
+	case "monotonic_count":
+		sender.MonotonicCount(m.name, value, "", tags)
+	}
+}
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package system
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+func TestPdhConfigParse(t *testing.T) {
+	cfg := new(pdhConfig)
+	err := cfg.parse([]byte(`
+countersetname: Processor
+metrics:
+  - ["% Processor Time", system.cpu.pdh.pct_processor_time, gauge]
+  - ["Interrupts/sec", system.cpu.pdh.interrupts, rate]
+tags:
+  - role:db
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Processor", cfg.counterSetName)
+	assert.Equal(t, "", cfg.instanceName)
+	assert.Equal(t, defaultPdhTagBy, cfg.tagBy)
+	assert.Equal(t, []string{"role:db"}, cfg.tags)
+	assert.Equal(t, []pdhMetric{
+		{counter: "% Processor Time", name: "system.cpu.pdh.pct_processor_time", metricType: "gauge"},
+		{counter: "Interrupts/sec", name: "system.cpu.pdh.interrupts", metricType: "rate"},
+	}, cfg.metrics)
+}
+
+func TestPdhConfigParseErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"no counter set":  `metrics: [["Processes", system.proc.count, gauge]]`,
+		"no metrics":      `countersetname: System`,
+		"short metric":    `{countersetname: System, metrics: [["Processes", system.proc.count]]}`,
+		"unknown type":    `{countersetname: System, metrics: [["Processes", system.proc.count, histogram]]}`,
+		"invalid content": `countersetname: [System`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, new(pdhConfig).parse([]byte(data)))
+		})
+	}
+}
+
+func TestSubmitPdhValue(t *testing.T) {
+	mock := mocksender.NewMockSender("pdh-test")
+	tags := []string{"instance:_Total"}
+
+	mock.On("Gauge", "pdh.gauge", 1.0, "", tags).Return().Times(1)
+	mock.On("Rate", "pdh.rate", 2.0, "", tags).Return().Times(1)
+	mock.On("Count", "pdh.count", 3.0, "", tags).Return().Times(1)
+	mock.On("MonotonicCount", "pdh.monotonic_count", 4.0, "", tags).Return().Times(1)
+
+	submitPdhValue(mock, pdhMetric{name: "pdh.gauge", metricType: "gauge"}, 1, tags)
+	submitPdhValue(mock, pdhMetric{name: "pdh.rate", metricType: "rate"}, 2, tags)
+	submitPdhValue(mock, pdhMetric{name: "pdh.count", metricType: "count"}, 3, tags)
+	submitPdhValue(mock, pdhMetric{name: "pdh.monotonic_count", metricType: "monotonic_count"}, 4, tags)
+
+	mock.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+// +build windows
+
+package system
+
+import (
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/util/winutil/pdhutil"
+)
+
+// PdhCheck collects the counters of a PDH counter set configured by the
+// instance, it replaces the WMI based python checks for the system metrics
+type PdhCheck struct {
+	core.CheckBase
+	cfg      *pdhConfig
+	counters map[string]*pdhutil.PdhCounterSet // counter name to counter set
+}
+
+// Configure parses the instance and opens a query for every counter
+func (c *PdhCheck) Configure(data integration.Data, initConfig integration.Data) error {
+	cfg := new(pdhConfig)
+	if err := cfg.parse(data); err != nil {
+		log.Errorf("system.PdhCheck: could not parse the configuration: %s", err)
+		return err
+	}
+
+	counters := make(map[string]*pdhutil.PdhCounterSet, len(cfg.metrics))
+	for _, m := range cfg.metrics {
+		if _, ok := counters[m.counter]; ok {
+			continue
+		}
+		set, err := pdhutil.GetCounterSet(cfg.counterSetName, m.counter, cfg.instanceName, nil)
+		if err != nil {
+			for _, s := range counters {
+				s.Close()
+			}
+			return err
+		}
+		counters[m.counter] = set
+	}
+
+	c.BuildID(data, initConfig)
+	c.cfg = cfg
+	c.counters = counters
+	return nil
+}
+
+// Run executes the check
+func (c *PdhCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	for _, m := range c.cfg.metrics {
+		set := c.counters[m.counter]
+		vals, err := set.GetAllValues()
+		if err != nil {
+			log.Warnf("system.PdhCheck: could not get the values of %s: %s", m.counter, err)
+			continue
+		}
+		for inst, val := range vals {
+			tags := c.cfg.tags
+			if !set.IsSingleInstance() {
+				tags = append(append([]string{}, c.cfg.tags...), c.cfg.tagBy+":"+inst)
+			}
+			submitPdhValue(sender, m, val, tags)
+		}
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// Stop closes the queries of the counters
+func (c *PdhCheck) Stop() {
+	for _, set := range c.counters {
+		set.Close()
+	}
+}
+
+func pdhCheckFactory() check.Check {
+	return &PdhCheck{
+		CheckBase: core.NewCheckBase(pdhCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(pdhCheckName, pdhCheckFactory)
+}
@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/metadata/inventories"
+	md "github.com/DataDog/datadog-agent/pkg/metadata"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+// InventoriesCollector fills and sends the inventory of the integrations
+type InventoriesCollector struct {
+	ac inventories.AutoConfig
+}
+
+// Send collects the data needed and submits the payload
+func (c *InventoriesCollector) Send(s *serializer.Serializer) error {
+	hostname, _ := util.GetHostname()
+	payload := inventories.GetPayload(hostname, c.ac)
+	if err := s.SendMetadata(payload); err != nil {
+		return fmt.Errorf("unable to submit inventories metadata payload, %s", err)
+	}
+	return nil
+}
+
+// RegisterInventoriesCollector adds the collector of the inventory of the
+// integrations to the catalog. Unlike the other collectors, it needs the
+// autodiscovery to be set up, so it is not registered on init.
+func RegisterInventoriesCollector(ac inventories.AutoConfig) {
+	md.RegisterCollector("inventories", &InventoriesCollector{ac: ac})
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package inventories builds the inventory of the integrations of the agent:
+// their versions, the providers of their configurations, the status of their
+// instances and their errors.
+package inventories
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner"
+	"github.com/DataDog/datadog-agent/pkg/metadata/common"
+	"github.com/DataDog/datadog-agent/pkg/util/scrubber"
+)
+
+// InstanceStatusNotRun is the status of the instances that did not run yet,
+// the other statuses are the ones of the check runs
+const InstanceStatusNotRun = "not_run"
+
+// AutoConfig is the source of the configurations of the integrations, it is
+// implemented by autodiscovery.AutoConfig
+type AutoConfig interface {
+	GetLoadedConfigs() []integration.Config
+	GetScheduledChecks() map[string][]string
+}
+
+// GetPayload builds the inventory of the integrations configured by ac
+func GetPayload(hostname string, ac AutoConfig) *Payload {
+	return &Payload{
+		CommonPayload{*common.GetPayload(hostname)},
+		InventoriesPayload{
+			Timestamp:    time.Now().UnixNano(),
+			Integrations: getIntegrations(ac),
+		},
+	}
+}
+
+func getIntegrations(ac AutoConfig) []Integration {
+	integrations := map[string]*Integration{}
+	get := func(name string) *Integration {
+		i, found := integrations[name]
+		if !found {
+			i = &Integration{Name: name, ConfigProviders: []string{}, Instances: []Instance{}}
+			integrations[name] = i
+		}
+		return i
+	}
+
+	checkStats := runner.GetCheckStats()
+	scheduled := ac.GetScheduledChecks()
+	for _, config := range ac.GetLoadedConfigs() {
+		i := get(config.Name)
+		if config.Provider != "" && !contains(i.ConfigProviders, config.Provider) {
+			i.ConfigProviders = append(i.ConfigProviders, config.Provider)
+		}
+		for _, id := range scheduled[config.Digest()] {
+			instance := Instance{ID: id, Status: InstanceStatusNotRun}
+			if stats, found := checkStats[check.ID(id)]; found {
+				if stats.CheckVersion != "" {
+					i.Version = stats.CheckVersion
+				}
+				if results := stats.RecentResults(); len(results) > 0 {
+					instance.Status = results[0].Status
+					instance.LastError = scrubber.ScrubLine(results[0].Error)
+				}
+			}
+			i.Instances = append(i.Instances, instance)
+		}
+	}
+
+	for name, errs := range autodiscovery.GetLoaderErrors() {
+		i := get(name)
+		for loader, err := range errs {
+			i.Errors = append(i.Errors, scrubber.ScrubLine(fmt.Sprintf("%s: %s", loader, err)))
+		}
+	}
+	for name, err := range autodiscovery.GetConfigErrors() {
+		i := get(name)
+		i.Errors = append(i.Errors, scrubber.ScrubLine(err))
+	}
+
+	result := make([]Integration, 0, len(integrations))
+	for _, i := range integrations {
+		sort.Strings(i.ConfigProviders)
+		sort.Strings(i.Errors)
+		sort.Slice(i.Instances, func(a, b int) bool { return i.Instances[a].ID < i.Instances[b].ID })
+		result = append(result, *i)
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Name < result[b].Name })
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package inventories
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+type fakeAutoConfig struct {
+	configs   []integration.Config
+	scheduled map[string][]string
+}
+
+func (ac *fakeAutoConfig) GetLoadedConfigs() []integration.Config {
+	return ac.configs
+}
+
+func (ac *fakeAutoConfig) GetScheduledChecks() map[string][]string {
+	return ac.scheduled
+}
+
+func TestGetPayload(t *testing.T) {
+	redisFile := integration.Config{Name: "redisdb", Instances: []integration.Data{integration.Data("host: a")}, Provider: "file"}
+	redisDocker := integration.Config{Name: "redisdb", Instances: []integration.Data{integration.Data("host: b")}, Provider: "docker"}
+	cpu := integration.Config{Name: "cpu", Instances: []integration.Data{integration.Data("{}")}, Provider: "file"}
+	ac := &fakeAutoConfig{
+		configs: []integration.Config{redisFile, redisDocker, cpu},
+		scheduled: map[string][]string{
+			redisFile.Digest():   {"redisdb:2"},
+			redisDocker.Digest(): {"redisdb:1"},
+			cpu.Digest():         {"cpu"},
+		},
+	}
+
+	payload := GetPayload("myhost", ac)
+	assert.Equal(t, "myhost", payload.InternalHostname)
+
+	integrations := payload.Integrations
+	require.Len(t, integrations, 2)
+	assert.Equal(t, "cpu", integrations[0].Name)
+	assert.Equal(t, []string{"file"}, integrations[0].ConfigProviders)
+	assert.Equal(t, []Instance{{ID: "cpu", Status: InstanceStatusNotRun}}, integrations[0].Instances)
+
+	assert.Equal(t, "redisdb", integrations[1].Name)
+	assert.Equal(t, []string{"docker", "file"}, integrations[1].ConfigProviders)
+	require.Len(t, integrations[1].Instances, 2)
+	assert.Equal(t, "redisdb:1", integrations[1].Instances[0].ID)
+	assert.Equal(t, "redisdb:2", integrations[1].Instances[1].ID)
+
+	content, err := payload.MarshalJSON()
+	require.Nil(t, err)
+	decoded := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(content, &decoded))
+	assert.Contains(t, decoded, "inventories")
+	assert.Contains(t, decoded, "internalHostname")
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package inventories
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/metadata/common"
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+)
+
+// Payload handles the JSON unmarshalling of the metadata payload
+type Payload struct {
+	CommonPayload
+	InventoriesPayload `json:"inventories"`
+}
+
+// CommonPayload wraps Payload from the common package
+type CommonPayload struct {
+	common.Payload
+}
+
+// InventoriesPayload lists the integrations of the agent
+type InventoriesPayload struct {
+	Timestamp    int64         `json:"timestamp"`
+	Integrations []Integration `json:"integrations"`
+}
+
+// Integration is an integration configured on the agent, with its scheduled
+// instances and the errors that prevented it from being scheduled
+type Integration struct {
+	Name            string     `json:"name"`
+	Version         string     `json:"version,omitempty"`
+	ConfigProviders []string   `json:"config_providers"`
+	Instances       []Instance `json:"instances"`
+	Errors          []string   `json:"errors,omitempty"`
+}
+
+// Instance is a scheduled instance of an integration, with the status of its
+// last run
+type Instance struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// MarshalJSON serialization a Payload to JSON
+func (p *Payload) MarshalJSON() ([]byte, error) {
+	// use an alias to avoid infinite recursion while serializing
+	type PayloadAlias Payload
+
+	return json.Marshal((*PayloadAlias)(p))
+}
+
+// Marshal not implemented
+func (p *Payload) Marshal() ([]byte, error) {
+	return nil, fmt.Errorf("Inventories Payload serialization is not implemented")
+}
+
+// SplitPayload breaks the payload into times number of pieces
+func (p *Payload) SplitPayload(times int) ([]marshaler.Marshaler, error) {
+	return nil, fmt.Errorf("Inventories Payload splitting is not implemented")
+}
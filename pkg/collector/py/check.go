@@ -33,6 +33,7 @@ type PythonCheck struct {
 	instance     *python.PyObject
 	class        *python.PyObject
 	ModuleName   string
+	version      string
 	config       *python.PyObject
 	interval     time.Duration
 	lastWarnings []error
@@ -128,6 +129,12 @@ func (c *PythonCheck) String() string {
 	return c.ModuleName
 }
 
+// Version returns the version of the integration of the check, it is empty
+// when its module holds none
+func (c *PythonCheck) Version() string {
+	return c.version
+}
+
 // GetWarnings grabs the last warnings from the struct
 func (c *PythonCheck) GetWarnings() []error {
 	warnings := c.lastWarnings
@@ -95,6 +95,9 @@ func (cl *PythonCheckLoader) Load(config integration.Config) ([]check.Check, err
 		return nil, err
 	}
 
+	// the wheels hold the version of their integration
+	version := getModuleVersion(checkModule)
+
 	// Try to find a class inheriting from AgentCheck within the module
 	checkClass, err := findSubclassOf(cl.agentCheckClass, checkModule, glock)
 	checkModule.DecRef()
@@ -107,6 +110,7 @@ func (cl *PythonCheckLoader) Load(config integration.Config) ([]check.Check, err
 	// Get an AgentCheck for each configuration instance and add it to the registry
 	for _, i := range config.Instances {
 		check := NewPythonCheck(moduleName, checkClass)
+		check.version = version
 		// The GIL should be unlocked at this point, `check.Configure` uses its own stickyLock and stickyLocks must not be nested
 		if err := check.Configure(i, config.InitConfig); err != nil {
 			log.Errorf("py.loader: could not configure check '%s': %s", moduleName, err)
@@ -122,6 +126,23 @@ func (cl *PythonCheckLoader) Load(config integration.Config) ([]check.Check, err
 	return checks, nil
 }
 
+// getModuleVersion returns the `__version__` of a module, or an empty string
+// when it has none. The GIL must be locked.
+func getModuleVersion(module *python.PyObject) string {
+	if module.HasAttrString("__version__") == 0 {
+		return ""
+	}
+	version := module.GetAttrString("__version__")
+	if version == nil {
+		return ""
+	}
+	defer version.DecRef()
+	if !python.PyString_Check(version) {
+		return ""
+	}
+	return python.PyString_AsString(version)
+}
+
 func (cl *PythonCheckLoader) String() string {
 	return "Python Check Loader"
 }
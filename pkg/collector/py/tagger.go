@@ -15,6 +15,7 @@ import (
 	"unsafe"
 
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 )
 
 // GetTags queries the agent6 tagger and returns a string array containing
@@ -23,12 +24,12 @@ import (
 //export GetTags
 func GetTags(id *C.char, highCard int) *C.PyObject {
 	goID := C.GoString(id)
-	var highCardBool bool
+	cardinality := tagger.ChecksCardinality
 	if highCard > 0 {
-		highCardBool = true
+		cardinality = collectors.HighCardinality
 	}
 
-	tags, _ := tagger.Tag(goID, highCardBool)
+	tags, _ := tagger.Tag(goID, cardinality)
 	output := C.PyList_New(0)
 
 	for _, t := range tags {
@@ -26,11 +26,11 @@ type DummyCollector struct {
 func (c *DummyCollector) Detect(out chan<- []*collectors.TagInfo) (collectors.CollectionMode, error) {
 	return collectors.FetchOnlyCollection, nil
 }
-func (c *DummyCollector) Fetch(entity string) ([]string, []string, error) {
+func (c *DummyCollector) Fetch(entity string) ([]string, []string, []string, error) {
 	if entity == "404" {
-		return nil, nil, errors.NewNotFound(entity)
+		return nil, nil, nil, errors.NewNotFound(entity)
 	} else {
-		return []string{entity + ":low"}, []string{entity + ":high", "other_tag:high"}, nil
+		return []string{entity + ":low"}, []string{entity + ":orchestrator"}, []string{entity + ":high", "other_tag:high"}, nil
 	}
 }
 
@@ -43,12 +43,12 @@ func TestGetTags(t *testing.T) {
 	tagger.Init()
 
 	// Make sure tagger works as expected first
-	low, err := tagger.Tag("test_entity", false)
+	low, err := tagger.Tag("test_entity", collectors.LowCardinality)
 	require.NoError(t, err)
 	require.Equal(t, low, []string{"test_entity:low"})
-	high, err := tagger.Tag("test_entity", true)
+	high, err := tagger.Tag("test_entity", collectors.HighCardinality)
 	require.NoError(t, err)
-	assert.ElementsMatch(t, high, []string{"test_entity:low", "test_entity:high", "other_tag:high"})
+	assert.ElementsMatch(t, high, []string{"test_entity:low", "test_entity:orchestrator", "test_entity:high", "other_tag:high"})
 
 	check, _ := getCheckInstance("testtagger", "TestCheck")
 	mockSender := mocksender.NewMockSender(check.ID())
@@ -58,6 +58,6 @@ func TestGetTags(t *testing.T) {
 	require.NoError(t, err)
 
 	mockSender.AssertMetricTaggedWith(t, "Gauge", "metric.low_card", []string{"test_entity:low"})
-	mockSender.AssertMetricTaggedWith(t, "Gauge", "metric.high_card", []string{"test_entity:low", "test_entity:high", "other_tag:high"})
+	mockSender.AssertMetricTaggedWith(t, "Gauge", "metric.high_card", []string{"test_entity:low", "test_entity:orchestrator", "test_entity:high", "other_tag:high"})
 	mockSender.AssertMetricTaggedWith(t, "Gauge", "metric.unknown", []string{})
 }
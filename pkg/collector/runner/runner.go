@@ -364,6 +364,19 @@ func GetCheckStats() map[check.ID]*check.Stats {
 	return checkStats.Stats
 }
 
+// GetRecentCheckResults returns the results of the last runs of every check
+// instance, the most recent first
+func GetRecentCheckResults() map[check.ID][]check.RunResult {
+	checkStats.M.RLock()
+	defer checkStats.M.RUnlock()
+
+	results := make(map[check.ID][]check.RunResult, len(checkStats.Stats))
+	for id, s := range checkStats.Stats {
+		results[id] = s.RecentResults()
+	}
+	return results
+}
+
 // RemoveCheckStats removes a check from the check stats map
 func RemoveCheckStats(checkID check.ID) {
 	checkStats.M.RLock()
@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package collector
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/status/sections"
+)
+
+// collectorStatus is the collector section of the status: the stats of the
+// checks and of the workers, and the errors of the check configurations.
+type collectorStatus struct {
+	Runner     interface{} `json:"runner"`
+	AutoConfig interface{} `json:"autoConfig,omitempty"`
+}
+
+type statusProvider struct{}
+
+func (statusProvider) Name() string { return "collector" }
+
+func (statusProvider) Status() (interface{}, error) {
+	runner, err := sections.ExpvarStatus("runner")
+	if err != nil {
+		return nil, err
+	}
+	// the autoconfig is not part of every binary
+	autoConfig, _ := sections.ExpvarStatus("autoconfig")
+	return collectorStatus{Runner: runner, AutoConfig: autoConfig}, nil
+}
+
+func init() {
+	sections.Register(statusProvider{})
+}
@@ -52,6 +52,14 @@ type Listeners struct {
 	Name string `mapstructure:"name"`
 }
 
+// KubernetesRemoteCluster helps unmarshalling `kubernetes_remote_clusters` config param
+type KubernetesRemoteCluster struct {
+	Name           string   `mapstructure:"name"`
+	KubeconfigPath string   `mapstructure:"kubeconfig_path"`
+	Context        string   `mapstructure:"context"`
+	Namespaces     []string `mapstructure:"namespaces"`
+}
+
 // Proxy represents the configuration for proxies in the agent
 type Proxy struct {
 	HTTP    string   `mapstructure:"http"`
@@ -75,9 +83,11 @@ func init() {
 	Datadog.SetDefault("dd_url", "https://app.datadoghq.com")
 	Datadog.SetDefault("app_key", "")
 	Datadog.SetDefault("proxy", nil)
+	BindEnvAndSetDefault("additional_endpoints", map[string][]string{})
 	Datadog.SetDefault("skip_ssl_validation", false)
 	Datadog.SetDefault("hostname", "")
 	Datadog.SetDefault("tags", []string{})
+	BindEnvAndSetDefault("profile", "") // see profiles.go
 	Datadog.SetDefault("conf_path", ".")
 	Datadog.SetDefault("confd_path", defaultConfdPath)
 	Datadog.SetDefault("confd_dca_path", defaultDCAConfdPath)
@@ -85,7 +95,9 @@ func init() {
 	Datadog.SetDefault("log_payloads", false)
 	Datadog.SetDefault("log_level", "info")
 	Datadog.SetDefault("log_to_syslog", false)
+	Datadog.SetDefault("log_to_event_viewer", false)
 	Datadog.SetDefault("log_to_console", true)
+	Datadog.SetDefault("log_buffer_size", 0)
 	Datadog.SetDefault("logging_frequency", int64(20))
 	Datadog.SetDefault("disable_file_logging", false)
 	Datadog.SetDefault("syslog_uri", "")
@@ -95,12 +107,19 @@ func init() {
 	Datadog.SetDefault("cmd_host", "localhost")
 	Datadog.SetDefault("cmd_port", 5001)
 	Datadog.SetDefault("cluster_agent_cmd_port", 5005)
+	Datadog.SetDefault("health_port", 0)
 	Datadog.SetDefault("default_integration_http_timeout", 9)
 	Datadog.SetDefault("enable_metadata_collection", true)
 	Datadog.SetDefault("enable_gohai", true)
+	BindEnvAndSetDefault("metadata_providers", []MetadataProviders{})
+	Datadog.SetDefault("gohai_exclude", []string{})
+	Datadog.SetDefault("processes_metadata.top_n", 10)
+	Datadog.SetDefault("processes_metadata.scrub_args", true)
+	Datadog.SetDefault("processes_metadata.sensitive_words", []string{})
 	Datadog.SetDefault("check_runners", int64(1))
 	Datadog.SetDefault("expvar_port", "5000")
 	Datadog.SetDefault("auth_token_file_path", "")
+	Datadog.SetDefault("auth_token_rotation_grace_period", 60) // value in seconds
 	Datadog.SetDefault("bind_host", "localhost")
 
 	// Retry settings
@@ -119,6 +138,27 @@ func init() {
 	// Use to force client side TLS version to 1.2
 	BindEnvAndSetDefault("force_tls_12", false)
 
+	// Secrets backend, the executable resolving the ENC[] handles of the configurations
+	BindEnvAndSetDefault("secret_backend_type", "executable")
+	BindEnvAndSetDefault("secret_backend_command", "")
+	BindEnvAndSetDefault("secret_backend_arguments", []string{})
+	BindEnvAndSetDefault("secret_backend_timeout", 5)                 // value in seconds
+	BindEnvAndSetDefault("secret_backend_output_max_size", 1024*1024) // value in bytes
+	BindEnvAndSetDefault("secret_backend_aws_region", "")
+	BindEnvAndSetDefault("secret_backend_gcp_kms_key", "")
+	BindEnvAndSetDefault("secret_backend_azure_vault_url", "")
+
+	// Rules scrubbing the flares and the logs of the agent, see scrubber.go
+	BindEnvAndSetDefault("scrubber.custom_rules", []scrubberRule{})
+
+	// Remote configuration
+	BindEnvAndSetDefault("remote_configuration.enabled", false)
+	BindEnvAndSetDefault("remote_configuration.refresh_interval", 60) // value in seconds
+	BindEnvAndSetDefault("remote_configuration.public_key", "")
+	BindEnvAndSetDefault("remote_configuration.flare_consent", "never")
+	BindEnvAndSetDefault("remote_configuration.flare_min_interval", 3600) // value in seconds
+	BindEnvAndSetDefault("remote_configuration.run_path", defaultRunPath)
+
 	// Agent GUI access port
 	Datadog.SetDefault("GUI_port", defaultGuiPort)
 	if IsContainerized() {
@@ -147,10 +187,28 @@ func init() {
 	Datadog.SetDefault("forwarder_timeout", 20)
 	Datadog.SetDefault("forwarder_retry_queue_max_size", 30)
 	BindEnvAndSetDefault("forwarder_num_workers", 1)
+	// Aggregator
+	BindEnvAndSetDefault("aggregator_buffer_size", 100)
+	// Interner of the strings shared by dogstatsd, the tagger and the aggregator, 0 disables it
+	BindEnvAndSetDefault("string_interner_size", 4096)
+	// Memory ceiling in MB the load is shed under, 0 disables it
+	BindEnvAndSetDefault("memory_ceiling_mb", 0)
+	BindEnvAndSetDefault("memory_ceiling_check_interval", 10)
+	// Profiles captured when the usage of the agent stays above the thresholds
+	BindEnvAndSetDefault("auto_profiling.enabled", false)
+	BindEnvAndSetDefault("auto_profiling.dir", defaultAutoProfilingPath)
+	BindEnvAndSetDefault("auto_profiling.cpu_threshold", 80)        // percent of one core, 0 disables it
+	BindEnvAndSetDefault("auto_profiling.memory_threshold_mb", 0)   // 0 disables it
+	BindEnvAndSetDefault("auto_profiling.sustained_duration", 120)  // value in seconds
+	BindEnvAndSetDefault("auto_profiling.check_interval", 10)       // value in seconds
+	BindEnvAndSetDefault("auto_profiling.cpu_profile_duration", 30) // value in seconds
+	BindEnvAndSetDefault("auto_profiling.cooldown", 3600)           // value in seconds
+	BindEnvAndSetDefault("auto_profiling.max_captures", 5)
 	// Dogstatsd
 	Datadog.SetDefault("use_dogstatsd", true)
 	Datadog.SetDefault("dogstatsd_port", 8125)          // Notice: 0 means UDP port closed
 	Datadog.SetDefault("dogstatsd_buffer_size", 1024*8) // 8KB buffer
+	BindEnvAndSetDefault("dogstatsd_queue_size", 100)
 	Datadog.SetDefault("dogstatsd_non_local_traffic", false)
 	Datadog.SetDefault("dogstatsd_socket", "") // Notice: empty means feature disabled
 	Datadog.SetDefault("dogstatsd_stats_port", 5000)
@@ -161,11 +219,30 @@ func init() {
 	Datadog.SetDefault("statsd_forward_host", "")
 	Datadog.SetDefault("statsd_forward_port", 0)
 	BindEnvAndSetDefault("statsd_metric_namespace", "")
+	BindEnvAndSetDefault("dogstatsd_metric_blocklist", []string{})
+	// OTLP receiver
+	BindEnvAndSetDefault("otlp.enabled", false)
+	BindEnvAndSetDefault("otlp.bind_host", "localhost")
+	BindEnvAndSetDefault("otlp.http_port", 4318)
+	BindEnvAndSetDefault("otlp.resource_attributes_as_tags", false)
+	// SNMP trap listener
+	BindEnvAndSetDefault("snmp_traps.enabled", false)
+	BindEnvAndSetDefault("snmp_traps.bind_host", "0.0.0.0")
+	BindEnvAndSetDefault("snmp_traps.port", 9162)
+	BindEnvAndSetDefault("snmp_traps.community_string", "")
+	BindEnvAndSetDefault("snmp_traps.mibs_folder", "")
+	BindEnvAndSetDefault("snmp_traps.tags", []string{})
+	BindEnvAndSetDefault("snmp_traps.users", []map[string]interface{}{})
+	BindEnvAndSetDefault("snmp_traps.translations", map[string]string{})
+	BindEnvAndSetDefault("snmp_traps.device_tags", map[string][]string{})
+	BindEnvAndSetDefault("snmp_traps.service_checks", []map[string]interface{}{})
 	// Autoconfig
 	Datadog.SetDefault("autoconf_template_dir", "/datadog/check_configs")
 	Datadog.SetDefault("exclude_pause_container", true)
 	Datadog.SetDefault("ac_include", []string{})
 	Datadog.SetDefault("ac_exclude", []string{})
+	BindEnvAndSetDefault("config_providers", []ConfigurationProviders{})
+	BindEnvAndSetDefault("listeners", []Listeners{})
 
 	// Docker
 	BindEnvAndSetDefault("docker_query_timeout", int64(5))
@@ -174,10 +251,17 @@ func init() {
 	Datadog.SetDefault("kubernetes_pod_labels_as_tags", map[string]string{})
 	Datadog.SetDefault("kubernetes_pod_annotations_as_tags", map[string]string{})
 	Datadog.SetDefault("kubernetes_node_labels_as_tags", map[string]string{})
+	Datadog.SetDefault("kubernetes_metadata_node_labels", []string{
+		"beta.kubernetes.io/instance-type",
+		"failure-domain.beta.kubernetes.io/region",
+		"failure-domain.beta.kubernetes.io/zone",
+		"kubernetes.io/role",
+	})
 
 	// Kubernetes
 	Datadog.SetDefault("kubernetes_http_kubelet_port", 10255)
 	Datadog.SetDefault("kubernetes_https_kubelet_port", 10250)
+	Datadog.SetDefault("cluster_name", "")
 
 	Datadog.SetDefault("kubelet_tls_verify", true)
 	Datadog.SetDefault("kubelet_client_ca", "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt")
@@ -194,16 +278,56 @@ func init() {
 	Datadog.SetDefault("leader_lease_duration", "60")
 	Datadog.SetDefault("leader_election", false)
 	Datadog.SetDefault("kube_resources_namespace", "")
+	Datadog.SetDefault("kubernetes_informers_resync_period", 60*5) // 5 min
+	Datadog.SetDefault("kubernetes_namespaces", []string{})        // all namespaces
+	Datadog.SetDefault("kubernetes_use_endpoint_slices", true)
+	Datadog.SetDefault("kubernetes_apiserver_client_qps", 5)
+	Datadog.SetDefault("kubernetes_apiserver_client_burst", 10)
+	Datadog.SetDefault("kubernetes_apiserver_client_timeout", 2) // value in seconds
+	Datadog.SetDefault("kubernetes_apiserver_list_timeout", 5)   // value in seconds
+	BindEnvAndSetDefault("kubernetes_remote_clusters", []KubernetesRemoteCluster{})
 
 	// Datadog cluster agent
 	Datadog.SetDefault("cluster_agent", false)
 	Datadog.SetDefault("cluster_agent.auth_token", "")
+	Datadog.SetDefault("cluster_agent.previous_auth_tokens", []string{})
 	Datadog.SetDefault("cluster_agent.url", "")
 	Datadog.SetDefault("cluster_agent.kubernetes_service_name", "dca")
+	Datadog.SetDefault("cluster_agent.tag_stream", true)
+	Datadog.SetDefault("cluster_agent.tag_stream_refresh_period", 30) // value in seconds
+	Datadog.SetDefault("cluster_agent.forward_to_leader", true)
+	Datadog.SetDefault("cluster_agent.reconcile_interval", 300) // value in seconds, 0 disables the reconciliation
+
+	// External Metrics Provider for the Horizontal Pod Autoscalers, served by the cluster agent
+	Datadog.SetDefault("external_metrics_provider.enabled", false)
+	Datadog.SetDefault("external_metrics_provider.port", 443)
+	Datadog.SetDefault("external_metrics_provider.refresh_period", 30) // value in seconds
+	Datadog.SetDefault("external_metrics_provider.bucket_size", 60*5)  // value in seconds
+	Datadog.SetDefault("external_metrics_provider.max_age", 120)       // value in seconds
+	Datadog.SetDefault("external_metrics_provider.batch_size", 20)
+	Datadog.SetDefault("external_metrics_provider.max_metrics", 1000)
+	Datadog.SetDefault("external_metrics_provider.use_datadogmetric_crd", false)
+
+	// Admission webhook injecting the agent configuration in the pods, served by the cluster agent
+	Datadog.SetDefault("admission_controller.enabled", false)
+	Datadog.SetDefault("admission_controller.port", 8000)
+	Datadog.SetDefault("admission_controller.tls_cert_file", "")
+	Datadog.SetDefault("admission_controller.tls_key_file", "")
+	Datadog.SetDefault("admission_controller.pod_selector", "admission.datadoghq.com/enabled=true")
+	Datadog.SetDefault("admission_controller.inject_dogstatsd_socket", false)
+	Datadog.SetDefault("admission_controller.dogstatsd_socket", "/var/run/datadog/dsd.socket")
+
+	// Collection of the cluster resources by the cluster agent
+	Datadog.SetDefault("orchestrator_collection.enabled", false)
+	Datadog.SetDefault("orchestrator_collection.interval", 30) // value in seconds
+	Datadog.SetDefault("orchestrator_collection.max_per_message", 100)
 
 	// ECS
 	Datadog.SetDefault("ecs_agent_url", "") // Will be autodetected
 	Datadog.SetDefault("collect_ec2_tags", false)
+	Datadog.SetDefault("collect_azure_tags", false)
+	Datadog.SetDefault("collect_oracle_tags", false)
+	Datadog.SetDefault("cloud_host_tags_include", []string{})
 
 	// Cloud Foundry
 	Datadog.SetDefault("cloud_foundry", false)
@@ -221,7 +345,7 @@ func init() {
 
 	// Logs Agent
 	BindEnvAndSetDefault("logs_enabled", false)
-	BindEnvAndSetDefault("log_enabled", false) // deprecated, use logs_enabled instead
+	RenameKey("log_enabled", "logs_enabled")
 	BindEnvAndSetDefault("logset", "")
 
 	BindEnvAndSetDefault("logs_config.dd_url", "agent-intake.logs.datadoghq.com")
@@ -230,10 +354,32 @@ func init() {
 	BindEnvAndSetDefault("logs_config.run_path", defaultRunPath)
 	BindEnvAndSetDefault("logs_config.open_files_limit", 100)
 	BindEnvAndSetDefault("logs_config.container_collect_all", false)
+	BindEnvAndSetDefault("logs_config.k8s_collect_all", false)
+	BindEnvAndSetDefault("logs_config.k8s_pod_logs_path", "/var/log/pods")
+	// HTTPS transport, for the hosts only allowed to reach the port 443
+	BindEnvAndSetDefault("logs_config.use_http", false)
+	BindEnvAndSetDefault("logs_config.http_dd_url", "agent-http-intake.logs.datadoghq.com")
+	BindEnvAndSetDefault("logs_config.http_dd_port", 443)
+	BindEnvAndSetDefault("logs_config.batch_wait", 5)
+	BindEnvAndSetDefault("logs_config.batch_max_size", 200)
+	BindEnvAndSetDefault("logs_config.use_compression", true)
+	BindEnvAndSetDefault("logs_config.compression_level", 6)
+	// Buffer the logs on the disk while the intake is unreachable
+	BindEnvAndSetDefault("logs_config.use_disk_buffer", false)
+	BindEnvAndSetDefault("logs_config.disk_buffer_max_size", 100*1024*1024)
+	BindEnvAndSetDefault("logs_config.disk_buffer_max_age", 24*60*60)
+	BindEnvAndSetDefault("logs_config.processing_rules", []map[string]interface{}{})
 
 	// Tagger full cardinality mode
-	// Undocumented opt-in feature for now
+	// Undocumented opt-in feature, deprecated by the tag cardinality options below
 	BindEnvAndSetDefault("full_cardinality_tagging", false)
+	DeprecateKey("full_cardinality_tagging", "set checks_tag_cardinality and dogstatsd_tag_cardinality to high instead")
+	// Cardinality of the tags added by the tagger: low, orchestrator or high
+	BindEnvAndSetDefault("checks_tag_cardinality", "low")
+	BindEnvAndSetDefault("dogstatsd_tag_cardinality", "low")
+	// TTL in seconds of the tags fetched on cache misses from the collectors not notifying the deletions
+	BindEnvAndSetDefault("tagger_fetched_tags_ttl", 600)
+	BindEnvAndSetDefault("tagger_static_tags", []map[string]interface{}{})
 
 	// ENV vars bindings
 	Datadog.BindEnv("api_key")
@@ -242,9 +388,11 @@ func init() {
 	Datadog.BindEnv("hostname")
 	Datadog.BindEnv("tags")
 	Datadog.BindEnv("cmd_port")
+	Datadog.BindEnv("health_port")
 	Datadog.BindEnv("conf_path")
 	Datadog.BindEnv("enable_metadata_collection")
 	Datadog.BindEnv("enable_gohai")
+	Datadog.BindEnv("gohai_exclude")
 	Datadog.BindEnv("dogstatsd_port")
 	Datadog.BindEnv("bind_host")
 	Datadog.BindEnv("proc_root")
@@ -263,6 +411,7 @@ func init() {
 	Datadog.BindEnv("kubernetes_kubelet_host")
 	Datadog.BindEnv("kubernetes_http_kubelet_port")
 	Datadog.BindEnv("kubernetes_https_kubelet_port")
+	Datadog.BindEnv("cluster_name")
 	Datadog.BindEnv("kubelet_client_crt")
 	Datadog.BindEnv("kubelet_client_key")
 	Datadog.BindEnv("kubelet_tls_verify")
@@ -274,12 +423,18 @@ func init() {
 	Datadog.BindEnv("kubernetes_pod_labels_as_tags")
 	Datadog.BindEnv("kubernetes_pod_annotations_as_tags")
 	Datadog.BindEnv("kubernetes_node_labels_as_tags")
+	Datadog.BindEnv("kubernetes_metadata_node_labels")
 	Datadog.BindEnv("ac_include")
 	Datadog.BindEnv("ac_exclude")
 
 	Datadog.BindEnv("cluster_agent")
 	Datadog.BindEnv("cluster_agent.url")
 	Datadog.BindEnv("cluster_agent.auth_token")
+	Datadog.BindEnv("cluster_agent.previous_auth_tokens")
+	Datadog.BindEnv("cluster_agent.tag_stream")
+	Datadog.BindEnv("cluster_agent.tag_stream_refresh_period")
+	Datadog.BindEnv("cluster_agent.forward_to_leader")
+	Datadog.BindEnv("cluster_agent.reconcile_interval")
 	Datadog.BindEnv("cluster_agent_cmd_port")
 
 	Datadog.BindEnv("forwarder_timeout")
@@ -292,11 +447,44 @@ func init() {
 	Datadog.BindEnv("leader_election")
 	Datadog.BindEnv("leader_lease_duration")
 	Datadog.BindEnv("kube_resources_namespace")
+	Datadog.BindEnv("kubernetes_informers_resync_period")
+	Datadog.BindEnv("kubernetes_namespaces")
+	Datadog.BindEnv("kubernetes_use_endpoint_slices")
+	Datadog.BindEnv("kubernetes_apiserver_client_qps")
+	Datadog.BindEnv("kubernetes_apiserver_client_burst")
+	Datadog.BindEnv("kubernetes_apiserver_client_timeout")
+	Datadog.BindEnv("kubernetes_apiserver_list_timeout")
+	Datadog.BindEnv("external_metrics_provider.enabled")
+	Datadog.BindEnv("external_metrics_provider.port")
+	Datadog.BindEnv("external_metrics_provider.refresh_period")
+	Datadog.BindEnv("external_metrics_provider.bucket_size")
+	Datadog.BindEnv("external_metrics_provider.max_age")
+	Datadog.BindEnv("external_metrics_provider.batch_size")
+	Datadog.BindEnv("external_metrics_provider.max_metrics")
+	Datadog.BindEnv("external_metrics_provider.use_datadogmetric_crd")
+	Datadog.BindEnv("admission_controller.enabled")
+	Datadog.BindEnv("admission_controller.port")
+	Datadog.BindEnv("admission_controller.tls_cert_file")
+	Datadog.BindEnv("admission_controller.tls_key_file")
+	Datadog.BindEnv("admission_controller.pod_selector")
+	Datadog.BindEnv("admission_controller.inject_dogstatsd_socket")
+	Datadog.BindEnv("admission_controller.dogstatsd_socket")
+	Datadog.BindEnv("orchestrator_collection.enabled")
+	Datadog.BindEnv("orchestrator_collection.interval")
+	Datadog.BindEnv("orchestrator_collection.max_per_message")
 
 	Datadog.BindEnv("collect_ec2_tags")
+	Datadog.BindEnv("collect_azure_tags")
+	Datadog.BindEnv("collect_oracle_tags")
+	Datadog.BindEnv("cloud_host_tags_include")
+
+	// every other key, nested or not, is bound to its DD_ env var
+	bindEnvVars(Datadog)
 }
 
-// BindEnvAndSetDefault sets the default value for a config parameter, and adds an env binding
+// BindEnvAndSetDefault sets the default value for a config parameter, and adds an env binding.
+// The lists and the maps must be declared with it, their env var is only parsed as
+// JSON for the known keys, see bindEnvVars.
 func BindEnvAndSetDefault(key string, val interface{}) {
 	Datadog.SetDefault(key, val)
 	Datadog.BindEnv(key)
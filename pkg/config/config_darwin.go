@@ -12,4 +12,5 @@ const (
 	defaultRunPath              = "/opt/datadog-agent/run"
 	defaultSyslogURI            = "unixgram:///var/run/syslog"
 	defaultGuiPort              = "5002"
+	defaultAutoProfilingPath    = "/opt/datadog-agent/run/profiles"
 )
@@ -14,4 +14,5 @@ const (
 	defaultRunPath              = "/opt/datadog-agent/run"
 	defaultSyslogURI            = "unixgram:///dev/log"
 	defaultGuiPort              = "-1"
+	defaultAutoProfilingPath    = "/opt/datadog-agent/run/profiles"
 )
@@ -7,7 +7,11 @@ package config
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -165,3 +169,96 @@ func TestEnvNestedConfig(t *testing.T) {
 	assert.Equal(t, "baz", Datadog.GetString("foo.bar.nested"))
 	os.Unsetenv("DD_FOO_BAR_NESTED")
 }
+
+func TestEnvOverridesAllNestedKeys(t *testing.T) {
+	conf := setupViperConf(`
+logs_config:
+  use_http: false
+  batch_max_size: 200
+`)
+	conf.SetEnvPrefix("DD")
+	conf.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	os.Setenv("DD_LOGS_CONFIG_USE_HTTP", "true")
+	os.Setenv("DD_LOGS_CONFIG_BATCH_MAX_SIZE", "50")
+	defer os.Unsetenv("DD_LOGS_CONFIG_USE_HTTP")
+	defer os.Unsetenv("DD_LOGS_CONFIG_BATCH_MAX_SIZE")
+	bindEnvVars(conf)
+
+	assert.True(t, conf.GetBool("logs_config.use_http"))
+	assert.Equal(t, 50, conf.GetInt("logs_config.batch_max_size"))
+}
+
+func TestEnvOverridesParseJSON(t *testing.T) {
+	conf := viper.New()
+	conf.SetDefault("ac_include", []string{})
+	conf.SetDefault("docker_labels_as_tags", map[string]string{})
+	conf.SetDefault("tags", []string{})
+	os.Setenv("DD_AC_INCLUDE", `["image:foo", "image:bar baz"]`)
+	os.Setenv("DD_DOCKER_LABELS_AS_TAGS", `{"app": "application"}`)
+	os.Setenv("DD_TAGS", "env:prod role:db")
+	defer os.Unsetenv("DD_AC_INCLUDE")
+	defer os.Unsetenv("DD_DOCKER_LABELS_AS_TAGS")
+	defer os.Unsetenv("DD_TAGS")
+	conf.SetEnvPrefix("DD")
+	bindEnvVars(conf)
+
+	assert.Equal(t, []string{"image:foo", "image:bar baz"}, conf.GetStringSlice("ac_include"))
+	assert.Equal(t, map[string]string{"app": "application"}, conf.GetStringMapString("docker_labels_as_tags"))
+	// the values that are not JSON keep their usual parsing
+	assert.Equal(t, []string{"env:prod", "role:db"}, conf.GetStringSlice("tags"))
+}
+
+func TestEnvOverridesParseJSONStructured(t *testing.T) {
+	conf := viper.New()
+	conf.SetDefault("listeners", []Listeners{})
+	conf.SetDefault("snmp_traps.device_tags", map[string][]string{})
+	os.Setenv("DD_LISTENERS", `[{"name": "docker"}]`)
+	os.Setenv("DD_SNMP_TRAPS_DEVICE_TAGS", `{"10.0.0.1": ["role:router"]}`)
+	defer os.Unsetenv("DD_LISTENERS")
+	defer os.Unsetenv("DD_SNMP_TRAPS_DEVICE_TAGS")
+	conf.SetEnvPrefix("DD")
+	conf.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	bindEnvVars(conf)
+
+	var listeners []Listeners
+	require.NoError(t, conf.UnmarshalKey("listeners", &listeners))
+	assert.Equal(t, []Listeners{{Name: "docker"}}, listeners)
+	assert.Equal(t, map[string][]string{"10.0.0.1": {"role:router"}}, conf.GetStringMapStringSlice("snmp_traps.device_tags"))
+}
+
+// listOrMapRead matches the reads of the lists and the maps of the configuration
+var listOrMapRead = regexp.MustCompile(`(?:config\.Datadog|LogsAgent|\bconfig)\.(?:UnmarshalKey|GetStringSlice|GetStringMap\w*)\("([a-z0-9_.]+)"`)
+
+// TestListsAndMapsAreDeclared checks the lists and the maps read by the agent are
+// declared with BindEnvAndSetDefault, their env var is not parsed as JSON otherwise.
+func TestListsAndMapsAreDeclared(t *testing.T) {
+	known := map[string]bool{}
+	for _, key := range Datadog.AllKeys() {
+		known[key] = true
+	}
+
+	for _, dir := range []string{"../../cmd", "../../pkg"} {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			source, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for _, match := range listOrMapRead.FindAllSubmatch(source, -1) {
+				assert.True(t, known[string(match[1])], "%s reads %s, it must be declared with BindEnvAndSetDefault", path, match[1])
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	assert.Equal(t, "DD_LOGS_CONFIG_USE_HTTP", envVarName("logs_config.use_http"))
+	assert.Equal(t, "DD_API_KEY", envVarName("api_key"))
+}
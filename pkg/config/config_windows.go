@@ -12,4 +12,5 @@ const (
 	defaultRunPath              = ""
 	defaultSyslogURI            = ""
 	defaultGuiPort              = "5002"
+	defaultAutoProfilingPath    = "c:\\programdata\\datadog\\run\\profiles"
 )
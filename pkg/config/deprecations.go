@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/cihub/seelog"
+	"github.com/spf13/viper"
+)
+
+var (
+	// renamedKeys are the new names of the options renamed, by old name
+	renamedKeys = map[string]string{}
+	// deprecatedKeys are the options that will be removed, with how to replace them
+	deprecatedKeys = map[string]string{}
+
+	// deprecationWarnings are the deprecated options set in the configuration loaded
+	deprecationWarnings []string
+	deprecationsMutex   sync.Mutex
+)
+
+// RenameKey declares that oldKey is renamed to newKey: when only oldKey is set,
+// its value is used for newKey. The options are only read by their new name.
+func RenameKey(oldKey string, newKey string) {
+	renamedKeys[strings.ToLower(oldKey)] = strings.ToLower(newKey)
+}
+
+// DeprecateKey declares that key will be removed, message tells how to replace it.
+func DeprecateKey(key string, message string) {
+	deprecatedKeys[strings.ToLower(key)] = message
+}
+
+// DeprecatedKeys returns how to replace the deprecated and renamed options, by name.
+func DeprecatedKeys() map[string]string {
+	keys := make(map[string]string, len(renamedKeys)+len(deprecatedKeys))
+	for oldKey, newKey := range renamedKeys {
+		keys[oldKey] = fmt.Sprintf("use %s instead", newKey)
+	}
+	for key, message := range deprecatedKeys {
+		keys[key] = message
+	}
+	return keys
+}
+
+// RenamedKey returns the new name of an option renamed.
+func RenamedKey(oldKey string) (string, bool) {
+	newKey, found := renamedKeys[strings.ToLower(oldKey)]
+	return newKey, found
+}
+
+// ResolveDeprecations sets the options renamed from their old names, it must
+// be called once the configuration is loaded. The deprecated options set are
+// logged by LogDeprecations, once the logger is set up.
+func ResolveDeprecations() {
+	deprecationsMutex.Lock()
+	defer deprecationsMutex.Unlock()
+	deprecationWarnings = resolveDeprecations(Datadog)
+}
+
+// LogDeprecations logs the deprecated options set in the configuration.
+func LogDeprecations() {
+	deprecationsMutex.Lock()
+	defer deprecationsMutex.Unlock()
+	for _, warning := range deprecationWarnings {
+		log.Warn(warning)
+	}
+}
+
+// resolveDeprecations returns a warning per deprecated option set, in order.
+func resolveDeprecations(config *viper.Viper) []string {
+	warnings := []string{}
+	for oldKey, newKey := range renamedKeys {
+		if !isConfigured(config, oldKey) {
+			continue
+		}
+		if isConfigured(config, newKey) {
+			warnings = append(warnings, fmt.Sprintf("%q is deprecated and ignored since %q is set, remove it", oldKey, newKey))
+			continue
+		}
+		config.Set(newKey, config.Get(oldKey))
+		warnings = append(warnings, fmt.Sprintf("%q is deprecated, use %q instead", oldKey, newKey))
+	}
+	for key, message := range deprecatedKeys {
+		if isConfigured(config, key) {
+			warnings = append(warnings, fmt.Sprintf("%q is deprecated, %s", key, message))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// isConfigured returns whether an option is set by its env var or by the
+// configuration file, its default value is not taken into account.
+func isConfigured(config *viper.Viper, key string) bool {
+	if _, found := os.LookupEnv(envVarName(key)); found {
+		return true
+	}
+	path := strings.Split(key, ".")
+	if !config.InConfig(path[0]) {
+		return false
+	}
+	// the sections of the configuration file are not merged with the defaults
+	var value interface{} = config.Get(path[0])
+	for _, name := range path[1:] {
+		section, ok := toStringMap(value)
+		if !ok {
+			return false
+		}
+		if value, ok = section[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			res[strings.ToLower(key)] = value
+		}
+		return res, true
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			res[strings.ToLower(fmt.Sprintf("%v", key))] = value
+		}
+		return res, true
+	}
+	return nil, false
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDeprecationsConf(t *testing.T, yaml string) *viper.Viper {
+	conf := viper.New()
+	conf.SetEnvPrefix("DD")
+	conf.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	conf.AutomaticEnv()
+	conf.SetConfigType("yaml")
+	conf.SetDefault("test_new_key", false)
+	conf.SetDefault("test_section.new_key", "default")
+	conf.SetDefault("test_removed_key", false)
+	require.Nil(t, conf.ReadConfig(strings.NewReader(yaml)))
+	return conf
+}
+
+func TestResolveDeprecations(t *testing.T) {
+	RenameKey("test_old_key", "test_new_key")
+	RenameKey("test_section.old_key", "test_section.new_key")
+	DeprecateKey("test_removed_key", "it has no effect")
+	defer func() {
+		delete(renamedKeys, "test_old_key")
+		delete(renamedKeys, "test_section.old_key")
+		delete(deprecatedKeys, "test_removed_key")
+	}()
+
+	conf := setupDeprecationsConf(t, `
+test_new_key: false
+test_section:
+  new_key: bar
+`)
+	assert.Len(t, resolveDeprecations(conf), 0)
+
+	conf = setupDeprecationsConf(t, `
+test_old_key: true
+test_section:
+  old_key: foo
+test_removed_key: false
+`)
+	assert.Equal(t, []string{
+		`"test_old_key" is deprecated, use "test_new_key" instead`,
+		`"test_removed_key" is deprecated, it has no effect`,
+		`"test_section.old_key" is deprecated, use "test_section.new_key" instead`,
+	}, resolveDeprecations(conf))
+	assert.True(t, conf.GetBool("test_new_key"))
+	assert.Equal(t, "foo", conf.GetString("test_section.new_key"))
+
+	// the new names take precedence
+	conf = setupDeprecationsConf(t, `
+test_old_key: true
+test_new_key: false
+test_section:
+  old_key: foo
+`)
+	os.Setenv("DD_TEST_SECTION_NEW_KEY", "bar")
+	defer os.Unsetenv("DD_TEST_SECTION_NEW_KEY")
+	assert.Equal(t, []string{
+		`"test_old_key" is deprecated and ignored since "test_new_key" is set, remove it`,
+		`"test_section.old_key" is deprecated and ignored since "test_section.new_key" is set, remove it`,
+	}, resolveDeprecations(conf))
+	assert.False(t, conf.GetBool("test_new_key"))
+	assert.Equal(t, "bar", conf.GetString("test_section.new_key"))
+
+	// from the env vars
+	conf = setupDeprecationsConf(t, "")
+	os.Setenv("DD_TEST_OLD_KEY", "true")
+	defer os.Unsetenv("DD_TEST_OLD_KEY")
+	assert.Len(t, resolveDeprecations(conf), 1)
+	assert.True(t, conf.GetBool("test_new_key"))
+}
+
+func TestDeprecatedKeys(t *testing.T) {
+	keys := DeprecatedKeys()
+	assert.Equal(t, "use logs_enabled instead", keys["log_enabled"])
+	assert.Contains(t, keys, "full_cardinality_tagging")
+
+	newKey, found := RenamedKey("LOG_ENABLED")
+	assert.True(t, found)
+	assert.Equal(t, "logs_enabled", newKey)
+	_, found = RenamedKey("logs_enabled")
+	assert.False(t, found)
+}
@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	log "github.com/cihub/seelog"
+	"github.com/spf13/viper"
+)
+
+// bindEnvVars makes every key of the configuration overridable by an env var,
+// the env var of a key is prefixed by DD_ and its dots are replaced by underscores,
+// for instance DD_LOGS_CONFIG_USE_HTTP overrides logs_config.use_http.
+// The lists and the maps can be set as JSON: DD_AC_INCLUDE='["image:foo", "image:bar"]',
+// the values not starting with '[' or '{' keep their usual parsing, the lists are
+// then separated by spaces. The JSON is only parsed for the keys known when it is
+// called: the keys with a default or an env binding, which is why the lists and the
+// maps are declared with BindEnvAndSetDefault.
+func bindEnvVars(config *viper.Viper) {
+	config.AutomaticEnv()
+	for _, key := range config.AllKeys() {
+		value, found := os.LookupEnv(envVarName(key))
+		if !found {
+			continue
+		}
+		trimmed := strings.TrimSpace(value)
+		if !strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "{") {
+			continue
+		}
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(trimmed), &parsed); err != nil {
+			log.Warnf("Could not parse the value of %s as JSON, it is used as is: %s", envVarName(key), err)
+			continue
+		}
+		config.Set(key, parsed)
+	}
+}
+
+// envVarName returns the env var overriding a key.
+func envVarName(key string) string {
+	return "DD_" + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FragmentsDirName is the name of the directory of the fragments of datadog.yaml,
+// it is next to datadog.yaml
+const FragmentsDirName = "datadog.d"
+
+// Fragments returns the paths of the YAML files of the datadog.d directory next
+// to configFile, in the order they are merged over it: by file name. The options
+// of a fragment override the ones of configFile and of the fragments before it,
+// the sections are merged, the lists are replaced.
+func Fragments(configFile string) ([]string, error) {
+	dir := filepath.Join(filepath.Dir(configFile), FragmentsDirName)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	fragments := []string{}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		fragments = append(fragments, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(fragments)
+	return fragments, nil
+}
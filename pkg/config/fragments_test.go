@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFragments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datadog")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "datadog.yaml")
+
+	fragments, err := Fragments(configFile)
+	assert.Nil(t, err)
+	assert.Len(t, fragments, 0)
+
+	fragmentsDir := filepath.Join(dir, FragmentsDirName)
+	require.Nil(t, os.Mkdir(fragmentsDir, 0755))
+	require.Nil(t, os.Mkdir(filepath.Join(fragmentsDir, "subdir.yaml"), 0755))
+	for _, name := range []string{"20-tags.yaml", "10-proxy.yml", "README.md", "30-logs.yaml.bak"} {
+		require.Nil(t, ioutil.WriteFile(filepath.Join(fragmentsDir, name), []byte{}, 0644))
+	}
+
+	fragments, err = Fragments(configFile)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(fragmentsDir, "10-proxy.yml"),
+		filepath.Join(fragmentsDir, "20-tags.yaml"),
+	}, fragments)
+}
@@ -18,6 +18,8 @@ import (
 	"strings"
 
 	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/util/scrubber"
 )
 
 const logFileMaxSize = 10 * 1024 * 1024         // 10MB
@@ -25,6 +27,10 @@ const logDateFormat = "2006-01-02 15:04:05 MST" // see time.Format for format sy
 
 var logCertPool *x509.CertPool
 
+// setupLoggerWithLevel sets up the logger again with the parameters of the last
+// SetupLogger call and another level, it is nil until the logger is set up
+var setupLoggerWithLevel func(logLevel string) error
+
 // SetupLogger sets up the default logger
 func SetupLogger(logLevel, logFile, uri string, rfc, tls bool, pem string, logToConsole bool, jsonFormat bool) error {
 	var syslog bool
@@ -45,6 +51,23 @@ func SetupLogger(logLevel, logFile, uri string, rfc, tls bool, pem string, logTo
 		seelogLogLevel = "warn"
 	}
 
+	// the buffer of the flares keeps the debug lines when the level is higher,
+	// the other outputs filter them out
+	bufferSize := Datadog.GetInt("log_buffer_size")
+	if seelogLogLevel == "off" {
+		bufferSize = 0
+	}
+	recentLogs.resize(bufferSize)
+	minLevel := seelogLogLevel
+	filterLevels := ""
+	switch seelogLogLevel {
+	case "info", "warn", "error", "critical":
+		if bufferSize > 0 {
+			minLevel = "debug"
+			filterLevels = logLevelsFrom(seelogLogLevel)
+		}
+	}
+
 	configTemplate := `<seelog minlevel="%s">`
 
 	formatID := ""
@@ -56,11 +79,12 @@ func SetupLogger(logLevel, logFile, uri string, rfc, tls bool, pem string, logTo
 
 	configTemplate += fmt.Sprintf(`<outputs formatid="%s">`, formatID)
 
+	receivers := ""
 	if logToConsole {
-		configTemplate += `<console />`
+		receivers += `<console />`
 	}
 	if logFile != "" {
-		configTemplate += `<rollingfile type="size" filename="%s" maxsize="%d" maxrolls="1" />`
+		receivers += `<rollingfile type="size" filename="%s" maxsize="%d" maxrolls="1" />`
 	}
 	if syslog {
 		var syslogTemplate string
@@ -74,28 +98,55 @@ func SetupLogger(logLevel, logFile, uri string, rfc, tls bool, pem string, logTo
 		} else {
 			syslogTemplate = fmt.Sprintf(`<custom name="syslog" formatid="syslog-%s" />`, formatID)
 		}
-		configTemplate += syslogTemplate
+		receivers += syslogTemplate
+	}
+	receivers += platformLogReceivers()
+	if filterLevels != "" && receivers != "" {
+		receivers = fmt.Sprintf(`<filter levels="%s">`, filterLevels) + receivers + `</filter>`
+	}
+	configTemplate += receivers
+	if bufferSize > 0 {
+		configTemplate += `<custom name="logbuffer" />`
 	}
 
 	configTemplate += `</outputs>
 	<formats>
-		<format id="json" format="{&quot;time&quot;:&quot;%%Date(%s)&quot;,&quot;level&quot;:&quot;%%LEVEL&quot;,&quot;file&quot;:&quot;%%File&quot;,&quot;line&quot;:&quot;%%Line&quot;,&quot;func&quot;:&quot;%%FuncShort&quot;,&quot;msg&quot;:&quot;%%Msg&quot;}%%n"/>
-		<format id="common" format="%%Date(%s) | %%LEVEL | (%%File:%%Line in %%FuncShort) | %%Msg%%n"/>
-		<format id="syslog-json" format="%%CustomSyslogHeader(20,` + strconv.FormatBool(rfc) + `){&quot;level&quot;:&quot;%%LEVEL&quot;,&quot;relfile&quot;:&quot;%%RelFile&quot;,&quot;line&quot;:&quot;%%Line&quot;,&quot;msg&quot;:&quot;%%Msg&quot;}%%n"/>
-		<format id="syslog-common" format="%%CustomSyslogHeader(20,` + strconv.FormatBool(rfc) + `) %%LEVEL | (%%RelFile:%%Line) | %%Msg%%n" />`
+		<format id="json" format="{&quot;time&quot;:&quot;%%Date(%s)&quot;,&quot;level&quot;:&quot;%%LEVEL&quot;,&quot;file&quot;:&quot;%%File&quot;,&quot;line&quot;:&quot;%%Line&quot;,&quot;func&quot;:&quot;%%FuncShort&quot;,&quot;msg&quot;:&quot;%%CustomScrubbedMsg&quot;}%%n"/>
+		<format id="common" format="%%Date(%s) | %%LEVEL | (%%File:%%Line in %%FuncShort) | %%CustomScrubbedMsg%%n"/>
+		<format id="syslog-json" format="%%CustomSyslogHeader(20,` + strconv.FormatBool(rfc) + `){&quot;level&quot;:&quot;%%LEVEL&quot;,&quot;relfile&quot;:&quot;%%RelFile&quot;,&quot;line&quot;:&quot;%%Line&quot;,&quot;msg&quot;:&quot;%%CustomScrubbedMsg&quot;}%%n"/>
+		<format id="syslog-common" format="%%CustomSyslogHeader(20,` + strconv.FormatBool(rfc) + `) %%LEVEL | (%%RelFile:%%Line) | %%CustomScrubbedMsg%%n" />`
 
 	configTemplate += `</formats>
 	</seelog>`
-	config := fmt.Sprintf(configTemplate, seelogLogLevel, logFile, logFileMaxSize, logDateFormat, logDateFormat)
+	config := fmt.Sprintf(configTemplate, minLevel, logFile, logFileMaxSize, logDateFormat, logDateFormat)
 
 	logger, err := log.LoggerFromConfigAsString(config)
 	if err != nil {
 		return err
 	}
 	log.ReplaceLogger(logger)
+
+	setupLoggerWithLevel = func(logLevel string) error {
+		return SetupLogger(logLevel, logFile, uri, rfc, tls, "", logToConsole, jsonFormat)
+	}
 	return nil
 }
 
+// ChangeLogLevel changes the level of the logger set up by SetupLogger
+func ChangeLogLevel(logLevel string) error {
+	seelogLogLevel := strings.ToLower(logLevel)
+	if seelogLogLevel == "warning" {
+		seelogLogLevel = "warn"
+	}
+	if _, found := log.LogLevelFromString(seelogLogLevel); !found {
+		return fmt.Errorf("invalid log level: %s", logLevel)
+	}
+	if setupLoggerWithLevel == nil {
+		return errors.New("the logger is not set up")
+	}
+	return setupLoggerWithLevel(seelogLogLevel)
+}
+
 // ErrorLogWriter is a Writer that logs all written messages with the global seelog logger
 // at an error level
 type ErrorLogWriter struct{}
@@ -147,6 +198,13 @@ func createSyslogHeaderFormatter(params string) log.FormatterFunc {
 	}
 }
 
+// createScrubbedMsgFormatter returns the messages without the credentials
+func createScrubbedMsgFormatter(params string) log.FormatterFunc {
+	return func(message string, level log.LogLevel, context log.LogContextInterface) interface{} {
+		return scrubber.ScrubLine(message)
+	}
+}
+
 // SyslogReceiver implements seelog.CustomReceiver
 type SyslogReceiver struct {
 	enabled bool
@@ -278,5 +336,7 @@ func (s *SyslogReceiver) Close() error {
 
 func init() {
 	log.RegisterCustomFormatter("CustomSyslogHeader", createSyslogHeaderFormatter)
+	log.RegisterCustomFormatter("CustomScrubbedMsg", createScrubbedMsgFormatter)
 	log.RegisterReceiver("syslog", &SyslogReceiver{})
+	log.RegisterReceiver("logbuffer", &logBufferReceiver{})
 }
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"strings"
+	"sync"
+
+	log "github.com/cihub/seelog"
+)
+
+// seelogLevels are the levels of seelog, in increasing order
+var seelogLevels = []string{"trace", "debug", "info", "warn", "error", "critical"}
+
+// logBuffer keeps the last lines logged, at debug level even when the level of
+// the other outputs is higher, so that they are added to the flares
+type logBuffer struct {
+	lines []string
+	next  int
+	full  bool
+	m     sync.Mutex
+}
+
+var recentLogs = &logBuffer{}
+
+// resize sets the number of lines kept, the lines already kept are dropped
+// when it changes
+func (b *logBuffer) resize(size int) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	if size == len(b.lines) {
+		return
+	}
+	b.lines = make([]string, size)
+	b.next = 0
+	b.full = false
+}
+
+func (b *logBuffer) add(line string) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	if len(b.lines) == 0 {
+		return
+	}
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % len(b.lines)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// get returns the lines kept, the oldest first
+func (b *logBuffer) get() []string {
+	b.m.Lock()
+	defer b.m.Unlock()
+	if !b.full {
+		return append([]string{}, b.lines[:b.next]...)
+	}
+	return append(append([]string{}, b.lines[b.next:]...), b.lines[:b.next]...)
+}
+
+// RecentLogs returns the last lines logged by the logger set up by SetupLogger,
+// at debug level whatever the log level, the oldest first. It keeps
+// `log_buffer_size` lines.
+func RecentLogs() []string {
+	return recentLogs.get()
+}
+
+// logLevelsFrom returns the seelog levels from level, comma separated
+func logLevelsFrom(level string) string {
+	for i, l := range seelogLevels {
+		if l == level {
+			return strings.Join(seelogLevels[i:], ",")
+		}
+	}
+	return level
+}
+
+// logBufferReceiver implements seelog.CustomReceiver, it adds the messages to
+// recentLogs
+type logBufferReceiver struct{}
+
+// ReceiveMessage adds the message to the buffer
+func (r *logBufferReceiver) ReceiveMessage(message string, level log.LogLevel, context log.LogContextInterface) error {
+	recentLogs.add(strings.TrimSuffix(message, "\n"))
+	return nil
+}
+
+// AfterParse is a NOP, the buffer is sized by SetupLogger
+func (r *logBufferReceiver) AfterParse(initArgs log.CustomReceiverInitArgs) error {
+	return nil
+}
+
+// Flush is a NOP, the messages are added when they are received
+func (r *logBufferReceiver) Flush() {}
+
+// Close is a NOP, the buffer is kept for the next logger
+func (r *logBufferReceiver) Close() error {
+	return nil
+}
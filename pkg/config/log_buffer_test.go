@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/cihub/seelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogBuffer(t *testing.T) {
+	b := &logBuffer{}
+	b.add("dropped")
+	assert.Len(t, b.get(), 0)
+
+	b.resize(3)
+	b.add("a")
+	b.add("b")
+	assert.Equal(t, []string{"a", "b"}, b.get())
+	b.add("c")
+	b.add("d")
+	assert.Equal(t, []string{"b", "c", "d"}, b.get())
+}
+
+func TestLogLevelsFrom(t *testing.T) {
+	assert.Equal(t, "info,warn,error,critical", logLevelsFrom("info"))
+	assert.Equal(t, "critical", logLevelsFrom("critical"))
+}
+
+func TestRecentLogsAtDebugLevel(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "datadog-agent-logs-")
+	require.Nil(t, err)
+	defer os.RemoveAll(testDir)
+	logFile := filepath.Join(testDir, "agent.log")
+
+	Datadog.Set("log_buffer_size", 2)
+	defer Datadog.Set("log_buffer_size", 0)
+	require.Nil(t, SetupLogger("info", logFile, "", false, false, "", false, false))
+	defer log.ReplaceLogger(log.Disabled)
+
+	log.Debugf("debug line %d", 1)
+	log.Infof("info line %d", 2)
+	log.Debugf("debug line %d", 3)
+	log.Flush()
+
+	lines := RecentLogs()
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "INFO")
+	assert.Contains(t, lines[0], "info line 2")
+	assert.Contains(t, lines[1], "DEBUG")
+	assert.Contains(t, lines[1], "debug line 3")
+
+	// the log file is still at info level
+	content, err := ioutil.ReadFile(logFile)
+	require.Nil(t, err)
+	assert.Contains(t, string(content), "info line 2")
+	assert.NotContains(t, string(content), "debug line 1")
+	assert.NotContains(t, string(content), "debug line 3")
+}
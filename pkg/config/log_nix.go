@@ -20,3 +20,8 @@ func GetSyslogURI() string {
 
 	return uri
 }
+
+// platformLogReceivers returns the receivers only available on the platform
+func platformLogReceivers() string {
+	return ""
+}
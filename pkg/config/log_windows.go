@@ -6,7 +6,20 @@
 package config
 
 import (
+	"strings"
+
 	log "github.com/cihub/seelog"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLogSource is the event source registered by the agent install-service
+// command, its messages are defined in cmd/agent/agentmsg.mc
+const eventLogSource = "DatadogAgent"
+
+// event IDs of the MSG_LOG_WARNING and MSG_LOG_ERROR messages
+const (
+	eventLogWarningID = 0x8000000b
+	eventLogErrorID   = 0xc000000c
 )
 
 // GetSyslogURI returns the configured/default syslog uri
@@ -18,3 +31,55 @@ func GetSyslogURI() string {
 	}
 	return ""
 }
+
+// platformLogReceivers returns the receivers only available on the platform
+func platformLogReceivers() string {
+	if Datadog.GetBool("log_to_event_viewer") {
+		return `<custom name="eventlog" formatid="common" />`
+	}
+	return ""
+}
+
+// EventLogReceiver implements seelog.CustomReceiver, it writes the warnings
+// and the errors to the Windows event log
+type EventLogReceiver struct {
+	elog *eventlog.Log
+}
+
+// ReceiveMessage writes the message to the event log if it is a warning or
+// an error, the other levels would flood it
+func (r *EventLogReceiver) ReceiveMessage(message string, level log.LogLevel, context log.LogContextInterface) error {
+	message = strings.TrimSuffix(message, "\n")
+	switch level {
+	case log.WarnLvl:
+		return r.elog.Warning(eventLogWarningID, message)
+	case log.ErrorLvl, log.CriticalLvl:
+		return r.elog.Error(eventLogErrorID, message)
+	}
+	return nil
+}
+
+// AfterParse opens the event log, the source must have been registered
+func (r *EventLogReceiver) AfterParse(initArgs log.CustomReceiverInitArgs) error {
+	elog, err := eventlog.Open(eventLogSource)
+	if err != nil {
+		return err
+	}
+	r.elog = elog
+	return nil
+}
+
+// Flush is a NOP, the messages are written when they are received
+func (r *EventLogReceiver) Flush() {}
+
+// Close closes the event log
+func (r *EventLogReceiver) Close() error {
+	if r.elog == nil {
+		return nil
+	}
+	return r.elog.Close()
+}
+
+func init() {
+	log.RegisterReceiver("eventlog", &EventLogReceiver{})
+}
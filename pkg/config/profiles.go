@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// profiles are the presets selected by the profile option, by name: their
+// options replace the defaults, the options set explicitly take precedence
+var profiles = map[string]map[string]interface{}{
+	// for the hosts receiving a lot of dogstatsd traffic: larger queues
+	// between dogstatsd, the aggregator and the forwarder, more workers
+	"high_throughput_statsd": {
+		"dogstatsd_buffer_size":          1024 * 16,
+		"dogstatsd_queue_size":           1024,
+		"aggregator_buffer_size":         1000,
+		"forwarder_num_workers":          4,
+		"forwarder_retry_queue_max_size": 60,
+	},
+	// for the small hosts and containers: smaller queues, a single worker
+	// and less frequent logs flushes
+	"low_resource": {
+		"check_runners":                  int64(1),
+		"dogstatsd_queue_size":           20,
+		"aggregator_buffer_size":         20,
+		"forwarder_num_workers":          1,
+		"forwarder_retry_queue_max_size": 10,
+		"logs_config.open_files_limit":   20,
+		"logs_config.batch_wait":         10,
+		"logs_config.batch_max_size":     100,
+	},
+}
+
+// ProfileNames returns the names of the built-in profiles, in order.
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile sets the defaults of the profile selected by the profile
+// option, it must be called once the configuration is loaded.
+func ApplyProfile() error {
+	return applyProfile(Datadog, Datadog.GetString("profile"))
+}
+
+func applyProfile(config *viper.Viper, name string) error {
+	if name == "" {
+		return nil
+	}
+	preset, found := profiles[strings.ToLower(name)]
+	if !found {
+		return fmt.Errorf("unknown profile %q, the valid profiles are: %s", name, strings.Join(ProfileNames(), ", "))
+	}
+	for key, value := range preset {
+		config.SetDefault(key, value)
+	}
+	return nil
+}
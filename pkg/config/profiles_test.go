@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupProfilesConf(t *testing.T, yaml string) *viper.Viper {
+	conf := viper.New()
+	conf.SetConfigType("yaml")
+	conf.SetDefault("dogstatsd_queue_size", 100)
+	conf.SetDefault("forwarder_num_workers", 1)
+	conf.SetDefault("logs_config.batch_wait", 5)
+	conf.SetDefault("logs_config.batch_max_size", 200)
+	require.Nil(t, conf.ReadConfig(strings.NewReader(yaml)))
+	return conf
+}
+
+func TestApplyProfile(t *testing.T) {
+	conf := setupProfilesConf(t, `
+profile: low_resource
+logs_config:
+  batch_max_size: 50
+`)
+	require.Nil(t, applyProfile(conf, conf.GetString("profile")))
+
+	assert.Equal(t, 20, conf.GetInt("dogstatsd_queue_size"))
+	assert.Equal(t, 10, conf.GetInt("logs_config.batch_wait"))
+	// the options set explicitly take precedence over the profile
+	assert.Equal(t, 50, conf.GetInt("logs_config.batch_max_size"))
+}
+
+func TestApplyProfileNone(t *testing.T) {
+	conf := setupProfilesConf(t, `
+forwarder_num_workers: 2
+`)
+	require.Nil(t, applyProfile(conf, conf.GetString("profile")))
+
+	assert.Equal(t, 100, conf.GetInt("dogstatsd_queue_size"))
+	assert.Equal(t, 2, conf.GetInt("forwarder_num_workers"))
+}
+
+func TestApplyProfileUnknown(t *testing.T) {
+	conf := setupProfilesConf(t, "")
+	err := applyProfile(conf, "tiny")
+	require.NotNil(t, err)
+	assert.Equal(t, `unknown profile "tiny", the valid profiles are: high_throughput_statsd, low_resource`, err.Error())
+	assert.Equal(t, 100, conf.GetInt("dogstatsd_queue_size"))
+}
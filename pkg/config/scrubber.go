@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/viper"
+
+	"github.com/DataDog/datadog-agent/pkg/util/scrubber"
+)
+
+// defaultScrubberReplacement replaces the matches of the rules without replacement
+const defaultScrubberReplacement = "********"
+
+// scrubberRule is a rule of the `scrubber.custom_rules` option
+type scrubberRule struct {
+	Pattern     string `mapstructure:"pattern"`
+	Replacement string `mapstructure:"replacement"`
+}
+
+// SetupScrubber adds the rules of the `scrubber.custom_rules` option to the
+// scrubber of the flares and of the logs, it must be called once the
+// configuration is loaded.
+func SetupScrubber() error {
+	replacers, err := scrubberReplacers(Datadog)
+	if err != nil {
+		return err
+	}
+	scrubber.SetCustomReplacers(replacers)
+	return nil
+}
+
+func scrubberReplacers(config *viper.Viper) ([]scrubber.Replacer, error) {
+	var rules []scrubberRule
+	if err := config.UnmarshalKey("scrubber.custom_rules", &rules); err != nil {
+		return nil, fmt.Errorf("cannot parse the scrubber.custom_rules option: %s", err)
+	}
+	replacers := make([]scrubber.Replacer, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("a rule of scrubber.custom_rules has no pattern")
+		}
+		regex, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in scrubber.custom_rules: %s", rule.Pattern, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = defaultScrubberReplacement
+		}
+		replacers = append(replacers, scrubber.Replacer{Regex: regex, Repl: []byte(replacement)})
+	}
+	return replacers, nil
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/scrubber"
+)
+
+func setupScrubberConf(t *testing.T, yaml string) *viper.Viper {
+	conf := viper.New()
+	conf.SetConfigType("yaml")
+	require.Nil(t, conf.ReadConfig(strings.NewReader(yaml)))
+	return conf
+}
+
+func TestScrubberReplacers(t *testing.T) {
+	conf := setupScrubberConf(t, `
+scrubber:
+  custom_rules:
+    - pattern: 'customer_id=\d+'
+    - pattern: '(internal_secret:).+'
+      replacement: '$1 hidden'
+`)
+	replacers, err := scrubberReplacers(conf)
+	require.Nil(t, err)
+	require.Len(t, replacers, 2)
+
+	scrubber.SetCustomReplacers(replacers)
+	defer scrubber.SetCustomReplacers(nil)
+	assert.Equal(t, "GET /orders?********", scrubber.ScrubLine("GET /orders?customer_id=4242"))
+	assert.Equal(t, "internal_secret: hidden", scrubber.ScrubLine("internal_secret: foo"))
+}
+
+func TestScrubberReplacersNone(t *testing.T) {
+	replacers, err := scrubberReplacers(setupScrubberConf(t, "api_key: foo"))
+	require.Nil(t, err)
+	assert.Len(t, replacers, 0)
+}
+
+func TestScrubberReplacersInvalid(t *testing.T) {
+	_, err := scrubberReplacers(setupScrubberConf(t, `
+scrubber:
+  custom_rules:
+    - pattern: 'customer_id=(\d+'
+`))
+	assert.NotNil(t, err)
+
+	_, err = scrubberReplacers(setupScrubberConf(t, `
+scrubber:
+  custom_rules:
+    - replacement: foo
+`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package settings
+
+import (
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// profilingEnabled is 1 while the block and mutex profiles are collected
+var profilingEnabled uint32
+
+// ProfilingRuntimeSetting enables the block and mutex profiles served by the
+// pprof endpoints of the IPC api, they slow the agent down.
+type ProfilingRuntimeSetting struct{}
+
+// Name returns the name of the setting
+func (p ProfilingRuntimeSetting) Name() string {
+	return "profiling"
+}
+
+// Description returns the description of the setting
+func (p ProfilingRuntimeSetting) Description() string {
+	return "Enable the block and mutex profiles of the pprof endpoints, valid values are: true and false"
+}
+
+// Get returns whether the profiles are collected
+func (p ProfilingRuntimeSetting) Get() (interface{}, error) {
+	return atomic.LoadUint32(&profilingEnabled) == 1, nil
+}
+
+// Set enables or disables the profiles
+func (p ProfilingRuntimeSetting) Set(value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		runtime.SetBlockProfileRate(1)
+		runtime.SetMutexProfileFraction(1)
+		atomic.StoreUint32(&profilingEnabled, 1)
+	} else {
+		runtime.SetBlockProfileRate(0)
+		runtime.SetMutexProfileFraction(0)
+		atomic.StoreUint32(&profilingEnabled, 0)
+	}
+	return nil
+}
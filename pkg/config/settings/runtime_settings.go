@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package settings implements the settings that can be changed while the
+// agent is running, through the `/agent/config` endpoints of the IPC API.
+package settings
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+// RuntimeSetting represents a setting that can be changed at runtime,
+// only the settings registered can be changed.
+type RuntimeSetting interface {
+	Name() string
+	Description() string
+	Get() (interface{}, error)
+	Set(value string) error
+}
+
+// SettingNotFoundError is returned for the settings that are not registered.
+type SettingNotFoundError struct {
+	name string
+}
+
+func (e *SettingNotFoundError) Error() string {
+	return fmt.Sprintf("setting %s not found", e.name)
+}
+
+// revert represents the previous value of a setting set for a duration.
+type revert struct {
+	timer *time.Timer
+	value string
+}
+
+var (
+	runtimeSettings = map[string]RuntimeSetting{}
+	reverts         = map[string]*revert{}
+	mu              sync.Mutex
+)
+
+// RegisterRuntimeSetting allows a setting to be changed at runtime.
+func RegisterRuntimeSetting(setting RuntimeSetting) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, found := runtimeSettings[setting.Name()]; found {
+		return fmt.Errorf("duplicated setting %s", setting.Name())
+	}
+	runtimeSettings[setting.Name()] = setting
+	return nil
+}
+
+// RuntimeSettings returns the settings that can be changed at runtime, by name.
+func RuntimeSettings() map[string]RuntimeSetting {
+	mu.Lock()
+	defer mu.Unlock()
+	settings := make(map[string]RuntimeSetting, len(runtimeSettings))
+	for name, setting := range runtimeSettings {
+		settings[name] = setting
+	}
+	return settings
+}
+
+// GetRuntimeSetting returns the current value of a setting.
+func GetRuntimeSetting(name string) (interface{}, error) {
+	mu.Lock()
+	setting, found := runtimeSettings[name]
+	mu.Unlock()
+	if !found {
+		return nil, &SettingNotFoundError{name: name}
+	}
+	return setting.Get()
+}
+
+// SetRuntimeSetting changes the value of a setting, the value it had before
+// is restored after duration when it is not zero. Setting it again before
+// restores the value it had before the first change.
+func SetRuntimeSetting(name string, value string, duration time.Duration) error {
+	mu.Lock()
+	defer mu.Unlock()
+	setting, found := runtimeSettings[name]
+	if !found {
+		return &SettingNotFoundError{name: name}
+	}
+
+	previous, pending := reverts[name]
+	var previousValue string
+	if pending {
+		previousValue = previous.value
+	} else if duration > 0 {
+		current, err := setting.Get()
+		if err != nil {
+			return fmt.Errorf("could not get the value of %s: %s", name, err)
+		}
+		previousValue = fmt.Sprintf("%v", current)
+	}
+
+	if err := setting.Set(value); err != nil {
+		return err
+	}
+	log.Infof("Setting %s changed to %s", name, value)
+
+	if pending {
+		previous.timer.Stop()
+		delete(reverts, name)
+	}
+	if duration > 0 {
+		r := &revert{value: previousValue}
+		r.timer = time.AfterFunc(duration, func() { revertSetting(setting, r) })
+		reverts[name] = r
+	}
+	return nil
+}
+
+// revertSetting restores the previous value of a setting, unless it was changed since.
+func revertSetting(setting RuntimeSetting, r *revert) {
+	mu.Lock()
+	defer mu.Unlock()
+	if reverts[setting.Name()] != r {
+		return
+	}
+	delete(reverts, setting.Name())
+	if err := setting.Set(r.value); err != nil {
+		log.Errorf("Could not restore the value %s of the setting %s: %s", r.value, setting.Name(), err)
+		return
+	}
+	log.Infof("Setting %s restored to %s", setting.Name(), r.value)
+}
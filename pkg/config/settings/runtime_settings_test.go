@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package settings
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockSetting is a setting that can not be set to "invalid"
+type mockSetting struct {
+	sync.Mutex
+	name  string
+	value string
+}
+
+func (m *mockSetting) Name() string        { return m.name }
+func (m *mockSetting) Description() string { return "mock setting" }
+
+func (m *mockSetting) Get() (interface{}, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.value, nil
+}
+
+func (m *mockSetting) Set(value string) error {
+	if value == "invalid" {
+		return fmt.Errorf("invalid value")
+	}
+	m.Lock()
+	defer m.Unlock()
+	m.value = value
+	return nil
+}
+
+func registerMockSetting(t *testing.T, name string) *mockSetting {
+	setting := &mockSetting{name: name, value: "info"}
+	assert.Nil(t, RegisterRuntimeSetting(setting))
+	return setting
+}
+
+func TestRegisterRuntimeSetting(t *testing.T) {
+	registerMockSetting(t, "register")
+	assert.NotNil(t, RegisterRuntimeSetting(&mockSetting{name: "register"}))
+	_, found := RuntimeSettings()["register"]
+	assert.True(t, found)
+}
+
+func TestGetAndSetRuntimeSetting(t *testing.T) {
+	registerMockSetting(t, "set")
+
+	assert.Nil(t, SetRuntimeSetting("set", "debug", 0))
+	value, err := GetRuntimeSetting("set")
+	assert.Nil(t, err)
+	assert.Equal(t, "debug", value)
+
+	assert.NotNil(t, SetRuntimeSetting("set", "invalid", 0))
+	value, _ = GetRuntimeSetting("set")
+	assert.Equal(t, "debug", value)
+}
+
+func TestUnknownRuntimeSetting(t *testing.T) {
+	_, err := GetRuntimeSetting("unknown")
+	_, ok := err.(*SettingNotFoundError)
+	assert.True(t, ok)
+
+	err = SetRuntimeSetting("unknown", "foo", 0)
+	_, ok = err.(*SettingNotFoundError)
+	assert.True(t, ok)
+}
+
+func TestRuntimeSettingIsRestoredAfterDuration(t *testing.T) {
+	setting := registerMockSetting(t, "revert")
+
+	assert.Nil(t, SetRuntimeSetting("revert", "debug", 50*time.Millisecond))
+	value, _ := setting.Get()
+	assert.Equal(t, "debug", value)
+
+	// the value restored is the one before the first change
+	assert.Nil(t, SetRuntimeSetting("revert", "trace", 50*time.Millisecond))
+	time.Sleep(200 * time.Millisecond)
+	value, _ = setting.Get()
+	assert.Equal(t, "info", value)
+}
+
+func TestRuntimeSettingSetWithoutDurationCancelsTheRestore(t *testing.T) {
+	setting := registerMockSetting(t, "cancel")
+
+	assert.Nil(t, SetRuntimeSetting("cancel", "debug", 50*time.Millisecond))
+	assert.Nil(t, SetRuntimeSetting("cancel", "warn", 0))
+	time.Sleep(200 * time.Millisecond)
+	value, _ := setting.Get()
+	assert.Equal(t, "warn", value)
+}
@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package validate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	logsConfig "github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+// checkKeys are the sections of a check configuration file
+var checkKeys = map[string]bool{
+	"init_config":    true,
+	"instances":      true,
+	"logs":           true,
+	"ad_identifiers": true,
+	"jmx_metrics":    true,
+	"docker_images":  true,
+}
+
+var (
+	logsConfigKeys     = structKeys(reflect.TypeOf(logsConfig.LogsConfig{}))
+	processingRuleKeys = structKeys(reflect.TypeOf(logsConfig.LogsProcessingRule{}))
+)
+
+// ValidateChecksConfigs checks the check configuration files of a conf.d directory,
+// the files `<check>.yaml` and the files of the directories `<check>.d`.
+func ValidateChecksConfigs(confdPath string) ([]Issue, error) {
+	entries, err := ioutil.ReadDir(confdPath)
+	if err != nil {
+		return nil, err
+	}
+	res := []Issue{}
+	for _, entry := range entries {
+		path := filepath.Join(confdPath, entry.Name())
+		if !entry.IsDir() {
+			res = append(res, validateCheckFile(path)...)
+			continue
+		}
+		if filepath.Ext(entry.Name()) != ".d" {
+			continue
+		}
+		subEntries, err := ioutil.ReadDir(path)
+		if err != nil {
+			res = append(res, Issue{File: path, Message: err.Error()})
+			continue
+		}
+		for _, subEntry := range subEntries {
+			if !subEntry.IsDir() {
+				res = append(res, validateCheckFile(filepath.Join(path, subEntry.Name()))...)
+			}
+		}
+	}
+	return res, nil
+}
+
+// validateCheckFile checks a file when it is a check configuration file.
+func validateCheckFile(path string) []Issue {
+	ext := filepath.Ext(strings.TrimSuffix(path, ".default"))
+	if ext != ".yaml" && ext != ".yml" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return []Issue{{File: path, Message: err.Error()}}
+	}
+	return validateCheckConfig(path, data)
+}
+
+func validateCheckConfig(file string, data []byte) []Issue {
+	conf := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return []Issue{syntaxError(file, err)}
+	}
+	i := &issues{file: file, data: data}
+
+	for _, name := range sortedKeys(conf) {
+		if !checkKeys[name] {
+			i.error([]string{name}, "unknown section, the valid sections are: init_config, instances, logs, ad_identifiers and jmx_metrics")
+		}
+	}
+
+	if err := checkType(conf["init_config"], map[string]interface{}{}); err != nil {
+		i.error([]string{"init_config"}, "%s", err)
+	}
+	if err := checkList(conf["ad_identifiers"], ""); err != nil {
+		i.error([]string{"ad_identifiers"}, "%s", err)
+	}
+	if _, found := conf["docker_images"]; found {
+		if _, found := conf["ad_identifiers"]; found {
+			i.warn([]string{"docker_images"}, "deprecated section, it is ignored, use ad_identifiers instead")
+		} else {
+			i.error([]string{"docker_images"}, "deprecated section, use ad_identifiers instead")
+		}
+	}
+
+	instances, _ := conf["instances"].([]interface{})
+	if err := checkList(conf["instances"], map[string]interface{}{}); err != nil {
+		i.error([]string{"instances"}, "%s", err)
+	}
+	if len(instances) == 0 && conf["logs"] == nil && conf["jmx_metrics"] == nil {
+		i.error(nil, "no instances, logs or jmx_metrics section, the file is ignored")
+	}
+
+	if err := checkList(conf["logs"], map[string]interface{}{}); err != nil {
+		i.error([]string{"logs"}, "%s", err)
+	} else if logs, ok := conf["logs"].([]interface{}); ok {
+		for _, logConfig := range logs {
+			section, ok := logConfig.(map[interface{}]interface{})
+			if !ok {
+				i.error([]string{"logs"}, "expected a section, got %s", describe(logConfig))
+				continue
+			}
+			validateStruct(i, section, []string{"logs"}, logsConfigKeys)
+		}
+	}
+	return i.sorted()
+}
+
+// checkList returns an error when value is not a list of items of the type of item.
+func checkList(value interface{}, item interface{}) error {
+	if value == nil {
+		return nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("expected a list, got %s", describe(value))
+	}
+	for _, v := range items {
+		if err := checkType(v, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateStruct checks the options of a section decoded to a struct, they are
+// the fields of the struct by their mapstructure names.
+func validateStruct(i *issues, section map[interface{}]interface{}, path []string, keys map[string]reflect.Type) {
+	for _, name := range sortedKeys(section) {
+		value := section[name]
+		keyPath := append(append([]string{}, path...), name)
+		fieldType, found := keys[strings.ToLower(name)]
+		if !found {
+			i.error(keyPath, "unknown option")
+			continue
+		}
+		if fieldType == reflect.TypeOf([]logsConfig.LogsProcessingRule{}) {
+			if err := checkList(value, map[string]interface{}{}); err != nil {
+				i.error(keyPath, "%s", err)
+				continue
+			}
+			rules, _ := value.([]interface{})
+			for _, rule := range rules {
+				section, ok := rule.(map[interface{}]interface{})
+				if !ok {
+					i.error(keyPath, "expected a section, got %s", describe(rule))
+					continue
+				}
+				validateStruct(i, section, keyPath, processingRuleKeys)
+			}
+			continue
+		}
+		if err := checkType(value, reflect.Zero(fieldType).Interface()); err != nil {
+			i.error(keyPath, "%s", err)
+		}
+	}
+}
+
+// structKeys returns the types of the fields of a struct by their mapstructure
+// names, the fields set at runtime are skipped.
+func structKeys(t reflect.Type) map[string]reflect.Type {
+	keys := map[string]reflect.Type{}
+	for j := 0; j < t.NumField(); j++ {
+		field := t.Field(j)
+		if field.PkgPath != "" || field.Type.Kind() == reflect.Ptr || field.Type == reflect.TypeOf([]byte{}) || field.Name == "Identifier" {
+			continue
+		}
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		keys[name] = field.Type
+	}
+	return keys
+}
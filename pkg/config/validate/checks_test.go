@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package validate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCheckConfigValid(t *testing.T) {
+	issues := validateCheckConfig("redisdb.yaml", []byte(`
+init_config:
+instances:
+  - host: localhost
+    port: 6379
+logs:
+  - type: file
+    path: /var/log/redis.log
+    service: redis
+    source: redis
+    sourcecategory: database
+    log_processing_rules:
+      - type: exclude_at_match
+        name: exclude_debug
+        pattern: DEBUG
+`))
+	assert.Len(t, issues, 0)
+}
+
+func TestValidateCheckConfigInvalid(t *testing.T) {
+	issues := validateCheckConfig("redisdb.yaml", []byte(`
+init_config:
+instance:
+  - host: localhost
+logs:
+  - type: file
+    paths: /var/log/redis.log
+    port: http
+    log_processing_rules:
+      - type: exclude_at_match
+        regex: DEBUG
+`))
+	assert.Len(t, issues, 4)
+	assert.Equal(t, 3, issues[0].Line)
+	assert.Equal(t, "instance", issues[0].Key)
+	assert.Equal(t, "redisdb.yaml:7: logs.paths: unknown option", issues[1].String())
+	assert.Equal(t, "redisdb.yaml:8: logs.port: expected an integer, got the string \"http\"", issues[2].String())
+	assert.Equal(t, "redisdb.yaml:11: logs.log_processing_rules.regex: unknown option", issues[3].String())
+}
+
+func TestValidateCheckConfigInstances(t *testing.T) {
+	issues := validateCheckConfig("redisdb.yaml", []byte(`
+instances:
+  - localhost
+ad_identifiers: redis
+`))
+	assert.Len(t, issues, 2)
+	assert.Equal(t, "redisdb.yaml:2: instances: expected a section, got the string \"localhost\"", issues[0].String())
+	assert.Equal(t, "redisdb.yaml:4: ad_identifiers: expected a list, got the string \"redis\"", issues[1].String())
+}
+
+func TestValidateCheckConfigEmptyItems(t *testing.T) {
+	issues := validateCheckConfig("redisdb.yaml", []byte(`
+logs:
+  -
+  - type: file
+    path: /var/log/redis.log
+    log_processing_rules:
+      -
+`))
+	assert.Len(t, issues, 2)
+	assert.Equal(t, "redisdb.yaml:2: logs: expected a section, got an empty value", issues[0].String())
+	assert.Equal(t, "redisdb.yaml:6: logs.log_processing_rules: expected a section, got an empty value", issues[1].String())
+}
+
+func TestValidateCheckConfigDeprecatedDockerImages(t *testing.T) {
+	issues := validateCheckConfig("redisdb.yaml", []byte(`
+docker_images:
+  - redis
+instances:
+  - host: "%%host%%"
+`))
+	assert.Len(t, issues, 1)
+	assert.False(t, issues[0].Warning)
+	assert.Equal(t, "docker_images", issues[0].Key)
+}
+
+func TestValidateChecksConfigs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "confd")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "redisdb.d"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "redisdb.d", "conf.yaml"), []byte("instance:\n  - host: localhost\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "cpu.yaml.default"), []byte("init_config:\ninstances:\n  - {}\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not a config"), 0644))
+
+	issues, err := ValidateChecksConfigs(dir)
+	assert.Nil(t, err)
+	assert.Len(t, issues, 2)
+	assert.Equal(t, "no instances, logs or jmx_metrics section, the file is ignored", issues[0].Message)
+	for _, issue := range issues {
+		assert.Equal(t, filepath.Join(dir, "redisdb.d", "conf.yaml"), issue.File)
+	}
+}
+
+func TestKeyLine(t *testing.T) {
+	data := []byte(`# comment
+logs_config:
+  use_http: true
+logs:
+  - type: file
+    path: /foo
+  - "type": tcp
+    port: 10514
+`)
+	assert.Equal(t, 2, keyLine(data, []string{"logs_config"}))
+	assert.Equal(t, 3, keyLine(data, []string{"logs_config", "use_http"}))
+	assert.Equal(t, 5, keyLine(data, []string{"logs", "type"}))
+	assert.Equal(t, 6, keyLine(data, []string{"logs", "path"}))
+	assert.Equal(t, 8, keyLine(data, []string{"logs", "port"}))
+	assert.Equal(t, 0, keyLine(data, []string{"use_http"}))
+}
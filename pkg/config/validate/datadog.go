@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package validate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+var (
+	// schema holds the default value of every option of datadog.yaml, it is
+	// captured when the package is initialized, before the file is loaded
+	schema = map[string]interface{}{}
+	// sections holds the parents of the options, for instance logs_config
+	sections = map[string]bool{}
+)
+
+// undefaultedKeys are the options read by the agent without default value, the
+// lists and the maps all have one
+var undefaultedKeys = []string{
+	"apm_enabled",
+	"kubernetes_collect_service_tags",
+	"kubernetes_service_tag_update_freq",
+	"logs_config.dev_mode_no_ssl",
+	"process_agent_enabled",
+}
+
+// freeFormSections are the sections of the other agents, and the sections keyed
+// by values holding dots (OIDs, IPs), their options are not checked
+var freeFormSections = []string{"apm_config", "process_config", "snmp_traps.device_tags", "snmp_traps.translations"}
+
+func init() {
+	for _, key := range config.Datadog.AllKeys() {
+		addKey(key, config.Datadog.Get(key))
+	}
+	for _, key := range undefaultedKeys {
+		addKey(key, nil)
+	}
+}
+
+// addKey adds an option to the schema, its type is the one of its default value.
+func addKey(key string, defaultValue interface{}) {
+	schema[key] = defaultValue
+	parts := strings.Split(key, ".")
+	for i := 1; i < len(parts); i++ {
+		sections[strings.Join(parts[:i], ".")] = true
+	}
+}
+
+// ValidateDatadogConfig checks the options of a datadog.yaml file, it returns
+// the unknown options, the options of an invalid type and the deprecated ones.
+func ValidateDatadogConfig(path string) ([]Issue, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return validateDatadogConfig(path, data), nil
+}
+
+func validateDatadogConfig(file string, data []byte) []Issue {
+	conf := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return []Issue{syntaxError(file, err)}
+	}
+	i := &issues{file: file, data: data}
+	validateSection(i, conf, nil)
+	return i.sorted()
+}
+
+// validateSection checks the options of a section, path is the path of the section.
+func validateSection(i *issues, section map[interface{}]interface{}, path []string) {
+	for _, name := range sortedKeys(section) {
+		value := section[name]
+		keyPath := append(append([]string{}, path...), name)
+		key := strings.ToLower(strings.Join(keyPath, "."))
+
+		if message, found := config.DeprecatedKeys()[key]; found {
+			i.warn(keyPath, "deprecated option, %s", message)
+		}
+		// the renamed options have the type of their new name
+		schemaKey := key
+		if newKey, found := config.RenamedKey(key); found {
+			schemaKey = newKey
+		}
+		if expected, found := schema[schemaKey]; found {
+			if err := checkType(value, expected); err != nil {
+				i.error(keyPath, "%s", err)
+			} else if key == "profile" {
+				validateProfile(i, keyPath, value)
+			}
+			continue
+		}
+		if isFreeForm(key) {
+			continue
+		}
+		if sections[key] {
+			if value == nil {
+				continue
+			}
+			subsection, ok := value.(map[interface{}]interface{})
+			if !ok {
+				i.error(keyPath, "expected a section, got %s", describe(value))
+				continue
+			}
+			validateSection(i, subsection, keyPath)
+			continue
+		}
+		i.error(keyPath, "unknown option")
+	}
+}
+
+// validateProfile checks that the profile option selects a built-in profile.
+func validateProfile(i *issues, keyPath []string, value interface{}) {
+	name, _ := value.(string)
+	if name == "" {
+		return
+	}
+	for _, profile := range config.ProfileNames() {
+		if strings.ToLower(name) == profile {
+			return
+		}
+	}
+	i.error(keyPath, "unknown profile, the valid profiles are: %s", strings.Join(config.ProfileNames(), ", "))
+}
+
+func isFreeForm(key string) bool {
+	for _, section := range freeFormSections {
+		if key == section || strings.HasPrefix(key, section+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a section in order, for the issues to be reported in order.
+func sortedKeys(section map[interface{}]interface{}) []string {
+	keys := make([]string, 0, len(section))
+	for key := range section {
+		keys = append(keys, fmt.Sprintf("%v", key))
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateDatadogConfigValid(t *testing.T) {
+	issues := validateDatadogConfig("datadog.yaml", []byte(`
+api_key: foo
+log_level: debug
+ac_include: ["image:foo"]
+docker_labels_as_tags:
+  app: application
+logs_config:
+  use_http: "true"
+  batch_wait: 10
+apm_config:
+  max_traces_per_second: 10
+snmp_traps:
+  enabled: true
+  users:
+    - user: datadog
+      auth_key: secret
+  translations:
+    1.3.6.1.4.1.9.9.43.2.0.1: CISCO-CONFIG-MAN-MIB::ciscoConfigManEvent
+  device_tags:
+    10.0.0.1: [device:switch-1]
+  service_checks:
+    - trap: IF-MIB::linkUp
+      name: snmp.interface.up
+      status: ok
+`))
+	assert.Len(t, issues, 0)
+}
+
+func TestValidateDatadogConfigUnknownKeys(t *testing.T) {
+	issues := validateDatadogConfig("datadog.yaml", []byte(`
+api_key: foo
+log_levl: debug
+logs_config:
+  use_http: true
+  use_htp: true
+`))
+	assert.Len(t, issues, 2)
+	assert.Equal(t, "datadog.yaml:3: log_levl: unknown option", issues[0].String())
+	assert.Equal(t, "datadog.yaml:6: logs_config.use_htp: unknown option", issues[1].String())
+	assert.True(t, HasErrors(issues))
+}
+
+func TestValidateDatadogConfigTypeMismatches(t *testing.T) {
+	issues := validateDatadogConfig("datadog.yaml", []byte(`
+log_level:
+  - debug
+docker_labels_as_tags: app
+logs_config:
+  use_http: maybe
+  batch_wait: 1.5
+`))
+	assert.Len(t, issues, 4)
+	assert.Equal(t, "datadog.yaml:2: log_level: expected a string, got a list", issues[0].String())
+	assert.Equal(t, "datadog.yaml:4: docker_labels_as_tags: expected a section, got the string \"app\"", issues[1].String())
+	assert.Equal(t, "datadog.yaml:6: logs_config.use_http: expected a boolean, got the string \"maybe\"", issues[2].String())
+	assert.Equal(t, "datadog.yaml:7: logs_config.batch_wait: expected an integer, got the number 1.5", issues[3].String())
+}
+
+func TestValidateDatadogConfigDeprecatedKeys(t *testing.T) {
+	issues := validateDatadogConfig("datadog.yaml", []byte(`
+log_enabled: true
+`))
+	assert.Len(t, issues, 1)
+	assert.True(t, issues[0].Warning)
+	assert.Equal(t, 2, issues[0].Line)
+	assert.False(t, HasErrors(issues))
+}
+
+func TestValidateDatadogConfigProfile(t *testing.T) {
+	issues := validateDatadogConfig("datadog.yaml", []byte(`
+profile: low_resource
+`))
+	assert.Len(t, issues, 0)
+
+	issues = validateDatadogConfig("datadog.yaml", []byte(`
+profile: tiny
+`))
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "datadog.yaml:2: profile: unknown profile, the valid profiles are: high_throughput_statsd, low_resource", issues[0].String())
+}
+
+func TestValidateDatadogConfigSyntaxError(t *testing.T) {
+	issues := validateDatadogConfig("datadog.yaml", []byte(`
+api_key: foo
+  log_level: debug
+`))
+	assert.Len(t, issues, 1)
+	assert.Equal(t, 3, issues[0].Line)
+}
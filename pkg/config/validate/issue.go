@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package validate checks the configuration files of the agent, datadog.yaml and
+// the check configurations, before they are deployed.
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Issue represents a problem found in a configuration file.
+type Issue struct {
+	File    string
+	Line    int // 0 when the line is unknown
+	Key     string
+	Message string
+	// Warning is set for the deprecated options, they are still supported
+	Warning bool
+}
+
+// String returns the issue as `file:line: key: message`.
+func (i Issue) String() string {
+	location := i.File
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	if i.Key != "" {
+		return fmt.Sprintf("%s: %s: %s", location, i.Key, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", location, i.Message)
+}
+
+// HasErrors returns whether some issues are not warnings.
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if !issue.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// issues collects the issues of a file.
+type issues struct {
+	file   string
+	data   []byte
+	issues []Issue
+}
+
+// sorted returns the issues in the order of the lines of the file.
+func (i *issues) sorted() []Issue {
+	sort.SliceStable(i.issues, func(a, b int) bool {
+		return i.issues[a].Line < i.issues[b].Line
+	})
+	return i.issues
+}
+
+func (i *issues) error(path []string, format string, args ...interface{}) {
+	i.add(path, false, format, args...)
+}
+
+func (i *issues) warn(path []string, format string, args ...interface{}) {
+	i.add(path, true, format, args...)
+}
+
+func (i *issues) add(path []string, warning bool, format string, args ...interface{}) {
+	i.issues = append(i.issues, Issue{
+		File:    i.file,
+		Line:    keyLine(i.data, path),
+		Key:     strings.Join(path, "."),
+		Message: fmt.Sprintf(format, args...),
+		Warning: warning,
+	})
+}
+
+// yamlLinePattern matches the line of the errors of the YAML parser
+var yamlLinePattern = regexp.MustCompile(`line (\d+):`)
+
+// syntaxError returns the issue of a file that can not be parsed.
+func syntaxError(file string, err error) Issue {
+	issue := Issue{File: file, Message: fmt.Sprintf("invalid YAML: %s", err)}
+	if matches := yamlLinePattern.FindStringSubmatch(err.Error()); len(matches) == 2 {
+		issue.Line, _ = strconv.Atoi(matches[1])
+	}
+	return issue
+}
+
+// keyPattern matches the lines defining a key, including the first key of a list item
+var keyPattern = regexp.MustCompile(`^(\s*(?:-\s+)*)["']?([^\s"'#:-][^"':#]*?)["']?\s*:(?:\s|$)`)
+
+// keyLine returns the line number of the first key matching path, the list
+// items are not part of the path: `logs.path` matches the path of any logs
+// config. It returns 0 when the key is not found.
+func keyLine(data []byte, path []string) int {
+	type key struct {
+		indent int
+		name   string
+	}
+	stack := []key{}
+	for i, line := range strings.Split(string(data), "\n") {
+		matches := keyPattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		indent := len(matches[1])
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, key{indent: indent, name: matches[2]})
+		if len(stack) != len(path) {
+			continue
+		}
+		found := true
+		for j, k := range stack {
+			if !strings.EqualFold(k.name, path[j]) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package validate
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// checkType returns an error when value can not be converted to the type of
+// expected, the conversions are the ones done by viper: "true" is a valid
+// boolean, "5" is a valid integer and a string is a valid list.
+func checkType(value interface{}, expected interface{}) error {
+	if value == nil || expected == nil {
+		return nil
+	}
+	switch reflect.ValueOf(expected).Kind() {
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+			return nil
+		case string:
+			if _, err := strconv.ParseBool(v); err == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected a boolean, got %s", describe(value))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch v := value.(type) {
+		case int, int64, uint64:
+			return nil
+		case float64:
+			if v == math.Trunc(v) {
+				return nil
+			}
+		case string:
+			if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return nil
+			}
+			if _, isDuration := expected.(time.Duration); isDuration {
+				if _, err := time.ParseDuration(v); err == nil {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("expected an integer, got %s", describe(value))
+	case reflect.Float32, reflect.Float64:
+		switch v := value.(type) {
+		case int, int64, uint64, float64:
+			return nil
+		case string:
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected a number, got %s", describe(value))
+	case reflect.String:
+		switch value.(type) {
+		case map[interface{}]interface{}, []interface{}:
+			return fmt.Errorf("expected a string, got %s", describe(value))
+		}
+	case reflect.Slice:
+		switch value.(type) {
+		case []interface{}, string:
+			return nil
+		}
+		return fmt.Errorf("expected a list, got %s", describe(value))
+	case reflect.Map:
+		if _, ok := value.(map[interface{}]interface{}); !ok {
+			return fmt.Errorf("expected a section, got %s", describe(value))
+		}
+	}
+	return nil
+}
+
+// describe returns the type of a YAML value.
+func describe(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "an empty value"
+	case bool:
+		return fmt.Sprintf("the boolean %v", v)
+	case int, int64, uint64, float64:
+		return fmt.Sprintf("the number %v", v)
+	case string:
+		return fmt.Sprintf("the string %q", v)
+	case []interface{}:
+		return "a list"
+	case map[interface{}]interface{}:
+		return "a section"
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -14,6 +14,7 @@ import (
 	log "github.com/cihub/seelog"
 
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/intern"
 )
 
 // Schema of a dogstatsd packet: see http://docs.datadoghq.com
@@ -70,9 +71,9 @@ func parseTags(rawTags []byte, extractHost bool) ([]string, string) {
 	for {
 		tag, remainder = nextField(remainder, tagSeparator)
 		if extractHost && bytes.HasPrefix(tag, []byte("host:")) {
-			host = string(tag[5:])
+			host = intern.Bytes(tag[5:])
 		} else {
-			tagsList = append(tagsList, string(tag))
+			tagsList = append(tagsList, intern.Bytes(tag))
 		}
 
 		if remainder == nil {
@@ -277,7 +278,7 @@ func parseMetricMessage(message []byte, namespace string) (*metrics.MetricSample
 		}
 	}
 
-	metricName := string(rawName)
+	metricName := intern.Bytes(rawName)
 	if namespace != "" {
 		metricName = namespace + metricName
 	}
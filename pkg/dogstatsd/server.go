@@ -9,12 +9,17 @@ import (
 	"bytes"
 	"expvar"
 	"fmt"
+	"math"
+	"math/rand"
 	"net"
 	"runtime"
+	"sort"
 	"strings"
+	"sync/atomic"
 
 	log "github.com/cihub/seelog"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -32,26 +37,33 @@ type Server struct {
 	listeners    []listeners.StatsdListener
 	packetIn     chan *listeners.Packet
 	Statistics   *util.Stats
+	statsEnabled uint32
 	Started      bool
 	packetPool   *listeners.PacketPool
 	stopChan     chan bool
 	health       *health.Handle
 	metricPrefix string
+	// metricBlocklist holds the names of the metrics dropped, a map[string]bool
+	metricBlocklist atomic.Value
+	// dropRate holds the bits of the ratio of the metrics dropped to shed load
+	dropRate uint64
 }
 
 // NewServer returns a running Dogstatsd server
 func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.Event, serviceCheckOut chan<- metrics.ServiceCheck) (*Server, error) {
-	var stats *util.Stats
+	// the statistics are always set up so they can be enabled at runtime
+	buff := config.Datadog.GetInt("dogstatsd_stats_buffer")
+	stats, err := util.NewStats(uint32(buff))
+	if err != nil {
+		log.Errorf("Dogstatsd: unable to start statistics facilities")
+	}
+	var statsEnabled uint32
 	if config.Datadog.GetBool("dogstatsd_stats_enable") == true {
-		buff := config.Datadog.GetInt("dogstatsd_stats_buffer")
-		s, err := util.NewStats(uint32(buff))
-		if err != nil {
-			log.Errorf("Dogstatsd: unable to start statistics facilities")
-		}
-		stats = s
+		statsEnabled = 1
 	}
 
-	packetChannel := make(chan *listeners.Packet, 100)
+	queueSize := config.Datadog.GetInt("dogstatsd_queue_size")
+	packetChannel := make(chan *listeners.Packet, queueSize)
 	packetPool := listeners.NewPacketPool(config.Datadog.GetInt("dogstatsd_buffer_size"))
 	tmpListeners := make([]listeners.StatsdListener, 0, 2)
 
@@ -86,6 +98,7 @@ func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.E
 	s := &Server{
 		Started:      true,
 		Statistics:   stats,
+		statsEnabled: statsEnabled,
 		packetIn:     packetChannel,
 		listeners:    tmpListeners,
 		packetPool:   packetPool,
@@ -94,6 +107,8 @@ func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.E
 		metricPrefix: metricPrefix,
 	}
 
+	s.SetMetricBlocklist(config.Datadog.GetStringSlice("dogstatsd_metric_blocklist"))
+
 	forwardHost := config.Datadog.GetString("statsd_forward_host")
 	forwardPort := config.Datadog.GetInt("statsd_forward_port")
 
@@ -106,7 +121,7 @@ func NewServer(metricOut chan<- *metrics.MetricSample, eventOut chan<- metrics.E
 		if err != nil {
 			log.Warnf("Could not connect to statsd forward host : %s", err)
 		} else {
-			s.packetIn = make(chan *listeners.Packet, 100)
+			s.packetIn = make(chan *listeners.Packet, queueSize)
 			go s.forwarder(con, packetChannel)
 		}
 	}
@@ -166,7 +181,7 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 			if packet.Origin != listeners.NoOrigin {
 				var err error
 				log.Tracef("Dogstatsd receive from %s: %s", packet.Origin, packet.Contents)
-				originTags, err = tagger.Tag(packet.Origin, tagger.IsFullCardinality())
+				originTags, err = tagger.Tag(packet.Origin, tagger.DogstatsdCardinality)
 				if err != nil {
 					log.Errorf(err.Error())
 				}
@@ -181,7 +196,7 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 					break
 				}
 
-				if s.Statistics != nil {
+				if s.Statistics != nil && atomic.LoadUint32(&s.statsEnabled) == 1 {
 					s.Statistics.StatEvent(1)
 				}
 
@@ -196,6 +211,7 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 						serviceCheck.Tags = append(serviceCheck.Tags, originTags...)
 					}
 					dogstatsdExpvar.Add("ServiceCheckPackets", 1)
+					diagnostic.HandleServiceCheck(diagnostic.DogstatsdCheck, *serviceCheck)
 					serviceCheckOut <- *serviceCheck
 				} else if bytes.HasPrefix(message, []byte("_e")) {
 					event, err := parseEventMessage(message)
@@ -208,14 +224,23 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 						event.Tags = append(event.Tags, originTags...)
 					}
 					dogstatsdExpvar.Add("EventPackets", 1)
+					diagnostic.HandleEvent(diagnostic.DogstatsdCheck, *event)
 					eventOut <- *event
 				} else {
+					if s.shouldDrop() {
+						dogstatsdExpvar.Add("MetricDropped", 1)
+						continue
+					}
 					sample, err := parseMetricMessage(message, s.metricPrefix)
 					if err != nil {
 						log.Errorf("Dogstatsd: error parsing metrics: %s", err)
 						dogstatsdExpvar.Add("MetricParseErrors", 1)
 						continue
 					}
+					if s.metricBlocklist.Load().(map[string]bool)[sample.Name] {
+						dogstatsdExpvar.Add("MetricBlocklisted", 1)
+						continue
+					}
 					if len(originTags) > 0 {
 						sample.Tags = append(sample.Tags, originTags...)
 					}
@@ -229,6 +254,57 @@ func (s *Server) worker(metricOut chan<- *metrics.MetricSample, eventOut chan<-
 	}
 }
 
+// EnableStatistics enables or disables the statistics of the packets received
+func (s *Server) EnableStatistics(enabled bool) {
+	var value uint32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreUint32(&s.statsEnabled, value)
+}
+
+// StatisticsEnabled returns whether the statistics of the packets received are enabled
+func (s *Server) StatisticsEnabled() bool {
+	return atomic.LoadUint32(&s.statsEnabled) == 1
+}
+
+// SetMetricBlocklist sets the names of the metrics dropped
+func (s *Server) SetMetricBlocklist(names []string) {
+	blocklist := make(map[string]bool, len(names))
+	for _, name := range names {
+		blocklist[name] = true
+	}
+	s.metricBlocklist.Store(blocklist)
+}
+
+// MetricBlocklist returns the names of the metrics dropped, in order
+func (s *Server) MetricBlocklist() []string {
+	blocklist := s.metricBlocklist.Load().(map[string]bool)
+	names := make([]string, 0, len(blocklist))
+	for name := range blocklist {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetDropRate sets the ratio of the metrics dropped before being parsed, the
+// events and the service checks are never dropped
+func (s *Server) SetDropRate(rate float64) {
+	atomic.StoreUint64(&s.dropRate, math.Float64bits(rate))
+}
+
+// DropRate returns the ratio of the metrics dropped
+func (s *Server) DropRate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&s.dropRate))
+}
+
+// shouldDrop returns whether the next metric is dropped
+func (s *Server) shouldDrop() bool {
+	rate := s.DropRate()
+	return rate > 0 && rand.Float64() < rate
+}
+
 // Stop stops a running Dogstatsd server
 func (s *Server) Stop() {
 	close(s.stopChan)
@@ -200,6 +200,83 @@ func TestUPDReceive(t *testing.T) {
 	}
 }
 
+func TestMetricBlocklist(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+	config.Datadog.Set("dogstatsd_metric_blocklist", []string{"daemon.blocked"})
+	defer config.Datadog.Set("dogstatsd_metric_blocklist", []string{})
+
+	metricOut := make(chan *metrics.MetricSample)
+	s, err := NewServer(metricOut, nil, nil)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+	assert.Equal(t, []string{"daemon.blocked"}, s.MetricBlocklist())
+
+	url := fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_port"))
+	conn, err := net.Dial("udp", url)
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	conn.Write([]byte("daemon.blocked:666|g\ndaemon:1|g"))
+	select {
+	case res := <-metricOut:
+		assert.Equal(t, "daemon", res.Name)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+
+	s.SetMetricBlocklist([]string{"daemon"})
+	assert.Equal(t, []string{"daemon"}, s.MetricBlocklist())
+	conn.Write([]byte("daemon:1|g\ndaemon.blocked:666|g"))
+	select {
+	case res := <-metricOut:
+		assert.Equal(t, "daemon.blocked", res.Name)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+}
+
+func TestMetricDropRate(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+
+	metricOut := make(chan *metrics.MetricSample)
+	eventOut := make(chan metrics.Event)
+	s, err := NewServer(metricOut, eventOut, nil)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+	assert.Equal(t, 0.0, s.DropRate())
+
+	url := fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_port"))
+	conn, err := net.Dial("udp", url)
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	// the metrics are all dropped but the events go through
+	s.SetDropRate(1)
+	assert.Equal(t, 1.0, s.DropRate())
+	conn.Write([]byte("daemon:666|g\n_e{10,10}:test title|test\\ntext"))
+	select {
+	case res := <-eventOut:
+		assert.Equal(t, "test title", res.Title)
+	case <-metricOut:
+		assert.FailNow(t, "The metric should have been dropped")
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+
+	s.SetDropRate(0)
+	conn.Write([]byte("daemon:666|g"))
+	select {
+	case res := <-metricOut:
+		assert.Equal(t, "daemon", res.Name)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+}
+
 func TestUDPForward(t *testing.T) {
 	fport, err := getAvailableUDPPort()
 	require.NoError(t, err)
@@ -19,11 +19,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/diagnose"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/scrubber"
 
 	log "github.com/cihub/seelog"
 	"github.com/mholt/archiver"
@@ -90,6 +93,16 @@ func createArchive(zipFilePath string, local bool, confSearchPaths SearchPaths,
 		if err != nil {
 			log.Errorf("Could not zip config check: %s", err)
 		}
+
+		err = zipCheckResults(tempDir, hostname)
+		if err != nil {
+			log.Errorf("Could not zip check results: %s", err)
+		}
+
+		err = zipRecentLogs(tempDir, hostname)
+		if err != nil {
+			log.Errorf("Could not zip recent logs: %s", err)
+		}
 	}
 
 	err = zipConfigFiles(tempDir, hostname, confSearchPaths)
@@ -117,6 +130,11 @@ func createArchive(zipFilePath string, local bool, confSearchPaths SearchPaths,
 		log.Errorf("Could not zip health check: %s", err)
 	}
 
+	err = zipAutoProfiles(tempDir, hostname)
+	if err != nil {
+		log.Errorf("Could not zip the captured profiles: %s", err)
+	}
+
 	if config.IsContainerized() {
 		err = zipDockerSelfInspect(tempDir, hostname)
 		if err != nil {
@@ -155,7 +173,7 @@ func zipStatusFile(tempDir, hostname string) error {
 	}
 
 	// Clean it up
-	cleaned, err := credentialsCleanerBytes(s)
+	cleaned, err := scrubber.ScrubBytes(s)
 	if err != nil {
 		return err
 	}
@@ -212,7 +230,7 @@ func zipExpVar(tempDir, hostname string) error {
 			return err
 		}
 
-		cleanedYAML, err := credentialsCleanerBytes(yamlValue)
+		cleanedYAML, err := scrubber.ScrubBytes(yamlValue)
 		if err != nil {
 			return err
 		}
@@ -239,7 +257,7 @@ func zipConfigFiles(tempDir, hostname string, confSearchPaths SearchPaths) error
 		return err
 	}
 	// zip up the actual config
-	cleaned, err := credentialsCleanerBytes(c)
+	cleaned, err := scrubber.ScrubBytes(c)
 	if err != nil {
 		return err
 	}
@@ -268,7 +286,7 @@ func zipConfigFiles(tempDir, hostname string, confSearchPaths SearchPaths) error
 		// Check if the file exists
 		_, err := os.Stat(filePath)
 		if err == nil {
-			cleaned, err = credentialsCleanerFile(filePath)
+			cleaned, err = scrubber.ScrubFile(filePath)
 			if err != nil {
 				return err
 			}
@@ -285,6 +303,30 @@ func zipConfigFiles(tempDir, hostname string, confSearchPaths SearchPaths) error
 				return err
 			}
 		}
+
+		// zip up the fragments merged over it
+		fragments, err := config.Fragments(filePath)
+		if err != nil {
+			return err
+		}
+		for _, fragment := range fragments {
+			cleaned, err = scrubber.ScrubFile(fragment)
+			if err != nil {
+				return err
+			}
+
+			f = filepath.Join(tempDir, hostname, "etc", config.FragmentsDirName, filepath.Base(fragment))
+
+			err = ensureParentDirsExist(f)
+			if err != nil {
+				return err
+			}
+
+			err = ioutil.WriteFile(f, cleaned, os.ModePerm)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return err
@@ -326,7 +368,7 @@ func zipConfigCheck(tempDir, hostname string) error {
 		return err
 	}
 
-	data, err := credentialsCleanerBytes(b.Bytes())
+	data, err := scrubber.ScrubBytes(b.Bytes())
 	if err != nil {
 		return err
 	}
@@ -340,7 +382,7 @@ func zipConfigCheck(tempDir, hostname string) error {
 }
 
 func zipHealth(tempDir, hostname string) error {
-	s := health.GetStatus()
+	s := health.GetReady()
 	sort.Strings(s.Healthy)
 	sort.Strings(s.Unhealthy)
 
@@ -364,6 +406,71 @@ func zipHealth(tempDir, hostname string) error {
 	return nil
 }
 
+// zipCheckResults adds the results of the last runs of every check instance
+func zipCheckResults(tempDir, hostname string) error {
+	results := map[string][]check.RunResult{}
+	for id, r := range runner.GetRecentCheckResults() {
+		results[string(id)] = r
+	}
+
+	yamlValue, err := yaml.Marshal(results)
+	if err != nil {
+		return err
+	}
+
+	// the errors of the checks can hold credentials
+	cleaned, err := scrubber.ScrubBytes(yamlValue)
+	if err != nil {
+		return err
+	}
+
+	f := filepath.Join(tempDir, hostname, "check_results.yaml")
+
+	err = ensureParentDirsExist(f)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(f, cleaned, os.ModePerm)
+}
+
+// zipAutoProfiles adds the profiles captured when the usage of the agent
+// spiked, they are kept in a directory per capture
+func zipAutoProfiles(tempDir, hostname string) error {
+	profilesDir := config.Datadog.GetString("auto_profiling.dir")
+	if _, err := os.Stat(profilesDir); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(profilesDir, func(src string, f os.FileInfo, err error) error {
+		if f == nil || f.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(profilesDir, src)
+		if err != nil {
+			return err
+		}
+		return util.CopyFileAll(src, filepath.Join(tempDir, hostname, "profiles", rel))
+	})
+}
+
+// zipRecentLogs adds the last lines logged, at debug level, the logger
+// already scrubbed them
+func zipRecentLogs(tempDir, hostname string) error {
+	lines := config.RecentLogs()
+	if len(lines) == 0 {
+		return nil
+	}
+
+	f := filepath.Join(tempDir, hostname, "logs", "recent-debug.log")
+
+	err := ensureParentDirsExist(f)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(f, []byte(strings.Join(lines, "\n")+"\n"), os.ModePerm)
+}
+
 func walkConfigFilePaths(tempDir, hostname string, confSearchPaths SearchPaths) error {
 	for prefix, filePath := range confSearchPaths {
 		err := filepath.Walk(filePath, func(src string, f os.FileInfo, err error) error {
@@ -379,7 +486,7 @@ func walkConfigFilePaths(tempDir, hostname string, confSearchPaths SearchPaths)
 			}
 
 			if getFirstSuffix(f.Name()) == ".yaml" || filepath.Ext(f.Name()) == ".yaml" {
-				cleaned, err := credentialsCleanerFile(src)
+				cleaned, err := scrubber.ScrubFile(src)
 				if err != nil {
 					return err
 				}
@@ -20,6 +20,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/scrubber"
 )
 
 // CreateDCAArchive packages up the files
@@ -120,7 +121,7 @@ func zipDCAStatusFile(tempDir, hostname string) error {
 	}
 
 	// Clean it up
-	cleaned, err := credentialsCleanerBytes(s)
+	cleaned, err := scrubber.ScrubBytes(s)
 	if err != nil {
 		log.Infof("Error redacting the log files: %q", err)
 		return err
@@ -153,7 +154,7 @@ func zipMetadataMap(tempDir, hostname string) error {
 		return err
 	}
 	// Clean it up
-	cleanedMetaBytes, err := credentialsCleanerBytes(metaBytes)
+	cleanedMetaBytes, err := scrubber.ScrubBytes(metaBytes)
 	if err != nil {
 		log.Infof("Error redacting the log files: %q", err)
 		return err
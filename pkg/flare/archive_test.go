@@ -87,3 +87,28 @@ func TestZipConfigCheck(t *testing.T) {
 
 	assert.NotContains(t, string(content), "MySecurePass")
 }
+
+func TestZipAutoProfiles(t *testing.T) {
+	profilesDir, err := ioutil.TempDir("", "TestZipAutoProfiles")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(profilesDir)
+	config.Datadog.Set("auto_profiling.dir", profilesDir)
+	defer config.Datadog.Set("auto_profiling.dir", "")
+
+	capture := filepath.Join(profilesDir, "20180601-101500")
+	os.MkdirAll(capture, 0700)
+	ioutil.WriteFile(filepath.Join(capture, "reason.txt"), []byte("the RSS is 600 MB"), 0600)
+
+	dir, err := ioutil.TempDir("", "TestZipAutoProfiles")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, zipAutoProfiles(dir, "host"))
+	content, err := ioutil.ReadFile(filepath.Join(dir, "host", "profiles", "20180601-101500", "reason.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "the RSS is 600 MB", string(content))
+}
@@ -13,12 +13,13 @@ import (
 
 	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
-	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/util/scrubber"
 	"github.com/fatih/color"
 )
 
 // ConfigCheckURL contains the Agent API endpoint URL exposing the loaded checks
-var ConfigCheckURL = fmt.Sprintf("https://localhost:%v/agent/config-check", config.Datadog.GetInt("cmd_port"))
+var ConfigCheckURL = util.IPCURL("agent/config-check")
 
 // GetConfigCheck dump all loaded configurations to the writer
 func GetConfigCheck(w io.Writer, withDebug bool) error {
@@ -57,36 +58,7 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 	}
 
 	for _, c := range cr.Configs {
-		fmt.Fprintln(w, fmt.Sprintf("\n=== %s check ===", color.GreenString(c.Name)))
-		if len(c.Provider) > 0 {
-			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Source"), color.CyanString(c.Provider)))
-		} else {
-			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Source"), color.RedString("Unknown provider")))
-		}
-		for i, inst := range c.Instances {
-			fmt.Fprintln(w, fmt.Sprintf("%s %s:", color.BlueString("Instance"), color.CyanString(strconv.Itoa(i+1))))
-			fmt.Fprint(w, fmt.Sprintf("%s", inst))
-			fmt.Fprintln(w, "~")
-		}
-		if len(c.InitConfig) > 0 {
-			fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Init Config")))
-			fmt.Fprintln(w, string(c.InitConfig))
-		}
-		if len(c.MetricConfig) > 0 {
-			fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Metric Config")))
-			fmt.Fprintln(w, string(c.MetricConfig))
-		}
-		if len(c.LogsConfig) > 0 {
-			fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Log Config")))
-			fmt.Fprintln(w, string(c.LogsConfig))
-		}
-		if len(c.ADIdentifiers) > 0 {
-			fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Auto-discovery IDs")))
-			for _, id := range c.ADIdentifiers {
-				fmt.Fprintln(w, fmt.Sprintf("* %s", color.CyanString(id)))
-			}
-		}
-		fmt.Fprintln(w, "===")
+		printConfig(w, c, cr.ScheduledChecks[c.Digest()], cr.Services[c.Digest()])
 	}
 
 	if withDebug {
@@ -111,3 +83,60 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 
 	return nil
 }
+
+// printConfig prints a loaded config, the service it was resolved for when it
+// is a template, and the checks scheduled for it. Its credentials are redacted.
+func printConfig(w io.Writer, c integration.Config, scheduledChecks []string, service string) {
+	fmt.Fprintln(w, fmt.Sprintf("\n=== %s check ===", color.GreenString(c.Name)))
+	if len(c.Provider) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Source"), color.CyanString(c.Provider)))
+	} else {
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Source"), color.RedString("Unknown provider")))
+	}
+	if len(service) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Resolved for service"), color.CyanString(service)))
+	}
+	fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Config digest"), c.Digest()))
+	if len(scheduledChecks) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Scheduled checks")))
+		for _, id := range scheduledChecks {
+			fmt.Fprintln(w, fmt.Sprintf("* %s", color.CyanString(id)))
+		}
+	} else {
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Scheduled checks"), color.RedString("none, see the configuration errors and the logs of the agent")))
+	}
+	for i, inst := range c.Instances {
+		fmt.Fprintln(w, fmt.Sprintf("%s %s:", color.BlueString("Instance"), color.CyanString(strconv.Itoa(i+1))))
+		fmt.Fprint(w, fmt.Sprintf("%s", redact(inst)))
+		fmt.Fprintln(w, "~")
+	}
+	if len(c.InitConfig) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Init Config")))
+		fmt.Fprintln(w, string(redact(c.InitConfig)))
+	}
+	if len(c.MetricConfig) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Metric Config")))
+		fmt.Fprintln(w, string(c.MetricConfig))
+	}
+	if len(c.LogsConfig) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Log Config")))
+		fmt.Fprintln(w, string(c.LogsConfig))
+	}
+	if len(c.ADIdentifiers) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Auto-discovery IDs")))
+		for _, id := range c.ADIdentifiers {
+			fmt.Fprintln(w, fmt.Sprintf("* %s", color.CyanString(id)))
+		}
+	}
+	fmt.Fprintln(w, "===")
+}
+
+// redact strips the credentials of a YAML configuration, the template variables
+// resolved can contain some
+func redact(data integration.Data) []byte {
+	cleaned, err := scrubber.ScrubBytes(data)
+	if err != nil {
+		return []byte("(could not redact the credentials of this configuration)\n")
+	}
+	return cleaned
+}
@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package flare
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestPrintConfig(t *testing.T) {
+	color.NoColor = true
+	c := integration.Config{
+		Name:          "redisdb",
+		Provider:      "Docker",
+		Instances:     []integration.Data{integration.Data("host: 172.17.0.2\npassword: secret\n")},
+		ADIdentifiers: []string{"redis"},
+	}
+
+	var w bytes.Buffer
+	printConfig(&w, c, []string{"redisdb:1234"}, "docker://abcd")
+	output := w.String()
+	assert.Contains(t, output, "=== redisdb check ===")
+	assert.Contains(t, output, "Source: Docker")
+	assert.Contains(t, output, "Resolved for service: docker://abcd")
+	assert.Contains(t, output, "Config digest: "+c.Digest())
+	assert.Contains(t, output, "* redisdb:1234")
+	assert.Contains(t, output, "host: 172.17.0.2")
+	assert.Contains(t, output, "password: ********")
+	assert.NotContains(t, output, "secret")
+}
+
+func TestPrintConfigNotScheduled(t *testing.T) {
+	color.NoColor = true
+	c := integration.Config{
+		Name:      "redisdb",
+		Provider:  "File",
+		Instances: []integration.Data{integration.Data("host: localhost\n")},
+	}
+
+	var w bytes.Buffer
+	printConfig(&w, c, nil, "")
+	output := w.String()
+	assert.Contains(t, output, "Scheduled checks: none")
+	assert.NotContains(t, output, "Resolved for service")
+}
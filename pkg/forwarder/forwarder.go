@@ -59,6 +59,9 @@ const (
 	sketchSeriesEndpoint  = "/api/beta/sketches"
 	hostMetadataEndpoint  = "/api/v2/host_metadata"
 	metadataEndpoint      = "/api/v2/metadata"
+	orchestratorEndpoint  = "/api/v2/orchestrator"
+	remoteConfigEndpoint  = "/api/v1/remote_config"
+	remoteStateEndpoint   = "/api/v1/remote_config/state"
 
 	apiHTTPHeaderKey     = "DD-Api-Key"
 	versionHTTPHeaderKey = "DD-Agent-Version"
@@ -81,6 +84,9 @@ type Forwarder interface {
 	SubmitSketchSeries(payload Payloads, extra http.Header) error
 	SubmitHostMetadata(payload Payloads, extra http.Header) error
 	SubmitMetadata(payload Payloads, extra http.Header) error
+	SubmitOrchestratorPayload(payload Payloads, extra http.Header) error
+	SubmitRemoteConfigState(payload Payloads, extra http.Header) error
+	FetchRemoteConfig(version uint64) ([]byte, error)
 }
 
 // DefaultForwarder is the default implementation of the Forwarder.
@@ -259,6 +265,20 @@ func (f *DefaultForwarder) SubmitMetadata(payload Payloads, extra http.Header) e
 	return f.sendHTTPTransactions(transactions)
 }
 
+// SubmitOrchestratorPayload will send a cluster resources payload to Datadog backend.
+func (f *DefaultForwarder) SubmitOrchestratorPayload(payload Payloads, extra http.Header) error {
+	transactions := f.createHTTPTransactions(orchestratorEndpoint, payload, false, extra)
+	transactionsExpvar.Add("Orchestrator", 1)
+	return f.sendHTTPTransactions(transactions)
+}
+
+// SubmitRemoteConfigState will send the state of the remote configuration applied to Datadog backend.
+func (f *DefaultForwarder) SubmitRemoteConfigState(payload Payloads, extra http.Header) error {
+	transactions := f.createHTTPTransactions(remoteStateEndpoint, payload, false, extra)
+	transactionsExpvar.Add("RemoteConfigState", 1)
+	return f.sendHTTPTransactions(transactions)
+}
+
 // SubmitV1Series will send timeserie to v1 endpoint (this will be remove once
 // the backend handles v2 endpoints).
 func (f *DefaultForwarder) SubmitV1Series(payload Payloads, extra http.Header) error {
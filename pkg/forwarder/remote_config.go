@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package forwarder
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+var fetchRemoteConfigTimeout = 10 * time.Second
+
+// maxRemoteConfigSize is the size above which a remote configuration is refused
+const maxRemoteConfigSize = 1024 * 1024
+
+// FetchRemoteConfig returns the remote configuration of the agent when the backend
+// has a more recent one than currentVersion, nil otherwise. Unlike the payloads, it is
+// fetched synchronously from the main endpoint, with the main api key.
+func (f *DefaultForwarder) FetchRemoteConfig(currentVersion uint64) ([]byte, error) {
+	url := fmt.Sprintf("%s%s?version=%d", config.Datadog.GetString("dd_url"), remoteConfigEndpoint, currentVersion)
+
+	client := &http.Client{
+		Transport: util.CreateHTTPTransport(),
+		Timeout:   fetchRemoteConfigTimeout,
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(apiHTTPHeaderKey, config.Datadog.GetString("api_key"))
+	req.Header.Set(versionHTTPHeaderKey, version.AgentVersion)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	transactionsExpvar.Add("RemoteConfig", 1)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxRemoteConfigSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxRemoteConfigSize {
+			return nil, fmt.Errorf("the remote configuration is larger than %d bytes", maxRemoteConfigSize)
+		}
+		return data, nil
+	case http.StatusNotModified, http.StatusNoContent:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected response code from the remote configuration endpoint: %v", resp.StatusCode)
+}
@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package forwarder
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/status/sections"
+)
+
+// statusProvider is the forwarder section of the status: the transactions by
+// endpoint, the retry queue and the status of the API keys.
+type statusProvider struct{}
+
+func (statusProvider) Name() string { return "forwarder" }
+
+func (statusProvider) Status() (interface{}, error) {
+	return sections.ExpvarStatus("forwarder")
+}
+
+func init() {
+	sections.Register(statusProvider{})
+}
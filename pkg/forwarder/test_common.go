@@ -96,3 +96,20 @@ func (tf *MockedForwarder) SubmitHostMetadata(payload Payloads, extra http.Heade
 func (tf *MockedForwarder) SubmitMetadata(payload Payloads, extra http.Header) error {
 	return tf.Called(payload, extra).Error(0)
 }
+
+// SubmitOrchestratorPayload updates the internal mock struct
+func (tf *MockedForwarder) SubmitOrchestratorPayload(payload Payloads, extra http.Header) error {
+	return tf.Called(payload, extra).Error(0)
+}
+
+// SubmitRemoteConfigState updates the internal mock struct
+func (tf *MockedForwarder) SubmitRemoteConfigState(payload Payloads, extra http.Header) error {
+	return tf.Called(payload, extra).Error(0)
+}
+
+// FetchRemoteConfig updates the internal mock struct
+func (tf *MockedForwarder) FetchRemoteConfig(version uint64) ([]byte, error) {
+	args := tf.Called(version)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
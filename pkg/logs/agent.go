@@ -10,8 +10,10 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/container"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/journald"
+	"github.com/DataDog/datadog-agent/pkg/logs/input/kubernetes"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/listener"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/tailer"
+	"github.com/DataDog/datadog-agent/pkg/logs/input/windowsevent"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
@@ -31,11 +33,13 @@ type Agent struct {
 	filesScanner      *tailer.Scanner
 	networkListener   *listener.Listener
 	journaldLauncher  *journald.Launcher
+	podsLauncher      *kubernetes.Launcher
+	eventLauncher     *windowsevent.Launcher
 	pipelineProvider  pipeline.Provider
 }
 
 // NewAgent returns a new Agent
-func NewAgent(sources *config.LogSources) *Agent {
+func NewAgent(sources *config.LogSources, processingRules []config.LogsProcessingRule) *Agent {
 	// setup the auditor
 	messageChan := make(chan message.Message, config.ChanSize)
 	auditor := auditor.New(messageChan, config.LogsAgent.GetString("logs_config.run_path"))
@@ -46,19 +50,34 @@ func NewAgent(sources *config.LogSources) *Agent {
 		config.LogsAgent.GetInt("logs_config.dd_port"),
 		config.LogsAgent.GetBool("logs_config.dev_mode_no_ssl"),
 	)
-	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, connectionManager, messageChan)
+	var httpDestination *sender.HTTPDestination
+	if config.LogsAgent.GetBool("logs_config.use_http") {
+		httpDestination = sender.NewHTTPDestination(
+			config.LogsAgent.GetString("logs_config.http_dd_url"),
+			config.LogsAgent.GetInt("logs_config.http_dd_port"),
+			!config.LogsAgent.GetBool("logs_config.dev_mode_no_ssl"),
+			config.LogsAgent.GetString("api_key"),
+			config.LogsAgent.GetBool("logs_config.use_compression"),
+			config.LogsAgent.GetInt("logs_config.compression_level"),
+		)
+	}
+	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, connectionManager, httpDestination, processingRules, messageChan)
 
 	// setup the collectors
 	containersScanner := container.New(sources.GetValidSources(), pipelineProvider, auditor)
 	networkListeners := listener.New(sources.GetValidSources(), pipelineProvider)
-	filesScanner := tailer.New(sources.GetValidSources(), config.LogsAgent.GetInt("logs_config.open_files_limit"), pipelineProvider, auditor, tailer.DefaultSleepDuration)
+	filesScanner := tailer.New(sources, config.LogsAgent.GetInt("logs_config.open_files_limit"), pipelineProvider, auditor, tailer.DefaultSleepDuration)
 	journaldLauncher := journald.New(sources.GetValidSources(), pipelineProvider, auditor)
+	podsLauncher := kubernetes.New(sources)
+	eventLauncher := windowsevent.New(sources.GetValidSources(), pipelineProvider)
 
 	return &Agent{
 		auditor:           auditor,
 		containersScanner: containersScanner,
 		filesScanner:      filesScanner,
 		journaldLauncher:  journaldLauncher,
+		podsLauncher:      podsLauncher,
+		eventLauncher:     eventLauncher,
 		networkListener:   networkListeners,
 		pipelineProvider:  pipelineProvider,
 	}
@@ -74,6 +93,8 @@ func (a *Agent) Start() {
 		a.networkListener,
 		a.containersScanner,
 		a.journaldLauncher,
+		a.podsLauncher,
+		a.eventLauncher,
 	)
 }
 
@@ -81,11 +102,15 @@ func (a *Agent) Start() {
 // in the right order to prevent data loss
 func (a *Agent) Stop() {
 	stopper := restart.NewSerialStopper(
+		// the pods launcher adds and removes sources to the files scanner,
+		// it must be stopped first.
+		a.podsLauncher,
 		restart.NewParallelStopper(
 			a.filesScanner,
 			a.networkListener,
 			a.containersScanner,
 			a.journaldLauncher,
+			a.eventLauncher,
 		),
 		a.pipelineProvider,
 		a.auditor,
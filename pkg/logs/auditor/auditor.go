@@ -16,6 +16,7 @@ import (
 	log "github.com/cihub/seelog"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
 )
 
 const defaultFlushPeriod = 1 * time.Second
@@ -46,6 +47,7 @@ type Auditor struct {
 	mu           sync.Mutex
 	entryTTL     time.Duration
 	done         chan struct{}
+	health       *health.Handle
 }
 
 // New returns an initialized Auditor
@@ -62,6 +64,7 @@ func New(inputChan chan message.Message, runPath string) *Auditor {
 func (a *Auditor) Start() {
 	a.registry = a.recoverRegistry()
 	a.cleanupRegistry()
+	a.health = health.Register("logs-agent")
 	go a.run()
 }
 
@@ -95,11 +98,13 @@ func (a *Auditor) run() {
 		// clean the context
 		cleanUpTicker.Stop()
 		flushTicker.Stop()
+		a.health.Deregister()
 		a.done <- struct{}{}
 	}()
 
 	for {
 		select {
+		case <-a.health.C:
 		case msg, isOpen := <-a.inputChan:
 			if !isOpen {
 				// inputChan has been closed, no need to update the registry anymore
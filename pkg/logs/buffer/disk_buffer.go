@@ -0,0 +1,479 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package buffer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const (
+	// segmentsCount is the number of files the buffer is split in, the oldest
+	// one is dropped when the buffer is full
+	segmentsCount = 10
+	// recordHeaderSize is the size of the length prefixing each record
+	recordHeaderSize = 4
+	// defaultSendTimeout is how long a message waits for the outputChan before
+	// it is written to the disk
+	defaultSendTimeout = 100 * time.Millisecond
+	// defaultFlushTimeout is how long Stop waits for the buffer to be flushed
+	defaultFlushTimeout = 5 * time.Second
+)
+
+var (
+	// the messages written to and dropped from the disk buffers
+	bufferExpvar    = expvar.NewMap("logs-disk-buffer")
+	spilledMessages = expvar.Int{}
+	droppedMessages = expvar.Int{}
+)
+
+func init() {
+	bufferExpvar.Set("SpilledMessages", &spilledMessages)
+	bufferExpvar.Set("DroppedMessages", &droppedMessages)
+}
+
+// bufferSource is the source of the messages read from the disk, they are already
+// processed and only their content and their offset are used from now on.
+var bufferSource = config.NewLogSource("disk_buffer", &config.LogsConfig{})
+
+// record represents a message written to the disk.
+type record struct {
+	Content    []byte `json:"content"`
+	Status     string `json:"status"`
+	Identifier string `json:"identifier"`
+	Offset     string `json:"offset"`
+	SpilledAt  int64  `json:"spilled_at"`
+}
+
+// segment represents a file of the buffer, records is the number of
+// records not read yet.
+type segment struct {
+	path    string
+	size    int64
+	records int
+}
+
+// A DiskBuffer forwards the messages from an inputChan to an outputChan, and
+// writes them to the disk while the outputChan is full, for instance during an
+// outage of the intake. The messages written are forwarded in order when the
+// outputChan is available again, the oldest ones are dropped when the buffer
+// exceeds maxSize or when they are older than maxAge.
+// The messages left on the disk when the buffer stops are sent after it starts
+// again.
+type DiskBuffer struct {
+	inputChan    chan message.Message
+	outputChan   chan message.Message
+	path         string
+	maxSize      int64
+	maxAge       time.Duration
+	segmentSize  int64
+	sendTimeout  time.Duration
+	flushTimeout time.Duration
+
+	segments []*segment
+	size     int64
+	sequence int
+	writer   *os.File
+	reader   *os.File
+	buf      *bufio.Reader
+	// next is the message read from the oldest segment to forward, nextRecord
+	// its record
+	next       message.Message
+	nextRecord *record
+	stop       chan struct{}
+	done       chan struct{}
+	// now is mocked in the tests
+	now func() time.Time
+}
+
+// New returns an initialized DiskBuffer writing in the directory path.
+func New(inputChan, outputChan chan message.Message, path string, maxSize int64, maxAge time.Duration) *DiskBuffer {
+	return &DiskBuffer{
+		inputChan:    inputChan,
+		outputChan:   outputChan,
+		path:         path,
+		maxSize:      maxSize,
+		maxAge:       maxAge,
+		segmentSize:  maxSize / segmentsCount,
+		sendTimeout:  defaultSendTimeout,
+		flushTimeout: defaultFlushTimeout,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+		now:          time.Now,
+	}
+}
+
+// Start loads the messages left on the disk and starts forwarding the messages,
+// the ones of the disk first.
+func (b *DiskBuffer) Start() {
+	if err := os.MkdirAll(b.path, 0755); err != nil {
+		log.Warnf("Could not create the disk buffer %s: %s", b.path, err)
+	}
+	b.loadSegments()
+	go b.run()
+}
+
+// Stop stops the DiskBuffer,
+// this call blocks until inputChan and the messages written to the disk are flushed,
+// or until flushTimeout: the messages not flushed are then left on the disk.
+func (b *DiskBuffer) Stop() {
+	close(b.inputChan)
+	select {
+	case <-b.done:
+	case <-time.After(b.flushTimeout):
+		log.Warnf("Could not flush the disk buffer %s in %s, its messages will be sent after the next start", b.path, b.flushTimeout)
+		close(b.stop)
+		<-b.done
+	}
+}
+
+// run forwards the messages, the new messages are written to the disk as long
+// as older ones are pending to keep them in order.
+func (b *DiskBuffer) run() {
+	defer func() {
+		b.closeWriter()
+		b.compactOldestSegment()
+		b.closeFiles()
+		b.done <- struct{}{}
+	}()
+	inputChan := b.inputChan
+	for {
+		if b.next == nil && len(b.segments) > 0 {
+			b.next = b.readNext()
+		}
+		if b.next == nil {
+			if inputChan == nil {
+				// the buffer is stopped and flushed
+				return
+			}
+			msg, isOpen := <-inputChan
+			if !isOpen {
+				return
+			}
+			b.send(msg)
+			continue
+		}
+		select {
+		case msg, isOpen := <-inputChan:
+			if !isOpen {
+				// flush the pending messages, a nil chan is never ready
+				inputChan = nil
+				continue
+			}
+			b.spill(msg)
+		case b.outputChan <- b.next:
+			b.next = nil
+			b.nextRecord = nil
+		case <-b.stop:
+			// the flush timed out, the pending messages are left on the disk
+			if inputChan != nil {
+				for msg := range inputChan {
+					b.spill(msg)
+				}
+			}
+			return
+		}
+	}
+}
+
+// send forwards a message to the outputChan, it is written to the disk when the
+// outputChan stays full for sendTimeout.
+func (b *DiskBuffer) send(msg message.Message) {
+	select {
+	case b.outputChan <- msg:
+		return
+	default:
+	}
+	timer := time.NewTimer(b.sendTimeout)
+	defer timer.Stop()
+	select {
+	case b.outputChan <- msg:
+	case <-timer.C:
+		b.spill(msg)
+	}
+}
+
+// spill writes a message to the disk, the oldest segments are dropped to keep
+// the buffer under maxSize.
+func (b *DiskBuffer) spill(msg message.Message) {
+	frame, err := encode(&record{
+		Content:    msg.Content(),
+		Status:     msg.GetStatus(),
+		Identifier: msg.GetOrigin().Identifier,
+		Offset:     msg.GetOrigin().Offset,
+		SpilledAt:  b.now().UnixNano(),
+	})
+	if err != nil {
+		b.drop(1, err)
+		return
+	}
+
+	for len(b.segments) > 0 && b.size+int64(len(frame)) > b.maxSize {
+		b.dropOldestSegment()
+	}
+	if b.writer == nil || b.segments[len(b.segments)-1].size >= b.segmentSize {
+		if err := b.newSegment(); err != nil {
+			b.drop(1, err)
+			return
+		}
+	}
+	current := b.segments[len(b.segments)-1]
+	n, err := b.writer.Write(frame)
+	current.size += int64(n)
+	b.size += int64(n)
+	if err != nil {
+		// the partial record is not counted so it is never read, the next
+		// records are written to a new segment
+		b.drop(1, err)
+		b.closeWriter()
+		return
+	}
+	current.records++
+	spilledMessages.Add(1)
+}
+
+// encode returns a record prefixed with its length.
+func encode(rec *record) ([]byte, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, recordHeaderSize+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[recordHeaderSize:], data)
+	return frame, nil
+}
+
+// newSegment creates the file the next records are written to.
+func (b *DiskBuffer) newSegment() error {
+	b.closeWriter()
+	b.sequence++
+	path := filepath.Join(b.path, fmt.Sprintf("%010d.buffer", b.sequence))
+	writer, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	b.writer = writer
+	b.segments = append(b.segments, &segment{path: path})
+	return nil
+}
+
+// loadSegments loads the segments left on the disk by the previous run, in order.
+func (b *DiskBuffer) loadSegments() {
+	// the names of the segments are zero-padded, Glob sorts them
+	paths, err := filepath.Glob(filepath.Join(b.path, "*.buffer"))
+	if err != nil {
+		log.Warnf("Could not list the disk buffer %s: %s", b.path, err)
+		return
+	}
+	for _, path := range paths {
+		segment, err := loadSegment(path)
+		if err != nil || segment.records == 0 {
+			if err != nil {
+				log.Warnf("Could not read the disk buffer file %s: %s", path, err)
+			}
+			os.Remove(path)
+			continue
+		}
+		b.segments = append(b.segments, segment)
+		b.size += segment.size
+		sequence, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(path), ".buffer"))
+		if err == nil && sequence > b.sequence {
+			b.sequence = sequence
+		}
+	}
+	for len(b.segments) > 0 && b.size > b.maxSize {
+		b.dropOldestSegment()
+	}
+}
+
+// loadSegment counts the complete records of a segment file, a partial record
+// written when the agent stopped is ignored.
+func loadSegment(path string) (*segment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	segment := &segment{path: path, size: info.Size()}
+	reader := bufio.NewReader(file)
+	header := make([]byte, recordHeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			break
+		}
+		length := int(binary.BigEndian.Uint32(header))
+		if discarded, _ := reader.Discard(length); discarded < length {
+			break
+		}
+		segment.records++
+	}
+	return segment, nil
+}
+
+// compactOldestSegment removes the records already read from the oldest segment,
+// except the one of the message not forwarded yet, so that they are not sent
+// again after the next start.
+func (b *DiskBuffer) compactOldestSegment() {
+	if b.reader == nil {
+		if b.next != nil {
+			// its segment was dropped to keep the buffer under maxSize
+			b.drop(1, fmt.Errorf("the disk buffer exceeds %d bytes", b.maxSize))
+		}
+		return
+	}
+	oldest := b.segments[0]
+	tmpPath := oldest.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err == nil {
+		if b.nextRecord != nil {
+			var frame []byte
+			if frame, err = encode(b.nextRecord); err == nil {
+				_, err = tmp.Write(frame)
+			}
+		}
+		if err == nil {
+			_, err = io.Copy(tmp, b.buf)
+		}
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	b.reader.Close()
+	b.reader = nil
+	b.buf = nil
+	if err == nil {
+		err = os.Rename(tmpPath, oldest.path)
+	}
+	if err != nil {
+		log.Warnf("Could not compact the disk buffer file %s, its messages may be sent again: %s", oldest.path, err)
+		os.Remove(tmpPath)
+	}
+}
+
+// readNext returns the oldest message of the disk not older than maxAge,
+// nil if there is none.
+func (b *DiskBuffer) readNext() message.Message {
+	b.nextRecord = nil
+	for len(b.segments) > 0 {
+		if b.segments[0].records <= 0 {
+			// all its records were read
+			b.removeOldestSegment()
+			continue
+		}
+		rec, err := b.readRecord()
+		if err != nil {
+			log.Warnf("Could not read the disk buffer %s: %s", b.path, err)
+			b.dropOldestSegment()
+			continue
+		}
+		if b.maxAge > 0 && b.now().Sub(time.Unix(0, rec.SpilledAt)) > b.maxAge {
+			droppedMessages.Add(1)
+			continue
+		}
+		origin := message.NewOrigin(bufferSource)
+		origin.Identifier = rec.Identifier
+		origin.Offset = rec.Offset
+		b.nextRecord = rec
+		return message.New(rec.Content, origin, rec.Status)
+	}
+	return nil
+}
+
+// readRecord reads the next record of the oldest segment, the segment is removed
+// by the next read once all its records are read.
+func (b *DiskBuffer) readRecord() (*record, error) {
+	oldest := b.segments[0]
+	if b.reader == nil {
+		reader, err := os.Open(oldest.path)
+		if err != nil {
+			return nil, err
+		}
+		b.reader = reader
+		b.buf = bufio.NewReader(reader)
+	}
+	header := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(b.buf, header); err != nil {
+		return nil, err
+	}
+	data := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := io.ReadFull(b.buf, data); err != nil {
+		return nil, err
+	}
+	oldest.records--
+	rec := &record{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// dropOldestSegment drops the records not read of the oldest segment.
+func (b *DiskBuffer) dropOldestSegment() {
+	oldest := b.segments[0]
+	if oldest.records > 0 {
+		b.drop(oldest.records, fmt.Errorf("the disk buffer exceeds %d bytes or can not be read", b.maxSize))
+	}
+	b.removeOldestSegment()
+}
+
+// removeOldestSegment closes and removes the oldest segment.
+func (b *DiskBuffer) removeOldestSegment() {
+	oldest := b.segments[0]
+	if b.reader != nil {
+		b.reader.Close()
+		b.reader = nil
+		b.buf = nil
+	}
+	if len(b.segments) == 1 {
+		b.closeWriter()
+	}
+	if err := os.Remove(oldest.path); err != nil {
+		log.Warnf("Could not remove the disk buffer file %s: %s", oldest.path, err)
+	}
+	b.size -= oldest.size
+	b.segments = b.segments[1:]
+}
+
+// drop counts the messages dropped.
+func (b *DiskBuffer) drop(count int, err error) {
+	log.Warnf("Dropped %d messages from the disk buffer %s: %s", count, b.path, err)
+	droppedMessages.Add(int64(count))
+}
+
+func (b *DiskBuffer) closeWriter() {
+	if b.writer != nil {
+		b.writer.Close()
+		b.writer = nil
+	}
+}
+
+func (b *DiskBuffer) closeFiles() {
+	b.closeWriter()
+	if b.reader != nil {
+		b.reader.Close()
+		b.reader = nil
+	}
+}
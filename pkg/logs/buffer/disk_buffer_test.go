@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package buffer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+type DiskBufferTestSuite struct {
+	suite.Suite
+	path       string
+	inputChan  chan message.Message
+	outputChan chan message.Message
+}
+
+func (suite *DiskBufferTestSuite) SetupTest() {
+	var err error
+	suite.path, err = ioutil.TempDir("", "disk-buffer")
+	suite.Nil(err)
+	suite.inputChan = make(chan message.Message)
+	// the output is full after one message
+	suite.outputChan = make(chan message.Message, 1)
+}
+
+func (suite *DiskBufferTestSuite) TearDownTest() {
+	os.RemoveAll(suite.path)
+}
+
+func (suite *DiskBufferTestSuite) newMessage(content string) message.Message {
+	origin := message.NewOrigin(config.NewLogSource("", &config.LogsConfig{}))
+	origin.Identifier = "file:/var/log/app.log"
+	origin.Offset = content
+	return message.New([]byte(content), origin, message.StatusError)
+}
+
+// buffered returns the number of files of the buffer
+func (suite *DiskBufferTestSuite) buffered() int {
+	files, _ := filepath.Glob(filepath.Join(suite.path, "*.buffer"))
+	return len(files)
+}
+
+func (suite *DiskBufferTestSuite) TestForwardsMessagesInOrder() {
+	buffer := New(suite.inputChan, suite.outputChan, suite.path, 1024*1024, time.Hour)
+	buffer.Start()
+
+	for i := 0; i < 20; i++ {
+		suite.inputChan <- suite.newMessage(fmt.Sprintf("%d", i))
+	}
+	// the messages over the capacity of the outputChan are written to the disk
+	suite.True(suite.buffered() > 0)
+
+	for i := 0; i < 20; i++ {
+		msg := <-suite.outputChan
+		suite.Equal(fmt.Sprintf("%d", i), string(msg.Content()))
+		suite.Equal(fmt.Sprintf("%d", i), msg.GetOrigin().Offset)
+		suite.Equal("file:/var/log/app.log", msg.GetOrigin().Identifier)
+		suite.Equal(message.StatusError, msg.GetStatus())
+	}
+
+	buffer.Stop()
+	suite.Equal(0, suite.buffered())
+}
+
+func (suite *DiskBufferTestSuite) TestStopFlushesTheBuffer() {
+	buffer := New(suite.inputChan, suite.outputChan, suite.path, 1024*1024, time.Hour)
+	buffer.Start()
+
+	for i := 0; i < 5; i++ {
+		suite.inputChan <- suite.newMessage(fmt.Sprintf("%d", i))
+	}
+	stopped := make(chan struct{})
+	go func() {
+		buffer.Stop()
+		close(stopped)
+	}()
+	for i := 0; i < 5; i++ {
+		suite.Equal(fmt.Sprintf("%d", i), string((<-suite.outputChan).Content()))
+	}
+	<-stopped
+	suite.Equal(0, suite.buffered())
+}
+
+func (suite *DiskBufferTestSuite) TestDropsTheOldestMessagesWhenFull() {
+	// each record takes about 130 bytes, a segment holds 2 of them
+	buffer := New(suite.inputChan, suite.outputChan, suite.path, 2000, time.Hour)
+	for i := 0; i < 100; i++ {
+		buffer.spill(suite.newMessage(fmt.Sprintf("%03d", i)))
+	}
+	suite.True(buffer.size <= 2000)
+	suite.True(suite.buffered() <= segmentsCount)
+
+	// the oldest messages were dropped
+	msg := buffer.readNext()
+	suite.NotEqual("000", string(msg.Content()))
+	for string(msg.Content()) != "099" {
+		msg = buffer.readNext()
+	}
+	suite.Nil(buffer.readNext())
+	suite.Equal(0, suite.buffered())
+}
+
+func (suite *DiskBufferTestSuite) TestDropsTheMessagesOlderThanMaxAge() {
+	now := time.Now()
+	buffer := New(suite.inputChan, suite.outputChan, suite.path, 1024*1024, time.Minute)
+	buffer.now = func() time.Time { return now }
+
+	buffer.spill(suite.newMessage("a"))
+	buffer.spill(suite.newMessage("b"))
+	now = now.Add(time.Hour)
+	buffer.spill(suite.newMessage("c"))
+
+	suite.Equal("c", string(buffer.readNext().Content()))
+	suite.Nil(buffer.readNext())
+}
+
+func (suite *DiskBufferTestSuite) TestSendsTheMessagesLeftAfterRestart() {
+	buffer := New(suite.inputChan, suite.outputChan, suite.path, 1024*1024, time.Hour)
+	buffer.sendTimeout = time.Millisecond
+	buffer.flushTimeout = 10 * time.Millisecond
+	buffer.Start()
+
+	for i := 0; i < 5; i++ {
+		suite.inputChan <- suite.newMessage(fmt.Sprintf("%d", i))
+	}
+	suite.Equal("0", string((<-suite.outputChan).Content()))
+	suite.Equal("1", string((<-suite.outputChan).Content()))
+	// the flush times out as the output is blocked
+	buffer.Stop()
+	suite.True(suite.buffered() > 0)
+
+	suite.inputChan = make(chan message.Message)
+	buffer = New(suite.inputChan, suite.outputChan, suite.path, 1024*1024, time.Hour)
+	buffer.Start()
+	for i := 2; i < 5; i++ {
+		suite.Equal(fmt.Sprintf("%d", i), string((<-suite.outputChan).Content()))
+	}
+	buffer.Stop()
+	suite.Equal(0, suite.buffered())
+}
+
+func (suite *DiskBufferTestSuite) TestWaitsBeforeWritingToTheDisk() {
+	buffer := New(suite.inputChan, suite.outputChan, suite.path, 1024*1024, time.Hour)
+	buffer.Start()
+
+	suite.inputChan <- suite.newMessage("0")
+	// the output is freed before the message is written to the disk
+	first := make(chan message.Message)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		first <- <-suite.outputChan
+	}()
+	suite.inputChan <- suite.newMessage("1")
+	suite.Equal("0", string((<-first).Content()))
+	suite.Equal("1", string((<-suite.outputChan).Content()))
+	suite.Equal(0, suite.buffered())
+	buffer.Stop()
+}
+
+func TestDiskBufferTestSuite(t *testing.T) {
+	suite.Run(t, new(DiskBufferTestSuite))
+}
@@ -15,15 +15,31 @@ var LogsAgent = config.Datadog
 
 // Build returns logs-agent sources
 func Build() (*LogSources, error) {
-	sources, err := buildLogSources(LogsAgent.GetString("confd_path"), LogsAgent.GetBool("logs_config.container_collect_all"))
+	sources, err := buildLogSources(LogsAgent.GetString("confd_path"), LogsAgent.GetBool("logs_config.container_collect_all"), LogsAgent.GetBool("logs_config.k8s_collect_all"))
 	if err != nil {
 		return nil, err
 	}
 	return sources, nil
 }
 
+// GlobalProcessingRules returns the processing rules of logs_config.processing_rules,
+// they are applied to the logs of all the sources before the rules of each source.
+func GlobalProcessingRules() ([]LogsProcessingRule, error) {
+	var rules []LogsProcessingRule
+	if err := LogsAgent.UnmarshalKey("logs_config.processing_rules", &rules); err != nil {
+		return nil, fmt.Errorf("could not parse logs_config.processing_rules: %s", err)
+	}
+	for _, rule := range rules {
+		if rule.Type == MultiLine {
+			// the lines are aggregated by the decoder of each source
+			return nil, fmt.Errorf("LogsAgent misconfigured: %s rules can not be global, remove the rule `%s`", MultiLine, rule.Name)
+		}
+	}
+	return validateProcessingRules(rules)
+}
+
 // buildLogSources returns all the logs sources computed from logs configuration files and environment variables
-func buildLogSources(ddconfdPath string, collectAllLogsFromContainers bool) (*LogSources, error) {
+func buildLogSources(ddconfdPath string, collectAllLogsFromContainers, collectAllLogsFromPods bool) (*LogSources, error) {
 	var sources []*LogSource
 
 	// append sources from all logs config files
@@ -33,16 +49,24 @@ func buildLogSources(ddconfdPath string, collectAllLogsFromContainers bool) (*Lo
 	if collectAllLogsFromContainers {
 		// append source to collect all logs from all containers,
 		// this source must be added to the end of the list
-		// to assure sources metadata are not overridden when already defined
+		// to assure sources metadata are not overridden when already defined,
+		// the source and service of each container are derived from its labels
 		containersSource := NewLogSource("container_collect_all", &LogsConfig{
-			Type:    DockerType,
-			Service: "docker",
-			Source:  "docker",
+			Type: DockerType,
 		})
 		sources = append(sources, containersSource)
 	}
 
-	logSources := &LogSources{sources}
+	if collectAllLogsFromPods {
+		// append source to collect all logs from all pod log files,
+		// the source and service of each container default to its image name
+		podsSource := NewLogSource("k8s_collect_all", &LogsConfig{
+			Type: KubernetesType,
+		})
+		sources = append(sources, podsSource)
+	}
+
+	logSources := NewLogSources(sources)
 
 	if len(logSources.GetValidSources()) == 0 {
 		return nil, fmt.Errorf("could not find any valid logs configuration")
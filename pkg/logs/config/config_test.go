@@ -21,6 +21,11 @@ func TestDefaultDatadogConfig(t *testing.T) {
 	assert.Equal(t, false, LogsAgent.GetBool("logs_config.dev_mode_no_ssl"))
 	assert.Equal(t, true, LogsAgent.GetBool("logs_config.dev_mode_use_proto"))
 	assert.Equal(t, 100, LogsAgent.GetInt("logs_config.open_files_limit"))
+	assert.Equal(t, false, LogsAgent.GetBool("logs_config.k8s_collect_all"))
+	assert.Equal(t, false, LogsAgent.GetBool("logs_config.use_http"))
+	assert.Equal(t, "agent-http-intake.logs.datadoghq.com", LogsAgent.GetString("logs_config.http_dd_url"))
+	assert.Equal(t, 443, LogsAgent.GetInt("logs_config.http_dd_port"))
+	assert.Equal(t, true, LogsAgent.GetBool("logs_config.use_compression"))
 }
 
 func TestBuildLogsSources(t *testing.T) {
@@ -30,27 +35,66 @@ func TestBuildLogsSources(t *testing.T) {
 	var err error
 
 	// should return an error
-	logsSources, err = buildLogSources(ddconfdPath, false)
+	logsSources, err = buildLogSources(ddconfdPath, false, false)
 	assert.NotNil(t, err)
 
 	// should return the default tail all containers source
-	logsSources, err = buildLogSources(ddconfdPath, true)
+	logsSources, err = buildLogSources(ddconfdPath, true, false)
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(logsSources.GetValidSources()))
 
 	source = logsSources.GetValidSources()[0]
 	assert.Equal(t, "container_collect_all", source.Name)
-	assert.Equal(t, "docker", source.Config.Service)
-	assert.Equal(t, "docker", source.Config.Source)
+	assert.Equal(t, "", source.Config.Service)
+	assert.Equal(t, "", source.Config.Source)
 
 	// default tail all containers source should be the last element of the list
 	ddconfdPath = filepath.Join("tests", "any_docker_integration.d")
-	logsSources, err = buildLogSources(ddconfdPath, true)
+	logsSources, err = buildLogSources(ddconfdPath, true, false)
 	assert.Nil(t, err)
 	assert.Equal(t, 3, len(logsSources.GetValidSources()))
 
 	source = logsSources.GetValidSources()[2]
 	assert.Equal(t, "container_collect_all", source.Name)
-	assert.Equal(t, "docker", source.Config.Service)
-	assert.Equal(t, "docker", source.Config.Source)
+	assert.Equal(t, "", source.Config.Service)
+	assert.Equal(t, "", source.Config.Source)
+
+	// should return the default tail all pods source
+	logsSources, err = buildLogSources("", false, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(logsSources.GetValidSources()))
+
+	source = logsSources.GetValidSources()[0]
+	assert.Equal(t, "k8s_collect_all", source.Name)
+	assert.Equal(t, KubernetesType, source.Config.Type)
+}
+
+func TestGlobalProcessingRules(t *testing.T) {
+	defer LogsAgent.Set("logs_config.processing_rules", nil)
+
+	rules, err := GlobalProcessingRules()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(rules))
+
+	LogsAgent.Set("logs_config.processing_rules", []map[string]interface{}{
+		{"type": MaskSequences, "name": "mask_credit_cards", "replace_placeholder": "[masked_credit_card]", "pattern": "(?:4[0-9]{12}(?:[0-9]{3})?)"},
+		{"type": ExcludeAtMatch, "name": "exclude_healthchecks", "pattern": "GET /health"},
+	})
+	rules, err = GlobalProcessingRules()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(rules))
+	assert.Equal(t, []byte("[masked_credit_card]"), rules[0].ReplacePlaceholderBytes)
+	assert.True(t, rules[1].Reg.MatchString("GET /health HTTP/1.1"))
+
+	LogsAgent.Set("logs_config.processing_rules", []map[string]interface{}{
+		{"type": MultiLine, "name": "new_entry", "pattern": "\\d{4}"},
+	})
+	_, err = GlobalProcessingRules()
+	assert.NotNil(t, err)
+
+	LogsAgent.Set("logs_config.processing_rules", []map[string]interface{}{
+		{"type": ExcludeAtMatch, "name": "invalid", "pattern": "("},
+	})
+	_, err = GlobalProcessingRules()
+	assert.NotNil(t, err)
 }
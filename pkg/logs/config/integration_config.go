@@ -24,6 +24,16 @@ const (
 	FileType     = "file"
 	DockerType   = "docker"
 	JournaldType = "journald"
+	// KubernetesType sources collect the log files of the pods of the node
+	KubernetesType = "kubernetes"
+	// WindowsEventType sources subscribe to a channel of the Windows event log
+	WindowsEventType = "windows_event"
+)
+
+// Network sources formats
+const (
+	// SyslogFormat parses the RFC3164 and RFC5424 syslog messages
+	SyslogFormat = "syslog"
 )
 
 // Logs rule types
@@ -32,6 +42,8 @@ const (
 	IncludeAtMatch = "include_at_match"
 	MaskSequences  = "mask_sequences"
 	MultiLine      = "multi_line"
+	// SampleAtMatch rules keep a ratio of the lines matching their pattern
+	SampleAtMatch = "sample_at_match"
 )
 
 // Valid integration config extensions
@@ -48,6 +60,9 @@ type LogsProcessingRule struct {
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder"`
 	Pattern            string
+	MaxLines           int     `mapstructure:"max_lines"`     // MultiLine
+	FlushTimeout       int     `mapstructure:"flush_timeout"` // MultiLine, in milliseconds
+	SampleRate         float64 `mapstructure:"sample_rate"`   // SampleAtMatch, the ratio of the matching lines kept
 	// TODO: should be moved out
 	Reg                     *regexp.Regexp
 	ReplacePlaceholderBytes []byte
@@ -61,6 +76,11 @@ type LogsConfig struct {
 	Port int    // Network
 	Path string // File, Journald
 
+	Format      string // Network
+	TLSCertFile string `mapstructure:"tls_cert_file"` // TCP
+	TLSKeyFile  string `mapstructure:"tls_key_file"`  // TCP
+	TLSCAFile   string `mapstructure:"tls_ca_file"`   // TCP, verifies the certificates of the clients
+
 	IncludeUnits         []string `mapstructure:"include_units"`         // Journald
 	ExcludeUnits         []string `mapstructure:"exclude_units"`         // Journald
 	DisableNormalization bool     `mapstructure:"disable_normalization"` // Journald
@@ -69,6 +89,28 @@ type LogsConfig struct {
 	Label string // Docker
 	Name  string // Docker
 
+	ChannelPath string `mapstructure:"channel_path"` // Windows Event
+	Query       string // Windows Event, an XPath query selecting the events of the channel
+
+	IncludeNamespaces []string `mapstructure:"include_namespaces"` // Kubernetes
+	ExcludeNamespaces []string `mapstructure:"exclude_namespaces"` // Kubernetes
+
+	// Identifier is the entity of the container the logs come from, to query
+	// its tags from the tagger. It is set on the sources created at runtime.
+	Identifier string
+
+	// Limits of the logs sent per second, the lines over them are dropped
+	MaxLinesPerSecond int `mapstructure:"max_lines_per_second"`
+	MaxBytesPerSecond int `mapstructure:"max_bytes_per_second"`
+
+	// Parse the lines as JSON objects, the attributes of JSONTagAttributes are
+	// added to the tags of the logs, JSONTimestampAttribute replaces their
+	// timestamp and JSONMessageAttribute is moved to their `message` attribute
+	ParseJSON              bool     `mapstructure:"parse_json"`
+	JSONTagAttributes      []string `mapstructure:"json_tag_attributes"`
+	JSONMessageAttribute   string   `mapstructure:"json_message_attribute"`
+	JSONTimestampAttribute string   `mapstructure:"json_timestamp_attribute"`
+
 	Service         string
 	Source          string
 	SourceCategory  string
@@ -191,7 +233,7 @@ func integrationConfigsFromDirectory(dir string, prefix string) []string {
 
 func validateConfig(config LogsConfig) error {
 	switch config.Type {
-	case FileType, DockerType, TCPType, UDPType, JournaldType:
+	case FileType, DockerType, TCPType, UDPType, JournaldType, KubernetesType, WindowsEventType:
 	default:
 		return fmt.Errorf("A source must have a valid type (got %s)", config.Type)
 	}
@@ -203,6 +245,25 @@ func validateConfig(config LogsConfig) error {
 		return fmt.Errorf("A tcp source must have a port")
 	case config.Type == UDPType && config.Port == 0:
 		return fmt.Errorf("A udp source must have a port")
+	case config.Type == WindowsEventType && config.ChannelPath == "":
+		return fmt.Errorf("A windows event source must have a channel_path")
+	}
+
+	switch {
+	case config.Format != "" && config.Format != SyslogFormat:
+		return fmt.Errorf("A network source format must be %s (got %s)", SyslogFormat, config.Format)
+	case config.Format != "" && config.Type != TCPType && config.Type != UDPType:
+		return fmt.Errorf("Only a tcp or udp source can have a format (got %s)", config.Type)
+	case (config.TLSCertFile == "") != (config.TLSKeyFile == ""):
+		return fmt.Errorf("A tls source must have both a tls_cert_file and a tls_key_file")
+	case config.TLSCertFile != "" && config.Type != TCPType:
+		return fmt.Errorf("Only a tcp source can use tls (got %s)", config.Type)
+	case config.TLSCAFile != "" && config.TLSCertFile == "":
+		return fmt.Errorf("A source verifying the client certificates with tls_ca_file must have a tls_cert_file")
+	case config.MaxLinesPerSecond < 0 || config.MaxBytesPerSecond < 0:
+		return fmt.Errorf("A source must not have negative max_lines_per_second and max_bytes_per_second")
+	case !config.ParseJSON && (len(config.JSONTagAttributes) > 0 || config.JSONMessageAttribute != "" || config.JSONTimestampAttribute != ""):
+		return fmt.Errorf("A source must set parse_json to use json_tag_attributes, json_message_attribute and json_timestamp_attribute")
 	default:
 		return nil
 	}
@@ -214,22 +275,34 @@ func validateProcessingRules(rules []LogsProcessingRule) ([]LogsProcessingRule,
 		if rule.Name == "" {
 			return nil, fmt.Errorf("LogsAgent misconfigured: all log processing rules need a name")
 		}
+		var err error
 		switch rule.Type {
 		case ExcludeAtMatch:
-			rules[i].Reg = regexp.MustCompile(rule.Pattern)
+			rules[i].Reg, err = regexp.Compile(rule.Pattern)
 		case IncludeAtMatch:
-			rules[i].Reg = regexp.MustCompile(rule.Pattern)
+			rules[i].Reg, err = regexp.Compile(rule.Pattern)
 		case MaskSequences:
-			rules[i].Reg = regexp.MustCompile(rule.Pattern)
+			rules[i].Reg, err = regexp.Compile(rule.Pattern)
 			rules[i].ReplacePlaceholderBytes = []byte(rule.ReplacePlaceholder)
 		case MultiLine:
-			rules[i].Reg = regexp.MustCompile("^" + rule.Pattern)
+			if rule.MaxLines < 0 || rule.FlushTimeout < 0 {
+				return nil, fmt.Errorf("LogsAgent misconfigured: max_lines and flush_timeout must not be negative for log processing rule `%s`", rule.Name)
+			}
+			rules[i].Reg, err = regexp.Compile("^" + rule.Pattern)
+		case SampleAtMatch:
+			if rule.SampleRate <= 0 || rule.SampleRate > 1 {
+				return nil, fmt.Errorf("LogsAgent misconfigured: sample_rate must be greater than 0 and at most 1 for log processing rule `%s`", rule.Name)
+			}
+			rules[i].Reg, err = regexp.Compile(rule.Pattern)
 		default:
 			if rule.Type == "" {
 				return nil, fmt.Errorf("LogsAgent misconfigured: type must be set for log processing rule `%s`", rule.Name)
 			}
 			return nil, fmt.Errorf("LogsAgent misconfigured: type %s is unsupported for log processing rule `%s`", rule.Type, rule.Name)
 		}
+		if err != nil {
+			return nil, fmt.Errorf("LogsAgent misconfigured: invalid pattern for log processing rule `%s`: %s", rule.Name, err)
+		}
 	}
 	return rules, nil
 }
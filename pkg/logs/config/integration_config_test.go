@@ -23,7 +23,7 @@ func TestAvailableIntegrationConfigs(t *testing.T) {
 
 func TestBuildLogsAgentIntegrationsConfigs(t *testing.T) {
 	ddconfdPath := filepath.Join(testsPath, "complete", "conf.d")
-	allSources, err := buildLogSources(ddconfdPath, false)
+	allSources, err := buildLogSources(ddconfdPath, false, false)
 
 	assert.Nil(t, err)
 	assert.Equal(t, 6, len(allSources.GetValidSources()))
@@ -85,23 +85,23 @@ func TestBuildLogsAgentIntegrationConfigsWithMisconfiguredFile(t *testing.T) {
 	var ddconfdPath string
 	var err error
 	ddconfdPath = filepath.Join(testsPath, "misconfigured_1")
-	_, err = buildLogSources(ddconfdPath, false)
+	_, err = buildLogSources(ddconfdPath, false, false)
 	assert.NotNil(t, err)
 
 	ddconfdPath = filepath.Join(testsPath, "misconfigured_2", "conf.d")
-	_, err = buildLogSources(ddconfdPath, false)
+	_, err = buildLogSources(ddconfdPath, false, false)
 	assert.NotNil(t, err)
 
 	ddconfdPath = filepath.Join(testsPath, "misconfigured_3", "conf.d")
-	_, err = buildLogSources(ddconfdPath, false)
+	_, err = buildLogSources(ddconfdPath, false, false)
 	assert.NotNil(t, err)
 
 	ddconfdPath = filepath.Join(testsPath, "misconfigured_4", "conf.d")
-	_, err = buildLogSources(ddconfdPath, false)
+	_, err = buildLogSources(ddconfdPath, false, false)
 	assert.NotNil(t, err)
 
 	ddconfdPath = filepath.Join(testsPath, "misconfigured_5", "conf.d")
-	_, err = buildLogSources(ddconfdPath, false)
+	_, err = buildLogSources(ddconfdPath, false, false)
 	assert.NotNil(t, err)
 }
 
@@ -127,3 +127,60 @@ func TestIntegrationName(t *testing.T) {
 	_, err = buildIntegrationName("foo.b/bar.yml")
 	assert.NotNil(t, err)
 }
+
+func TestValidateNetworkConfig(t *testing.T) {
+	assert.Nil(t, validateConfig(LogsConfig{Type: TCPType, Port: 10514, Format: SyslogFormat}))
+	assert.Nil(t, validateConfig(LogsConfig{Type: TCPType, Port: 10514, TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", TLSCAFile: "ca.pem"}))
+
+	assert.NotNil(t, validateConfig(LogsConfig{Type: UDPType, Port: 10514, Format: "gelf"}))
+	assert.NotNil(t, validateConfig(LogsConfig{Type: FileType, Path: "/var/log/syslog", Format: SyslogFormat}))
+	assert.NotNil(t, validateConfig(LogsConfig{Type: TCPType, Port: 10514, TLSCertFile: "cert.pem"}))
+	assert.NotNil(t, validateConfig(LogsConfig{Type: UDPType, Port: 10514, TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"}))
+	assert.NotNil(t, validateConfig(LogsConfig{Type: TCPType, Port: 10514, TLSCAFile: "ca.pem"}))
+}
+
+func TestValidateWindowsEventConfig(t *testing.T) {
+	assert.Nil(t, validateConfig(LogsConfig{Type: WindowsEventType, ChannelPath: "System"}))
+	assert.Nil(t, validateConfig(LogsConfig{Type: WindowsEventType, ChannelPath: "Security", Query: "*[System[(Level=1 or Level=2)]]"}))
+	assert.NotNil(t, validateConfig(LogsConfig{Type: WindowsEventType}))
+}
+
+func TestValidateMultiLineRules(t *testing.T) {
+	var err error
+	_, err = validateProcessingRules([]LogsProcessingRule{{Type: MultiLine, Name: "new_entry", Pattern: "\\d{4}", MaxLines: 500, FlushTimeout: 5000}})
+	assert.Nil(t, err)
+
+	_, err = validateProcessingRules([]LogsProcessingRule{{Type: MultiLine, Name: "new_entry", Pattern: "\\d{4}", MaxLines: -1}})
+	assert.NotNil(t, err)
+
+	_, err = validateProcessingRules([]LogsProcessingRule{{Type: MultiLine, Name: "new_entry", Pattern: "\\d{4}", FlushTimeout: -1}})
+	assert.NotNil(t, err)
+}
+
+func TestValidateSampleRules(t *testing.T) {
+	var err error
+	_, err = validateProcessingRules([]LogsProcessingRule{{Type: SampleAtMatch, Name: "sample_debug", Pattern: "DEBUG", SampleRate: 0.1}})
+	assert.Nil(t, err)
+
+	_, err = validateProcessingRules([]LogsProcessingRule{{Type: SampleAtMatch, Name: "sample_all", SampleRate: 1}})
+	assert.Nil(t, err)
+
+	_, err = validateProcessingRules([]LogsProcessingRule{{Type: SampleAtMatch, Name: "sample_debug", Pattern: "DEBUG"}})
+	assert.NotNil(t, err)
+
+	_, err = validateProcessingRules([]LogsProcessingRule{{Type: SampleAtMatch, Name: "sample_debug", Pattern: "DEBUG", SampleRate: 1.5}})
+	assert.NotNil(t, err)
+}
+
+func TestValidateRateLimits(t *testing.T) {
+	assert.Nil(t, validateConfig(LogsConfig{Type: FileType, Path: "/var/log/app.log", MaxLinesPerSecond: 100, MaxBytesPerSecond: 1024}))
+	assert.NotNil(t, validateConfig(LogsConfig{Type: FileType, Path: "/var/log/app.log", MaxLinesPerSecond: -1}))
+	assert.NotNil(t, validateConfig(LogsConfig{Type: FileType, Path: "/var/log/app.log", MaxBytesPerSecond: -1}))
+}
+
+func TestValidateJSONConfig(t *testing.T) {
+	assert.Nil(t, validateConfig(LogsConfig{Type: FileType, Path: "/var/log/app.log", ParseJSON: true}))
+	assert.Nil(t, validateConfig(LogsConfig{Type: FileType, Path: "/var/log/app.log", ParseJSON: true, JSONTagAttributes: []string{"level"}, JSONMessageAttribute: "msg", JSONTimestampAttribute: "time"}))
+	assert.NotNil(t, validateConfig(LogsConfig{Type: FileType, Path: "/var/log/app.log", JSONTagAttributes: []string{"level"}}))
+	assert.NotNil(t, validateConfig(LogsConfig{Type: FileType, Path: "/var/log/app.log", JSONMessageAttribute: "msg"}))
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the number of lines and of bytes sent every second,
+// a limit is disabled when it is not greater than 0.
+type RateLimiter struct {
+	maxLines int
+	maxBytes int
+
+	mu     sync.Mutex
+	window int64
+	lines  int
+	bytes  int
+	// now is mocked in the tests
+	now func() time.Time
+}
+
+// NewRateLimiter returns a new RateLimiter.
+func NewRateLimiter(maxLinesPerSecond, maxBytesPerSecond int) *RateLimiter {
+	return &RateLimiter{
+		maxLines: maxLinesPerSecond,
+		maxBytes: maxBytesPerSecond,
+		now:      time.Now,
+	}
+}
+
+// Allow returns true if a line of the given size can be sent in the current second
+// and counts it, a line larger than the bytes limit is only allowed first in its second.
+func (r *RateLimiter) Allow(size int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if window := r.now().Unix(); window != r.window {
+		r.window = window
+		r.lines = 0
+		r.bytes = 0
+	}
+	if r.maxLines > 0 && r.lines >= r.maxLines {
+		return false
+	}
+	if r.maxBytes > 0 && r.bytes > 0 && r.bytes+size > r.maxBytes {
+		return false
+	}
+	r.lines++
+	r.bytes += size
+	return true
+}
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterLimitsLines(t *testing.T) {
+	now := time.Unix(1000, 0)
+	limiter := NewRateLimiter(2, 0)
+	limiter.now = func() time.Time { return now }
+
+	assert.True(t, limiter.Allow(10))
+	assert.True(t, limiter.Allow(10))
+	assert.False(t, limiter.Allow(10))
+
+	// the limit is reset every second
+	now = now.Add(time.Second)
+	assert.True(t, limiter.Allow(10))
+}
+
+func TestRateLimiterLimitsBytes(t *testing.T) {
+	now := time.Unix(1000, 0)
+	limiter := NewRateLimiter(0, 10)
+	limiter.now = func() time.Time { return now }
+
+	assert.True(t, limiter.Allow(6))
+	assert.False(t, limiter.Allow(6))
+	assert.True(t, limiter.Allow(4))
+	assert.False(t, limiter.Allow(1))
+
+	// a line larger than the limit is sent first in its second
+	now = now.Add(time.Second)
+	assert.True(t, limiter.Allow(20))
+	assert.False(t, limiter.Allow(1))
+}
+
+func TestNewLogSourceSetsRateLimiter(t *testing.T) {
+	assert.Nil(t, NewLogSource("", &LogsConfig{}).RateLimiter)
+	assert.NotNil(t, NewLogSource("", &LogsConfig{MaxLinesPerSecond: 10}).RateLimiter)
+	assert.NotNil(t, NewLogSource("", &LogsConfig{MaxBytesPerSecond: 1024}).RateLimiter)
+}
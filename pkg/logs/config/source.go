@@ -7,6 +7,13 @@ package config
 
 import "sync"
 
+// SourceType tells the format of the logs of a source, for the inputs to parse them.
+type SourceType string
+
+// KubernetesSourceType is set on the file sources of the log files of the pods,
+// written by the container runtime.
+const KubernetesSourceType SourceType = "kubernetes"
+
 // LogSource holds a reference to and integration name and a log configuration, and allows to track errors and
 // successful operations on it. Both name and configuration are static for now and determined at creation time.
 // Changing the status is designed to be thread safe.
@@ -16,17 +23,53 @@ type LogSource struct {
 	Status *LogStatus
 	inputs map[string]bool
 	lock   *sync.Mutex
+	// sourceType is empty for the raw logs
+	sourceType SourceType
+	// RateLimiter is shared by all the pipelines processing the logs of the
+	// source, nil when the source has no limit
+	RateLimiter *RateLimiter
+	// ParentSource is the source of the configuration a source created at
+	// runtime derives from, nil for the sources of the configuration
+	ParentSource *LogSource
 }
 
 // NewLogSource creates a new log source.
 func NewLogSource(name string, config *LogsConfig) *LogSource {
-	return &LogSource{
+	source := &LogSource{
 		Name:   name,
 		Config: config,
 		Status: NewLogStatus(),
 		inputs: make(map[string]bool),
 		lock:   &sync.Mutex{},
 	}
+	if config.MaxLinesPerSecond > 0 || config.MaxBytesPerSecond > 0 {
+		source.RateLimiter = NewRateLimiter(config.MaxLinesPerSecond, config.MaxBytesPerSecond)
+	}
+	return source
+}
+
+// ConfigName returns the name of the source of the configuration, the name of
+// its parent for the sources created at runtime. Unlike the names of these
+// sources, there is a bounded number of them.
+func (s *LogSource) ConfigName() string {
+	if s.ParentSource != nil {
+		return s.ParentSource.ConfigName()
+	}
+	return s.Name
+}
+
+// SetSourceType sets the format of the logs of the source.
+func (s *LogSource) SetSourceType(sourceType SourceType) {
+	s.lock.Lock()
+	s.sourceType = sourceType
+	s.lock.Unlock()
+}
+
+// GetSourceType returns the format of the logs of the source.
+func (s *LogSource) GetSourceType() SourceType {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sourceType
 }
 
 // AddInput registers an input as being handled by this source.
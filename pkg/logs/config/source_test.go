@@ -28,6 +28,14 @@ func (s *LogSourceSuite) TestInputs() {
 
 }
 
+func (s *LogSourceSuite) TestConfigName() {
+	parent := NewLogSource("k8s_collect_all", &LogsConfig{})
+	s.source = NewLogSource("default/web/nginx", &LogsConfig{})
+	s.Equal("default/web/nginx", s.source.ConfigName())
+	s.source.ParentSource = parent
+	s.Equal("k8s_collect_all", s.source.ConfigName())
+}
+
 func TestTrackerSuite(t *testing.T) {
 	suite.Run(t, new(LogSourceSuite))
 }
@@ -5,21 +5,87 @@
 
 package config
 
-// LogSources stores a list of log sources.
+import "sync"
+
+// LogSources stores a list of log sources, the sources can be added and removed
+// at runtime by the inputs discovering them, such as the kubernetes launcher.
 type LogSources struct {
-	sources []*LogSource
+	mu            sync.Mutex
+	sources       []*LogSource
+	addedByType   map[string][]chan *LogSource
+	removedByType map[string][]chan *LogSource
 }
 
-// newLogsSource creates a new log sources.
-func newLogSources(sources []*LogSource) *LogSources {
+// NewLogSources creates a new log sources.
+func NewLogSources(sources []*LogSource) *LogSources {
 	return &LogSources{
-		sources: sources,
+		sources:       sources,
+		addedByType:   make(map[string][]chan *LogSource),
+		removedByType: make(map[string][]chan *LogSource),
+	}
+}
+
+// AddSource adds a new source and sends it to the streams of its type,
+// this call blocks until the source is received by all of them.
+func (s *LogSources) AddSource(source *LogSource) {
+	s.mu.Lock()
+	s.sources = append(s.sources, source)
+	streams := s.addedByType[source.Config.Type]
+	s.mu.Unlock()
+	for _, stream := range streams {
+		stream <- source
 	}
 }
 
+// RemoveSource removes a source and sends it to the streams of removed sources
+// of its type, this call blocks until the source is received by all of them.
+func (s *LogSources) RemoveSource(source *LogSource) {
+	s.mu.Lock()
+	removed := false
+	for i, src := range s.sources {
+		if src == source {
+			s.sources = append(s.sources[:i], s.sources[i+1:]...)
+			removed = true
+			break
+		}
+	}
+	streams := s.removedByType[source.Config.Type]
+	s.mu.Unlock()
+	if !removed {
+		return
+	}
+	for _, stream := range streams {
+		stream <- source
+	}
+}
+
+// GetAddedForType returns a stream receiving the sources of the given type added
+// from now on, it must be consumed for AddSource to return.
+func (s *LogSources) GetAddedForType(sourceType string) chan *LogSource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream := make(chan *LogSource)
+	s.addedByType[sourceType] = append(s.addedByType[sourceType], stream)
+	return stream
+}
+
+// GetRemovedForType returns a stream receiving the sources of the given type
+// removed from now on, it must be consumed for RemoveSource to return.
+func (s *LogSources) GetRemovedForType(sourceType string) chan *LogSource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream := make(chan *LogSource)
+	s.removedByType[sourceType] = append(s.removedByType[sourceType], stream)
+	return stream
+}
+
 // GetSources returns all the sources currently held.
 func (s *LogSources) GetSources() []*LogSource {
-	return s.sources
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sources := make([]*LogSource, len(s.sources))
+	copy(sources, s.sources)
+	return sources
 }
 
 // GetValidSources returns all the sources currently held not having errors.
@@ -32,7 +98,7 @@ func (s *LogSources) GetValidSources() []*LogSource {
 // getSources returns all the sources matching the provided filter.
 func (s *LogSources) getSources(filter func(*LogSource) bool) []*LogSource {
 	sources := make([]*LogSource, 0)
-	for _, source := range s.sources {
+	for _, source := range s.GetSources() {
 		if filter(source) {
 			sources = append(sources, source)
 		}
@@ -18,16 +18,16 @@ type LogSourcesSuite struct {
 }
 
 func (s *LogSourcesSuite) TestGetSources() {
-	s.sources = newLogSources([]*LogSource{})
+	s.sources = NewLogSources([]*LogSource{})
 	s.Equal(0, len(s.sources.GetSources()))
-	s.sources = newLogSources([]*LogSource{NewLogSource("", nil)})
+	s.sources = NewLogSources([]*LogSource{NewLogSource("", nil)})
 	s.Equal(1, len(s.sources.GetSources()))
 }
 
 func (s *LogSourcesSuite) TestGetValidSources() {
 	source1 := NewLogSource("", nil)
 	source2 := NewLogSource("", nil)
-	s.sources = newLogSources([]*LogSource{source1, source2})
+	s.sources = NewLogSources([]*LogSource{source1, source2})
 	s.Equal(2, len(s.sources.GetValidSources()))
 	source1.Status.Error(errors.New("invalid"))
 	s.Equal(1, len(s.sources.GetValidSources()))
@@ -35,6 +35,25 @@ func (s *LogSourcesSuite) TestGetValidSources() {
 	s.Equal(2, len(s.sources.GetValidSources()))
 }
 
+func (s *LogSourcesSuite) TestAddAndRemoveSources() {
+	s.sources = NewLogSources([]*LogSource{})
+	added := s.sources.GetAddedForType(FileType)
+	removed := s.sources.GetRemovedForType(FileType)
+
+	source := NewLogSource("", &LogsConfig{Type: FileType})
+	go s.sources.AddSource(source)
+	s.Equal(source, <-added)
+	s.Equal(1, len(s.sources.GetSources()))
+
+	// the sources of other types are not sent to the stream
+	s.sources.AddSource(NewLogSource("", &LogsConfig{Type: TCPType}))
+	s.Equal(2, len(s.sources.GetSources()))
+
+	go s.sources.RemoveSource(source)
+	s.Equal(source, <-removed)
+	s.Equal(1, len(s.sources.GetSources()))
+}
+
 func TestLogSourcesSuite(t *testing.T) {
 	suite.Run(t, new(LogSourcesSuite))
 }
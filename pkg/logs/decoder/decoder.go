@@ -7,6 +7,7 @@ package decoder
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 )
@@ -63,7 +64,11 @@ func InitializeDecoder(source *config.LogSource) *Decoder {
 			default:
 				lineUnwrapper = NewUnwrapper()
 			}
-			lineHandler = NewMultiLineHandler(outputChan, rule.Reg, defaultFlushTimeout, lineUnwrapper)
+			flushTimeout := defaultFlushTimeout
+			if rule.FlushTimeout > 0 {
+				flushTimeout = time.Duration(rule.FlushTimeout) * time.Millisecond
+			}
+			lineHandler = NewMultiLineHandler(outputChan, rule.Reg, flushTimeout, rule.MaxLines, lineUnwrapper)
 			break
 		}
 	}
@@ -98,7 +98,7 @@ func (h *SingleLineHandler) process(line []byte) {
 const defaultFlushTimeout = 1000 * time.Millisecond
 
 // MultiLineHandler reads lines from lineChan and uses lineBuffer to send them
-// when a new line matches with re or flushTimer is fired
+// when a new line matches with re, maxLines lines are buffered or flushTimer is fired
 type MultiLineHandler struct {
 	lineChan      chan []byte
 	outputChan    chan *Output
@@ -106,10 +106,12 @@ type MultiLineHandler struct {
 	lineUnwrapper LineUnwrapper
 	newContentRe  *regexp.Regexp
 	flushTimeout  time.Duration
+	maxLines      int
+	linesCount    int
 }
 
-// NewMultiLineHandler returns a new MultiLineHandler
-func NewMultiLineHandler(outputChan chan *Output, newContentRe *regexp.Regexp, flushTimeout time.Duration, lineUnwrapper LineUnwrapper) *MultiLineHandler {
+// NewMultiLineHandler returns a new MultiLineHandler, maxLines is not enforced if zero
+func NewMultiLineHandler(outputChan chan *Output, newContentRe *regexp.Regexp, flushTimeout time.Duration, maxLines int, lineUnwrapper LineUnwrapper) *MultiLineHandler {
 	return &MultiLineHandler{
 		lineChan:      make(chan []byte),
 		outputChan:    outputChan,
@@ -117,6 +119,7 @@ func NewMultiLineHandler(outputChan chan *Output, newContentRe *regexp.Regexp, f
 		lineUnwrapper: lineUnwrapper,
 		newContentRe:  newContentRe,
 		flushTimeout:  flushTimeout,
+		maxLines:      maxLines,
 	}
 }
 
@@ -161,7 +164,7 @@ func (h *MultiLineHandler) run() {
 }
 
 // process accumulates lines in lineBuffer and flushes lineBuffer when a new line matches with newContentRe
-// When lines are too long, they are truncated
+// or when it holds maxLines lines. When lines are too long, they are truncated
 func (h *MultiLineHandler) process(line []byte) {
 	unwrappedLine := h.lineUnwrapper.Unwrap(line)
 	if h.newContentRe.Match(unwrappedLine) {
@@ -185,12 +188,21 @@ func (h *MultiLineHandler) process(line []byte) {
 		h.sendContent()
 		// truncate next content
 		h.lineBuffer.AddTruncate(line)
+		return
+	}
+	h.linesCount++
+	if h.maxLines > 0 && h.linesCount >= h.maxLines {
+		// the content is too long, send it and start a new one with the next line
+		h.sendContent()
 	}
 }
 
 // sendContent forwards the content from lineBuffer to outputChan
 func (h *MultiLineHandler) sendContent() {
-	defer h.lineBuffer.Reset()
+	defer func() {
+		h.lineBuffer.Reset()
+		h.linesCount = 0
+	}()
 	content, rawDataLen := h.lineBuffer.Content()
 	content = bytes.TrimSpace(content)
 	if len(content) > 0 {
@@ -95,7 +95,7 @@ func TestTrimSingleLine(t *testing.T) {
 func TestMultiLineHandler(t *testing.T) {
 	re := regexp.MustCompile("[0-9]+\\.")
 	outputChan := make(chan *Output, 10)
-	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, NewUnwrapper())
+	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, 0, NewUnwrapper())
 	h.Start()
 
 	var output *Output
@@ -145,10 +145,34 @@ func TestMultiLineHandler(t *testing.T) {
 	h.Stop()
 }
 
+func TestMultiLineHandlerMaxLines(t *testing.T) {
+	re := regexp.MustCompile("[0-9]+\\.")
+	outputChan := make(chan *Output, 10)
+	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, 2, NewUnwrapper())
+	h.Start()
+
+	var output *Output
+
+	// the content should be sent once it holds two lines
+	h.Handle([]byte("1. first line"))
+	h.Handle([]byte("second line"))
+	h.Handle([]byte("third line"))
+	h.Handle([]byte("2. first line"))
+
+	output = <-outputChan
+	assert.Equal(t, "1. first line"+"\\n"+"second line", string(output.Content))
+	output = <-outputChan
+	assert.Equal(t, "third line", string(output.Content))
+	output = <-outputChan
+	assert.Equal(t, "2. first line", string(output.Content))
+
+	h.Stop()
+}
+
 func TestTrimMultiLine(t *testing.T) {
 	re := regexp.MustCompile("[0-9]+\\.")
 	outputChan := make(chan *Output, 10)
-	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, NewUnwrapper())
+	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, 0, NewUnwrapper())
 	h.Start()
 
 	var output *Output
@@ -174,7 +198,7 @@ func TestUnwrapMultiLine(t *testing.T) {
 
 	re := regexp.MustCompile("[0-9]+\\.")
 	outputChan := make(chan *Output, 10)
-	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, NewMockUnwrapper(header))
+	h := NewMultiLineHandler(outputChan, re, 10*time.Millisecond, 0, NewMockUnwrapper(header))
 	h.Start()
 
 	var output *Output
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package diagnostic streams the processed logs to the clients of the
+// `agent stream-logs` command.
+package diagnostic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/util/stream"
+)
+
+// The fields the logs can be filtered by
+const (
+	FilterName    = "name"
+	FilterType    = "type"
+	FilterSource  = "source"
+	FilterService = "service"
+)
+
+// FilterKeys are the fields the logs can be filtered by
+var FilterKeys = []string{FilterName, FilterType, FilterSource, FilterService}
+
+// Broadcaster sends the processed logs to the clients of the stream
+var Broadcaster = stream.NewBroadcaster()
+
+// HandleMessage sends a message to the clients of the stream, content is the
+// content of the message once the processing rules are applied.
+func HandleMessage(msg message.Message, content []byte) {
+	if !Broadcaster.Active() {
+		return
+	}
+	origin := msg.GetOrigin()
+	fields := map[string]string{
+		FilterName:    origin.LogSource.Name,
+		FilterType:    origin.LogSource.Config.Type,
+		FilterSource:  origin.Source(),
+		FilterService: origin.Service(),
+	}
+	Broadcaster.Send(fields, formatMessage(fields, msg, content))
+}
+
+func formatMessage(fields map[string]string, msg message.Message, content []byte) string {
+	return fmt.Sprintf("Integration Name: %s | Type: %s | Status: %s | Source: %s | Service: %s | Tags: %s | %s",
+		fields[FilterName],
+		fields[FilterType],
+		msg.GetStatus(),
+		fields[FilterSource],
+		fields[FilterService],
+		strings.Join(msg.GetOrigin().Tags(), ","),
+		content,
+	)
+}
@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package diagnostic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/util/stream"
+)
+
+func newMessage(name, source, content string) message.Message {
+	logSource := config.NewLogSource(name, &config.LogsConfig{Type: config.FileType, Source: source, Service: "web"})
+	return message.New([]byte(content), message.NewOrigin(logSource), message.StatusInfo)
+}
+
+func TestHandleMessage(t *testing.T) {
+	// no client is subscribed
+	HandleMessage(newMessage("nginx", "nginx", "dropped"), []byte("dropped"))
+
+	s := Broadcaster.Subscribe(stream.Filter{FilterSource: "nginx"})
+	defer Broadcaster.Unsubscribe(s)
+
+	HandleMessage(newMessage("redis", "redis", "ignored"), []byte("ignored"))
+	HandleMessage(newMessage("nginx", "nginx", "GET /"), []byte("GET /"))
+
+	assert.Len(t, s.C, 1)
+	assert.Equal(t, "Integration Name: nginx | Type: file | Status: info | Source: nginx | Service: web | Tags: source:nginx | GET /", <-s.C)
+}
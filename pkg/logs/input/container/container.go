@@ -157,3 +157,40 @@ func (c *Container) parseConfig() *config.LogsConfig {
 	config := configs[0]
 	return &config
 }
+
+// checkNamesLabel refers to the names of the checks of the autodiscovery template of a container.
+const checkNamesLabel = "com.datadoghq.ad.check_names"
+
+// composeServiceLabel refers to the name of the service of a container started by docker-compose.
+const composeServiceLabel = "com.docker.compose.service"
+
+// getSource returns the source of the logs of the container when its config
+// does not define one: the first check of its autodiscovery template, or the
+// short name of its image.
+func (c *Container) getSource() string {
+	if label, exists := c.Labels[checkNamesLabel]; exists {
+		var checkNames []string
+		if err := json.Unmarshal([]byte(label), &checkNames); err == nil && len(checkNames) > 0 && checkNames[0] != "" {
+			return checkNames[0]
+		}
+		log.Warnf("Could not parse the check names, %v is malformed", label)
+	}
+	return c.shortImageName()
+}
+
+// getService returns the service of the logs of the container when its config
+// does not define one: its docker-compose service, or the short name of its image.
+func (c *Container) getService() string {
+	if service := c.Labels[composeServiceLabel]; service != "" {
+		return service
+	}
+	return c.shortImageName()
+}
+
+// shortImageName returns the name of the image of the container without its
+// repository, tag and digest, e.g. nginx for gcr.io/project/nginx:1.15@sha256:...
+func (c *Container) shortImageName() string {
+	image := strings.SplitN(c.Image, digestPrefix, 2)[0]
+	image = image[strings.LastIndex(image, "/")+1:]
+	return strings.SplitN(image, tagSeparator, 2)[0]
+}
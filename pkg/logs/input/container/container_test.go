@@ -247,3 +247,28 @@ func TestParseConfigWithValidFormatShouldSucceed(t *testing.T) {
 	assert.Equal(t, "any_source", config.Source)
 	assert.Equal(t, "any_service", config.Service)
 }
+
+func TestGetSourceAndService(t *testing.T) {
+	var container *Container
+
+	container = NewContainer(types.Container{Image: "gcr.io/project/nginx:1.15@sha256:1234"})
+	assert.Equal(t, "nginx", container.getSource())
+	assert.Equal(t, "nginx", container.getService())
+
+	container = NewContainer(types.Container{
+		Image: "myapp:latest",
+		Labels: map[string]string{
+			"com.datadoghq.ad.check_names": "[\"redisdb\"]",
+			"com.docker.compose.service":   "cache",
+		},
+	})
+	assert.Equal(t, "redisdb", container.getSource())
+	assert.Equal(t, "cache", container.getService())
+
+	container = NewContainer(types.Container{
+		Image:  "redis",
+		Labels: map[string]string{"com.datadoghq.ad.check_names": "redisdb"},
+	})
+	assert.Equal(t, "redis", container.getSource())
+	assert.Equal(t, "redis", container.getService())
+}
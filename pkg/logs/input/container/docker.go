@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	dockerutil "github.com/DataDog/datadog-agent/pkg/util/docker"
 	log "github.com/cihub/seelog"
 
@@ -42,6 +43,9 @@ type DockerTailer struct {
 	cli           *client.Client
 	source        *config.LogSource
 	containerTags []string
+	// the service and the source of the logs when the source does not define them
+	service    string
+	sourceName string
 
 	sleepDuration time.Duration
 	shouldStop    bool
@@ -49,14 +53,17 @@ type DockerTailer struct {
 	done          chan struct{}
 }
 
-// NewDockerTailer returns a new DockerTailer
-func NewDockerTailer(cli *client.Client, containerID string, source *config.LogSource, outputChan chan message.Message) *DockerTailer {
+// NewDockerTailer returns a new DockerTailer, the service and the source of the
+// logs are derived from the labels of the container when the source does not define them
+func NewDockerTailer(cli *client.Client, container *Container, source *config.LogSource, outputChan chan message.Message) *DockerTailer {
 	return &DockerTailer{
-		ContainerID: containerID,
+		ContainerID: container.ID,
 		outputChan:  outputChan,
 		decoder:     decoder.InitializeDecoder(source),
 		source:      source,
 		cli:         cli,
+		service:     container.getService(),
+		sourceName:  container.getSource(),
 
 		sleepDuration: defaultSleepDuration,
 		stop:          make(chan struct{}, 1),
@@ -201,6 +208,8 @@ func (dt *DockerTailer) forwardMessages() {
 		origin.Offset = ts
 		origin.Identifier = dt.Identifier()
 		origin.SetTags(dt.containerTags)
+		origin.SetService(dt.service)
+		origin.SetSource(dt.sourceName)
 		dt.outputChan <- message.New(content, origin, status)
 	}
 }
@@ -217,7 +226,7 @@ func (dt *DockerTailer) keepDockerTagsUpdated() {
 }
 
 func (dt *DockerTailer) checkForNewDockerTags() {
-	tags, err := tagger.Tag(dockerutil.ContainerIDToEntityName(dt.ContainerID), true)
+	tags, err := tagger.Tag(dockerutil.ContainerIDToEntityName(dt.ContainerID), collectors.HighCardinality)
 	if err != nil {
 		log.Warn(err)
 	} else {
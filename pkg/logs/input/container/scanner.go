@@ -164,7 +164,7 @@ func (s *Scanner) setup() error {
 // returns true if the setup succeeded, false otherwise
 func (s *Scanner) setupTailer(cli *client.Client, container types.Container, source *config.LogSource, tailFromBeginning bool, outputChan chan message.Message) bool {
 	log.Info("Detected container ", container.Image, " - ", s.humanReadableContainerID(container.ID))
-	t := NewDockerTailer(cli, container.ID, source, outputChan)
+	t := NewDockerTailer(cli, NewContainer(container), source, outputChan)
 	var err error
 	if tailFromBeginning {
 		err = t.tailFromBeginning()
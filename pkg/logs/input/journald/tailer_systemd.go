@@ -69,18 +69,28 @@ func (t *Tailer) setup() error {
 
 // seek seeks to the cursor if it is not empty or the end of the journal,
 // returns an error if the operation failed.
+// A cursor which can not be restored, for instance when the journal has been
+// vacuumed or the machine id changed, is ignored and the journal is tailed from its end.
 func (t *Tailer) seek(cursor string) error {
 	if cursor != "" {
-		err := t.journal.SeekCursor(cursor)
-		if err != nil {
-			return err
+		err := t.seekCursor(cursor)
+		if err == nil {
+			return nil
 		}
-		// must skip one entry since the cursor points to the last committed one.
-		_, err = t.journal.NextSkip(1)
+		log.Warnf("Could not restore the cursor of journal %s, tailing it from its end: %s", t.journalPath(), err)
+	}
+	return t.journal.SeekTail()
+}
+
+// seekCursor moves to the entry following the cursor.
+func (t *Tailer) seekCursor(cursor string) error {
+	err := t.journal.SeekCursor(cursor)
+	if err != nil {
 		return err
-	} else {
-		return t.journal.SeekTail()
 	}
+	// must skip one entry since the cursor points to the last committed one.
+	_, err = t.journal.NextSkip(1)
+	return err
 }
 
 // tail tails the journal until a message stop is received.
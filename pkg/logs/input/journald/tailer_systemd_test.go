@@ -138,3 +138,14 @@ func TestSeverity(t *testing.T) {
 			}))
 	}
 }
+
+func TestSeekInvalidCursorTailsFromTheEnd(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{})
+	tailer := NewTailer(source, nil)
+
+	var err error
+	err = tailer.setup()
+	assert.Nil(t, err)
+	err = tailer.seek("not-a-cursor")
+	assert.Nil(t, err)
+}
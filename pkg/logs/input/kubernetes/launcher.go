@@ -0,0 +1,233 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+const scanPeriod = 10 * time.Second
+
+// podLister lists the pods of the node, implemented by the KubeUtil
+type podLister interface {
+	GetLocalPodList() ([]*kubelet.Pod, error)
+}
+
+// Launcher lists the pods of the node from the kubelet and adds a file source
+// for the log files of each of their containers, tailed by the file scanner.
+// The sources are removed when the containers are gone.
+type Launcher struct {
+	sources          *config.LogSources
+	podSources       []*config.LogSource
+	podsPath         string
+	kubeutil         podLister
+	containerSources map[string]*config.LogSource // container entity -> file source
+	stop             chan struct{}
+	isRunning        bool
+}
+
+// New returns a new Launcher.
+func New(sources *config.LogSources) *Launcher {
+	podSources := []*config.LogSource{}
+	for _, source := range sources.GetValidSources() {
+		if source.Config.Type == config.KubernetesType {
+			podSources = append(podSources, source)
+		}
+	}
+	return &Launcher{
+		sources:          sources,
+		podSources:       podSources,
+		podsPath:         config.LogsAgent.GetString("logs_config.k8s_pod_logs_path"),
+		containerSources: make(map[string]*config.LogSource),
+		stop:             make(chan struct{}),
+	}
+}
+
+// Start starts the discovery of the containers if there is a kubernetes source.
+func (l *Launcher) Start() {
+	if len(l.podSources) == 0 {
+		return
+	}
+	kubeutil, err := kubelet.GetKubeUtil()
+	if err != nil {
+		log.Error("Can't tail the pod log files, ", err)
+		for _, source := range l.podSources {
+			source.Status.Error(err)
+		}
+		return
+	}
+	l.kubeutil = kubeutil
+	// the tailers tag the logs with the tags of their containers
+	if err = tagger.Init(); err != nil {
+		log.Warn(err)
+	}
+	l.isRunning = true
+	go l.run()
+}
+
+// Stop stops the discovery of the containers, the files already tailed are
+// released by the file scanner when it stops.
+func (l *Launcher) Stop() {
+	if !l.isRunning {
+		return
+	}
+	l.stop <- struct{}{}
+}
+
+// run lists the pods every scanPeriod until stop
+func (l *Launcher) run() {
+	l.scan()
+	scanTicker := time.NewTicker(scanPeriod)
+	defer scanTicker.Stop()
+	for {
+		select {
+		case <-scanTicker.C:
+			l.scan()
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// scan adds the sources of the new containers and removes the ones of the
+// containers which are gone.
+func (l *Launcher) scan() {
+	pods, err := l.kubeutil.GetLocalPodList()
+	if err != nil {
+		log.Warn("Could not list the pods, not updating the pod log files to tail: ", err)
+		for _, source := range l.podSources {
+			source.Status.Error(err)
+		}
+		return
+	}
+	for _, source := range l.podSources {
+		source.Status.Success()
+	}
+
+	running := make(map[string]bool)
+	for _, pod := range pods {
+		podSource := l.findSource(pod.Metadata.Namespace)
+		if podSource == nil {
+			continue
+		}
+		for _, container := range pod.Status.Containers {
+			if container.ID == "" {
+				// the container is not created yet
+				continue
+			}
+			running[container.ID] = true
+			if _, exists := l.containerSources[container.ID]; exists {
+				continue
+			}
+			source := l.newContainerSource(pod, container, podSource)
+			log.Infof("Detected container %s of the pod %s/%s, tailing %s", container.Name, pod.Metadata.Namespace, pod.Metadata.Name, source.Config.Path)
+			l.containerSources[container.ID] = source
+			podSource.AddInput(source.Name)
+			l.sources.AddSource(source)
+		}
+	}
+
+	for entity, source := range l.containerSources {
+		if running[entity] {
+			continue
+		}
+		delete(l.containerSources, entity)
+		for _, podSource := range l.podSources {
+			podSource.RemoveInput(source.Name)
+		}
+		l.sources.RemoveSource(source)
+	}
+}
+
+// findSource returns the first kubernetes source whose namespace filters match
+// the namespace, nil if there is none.
+func (l *Launcher) findSource(namespace string) *config.LogSource {
+	for _, source := range l.podSources {
+		if len(source.Config.IncludeNamespaces) > 0 && !contains(source.Config.IncludeNamespaces, namespace) {
+			continue
+		}
+		if contains(source.Config.ExcludeNamespaces, namespace) {
+			continue
+		}
+		return source
+	}
+	return nil
+}
+
+// newContainerSource returns the file source of the log files of a container,
+// it inherits the configuration of the kubernetes source. The source and the
+// service default to the short name of the image of the container.
+func (l *Launcher) newContainerSource(pod *kubelet.Pod, container kubelet.ContainerStatus, podSource *config.LogSource) *config.LogSource {
+	image := shortImageName(container.Image)
+	cfg := &config.LogsConfig{
+		Type:            config.FileType,
+		Path:            l.containerLogsPath(pod, container.Name),
+		Identifier:      container.ID,
+		Service:         podSource.Config.Service,
+		Source:          podSource.Config.Source,
+		SourceCategory:  podSource.Config.SourceCategory,
+		Tags:            podSource.Config.Tags,
+		ProcessingRules: podSource.Config.ProcessingRules,
+		// the limits apply to each container
+		MaxLinesPerSecond: podSource.Config.MaxLinesPerSecond,
+		MaxBytesPerSecond: podSource.Config.MaxBytesPerSecond,
+
+		ParseJSON:              podSource.Config.ParseJSON,
+		JSONTagAttributes:      podSource.Config.JSONTagAttributes,
+		JSONMessageAttribute:   podSource.Config.JSONMessageAttribute,
+		JSONTimestampAttribute: podSource.Config.JSONTimestampAttribute,
+	}
+	if cfg.Service == "" {
+		cfg.Service = image
+	}
+	if cfg.Source == "" {
+		cfg.Source = image
+	}
+	source := config.NewLogSource(fmt.Sprintf("%s/%s/%s", pod.Metadata.Namespace, pod.Metadata.Name, container.Name), cfg)
+	source.SetSourceType(config.KubernetesSourceType)
+	source.ParentSource = podSource
+	return source
+}
+
+// containerLogsPath returns the pattern of the log files of a container, the
+// kubelet writes them in `<namespace>_<name>_<uid>/<container>/<restarts>.log`
+// since kubernetes 1.14, and in `<uid>/<container>_<restarts>.log` before.
+func (l *Launcher) containerLogsPath(pod *kubelet.Pod, containerName string) string {
+	podDir := filepath.Join(l.podsPath, fmt.Sprintf("%s_%s_%s", pod.Metadata.Namespace, pod.Metadata.Name, pod.Metadata.UID))
+	if _, err := os.Stat(podDir); err == nil {
+		return filepath.Join(podDir, containerName, "*.log")
+	}
+	return filepath.Join(l.podsPath, pod.Metadata.UID, fmt.Sprintf("%s_*.log", containerName))
+}
+
+// shortImageName returns the name of an image without its repository, tag and digest,
+// e.g. nginx for gcr.io/project/nginx:1.15@sha256:...
+func shortImageName(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	image = image[strings.LastIndex(image, "/")+1:]
+	return strings.SplitN(image, ":", 2)[0]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet
+
+package kubernetes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+type fakePodLister struct {
+	pods []*kubelet.Pod
+}
+
+func (f *fakePodLister) GetLocalPodList() ([]*kubelet.Pod, error) {
+	return f.pods, nil
+}
+
+func newPod(namespace, name, uid string, containers ...kubelet.ContainerStatus) *kubelet.Pod {
+	return &kubelet.Pod{
+		Metadata: kubelet.PodMetadata{Namespace: namespace, Name: name, UID: uid},
+		Status:   kubelet.Status{Containers: containers},
+	}
+}
+
+func TestShortImageName(t *testing.T) {
+	assert.Equal(t, "nginx", shortImageName("nginx"))
+	assert.Equal(t, "nginx", shortImageName("nginx:1.15"))
+	assert.Equal(t, "redis", shortImageName("gcr.io/project/redis:4.0@sha256:abcdef"))
+	assert.Equal(t, "app", shortImageName("localhost:5000/app"))
+}
+
+func TestFindSourceFiltersNamespaces(t *testing.T) {
+	included := config.NewLogSource("included", &config.LogsConfig{Type: config.KubernetesType, IncludeNamespaces: []string{"web"}})
+	excluded := config.NewLogSource("excluded", &config.LogsConfig{Type: config.KubernetesType, ExcludeNamespaces: []string{"kube-system"}})
+	launcher := New(config.NewLogSources([]*config.LogSource{included, excluded}))
+
+	assert.Equal(t, included, launcher.findSource("web"))
+	assert.Equal(t, excluded, launcher.findSource("default"))
+	assert.Nil(t, launcher.findSource("kube-system"))
+}
+
+func TestContainerLogsPath(t *testing.T) {
+	podsPath, err := ioutil.TempDir("", "pods")
+	assert.Nil(t, err)
+	defer os.RemoveAll(podsPath)
+
+	launcher := &Launcher{podsPath: podsPath}
+	pod := newPod("default", "web", "1234")
+	assert.Equal(t, filepath.Join(podsPath, "1234", "nginx_*.log"), launcher.containerLogsPath(pod, "nginx"))
+
+	podDir := filepath.Join(podsPath, "default_web_1234")
+	assert.Nil(t, os.Mkdir(podDir, 0755))
+	assert.Equal(t, filepath.Join(podDir, "nginx", "*.log"), launcher.containerLogsPath(pod, "nginx"))
+}
+
+func TestScanAddsAndRemovesContainerSources(t *testing.T) {
+	podSource := config.NewLogSource("k8s_collect_all", &config.LogsConfig{Type: config.KubernetesType, Tags: []string{"env:prod"}})
+	sources := config.NewLogSources([]*config.LogSource{podSource})
+	added := sources.GetAddedForType(config.FileType)
+	removed := sources.GetRemovedForType(config.FileType)
+
+	lister := &fakePodLister{pods: []*kubelet.Pod{
+		newPod("default", "web", "1234", kubelet.ContainerStatus{Name: "nginx", Image: "nginx:1.15", ID: "docker://abcd"}),
+		newPod("default", "pending", "5678", kubelet.ContainerStatus{Name: "redis", Image: "redis"}),
+	}}
+	launcher := New(sources)
+	launcher.podsPath = "/var/log/pods"
+	launcher.kubeutil = lister
+
+	go launcher.scan()
+	source := <-added
+	assert.Equal(t, "default/web/nginx", source.Name)
+	assert.Equal(t, config.FileType, source.Config.Type)
+	assert.Equal(t, "/var/log/pods/1234/nginx_*.log", source.Config.Path)
+	assert.Equal(t, "docker://abcd", source.Config.Identifier)
+	assert.Equal(t, "nginx", source.Config.Service)
+	assert.Equal(t, "nginx", source.Config.Source)
+	assert.Equal(t, []string{"env:prod"}, source.Config.Tags)
+	assert.Equal(t, config.KubernetesSourceType, source.GetSourceType())
+	assert.Equal(t, "k8s_collect_all", source.ConfigName())
+
+	lister.pods = nil
+	go launcher.scan()
+	assert.Equal(t, source, <-removed)
+}
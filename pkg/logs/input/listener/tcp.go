@@ -6,6 +6,7 @@
 package listener
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 
@@ -31,6 +32,15 @@ func NewTCPListener(pp pipeline.Provider, source *config.LogSource) (*TCPListene
 		source.Status.Error(err)
 		return nil, err
 	}
+	if source.Config.TLSCertFile != "" {
+		tlsConfig, err := buildTLSConfig(source.Config)
+		if err != nil {
+			listener.Close()
+			source.Status.Error(err)
+			return nil, err
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+	}
 	source.Status.Success()
 	connHandler := NewConnectionHandler(pp, source)
 	return &TCPListener{
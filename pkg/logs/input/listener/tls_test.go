@@ -0,0 +1,22 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package listener
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	pipeline "github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+)
+
+func TestTCPListenerFailsWithInvalidCertificate(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.TCPType, Port: 10515, TLSCertFile: "/does/not/exist.pem", TLSKeyFile: "/does/not/exist.key"})
+	_, err := NewTCPListener(pipeline.NewMockProvider(), source)
+	assert.NotNil(t, err)
+	assert.True(t, source.Status.IsError())
+}
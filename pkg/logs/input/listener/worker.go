@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/decoder"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/syslog"
 )
 
 // defaultTimeout represents the time after which a connection is closed when no data is read
@@ -65,11 +66,29 @@ func (w *Worker) forwardMessages() {
 		w.done <- struct{}{}
 	}()
 	for output := range w.decoder.OutputChan {
-		origin := message.NewOrigin(w.source)
-		w.outputChan <- message.New(output.Content, origin, "")
+		w.outputChan <- w.toMessage(output.Content)
 	}
 }
 
+// toMessage returns the message of a line, the syslog lines are parsed to
+// extract their content, status, application and host.
+func (w *Worker) toMessage(content []byte) message.Message {
+	origin := message.NewOrigin(w.source)
+	if w.source.Config.Format != config.SyslogFormat {
+		return message.New(content, origin, "")
+	}
+	msg, err := syslog.Parse(content)
+	if err != nil {
+		// forward the lines which are not syslog messages as they are
+		return message.New(content, origin, "")
+	}
+	origin.SetService(msg.AppName)
+	if msg.Hostname != "" {
+		origin.SetTags([]string{"syslog_hostname:" + msg.Hostname})
+	}
+	return message.New(msg.Content, origin, msg.Status)
+}
+
 // readForever reads the data from conn until timeout or an error occurs
 func (w *Worker) readForever() {
 	defer func() {
@@ -74,3 +74,25 @@ func TestMustKeepConnAlive(t *testing.T) {
 	worker = NewWorker(source, nil, nil)
 	assert.True(t, worker.mustKeepConnAlive())
 }
+
+func TestToMessageParsesSyslogLines(t *testing.T) {
+	var msg message.Message
+
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.UDPType, Format: config.SyslogFormat})
+	worker := NewWorker(source, nil, nil)
+	msg = worker.toMessage([]byte("<11>Feb  5 17:32:18 router myapp[12]: link down"))
+	assert.Equal(t, "link down", string(msg.Content()))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, "myapp", msg.GetOrigin().Service())
+	assert.Contains(t, msg.GetOrigin().Tags(), "syslog_hostname:router")
+
+	// lines which are not syslog messages are forwarded as they are
+	msg = worker.toMessage([]byte("link down"))
+	assert.Equal(t, "link down", string(msg.Content()))
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+
+	source = config.NewLogSource("", &config.LogsConfig{Type: config.UDPType})
+	worker = NewWorker(source, nil, nil)
+	msg = worker.toMessage([]byte("<11>Feb  5 17:32:18 router myapp[12]: link down"))
+	assert.Equal(t, "<11>Feb  5 17:32:18 router myapp[12]: link down", string(msg.Content()))
+}
@@ -46,6 +46,21 @@ func NewFileProvider(sources []*config.LogSource, filesLimit int) *FileProvider
 	}
 }
 
+// addSource adds a source whose files are returned from now on
+func (p *FileProvider) addSource(source *config.LogSource) {
+	p.sources = append(p.sources, source)
+}
+
+// removeSource removes a source whose files are no longer returned
+func (p *FileProvider) removeSource(source *config.LogSource) {
+	for i, src := range p.sources {
+		if src == source {
+			p.sources = append(p.sources[:i], p.sources[i+1:]...)
+			return
+		}
+	}
+}
+
 // FilesToTail returns all the Files matching paths in sources,
 // it cannot return more than filesLimit Files.
 // For now, there is no way to prioritize specific Files over others,
@@ -92,6 +92,21 @@ func (suite *FileProviderTestSuite) TestFilesToTailReturnsAllFilesFromDirectory(
 	suite.Equal(fmt.Sprintf("%s/1/?.log", suite.testDir), files[2].Path)
 }
 
+func (suite *FileProviderTestSuite) TestFilesToTailReturnsFilesOfAddedSources() {
+	fileProvider := suite.newFileProvider(fmt.Sprintf("%s/1/1.log", suite.testDir))
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: fmt.Sprintf("%s/2/1.log", suite.testDir)})
+
+	fileProvider.addSource(source)
+	files := fileProvider.FilesToTail()
+	suite.Equal(2, len(files))
+	suite.Equal(fmt.Sprintf("%s/2/1.log", suite.testDir), files[1].Path)
+
+	fileProvider.removeSource(source)
+	files = fileProvider.FilesToTail()
+	suite.Equal(1, len(files))
+	suite.Equal(fmt.Sprintf("%s/1/1.log", suite.testDir), files[0].Path)
+}
+
 func (suite *FileProviderTestSuite) TestFilesToTailReturnsAllFilesFromAnyDirectoryWithRightPermissions() {
 	path := fmt.Sprintf("%s/*/*1.log", suite.testDir)
 	fileProvider := suite.newFileProvider(path)
@@ -30,13 +30,16 @@ type Scanner struct {
 	tailers             map[string]*Tailer
 	auditor             *auditor.Auditor
 	tailerSleepDuration time.Duration
+	addedSources        chan *config.LogSource
+	removedSources      chan *config.LogSource
 	stop                chan struct{}
 }
 
-// New returns an initialized Scanner
-func New(sources []*config.LogSource, tailingLimit int, pp pipeline.Provider, auditor *auditor.Auditor, tailerSleepDuration time.Duration) *Scanner {
+// New returns an initialized Scanner, tailing the files of the valid sources and
+// of the file sources added at runtime.
+func New(sources *config.LogSources, tailingLimit int, pp pipeline.Provider, auditor *auditor.Auditor, tailerSleepDuration time.Duration) *Scanner {
 	tailSources := []*config.LogSource{}
-	for _, source := range sources {
+	for _, source := range sources.GetValidSources() {
 		switch source.Config.Type {
 		case config.FileType:
 			tailSources = append(tailSources, source)
@@ -50,6 +53,8 @@ func New(sources []*config.LogSource, tailingLimit int, pp pipeline.Provider, au
 		tailers:             make(map[string]*Tailer),
 		auditor:             auditor,
 		tailerSleepDuration: tailerSleepDuration,
+		addedSources:        sources.GetAddedForType(config.FileType),
+		removedSources:      sources.GetRemovedForType(config.FileType),
 		stop:                make(chan struct{}),
 	}
 }
@@ -124,12 +129,17 @@ func (s *Scanner) Stop() {
 	s.cleanup()
 }
 
-// run checks periodically if there are new files to tail and the state of its tailers until stop
+// run checks periodically if there are new files to tail and the state of its tailers until stop,
+// the files of the sources added or removed are tailed or released on the next scan
 func (s *Scanner) run() {
 	scanTicker := time.NewTicker(scanPeriod)
 	defer scanTicker.Stop()
 	for {
 		select {
+		case source := <-s.addedSources:
+			s.fileProvider.addSource(source)
+		case source := <-s.removedSources:
+			s.fileProvider.removeSource(source)
 		case <-scanTicker.C:
 			// check if there are new files to tail, tailers to stop and tailer to restart because of file rotation
 			s.scan()
@@ -211,7 +221,12 @@ func (s *Scanner) didFileRotate(file *File, tailer *Tailer) (bool, error) {
 // returns true if the new tailer is up and running, false if an error occurred
 func (s *Scanner) restartTailerAfterFileRotation(tailer *Tailer, file *File) bool {
 	log.Info("Log rotation happened to ", tailer.path)
-	tailer.StopAfterFileRotation()
+	if tailer.isTruncated() {
+		// the old tailer would read the new content of the file past its offset
+		tailer.StopAfterFileTruncation()
+	} else {
+		tailer.StopAfterFileRotation()
+	}
 	tailer = s.createTailer(file, tailer.outputChan)
 	// force reading file from beginning since it has been log-rotated
 	err := tailer.tailFromBeginning()
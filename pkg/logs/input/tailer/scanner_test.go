@@ -60,7 +60,7 @@ func (suite *ScannerTestSuite) SetupTest() {
 	suite.openFilesLimit = 100
 	suite.sources = []*config.LogSource{config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: suite.testPath})}
 	sleepDuration := 20 * time.Millisecond
-	suite.s = New(suite.sources, suite.openFilesLimit, suite.pp, auditor.New(nil, ""), sleepDuration)
+	suite.s = New(config.NewLogSources(suite.sources), suite.openFilesLimit, suite.pp, auditor.New(nil, ""), sleepDuration)
 	suite.s.setup()
 }
 
@@ -160,6 +160,39 @@ func (suite *ScannerTestSuite) TestScannerScanWithLogRotationCopyTruncate() {
 	suite.Equal("third", string(msg.Content()))
 }
 
+func (suite *ScannerTestSuite) TestScannerScanWithLogRotationCopyTruncateDoesNotDuplicateLines() {
+	s := suite.s
+
+	var err error
+	var msg message.Message
+
+	_, err = suite.testFile.WriteString("hello world\n")
+	suite.Nil(err)
+	msg = <-suite.outputChan
+	suite.Equal("hello world", string(msg.Content()))
+
+	suite.testFile.Truncate(0)
+	suite.testFile.Seek(0, 0)
+	_, err = suite.testFile.WriteString("third\n")
+	suite.Nil(err)
+	s.scan()
+
+	msg = <-suite.outputChan
+	suite.Equal("third", string(msg.Content()))
+
+	// the content written past the offset of the previous tailer must be read once
+	_, err = suite.testFile.WriteString("a line longer than the first one\n")
+	suite.Nil(err)
+	msg = <-suite.outputChan
+	suite.Equal("a line longer than the first one", string(msg.Content()))
+
+	select {
+	case msg = <-suite.outputChan:
+		suite.Fail("unexpected message", string(msg.Content()))
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 func (suite *ScannerTestSuite) TestScannerScanWithFileRemovedAndCreated() {
 	s := suite.s
 	tailerLen := len(s.tailers)
@@ -208,7 +241,7 @@ func TestScannerScanStartNewTailer(t *testing.T) {
 	sources := []*config.LogSource{config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})}
 	openFilesLimit := 2
 	sleepDuration := 20 * time.Millisecond
-	scanner := New(sources, openFilesLimit, mock.NewMockProvider(), auditor.New(nil, ""), sleepDuration)
+	scanner := New(config.NewLogSources(sources), openFilesLimit, mock.NewMockProvider(), auditor.New(nil, ""), sleepDuration)
 
 	// setup scanner
 	scanner.setup()
@@ -260,7 +293,7 @@ func TestScannerScanWithTooManyFiles(t *testing.T) {
 	sources := []*config.LogSource{config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})}
 	openFilesLimit := 2
 	sleepDuration := 20 * time.Millisecond
-	scanner := New(sources, openFilesLimit, mock.NewMockProvider(), auditor.New(nil, ""), sleepDuration)
+	scanner := New(config.NewLogSources(sources), openFilesLimit, mock.NewMockProvider(), auditor.New(nil, ""), sleepDuration)
 
 	// test at setup
 	scanner.setup()
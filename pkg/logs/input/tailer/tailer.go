@@ -18,7 +18,10 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/kubernetes"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 )
 
 // DefaultSleepDuration represents the amount of time the tailer waits before reading new data when no data is received
@@ -26,6 +29,29 @@ const DefaultSleepDuration = 1 * time.Second
 
 const defaultCloseTimeout = 60 * time.Second
 
+// tagsUpdatePeriod is the period of the refresh of the tags of the container the
+// logs come from, for the sources with an identifier
+const tagsUpdatePeriod = 10 * time.Second
+
+// paused is set to stop all the tailers from reading their files, the
+// offsets are kept so they resume where they left off
+var paused int32
+
+// Pause stops all the tailers from reading until Resume is called
+func Pause() {
+	atomic.StoreInt32(&paused, 1)
+}
+
+// Resume restarts the reads of the tailers paused by Pause
+func Resume() {
+	atomic.StoreInt32(&paused, 0)
+}
+
+// IsPaused returns whether the tailers are paused
+func IsPaused() bool {
+	return atomic.LoadInt32(&paused) == 1
+}
+
 // Tailer tails one file and sends messages to an output channel
 type Tailer struct {
 	path     string
@@ -33,6 +59,12 @@ type Tailer struct {
 	file     *os.File
 	tags     []string
 
+	containerTags      []string
+	containerTagsAdded time.Time
+
+	// parser reassembles the lines of the sources of type kubernetes
+	parser kubernetes.Parser
+
 	readOffset    int64
 	decodedOffset int64
 
@@ -81,8 +113,13 @@ func (t *Tailer) tailFromEnd() error {
 	return t.tailFrom(0, io.SeekEnd)
 }
 
-// recoverTailingFrom starts the tailing from the last log line processed
+// recoverTailingFrom starts the tailing from the last log line processed,
+// or from the beginning of the file if it has been truncated or rotated since
 func (t *Tailer) recoverTailing(offset int64) error {
+	if stat, err := os.Stat(t.path); err == nil && stat.Size() < offset {
+		log.Infof("The offset %d of %s is past its end, tailing it from the beginning", offset, t.path)
+		offset = 0
+	}
 	return t.tailFrom(offset, io.SeekStart)
 }
 
@@ -103,6 +140,15 @@ func (t *Tailer) StopAfterFileRotation() {
 	t.source.RemoveInput(t.path)
 }
 
+// StopAfterFileTruncation stops the tailer right away without tracking its
+// offset anymore: the content it did not read has been truncated, and the new
+// content written from the beginning of the file is read by a new tailer.
+func (t *Tailer) StopAfterFileTruncation() {
+	t.didFileRotate = true
+	t.stop <- struct{}{}
+	t.source.RemoveInput(t.path)
+}
+
 // startStopTimer initialises and starts a timer to stop the tailor after the timeout
 func (t *Tailer) startStopTimer() {
 	stopTimer := time.NewTimer(t.closeTimeout)
@@ -149,12 +195,41 @@ func (t *Tailer) forwardMessages() {
 			identifier = ""
 		}
 		t.decodedOffset = offset
+		content, status := output.Content, ""
+		if t.source.GetSourceType() == config.KubernetesSourceType {
+			var ok bool
+			status, content, ok = t.parser.Parse(output.Content)
+			if !ok {
+				continue
+			}
+		}
 		origin := message.NewOrigin(t.source)
 		origin.Identifier = identifier
 		origin.Offset = strconv.FormatInt(offset, 10)
-		origin.SetTags(t.tags)
-		t.outputChan <- message.New(output.Content, origin, "")
+		origin.SetTags(t.buildTags())
+		t.outputChan <- message.New(content, origin, status)
+	}
+}
+
+// buildTags returns the tags of the file, and the ones of the container the logs
+// come from when the source has an identifier, refreshed every tagsUpdatePeriod.
+func (t *Tailer) buildTags() []string {
+	entity := t.source.Config.Identifier
+	if entity == "" {
+		return t.tags
+	}
+	if time.Since(t.containerTagsAdded) > tagsUpdatePeriod {
+		tags, err := tagger.Tag(entity, collectors.HighCardinality)
+		if err != nil {
+			log.Warn(err)
+		} else {
+			t.containerTags = tags
+		}
+		t.containerTagsAdded = time.Now()
 	}
+	tags := make([]string, 0, len(t.tags)+len(t.containerTags))
+	tags = append(tags, t.tags...)
+	return append(tags, t.containerTags...)
 }
 
 func (t *Tailer) incrementReadOffset(n int) {
@@ -51,6 +51,11 @@ func (t *Tailer) readForever() {
 			// stop reading data from file
 			return
 		default:
+			if IsPaused() {
+				// the memory is shed, the data stays in the file
+				t.wait()
+				continue
+			}
 			// keep reading data from file
 			inBuf := make([]byte, 4096)
 			n, err := t.file.Read(inBuf)
@@ -71,12 +76,15 @@ func (t *Tailer) readForever() {
 	}
 }
 
+// checkForRotation returns true if the file has been moved and replaced by a
+// new one, or truncated in place as done by copytruncate
 func (t *Tailer) checkForRotation() (bool, error) {
 	f, err := os.Open(t.path)
 	if err != nil {
 		t.source.Status.Error(err)
 		return false, err
 	}
+	defer f.Close()
 
 	stat1, err := f.Stat()
 	if err != nil {
@@ -92,6 +100,16 @@ func (t *Tailer) checkForRotation() (bool, error) {
 	return inode(stat1) != inode(stat2) || stat1.Size() < t.GetReadOffset(), nil
 }
 
+// isTruncated returns true if the file tailed is shorter than the data read,
+// the file has been truncated in place and it is not a new file.
+func (t *Tailer) isTruncated() bool {
+	stat, err := t.file.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Size() < t.GetReadOffset()
+}
+
 // inode uniquely identifies a file on a filesystem
 func inode(f os.FileInfo) uint64 {
 	s := f.Sys()
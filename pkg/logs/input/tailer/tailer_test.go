@@ -153,6 +153,20 @@ func (suite *TailerTestSuite) TestRecoverTailing() {
 	suite.Equal(len(lines[0])+len(lines[1])+len(lines[2]), int(suite.tl.GetDecodedOffset()))
 }
 
+func (suite *TailerTestSuite) TestRecoverTailingPastTheEndOfFile() {
+	var msg message.Message
+	var err error
+
+	// the file has been truncated since the offset was committed
+	_, err = suite.testFile.WriteString("hello world\n")
+	suite.Nil(err)
+
+	suite.tl.recoverTailing(1024)
+
+	msg = <-suite.outputChan
+	suite.Equal("hello world", string(msg.Content()))
+}
+
 func (suite *TailerTestSuite) TestTailerIdentifier() {
 	suite.tl.tailFromBeginning()
 	suite.Equal(fmt.Sprintf("file:%s/tailer.log", suite.testDir), suite.tl.Identifier())
@@ -82,6 +82,11 @@ func (t *Tailer) readForever() {
 			// stop reading data from file
 			return
 		default:
+			if IsPaused() {
+				// the memory is shed, the data stays in the file
+				t.wait()
+				continue
+			}
 			err := t.readAvailable()
 			if err == io.EOF || os.IsNotExist(err) {
 				t.wait()
@@ -101,3 +106,9 @@ func (t *Tailer) readForever() {
 func (t *Tailer) checkForRotation() (bool, error) {
 	return false, nil
 }
+
+// isTruncated returns false on windows, truncations are handled by the
+// readAvailable method
+func (t *Tailer) isTruncated() bool {
+	return false
+}
@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package windowsevent
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// event represents the XML rendering of an event,
+// for more information, see https://docs.microsoft.com/en-us/windows/desktop/wes/eventschema-schema.
+type event struct {
+	System    eventSystem `xml:"System"`
+	EventData []eventData `xml:"EventData>Data"`
+}
+
+type eventSystem struct {
+	Provider struct {
+		Name string `xml:"Name,attr"`
+	} `xml:"Provider"`
+	EventID     string `xml:"EventID"`
+	Level       string `xml:"Level"`
+	Task        string `xml:"Task"`
+	Opcode      string `xml:"Opcode"`
+	Keywords    string `xml:"Keywords"`
+	TimeCreated struct {
+		SystemTime string `xml:"SystemTime,attr"`
+	} `xml:"TimeCreated"`
+	EventRecordID string `xml:"EventRecordID"`
+	Channel       string `xml:"Channel"`
+	Computer      string `xml:"Computer"`
+}
+
+type eventData struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// parseEvent returns the event of its XML rendering.
+func parseEvent(content []byte) (*event, error) {
+	e := &event{}
+	if err := xml.Unmarshal(content, e); err != nil {
+		return nil, fmt.Errorf("could not parse the event: %s", err)
+	}
+	return e, nil
+}
+
+// getContent returns the fields of the event as a json-string, the rendered
+// message of the event in "message" and the other fields in a "windows_event" attribute.
+// ex:
+//
+//	{
+//	  "message": "The Windows Update service entered the running state.",
+//	  "windows_event": {
+//	    "provider": "Service Control Manager",
+//	    "event_id": "7036",
+//	    "channel": "System",
+//	    "event_data": {
+//	      "param1": "Windows Update",
+//	      ...
+//	    },
+//	    ...
+//	  }
+//	}
+func (e *event) getContent(msg string) []byte {
+	data := make(map[string]string)
+	for i, d := range e.EventData {
+		name := d.Name
+		if name == "" {
+			// the classic events have no names for their data
+			name = fmt.Sprintf("data_%d", i)
+		}
+		data[name] = d.Value
+	}
+	payload := map[string]interface{}{
+		"windows_event": map[string]interface{}{
+			"provider":     e.System.Provider.Name,
+			"event_id":     e.System.EventID,
+			"level":        e.System.Level,
+			"task":         e.System.Task,
+			"opcode":       e.System.Opcode,
+			"keywords":     e.System.Keywords,
+			"time_created": e.System.TimeCreated.SystemTime,
+			"record_id":    e.System.EventRecordID,
+			"channel":      e.System.Channel,
+			"computer":     e.System.Computer,
+			"event_data":   data,
+		},
+	}
+	if msg != "" {
+		payload["message"] = msg
+	}
+	content, err := json.Marshal(payload)
+	if err != nil {
+		// ensure the message has some content if the json encoding failed
+		content = []byte(msg)
+	}
+	return content
+}
+
+// levelStatusMapping represents the mapping between the event levels and statuses.
+var levelStatusMapping = map[string]string{
+	"0": message.StatusInfo, // LogAlways
+	"1": message.StatusCritical,
+	"2": message.StatusError,
+	"3": message.StatusWarning,
+	"4": message.StatusInfo,
+	"5": message.StatusDebug, // Verbose
+}
+
+// getStatus returns the status of the event,
+// returns "info" by default if no valid value is found.
+func (e *event) getStatus() string {
+	status, exists := levelStatusMapping[e.System.Level]
+	if !exists {
+		return message.StatusInfo
+	}
+	return status
+}
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package windowsevent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const testEvent = `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'>
+  <System>
+    <Provider Name='Service Control Manager' Guid='{555908d1-a6d7-4695-8e1e-26931d2012f4}' EventSourceName='Service Control Manager'/>
+    <EventID Qualifiers='16384'>7036</EventID>
+    <Version>0</Version>
+    <Level>4</Level>
+    <Task>0</Task>
+    <Opcode>0</Opcode>
+    <Keywords>0x8080000000000000</Keywords>
+    <TimeCreated SystemTime='2018-06-12T09:16:45.352075100Z'/>
+    <EventRecordID>1913</EventRecordID>
+    <Channel>System</Channel>
+    <Computer>WIN-HOST</Computer>
+  </System>
+  <EventData>
+    <Data Name='param1'>Windows Update</Data>
+    <Data Name='param2'>running</Data>
+  </EventData>
+</Event>`
+
+func TestParseEvent(t *testing.T) {
+	e, err := parseEvent([]byte(testEvent))
+	assert.Nil(t, err)
+	assert.Equal(t, "Service Control Manager", e.System.Provider.Name)
+	assert.Equal(t, "7036", e.System.EventID)
+	assert.Equal(t, "2018-06-12T09:16:45.352075100Z", e.System.TimeCreated.SystemTime)
+	assert.Equal(t, 2, len(e.EventData))
+
+	_, err = parseEvent([]byte("<Event>"))
+	assert.NotNil(t, err)
+}
+
+func TestContent(t *testing.T) {
+	e, err := parseEvent([]byte(testEvent))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"message":"The Windows Update service entered the running state.","windows_event":{"channel":"System","computer":"WIN-HOST","event_data":{"param1":"Windows Update","param2":"running"},"event_id":"7036","keywords":"0x8080000000000000","level":"4","opcode":"0","provider":"Service Control Manager","record_id":"1913","task":"0","time_created":"2018-06-12T09:16:45.352075100Z"}}`,
+		string(e.getContent("The Windows Update service entered the running state.")))
+
+	e = &event{EventData: []eventData{{Value: "foo"}}}
+	assert.Equal(t, `{"windows_event":{"channel":"","computer":"","event_data":{"data_0":"foo"},"event_id":"","keywords":"","level":"","opcode":"","provider":"","record_id":"","task":"","time_created":""}}`,
+		string(e.getContent("")))
+}
+
+func TestStatus(t *testing.T) {
+	levels := []string{"0", "1", "2", "3", "4", "5", "foo"}
+	statuses := []string{message.StatusInfo, message.StatusCritical, message.StatusError, message.StatusWarning, message.StatusInfo, message.StatusDebug, message.StatusInfo}
+
+	for i, level := range levels {
+		e := &event{}
+		e.System.Level = level
+		assert.Equal(t, statuses[i], e.getStatus())
+	}
+}
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package windowsevent
+
+import (
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/restart"
+)
+
+// Launcher is in charge of starting and stopping new windows event tailers
+type Launcher struct {
+	sources          []*config.LogSource
+	pipelineProvider pipeline.Provider
+	tailers          map[string]*Tailer
+}
+
+// New returns a new Launcher.
+func New(sources []*config.LogSource, pipelineProvider pipeline.Provider) *Launcher {
+	windowsEventSources := []*config.LogSource{}
+	for _, source := range sources {
+		if source.Config.Type == config.WindowsEventType {
+			windowsEventSources = append(windowsEventSources, source)
+		}
+	}
+	return &Launcher{
+		sources:          windowsEventSources,
+		pipelineProvider: pipelineProvider,
+		tailers:          make(map[string]*Tailer),
+	}
+}
+
+// Start starts new tailers.
+func (l *Launcher) Start() {
+	for _, source := range l.sources {
+		tailer := NewTailer(source, l.pipelineProvider.NextPipelineChan())
+		if _, exists := l.tailers[tailer.Identifier()]; exists {
+			// set up only one tailer per channel and query
+			continue
+		}
+		if err := tailer.Start(); err != nil {
+			log.Warn("Could not set up windows event tailer: ", err)
+			continue
+		}
+		l.tailers[tailer.Identifier()] = tailer
+	}
+}
+
+// Stop stops all active tailers
+func (l *Launcher) Stop() {
+	stopper := restart.NewParallelStopper()
+	for identifier, tailer := range l.tailers {
+		stopper.Add(tailer)
+		delete(l.tailers, identifier)
+	}
+	stopper.Stop()
+}
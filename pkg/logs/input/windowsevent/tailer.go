@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package windowsevent
+
+import (
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// defaultQuery selects all the events of a channel
+const defaultQuery = "*"
+
+// NewTailer returns a new tailer.
+func NewTailer(source *config.LogSource, outputChan chan message.Message) *Tailer {
+	return &Tailer{
+		source:     source,
+		outputChan: outputChan,
+		stop:       make(chan struct{}, 1),
+		done:       make(chan struct{}, 1),
+	}
+}
+
+// Identifier returns the unique identifier of the channel and query being tailed.
+func (t *Tailer) Identifier() string {
+	return "eventlog:" + t.source.Config.ChannelPath + ";" + t.query()
+}
+
+// Start subscribes to the events of the channel published from now on.
+func (t *Tailer) Start() error {
+	if err := t.subscribe(); err != nil {
+		t.source.Status.Error(err)
+		return err
+	}
+	t.source.Status.Success()
+	t.source.AddInput(t.source.Config.ChannelPath)
+	log.Info("Start tailing windows event log channel ", t.source.Config.ChannelPath)
+	go t.tail()
+	return nil
+}
+
+// Stop stops the tailer
+func (t *Tailer) Stop() {
+	log.Info("Stop tailing windows event log channel ", t.source.Config.ChannelPath)
+	t.stop <- struct{}{}
+	t.source.RemoveInput(t.source.Config.ChannelPath)
+	<-t.done
+}
+
+// query returns the XPath query filtering the events of the channel
+func (t *Tailer) query() string {
+	if t.source.Config.Query != "" {
+		return t.source.Config.Query
+	}
+	return defaultQuery
+}
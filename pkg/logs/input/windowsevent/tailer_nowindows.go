@@ -0,0 +1,34 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+
+package windowsevent
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// Tailer collects logs from a windows event log channel.
+type Tailer struct {
+	source     *config.LogSource
+	outputChan chan message.Message
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// subscribe does nothing
+func (t *Tailer) subscribe() error {
+	return fmt.Errorf("windows event log is not supported on this system")
+}
+
+// tail waits for message stop
+func (t *Tailer) tail() {
+	<-t.stop
+	t.done <- struct{}{}
+}
@@ -0,0 +1,28 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package windowsevent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestIdentifier(t *testing.T) {
+	var tailer *Tailer
+	var source *config.LogSource
+
+	// expect default query
+	source = config.NewLogSource("", &config.LogsConfig{ChannelPath: "System"})
+	tailer = NewTailer(source, nil)
+	assert.Equal(t, "eventlog:System;*", tailer.Identifier())
+
+	source = config.NewLogSource("", &config.LogsConfig{ChannelPath: "Security", Query: "*[System[Level=2]]"})
+	tailer = NewTailer(source, nil)
+	assert.Equal(t, "eventlog:Security;*[System[Level=2]]", tailer.Identifier())
+}
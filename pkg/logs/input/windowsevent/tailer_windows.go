@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build windows
+
+package windowsevent
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	log "github.com/cihub/seelog"
+	"golang.org/x/sys/windows"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+var (
+	modWevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	procEvtSubscribe             = modWevtapi.NewProc("EvtSubscribe")
+	procEvtNext                  = modWevtapi.NewProc("EvtNext")
+	procEvtRender                = modWevtapi.NewProc("EvtRender")
+	procEvtClose                 = modWevtapi.NewProc("EvtClose")
+	procEvtOpenPublisherMetadata = modWevtapi.NewProc("EvtOpenPublisherMetadata")
+	procEvtFormatMessage         = modWevtapi.NewProc("EvtFormatMessage")
+)
+
+const (
+	// taken from winevt.h
+	evtSubscribeToFutureEvents = 1
+	evtRenderEventXML          = 1
+	evtFormatMessageEvent      = 1
+
+	errorInsufficientBuffer syscall.Errno = 122
+	errorNoMoreItems        syscall.Errno = 259
+	errorTimeout            syscall.Errno = 1460
+
+	// eventsBatchSize is the maximum number of events read at once
+	eventsBatchSize = 10
+	// waitTimeout is the delay in milliseconds after which the tailer checks
+	// whether it must stop when no event is published
+	waitTimeout = 1000
+)
+
+// Tailer collects logs from a windows event log channel.
+type Tailer struct {
+	source       *config.LogSource
+	outputChan   chan message.Message
+	signal       windows.Handle
+	subscription uintptr
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+// subscribe creates a subscription to the events of the channel matching the query,
+// the signal is set when new events are available.
+func (t *Tailer) subscribe() error {
+	channelPath, err := windows.UTF16PtrFromString(t.source.Config.ChannelPath)
+	if err != nil {
+		return err
+	}
+	query, err := windows.UTF16PtrFromString(t.query())
+	if err != nil {
+		return err
+	}
+	// auto-reset event, initially set to read the events already pending
+	t.signal, err = windows.CreateEvent(nil, 0, 1, nil)
+	if err != nil {
+		return fmt.Errorf("could not create the subscription signal: %s", err)
+	}
+	subscription, _, err := procEvtSubscribe.Call(
+		uintptr(0), // local computer
+		uintptr(t.signal),
+		uintptr(unsafe.Pointer(channelPath)),
+		uintptr(unsafe.Pointer(query)),
+		uintptr(0), // no bookmark
+		uintptr(0), // no context
+		uintptr(0), // no callback, the events are pulled
+		uintptr(evtSubscribeToFutureEvents))
+	if subscription == 0 {
+		windows.CloseHandle(t.signal)
+		return fmt.Errorf("could not subscribe to the channel %s with the query %s: %s", t.source.Config.ChannelPath, t.query(), err)
+	}
+	t.subscription = subscription
+	return nil
+}
+
+// tail reads the events of the subscription until a message stop is received.
+func (t *Tailer) tail() {
+	defer func() {
+		procEvtClose.Call(t.subscription)
+		windows.CloseHandle(t.signal)
+		t.done <- struct{}{}
+	}()
+	for {
+		select {
+		case <-t.stop:
+			// stop tailing the channel
+			return
+		default:
+			status, err := windows.WaitForSingleObject(t.signal, waitTimeout)
+			if err != nil {
+				err := fmt.Errorf("can't tail the channel %s: %s", t.source.Config.ChannelPath, err)
+				t.source.Status.Error(err)
+				log.Error(err)
+				return
+			}
+			if status != windows.WAIT_OBJECT_0 {
+				// no new event
+				continue
+			}
+			if err := t.readEvents(); err != nil {
+				err := fmt.Errorf("can't tail the channel %s: %s", t.source.Config.ChannelPath, err)
+				t.source.Status.Error(err)
+				log.Error(err)
+				return
+			}
+		}
+	}
+}
+
+// readEvents forwards all the events available on the subscription.
+func (t *Tailer) readEvents() error {
+	events := make([]uintptr, eventsBatchSize)
+	for {
+		var returned uint32
+		r, _, err := procEvtNext.Call(
+			t.subscription,
+			uintptr(eventsBatchSize),
+			uintptr(unsafe.Pointer(&events[0])),
+			uintptr(0), // no timeout
+			uintptr(0),
+			uintptr(unsafe.Pointer(&returned)))
+		if r == 0 {
+			if err == errorNoMoreItems || err == errorTimeout {
+				return nil
+			}
+			return err
+		}
+		for _, handle := range events[:returned] {
+			msg, err := t.toMessage(handle)
+			procEvtClose.Call(handle)
+			if err != nil {
+				log.Warnf("Could not render the event of the channel %s: %s", t.source.Config.ChannelPath, err)
+				continue
+			}
+			t.outputChan <- msg
+		}
+	}
+}
+
+// toMessage transforms an event into a message.
+func (t *Tailer) toMessage(handle uintptr) (message.Message, error) {
+	xml, err := render(handle)
+	if err != nil {
+		return nil, err
+	}
+	e, err := parseEvent(xml)
+	if err != nil {
+		return nil, err
+	}
+	origin := message.NewOrigin(t.source)
+	origin.Identifier = t.Identifier()
+	// the service and the source are still overridden by the integration config when defined
+	origin.SetService(e.System.Provider.Name)
+	origin.SetSource(windowsEventIntegration)
+	return message.New(e.getContent(formatMessage(handle, e.System.Provider.Name)), origin, e.getStatus()), nil
+}
+
+// windowsEventIntegration is the default source of the messages
+const windowsEventIntegration = "windows.events"
+
+// render returns the XML rendering of an event.
+func render(handle uintptr) ([]byte, error) {
+	var used, properties uint32
+	// get the size of the rendering first
+	r, _, err := procEvtRender.Call(uintptr(0), handle, uintptr(evtRenderEventXML), uintptr(0), uintptr(0),
+		uintptr(unsafe.Pointer(&used)), uintptr(unsafe.Pointer(&properties)))
+	if r == 0 && err != errorInsufficientBuffer {
+		return nil, err
+	}
+	buf := make([]uint16, used/2+1)
+	r, _, err = procEvtRender.Call(uintptr(0), handle, uintptr(evtRenderEventXML), uintptr(len(buf)*2), uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(unsafe.Pointer(&used)), uintptr(unsafe.Pointer(&properties)))
+	if r == 0 {
+		return nil, err
+	}
+	return []byte(windows.UTF16ToString(buf)), nil
+}
+
+// formatMessage returns the message of an event rendered from the metadata of
+// its provider, empty if it can not be rendered, for instance when the
+// provider is not installed on the host.
+func formatMessage(handle uintptr, provider string) string {
+	providerName, err := windows.UTF16PtrFromString(provider)
+	if err != nil {
+		return ""
+	}
+	metadata, _, _ := procEvtOpenPublisherMetadata.Call(uintptr(0), uintptr(unsafe.Pointer(providerName)), uintptr(0), uintptr(0), uintptr(0))
+	if metadata == 0 {
+		return ""
+	}
+	defer procEvtClose.Call(metadata)
+
+	var used uint32
+	r, _, err := procEvtFormatMessage.Call(metadata, handle, uintptr(0), uintptr(0), uintptr(0), uintptr(evtFormatMessageEvent),
+		uintptr(0), uintptr(0), uintptr(unsafe.Pointer(&used)))
+	if r == 0 && err != errorInsufficientBuffer {
+		return ""
+	}
+	buf := make([]uint16, used+1)
+	r, _, _ = procEvtFormatMessage.Call(metadata, handle, uintptr(0), uintptr(0), uintptr(0), uintptr(evtFormatMessageEvent),
+		uintptr(len(buf)), uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&used)))
+	if r == 0 {
+		return ""
+	}
+	return windows.UTF16ToString(buf)
+}
@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// maxPartialLen is the length above which the partial lines of a CRI runtime
+// are sent without waiting for the end of the line.
+const maxPartialLen = 256 * 1000
+
+// warningPeriod is the minimum period between two warnings of a parser about
+// the lines it could not parse.
+const warningPeriod = time.Minute
+
+// dockerLine is a line of the json-file logging driver of docker.
+type dockerLine struct {
+	Log    string `json:"log"`
+	Stream string `json:"stream"`
+	Time   string `json:"time"`
+}
+
+// ParseMessage extracts the date, the status and the content of a line of the
+// log files of the pods, written by the container runtime:
+// - docker writes json lines: {"log":"<content>\n","stream":"stdout","time":"<date>"}
+// - the CRI runtimes write `<date> <stream> <tag> <content>`, the tag being F for
+// a full line and P for a partial one, see Parser to reassemble them.
+func ParseMessage(msg []byte) (string, string, []byte, error) {
+	ts, status, content, _, err := parseMessage(msg)
+	return ts, status, content, err
+}
+
+// Parser parses the lines of the log file of a container, it reassembles the
+// partial lines written by the CRI runtimes and rate limits the warnings about
+// the lines it can not parse. A Parser is not safe for concurrent use.
+type Parser struct {
+	partial     []byte
+	lastWarning time.Time
+	skipped     int
+}
+
+// Parse returns the status and the content of a line, ok is false when the line
+// is partial or invalid and there is nothing to send yet.
+func (p *Parser) Parse(msg []byte) (status string, content []byte, ok bool) {
+	_, status, content, partial, err := parseMessage(msg)
+	if err != nil {
+		p.warn(err)
+		return "", nil, false
+	}
+	if partial && len(p.partial)+len(content) < maxPartialLen {
+		p.partial = append(p.partial, content...)
+		return "", nil, false
+	}
+	if len(p.partial) > 0 {
+		content = append(p.partial, content...)
+		p.partial = nil
+	}
+	return status, content, true
+}
+
+// warn logs err at most once every warningPeriod, with the number of lines
+// skipped since the last warning.
+func (p *Parser) warn(err error) {
+	p.skipped++
+	if time.Since(p.lastWarning) < warningPeriod {
+		return
+	}
+	log.Warnf("%s, %d lines skipped", err, p.skipped)
+	p.lastWarning = time.Now()
+	p.skipped = 0
+}
+
+func parseMessage(msg []byte) (string, string, []byte, bool, error) {
+	if len(msg) > 0 && msg[0] == '{' {
+		ts, status, content, err := parseDockerLine(msg)
+		return ts, status, content, false, err
+	}
+	return parseCRILine(msg)
+}
+
+func parseDockerLine(msg []byte) (string, string, []byte, error) {
+	var line dockerLine
+	if err := json.Unmarshal(msg, &line); err != nil {
+		return "", "", nil, errors.New("Can't parse kubernetes message: invalid json line")
+	}
+	content := []byte(line.Log)
+	if n := len(content); n > 0 && content[n-1] == '\n' {
+		content = content[:n-1]
+	}
+	return line.Time, streamToStatus(line.Stream), content, nil
+}
+
+func parseCRILine(msg []byte) (string, string, []byte, bool, error) {
+	components := bytes.SplitN(msg, []byte{' '}, 4)
+	if len(components) < 3 {
+		return "", "", nil, false, errors.New("Can't parse kubernetes message: expected a date, a stream and a tag")
+	}
+	var content []byte
+	if len(components) == 4 {
+		content = components[3]
+	}
+	partial := string(components[2]) == "P"
+	return string(components[0]), streamToStatus(string(components[1])), content, partial, nil
+}
+
+// streamToStatus returns the error status for stderr, info otherwise.
+func streamToStatus(stream string) string {
+	if stream == "stderr" {
+		return message.StatusError
+	}
+	return message.StatusInfo
+}
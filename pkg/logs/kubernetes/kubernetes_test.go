@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestParseCRIMessage(t *testing.T) {
+	ts, status, content, err := ParseMessage([]byte("2018-09-20T11:54:11.753589172Z stderr F an error occurred"))
+	assert.Nil(t, err)
+	assert.Equal(t, "2018-09-20T11:54:11.753589172Z", ts)
+	assert.Equal(t, message.StatusError, status)
+	assert.Equal(t, "an error occurred", string(content))
+
+	_, status, content, err = ParseMessage([]byte("2018-09-20T11:54:11.753589172Z stdout F"))
+	assert.Nil(t, err)
+	assert.Equal(t, message.StatusInfo, status)
+	assert.Equal(t, 0, len(content))
+
+	_, _, _, err = ParseMessage([]byte("2018-09-20T11:54:11.753589172Z stdout"))
+	assert.NotNil(t, err)
+}
+
+func TestParseDockerMessage(t *testing.T) {
+	ts, status, content, err := ParseMessage([]byte(`{"log":"hello \"world\"\n","stream":"stdout","time":"2018-09-20T11:54:11.753589172Z"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "2018-09-20T11:54:11.753589172Z", ts)
+	assert.Equal(t, message.StatusInfo, status)
+	assert.Equal(t, `hello "world"`, string(content))
+
+	_, _, _, err = ParseMessage([]byte(`{"log":"truncated`))
+	assert.NotNil(t, err)
+}
+
+func TestParserReassemblesPartialLines(t *testing.T) {
+	var p Parser
+
+	_, _, ok := p.Parse([]byte("2018-09-20T11:54:11.753589172Z stdout P hello "))
+	assert.False(t, ok)
+	status, content, ok := p.Parse([]byte("2018-09-20T11:54:11.753589172Z stdout F world"))
+	assert.True(t, ok)
+	assert.Equal(t, message.StatusInfo, status)
+	assert.Equal(t, "hello world", string(content))
+
+	_, content, ok = p.Parse([]byte("2018-09-20T11:54:11.753589172Z stdout F next"))
+	assert.True(t, ok)
+	assert.Equal(t, "next", string(content))
+
+	// the invalid lines are skipped
+	_, _, ok = p.Parse([]byte("2018-09-20T11:54:11.753589172Z stdout"))
+	assert.False(t, ok)
+	assert.Equal(t, 0, p.skipped)
+	_, _, ok = p.Parse([]byte("2018-09-20T11:54:11.753589172Z stdout"))
+	assert.False(t, ok)
+	assert.Equal(t, 1, p.skipped)
+}
@@ -9,6 +9,7 @@ import (
 	log "github.com/cihub/seelog"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/input/tailer"
 	"github.com/DataDog/datadog-agent/pkg/logs/status"
 )
 
@@ -26,14 +27,19 @@ func Start() error {
 		// could not parse the configuration
 		return err
 	}
+	processingRules, err := config.GlobalProcessingRules()
+	if err != nil {
+		// the logs must not be sent without the rules scrubbing them
+		return err
+	}
 	log.Info("Starting logs-agent")
 
 	// setup and start the agent
-	agent = NewAgent(sources)
+	agent = NewAgent(sources, processingRules)
 	agent.Start()
 
 	// setup the status
-	status.Initialize(sources.GetSources())
+	status.Initialize(sources)
 
 	isRunning = true
 
@@ -49,6 +55,16 @@ func Stop() {
 	}
 }
 
+// SetPaused pauses or resumes the tailing of the files, the tailers keep
+// their offsets so no log is lost while they are paused
+func SetPaused(pause bool) {
+	if pause {
+		tailer.Pause()
+	} else {
+		tailer.Resume()
+	}
+}
+
 // GetStatus returns logs-agent status
 func GetStatus() status.Status {
 	if !isRunning {
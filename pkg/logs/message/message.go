@@ -5,18 +5,23 @@
 
 package message
 
+import "time"
+
 // Message represents a log line sent to datadog, with its metadata
 type Message interface {
 	Content() []byte
 	SetContent([]byte)
 	GetOrigin() *Origin
 	GetStatus() string
+	GetTimestamp() time.Time
+	SetTimestamp(time.Time)
 }
 
 type message struct {
-	content []byte
-	origin  *Origin
-	status  string
+	content   []byte
+	origin    *Origin
+	status    string
+	timestamp time.Time
 }
 
 // New returns a new Message
@@ -50,3 +55,14 @@ func (m *message) GetOrigin() *Origin {
 func (m *message) GetStatus() string {
 	return m.status
 }
+
+// GetTimestamp returns the time of the message, it is zero when the message
+// does not have a time of its own and is timestamped when it is processed
+func (m *message) GetTimestamp() time.Time {
+	return m.timestamp
+}
+
+// SetTimestamp updates the time of the message
+func (m *message) SetTimestamp(timestamp time.Time) {
+	m.timestamp = timestamp
+}
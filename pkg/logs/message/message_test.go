@@ -7,6 +7,7 @@ package message
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,3 +22,14 @@ func TestMessage(t *testing.T) {
 	assert.Equal(t, StatusInfo, message.GetStatus())
 
 }
+
+func TestMessageTimestamp(t *testing.T) {
+
+	message := New([]byte("hello"), nil, "")
+	assert.True(t, message.GetTimestamp().IsZero())
+
+	timestamp := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	message.SetTimestamp(timestamp)
+	assert.Equal(t, timestamp, message.GetTimestamp())
+
+}
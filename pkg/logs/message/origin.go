@@ -76,6 +76,13 @@ func (o *Origin) SetTags(tags []string) {
 	o.tags = tags
 }
 
+// AddTags adds tags to the ones of the origin.
+func (o *Origin) AddTags(tags ...string) {
+	// the tags set may be shared with the other origins of the input
+	newTags := make([]string, 0, len(o.tags)+len(tags))
+	o.tags = append(append(newTags, o.tags...), tags...)
+}
+
 // SetSource sets the source of the origin.
 func (o *Origin) SetSource(source string) {
 	o.source = source
@@ -101,3 +101,21 @@ func TestDefaultServiceValueIsServiceFromConfig(t *testing.T) {
 	origin.SetService("bar")
 	assert.Equal(t, "bar", origin.Service())
 }
+
+func TestAddTags(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{})
+	tags := make([]string, 1, 10)
+	tags[0] = "foo:bar"
+
+	origin := NewOrigin(source)
+	origin.SetTags(tags)
+	origin.AddTags("level:info")
+	assert.Equal(t, []string{"foo:bar", "level:info"}, origin.Tags())
+
+	// the tags shared with another origin are not modified
+	other := NewOrigin(source)
+	other.SetTags(tags)
+	other.AddTags("level:error")
+	assert.Equal(t, []string{"foo:bar", "level:info"}, origin.Tags())
+	assert.Equal(t, []string{"foo:bar", "level:error"}, other.Tags())
+}
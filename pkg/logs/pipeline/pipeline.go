@@ -6,9 +6,13 @@
 package pipeline
 
 import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/buffer"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/processor"
+	"github.com/DataDog/datadog-agent/pkg/logs/restart"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
 )
 
@@ -16,44 +20,77 @@ import (
 type Pipeline struct {
 	InputChan chan message.Message
 	processor *processor.Processor
-	sender    *sender.Sender
+	buffer    *buffer.DiskBuffer
+	sender    restart.Restartable
 }
 
-// NewPipeline returns a new Pipeline
-func NewPipeline(connManager *sender.ConnectionManager, outputChan chan message.Message) *Pipeline {
+// NewPipeline returns a new Pipeline, the messages are sent to the HTTP intake
+// when httpDestination is set, over TCP with connManager otherwise. The messages
+// are buffered in the directory bufferPath while the sender is blocked, when it is set.
+func NewPipeline(connManager *sender.ConnectionManager, httpDestination *sender.HTTPDestination, processingRules []config.LogsProcessingRule, bufferPath string, outputChan chan message.Message) *Pipeline {
 
 	useProto := config.LogsAgent.GetBool("logs_config.dev_mode_use_proto")
 
+	var encoder processor.Encoder
+	var prefixer processor.Prefixer
+	var messageSender restart.Restartable
+
 	// initialize the sender
 	senderChan := make(chan message.Message, config.ChanSize)
-	delimiter := sender.NewDelimiter(useProto)
-	sender := sender.New(senderChan, outputChan, connManager, delimiter)
+	if httpDestination != nil {
+		// the API key is sent in the headers of the requests
+		encoder = processor.NewJSONEncoder()
+		prefixer = processor.NewNoopPrefixer()
+		batchWait := time.Duration(config.LogsAgent.GetInt("logs_config.batch_wait")) * time.Second
+		batchMaxSize := config.LogsAgent.GetInt("logs_config.batch_max_size")
+		messageSender = sender.NewHTTPSender(senderChan, outputChan, httpDestination, batchWait, batchMaxSize)
+	} else {
+		encoder = processor.NewEncoder(useProto)
+		apikey := config.LogsAgent.GetString("api_key")
+		logset := config.LogsAgent.GetString("logset") // TODO Logset is deprecated and should be removed eventually.
+		prefixer = processor.NewAPIKeyPrefixer(apikey, logset)
+		delimiter := sender.NewDelimiter(useProto)
+		messageSender = sender.New(senderChan, outputChan, connManager, delimiter)
+	}
+
+	// initialize the disk buffer
+	processorChan := senderChan
+	var diskBuffer *buffer.DiskBuffer
+	if bufferPath != "" {
+		processorChan = make(chan message.Message, config.ChanSize)
+		maxSize := config.LogsAgent.GetInt64("logs_config.disk_buffer_max_size")
+		maxAge := time.Duration(config.LogsAgent.GetInt("logs_config.disk_buffer_max_age")) * time.Second
+		diskBuffer = buffer.New(processorChan, senderChan, bufferPath, maxSize, maxAge)
+	}
 
 	// initialize the input chan
 	inputChan := make(chan message.Message, config.ChanSize)
 
 	// initialize the processor
-	encoder := processor.NewEncoder(useProto)
-	apikey := config.LogsAgent.GetString("api_key")
-	logset := config.LogsAgent.GetString("logset") // TODO Logset is deprecated and should be removed eventually.
-	prefixer := processor.NewAPIKeyPrefixer(apikey, logset)
-	processor := processor.New(inputChan, senderChan, encoder, prefixer)
+	processor := processor.New(inputChan, processorChan, processingRules, encoder, prefixer)
 
 	return &Pipeline{
 		InputChan: inputChan,
 		processor: processor,
-		sender:    sender,
+		buffer:    diskBuffer,
+		sender:    messageSender,
 	}
 }
 
 // Start launches the pipeline
 func (p *Pipeline) Start() {
 	p.sender.Start()
+	if p.buffer != nil {
+		p.buffer.Start()
+	}
 	p.processor.Start()
 }
 
 // Stop stops the pipeline
 func (p *Pipeline) Stop() {
 	p.processor.Stop()
+	if p.buffer != nil {
+		p.buffer.Stop()
+	}
 	p.sender.Stop()
 }
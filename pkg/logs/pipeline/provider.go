@@ -6,8 +6,11 @@
 package pipeline
 
 import (
+	"path/filepath"
+	"strconv"
 	"sync/atomic"
 
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
@@ -24,16 +27,21 @@ type Provider interface {
 type provider struct {
 	numberOfPipelines    int
 	connManager          *sender.ConnectionManager
+	httpDestination      *sender.HTTPDestination
+	processingRules      []config.LogsProcessingRule
 	outputChan           chan message.Message
 	pipelines            []*Pipeline
 	currentPipelineIndex int32
 }
 
-// NewProvider returns a new Provider
-func NewProvider(numberOfPipelines int, connManager *sender.ConnectionManager, outputChan chan message.Message) Provider {
+// NewProvider returns a new Provider, its pipelines send the messages to the HTTP
+// intake when httpDestination is set.
+func NewProvider(numberOfPipelines int, connManager *sender.ConnectionManager, httpDestination *sender.HTTPDestination, processingRules []config.LogsProcessingRule, outputChan chan message.Message) Provider {
 	return &provider{
 		numberOfPipelines: numberOfPipelines,
 		connManager:       connManager,
+		httpDestination:   httpDestination,
+		processingRules:   processingRules,
 		outputChan:        outputChan,
 		pipelines:         []*Pipeline{},
 	}
@@ -42,7 +50,12 @@ func NewProvider(numberOfPipelines int, connManager *sender.ConnectionManager, o
 // Start initializes the pipelines
 func (p *provider) Start() {
 	for i := 0; i < p.numberOfPipelines; i++ {
-		pipeline := NewPipeline(p.connManager, p.outputChan)
+		var bufferPath string
+		if config.LogsAgent.GetBool("logs_config.use_disk_buffer") {
+			// each pipeline has its own buffer to keep the order of its messages
+			bufferPath = filepath.Join(config.LogsAgent.GetString("logs_config.run_path"), "buffer", strconv.Itoa(i))
+		}
+		pipeline := NewPipeline(p.connManager, p.httpDestination, p.processingRules, bufferPath, p.outputChan)
 		pipeline.Start()
 		p.pipelines = append(p.pipelines, pipeline)
 	}
@@ -6,6 +6,8 @@
 package processor
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"regexp"
@@ -27,6 +29,10 @@ var rawEncoder raw
 // Proto is an encoder implementation that writes messages as protocol buffers.
 var protoEncoder proto
 
+// JSON is an encoder implementation that writes messages as json objects,
+// used by the HTTP transport.
+var jsonEncoder jsonEncoding
+
 // NewEncoder returns an encoder.
 func NewEncoder(useProto bool) Encoder {
 	if useProto {
@@ -35,6 +41,11 @@ func NewEncoder(useProto bool) Encoder {
 	return &rawEncoder
 }
 
+// NewJSONEncoder returns an encoder writing the messages in the format of the HTTP intake.
+func NewJSONEncoder() Encoder {
+	return &jsonEncoder
+}
+
 var rfc5424Pattern, _ = regexp.Compile("<[0-9]{1,3}>[0-9] ")
 
 type raw struct{}
@@ -56,7 +67,7 @@ func (r *raw) encode(msg message.Message, redactedMsg []byte) ([]byte, error) {
 		extraContent = append(extraContent, ' ')
 
 		// Timestamp
-		extraContent = time.Now().UTC().AppendFormat(extraContent, config.DateFormat)
+		extraContent = getTimestamp(msg).AppendFormat(extraContent, config.DateFormat)
 		extraContent = append(extraContent, ' ')
 
 		extraContent = append(extraContent, []byte(getHostname())...)
@@ -106,7 +117,7 @@ func (p *proto) encode(msg message.Message, redactedMsg []byte) ([]byte, error)
 	return (&pb.Log{
 		Message:   string(redactedMsg),
 		Status:    msg.GetStatus(),
-		Timestamp: time.Now().UTC().UnixNano(),
+		Timestamp: getTimestamp(msg).UnixNano(),
 		Hostname:  getHostname(),
 		Service:   msg.GetOrigin().Service(),
 		Source:    msg.GetOrigin().Source(),
@@ -114,6 +125,42 @@ func (p *proto) encode(msg message.Message, redactedMsg []byte) ([]byte, error)
 	}).Marshal()
 }
 
+type jsonEncoding struct{}
+
+// jsonPayload represents a log in the format of the HTTP intake.
+type jsonPayload struct {
+	Message        string `json:"message"`
+	Status         string `json:"status"`
+	Timestamp      int64  `json:"timestamp"`
+	Hostname       string `json:"hostname"`
+	Service        string `json:"service,omitempty"`
+	Source         string `json:"ddsource,omitempty"`
+	SourceCategory string `json:"ddsourcecategory,omitempty"`
+	Tags           string `json:"ddtags,omitempty"`
+}
+
+func (j *jsonEncoding) encode(msg message.Message, redactedMsg []byte) ([]byte, error) {
+	origin := msg.GetOrigin()
+	return json.Marshal(jsonPayload{
+		Message:        string(redactedMsg),
+		Status:         msg.GetStatus(),
+		Timestamp:      getTimestamp(msg).UnixNano() / int64(time.Millisecond),
+		Hostname:       getHostname(),
+		Service:        origin.Service(),
+		Source:         origin.Source(),
+		SourceCategory: origin.LogSource.Config.SourceCategory,
+		Tags:           strings.Join(origin.Tags(), ","),
+	})
+}
+
+// getTimestamp returns the time of the message, or now when it does not have one.
+func getTimestamp(msg message.Message) time.Time {
+	if timestamp := msg.GetTimestamp(); !timestamp.IsZero() {
+		return timestamp.UTC()
+	}
+	return time.Now().UTC()
+}
+
 // getHostname returns the hostname for the agent.
 func getHostname() string {
 	// Compute the hostname
@@ -6,6 +6,7 @@
 package processor
 
 import (
+	"encoding/json"
 	"testing"
 
 	"strings"
@@ -21,6 +22,7 @@ import (
 func TestNewEncoder(t *testing.T) {
 	assert.Equal(t, &protoEncoder, NewEncoder(true))
 	assert.Equal(t, &rawEncoder, NewEncoder(false))
+	assert.Equal(t, &jsonEncoder, NewJSONEncoder())
 }
 
 func TestRawEncoder(t *testing.T) {
@@ -180,3 +182,74 @@ func TestProtoEncoderEmpty(t *testing.T) {
 	assert.NotEmpty(t, log.Timestamp)
 
 }
+
+func TestJSONEncoder(t *testing.T) {
+
+	logsConfig := &config.LogsConfig{
+		Service:        "Service",
+		Source:         "Source",
+		SourceCategory: "SourceCategory",
+		Tags:           []string{"foo:bar", "baz"},
+	}
+
+	source := config.NewLogSource("", logsConfig)
+
+	rawMessage := "message"
+	msg := newMessage([]byte(rawMessage), source, message.StatusError)
+	msg.GetOrigin().SetTags([]string{"a", "b:c"})
+
+	redactedMessage := "redacted"
+
+	content, err := jsonEncoder.encode(msg, []byte(redactedMessage))
+	assert.Nil(t, err)
+
+	payload := &jsonPayload{}
+	err = json.Unmarshal(content, payload)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "redacted", payload.Message)
+	assert.Equal(t, message.StatusError, payload.Status)
+	assert.NotEmpty(t, payload.Hostname)
+	assert.Equal(t, "Service", payload.Service)
+	assert.Equal(t, "Source", payload.Source)
+	assert.Equal(t, "SourceCategory", payload.SourceCategory)
+	assert.Equal(t, "a,b:c,source:Source,sourcecategory:SourceCategory,foo:bar,baz", payload.Tags)
+	assert.True(t, payload.Timestamp > 0)
+
+}
+
+func TestJSONEncoderDefaults(t *testing.T) {
+
+	source := config.NewLogSource("", &config.LogsConfig{})
+	msg := newMessage([]byte("a"), source, "")
+
+	content, err := jsonEncoder.encode(msg, []byte("a"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(content), "ddtags")
+	assert.NotContains(t, string(content), "service")
+}
+
+func TestEncodersUseTheTimestampOfTheMessage(t *testing.T) {
+
+	source := config.NewLogSource("", &config.LogsConfig{})
+	msg := newMessage([]byte("a"), source, "")
+	timestamp := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	msg.SetTimestamp(timestamp)
+
+	raw, err := rawEncoder.encode(msg, []byte("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, timestamp.Format(config.DateFormat), strings.Fields(string(raw))[1])
+
+	proto, err := protoEncoder.encode(msg, []byte("a"))
+	assert.Nil(t, err)
+	log := &pb.Log{}
+	assert.Nil(t, log.Unmarshal(proto))
+	assert.Equal(t, timestamp.UnixNano(), log.Timestamp)
+
+	content, err := jsonEncoder.encode(msg, []byte("a"))
+	assert.Nil(t, err)
+	payload := &jsonPayload{}
+	assert.Nil(t, json.Unmarshal(content, payload))
+	assert.Equal(t, timestamp.UnixNano()/int64(time.Millisecond), payload.Timestamp)
+
+}
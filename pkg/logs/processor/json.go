@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// millisecondsThreshold is the smallest epoch timestamp considered in milliseconds,
+// the timestamps under it are in seconds
+const millisecondsThreshold = 1e11
+
+// messageAttribute is the attribute of the JSON logs holding their message in
+// the intake
+const messageAttribute = "message"
+
+// parseJSON remaps a message whose content is a JSON object: the attributes
+// configured are added to its tags, its timestamp is replaced by the timestamp
+// attribute and the message attribute is moved to `message`, the other
+// attributes are kept. The messages that are not JSON objects are left unchanged.
+func parseJSON(msg message.Message) {
+	var attributes map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(msg.Content()))
+	// the numbers are kept as is, e.g. the 64 bits trace IDs
+	decoder.UseNumber()
+	if err := decoder.Decode(&attributes); err != nil || attributes == nil || decoder.More() {
+		return
+	}
+	config := msg.GetOrigin().LogSource.Config
+
+	var tags []string
+	for _, name := range config.JSONTagAttributes {
+		if value, found := toString(lookup(attributes, name)); found {
+			tags = append(tags, name+":"+value)
+		}
+	}
+	if len(tags) > 0 {
+		msg.GetOrigin().AddTags(tags...)
+	}
+
+	if config.JSONTimestampAttribute != "" {
+		if timestamp, found := toTime(lookup(attributes, config.JSONTimestampAttribute)); found {
+			msg.SetTimestamp(timestamp)
+		}
+	}
+
+	if config.JSONMessageAttribute != "" && config.JSONMessageAttribute != messageAttribute {
+		if content, found := toString(lookup(attributes, config.JSONMessageAttribute)); found {
+			remove(attributes, config.JSONMessageAttribute)
+			attributes[messageAttribute] = content
+			if remapped, err := marshal(attributes); err == nil {
+				msg.SetContent(remapped)
+			}
+		}
+	}
+}
+
+// marshal returns the JSON of the attributes, without escaping their HTML
+func marshal(attributes map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(attributes); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// remove removes an attribute found by lookup.
+func remove(attributes map[string]interface{}, name string) {
+	if _, exists := attributes[name]; exists {
+		delete(attributes, name)
+		return
+	}
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) < 2 {
+		return
+	}
+	if nested, isObject := attributes[parts[0]].(map[string]interface{}); isObject {
+		remove(nested, parts[1])
+	}
+}
+
+// lookup returns the value of an attribute, the attributes of nested objects
+// are separated with a dot, e.g. `http.status_code`.
+func lookup(attributes map[string]interface{}, name string) interface{} {
+	if value, exists := attributes[name]; exists {
+		return value
+	}
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) < 2 {
+		return nil
+	}
+	nested, isObject := attributes[parts[0]].(map[string]interface{})
+	if !isObject {
+		return nil
+	}
+	return lookup(nested, parts[1])
+}
+
+// toString returns the string representation of the scalar values.
+func toString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return fmt.Sprintf("%t", v), true
+	default:
+		return "", false
+	}
+}
+
+// toTime returns the time of a RFC3339 date or of an epoch timestamp in seconds or milliseconds.
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		timestamp, err := time.Parse(time.RFC3339Nano, v)
+		return timestamp, err == nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		if f >= millisecondsThreshold {
+			return time.Unix(0, int64(f)*int64(time.Millisecond)).UTC(), true
+		}
+		seconds := int64(f)
+		return time.Unix(seconds, int64((f-float64(seconds))*float64(time.Second))).UTC(), true
+	default:
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestParseJSONRemapsAttributes(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{
+		ParseJSON:              true,
+		JSONTagAttributes:      []string{"level", "dd.trace_id", "retries", "missing"},
+		JSONMessageAttribute:   "msg",
+		JSONTimestampAttribute: "time",
+	})
+	msg := newMessage([]byte(`{"level":"warn","msg":"disk almost full","time":"2018-06-01T12:00:00.5Z","dd":{"trace_id":"1234"},"retries":3}`), source, "")
+
+	parseJSON(msg)
+	assert.Equal(t, `{"dd":{"trace_id":"1234"},"level":"warn","message":"disk almost full","retries":3,"time":"2018-06-01T12:00:00.5Z"}`, string(msg.Content()))
+	assert.Equal(t, time.Date(2018, 6, 1, 12, 0, 0, 500000000, time.UTC), msg.GetTimestamp())
+	assert.Equal(t, []string{"level:warn", "dd.trace_id:1234", "retries:3"}, msg.GetOrigin().Tags())
+}
+
+func TestParseJSONKeepsOtherAttributes(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{
+		ParseJSON:            true,
+		JSONTagAttributes:    []string{"dd.trace_id"},
+		JSONMessageAttribute: "log.text",
+	})
+	msg := newMessage([]byte(`{"log":{"text":"<html> & more","file":"app.go"},"dd":{"trace_id":12345678901234567890}}`), source, "")
+
+	parseJSON(msg)
+	assert.Equal(t, `{"dd":{"trace_id":12345678901234567890},"log":{"file":"app.go"},"message":"<html> & more"}`, string(msg.Content()))
+	assert.Equal(t, []string{"dd.trace_id:12345678901234567890"}, msg.GetOrigin().Tags())
+
+	// the content is already in the message attribute of the intake
+	source.Config.JSONMessageAttribute = "message"
+	content := `{"message":"hello","level":"info"}`
+	msg = newMessage([]byte(content), source, "")
+	parseJSON(msg)
+	assert.Equal(t, content, string(msg.Content()))
+}
+
+func TestParseJSONEpochTimestamps(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{ParseJSON: true, JSONTimestampAttribute: "ts"})
+
+	msg := newMessage([]byte(`{"ts":1527854400}`), source, "")
+	parseJSON(msg)
+	assert.Equal(t, time.Unix(1527854400, 0).UTC(), msg.GetTimestamp())
+
+	msg = newMessage([]byte(`{"ts":1527854400123}`), source, "")
+	parseJSON(msg)
+	assert.Equal(t, time.Unix(1527854400, 123000000).UTC(), msg.GetTimestamp())
+
+	msg = newMessage([]byte(`{"ts":"yesterday"}`), source, "")
+	parseJSON(msg)
+	assert.True(t, msg.GetTimestamp().IsZero())
+}
+
+func TestParseJSONLeavesOtherMessagesUnchanged(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{ParseJSON: true, JSONTagAttributes: []string{"level"}, JSONMessageAttribute: "msg"})
+
+	for _, content := range []string{"plain text", `["an", "array"]`, `{"truncated":`, `null`} {
+		msg := newMessage([]byte(content), source, "")
+		parseJSON(msg)
+		assert.Equal(t, content, string(msg.Content()))
+		assert.Equal(t, 0, len(msg.GetOrigin().Tags()))
+	}
+
+	// the content is kept when the message attribute is not a string
+	msg := newMessage([]byte(`{"msg":{"nested":true}}`), source, "")
+	parseJSON(msg)
+	assert.Equal(t, `{"msg":{"nested":true}}`, string(msg.Content()))
+}
@@ -28,3 +28,15 @@ func NewAPIKeyPrefixer(apikey, logset string) Prefixer {
 func (p *apiKeyPrefixer) prefix(content []byte) []byte {
 	return append(p.key, content...)
 }
+
+// noopPrefixer leaves the messages unchanged, the HTTP transport sends the API key in a header.
+type noopPrefixer struct{}
+
+// NewNoopPrefixer returns a prefixer that does not modify the messages.
+func NewNoopPrefixer() Prefixer {
+	return &noopPrefixer{}
+}
+
+func (p *noopPrefixer) prefix(content []byte) []byte {
+	return content
+}
@@ -24,3 +24,10 @@ func TestApiKeyPrefixerScope(t *testing.T) {
 	assert.Equal(t, []byte("foo/bar baz"), prefixer.prefix([]byte("baz")))
 
 }
+
+func TestNoopPrefixer(t *testing.T) {
+
+	prefixer := NewNoopPrefixer()
+	assert.Equal(t, []byte("bar"), prefixer.prefix([]byte("bar")))
+
+}
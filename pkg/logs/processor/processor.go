@@ -6,30 +6,51 @@
 package processor
 
 import (
+	"expvar"
+	"math/rand"
+
 	log "github.com/cihub/seelog"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
+var (
+	// the lines dropped by the sample_at_match rules and the rate limits, by source
+	processorExpvar  = expvar.NewMap("logs-processor")
+	sampledOutLines  = expvar.Map{}
+	rateLimitedLines = expvar.Map{}
+)
+
+func init() {
+	sampledOutLines.Init()
+	rateLimitedLines.Init()
+	processorExpvar.Set("SampledOutLines", &sampledOutLines)
+	processorExpvar.Set("RateLimitedLines", &rateLimitedLines)
+}
+
 // A Processor updates messages from an inputChan and pushes
 // in an outputChan.
 type Processor struct {
-	inputChan  chan message.Message
-	outputChan chan message.Message
-	encoder    Encoder
-	prefixer   Prefixer
-	done       chan struct{}
+	inputChan       chan message.Message
+	outputChan      chan message.Message
+	processingRules []config.LogsProcessingRule
+	encoder         Encoder
+	prefixer        Prefixer
+	done            chan struct{}
 }
 
-// New returns an initialized Processor.
-func New(inputChan, outputChan chan message.Message, encoder Encoder, prefixer Prefixer) *Processor {
+// New returns an initialized Processor, the processing rules are applied to
+// all the messages before the ones of their sources.
+func New(inputChan, outputChan chan message.Message, processingRules []config.LogsProcessingRule, encoder Encoder, prefixer Prefixer) *Processor {
 	return &Processor{
-		inputChan:  inputChan,
-		outputChan: outputChan,
-		encoder:    encoder,
-		prefixer:   prefixer,
-		done:       make(chan struct{}),
+		inputChan:       inputChan,
+		outputChan:      outputChan,
+		processingRules: processingRules,
+		encoder:         encoder,
+		prefixer:        prefixer,
+		done:            make(chan struct{}),
 	}
 }
 
@@ -51,7 +72,15 @@ func (p *Processor) run() {
 		p.done <- struct{}{}
 	}()
 	for msg := range p.inputChan {
-		if shouldProcess, redactedMsg := applyRedactingRules(msg); shouldProcess {
+		if msg.GetOrigin().LogSource.Config.ParseJSON {
+			parseJSON(msg)
+		}
+		if shouldProcess, redactedMsg := p.applyRedactingRules(msg); shouldProcess {
+			if !p.isWithinRateLimit(msg, redactedMsg) {
+				continue
+			}
+			// Copy the message to the clients of the stream-logs command
+			diagnostic.HandleMessage(msg, redactedMsg)
 			// Encode the message to its final format
 			content, err := p.encoder.encode(msg, redactedMsg)
 			if err != nil {
@@ -68,21 +97,39 @@ func (p *Processor) run() {
 
 // applyRedactingRules returns given a message if we should process it or not,
 // and a copy of the message with some fields redacted, depending on config
-func applyRedactingRules(msg message.Message) (bool, []byte) {
+func (p *Processor) applyRedactingRules(msg message.Message) (bool, []byte) {
 	content := msg.Content()
-	for _, rule := range msg.GetOrigin().LogSource.Config.ProcessingRules {
-		switch rule.Type {
-		case config.ExcludeAtMatch:
-			if rule.Reg.Match(content) {
-				return false, nil
-			}
-		case config.IncludeAtMatch:
-			if !rule.Reg.Match(content) {
-				return false, nil
+	// the global rules are applied first
+	for _, rules := range [][]config.LogsProcessingRule{p.processingRules, msg.GetOrigin().LogSource.Config.ProcessingRules} {
+		for _, rule := range rules {
+			switch rule.Type {
+			case config.ExcludeAtMatch:
+				if rule.Reg.Match(content) {
+					return false, nil
+				}
+			case config.IncludeAtMatch:
+				if !rule.Reg.Match(content) {
+					return false, nil
+				}
+			case config.MaskSequences:
+				content = rule.Reg.ReplaceAllLiteral(content, rule.ReplacePlaceholderBytes)
+			case config.SampleAtMatch:
+				if rule.Reg.Match(content) && rand.Float64() >= rule.SampleRate {
+					sampledOutLines.Add(msg.GetOrigin().LogSource.ConfigName(), 1)
+					return false, nil
+				}
 			}
-		case config.MaskSequences:
-			content = rule.Reg.ReplaceAllLiteral(content, rule.ReplacePlaceholderBytes)
 		}
 	}
 	return true, content
 }
+
+// isWithinRateLimit returns true if the message can be sent within the limits of its source.
+func (p *Processor) isWithinRateLimit(msg message.Message, redactedMsg []byte) bool {
+	source := msg.GetOrigin().LogSource
+	if source.RateLimiter == nil || source.RateLimiter.Allow(len(redactedMsg)) {
+		return true
+	}
+	rateLimitedLines.Add(source.ConfigName(), 1)
+	return false
+}
@@ -6,6 +6,7 @@
 package processor
 
 import (
+	"fmt"
 	"regexp"
 	"testing"
 
@@ -33,51 +34,54 @@ func newMessage(content []byte, source *config.LogSource, status string) message
 }
 
 func TestExclusion(t *testing.T) {
+	p := &Processor{}
 
 	var shouldProcess bool
 	var redactedMessage []byte
 
 	source := buildTestConfigLogSource("exclude_at_match", "", "world")
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("hello"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("hello"), &source, ""))
 	assert.Equal(t, true, shouldProcess)
 	assert.Equal(t, []byte("hello"), redactedMessage)
 
-	shouldProcess, _ = applyRedactingRules(newMessage([]byte("world"), &source, ""))
+	shouldProcess, _ = p.applyRedactingRules(newMessage([]byte("world"), &source, ""))
 	assert.Equal(t, false, shouldProcess)
 
-	shouldProcess, _ = applyRedactingRules(newMessage([]byte("a brand new world"), &source, ""))
+	shouldProcess, _ = p.applyRedactingRules(newMessage([]byte("a brand new world"), &source, ""))
 	assert.Equal(t, false, shouldProcess)
 
 	source = buildTestConfigLogSource("exclude_at_match", "", "$world")
-	shouldProcess, _ = applyRedactingRules(newMessage([]byte("a brand new world"), &source, ""))
+	shouldProcess, _ = p.applyRedactingRules(newMessage([]byte("a brand new world"), &source, ""))
 	assert.Equal(t, true, shouldProcess)
 }
 
 func TestInclusion(t *testing.T) {
+	p := &Processor{}
 
 	var shouldProcess bool
 	var redactedMessage []byte
 
 	source := buildTestConfigLogSource("include_at_match", "", "world")
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("hello"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("hello"), &source, ""))
 	assert.Equal(t, false, shouldProcess)
 	assert.Nil(t, redactedMessage)
 
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("world"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("world"), &source, ""))
 	assert.Equal(t, true, shouldProcess)
 	assert.Equal(t, []byte("world"), redactedMessage)
 
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("a brand new world"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("a brand new world"), &source, ""))
 	assert.Equal(t, true, shouldProcess)
 	assert.Equal(t, []byte("a brand new world"), redactedMessage)
 
 	source = buildTestConfigLogSource("include_at_match", "", "^world")
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("a brand new world"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("a brand new world"), &source, ""))
 	assert.Equal(t, false, shouldProcess)
 	assert.Nil(t, redactedMessage)
 }
 
 func TestExclusionWithInclusion(t *testing.T) {
+	p := &Processor{}
 
 	var shouldProcess bool
 	var redactedMessage []byte
@@ -98,53 +102,133 @@ func TestExclusionWithInclusion(t *testing.T) {
 	}
 	source := config.LogSource{Config: &config.LogsConfig{ProcessingRules: []config.LogsProcessingRule{eRule, iRule}}}
 
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("bob@datadoghq.com"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("bob@datadoghq.com"), &source, ""))
 	assert.Equal(t, false, shouldProcess)
 	assert.Nil(t, redactedMessage)
 
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("bill@datadoghq.com"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("bill@datadoghq.com"), &source, ""))
 	assert.Equal(t, true, shouldProcess)
 	assert.Equal(t, []byte("bill@datadoghq.com"), redactedMessage)
 
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("bob@amail.com"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("bob@amail.com"), &source, ""))
 	assert.Equal(t, false, shouldProcess)
 	assert.Nil(t, redactedMessage)
 
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("bill@amail.com"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("bill@amail.com"), &source, ""))
 	assert.Equal(t, false, shouldProcess)
 	assert.Nil(t, redactedMessage)
 }
 
 func TestMask(t *testing.T) {
+	p := &Processor{}
 
 	var shouldProcess bool
 	var redactedMessage []byte
 
 	source := buildTestConfigLogSource("mask_sequences", "[masked_world]", "world")
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("hello"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("hello"), &source, ""))
 	assert.Equal(t, true, shouldProcess)
 	assert.Equal(t, []byte("hello"), redactedMessage)
 
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("hello world!"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("hello world!"), &source, ""))
 	assert.Equal(t, true, shouldProcess)
 	assert.Equal(t, []byte("hello [masked_world]!"), redactedMessage)
 
 	source = buildTestConfigLogSource("mask_sequences", "[masked_user]", "User=\\w+@datadoghq.com")
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("new test launched by User=beats@datadoghq.com on localhost"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("new test launched by User=beats@datadoghq.com on localhost"), &source, ""))
 	assert.Equal(t, true, shouldProcess)
 	assert.Equal(t, []byte("new test launched by [masked_user] on localhost"), redactedMessage)
 
 	source = buildTestConfigLogSource("mask_sequences", "[masked_credit_card]", "(?:4[0-9]{12}(?:[0-9]{3})?|[25][1-7][0-9]{14}|6(?:011|5[0-9][0-9])[0-9]{12}|3[47][0-9]{13}|3(?:0[0-5]|[68][0-9])[0-9]{11}|(?:2131|1800|35\\d{3})\\d{11})")
-	shouldProcess, redactedMessage = applyRedactingRules(newMessage([]byte("The credit card 4323124312341234 was used to buy some time"), &source, ""))
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("The credit card 4323124312341234 was used to buy some time"), &source, ""))
 	assert.Equal(t, true, shouldProcess)
 	assert.Equal(t, []byte("The credit card [masked_credit_card] was used to buy some time"), redactedMessage)
 }
 
 func TestTruncate(t *testing.T) {
+	p := &Processor{}
 
 	source := config.NewLogSource("", &config.LogsConfig{})
 	var redactedMessage []byte
 
-	_, redactedMessage = applyRedactingRules(newMessage([]byte("hello"), source, ""))
+	_, redactedMessage = p.applyRedactingRules(newMessage([]byte("hello"), source, ""))
 	assert.Equal(t, []byte("hello"), redactedMessage)
 }
+
+func TestGlobalRulesAreAppliedBeforeSourceRules(t *testing.T) {
+	globalSource := buildTestConfigLogSource("mask_sequences", "[masked_email]", "[a-z]+@datadoghq.com")
+	p := &Processor{processingRules: globalSource.Config.ProcessingRules}
+
+	var shouldProcess bool
+	var redactedMessage []byte
+
+	source := buildTestConfigLogSource("exclude_at_match", "", "bob@datadoghq.com")
+	shouldProcess, redactedMessage = p.applyRedactingRules(newMessage([]byte("hello bob@datadoghq.com"), &source, ""))
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte("hello [masked_email]"), redactedMessage)
+
+	source = buildTestConfigLogSource("exclude_at_match", "", "masked_email")
+	shouldProcess, _ = p.applyRedactingRules(newMessage([]byte("hello bob@datadoghq.com"), &source, ""))
+	assert.Equal(t, false, shouldProcess)
+}
+
+func TestSampling(t *testing.T) {
+	p := &Processor{}
+
+	rule := config.LogsProcessingRule{
+		Type:       "sample_at_match",
+		Name:       "sample_debug",
+		Pattern:    "DEBUG",
+		SampleRate: 0.5,
+		Reg:        regexp.MustCompile("DEBUG"),
+	}
+	source := config.LogSource{Name: "sampled", Config: &config.LogsConfig{ProcessingRules: []config.LogsProcessingRule{rule}}}
+
+	// the lines not matching are all kept
+	for i := 0; i < 100; i++ {
+		shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("INFO hello"), &source, ""))
+		assert.Equal(t, true, shouldProcess)
+	}
+
+	kept := 0
+	for i := 0; i < 10000; i++ {
+		if shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("DEBUG hello"), &source, "")); shouldProcess {
+			kept++
+		}
+	}
+	assert.True(t, kept > 4000 && kept < 6000)
+	assert.Equal(t, fmt.Sprintf("%d", 10000-kept), sampledOutLines.Get("sampled").String())
+}
+
+func TestRateLimit(t *testing.T) {
+	p := &Processor{}
+
+	source := config.NewLogSource("limited", &config.LogsConfig{MaxLinesPerSecond: 10})
+	sent := 0
+	for i := 0; i < 100; i++ {
+		if p.isWithinRateLimit(newMessage([]byte("hello"), source, ""), []byte("hello")) {
+			sent++
+		}
+	}
+	// the lines may be sent over two seconds
+	assert.True(t, sent >= 10 && sent <= 20)
+	assert.Equal(t, fmt.Sprintf("%d", 100-sent), rateLimitedLines.Get("limited").String())
+
+	// the lines of the sources created at runtime are counted with their parent
+	child := config.NewLogSource("default/web/nginx", &config.LogsConfig{MaxLinesPerSecond: 1})
+	child.ParentSource = source
+	limited := 100 - sent
+	for i := 0; i < 10; i++ {
+		if !p.isWithinRateLimit(newMessage([]byte("hello"), child, ""), []byte("hello")) {
+			limited++
+		}
+	}
+	assert.Nil(t, rateLimitedLines.Get("default/web/nginx"))
+	assert.Equal(t, fmt.Sprintf("%d", limited), rateLimitedLines.Get("limited").String())
+
+	// the sources without limit are not limited
+	source = config.NewLogSource("unlimited", &config.LogsConfig{})
+	for i := 0; i < 20; i++ {
+		assert.True(t, p.isWithinRateLimit(newMessage([]byte("hello"), source, ""), []byte("hello")))
+	}
+}
@@ -10,6 +10,12 @@ type Startable interface {
 	Start()
 }
 
+// Restartable represents a startable and stoppable object
+type Restartable interface {
+	Startable
+	Stoppable
+}
+
 // Start starts all components in series
 func Start(components ...Startable) {
 	for _, component := range components {
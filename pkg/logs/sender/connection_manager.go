@@ -110,10 +110,16 @@ func (cm *ConnectionManager) handleServerClose(conn net.Conn) {
 
 // backoff lets the connection mananger sleep a bit
 func (cm *ConnectionManager) backoff() {
-	backoffDuration := backoffSleepTimeUnit * cm.retries
+	timer := time.NewTimer(backoffDuration(cm.retries))
+	<-timer.C
+}
+
+// backoffDuration returns the delay before the next attempt, it grows with the
+// number of retries up to maxBackoffSleepTime.
+func backoffDuration(retries int) time.Duration {
+	backoffDuration := backoffSleepTimeUnit * retries
 	if backoffDuration > maxBackoffSleepTime {
 		backoffDuration = maxBackoffSleepTime
 	}
-	timer := time.NewTimer(time.Second * time.Duration(backoffDuration))
-	<-timer.C
+	return time.Second * time.Duration(backoffDuration)
 }
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package sender
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+// errClient is returned when the intake rejects a batch, such a batch must not be retried.
+type errClient struct {
+	statusCode int
+	body       string
+}
+
+func (e *errClient) Error() string {
+	return fmt.Sprintf("the intake rejected the batch with the status code %d: %s", e.statusCode, e.body)
+}
+
+// An HTTPDestination posts batches of logs to the HTTP intake.
+type HTTPDestination struct {
+	url              string
+	apiKey           string
+	useCompression   bool
+	compressionLevel int
+	client           *http.Client
+}
+
+// NewHTTPDestination returns a destination posting to the HTTP intake served on host:port,
+// it uses the proxy and the TLS settings of the agent.
+func NewHTTPDestination(host string, port int, useSSL bool, apiKey string, useCompression bool, compressionLevel int) *HTTPDestination {
+	scheme := "https"
+	if !useSSL {
+		scheme = "http"
+	}
+	if compressionLevel < gzip.BestSpeed || compressionLevel > gzip.BestCompression {
+		compressionLevel = gzip.DefaultCompression
+	}
+	return &HTTPDestination{
+		url:              fmt.Sprintf("%s://%s:%d/v1/input", scheme, host, port),
+		apiKey:           apiKey,
+		useCompression:   useCompression,
+		compressionLevel: compressionLevel,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: util.CreateHTTPTransport(),
+		},
+	}
+}
+
+// Send posts a payload, it returns an errClient when the payload must not be retried.
+func (d *HTTPDestination) Send(payload []byte) error {
+	body, err := d.encode(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", d.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("DD-API-KEY", d.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	if d.useCompression {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	response, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		// the intake is unavailable, the batch is retried
+		return fmt.Errorf("the intake is unavailable, status code %d: %s", resp.StatusCode, response)
+	case resp.StatusCode >= 400:
+		return &errClient{statusCode: resp.StatusCode, body: string(response)}
+	}
+	return nil
+}
+
+// encode compresses the payload with gzip when the compression is enabled.
+func (d *HTTPDestination) encode(payload []byte) ([]byte, error) {
+	if !d.useCompression {
+		return payload, nil
+	}
+	var buf bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buf, d.compressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package sender
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	log "github.com/cihub/seelog"
+)
+
+// defaultBatchWait is the delay after which a batch is sent when batch_wait is not valid
+const defaultBatchWait = 5 * time.Second
+
+// maxBatchContentSize is the maximum size of the uncompressed payload accepted by the HTTP intake
+const maxBatchContentSize = 5 * 1024 * 1024
+
+// An HTTPSender sends messages from an inputChan to datadog's HTTP intake,
+// in batches sent when they are full or after batchWait, and retried with a
+// backoff while the intake is unreachable and the sender is not stopped.
+type HTTPSender struct {
+	inputChan    chan message.Message
+	outputChan   chan message.Message
+	destination  *HTTPDestination
+	batchWait    time.Duration
+	batchMaxSize int
+	batch        []message.Message
+	contentSize  int
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+// NewHTTPSender returns an initialized HTTPSender
+func NewHTTPSender(inputChan, outputChan chan message.Message, destination *HTTPDestination, batchWait time.Duration, batchMaxSize int) *HTTPSender {
+	if batchWait <= 0 {
+		batchWait = defaultBatchWait
+	}
+	return &HTTPSender{
+		inputChan:    inputChan,
+		outputChan:   outputChan,
+		destination:  destination,
+		batchWait:    batchWait,
+		batchMaxSize: batchMaxSize,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start starts the HTTPSender
+func (s *HTTPSender) Start() {
+	go s.run()
+}
+
+// Stop stops the HTTPSender,
+// this call blocks until inputChan is flushed and the last batch is sent,
+// the batches are not retried anymore once the sender is stopped.
+func (s *HTTPSender) Stop() {
+	close(s.stop)
+	close(s.inputChan)
+	<-s.done
+}
+
+// run batches the messages and sends them
+func (s *HTTPSender) run() {
+	defer func() {
+		s.done <- struct{}{}
+	}()
+	ticker := time.NewTicker(s.batchWait)
+	defer ticker.Stop()
+	for {
+		select {
+		case payload, isOpen := <-s.inputChan:
+			if !isOpen {
+				// the sender is stopped, send the remaining messages
+				s.flush()
+				return
+			}
+			if len(s.batch) > 0 && s.contentSize+len(payload.Content())+1 > maxBatchContentSize {
+				s.flush()
+			}
+			s.batch = append(s.batch, payload)
+			s.contentSize += len(payload.Content()) + 1
+			if len(s.batch) >= s.batchMaxSize {
+				s.flush()
+			}
+		case <-ticker.C:
+			s.flush()
+		}
+	}
+}
+
+// flush sends the current batch, then forwards its messages to the outputChan.
+// The messages of a batch that could not be sent before the sender stopped are
+// not forwarded, so their offsets are not committed and they are sent again
+// after a restart.
+func (s *HTTPSender) flush() {
+	if len(s.batch) == 0 {
+		return
+	}
+	if s.send(s.toPayload(s.batch)) {
+		for _, msg := range s.batch {
+			s.outputChan <- msg
+		}
+	}
+	s.batch = nil
+	s.contentSize = 0
+}
+
+// send posts a payload until it is accepted, or dropped when it is rejected by
+// the intake, and returns false when the sender is stopped before.
+func (s *HTTPSender) send(payload []byte) bool {
+	for retries := 1; ; retries++ {
+		err := s.destination.Send(payload)
+		if err == nil {
+			return true
+		}
+		if _, isClientError := err.(*errClient); isClientError {
+			log.Errorf("Could not send the logs, dropping them: %s", err)
+			return true
+		}
+		log.Warnf("Could not send the logs, retrying: %s", err)
+		timer := time.NewTimer(backoffDuration(retries))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			log.Warn("The sender is stopped, the logs are not retried")
+			return false
+		case <-timer.C:
+		}
+	}
+}
+
+// toPayload returns the json array of the encoded messages.
+func (s *HTTPSender) toPayload(messages []message.Message) []byte {
+	var payload bytes.Buffer
+	payload.WriteByte('[')
+	for i, msg := range messages {
+		if i > 0 {
+			payload.WriteByte(',')
+		}
+		payload.Write(msg.Content())
+	}
+	payload.WriteByte(']')
+	return payload.Bytes()
+}
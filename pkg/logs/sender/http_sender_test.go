@@ -0,0 +1,178 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package sender
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// request represents a request received by the test intake
+type request struct {
+	apiKey  string
+	payload string
+}
+
+// newTestIntake returns an intake answering with the given status codes in order,
+// then with 200, and the channel of the requests it receives.
+func newTestIntake(statusCodes ...int) (*httptest.Server, chan request) {
+	requests := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = reader
+		}
+		payload, _ := ioutil.ReadAll(body)
+		requests <- request{apiKey: r.Header.Get("DD-API-KEY"), payload: string(payload)}
+		if len(statusCodes) > 0 {
+			w.WriteHeader(statusCodes[0])
+			statusCodes = statusCodes[1:]
+		}
+	}))
+	return server, requests
+}
+
+func newTestDestination(url string, useCompression bool) *HTTPDestination {
+	return &HTTPDestination{
+		url:              url,
+		apiKey:           "foo",
+		useCompression:   useCompression,
+		compressionLevel: gzip.DefaultCompression,
+		client:           &http.Client{Timeout: time.Second},
+	}
+}
+
+func newTestMessage(content string) message.Message {
+	origin := message.NewOrigin(config.NewLogSource("", &config.LogsConfig{}))
+	return message.New([]byte(content), origin, "")
+}
+
+func TestNewHTTPDestination(t *testing.T) {
+	destination := NewHTTPDestination("foo", 443, true, "bar", true, 6)
+	assert.Equal(t, "https://foo:443/v1/input", destination.url)
+	assert.Equal(t, 6, destination.compressionLevel)
+
+	destination = NewHTTPDestination("foo", 8080, false, "bar", true, 12)
+	assert.Equal(t, "http://foo:8080/v1/input", destination.url)
+	assert.Equal(t, gzip.DefaultCompression, destination.compressionLevel)
+}
+
+func TestHTTPSenderSendsFullBatches(t *testing.T) {
+	server, requests := newTestIntake()
+	defer server.Close()
+
+	inputChan := make(chan message.Message, 10)
+	outputChan := make(chan message.Message, 10)
+	sender := NewHTTPSender(inputChan, outputChan, newTestDestination(server.URL, true), time.Hour, 2)
+	sender.Start()
+
+	inputChan <- newTestMessage(`{"message":"a"}`)
+	inputChan <- newTestMessage(`{"message":"b"}`)
+	inputChan <- newTestMessage(`{"message":"c"}`)
+
+	req := <-requests
+	assert.Equal(t, "foo", req.apiKey)
+	assert.Equal(t, `[{"message":"a"},{"message":"b"}]`, req.payload)
+	assert.Equal(t, `{"message":"a"}`, string((<-outputChan).Content()))
+	assert.Equal(t, `{"message":"b"}`, string((<-outputChan).Content()))
+
+	// the last batch is sent when the sender stops
+	sender.Stop()
+	req = <-requests
+	assert.Equal(t, `[{"message":"c"}]`, req.payload)
+	assert.Equal(t, `{"message":"c"}`, string((<-outputChan).Content()))
+}
+
+func TestHTTPSenderSendsBatchesAfterBatchWait(t *testing.T) {
+	server, requests := newTestIntake()
+	defer server.Close()
+
+	inputChan := make(chan message.Message, 10)
+	outputChan := make(chan message.Message, 10)
+	sender := NewHTTPSender(inputChan, outputChan, newTestDestination(server.URL, false), 10*time.Millisecond, 100)
+	sender.Start()
+	defer sender.Stop()
+
+	inputChan <- newTestMessage(`{"message":"a"}`)
+	req := <-requests
+	assert.Equal(t, `[{"message":"a"}]`, req.payload)
+	assert.Equal(t, `{"message":"a"}`, string((<-outputChan).Content()))
+}
+
+func TestHTTPSenderRetriesUnavailableIntake(t *testing.T) {
+	server, requests := newTestIntake(http.StatusServiceUnavailable)
+	defer server.Close()
+
+	inputChan := make(chan message.Message, 10)
+	outputChan := make(chan message.Message, 10)
+	sender := NewHTTPSender(inputChan, outputChan, newTestDestination(server.URL, true), time.Hour, 1)
+	sender.Start()
+	defer sender.Stop()
+
+	inputChan <- newTestMessage(`{"message":"a"}`)
+	assert.Equal(t, `[{"message":"a"}]`, (<-requests).payload)
+	// the batch is sent again after the backoff
+	assert.Equal(t, `[{"message":"a"}]`, (<-requests).payload)
+	assert.Equal(t, `{"message":"a"}`, string((<-outputChan).Content()))
+}
+
+func TestHTTPSenderDropsRejectedBatches(t *testing.T) {
+	server, requests := newTestIntake(http.StatusForbidden)
+	defer server.Close()
+
+	inputChan := make(chan message.Message, 10)
+	outputChan := make(chan message.Message, 10)
+	sender := NewHTTPSender(inputChan, outputChan, newTestDestination(server.URL, true), time.Hour, 1)
+	sender.Start()
+	defer sender.Stop()
+
+	inputChan <- newTestMessage(`{"message":"a"}`)
+	inputChan <- newTestMessage(`{"message":"b"}`)
+	assert.Equal(t, `[{"message":"a"}]`, (<-requests).payload)
+	assert.Equal(t, `{"message":"a"}`, string((<-outputChan).Content()))
+	assert.Equal(t, `[{"message":"b"}]`, (<-requests).payload)
+	assert.Equal(t, `{"message":"b"}`, string((<-outputChan).Content()))
+}
+
+func TestHTTPSenderStopsRetrying(t *testing.T) {
+	server, requests := newTestIntake(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	inputChan := make(chan message.Message, 10)
+	outputChan := make(chan message.Message, 10)
+	sender := NewHTTPSender(inputChan, outputChan, newTestDestination(server.URL, true), time.Hour, 1)
+	sender.Start()
+
+	inputChan <- newTestMessage(`{"message":"a"}`)
+	assert.Equal(t, `[{"message":"a"}]`, (<-requests).payload)
+
+	// the batch is not retried anymore, nor forwarded to the auditor
+	stopped := make(chan struct{})
+	go func() {
+		sender.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the sender did not stop")
+	}
+	assert.Len(t, outputChan, 0)
+}
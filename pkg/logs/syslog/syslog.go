@@ -0,0 +1,170 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// Message represents the fields of a syslog message.
+type Message struct {
+	Timestamp string
+	Hostname  string
+	AppName   string
+	Status    string
+	Content   []byte
+}
+
+// rfc3164TimestampLength is the length of a timestamp like `Jan  2 15:04:05`
+const rfc3164TimestampLength = 15
+
+// nilValue is the value of the empty fields of a RFC5424 message
+const nilValue = "-"
+
+// utf8BOM may prefix the content of a RFC5424 message
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// errInvalidPriority is returned for the messages not starting with a priority
+var errInvalidPriority = errors.New("Can't parse syslog message: invalid priority")
+
+// severityStatusMapping represents the 1:1 mapping between syslog severities and statuses.
+var severityStatusMapping = []string{
+	message.StatusEmergency,
+	message.StatusAlert,
+	message.StatusCritical,
+	message.StatusError,
+	message.StatusWarning,
+	message.StatusNotice,
+	message.StatusInfo,
+	message.StatusDebug,
+}
+
+// Parse extracts the fields of a syslog message, in the RFC5424 format
+// `<PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG`
+// or in the RFC3164 (BSD) one `<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG`.
+// The status of the message is mapped from the severity of its priority.
+func Parse(msg []byte) (Message, error) {
+	status, rest, err := parsePriority(msg)
+	if err != nil {
+		return Message{}, err
+	}
+	if len(rest) > 1 && rest[0] >= '1' && rest[0] <= '9' && rest[1] == ' ' {
+		return parseRFC5424(status, rest[2:])
+	}
+	return parseRFC3164(status, rest)
+}
+
+// parsePriority returns the status of the priority prefixing the message and
+// the remaining bytes.
+func parsePriority(msg []byte) (string, []byte, error) {
+	if len(msg) < 3 || msg[0] != '<' {
+		return "", nil, errInvalidPriority
+	}
+	end := bytes.IndexByte(msg, '>')
+	if end < 2 || end > 4 {
+		return "", nil, errInvalidPriority
+	}
+	priority, err := strconv.Atoi(string(msg[1:end]))
+	if err != nil || priority > 191 {
+		return "", nil, errInvalidPriority
+	}
+	return severityStatusMapping[priority%8], msg[end+1:], nil
+}
+
+func parseRFC5424(status string, msg []byte) (Message, error) {
+	// TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+	fields := bytes.SplitN(msg, []byte{' '}, 6)
+	if len(fields) < 6 {
+		return Message{}, errors.New("Can't parse syslog message: missing RFC5424 header fields")
+	}
+	content, err := skipStructuredData(fields[5])
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Timestamp: value(fields[0]),
+		Hostname:  value(fields[1]),
+		AppName:   value(fields[2]),
+		Status:    status,
+		Content:   bytes.TrimPrefix(content, utf8BOM),
+	}, nil
+}
+
+// skipStructuredData returns the content following the structured data, made
+// of `-` or of elements like `[id key="value"]` whose values may contain escaped quotes.
+func skipStructuredData(msg []byte) ([]byte, error) {
+	if len(msg) > 0 && msg[0] == '-' {
+		return bytes.TrimPrefix(msg[1:], []byte{' '}), nil
+	}
+	i := 0
+	for i < len(msg) && msg[i] == '[' {
+		inValue := false
+		for i++; i < len(msg); i++ {
+			if msg[i] == '\\' && inValue {
+				// escaped character
+				i++
+				continue
+			}
+			if msg[i] == '"' {
+				inValue = !inValue
+			} else if msg[i] == ']' && !inValue {
+				break
+			}
+		}
+		if i >= len(msg) {
+			return nil, errors.New("Can't parse syslog message: unterminated structured data")
+		}
+		i++
+	}
+	if i == 0 {
+		return nil, errors.New("Can't parse syslog message: invalid structured data")
+	}
+	return bytes.TrimPrefix(msg[i:], []byte{' '}), nil
+}
+
+func parseRFC3164(status string, msg []byte) (Message, error) {
+	if len(msg) <= rfc3164TimestampLength || msg[rfc3164TimestampLength] != ' ' {
+		// some devices only send the priority and the content
+		return Message{Status: status, Content: msg}, nil
+	}
+	parsed := Message{
+		Timestamp: string(msg[:rfc3164TimestampLength]),
+		Status:    status,
+	}
+	rest := msg[rfc3164TimestampLength+1:]
+	if end := bytes.IndexByte(rest, ' '); end > 0 {
+		parsed.Hostname = string(rest[:end])
+		rest = rest[end+1:]
+	}
+	// the tag is followed by the optional pid between brackets, then by `:`
+	end := bytes.IndexAny(rest, "[: ")
+	if end > 0 && rest[end] != ' ' {
+		tagEnd := end
+		if rest[end] == '[' {
+			if closing := bytes.IndexByte(rest[end:], ']'); closing > 0 {
+				tagEnd = end + closing + 1
+			}
+		}
+		if tagEnd < len(rest) && rest[tagEnd] == ':' {
+			parsed.AppName = string(rest[:end])
+			rest = bytes.TrimPrefix(rest[tagEnd+1:], []byte{' '})
+		}
+	}
+	parsed.Content = rest
+	return parsed, nil
+}
+
+// value returns the value of a field, empty if it is nil.
+func value(field []byte) string {
+	if string(field) == nilValue {
+		return ""
+	}
+	return string(field)
+}
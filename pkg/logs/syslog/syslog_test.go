@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package syslog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestParseRFC3164(t *testing.T) {
+	msg, err := Parse([]byte("<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Oct 11 22:14:15", msg.Timestamp)
+	assert.Equal(t, "mymachine", msg.Hostname)
+	assert.Equal(t, "su", msg.AppName)
+	assert.Equal(t, message.StatusCritical, msg.Status)
+	assert.Equal(t, "'su root' failed for lonvick on /dev/pts/8", string(msg.Content))
+
+	msg, err = Parse([]byte("<13>Feb  5 17:32:18 10.0.0.99 myapp: hello world"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Feb  5 17:32:18", msg.Timestamp)
+	assert.Equal(t, "10.0.0.99", msg.Hostname)
+	assert.Equal(t, "myapp", msg.AppName)
+	assert.Equal(t, message.StatusNotice, msg.Status)
+	assert.Equal(t, "hello world", string(msg.Content))
+
+	msg, err = Parse([]byte("<11>Feb  5 17:32:18 router link down on port 3"))
+	assert.Nil(t, err)
+	assert.Equal(t, "router", msg.Hostname)
+	assert.Equal(t, "", msg.AppName)
+	assert.Equal(t, message.StatusError, msg.Status)
+	assert.Equal(t, "link down on port 3", string(msg.Content))
+
+	msg, err = Parse([]byte("<14>hello"))
+	assert.Nil(t, err)
+	assert.Equal(t, message.StatusInfo, msg.Status)
+	assert.Equal(t, "hello", string(msg.Content))
+}
+
+func TestParseRFC5424(t *testing.T) {
+	msg, err := Parse([]byte("<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut=\"3\" eventSource=\"Application\" eventID=\"1011\"][examplePriority@32473 class=\"high\"] An application event"))
+	assert.Nil(t, err)
+	assert.Equal(t, "2003-10-11T22:14:15.003Z", msg.Timestamp)
+	assert.Equal(t, "mymachine.example.com", msg.Hostname)
+	assert.Equal(t, "evntslog", msg.AppName)
+	assert.Equal(t, message.StatusNotice, msg.Status)
+	assert.Equal(t, "An application event", string(msg.Content))
+
+	msg, err = Parse([]byte("<34>1 2003-10-11T22:14:15.003Z - su - ID47 - \xef\xbb\xbf'su root' failed"))
+	assert.Nil(t, err)
+	assert.Equal(t, "", msg.Hostname)
+	assert.Equal(t, "su", msg.AppName)
+	assert.Equal(t, message.StatusCritical, msg.Status)
+	assert.Equal(t, "'su root' failed", string(msg.Content))
+
+	msg, err = Parse([]byte("<34>1 2003-10-11T22:14:15.003Z host app 12 - [id key=\"a \\\"quoted\\\" ] value\"] content"))
+	assert.Nil(t, err)
+	assert.Equal(t, "content", string(msg.Content))
+
+	msg, err = Parse([]byte("<34>1 2003-10-11T22:14:15.003Z host app 12 - -"))
+	assert.Nil(t, err)
+	assert.Equal(t, "", string(msg.Content))
+}
+
+func TestParseInvalidMessages(t *testing.T) {
+	var err error
+	_, err = Parse([]byte("hello world"))
+	assert.NotNil(t, err)
+	_, err = Parse([]byte("<abc>hello world"))
+	assert.NotNil(t, err)
+	_, err = Parse([]byte("<192>hello world"))
+	assert.NotNil(t, err)
+	_, err = Parse([]byte("<34>1 2003-10-11T22:14:15.003Z host app"))
+	assert.NotNil(t, err)
+	_, err = Parse([]byte("<34>1 2003-10-11T22:14:15.003Z host app 12 - [id key=\"value\" content"))
+	assert.NotNil(t, err)
+}
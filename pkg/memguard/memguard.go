@@ -0,0 +1,225 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package memguard keeps the memory used by the agent under a ceiling. The
+// components register how they shed load when its RSS gets close to the
+// ceiling, so the agent degrades gracefully instead of being OOM-killed and
+// losing all its data.
+package memguard
+
+import (
+	"expvar"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/shirou/gopsutil/process"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// Stage is a degradation stage, the stages are entered in order as the RSS
+// gets closer to the ceiling
+type Stage int
+
+// The stages and the ratio of the ceiling entering them
+const (
+	StageNormal   Stage = iota
+	StageLight          // 80% of the ceiling
+	StageModerate       // 90% of the ceiling
+	StageSevere         // 100% of the ceiling
+)
+
+var stageRatios = map[Stage]float64{
+	StageLight:    0.8,
+	StageModerate: 0.9,
+	StageSevere:   1,
+}
+
+// hysteresisRatio is the ratio of the ceiling the RSS must get below the
+// threshold of a stage to leave it, so the stage doesn't flap
+const hysteresisRatio = 0.05
+
+func (s Stage) String() string {
+	switch s {
+	case StageNormal:
+		return "normal"
+	case StageLight:
+		return "light"
+	case StageModerate:
+		return "moderate"
+	case StageSevere:
+		return "severe"
+	}
+	return fmt.Sprintf("stage %d", int(s))
+}
+
+// Shedder is called with the new stage every time the stage changes, it sheds
+// or restores the load of a component
+type Shedder func(stage Stage)
+
+type shedder struct {
+	name string
+	shed Shedder
+}
+
+var (
+	memguardExpvar = expvar.NewMap("memguard")
+
+	sheddersMutex sync.Mutex
+	shedders      []shedder
+)
+
+// for testing purpose
+var (
+	currentRSS = processRSS
+	sendEvent  = sendAgentEvent
+)
+
+// Register adds the shedder of a component, it is called on every change of
+// the stage
+func Register(name string, shed Shedder) {
+	sheddersMutex.Lock()
+	defer sheddersMutex.Unlock()
+	shedders = append(shedders, shedder{name: name, shed: shed})
+}
+
+// Guard checks the RSS of the agent against the ceiling on an interval
+type Guard struct {
+	ceiling  uint64
+	interval time.Duration
+	stage    Stage
+	stop     chan struct{}
+}
+
+// NewGuard returns a Guard keeping the RSS under ceiling bytes, checking it
+// every interval
+func NewGuard(ceiling uint64, interval time.Duration) (*Guard, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid check interval %s, it must be positive", interval)
+	}
+	return &Guard{
+		ceiling:  ceiling,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+// Start checks the RSS in the background until Stop is called
+func (g *Guard) Start() {
+	log.Infof("Keeping the memory of the agent under %d MB", g.ceiling/1024/1024)
+	go func() {
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				g.check()
+			case <-g.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops checking the RSS
+func (g *Guard) Stop() {
+	close(g.stop)
+}
+
+// check moves to the stage of the current RSS and calls the shedders if it
+// changed
+func (g *Guard) check() {
+	rss, err := currentRSS()
+	if err != nil {
+		log.Warnf("Could not get the memory used by the agent: %s", err)
+		return
+	}
+	memguardExpvar.Set("RSS", expvarInt(int64(rss)))
+
+	stage := stageFor(rss, g.ceiling, g.stage)
+	if stage == g.stage {
+		return
+	}
+	previous := g.stage
+	g.stage = stage
+	memguardExpvar.Set("Stage", expvarInt(int64(stage)))
+	memguardExpvar.Add("StageChanges", 1)
+
+	message := fmt.Sprintf("The agent uses %d MB, %.0f%% of its ceiling of %d MB: the load shedding goes from %s to %s",
+		rss/1024/1024, 100*float64(rss)/float64(g.ceiling), g.ceiling/1024/1024, previous, stage)
+	if stage > previous {
+		log.Warn(message)
+	} else {
+		log.Info(message)
+	}
+	sendEvent(stage, message)
+
+	sheddersMutex.Lock()
+	defer sheddersMutex.Unlock()
+	for _, s := range shedders {
+		log.Debugf("Setting the load shedding of %s to %s", s.name, stage)
+		s.shed(stage)
+	}
+}
+
+// stageFor returns the stage of rss, the current stage is only left for a
+// lower one once rss is under its threshold minus the hysteresis
+func stageFor(rss, ceiling uint64, current Stage) Stage {
+	ratio := float64(rss) / float64(ceiling)
+	stage := StageNormal
+	for s := StageLight; s <= StageSevere; s++ {
+		if ratio >= stageRatios[s] {
+			stage = s
+		}
+	}
+	if stage < current && ratio >= stageRatios[current]-hysteresisRatio {
+		return current
+	}
+	return stage
+}
+
+func processRSS() (uint64, error) {
+	p, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return 0, err
+	}
+	mem, err := p.MemoryInfo()
+	if err != nil {
+		return 0, err
+	}
+	return mem.RSS, nil
+}
+
+// sendAgentEvent sends an event to tell the load shedding stage changed
+func sendAgentEvent(stage Stage, message string) {
+	sender, err := aggregator.GetDefaultSender()
+	if err != nil {
+		log.Debugf("Could not send the load shedding event: %s", err)
+		return
+	}
+	alertType := metrics.EventAlertTypeWarning
+	if stage == StageNormal {
+		alertType = metrics.EventAlertTypeSuccess
+	}
+	sender.Event(metrics.Event{
+		Title:          fmt.Sprintf("Datadog Agent load shedding: %s", stage),
+		Text:           message,
+		AlertType:      alertType,
+		SourceTypeName: "System",
+		EventType:      "Agent Memory Ceiling",
+		AggregationKey: "agent_memory_ceiling",
+	})
+	sender.Commit()
+}
+
+func expvarInt(value int64) *expvar.Int {
+	v := new(expvar.Int)
+	v.Set(value)
+	return v
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package memguard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mb = 1024 * 1024
+
+func TestStageFor(t *testing.T) {
+	for _, tc := range []struct {
+		rss     uint64
+		current Stage
+		stage   Stage
+	}{
+		{rss: 50 * mb, current: StageNormal, stage: StageNormal},
+		{rss: 80 * mb, current: StageNormal, stage: StageLight},
+		{rss: 95 * mb, current: StageNormal, stage: StageModerate},
+		{rss: 120 * mb, current: StageNormal, stage: StageSevere},
+		// the hysteresis keeps the current stage
+		{rss: 78 * mb, current: StageLight, stage: StageLight},
+		{rss: 74 * mb, current: StageLight, stage: StageNormal},
+		{rss: 96 * mb, current: StageSevere, stage: StageSevere},
+		{rss: 92 * mb, current: StageSevere, stage: StageModerate},
+		{rss: 60 * mb, current: StageSevere, stage: StageNormal},
+	} {
+		assert.Equal(t, tc.stage, stageFor(tc.rss, 100*mb, tc.current), "rss %d MB from %s", tc.rss/mb, tc.current)
+	}
+}
+
+func TestNewGuardInvalidInterval(t *testing.T) {
+	_, err := NewGuard(100*mb, 0)
+	assert.NotNil(t, err)
+}
+
+func TestCheckCallsShedders(t *testing.T) {
+	defer func() {
+		currentRSS = processRSS
+		sendEvent = sendAgentEvent
+		shedders = nil
+	}()
+
+	var rss uint64
+	currentRSS = func() (uint64, error) { return rss, nil }
+	var events []Stage
+	sendEvent = func(stage Stage, message string) { events = append(events, stage) }
+	var shed []Stage
+	Register("test", func(stage Stage) { shed = append(shed, stage) })
+
+	g, err := NewGuard(100*mb, time.Second)
+	require.Nil(t, err)
+
+	rss = 50 * mb
+	g.check()
+	assert.Empty(t, shed)
+
+	rss = 91 * mb
+	g.check()
+	rss = 92 * mb
+	g.check()
+	rss = 10 * mb
+	g.check()
+	assert.Equal(t, []Stage{StageModerate, StageNormal}, shed)
+	assert.Equal(t, []Stage{StageModerate, StageNormal}, events)
+}
@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux windows darwin
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metadata/v5"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+// GohaiCollector sends the systems data collected by gohai on their own, to
+// refresh them more often than the host metadata
+type GohaiCollector struct{}
+
+// Send collects the data needed and submits the payload
+func (gp *GohaiCollector) Send(s *serializer.Serializer) error {
+	if !config.Datadog.GetBool("enable_gohai") {
+		return nil
+	}
+	hostname, _ := util.GetHostname()
+
+	payload := v5.GetGohaiPayload(hostname)
+	if err := s.SendJSONToV1Intake(payload); err != nil {
+		return fmt.Errorf("unable to submit gohai metadata payload, %s", err)
+	}
+	return nil
+}
+
+func init() {
+	catalog["gohai"] = new(GohaiCollector)
+}
@@ -6,6 +6,8 @@
 package gohai
 
 import (
+	"strings"
+
 	"github.com/DataDog/gohai/cpu"
 	"github.com/DataDog/gohai/filesystem"
 	"github.com/DataDog/gohai/memory"
@@ -13,8 +15,27 @@ import (
 	"github.com/DataDog/gohai/platform"
 
 	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// The sections of the metadata collected with gohai, they are disabled by
+// listing them in the `gohai_exclude` option
+const (
+	SectionCPU        = "cpu"
+	SectionFileSystem = "filesystem"
+	SectionMemory     = "memory"
+	SectionNetwork    = "network"
+	SectionPlatform   = "platform"
+	// SectionProcesses is sent in the resources payload
+	SectionProcesses = "processes"
 )
 
+// collector is implemented by the gohai collectors
+type collector interface {
+	Collect() (interface{}, error)
+}
+
 // GetPayload builds a payload of every metadata collected with gohai except processes metadata.
 func GetPayload() *Payload {
 	return &Payload{
@@ -22,44 +43,38 @@ func GetPayload() *Payload {
 	}
 }
 
-func getGohaiInfo() *gohai {
-
-	res := new(gohai)
-
-	cpuPayload, err := new(cpu.Cpu).Collect()
-	if err == nil {
-		res.CPU = cpuPayload
-	} else {
-		log.Errorf("Failed to retrieve cpu metadata: %s", err)
-	}
-
-	fileSystemPayload, err := new(filesystem.FileSystem).Collect()
-	if err == nil {
-		res.FileSystem = fileSystemPayload
-	} else {
-		log.Errorf("Failed to retrieve filesystem metadata: %s", err)
+// IsSectionEnabled returns whether a section of the gohai metadata is
+// collected, it is unless it is listed in `gohai_exclude`.
+func IsSectionEnabled(section string) bool {
+	for _, excluded := range config.Datadog.GetStringSlice("gohai_exclude") {
+		if strings.ToLower(strings.TrimSpace(excluded)) == section {
+			return false
+		}
 	}
+	return true
+}
 
-	memoryPayload, err := new(memory.Memory).Collect()
-	if err == nil {
-		res.Memory = memoryPayload
-	} else {
-		log.Errorf("Failed to retrieve memory metadata: %s", err)
+func getGohaiInfo() *gohai {
+	return &gohai{
+		CPU:        collect(SectionCPU, new(cpu.Cpu)),
+		FileSystem: collect(SectionFileSystem, new(filesystem.FileSystem)),
+		Memory:     collect(SectionMemory, new(memory.Memory)),
+		Network:    collect(SectionNetwork, new(network.Network)),
+		Platform:   collect(SectionPlatform, new(platform.Platform)),
 	}
+}
 
-	networkPayload, err := new(network.Network).Collect()
-	if err == nil {
-		res.Network = networkPayload
-	} else {
-		log.Errorf("Failed to retrieve network metadata: %s", err)
+// collect returns the metadata of a section, nil when it is excluded or
+// cannot be collected
+func collect(section string, c collector) interface{} {
+	if !IsSectionEnabled(section) {
+		log.Debugf("The %s metadata are excluded from the gohai collection", section)
+		return nil
 	}
-
-	platformPayload, err := new(platform.Platform).Collect()
-	if err == nil {
-		res.Platform = platformPayload
-	} else {
-		log.Errorf("Failed to retrieve platform metadata: %s", err)
+	payload, err := c.Collect()
+	if err != nil {
+		log.Errorf("Failed to retrieve %s metadata: %s", section, err)
+		return nil
 	}
-
-	return res
+	return payload
 }
@@ -6,8 +6,11 @@
 package gohai
 
 import (
-	"github.com/stretchr/testify/assert"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 func TestGetPayload(t *testing.T) {
@@ -19,3 +22,16 @@ func TestGetPayload(t *testing.T) {
 	assert.NotNil(t, gohai.Gohai.Network)
 	assert.NotNil(t, gohai.Gohai.Platform)
 }
+
+func TestGetPayloadExcludedSections(t *testing.T) {
+	config.Datadog.Set("gohai_exclude", []string{"network", " Processes", "filesystem"})
+	defer config.Datadog.Set("gohai_exclude", []string{})
+
+	gohai := GetPayload()
+	assert.NotNil(t, gohai.Gohai.CPU)
+	assert.Nil(t, gohai.Gohai.FileSystem)
+	assert.NotNil(t, gohai.Gohai.Memory)
+	assert.Nil(t, gohai.Gohai.Network)
+	assert.NotNil(t, gohai.Gohai.Platform)
+	assert.False(t, IsSectionEnabled(SectionProcesses))
+}
@@ -20,9 +20,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metadata/common"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host/container"
 	"github.com/DataDog/datadog-agent/pkg/util"
-	"github.com/DataDog/datadog-agent/pkg/util/azure"
 	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/DataDog/datadog-agent/pkg/util/cloudfoundry"
+	"github.com/DataDog/datadog-agent/pkg/util/cloudproviders"
 	"github.com/DataDog/datadog-agent/pkg/util/ec2"
 	"github.com/DataDog/datadog-agent/pkg/util/gce"
 	k8s "github.com/DataDog/datadog-agent/pkg/util/kubernetes/hostinfo"
@@ -50,12 +50,13 @@ func GetPayload(hostname string) *Payload {
 	meta.Hostname = hostname
 
 	p := &Payload{
-		Os:            osName,
-		PythonVersion: getPythonVersion(),
-		SystemStats:   getSystemStats(),
-		Meta:          meta,
-		HostTags:      getHostTags(),
-		ContainerMeta: getContainerMeta(),
+		Os:             osName,
+		PythonVersion:  getPythonVersion(),
+		SystemStats:    getSystemStats(),
+		Meta:           meta,
+		HostTags:       getHostTags(),
+		ContainerMeta:  getContainerMeta(),
+		KubernetesNode: getKubernetesNodeInfo(),
 	}
 
 	// Cache the metadata for use in other payloads
@@ -65,6 +66,25 @@ func GetPayload(hostname string) *Payload {
 	return p
 }
 
+// GetHostTagsPayload builds a payload of the host tags only, the cloud provider
+// tags are fetched again.
+func GetHostTagsPayload(hostname string) *HostTagsPayload {
+	return &HostTagsPayload{
+		Payload:  *common.GetPayload(hostname),
+		HostTags: getHostTags(),
+	}
+}
+
+// CloudTagsEnabled returns whether host tags are read from the api of a cloud
+// provider, the host_tags metadata provider then sends them again so their
+// changes are picked up
+func CloudTagsEnabled() bool {
+	return config.Datadog.GetBool("collect_ec2_tags") ||
+		config.Datadog.GetBool("collect_azure_tags") ||
+		config.Datadog.GetBool("collect_oracle_tags") ||
+		gce.TagsEnabled()
+}
+
 // GetPayloadFromCache returns the payload from the cache if it exists, otherwise it creates it.
 // The metadata reporting should always grab it fresh. Any other uses, e.g. status, should use this
 func GetPayloadFromCache(hostname string) *Payload {
@@ -93,15 +113,19 @@ func GetMeta() *Meta {
 func getHostTags() *tags {
 	hostTags := config.Datadog.GetStringSlice("tags")
 
+	cloudTagsInclude := config.Datadog.GetStringSlice("cloud_host_tags_include")
+
 	if config.Datadog.GetBool("collect_ec2_tags") {
 		ec2Tags, err := ec2.GetTags()
 		if err != nil {
 			log.Debugf("No EC2 host tags %v", err)
 		} else {
-			hostTags = append(hostTags, ec2Tags...)
+			hostTags = append(hostTags, filterCloudTags(ec2Tags, cloudTagsInclude)...)
 		}
 	}
 
+	hostTags = append(hostTags, filterCloudTags(cloudproviders.GetHostTags(), cloudTagsInclude)...)
+
 	k8sTags, err := k8s.GetTags()
 	if err != nil {
 		log.Debugf("No Kubernetes host tags %v", err)
@@ -116,8 +140,28 @@ func getHostTags() *tags {
 
 	return &tags{
 		System:              hostTags,
-		GoogleCloudPlatform: gceTags,
+		GoogleCloudPlatform: filterCloudTags(gceTags, cloudTagsInclude),
+	}
+}
+
+// filterCloudTags keeps the cloud provider tags whose key is in the
+// `cloud_host_tags_include` list, all tags are kept if the list is empty
+func filterCloudTags(tags []string, include []string) []string {
+	if len(include) == 0 {
+		return tags
+	}
+
+	filtered := []string{}
+	for _, tag := range tags {
+		key := strings.SplitN(tag, ":", 2)[0]
+		for _, included := range include {
+			if key == included {
+				filtered = append(filtered, tag)
+				break
+			}
+		}
 	}
+	return filtered
 }
 
 func getSystemStats() *systemStats {
@@ -192,23 +236,9 @@ func getHostInfo() *host.InfoStat {
 }
 
 // getHostAliases returns the hostname aliases from different provider
-// This should include GCE, Azure, Cloud foundry, kubernetes
+// This should include the cloud providers, Cloud foundry, kubernetes
 func getHostAliases() []string {
-	aliases := []string{}
-
-	azureAlias, err := azure.GetHostAlias()
-	if err != nil {
-		log.Debugf("no Azure Host Alias: %s", err)
-	} else if azureAlias != "" {
-		aliases = append(aliases, azureAlias)
-	}
-
-	gceAlias, err := gce.GetHostAlias()
-	if err != nil {
-		log.Debugf("no GCE Host Alias: %s", err)
-	} else {
-		aliases = append(aliases, gceAlias)
-	}
+	aliases := cloudproviders.GetHostAliases()
 
 	cfAlias, err := cloudfoudry.GetHostAlias()
 	if err != nil {
@@ -266,6 +296,17 @@ func getContainerMeta() map[string]string {
 	return containerMeta
 }
 
+// getKubernetesNodeInfo returns the Kubernetes info of the node, or nil when the
+// agent does not run on Kubernetes
+func getKubernetesNodeInfo() *k8s.NodeInfo {
+	info, err := k8s.GetNodeInfo()
+	if err != nil {
+		log.Debugf("No Kubernetes node info: %s", err)
+		return nil
+	}
+	return info
+}
+
 func buildKey(key string) string {
 	return path.Join(common.CachePrefix, packageCachePrefix, key)
 }
@@ -83,6 +83,23 @@ func TestGetEmptyHostTags(t *testing.T) {
 	assert.Equal(t, hostTags.System, []string{})
 }
 
+func TestFilterCloudTags(t *testing.T) {
+	tags := []string{"env:prod", "team:payments", "Name:web-1", "http-server"}
+
+	assert.Equal(t, tags, filterCloudTags(tags, nil))
+	assert.Equal(t, []string{"env:prod", "team:payments"}, filterCloudTags(tags, []string{"env", "team"}))
+	assert.Equal(t, []string{"http-server"}, filterCloudTags(tags, []string{"http-server"}))
+	assert.Equal(t, []string{}, filterCloudTags(tags, []string{"unknown"}))
+}
+
+func TestCloudTagsEnabled(t *testing.T) {
+	for _, option := range []string{"collect_ec2_tags", "collect_azure_tags", "collect_oracle_tags"} {
+		config.Datadog.Set(option, true)
+		assert.True(t, CloudTagsEnabled(), option)
+		config.Datadog.Set(option, false)
+	}
+}
+
 func TestBuildKey(t *testing.T) {
 	assert.Equal(t, "metadata/host/foo", buildKey("foo"))
 }
@@ -5,6 +5,11 @@
 
 package host
 
+import (
+	"github.com/DataDog/datadog-agent/pkg/metadata/common"
+	k8s "github.com/DataDog/datadog-agent/pkg/util/kubernetes/hostinfo"
+)
+
 type systemStats struct {
 	CPUCores  int32     `json:"cpuCores"`
 	Machine   string    `json:"machine"`
@@ -35,10 +40,17 @@ type tags struct {
 
 // Payload handles the JSON unmarshalling of the metadata payload
 type Payload struct {
-	Os            string            `json:"os"`
-	PythonVersion string            `json:"python"`
-	SystemStats   *systemStats      `json:"systemStats"`
-	Meta          *Meta             `json:"meta"`
-	HostTags      *tags             `json:"host-tags"`
-	ContainerMeta map[string]string `json:"container-meta,omitempty"`
+	Os             string            `json:"os"`
+	PythonVersion  string            `json:"python"`
+	SystemStats    *systemStats      `json:"systemStats"`
+	Meta           *Meta             `json:"meta"`
+	HostTags       *tags             `json:"host-tags"`
+	ContainerMeta  map[string]string `json:"container-meta,omitempty"`
+	KubernetesNode *k8s.NodeInfo     `json:"kubernetes-node,omitempty"`
+}
+
+// HostTagsPayload refreshes the host tags between two host metadata payloads
+type HostTagsPayload struct {
+	common.Payload
+	HostTags *tags `json:"host-tags"`
 }
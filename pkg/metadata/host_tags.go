@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/metadata/host"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+// HostTagsCollector sends the host tags on their own, so that the changes of
+// the cloud provider tags are picked up without waiting for the host metadata
+type HostTagsCollector struct{}
+
+// Send collects the host tags and submits the payload
+func (hp *HostTagsCollector) Send(s *serializer.Serializer) error {
+	hostname, _ := util.GetHostname()
+
+	payload := host.GetHostTagsPayload(hostname)
+	if err := s.SendJSONToV1Intake(payload); err != nil {
+		return fmt.Errorf("unable to submit host tags payload, %s", err)
+	}
+	return nil
+}
+
+func init() {
+	catalog["host_tags"] = new(HostTagsCollector)
+}
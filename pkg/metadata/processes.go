@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/metadata/processes"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+// ProcessesCollector sends the processes using the most CPU and memory in a
+// resources payload
+type ProcessesCollector struct{}
+
+// Send collects the top processes and submits the payload
+func (pp *ProcessesCollector) Send(s *serializer.Serializer) error {
+	hostname, _ := util.GetHostname()
+
+	res, err := processes.GetPayload(hostname)
+	if err != nil {
+		return fmt.Errorf("unable to list the processes, %s", err)
+	}
+	payload := map[string]interface{}{
+		"resources": res,
+	}
+	if err := s.SendJSONToV1Intake(payload); err != nil {
+		return fmt.Errorf("unable to serialize top processes metadata payload, %s", err)
+	}
+	return nil
+}
+
+func init() {
+	catalog["processes"] = new(ProcessesCollector)
+}
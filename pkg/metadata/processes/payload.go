@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package processes
+
+// Process is a process of the top ones, its CPU usage is the average since the
+// previous collection, or since its start for the first one
+type Process struct {
+	PID           int32   `json:"pid"`
+	Name          string  `json:"name"`
+	User          string  `json:"user"`
+	Cmdline       string  `json:"cmdline"`
+	CPUPercent    float64 `json:"cpu_pct"`
+	MemoryPercent float32 `json:"mem_pct"`
+	RSS           uint64  `json:"rss"`
+}
+
+// Payload handles the JSON unmarshalling of the top processes payload, it is
+// sent as a resources payload
+type Payload struct {
+	Processes []Process         `json:"top_processes"`
+	Meta      map[string]string `json:"meta"`
+}
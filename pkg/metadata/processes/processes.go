@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package processes samples the processes of the host using the most CPU and
+// memory, for the users who want them in the metadata without running the
+// process agent.
+package processes
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/shirou/gopsutil/process"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// sample is a process at the time of a collection
+type sample struct {
+	pid        int32
+	name       string
+	user       string
+	cmdline    []string
+	createTime time.Time
+	cpuTime    float64 // seconds of CPU used since the start of the process
+	rss        uint64
+	memPercent float32
+}
+
+// sampler keeps the CPU time of the processes at the previous collection, to
+// compute their CPU usage between two collections
+type sampler struct {
+	m        sync.Mutex
+	previous map[int32]float64
+	lastRun  time.Time
+}
+
+var defaultSampler = &sampler{}
+
+// listProcesses is a var to ease testing
+var listProcesses = func() ([]sample, error) {
+	pids, err := process.Pids()
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]sample, 0, len(pids))
+	for _, pid := range pids {
+		p, err := process.NewProcess(pid)
+		if err != nil {
+			// the process exited
+			continue
+		}
+		times, err := p.Times()
+		if err != nil {
+			log.Debugf("Unable to get the CPU time of the process %d: %s", pid, err)
+			continue
+		}
+		s := sample{pid: pid, cpuTime: times.User + times.System}
+		s.name, _ = p.Name()
+		s.user, _ = p.Username()
+		s.cmdline, _ = p.CmdlineSlice()
+		if created, err := p.CreateTime(); err == nil {
+			s.createTime = time.Unix(0, created*int64(time.Millisecond))
+		}
+		if mem, err := p.MemoryInfo(); err == nil {
+			s.rss = mem.RSS
+		}
+		s.memPercent, _ = p.MemoryPercent()
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+// GetPayload samples the processes and returns the `processes_metadata.top_n`
+// ones using the most CPU and the ones using the most memory, their command
+// lines are scrubbed.
+func GetPayload(hostname string) (*Payload, error) {
+	samples, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+	top := defaultSampler.top(samples, time.Now(), config.Datadog.GetInt("processes_metadata.top_n"))
+
+	s := newCmdlineScrubber(config.Datadog.GetBool("processes_metadata.scrub_args"), config.Datadog.GetStringSlice("processes_metadata.sensitive_words"))
+	for i := range top {
+		top[i].Cmdline = s.scrub(top[i].Cmdline)
+	}
+
+	return &Payload{
+		Processes: top,
+		Meta: map[string]string{
+			"host": hostname,
+		},
+	}, nil
+}
+
+// top returns the n processes using the most CPU and the n processes using the
+// most memory, sorted by CPU usage. The command lines are not scrubbed.
+func (s *sampler) top(samples []sample, now time.Time, n int) []Process {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	elapsed := now.Sub(s.lastRun).Seconds()
+	processes := make([]Process, 0, len(samples))
+	current := make(map[int32]float64, len(samples))
+	for _, sample := range samples {
+		current[sample.pid] = sample.cpuTime
+
+		var cpuPercent float64
+		if previous, found := s.previous[sample.pid]; found && elapsed > 0 && sample.cpuTime >= previous {
+			cpuPercent = 100 * (sample.cpuTime - previous) / elapsed
+		} else if lifetime := now.Sub(sample.createTime).Seconds(); !sample.createTime.IsZero() && lifetime > 0 {
+			cpuPercent = 100 * sample.cpuTime / lifetime
+		}
+		processes = append(processes, Process{
+			PID:           sample.pid,
+			Name:          sample.name,
+			User:          sample.user,
+			Cmdline:       joinCmdline(sample.cmdline),
+			CPUPercent:    cpuPercent,
+			MemoryPercent: sample.memPercent,
+			RSS:           sample.rss,
+		})
+	}
+	s.previous = current
+	s.lastRun = now
+
+	if n <= 0 || len(processes) <= n {
+		sortByCPU(processes)
+		return processes
+	}
+
+	selected := make(map[int32]bool, 2*n)
+	sort.SliceStable(processes, func(i, j int) bool { return processes[i].RSS > processes[j].RSS })
+	for _, p := range processes[:n] {
+		selected[p.PID] = true
+	}
+	sortByCPU(processes)
+	for _, p := range processes[:n] {
+		selected[p.PID] = true
+	}
+
+	top := make([]Process, 0, len(selected))
+	for _, p := range processes {
+		if selected[p.PID] {
+			top = append(top, p)
+		}
+	}
+	return top
+}
+
+func sortByCPU(processes []Process) {
+	sort.SliceStable(processes, func(i, j int) bool { return processes[i].CPUPercent > processes[j].CPUPercent })
+}
@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package processes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func pids(processes []Process) []int32 {
+	result := []int32{}
+	for _, p := range processes {
+		result = append(result, p.PID)
+	}
+	return result
+}
+
+func TestTopProcesses(t *testing.T) {
+	now := time.Now()
+	started := now.Add(-100 * time.Second)
+	samples := []sample{
+		{pid: 1, createTime: started, cpuTime: 1, rss: 100},
+		{pid: 2, createTime: started, cpuTime: 50, rss: 200},
+		{pid: 3, createTime: started, cpuTime: 10, rss: 5000},
+		{pid: 4, createTime: started, cpuTime: 20, rss: 300},
+	}
+	s := &sampler{}
+
+	// the first collection uses the CPU usage since the start of the processes
+	top := s.top(samples, now, 1)
+	assert.Equal(t, []int32{2, 3}, pids(top))
+	assert.InDelta(t, 50, top[0].CPUPercent, 0.01)
+
+	// the next ones use the CPU usage since the previous collection
+	samples[0].cpuTime += 5
+	samples[1].cpuTime += 1
+	top = s.top(samples, now.Add(10*time.Second), 2)
+	assert.Equal(t, []int32{1, 2, 3, 4}, pids(top))
+	assert.InDelta(t, 50, top[0].CPUPercent, 0.01)
+	assert.InDelta(t, 10, top[1].CPUPercent, 0.01)
+
+	// every process is kept without limit
+	assert.Len(t, s.top(samples, now.Add(20*time.Second), 0), 4)
+}
+
+func TestGetPayload(t *testing.T) {
+	listProcesses = func() ([]sample, error) {
+		return []sample{
+			{pid: 1, name: "mysqld", cmdline: []string{"mysqld", "--password=hunter2"}, createTime: time.Now().Add(-time.Minute), cpuTime: 30, rss: 100},
+			{pid: 2, name: "bash", cmdline: []string{"bash"}, rss: 200},
+		}, nil
+	}
+	config.Datadog.Set("processes_metadata.top_n", 1)
+	defer config.Datadog.Set("processes_metadata.top_n", 10)
+
+	payload, err := GetPayload("myhost")
+	require.Nil(t, err)
+	assert.Equal(t, "myhost", payload.Meta["host"])
+	require.Len(t, payload.Processes, 2)
+	for _, p := range payload.Processes {
+		assert.NotContains(t, p.Cmdline, "hunter2")
+	}
+}
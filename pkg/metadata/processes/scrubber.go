@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package processes
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/scrubber"
+)
+
+const redacted = "********"
+
+// defaultSensitiveWords are the arguments whose value is always scrubbed when
+// `processes_metadata.scrub_args` is enabled
+var defaultSensitiveWords = []string{
+	"password", "passwd", "mysql_pwd", "access_token", "auth_token",
+	"api_key", "apikey", "secret", "credentials", "stripetoken",
+}
+
+// cmdlineScrubber scrubs the values of the arguments named after a sensitive
+// word, then applies the rules of the flares and logs scrubber, including the
+// `scrubber.custom_rules`
+type cmdlineScrubber struct {
+	scrubArgs bool
+	words     []string
+}
+
+func newCmdlineScrubber(scrubArgs bool, customWords []string) *cmdlineScrubber {
+	words := append([]string{}, defaultSensitiveWords...)
+	for _, word := range customWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			words = append(words, word)
+		}
+	}
+	return &cmdlineScrubber{scrubArgs: scrubArgs, words: words}
+}
+
+func (s *cmdlineScrubber) scrub(cmdline string) string {
+	if s.scrubArgs {
+		args := strings.Fields(cmdline)
+		for i := 0; i < len(args); i++ {
+			// --password=secret, password:secret
+			if sep := strings.IndexAny(args[i], "=:"); sep > 0 {
+				if s.isSensitive(args[i][:sep]) {
+					args[i] = args[i][:sep+1] + redacted
+				}
+				continue
+			}
+			// --password secret
+			if s.isSensitive(args[i]) && i+1 < len(args) {
+				args[i+1] = redacted
+				i++
+			}
+		}
+		cmdline = joinCmdline(args)
+	}
+	return scrubber.ScrubLine(cmdline)
+}
+
+// isSensitive returns whether the name of an argument contains a sensitive word
+func (s *cmdlineScrubber) isSensitive(name string) bool {
+	name = strings.ToLower(strings.TrimLeft(name, "-"))
+	for _, word := range s.words {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinCmdline(args []string) string {
+	return strings.Join(args, " ")
+}
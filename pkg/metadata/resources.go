@@ -6,7 +6,6 @@
 package metadata
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/DataDog/datadog-agent/pkg/metadata/resources"
@@ -24,10 +23,12 @@ func (rp *ResourcesCollector) Send(s *serializer.Serializer) error {
 
 	res := resources.GetPayload(hostname)
 	if res == nil {
-		return errors.New("empty processes metadata")
+		// the processes are excluded by `gohai_exclude`, or their collection
+		// failed and was logged
+		return nil
 	}
 	payload := map[string]interface{}{
-		"resources": resources.GetPayload(hostname),
+		"resources": res,
 	}
 	if err := s.SendJSONToV1Intake(payload); err != nil {
 		return fmt.Errorf("unable to serialize processes metadata payload, %s", err)
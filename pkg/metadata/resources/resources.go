@@ -10,10 +10,16 @@ package resources
 import (
 	"github.com/DataDog/gohai/processes"
 	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/metadata/gohai"
 )
 
-// GetPayload builds a payload of processes metadata collected from gohai.
+// GetPayload builds a payload of processes metadata collected from gohai, it
+// returns nil when the processes are excluded by `gohai_exclude`.
 func GetPayload(hostname string) *Payload {
+	if !gohai.IsSectionEnabled(gohai.SectionProcesses) {
+		return nil
+	}
 
 	// Get processes metadata from gohai
 	proc, err := new(processes.Processes).Collect()
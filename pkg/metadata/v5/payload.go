@@ -23,6 +23,13 @@ type Payload struct {
 	GohaiPayload
 }
 
+// GohaiOnlyPayload is the part of the payload collected with gohai, sent on its
+// own to refresh it between two host metadata payloads
+type GohaiOnlyPayload struct {
+	CommonPayload
+	GohaiPayload
+}
+
 // GohaiPayload wraps Payload from the gohai package
 // As weird as it sounds, in the v5 payload the value of the "gohai" field
 // is a JSON-formatted string. So this struct contains a MarshalledGohaiPayload
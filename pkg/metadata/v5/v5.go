@@ -36,3 +36,11 @@ func GetPayload(hostname string) *Payload {
 
 	return p
 }
+
+// GetGohaiPayload returns the part of the metadata payload collected with gohai
+func GetGohaiPayload(hostname string) *GohaiOnlyPayload {
+	return &GohaiOnlyPayload{
+		CommonPayload: CommonPayload{*common.GetPayload(hostname)},
+		GohaiPayload:  GohaiPayload{MarshalledGohaiPayload{*gohai.GetPayload()}},
+	}
+}
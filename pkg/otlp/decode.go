@@ -0,0 +1,488 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package otlp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// The messages of opentelemetry/proto/metrics/v1/metrics.proto the receiver
+// handles, they are decoded by hand from the protobuf wire format so the
+// agent doesn't depend on the generated code of the OpenTelemetry protos.
+// Only the fields mapped to the aggregator are kept.
+
+// aggregation temporalities of the sums and the histograms
+const (
+	temporalityUnspecified = 0
+	temporalityDelta       = 1
+	temporalityCumulative  = 2
+)
+
+type resourceMetrics struct {
+	attributes []keyValue
+	metrics    []metric
+}
+
+type keyValue struct {
+	key   string
+	value string
+}
+
+type metricKind int
+
+const (
+	kindUnsupported metricKind = iota
+	kindGauge
+	kindSum
+	kindHistogram
+)
+
+type metric struct {
+	name        string
+	kind        metricKind
+	temporality int
+	monotonic   bool
+	points      []numberPoint
+	histograms  []histogramPoint
+}
+
+type numberPoint struct {
+	attributes []keyValue
+	startTime  uint64 // unix nanoseconds
+	time       uint64 // unix nanoseconds
+	value      float64
+}
+
+type histogramPoint struct {
+	attributes   []keyValue
+	startTime    uint64
+	time         uint64
+	count        uint64
+	sum          float64
+	hasSum       bool
+	bucketCounts []uint64
+	bounds       []float64
+}
+
+// protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated protobuf message")
+
+// wireReader reads the fields of a protobuf message
+type wireReader struct {
+	buf []byte
+}
+
+// next returns the number and the wire type of the next field, the reader is
+// done when it returns false
+func (r *wireReader) next() (int, int, bool, error) {
+	if len(r.buf) == 0 {
+		return 0, 0, false, nil
+	}
+	tag, err := r.varint()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return int(tag >> 3), int(tag & 7), true, nil
+}
+
+func (r *wireReader) varint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	r.buf = r.buf[n:]
+	return v, nil
+}
+
+func (r *wireReader) fixed64() (uint64, error) {
+	if len(r.buf) < 8 {
+		return 0, errTruncated
+	}
+	v := binary.LittleEndian.Uint64(r.buf)
+	r.buf = r.buf[8:]
+	return v, nil
+}
+
+func (r *wireReader) bytes() ([]byte, error) {
+	l, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(r.buf)) < l {
+		return nil, errTruncated
+	}
+	b := r.buf[:l]
+	r.buf = r.buf[l:]
+	return b, nil
+}
+
+// skip skips the value of a field the receiver doesn't use
+func (r *wireReader) skip(wireType int) error {
+	var err error
+	switch wireType {
+	case wireVarint:
+		_, err = r.varint()
+	case wireFixed64:
+		_, err = r.fixed64()
+	case wireBytes:
+		_, err = r.bytes()
+	case wireFixed32:
+		if len(r.buf) < 4 {
+			return errTruncated
+		}
+		r.buf = r.buf[4:]
+	default:
+		return fmt.Errorf("unsupported protobuf wire type %d", wireType)
+	}
+	return err
+}
+
+// forEachField calls read for every field of the message in buf, read
+// returns false to skip the field
+func forEachField(buf []byte, read func(r *wireReader, field, wireType int) (bool, error)) error {
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok, err := r.next()
+		if err != nil || !ok {
+			return err
+		}
+		handled, err := read(r, field, wireType)
+		if err != nil {
+			return err
+		}
+		if !handled {
+			if err := r.skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// decodeExportRequest decodes an ExportMetricsServiceRequest
+func decodeExportRequest(buf []byte) ([]resourceMetrics, error) {
+	var result []resourceMetrics
+	err := forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		if field != 1 || wireType != wireBytes {
+			return false, nil
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return true, err
+		}
+		rm, err := decodeResourceMetrics(b)
+		if err != nil {
+			return true, err
+		}
+		result = append(result, rm)
+		return true, nil
+	})
+	return result, err
+}
+
+func decodeResourceMetrics(buf []byte) (resourceMetrics, error) {
+	var rm resourceMetrics
+	err := forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		if wireType != wireBytes {
+			return false, nil
+		}
+		switch field {
+		case 1: // resource
+			b, err := r.bytes()
+			if err != nil {
+				return true, err
+			}
+			rm.attributes, err = decodeAttributes(b, 1)
+			return true, err
+		case 2, 1000: // scope_metrics, and the deprecated instrumentation_library_metrics
+			b, err := r.bytes()
+			if err != nil {
+				return true, err
+			}
+			metrics, err := decodeScopeMetrics(b)
+			rm.metrics = append(rm.metrics, metrics...)
+			return true, err
+		}
+		return false, nil
+	})
+	return rm, err
+}
+
+func decodeScopeMetrics(buf []byte) ([]metric, error) {
+	var metrics []metric
+	err := forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		if field != 2 || wireType != wireBytes {
+			return false, nil
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return true, err
+		}
+		m, err := decodeMetric(b)
+		if err != nil {
+			return true, err
+		}
+		metrics = append(metrics, m)
+		return true, nil
+	})
+	return metrics, err
+}
+
+func decodeMetric(buf []byte) (metric, error) {
+	var m metric
+	err := forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		if wireType != wireBytes {
+			return false, nil
+		}
+		switch field {
+		case 1: // name
+			b, err := r.bytes()
+			m.name = string(b)
+			return true, err
+		case 5: // gauge
+			b, err := r.bytes()
+			if err != nil {
+				return true, err
+			}
+			m.kind = kindGauge
+			return true, decodeNumberData(b, &m)
+		case 7: // sum
+			b, err := r.bytes()
+			if err != nil {
+				return true, err
+			}
+			m.kind = kindSum
+			return true, decodeNumberData(b, &m)
+		case 9: // histogram
+			b, err := r.bytes()
+			if err != nil {
+				return true, err
+			}
+			m.kind = kindHistogram
+			return true, decodeHistogramData(b, &m)
+		}
+		return false, nil
+	})
+	return m, err
+}
+
+// decodeNumberData decodes a Gauge or a Sum, they share the field numbers of
+// the data points
+func decodeNumberData(buf []byte, m *metric) error {
+	return forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		switch {
+		case field == 1 && wireType == wireBytes: // data_points
+			b, err := r.bytes()
+			if err != nil {
+				return true, err
+			}
+			p, err := decodeNumberPoint(b)
+			m.points = append(m.points, p)
+			return true, err
+		case field == 2 && wireType == wireVarint: // aggregation_temporality
+			v, err := r.varint()
+			m.temporality = int(v)
+			return true, err
+		case field == 3 && wireType == wireVarint: // is_monotonic
+			v, err := r.varint()
+			m.monotonic = v != 0
+			return true, err
+		}
+		return false, nil
+	})
+}
+
+func decodeNumberPoint(buf []byte) (numberPoint, error) {
+	var p numberPoint
+	err := forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		var err error
+		switch {
+		case field == 7 && wireType == wireBytes: // attributes
+			var b []byte
+			if b, err = r.bytes(); err == nil {
+				var kv keyValue
+				kv, err = decodeKeyValue(b)
+				p.attributes = append(p.attributes, kv)
+			}
+		case field == 2 && wireType == wireFixed64: // start_time_unix_nano
+			p.startTime, err = r.fixed64()
+		case field == 3 && wireType == wireFixed64: // time_unix_nano
+			p.time, err = r.fixed64()
+		case field == 4 && wireType == wireFixed64: // as_double
+			var v uint64
+			v, err = r.fixed64()
+			p.value = math.Float64frombits(v)
+		case field == 6 && wireType == wireFixed64: // as_int, a sfixed64
+			var v uint64
+			v, err = r.fixed64()
+			p.value = float64(int64(v))
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	return p, err
+}
+
+func decodeHistogramData(buf []byte, m *metric) error {
+	return forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		switch {
+		case field == 1 && wireType == wireBytes: // data_points
+			b, err := r.bytes()
+			if err != nil {
+				return true, err
+			}
+			p, err := decodeHistogramPoint(b)
+			m.histograms = append(m.histograms, p)
+			return true, err
+		case field == 2 && wireType == wireVarint: // aggregation_temporality
+			v, err := r.varint()
+			m.temporality = int(v)
+			return true, err
+		}
+		return false, nil
+	})
+}
+
+func decodeHistogramPoint(buf []byte) (histogramPoint, error) {
+	var p histogramPoint
+	err := forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		var err error
+		switch {
+		case field == 9 && wireType == wireBytes: // attributes
+			var b []byte
+			if b, err = r.bytes(); err == nil {
+				var kv keyValue
+				kv, err = decodeKeyValue(b)
+				p.attributes = append(p.attributes, kv)
+			}
+		case field == 2 && wireType == wireFixed64: // start_time_unix_nano
+			p.startTime, err = r.fixed64()
+		case field == 3 && wireType == wireFixed64: // time_unix_nano
+			p.time, err = r.fixed64()
+		case field == 4 && wireType == wireFixed64: // count
+			p.count, err = r.fixed64()
+		case field == 5 && wireType == wireFixed64: // sum
+			var v uint64
+			v, err = r.fixed64()
+			p.sum = math.Float64frombits(v)
+			p.hasSum = true
+		case field == 6: // bucket_counts, packed or not
+			err = readFixed64s(r, wireType, func(v uint64) { p.bucketCounts = append(p.bucketCounts, v) })
+		case field == 7: // explicit_bounds, packed or not
+			err = readFixed64s(r, wireType, func(v uint64) { p.bounds = append(p.bounds, math.Float64frombits(v)) })
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	return p, err
+}
+
+// readFixed64s reads a repeated fixed64 field, packed or not
+func readFixed64s(r *wireReader, wireType int, add func(v uint64)) error {
+	switch wireType {
+	case wireFixed64:
+		v, err := r.fixed64()
+		if err == nil {
+			add(v)
+		}
+		return err
+	case wireBytes:
+		b, err := r.bytes()
+		if err != nil {
+			return err
+		}
+		if len(b)%8 != 0 {
+			return errTruncated
+		}
+		for i := 0; i < len(b); i += 8 {
+			add(binary.LittleEndian.Uint64(b[i:]))
+		}
+		return nil
+	}
+	return r.skip(wireType)
+}
+
+// decodeAttributes decodes the attributes of a message, e.g. a Resource
+func decodeAttributes(buf []byte, attributesField int) ([]keyValue, error) {
+	var attributes []keyValue
+	err := forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		if field != attributesField || wireType != wireBytes {
+			return false, nil
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return true, err
+		}
+		kv, err := decodeKeyValue(b)
+		attributes = append(attributes, kv)
+		return true, err
+	})
+	return attributes, err
+}
+
+func decodeKeyValue(buf []byte) (keyValue, error) {
+	var kv keyValue
+	err := forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		if wireType != wireBytes {
+			return false, nil
+		}
+		b, err := r.bytes()
+		if err != nil {
+			return true, err
+		}
+		switch field {
+		case 1: // key
+			kv.key = string(b)
+		case 2: // value
+			kv.value, err = decodeAnyValue(b)
+		}
+		return true, err
+	})
+	return kv, err
+}
+
+// decodeAnyValue returns the string representation of the scalar values, the
+// arrays, the maps and the bytes are not used as tags
+func decodeAnyValue(buf []byte) (string, error) {
+	var value string
+	err := forEachField(buf, func(r *wireReader, field, wireType int) (bool, error) {
+		var err error
+		switch {
+		case field == 1 && wireType == wireBytes: // string_value
+			var b []byte
+			b, err = r.bytes()
+			value = string(b)
+		case field == 2 && wireType == wireVarint: // bool_value
+			var v uint64
+			v, err = r.varint()
+			value = strconv.FormatBool(v != 0)
+		case field == 3 && wireType == wireVarint: // int_value
+			var v uint64
+			v, err = r.varint()
+			value = strconv.FormatInt(int64(v), 10)
+		case field == 4 && wireType == wireFixed64: // double_value
+			var v uint64
+			v, err = r.fixed64()
+			value = strconv.FormatFloat(math.Float64frombits(v), 'f', -1, 64)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	return value, err
+}
@@ -0,0 +1,161 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package otlp
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pb encodes the protobuf messages of the tests
+type pb []byte
+
+func (m pb) uvarint(v uint64) pb {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return append(m, buf[:binary.PutUvarint(buf, v)]...)
+}
+
+func (m pb) varint(field int, v uint64) pb {
+	return m.uvarint(uint64(field<<3 | wireVarint)).uvarint(v)
+}
+
+func (m pb) fixed64(field int, v uint64) pb {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, v)
+	return append(m.uvarint(uint64(field<<3|wireFixed64)), buf...)
+}
+
+func (m pb) double(field int, v float64) pb {
+	return m.fixed64(field, math.Float64bits(v))
+}
+
+func (m pb) bytes(field int, b []byte) pb {
+	return append(m.uvarint(uint64(field<<3|wireBytes)).uvarint(uint64(len(b))), b...)
+}
+
+func (m pb) str(field int, s string) pb {
+	return m.bytes(field, []byte(s))
+}
+
+// packedDoubles encodes a packed repeated double
+func (m pb) packedDoubles(field int, values ...float64) pb {
+	buf := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(buf[8*i:], math.Float64bits(v))
+	}
+	return m.bytes(field, buf)
+}
+
+// attribute encodes a KeyValue with a string value
+func attribute(key, value string) pb {
+	return pb{}.str(1, key).bytes(2, pb{}.str(1, value))
+}
+
+// testExportRequest has a resource with a gauge, a cumulative monotonic sum
+// with an int value and a delta histogram
+func testExportRequest() []byte {
+	resource := pb{}.
+		bytes(1, attribute("service.name", "checkout")).
+		bytes(1, attribute("host.name", "web-1")).
+		bytes(1, attribute("cloud.region", "eu-west-1"))
+
+	gauge := pb{}.str(1, "queue.depth").bytes(5, pb{}.bytes(1, pb{}.
+		bytes(7, attribute("queue", "orders")).
+		fixed64(3, 1500000000000000000).
+		double(4, 12.5)))
+
+	sum := pb{}.str(1, "requests").str(3, "1").bytes(7, pb{}.
+		bytes(1, pb{}.fixed64(2, 1400000000000000000).fixed64(3, 1500000000000000000).fixed64(6, 42)).
+		varint(2, temporalityCumulative).
+		varint(3, 1))
+
+	histogram := pb{}.str(1, "latency").bytes(9, pb{}.
+		bytes(1, pb{}.
+			fixed64(4, 6).
+			double(5, 3.5).
+			fixed64(6, 1).fixed64(6, 3).fixed64(6, 2).
+			packedDoubles(7, 0.1, 1)).
+		varint(2, temporalityDelta))
+
+	summary := pb{}.str(1, "legacy").bytes(11, pb{}.bytes(1, pb{}))
+
+	scope := pb{}.bytes(1, pb{}.str(1, "instrumentation")).
+		bytes(2, gauge).bytes(2, sum).bytes(2, histogram).bytes(2, summary)
+	return pb{}.bytes(1, pb{}.bytes(1, resource).bytes(2, scope).str(3, "https://opentelemetry.io/schemas/1.9.0"))
+}
+
+func TestDecodeExportRequest(t *testing.T) {
+	resources, err := decodeExportRequest(testExportRequest())
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+
+	rm := resources[0]
+	assert.Equal(t, []keyValue{
+		{"service.name", "checkout"},
+		{"host.name", "web-1"},
+		{"cloud.region", "eu-west-1"},
+	}, rm.attributes)
+	require.Len(t, rm.metrics, 4)
+
+	assert.Equal(t, metric{
+		name: "queue.depth",
+		kind: kindGauge,
+		points: []numberPoint{
+			{attributes: []keyValue{{"queue", "orders"}}, time: 1500000000000000000, value: 12.5},
+		},
+	}, rm.metrics[0])
+
+	assert.Equal(t, metric{
+		name:        "requests",
+		kind:        kindSum,
+		temporality: temporalityCumulative,
+		monotonic:   true,
+		points: []numberPoint{
+			{startTime: 1400000000000000000, time: 1500000000000000000, value: 42},
+		},
+	}, rm.metrics[1])
+
+	assert.Equal(t, metric{
+		name:        "latency",
+		kind:        kindHistogram,
+		temporality: temporalityDelta,
+		histograms: []histogramPoint{
+			{count: 6, sum: 3.5, hasSum: true, bucketCounts: []uint64{1, 3, 2}, bounds: []float64{0.1, 1}},
+		},
+	}, rm.metrics[2])
+
+	assert.Equal(t, metric{name: "legacy", kind: kindUnsupported}, rm.metrics[3])
+}
+
+func TestDecodeAnyValue(t *testing.T) {
+	minusThree := int64(-3)
+	for name, tc := range map[string]struct {
+		value    pb
+		expected string
+	}{
+		"string": {pb{}.str(1, "foo"), "foo"},
+		"bool":   {pb{}.varint(2, 1), "true"},
+		"int":    {pb{}.varint(3, uint64(minusThree)), "-3"},
+		"double": {pb{}.double(4, 0.25), "0.25"},
+		"array":  {pb{}.bytes(5, pb{}.bytes(1, pb{}.str(1, "foo"))), ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			value, err := decodeAnyValue(tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	request := testExportRequest()
+	_, err := decodeExportRequest(request[:len(request)-3])
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+/*
+Package otlp receives the metrics of the applications instrumented with
+OpenTelemetry, so they are sent through the local agent without running a
+separate collector. The OTLP exports are accepted over HTTP with protobuf
+payloads, their metrics are mapped to the types of the aggregator and the
+attributes of their resource to tags. OTLP over gRPC isn't supported as
+grpc-go isn't vendored.
+*/
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"expvar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	stdLog "log"
+	"net"
+	"net/http"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	// httpExportPath is the endpoint of the OTLP/HTTP metrics
+	httpExportPath = "/v1/metrics"
+	// maxRequestSize is the size of the largest export, the default of the
+	// OpenTelemetry collector
+	maxRequestSize = 4 * 1024 * 1024
+
+	defaultTimeout = 10 * time.Second
+)
+
+var otlpExpvar = expvar.NewMap("otlp")
+
+// Receiver receives the OTLP metrics and sends them to the aggregator
+type Receiver struct {
+	metricOut  chan<- *metrics.MetricSample
+	translator *translator
+
+	listener   net.Listener
+	httpServer *http.Server
+}
+
+// NewReceiver returns a running Receiver listening on the HTTP port of the
+// configuration
+func NewReceiver(metricOut chan<- *metrics.MetricSample) (*Receiver, error) {
+	r := &Receiver{
+		metricOut:  metricOut,
+		translator: newTranslator(config.Datadog.GetBool("otlp.resource_attributes_as_tags")),
+	}
+
+	addr := fmt.Sprintf("%s:%d", config.Datadog.GetString("otlp.bind_host"), config.Datadog.GetInt("otlp.http_port"))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen to the OTLP HTTP port: %s", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(httpExportPath, r.handleHTTP)
+	r.listener = listener
+	r.httpServer = &http.Server{
+		Handler:      mux,
+		ErrorLog:     stdLog.New(&config.ErrorLogWriter{}, "", 0), // log errors to seelog
+		ReadTimeout:  defaultTimeout,
+		WriteTimeout: defaultTimeout,
+	}
+	go r.httpServer.Serve(listener)
+	log.Infof("OTLP receiver is listening for HTTP at %s", listener.Addr())
+	return r, nil
+}
+
+// Stop closes the listener and the connections of the receiver
+func (r *Receiver) Stop() {
+	r.httpServer.Close()
+}
+
+// handleHTTP handles an OTLP/HTTP export with a protobuf payload
+func (r *Receiver) handleHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	if req.Header.Get("Content-Type") != "application/x-protobuf" {
+		http.Error(w, "only the application/x-protobuf content type is supported", http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxRequestSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxRequestSize {
+		http.Error(w, "the request is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		if body, err = gunzip(body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := r.export(body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// an empty ExportMetricsServiceResponse
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+// export decodes an ExportMetricsServiceRequest and sends its metrics to the
+// aggregator
+func (r *Receiver) export(message []byte) error {
+	otlpExpvar.Add("Requests", 1)
+	resources, err := decodeExportRequest(message)
+	if err != nil {
+		otlpExpvar.Add("Errors", 1)
+		log.Debugf("Could not decode an OTLP export: %s", err)
+		return fmt.Errorf("could not decode the metrics: %s", err)
+	}
+	now := time.Now()
+	for _, rm := range resources {
+		samples, unsupported := r.translator.translate(rm, now)
+		otlpExpvar.Add("UnsupportedMetrics", int64(unsupported))
+		otlpExpvar.Add("MetricSamples", int64(len(samples)))
+		for _, sample := range samples {
+			r.metricOut <- sample
+		}
+	}
+	return nil
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(io.LimitReader(reader, maxRequestSize))
+}
@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package otlp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func newTestReceiver() (*Receiver, chan *metrics.MetricSample) {
+	metricOut := make(chan *metrics.MetricSample, 100)
+	return &Receiver{
+		metricOut:  metricOut,
+		translator: newTranslator(false),
+	}, metricOut
+}
+
+func TestHandleHTTP(t *testing.T) {
+	r, metricOut := newTestReceiver()
+
+	req := httptest.NewRequest("POST", httpExportPath, bytes.NewReader(testExportRequest()))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	rec := httptest.NewRecorder()
+	r.handleHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-protobuf", rec.Header().Get("Content-Type"))
+	assert.Len(t, metricOut, 6)
+}
+
+func TestHandleHTTPErrors(t *testing.T) {
+	r, _ := newTestReceiver()
+
+	req := httptest.NewRequest("POST", httpExportPath, bytes.NewReader([]byte("{}")))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.handleHTTP(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	req = httptest.NewRequest("POST", httpExportPath, bytes.NewReader([]byte{0x0a, 0xff}))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	rec = httptest.NewRecorder()
+	r.handleHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,206 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package otlp
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// resourceTags maps the semantic conventions of the resource attributes to
+// the tags of the unified service tagging
+var resourceTags = map[string]string{
+	"service.name":           "service",
+	"service.version":        "version",
+	"deployment.environment": "env",
+	"container.id":           "container_id",
+	"k8s.pod.name":           "pod_name",
+	"k8s.namespace.name":     "kube_namespace",
+}
+
+// hostAttribute is the resource attribute setting the host of the metrics
+const hostAttribute = "host.name"
+
+// cumulativeExpiry is how long the last value of a cumulative series is kept
+// after its last point
+const cumulativeExpiry = time.Hour
+
+// cumulativePoint is the last point of a cumulative series, the aggregator
+// counts the delta with the next one
+type cumulativePoint struct {
+	startTime uint64
+	value     float64
+	lastSeen  time.Time
+}
+
+// translator maps the OTLP metrics to metric samples
+type translator struct {
+	// allResourceTags also adds the resource attributes not mapped to a tag
+	allResourceTags bool
+
+	mu         sync.Mutex
+	cumulative map[string]*cumulativePoint
+	lastSweep  time.Time
+}
+
+func newTranslator(allResourceTags bool) *translator {
+	return &translator{
+		allResourceTags: allResourceTags,
+		cumulative:      make(map[string]*cumulativePoint),
+		lastSweep:       time.Now(),
+	}
+}
+
+// translate maps the metrics of a resource to metric samples: the gauges and
+// the non-monotonic sums are gauges, the monotonic sums are counts and the
+// histograms are the counts of their samples, of their sum and of their
+// buckets. The cumulative values are turned into deltas. The samples are at
+// the time of their point, now if it is not set. It returns the samples and
+// the number of metrics of an unsupported type.
+func (t *translator) translate(rm resourceMetrics, now time.Time) ([]*metrics.MetricSample, int) {
+	host, tags := t.resourceTags(rm.attributes)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sweep(now)
+
+	var samples []*metrics.MetricSample
+	unsupported := 0
+	add := func(name string, value float64, mtype metrics.MetricType, tags []string, pointTime uint64) {
+		timestamp := float64(now.Unix())
+		if pointTime != 0 {
+			timestamp = float64(pointTime) / float64(time.Second)
+		}
+		samples = append(samples, &metrics.MetricSample{
+			Name:       name,
+			Value:      value,
+			Mtype:      mtype,
+			Tags:       tags,
+			Host:       host,
+			SampleRate: 1,
+			Timestamp:  timestamp,
+		})
+	}
+
+	for _, m := range rm.metrics {
+		switch {
+		case m.kind == kindGauge || (m.kind == kindSum && !m.monotonic):
+			for _, p := range m.points {
+				add(m.name, p.value, metrics.GaugeType, pointTags(tags, p.attributes), p.time)
+			}
+		case m.kind == kindSum:
+			for _, p := range m.points {
+				pTags := pointTags(tags, p.attributes)
+				if value, ok := t.delta(m.temporality, host, m.name, pTags, p.startTime, p.value, now); ok {
+					add(m.name, value, metrics.CountType, pTags, p.time)
+				}
+			}
+		case m.kind == kindHistogram:
+			for _, p := range m.histograms {
+				pTags := pointTags(tags, p.attributes)
+				if value, ok := t.delta(m.temporality, host, m.name+".count", pTags, p.startTime, float64(p.count), now); ok {
+					add(m.name+".count", value, metrics.CountType, pTags, p.time)
+				}
+				if p.hasSum {
+					if value, ok := t.delta(m.temporality, host, m.name+".sum", pTags, p.startTime, p.sum, now); ok {
+						add(m.name+".sum", value, metrics.CountType, pTags, p.time)
+					}
+				}
+				for i, count := range p.bucketCounts {
+					bTags := append(append(make([]string, 0, len(pTags)+1), pTags...), "upper_bound:"+upperBound(p.bounds, i))
+					if value, ok := t.delta(m.temporality, host, m.name+".bucket", bTags, p.startTime, float64(count), now); ok {
+						add(m.name+".bucket", value, metrics.CountType, bTags, p.time)
+					}
+				}
+			}
+		default:
+			unsupported++
+		}
+	}
+	return samples, unsupported
+}
+
+// delta returns the value of a delta point, or the difference with the last
+// point of a cumulative series. The first point of a cumulative series, and
+// the first one after a reset, only set the base of the next delta. A reset
+// changes the start time, or decreases the value of the senders not setting
+// the start time.
+func (t *translator) delta(temporality int, host, name string, tags []string, startTime uint64, value float64, now time.Time) (float64, bool) {
+	if temporality != temporalityCumulative {
+		return value, true
+	}
+	key := name + "|" + host + "|" + strings.Join(tags, ",")
+	last, ok := t.cumulative[key]
+	if !ok {
+		t.cumulative[key] = &cumulativePoint{startTime: startTime, value: value, lastSeen: now}
+		return 0, false
+	}
+	reset := startTime != last.startTime || (startTime == 0 && value < last.value)
+	delta := value - last.value
+	last.startTime, last.value, last.lastSeen = startTime, value, now
+	if reset {
+		return 0, false
+	}
+	return delta, true
+}
+
+// sweep forgets the cumulative series without recent points, at most once a
+// minute
+func (t *translator) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < time.Minute {
+		return
+	}
+	t.lastSweep = now
+	for key, p := range t.cumulative {
+		if now.Sub(p.lastSeen) > cumulativeExpiry {
+			delete(t.cumulative, key)
+		}
+	}
+}
+
+// resourceTags returns the host and the tags of the resource attributes
+func (t *translator) resourceTags(attributes []keyValue) (string, []string) {
+	var host string
+	var tags []string
+	for _, kv := range attributes {
+		if kv.key == hostAttribute {
+			host = kv.value
+			continue
+		}
+		if tag, ok := resourceTags[kv.key]; ok {
+			tags = append(tags, tag+":"+kv.value)
+		} else if t.allResourceTags {
+			tags = append(tags, kv.key+":"+kv.value)
+		}
+	}
+	return host, tags
+}
+
+// pointTags returns the tags of the resource and the attributes of a point,
+// sorted so a series has the same key at every point
+func pointTags(resourceTags []string, attributes []keyValue) []string {
+	tags := make([]string, 0, len(resourceTags)+len(attributes))
+	tags = append(tags, resourceTags...)
+	for _, kv := range attributes {
+		tags = append(tags, kv.key+":"+kv.value)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// upperBound returns the upper bound of the i-th bucket, the last bucket
+// has no upper bound
+func upperBound(bounds []float64, i int) string {
+	if i >= len(bounds) {
+		return "inf"
+	}
+	return strconv.FormatFloat(bounds[i], 'f', -1, 64)
+}
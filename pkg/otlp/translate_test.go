@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestTranslate(t *testing.T) {
+	resources, err := decodeExportRequest(testExportRequest())
+	require.NoError(t, err)
+	now := time.Unix(1500000060, 0)
+	tr := newTranslator(false)
+
+	samples, unsupported := tr.translate(resources[0], now)
+	assert.Equal(t, 1, unsupported)
+
+	sample := func(name string, value float64, mtype metrics.MetricType, timestamp float64, tags ...string) *metrics.MetricSample {
+		return &metrics.MetricSample{
+			Name:       name,
+			Value:      value,
+			Mtype:      mtype,
+			Tags:       tags,
+			Host:       "web-1",
+			SampleRate: 1,
+			Timestamp:  timestamp,
+		}
+	}
+	// the first point of the cumulative sum is only the base of the next delta,
+	// the points of the histogram have no time
+	assert.Equal(t, []*metrics.MetricSample{
+		sample("queue.depth", 12.5, metrics.GaugeType, 1500000000, "queue:orders", "service:checkout"),
+		sample("latency.count", 6, metrics.CountType, 1500000060, "service:checkout"),
+		sample("latency.sum", 3.5, metrics.CountType, 1500000060, "service:checkout"),
+		sample("latency.bucket", 1, metrics.CountType, 1500000060, "service:checkout", "upper_bound:0.1"),
+		sample("latency.bucket", 3, metrics.CountType, 1500000060, "service:checkout", "upper_bound:1"),
+		sample("latency.bucket", 2, metrics.CountType, 1500000060, "service:checkout", "upper_bound:inf"),
+	}, samples)
+}
+
+func TestTranslateAllResourceTags(t *testing.T) {
+	tr := newTranslator(true)
+	host, tags := tr.resourceTags([]keyValue{
+		{"service.name", "checkout"},
+		{"deployment.environment", "prod"},
+		{"host.name", "web-1"},
+		{"cloud.region", "eu-west-1"},
+	})
+	assert.Equal(t, "web-1", host)
+	assert.Equal(t, []string{"service:checkout", "env:prod", "cloud.region:eu-west-1"}, tags)
+
+	_, tags = newTranslator(false).resourceTags([]keyValue{{"cloud.region", "eu-west-1"}})
+	assert.Empty(t, tags)
+}
+
+func TestTranslateCumulativeSum(t *testing.T) {
+	tr := newTranslator(false)
+	now := time.Now()
+	rm := func(startTime uint64, value float64) resourceMetrics {
+		return resourceMetrics{metrics: []metric{{
+			name:        "requests",
+			kind:        kindSum,
+			temporality: temporalityCumulative,
+			monotonic:   true,
+			points:      []numberPoint{{startTime: startTime, value: value}},
+		}}}
+	}
+
+	samples, _ := tr.translate(rm(100, 10), now)
+	assert.Len(t, samples, 0)
+
+	samples, _ = tr.translate(rm(100, 25), now)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 15.0, samples[0].Value)
+	assert.Equal(t, metrics.CountType, samples[0].Mtype)
+
+	// the sender restarted, the new point is the base of the next delta
+	samples, _ = tr.translate(rm(200, 3), now)
+	assert.Len(t, samples, 0)
+
+	samples, _ = tr.translate(rm(200, 5), now)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 2.0, samples[0].Value)
+
+	// the series without recent points are forgotten
+	tr.translate(resourceMetrics{}, now.Add(2*cumulativeExpiry))
+	assert.Len(t, tr.cumulative, 0)
+}
+
+func TestTranslateNonMonotonicSum(t *testing.T) {
+	samples, _ := newTranslator(false).translate(resourceMetrics{metrics: []metric{{
+		name:        "connections",
+		kind:        kindSum,
+		temporality: temporalityCumulative,
+		points:      []numberPoint{{value: 7}},
+	}}}, time.Now())
+	require.Len(t, samples, 1)
+	assert.Equal(t, metrics.GaugeType, samples[0].Mtype)
+	assert.Equal(t, 7.0, samples[0].Value)
+}
@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package remoteconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+// The local consents to the flares requested by the backend
+const (
+	// FlareConsentNever refuses every request
+	FlareConsentNever = "never"
+	// FlareConsentWithCase accepts the requests referencing a support case
+	FlareConsentWithCase = "with_case"
+	// FlareConsentAlways accepts every request
+	FlareConsentAlways = "always"
+)
+
+// The statuses of the flares requested
+const (
+	FlareStatusSent    = "sent"
+	FlareStatusRefused = "refused"
+	FlareStatusFailed  = "failed"
+)
+
+// FlareRequest is a flare requested by the backend with a configuration update,
+// it is handled once, when the update is applied.
+type FlareRequest struct {
+	ID     string `json:"id"`
+	CaseID string `json:"case_id,omitempty"`
+	Email  string `json:"email,omitempty"`
+}
+
+// FlareState is the outcome of a flare request, it is reported with the state
+// of the configuration.
+type FlareState struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// FlarePolicy is the local consent to the flares requested by the backend,
+// MinInterval is the minimum duration between two flares sent. The ID of the
+// last flare handled is persisted in HandledFile, when set, so that a request
+// is not handled again when the agent restarts.
+type FlarePolicy struct {
+	Consent     string
+	MinInterval time.Duration
+	HandledFile string
+}
+
+// FlareSender creates a flare and uploads it, it returns the response of the backend.
+type FlareSender func(caseID string, email string) (string, error)
+
+// HandleFlares makes the poller send the flares requested by the backend with
+// send, when policy allows them. The requests are refused until it is called.
+func (p *Poller) HandleFlares(send FlareSender, policy FlarePolicy) error {
+	switch policy.Consent {
+	case FlareConsentNever, FlareConsentWithCase, FlareConsentAlways:
+	default:
+		return fmt.Errorf("invalid flare consent %q, the valid consents are: %s, %s and %s", policy.Consent, FlareConsentNever, FlareConsentWithCase, FlareConsentAlways)
+	}
+	lastFlareID := ""
+	if policy.HandledFile != "" {
+		content, err := ioutil.ReadFile(policy.HandledFile)
+		if err != nil && !os.IsNotExist(err) {
+			log.Warnf("Could not read the last flare requested by the remote configuration: %s", err)
+		}
+		lastFlareID = strings.TrimSpace(string(content))
+	}
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.sendFlare = send
+	p.flarePolicy = policy
+	p.lastFlareID = lastFlareID
+	return nil
+}
+
+// handleFlare sends the flare of a request when the policy allows it and
+// returns the state with its outcome.
+func (p *Poller) handleFlare(request *FlareRequest) State {
+	p.m.Lock()
+	send, policy, lastFlare, lastFlareID := p.sendFlare, p.flarePolicy, p.lastFlare, p.lastFlareID
+	p.m.Unlock()
+
+	if request.ID == lastFlareID {
+		log.Debugf("Ignoring the flare %s requested by the remote configuration, it was already handled", request.ID)
+		return p.State()
+	}
+
+	flareState := &FlareState{ID: request.ID}
+	if reason := refusal(send, policy, lastFlare, request); reason != "" {
+		log.Warnf("Refusing the flare %s requested by the remote configuration: %s", request.ID, reason)
+		flareState.Status = FlareStatusRefused
+		flareState.Message = reason
+	} else {
+		log.Infof("Sending the flare %s requested by the remote configuration", request.ID)
+		response, err := send(request.CaseID, request.Email)
+		if err != nil {
+			log.Errorf("Could not send the flare %s requested by the remote configuration: %s", request.ID, err)
+			flareState.Status = FlareStatusFailed
+			flareState.Message = err.Error()
+		} else {
+			flareState.Status = FlareStatusSent
+			flareState.Message = response
+		}
+	}
+
+	if policy.HandledFile != "" {
+		if err := ioutil.WriteFile(policy.HandledFile, []byte(request.ID), 0600); err != nil {
+			log.Warnf("Could not persist the flare %s requested by the remote configuration: %s", request.ID, err)
+		}
+	}
+
+	p.m.Lock()
+	defer p.m.Unlock()
+	if flareState.Status != FlareStatusRefused {
+		p.lastFlare = time.Now()
+	}
+	p.lastFlareID = request.ID
+	p.state.Flare = flareState
+	return p.state
+}
+
+// refusal returns why a flare request is refused, or an empty string when it is accepted.
+func refusal(send FlareSender, policy FlarePolicy, lastFlare time.Time, request *FlareRequest) string {
+	switch {
+	case send == nil:
+		return "the flares are not supported by this agent"
+	case policy.Consent == FlareConsentWithCase && request.CaseID == "":
+		return "the flares are only allowed for a support case"
+	case policy.Consent != FlareConsentWithCase && policy.Consent != FlareConsentAlways:
+		return "the flares are not allowed by the agent configuration"
+	case !lastFlare.IsZero() && time.Since(lastFlare) < policy.MinInterval:
+		return fmt.Sprintf("a flare was sent less than %s ago", policy.MinInterval)
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package remoteconfig
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+)
+
+// flareRecorder is a FlareSender recording the flares sent
+type flareRecorder struct {
+	caseIDs []string
+	err     error
+}
+
+func (r *flareRecorder) send(caseID string, email string) (string, error) {
+	if r.err != nil {
+		return "", r.err
+	}
+	r.caseIDs = append(r.caseIDs, caseID)
+	return "uploaded", nil
+}
+
+// pollFlare applies an update of version requesting a flare and returns the state reported.
+func pollFlare(t *testing.T, f testFlarePoller, version uint64, request FlareRequest) State {
+	p, fwd := f.poller, f.fwd
+	update := sign(t, f.key, Config{Version: version, Flare: &request})
+	fwd.On("FetchRemoteConfig", version-1).Return(update, nil).Once()
+	fwd.On("SubmitRemoteConfigState", mock.Anything, stateHeaders).Return(nil).Once()
+	p.poll()
+	fwd.AssertExpectations(t)
+
+	reported := State{}
+	payloads := fwd.Calls[len(fwd.Calls)-1].Arguments.Get(0).(forwarder.Payloads)
+	require.Nil(t, json.Unmarshal(*payloads[0], &reported))
+	require.NotNil(t, reported.Flare)
+	assert.Equal(t, request.ID, reported.Flare.ID)
+	return reported
+}
+
+// testFlarePoller is a poller with its mocked forwarder and the key signing its updates
+type testFlarePoller struct {
+	poller *Poller
+	fwd    *forwarder.MockedForwarder
+	key    *ecdsa.PrivateKey
+}
+
+func newFlarePoller(t *testing.T) testFlarePoller {
+	key, publicKey := generateKey(t)
+	fwd := &forwarder.MockedForwarder{}
+	p, err := NewPoller(fwd, publicKey, time.Minute, "host")
+	require.Nil(t, err)
+	return testFlarePoller{poller: p, fwd: fwd, key: key}
+}
+
+func TestHandleFlaresInvalidConsent(t *testing.T) {
+	f := newFlarePoller(t)
+	recorder := &flareRecorder{}
+	assert.NotNil(t, f.poller.HandleFlares(recorder.send, FlarePolicy{Consent: "sometimes"}))
+}
+
+func TestFlareRefusedByDefault(t *testing.T) {
+	f := newFlarePoller(t)
+
+	state := pollFlare(t, f, 1, FlareRequest{ID: "f1", CaseID: "42"})
+	assert.Equal(t, FlareStatusRefused, state.Flare.Status)
+
+	recorder := &flareRecorder{}
+	require.Nil(t, f.poller.HandleFlares(recorder.send, FlarePolicy{Consent: FlareConsentNever}))
+	state = pollFlare(t, f, 2, FlareRequest{ID: "f2", CaseID: "42"})
+	assert.Equal(t, FlareStatusRefused, state.Flare.Status)
+	assert.Len(t, recorder.caseIDs, 0)
+}
+
+func TestFlareWithCase(t *testing.T) {
+	f := newFlarePoller(t)
+	recorder := &flareRecorder{}
+	require.Nil(t, f.poller.HandleFlares(recorder.send, FlarePolicy{Consent: FlareConsentWithCase}))
+
+	state := pollFlare(t, f, 1, FlareRequest{ID: "f1"})
+	assert.Equal(t, FlareStatusRefused, state.Flare.Status)
+
+	state = pollFlare(t, f, 2, FlareRequest{ID: "f2", CaseID: "42"})
+	assert.Equal(t, FlareStatusSent, state.Flare.Status)
+	assert.Equal(t, "uploaded", state.Flare.Message)
+	assert.Equal(t, []string{"42"}, recorder.caseIDs)
+}
+
+func TestFlareMinInterval(t *testing.T) {
+	f := newFlarePoller(t)
+	recorder := &flareRecorder{}
+	require.Nil(t, f.poller.HandleFlares(recorder.send, FlarePolicy{Consent: FlareConsentAlways, MinInterval: time.Hour}))
+
+	state := pollFlare(t, f, 1, FlareRequest{ID: "f1"})
+	assert.Equal(t, FlareStatusSent, state.Flare.Status)
+	state = pollFlare(t, f, 2, FlareRequest{ID: "f2"})
+	assert.Equal(t, FlareStatusRefused, state.Flare.Status)
+	assert.Len(t, recorder.caseIDs, 1)
+
+	// the updates without request clear the state of the flare
+	f.fwd.On("FetchRemoteConfig", uint64(2)).Return(sign(t, f.key, Config{Version: 3}), nil).Once()
+	f.fwd.On("SubmitRemoteConfigState", mock.Anything, stateHeaders).Return(nil).Once()
+	f.poller.poll()
+	assert.Nil(t, f.poller.State().Flare)
+}
+
+func TestFlareFailed(t *testing.T) {
+	f := newFlarePoller(t)
+	recorder := &flareRecorder{err: errors.New("no route to host")}
+	require.Nil(t, f.poller.HandleFlares(recorder.send, FlarePolicy{Consent: FlareConsentAlways}))
+
+	state := pollFlare(t, f, 1, FlareRequest{ID: "f1"})
+	assert.Equal(t, FlareStatusFailed, state.Flare.Status)
+	assert.Equal(t, "no route to host", state.Flare.Message)
+}
+
+func TestFlareHandledOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "remote-flare")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	policy := FlarePolicy{Consent: FlareConsentAlways, HandledFile: filepath.Join(dir, "remote_flare")}
+
+	f := newFlarePoller(t)
+	recorder := &flareRecorder{}
+	require.Nil(t, f.poller.HandleFlares(recorder.send, policy))
+	state := pollFlare(t, f, 1, FlareRequest{ID: "f1"})
+	assert.Equal(t, FlareStatusSent, state.Flare.Status)
+
+	// the request is not handled again after a restart
+	f = newFlarePoller(t)
+	require.Nil(t, f.poller.HandleFlares(recorder.send, policy))
+	update := sign(t, f.key, Config{Version: 1, Flare: &FlareRequest{ID: "f1"}})
+	f.fwd.On("FetchRemoteConfig", uint64(0)).Return(update, nil).Once()
+	f.fwd.On("SubmitRemoteConfigState", mock.Anything, stateHeaders).Return(nil).Once()
+	f.poller.poll()
+	f.fwd.AssertExpectations(t)
+	assert.Nil(t, f.poller.State().Flare)
+	assert.Len(t, recorder.caseIDs, 1)
+}
@@ -0,0 +1,194 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package remoteconfig
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+)
+
+var remoteConfigExpvar = expvar.NewMap("remoteconfig")
+
+// stateHeaders are the headers of the state payloads
+var stateHeaders = http.Header{"Content-Type": {"application/json"}}
+
+// State is the state of the remote configuration applied, it is reported to
+// the backend after every update.
+type State struct {
+	Hostname  string            `json:"hostname"`
+	Version   uint64            `json:"version"`
+	AppliedAt int64             `json:"applied_at,omitempty"`
+	Applied   map[string]string `json:"applied,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+	LastError string            `json:"last_error,omitempty"`
+	Flare     *FlareState       `json:"flare,omitempty"`
+}
+
+// Poller fetches the remote configuration periodically through the forwarder
+// and applies it.
+type Poller struct {
+	forwarder forwarder.Forwarder
+	publicKey *ecdsa.PublicKey
+	interval  time.Duration
+	state     State
+	m         sync.Mutex
+	stop      chan struct{}
+	stopped   chan struct{}
+
+	// the flares requested, see HandleFlares
+	sendFlare   FlareSender
+	flarePolicy FlarePolicy
+	lastFlare   time.Time
+	lastFlareID string
+}
+
+// NewPoller returns a poller fetching the updates every interval, they must
+// be signed by the private key of publicKey, a PEM encoded ECDSA key.
+func NewPoller(fwd forwarder.Forwarder, publicKey string, interval time.Duration, hostname string) (*Poller, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid refresh interval %s, it must be positive", interval)
+	}
+	key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	p := &Poller{
+		forwarder: fwd,
+		publicKey: key,
+		interval:  interval,
+		state:     State{Hostname: hostname},
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	remoteConfigExpvar.Set("State", expvar.Func(func() interface{} { return p.State() }))
+	return p, nil
+}
+
+// Start fetches the remote configuration until the poller is stopped.
+func (p *Poller) Start() {
+	go p.run()
+}
+
+// Stop stops the poller, the settings keep their value.
+func (p *Poller) Stop() {
+	close(p.stop)
+	<-p.stopped
+}
+
+// State returns the state of the remote configuration applied.
+func (p *Poller) State() State {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.state
+}
+
+func (p *Poller) run() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.poll()
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches the remote configuration, applies it when it is more recent than
+// the one applied, sends the flare it requests, and reports the state.
+func (p *Poller) poll() {
+	p.m.Lock()
+	currentVersion := p.state.Version
+	p.m.Unlock()
+
+	data, err := p.forwarder.FetchRemoteConfig(currentVersion)
+	if err != nil {
+		p.setError("could not fetch the remote configuration: %s", err)
+		return
+	}
+	if data == nil {
+		return
+	}
+	config, err := verify(data, p.publicKey)
+	if err != nil {
+		p.setError("%s", err)
+		return
+	}
+	if config.Version <= currentVersion {
+		log.Debugf("Ignoring the remote configuration %d, the configuration %d is applied", config.Version, currentVersion)
+		return
+	}
+
+	state := p.apply(config)
+	if config.Flare != nil {
+		state = p.handleFlare(config.Flare)
+	}
+	p.report(state)
+}
+
+// apply sets the settings of a configuration, in the order of their names.
+func (p *Poller) apply(config *Config) State {
+	names := make([]string, 0, len(config.Settings))
+	for name := range config.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	applied := map[string]string{}
+	failed := map[string]string{}
+	for _, name := range names {
+		value := config.Settings[name]
+		if err := settings.SetRuntimeSetting(name, value, 0); err != nil {
+			log.Warnf("Could not apply the setting %s of the remote configuration %d: %s", name, config.Version, err)
+			failed[name] = err.Error()
+			continue
+		}
+		applied[name] = value
+	}
+	log.Infof("Applied %d settings of the remote configuration %d", len(applied), config.Version)
+
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.state.Version = config.Version
+	p.state.AppliedAt = time.Now().Unix()
+	p.state.Applied = applied
+	p.state.Errors = failed
+	p.state.LastError = ""
+	p.state.Flare = nil
+	return p.state
+}
+
+// report sends the state of the configuration applied to the backend.
+func (p *Poller) report(state State) {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		log.Errorf("Could not serialize the state of the remote configuration: %s", err)
+		return
+	}
+	if err := p.forwarder.SubmitRemoteConfigState(forwarder.Payloads{&payload}, stateHeaders); err != nil {
+		log.Errorf("Could not send the state of the remote configuration: %s", err)
+	}
+}
+
+func (p *Poller) setError(format string, args ...interface{}) {
+	err := log.Warnf(format, args...)
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.state.LastError = err.Error()
+}
@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package remoteconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+)
+
+// testSetting is a runtime setting only accepting the values starting with "ok"
+type testSetting struct {
+	name  string
+	value string
+}
+
+func (s *testSetting) Name() string              { return s.name }
+func (s *testSetting) Description() string       { return "" }
+func (s *testSetting) Get() (interface{}, error) { return s.value, nil }
+func (s *testSetting) Set(value string) error {
+	if len(value) < 2 || value[:2] != "ok" {
+		return errors.New("invalid value")
+	}
+	s.value = value
+	return nil
+}
+
+var (
+	settingA = &testSetting{name: "remoteconfig_test_a"}
+	settingB = &testSetting{name: "remoteconfig_test_b"}
+)
+
+func init() {
+	settings.RegisterRuntimeSetting(settingA)
+	settings.RegisterRuntimeSetting(settingB)
+}
+
+func generateKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.Nil(t, err)
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, config Config) []byte {
+	data, err := json.Marshal(config)
+	require.Nil(t, err)
+	hash := sha256.Sum256(data)
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	require.Nil(t, err)
+	signature, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+	require.Nil(t, err)
+	signed, err := json.Marshal(signedConfig{Config: data, Signature: signature})
+	require.Nil(t, err)
+	return signed
+}
+
+func TestNewPollerInvalidKey(t *testing.T) {
+	_, err := NewPoller(&forwarder.MockedForwarder{}, "", time.Minute, "host")
+	assert.NotNil(t, err)
+	_, err = NewPoller(&forwarder.MockedForwarder{}, "-----BEGIN PUBLIC KEY-----\nZm9v\n-----END PUBLIC KEY-----\n", time.Minute, "host")
+	assert.NotNil(t, err)
+}
+
+func TestNewPollerInvalidInterval(t *testing.T) {
+	_, publicKey := generateKey(t)
+	_, err := NewPoller(&forwarder.MockedForwarder{}, publicKey, 0, "host")
+	assert.NotNil(t, err)
+	_, err = NewPoller(&forwarder.MockedForwarder{}, publicKey, -time.Second, "host")
+	assert.NotNil(t, err)
+}
+
+func TestPollAppliesTheSettings(t *testing.T) {
+	key, publicKey := generateKey(t)
+	fwd := &forwarder.MockedForwarder{}
+	p, err := NewPoller(fwd, publicKey, time.Minute, "host")
+	require.Nil(t, err)
+
+	update := sign(t, key, Config{Version: 3, Settings: map[string]string{
+		"remoteconfig_test_a": "ok_a",
+		"remoteconfig_test_b": "invalid",
+		"unknown_setting":     "ok",
+	}})
+	fwd.On("FetchRemoteConfig", uint64(0)).Return(update, nil).Once()
+	fwd.On("SubmitRemoteConfigState", mock.Anything, stateHeaders).Return(nil).Once()
+	p.poll()
+	fwd.AssertExpectations(t)
+
+	assert.Equal(t, "ok_a", settingA.value)
+	assert.Equal(t, "", settingB.value)
+	state := p.State()
+	assert.Equal(t, uint64(3), state.Version)
+	assert.Equal(t, "host", state.Hostname)
+	assert.Equal(t, map[string]string{"remoteconfig_test_a": "ok_a"}, state.Applied)
+	assert.Len(t, state.Errors, 2)
+	assert.Equal(t, "", state.LastError)
+
+	reported := State{}
+	payloads := fwd.Calls[1].Arguments.Get(0).(forwarder.Payloads)
+	require.Len(t, payloads, 1)
+	require.Nil(t, json.Unmarshal(*payloads[0], &reported))
+	assert.Equal(t, state, reported)
+
+	// not modified
+	fwd.On("FetchRemoteConfig", uint64(3)).Return(nil, nil).Once()
+	p.poll()
+	fwd.AssertExpectations(t)
+	assert.Equal(t, uint64(3), p.State().Version)
+}
+
+func TestPollIgnoresTheInvalidUpdates(t *testing.T) {
+	key, publicKey := generateKey(t)
+	otherKey, _ := generateKey(t)
+	fwd := &forwarder.MockedForwarder{}
+	p, err := NewPoller(fwd, publicKey, time.Minute, "host")
+	require.Nil(t, err)
+	p.state.Version = 5
+
+	// signed by another key
+	fwd.On("FetchRemoteConfig", uint64(5)).Return(sign(t, otherKey, Config{Version: 6, Settings: map[string]string{"remoteconfig_test_b": "ok_other"}}), nil).Once()
+	p.poll()
+	assert.NotEqual(t, "", p.State().LastError)
+
+	// tampered
+	update := sign(t, key, Config{Version: 6, Settings: map[string]string{"remoteconfig_test_b": "ok_b"}})
+	signed := signedConfig{}
+	require.Nil(t, json.Unmarshal(update, &signed))
+	signed.Config = []byte(`{"version": 6, "settings": {"remoteconfig_test_b": "ok_tampered"}}`)
+	tampered, _ := json.Marshal(signed)
+	fwd.On("FetchRemoteConfig", uint64(5)).Return(tampered, nil).Once()
+	p.poll()
+
+	// older than the one applied
+	fwd.On("FetchRemoteConfig", uint64(5)).Return(sign(t, key, Config{Version: 4, Settings: map[string]string{"remoteconfig_test_b": "ok_old"}}), nil).Once()
+	p.poll()
+
+	// fetch error
+	fwd.On("FetchRemoteConfig", uint64(5)).Return(nil, errors.New("timeout")).Once()
+	p.poll()
+	assert.Contains(t, p.State().LastError, "timeout")
+
+	fwd.AssertExpectations(t)
+	fwd.AssertNotCalled(t, "SubmitRemoteConfigState", mock.Anything, mock.Anything)
+	assert.Equal(t, uint64(5), p.State().Version)
+	assert.NotContains(t, settingB.value, "ok_")
+}
+
+func TestPollerStartStop(t *testing.T) {
+	_, publicKey := generateKey(t)
+	fwd := &forwarder.MockedForwarder{}
+	p, err := NewPoller(fwd, publicKey, time.Hour, "host")
+	require.Nil(t, err)
+
+	fetched := make(chan struct{}, 1)
+	fwd.On("FetchRemoteConfig", uint64(0)).Return(nil, nil).Run(func(mock.Arguments) { fetched <- struct{}{} }).Once()
+	p.Start()
+	select {
+	case <-fetched:
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "the remote configuration was not fetched on start")
+	}
+	p.Stop()
+	fwd.AssertExpectations(t)
+}
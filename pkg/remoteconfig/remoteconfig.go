@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package remoteconfig fetches the configuration updates of the backend and
+// applies them to the settings that can be changed at runtime.
+package remoteconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Config is a configuration update, the values of the runtime settings by name.
+// The settings absent from an update keep their value. It can request a flare.
+type Config struct {
+	Version  uint64            `json:"version"`
+	Settings map[string]string `json:"settings"`
+	Flare    *FlareRequest     `json:"flare,omitempty"`
+}
+
+// signedConfig is the response of the backend, a Config signed with its
+// private key, the byte slices are encoded in base64.
+type signedConfig struct {
+	Config    []byte `json:"config"`
+	Signature []byte `json:"signature"`
+}
+
+// ecdsaSignature is the ASN.1 encoding of an ECDSA signature
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// parsePublicKey parses the PEM encoded ECDSA public key of the backend.
+func parsePublicKey(data string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("no PEM block found in the public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the public key: %s", err)
+	}
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("the public key is not an ECDSA key")
+	}
+	return publicKey, nil
+}
+
+// verify returns the configuration of a response of the backend, it fails when
+// its signature is not the one of the public key.
+func verify(data []byte, publicKey *ecdsa.PublicKey) (*Config, error) {
+	signed := signedConfig{}
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, fmt.Errorf("could not parse the remote configuration: %s", err)
+	}
+	signature := ecdsaSignature{}
+	if _, err := asn1.Unmarshal(signed.Signature, &signature); err != nil || signature.R == nil || signature.S == nil {
+		return nil, errors.New("invalid signature of the remote configuration")
+	}
+	hash := sha256.Sum256(signed.Config)
+	if !ecdsa.Verify(publicKey, hash[:], signature.R, signature.S) {
+		return nil, errors.New("the signature of the remote configuration does not match the public key")
+	}
+	config := &Config{}
+	if err := json.Unmarshal(signed.Config, config); err != nil {
+		return nil, fmt.Errorf("could not parse the remote configuration: %s", err)
+	}
+	return config, nil
+}
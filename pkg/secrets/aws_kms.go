@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build ec2
+
+package secrets
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+)
+
+// decryptAWSKMS decrypts a handle, the base64 ciphertext returned by `aws kms encrypt`,
+// the key is the one of the ciphertext. The credentials are the ones of the AWS
+// environment: the env vars, the shared credentials file or the IAM role of the instance.
+func decryptAWSKMS(handle string) (string, error) {
+	blob, err := base64.StdEncoding.DecodeString(handle)
+	if err != nil {
+		return "", fmt.Errorf("could not decode the ciphertext: %s", err)
+	}
+
+	awsConfig := aws.Config{HTTPClient: newHTTPClient()}
+	if awsRegion != "" {
+		awsConfig.Region = aws.String(awsRegion)
+	}
+	awsSess, err := session.NewSessionWithOptions(session.Options{
+		Config:            awsConfig,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to get aws session, %s", err)
+	}
+
+	res, err := kms.New(awsSess).Decrypt(&kms.DecryptInput{CiphertextBlob: blob})
+	if err != nil {
+		return "", err
+	}
+	return string(res.Plaintext), nil
+}
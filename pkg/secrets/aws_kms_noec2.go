@@ -0,0 +1,15 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !ec2
+
+package secrets
+
+import "fmt"
+
+// decryptAWSKMS is not supported without the AWS SDK.
+func decryptAWSKMS(handle string) (string, error) {
+	return "", fmt.Errorf("the agent is built without the ec2 support")
+}
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package secrets
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// declare these as vars not const to ease testing
+var azureMetadataURL = "http://169.254.169.254"
+
+// azureKeyVaultResource is the resource of the tokens of the Key Vault API
+const azureKeyVaultResource = "https://vault.azure.net"
+
+// getAzureToken returns a token of the managed identity of the VM, from the
+// Azure metadata endpoint.
+func getAzureToken() (string, error) {
+	res := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	tokenURL := fmt.Sprintf("%s/metadata/identity/oauth2/token?api-version=2018-02-01&resource=%s", azureMetadataURL, url.QueryEscape(azureKeyVaultResource))
+	if err := doRequest("GET", tokenURL, nil, map[string]string{"Metadata": "true"}, &res); err != nil {
+		return "", fmt.Errorf("unable to retrieve a token from Azure: %s", err)
+	}
+	return res.AccessToken, nil
+}
+
+// getAzureSecret returns a secret of the vault secret_backend_azure_vault_url,
+// the handle is the name of the secret, optionally followed by its version:
+// `<name>/<version>`.
+func getAzureSecret(handle string, token string) (string, error) {
+	res := struct {
+		Value string `json:"value"`
+	}{}
+	secretURL := fmt.Sprintf("%s/secrets/%s?api-version=7.0", azureVaultURL, handle)
+	if err := doRequest("GET", secretURL, nil, map[string]string{"Authorization": "Bearer " + token}, &res); err != nil {
+		return "", err
+	}
+	return res.Value, nil
+}
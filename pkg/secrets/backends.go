@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// the values of secret_backend_type
+const (
+	executableBackend    = "executable"
+	awsKMSBackend        = "aws-kms"
+	gcpKMSBackend        = "gcp-kms"
+	azureKeyVaultBackend = "azure-keyvault"
+)
+
+// fetchSecrets returns the secrets of the handles with the backend selected,
+// every handle must be resolved.
+func fetchSecrets(handles []string, origin string) (map[string]string, error) {
+	var fetch func(handle string) (string, error)
+	switch secretBackendType {
+	case awsKMSBackend:
+		fetch = decryptAWSKMS
+	case gcpKMSBackend:
+		token, err := getGCPToken()
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch the secrets of %s: %s", origin, err)
+		}
+		fetch = func(handle string) (string, error) { return decryptGCPKMS(handle, token) }
+	case azureKeyVaultBackend:
+		token, err := getAzureToken()
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch the secrets of %s: %s", origin, err)
+		}
+		fetch = func(handle string) (string, error) { return getAzureSecret(handle, token) }
+	default:
+		return fetchSecret(handles, origin)
+	}
+
+	res := make(map[string]string, len(handles))
+	for _, handle := range handles {
+		secret, err := fetch(handle)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch the secret '%s' of %s with the %s secret backend: %s", handle, origin, secretBackendType, err)
+		}
+		if secret == "" {
+			return nil, fmt.Errorf("the %s secret backend returned an empty value for the secret '%s' of %s", secretBackendType, handle, origin)
+		}
+		res[handle] = secret
+	}
+	return res, nil
+}
+
+// newHTTPClient returns a client timing out after secret_backend_timeout seconds.
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: secretBackendTimeout,
+	}
+}
+
+// doRequest sends a request to the API of a cloud provider and decodes its JSON response in v.
+func doRequest(method string, url string, body io.Reader, headers map[string]string, v interface{}) error {
+	client := newHTTPClient()
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	for header, value := range headers {
+		req.Header.Set(header, value)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	all, err := ioutil.ReadAll(io.LimitReader(res.Body, int64(secretBackendOutputMaxSize)))
+	if err != nil {
+		return fmt.Errorf("error reading the response of %s: %s", url, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code %d trying to %s %s: %s", res.StatusCode, method, url, all)
+	}
+	if err := json.Unmarshal(all, v); err != nil {
+		return fmt.Errorf("could not parse the response of %s: %s", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInit(t *testing.T) {
+	defer Init(Config{})
+
+	assert.Nil(t, Init(Config{}))
+	assert.Equal(t, executableBackend, secretBackendType)
+	assert.False(t, IsEnabled())
+
+	assert.Nil(t, Init(Config{Command: "/bin/fetch"}))
+	assert.True(t, IsEnabled())
+
+	assert.Nil(t, Init(Config{Type: "aws-kms"}))
+	assert.True(t, IsEnabled())
+
+	assert.NotNil(t, Init(Config{Type: "gcp-kms"}))
+	assert.Nil(t, Init(Config{Type: "gcp-kms", GCPKMSKey: "projects/p/locations/global/keyRings/r/cryptoKeys/k"}))
+	assert.NotNil(t, Init(Config{Type: "azure-keyvault"}))
+	assert.Nil(t, Init(Config{Type: "azure-keyvault", AzureVaultURL: "https://myvault.vault.azure.net/"}))
+	assert.Equal(t, "https://myvault.vault.azure.net", azureVaultURL)
+	assert.NotNil(t, Init(Config{Type: "vault"}))
+}
+
+func TestFetchSecretsGCPKMS(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metadata/instance/service-accounts/default/token":
+			assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+			fmt.Fprint(w, `{"access_token": "token", "expires_in": 3599, "token_type": "Bearer"}`)
+		case "/kms/projects/p/locations/global/keyRings/r/cryptoKeys/k:decrypt":
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			body := map[string]string{}
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["ciphertext"] != "Y2lwaGVy" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": {"message": "Decryption failed"}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"plaintext": "%s"}`, base64.StdEncoding.EncodeToString([]byte("secret")))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	gcpMetadataURL = ts.URL + "/metadata"
+	gcpKMSURL = ts.URL + "/kms"
+	defer Init(Config{})
+	assert.Nil(t, Init(Config{Type: "gcp-kms", GCPKMSKey: "projects/p/locations/global/keyRings/r/cryptoKeys/k"}))
+
+	secrets, err := fetchSecrets([]string{"Y2lwaGVy"}, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"Y2lwaGVy": "secret"}, secrets)
+
+	_, err = fetchSecrets([]string{"Y2lwaGVy", "b3RoZXI="}, "test")
+	assert.NotNil(t, err)
+}
+
+func TestFetchSecretsAzureKeyVault(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metadata/identity/oauth2/token":
+			assert.Equal(t, "true", r.Header.Get("Metadata"))
+			assert.Equal(t, "https://vault.azure.net", r.URL.Query().Get("resource"))
+			fmt.Fprint(w, `{"access_token": "token", "token_type": "Bearer"}`)
+		case "/vault/secrets/api-key", "/vault/secrets/api-key/v2":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			assert.Equal(t, "7.0", r.URL.Query().Get("api-version"))
+			fmt.Fprintf(w, `{"value": "%s", "id": "https://myvault.vault.azure.net%s"}`, r.URL.Path[len("/vault/secrets/"):], r.URL.Path)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": "SecretNotFound"}}`)
+		}
+	}))
+	defer ts.Close()
+	azureMetadataURL = ts.URL
+	defer Init(Config{})
+	assert.Nil(t, Init(Config{Type: "azure-keyvault", AzureVaultURL: ts.URL + "/vault"}))
+
+	secrets, err := fetchSecrets([]string{"api-key", "api-key/v2"}, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"api-key": "api-key", "api-key/v2": "api-key/v2"}, secrets)
+
+	_, err = fetchSecrets([]string{"password"}, "test")
+	assert.NotNil(t, err)
+}
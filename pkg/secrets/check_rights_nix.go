@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkRights makes sure that the executable is owned by the user running the
+// agent and can not be modified or read by the other users, it could otherwise
+// be replaced to exfiltrate the secrets.
+func checkRights(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("invalid secret_backend_command %s: %s", path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("invalid secret_backend_command %s: not a regular file", path)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("invalid secret_backend_command %s: it must not be accessible by the group and the other users, its rights are %s", path, info.Mode().Perm())
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		return fmt.Errorf("invalid secret_backend_command %s: it is not executable by its owner", path)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Geteuid() {
+		return fmt.Errorf("invalid secret_backend_command %s: it must be owned by the user running the agent", path)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRights(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "backend")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"), 0700))
+
+	assert.Nil(t, checkRights(path))
+
+	assert.Nil(t, os.Chmod(path, 0750))
+	assert.NotNil(t, checkRights(path))
+
+	assert.Nil(t, os.Chmod(path, 0702))
+	assert.NotNil(t, checkRights(path))
+
+	assert.Nil(t, os.Chmod(path, 0600))
+	assert.NotNil(t, checkRights(path))
+
+	assert.NotNil(t, checkRights(filepath.Join(dir, "missing")))
+}
+
+func TestFetchSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "backend")
+	script := "#!/bin/sh\ncat > /dev/null\necho '{\"key\": {\"value\": \"foo\", \"error\": null}}'\n"
+	assert.Nil(t, ioutil.WriteFile(path, []byte(script), 0700))
+	secretBackendCommand = path
+	secretBackendTimeout = 5 * time.Second
+	secretBackendOutputMaxSize = 1024
+
+	secrets, err := fetchSecret([]string{"key"}, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"key": "foo"}, secrets)
+
+	// the output is too large
+	secretBackendOutputMaxSize = 10
+	_, err = fetchSecret([]string{"key"}, "test")
+	assert.NotNil(t, err)
+}
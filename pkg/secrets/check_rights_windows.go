@@ -0,0 +1,26 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build windows
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+)
+
+// checkRights only makes sure that the executable exists, the rights on windows
+// are managed with ACLs restricted by the installer.
+func checkRights(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("invalid secret_backend_command %s: %s", path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("invalid secret_backend_command %s: not a regular file", path)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// payloadVersion is the version of the format exchanged with the executable
+const payloadVersion = "1.0"
+
+// secret is the value returned by the executable for a handle.
+type secret struct {
+	Value    string  `json:"value"`
+	ErrorMsg *string `json:"error"`
+}
+
+// limitedBuffer is a buffer failing once it holds more than max bytes.
+type limitedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.max {
+		return 0, fmt.Errorf("the output exceeds %d bytes", b.max)
+	}
+	return b.buf.Write(p)
+}
+
+// execCommand runs the executable with the payload on its stdin and returns its stdout.
+func execCommand(payload []byte) ([]byte, error) {
+	if err := checkRights(secretBackendCommand); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), secretBackendTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, secretBackendCommand, secretBackendArguments...)
+	cmd.Stdin = bytes.NewReader(payload)
+	stdout := &limitedBuffer{max: secretBackendOutputMaxSize}
+	stderr := &limitedBuffer{max: secretBackendOutputMaxSize}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("the secret_backend_command timed out after %s", secretBackendTimeout)
+		}
+		return nil, fmt.Errorf("the secret_backend_command failed: %s, stderr: %s", err, strings.TrimSpace(stderr.buf.String()))
+	}
+	return stdout.buf.Bytes(), nil
+}
+
+// fetchSecret returns the secrets of the handles, every handle must be resolved.
+func fetchSecret(handles []string, origin string) (map[string]string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"version": payloadVersion,
+		"secrets": handles,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not serialize the secrets of %s: %s", origin, err)
+	}
+	output, err := execCommand(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch the secrets of %s: %s", origin, err)
+	}
+	return parseOutput(output, handles, origin)
+}
+
+// parseOutput returns the secrets of the output of the executable, a JSON
+// object like `{"<handle>": {"value": "<secret>", "error": null}}`.
+func parseOutput(output []byte, handles []string, origin string) (map[string]string, error) {
+	secrets := map[string]secret{}
+	if err := json.Unmarshal(output, &secrets); err != nil {
+		return nil, fmt.Errorf("could not parse the output of the secret_backend_command for %s: %s", origin, err)
+	}
+	res := make(map[string]string, len(handles))
+	for _, handle := range handles {
+		s, found := secrets[handle]
+		if !found {
+			return nil, fmt.Errorf("the secret_backend_command did not return the secret '%s' of %s", handle, origin)
+		}
+		if s.ErrorMsg != nil {
+			return nil, fmt.Errorf("could not fetch the secret '%s' of %s: %s", handle, origin, *s.ErrorMsg)
+		}
+		if s.Value == "" {
+			return nil, fmt.Errorf("the secret_backend_command returned an empty value for the secret '%s' of %s", handle, origin)
+		}
+		res[handle] = s.Value
+	}
+	return res, nil
+}
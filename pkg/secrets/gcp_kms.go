@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// declare these as vars not const to ease testing
+var (
+	gcpMetadataURL = "http://169.254.169.254/computeMetadata/v1"
+	gcpKMSURL      = "https://cloudkms.googleapis.com/v1"
+)
+
+// getGCPToken returns a token of the service account of the instance, from the
+// GCE metadata server.
+func getGCPToken() (string, error) {
+	res := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	err := doRequest("GET", gcpMetadataURL+"/instance/service-accounts/default/token", nil, map[string]string{"Metadata-Flavor": "Google"}, &res)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve a token from GCE: %s", err)
+	}
+	return res.AccessToken, nil
+}
+
+// decryptGCPKMS decrypts a handle with the key secret_backend_gcp_kms_key,
+// the handle is the base64 ciphertext returned by `gcloud kms encrypt`.
+func decryptGCPKMS(handle string, token string) (string, error) {
+	body, err := json.Marshal(map[string]string{"ciphertext": handle})
+	if err != nil {
+		return "", err
+	}
+	res := struct {
+		Plaintext string `json:"plaintext"`
+	}{}
+	headers := map[string]string{
+		"Authorization": "Bearer " + token,
+		"Content-Type":  "application/json",
+	}
+	if err := doRequest("POST", fmt.Sprintf("%s/%s:decrypt", gcpKMSURL, gcpKMSKey), bytes.NewReader(body), headers, &res); err != nil {
+		return "", err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(res.Plaintext)
+	if err != nil {
+		return "", fmt.Errorf("could not decode the plaintext: %s", err)
+	}
+	return string(plaintext), nil
+}
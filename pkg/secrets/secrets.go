@@ -0,0 +1,184 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var (
+	// the secrets already fetched, by handle, they are only kept in memory
+	secretCache = map[string]string{}
+	mu          sync.Mutex
+
+	secretBackendType          = executableBackend
+	secretBackendCommand       string
+	secretBackendArguments     []string
+	secretBackendTimeout       = 5 * time.Second
+	secretBackendOutputMaxSize = 1024 * 1024
+
+	awsRegion     string
+	gcpKMSKey     string
+	azureVaultURL string
+
+	// secretFetcher is mocked in the tests
+	secretFetcher = fetchSecrets
+)
+
+// encPattern matches the values `ENC[<handle>]`
+var encPattern = regexp.MustCompile(`^ENC\[(.+)\]$`)
+
+// Config is the configuration of the backend fetching the secrets.
+type Config struct {
+	// Type is the backend, the executable by default
+	Type string
+	// Command is the executable fetching the secrets, it is called with Arguments,
+	// and killed after Timeout seconds. Its output can not exceed OutputMaxSize bytes.
+	Command       string
+	Arguments     []string
+	Timeout       int
+	OutputMaxSize int
+	// AWSRegion is the region of the AWS KMS keys, the one of the AWS environment by default
+	AWSRegion string
+	// GCPKMSKey is the resource name of the GCP KMS key
+	GCPKMSKey string
+	// AzureVaultURL is the URL of the Azure Key Vault, ex: https://myvault.vault.azure.net
+	AzureVaultURL string
+}
+
+// Init sets the backend fetching the secrets, it fails when the backend is unknown
+// or when its settings are missing.
+func Init(c Config) error {
+	mu.Lock()
+	defer mu.Unlock()
+	switch c.Type {
+	case "", executableBackend:
+		c.Type = executableBackend
+	case awsKMSBackend:
+	case gcpKMSBackend:
+		if c.GCPKMSKey == "" {
+			return fmt.Errorf("secret_backend_gcp_kms_key must be set for the %s secret backend", c.Type)
+		}
+	case azureKeyVaultBackend:
+		if c.AzureVaultURL == "" {
+			return fmt.Errorf("secret_backend_azure_vault_url must be set for the %s secret backend", c.Type)
+		}
+	default:
+		return fmt.Errorf("unknown secret_backend_type %s, the valid types are: %s, %s, %s and %s", c.Type, executableBackend, awsKMSBackend, gcpKMSBackend, azureKeyVaultBackend)
+	}
+	secretBackendType = c.Type
+	secretBackendCommand = c.Command
+	secretBackendArguments = c.Arguments
+	if c.Timeout > 0 {
+		secretBackendTimeout = time.Duration(c.Timeout) * time.Second
+	}
+	if c.OutputMaxSize > 0 {
+		secretBackendOutputMaxSize = c.OutputMaxSize
+	}
+	awsRegion = c.AWSRegion
+	gcpKMSKey = c.GCPKMSKey
+	azureVaultURL = strings.TrimSuffix(c.AzureVaultURL, "/")
+	return nil
+}
+
+// IsEnabled returns whether a backend can fetch the secrets: a built-in one is
+// selected or the executable is set.
+func IsEnabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return isEnabled()
+}
+
+func isEnabled() bool {
+	return secretBackendType != executableBackend || secretBackendCommand != ""
+}
+
+// Decrypt replaces the values `ENC[<handle>]` of a YAML document by the secrets
+// returned by the backend for their handles, origin names the document in
+// the errors. The document is returned unchanged when it has no handle.
+func Decrypt(data []byte, origin string) ([]byte, error) {
+	if !bytes.Contains(data, []byte("ENC[")) {
+		return data, nil
+	}
+	var config interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", origin, err)
+	}
+
+	// collect the handles not fetched yet
+	mu.Lock()
+	defer mu.Unlock()
+	handles := []string{}
+	found := false
+	walk(config, func(value string) string {
+		if handle, ok := getHandle(value); ok {
+			found = true
+			if _, fetched := secretCache[handle]; !fetched {
+				handles = append(handles, handle)
+			}
+		}
+		return value
+	})
+	if !found {
+		return data, nil
+	}
+	if !isEnabled() {
+		return nil, fmt.Errorf("%s uses secrets but neither secret_backend_command nor secret_backend_type is set", origin)
+	}
+
+	if len(handles) > 0 {
+		secrets, err := secretFetcher(handles, origin)
+		if err != nil {
+			return nil, err
+		}
+		for handle, secret := range secrets {
+			secretCache[handle] = secret
+		}
+		log.Infof("Fetched %d secrets for %s", len(secrets), origin)
+	}
+
+	config = walk(config, func(value string) string {
+		if handle, ok := getHandle(value); ok {
+			return secretCache[handle]
+		}
+		return value
+	})
+	return yaml.Marshal(config)
+}
+
+// getHandle returns the handle of a value `ENC[<handle>]`.
+func getHandle(value string) (string, bool) {
+	matches := encPattern.FindStringSubmatch(value)
+	if len(matches) != 2 {
+		return "", false
+	}
+	return matches[1], true
+}
+
+// walk replaces the string values of a YAML document, the keys are unchanged.
+func walk(data interface{}, callback func(string) string) interface{} {
+	switch v := data.(type) {
+	case string:
+		return callback(v)
+	case map[interface{}]interface{}:
+		for key, value := range v {
+			v[key] = walk(value, callback)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = walk(value, callback)
+		}
+	}
+	return data
+}
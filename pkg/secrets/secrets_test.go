@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package secrets
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockFetcher replaces the executable by the secrets given, it returns the
+// handles it is called with.
+func mockFetcher(secrets map[string]string) *[][]string {
+	calls := [][]string{}
+	secretCache = map[string]string{}
+	secretBackendCommand = "some_command"
+	secretFetcher = func(handles []string, origin string) (map[string]string, error) {
+		calls = append(calls, handles)
+		res := map[string]string{}
+		for _, handle := range handles {
+			secret, found := secrets[handle]
+			if !found {
+				return nil, fmt.Errorf("unknown secret '%s'", handle)
+			}
+			res[handle] = secret
+		}
+		return res, nil
+	}
+	return &calls
+}
+
+func TestDecryptWithoutHandles(t *testing.T) {
+	calls := mockFetcher(nil)
+	data := []byte("api_key: foo\n")
+	decrypted, err := Decrypt(data, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, data, decrypted)
+	assert.Len(t, *calls, 0)
+}
+
+func TestDecryptReplacesTheHandles(t *testing.T) {
+	calls := mockFetcher(map[string]string{"key": "foo", "pass": "bar"})
+	data := []byte(`api_key: ENC[key]
+instances:
+- password: ENC[pass]
+  tags:
+  - ENC[key]
+  - env:prod
+`)
+	decrypted, err := Decrypt(data, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, `api_key: foo
+instances:
+- password: bar
+  tags:
+  - foo
+  - env:prod
+`, string(decrypted))
+	assert.Len(t, *calls, 1)
+	assert.Len(t, (*calls)[0], 3)
+}
+
+func TestDecryptCachesTheSecrets(t *testing.T) {
+	calls := mockFetcher(map[string]string{"key": "foo"})
+	_, err := Decrypt([]byte("api_key: ENC[key]\n"), "test")
+	assert.Nil(t, err)
+	decrypted, err := Decrypt([]byte("password: ENC[key]\n"), "test")
+	assert.Nil(t, err)
+	assert.Equal(t, "password: foo\n", string(decrypted))
+	assert.Len(t, *calls, 1)
+}
+
+func TestDecryptFailures(t *testing.T) {
+	mockFetcher(map[string]string{})
+	_, err := Decrypt([]byte("api_key: ENC[key]\n"), "test")
+	assert.NotNil(t, err)
+
+	secretBackendCommand = ""
+	_, err = Decrypt([]byte("api_key: ENC[key]\n"), "test")
+	assert.NotNil(t, err)
+}
+
+func TestParseOutput(t *testing.T) {
+	secrets, err := parseOutput([]byte(`{"key": {"value": "foo", "error": null}}`), []string{"key"}, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"key": "foo"}, secrets)
+
+	_, err = parseOutput([]byte(`{"key": {"value": "", "error": "not found"}}`), []string{"key"}, "test")
+	assert.NotNil(t, err)
+
+	_, err = parseOutput([]byte(`{"other": {"value": "foo", "error": null}}`), []string{"key"}, "test")
+	assert.NotNil(t, err)
+
+	_, err = parseOutput([]byte(`{"key": {"value": "", "error": null}}`), []string{"key"}, "test")
+	assert.NotNil(t, err)
+
+	_, err = parseOutput([]byte(`not json`), []string{"key"}, "test")
+	assert.NotNil(t, err)
+}
@@ -197,3 +197,22 @@ func (s *Serializer) SendJSONToV1Intake(data interface{}) error {
 	log.Debugf("Sent processes metadata payload, content: %v", apiKeyRegExp.ReplaceAllString(string(payload), apiKeyReplacement))
 	return nil
 }
+
+// SendOrchestratorPayload serializes a cluster resources payload and sends it to
+// the forwarder. Those payloads can be large, they are always compressed.
+func (s *Serializer) SendOrchestratorPayload(data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("could not serialize orchestrator payload: %s", err)
+	}
+	compressed, err := compression.Compress(nil, payload)
+	if err != nil {
+		return fmt.Errorf("could not compress orchestrator payload: %s", err)
+	}
+	if err := s.Forwarder.SubmitOrchestratorPayload(forwarder.Payloads{&compressed}, jsonExtraHeadersWithCompression); err != nil {
+		return err
+	}
+
+	log.Infof("Sent orchestrator payload, size: %d bytes.", len(payload))
+	return nil
+}
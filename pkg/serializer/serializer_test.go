@@ -287,3 +287,25 @@ func TestSendJSONToV1Intake(t *testing.T) {
 	err = s.SendJSONToV1Intake(errPayload)
 	require.NotNil(t, err)
 }
+
+func TestSendOrchestratorPayload(t *testing.T) {
+	f := &forwarder.MockedForwarder{}
+	payload := []byte("\"test\"")
+	payloads, _ := mkPayloads(payload, true)
+	f.On("SubmitOrchestratorPayload", payloads, jsonExtraHeadersWithCompression).Return(nil).Times(1)
+
+	s := Serializer{Forwarder: f}
+
+	err := s.SendOrchestratorPayload("test")
+	require.Nil(t, err)
+	f.AssertExpectations(t)
+
+	f.On("SubmitOrchestratorPayload", payloads, jsonExtraHeadersWithCompression).Return(fmt.Errorf("some error")).Times(1)
+	err = s.SendOrchestratorPayload("test")
+	require.NotNil(t, err)
+	f.AssertExpectations(t)
+
+	errPayload := &testErrorPayload{}
+	err = s.SendOrchestratorPayload(errPayload)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,161 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package snmptraps
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k-sone/snmpgo"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// Config is the `snmp_traps` section of the configuration
+type Config struct {
+	BindHost        string
+	Port            int
+	CommunityString string
+	Users           []UserConfig
+	MIBsFolder      string
+	Translations    map[string]string
+	Tags            []string
+	DeviceTags      map[string][]string
+	ServiceChecks   []ServiceCheckRule
+}
+
+// UserConfig holds the credentials of a SNMPv3 user
+type UserConfig struct {
+	User         string `mapstructure:"user"`
+	AuthKey      string `mapstructure:"auth_key"`
+	AuthProtocol string `mapstructure:"auth_protocol"`
+	PrivKey      string `mapstructure:"priv_key"`
+	PrivProtocol string `mapstructure:"priv_protocol"`
+	// EngineID is the authoritative engine ID of the devices sending the
+	// traps, in hexadecimal
+	EngineID string `mapstructure:"engine_id"`
+}
+
+// ServiceCheckRule submits a service check for the traps of an OID, in
+// addition to their event
+type ServiceCheckRule struct {
+	// Trap is the name of the trap, e.g. IF-MIB::linkDown, or its OID
+	Trap   string `mapstructure:"trap"`
+	Name   string `mapstructure:"name"`
+	Status string `mapstructure:"status"`
+	// VarbindTags are the variables of the trap added as tags, e.g.
+	// IF-MIB::ifIndex, so a service check is submitted per interface
+	VarbindTags []string `mapstructure:"varbind_tags"`
+
+	status      metrics.ServiceCheckStatus
+	varbindKeys []string // the keys of the variables of VarbindTags
+}
+
+// loadConfig reads and validates the `snmp_traps` section
+func loadConfig() (*Config, error) {
+	cfg := &Config{
+		BindHost:        config.Datadog.GetString("snmp_traps.bind_host"),
+		Port:            config.Datadog.GetInt("snmp_traps.port"),
+		CommunityString: config.Datadog.GetString("snmp_traps.community_string"),
+		MIBsFolder:      config.Datadog.GetString("snmp_traps.mibs_folder"),
+		Translations:    make(map[string]string),
+		Tags:            config.Datadog.GetStringSlice("snmp_traps.tags"),
+	}
+	for oid, name := range config.Datadog.GetStringMapString("snmp_traps.translations") {
+		cfg.Translations[strings.TrimPrefix(oid, ".")] = name
+	}
+	// the lists of structures are decoded with their own key so the defaults
+	// of the other keys of the section apply
+	if err := config.Datadog.UnmarshalKey("snmp_traps.users", &cfg.Users); err != nil {
+		return nil, fmt.Errorf("invalid snmp_traps.users: %s", err)
+	}
+	if err := config.Datadog.UnmarshalKey("snmp_traps.device_tags", &cfg.DeviceTags); err != nil {
+		return nil, fmt.Errorf("invalid snmp_traps.device_tags: %s", err)
+	}
+	if err := config.Datadog.UnmarshalKey("snmp_traps.service_checks", &cfg.ServiceChecks); err != nil {
+		return nil, fmt.Errorf("invalid snmp_traps.service_checks: %s", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	if c.CommunityString == "" && len(c.Users) == 0 {
+		return fmt.Errorf("no community string nor SNMPv3 user is configured")
+	}
+	for _, u := range c.Users {
+		if u.User == "" {
+			return fmt.Errorf("a SNMPv3 user has no name")
+		}
+		if _, err := u.securityEntry(); err != nil {
+			return fmt.Errorf("invalid SNMPv3 user %s: %s", u.User, err)
+		}
+	}
+	for i := range c.ServiceChecks {
+		rule := &c.ServiceChecks[i]
+		if rule.Trap == "" || rule.Name == "" {
+			return fmt.Errorf("the service checks require a trap and a name")
+		}
+		switch strings.ToLower(rule.Status) {
+		case "ok":
+			rule.status = metrics.ServiceCheckOK
+		case "warning":
+			rule.status = metrics.ServiceCheckWarning
+		case "critical":
+			rule.status = metrics.ServiceCheckCritical
+		case "unknown", "":
+			rule.status = metrics.ServiceCheckUnknown
+		default:
+			return fmt.Errorf("invalid status %s of the service check %s", rule.Status, rule.Name)
+		}
+	}
+	return nil
+}
+
+// securityEntry returns the snmpgo security entry of a SNMPv3 user, its
+// security level depends on the keys set
+func (u UserConfig) securityEntry() (*snmpgo.SecurityEntry, error) {
+	entry := &snmpgo.SecurityEntry{
+		Version:          snmpgo.V3,
+		UserName:         u.User,
+		SecurityLevel:    snmpgo.NoAuthNoPriv,
+		SecurityEngineId: u.EngineID,
+	}
+	if u.AuthKey == "" {
+		if u.PrivKey != "" {
+			return nil, fmt.Errorf("the privacy requires an auth_key")
+		}
+		return entry, nil
+	}
+
+	entry.SecurityLevel = snmpgo.AuthNoPriv
+	entry.AuthPassword = u.AuthKey
+	switch strings.ToUpper(u.AuthProtocol) {
+	case "MD5", "":
+		entry.AuthProtocol = snmpgo.Md5
+	case "SHA":
+		entry.AuthProtocol = snmpgo.Sha
+	default:
+		return nil, fmt.Errorf("unsupported auth_protocol %s, expected MD5 or SHA", u.AuthProtocol)
+	}
+
+	if u.PrivKey != "" {
+		entry.SecurityLevel = snmpgo.AuthPriv
+		entry.PrivPassword = u.PrivKey
+		switch strings.ToUpper(u.PrivProtocol) {
+		case "DES", "":
+			entry.PrivProtocol = snmpgo.Des
+		case "AES":
+			entry.PrivProtocol = snmpgo.Aes
+		default:
+			return nil, fmt.Errorf("unsupported priv_protocol %s, expected DES or AES", u.PrivProtocol)
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,216 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+/*
+Package snmptraps receives the SNMP traps of the network devices and turns
+them into events, and into service checks for the traps configured, so their
+alerts flow through the agent without a separate trap daemon. The traps are
+accepted with SNMPv2c communities and SNMPv3 users, their OIDs are resolved
+to names with the MIBs when the agent is built with net-snmp.
+*/
+package snmptraps
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/k-sone/snmpgo"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	sourceTypeName = "snmp"
+	eventType      = "snmp_trap"
+)
+
+var snmpTrapsExpvar = expvar.NewMap("snmp-traps")
+
+// Listener receives the traps and sends their events and service checks to
+// the aggregator
+type Listener struct {
+	server          *snmpgo.TrapServer
+	eventOut        chan<- metrics.Event
+	serviceCheckOut chan<- metrics.ServiceCheck
+	converter       *converter
+}
+
+// NewListener returns a running Listener configured by the `snmp_traps`
+// section of the configuration
+func NewListener(eventOut chan<- metrics.Event, serviceCheckOut chan<- metrics.ServiceCheck) (*Listener, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid snmp_traps configuration: %s", err)
+	}
+
+	r := chainResolver{tableResolver(cfg.Translations)}
+	if newMIBResolver != nil {
+		mibs, err := newMIBResolver(cfg.MIBsFolder)
+		if err != nil {
+			log.Warnf("Could not load the MIBs, only the generic traps are resolved: %s", err)
+		} else {
+			r = append(r, mibs)
+		}
+	} else if cfg.MIBsFolder != "" {
+		log.Warnf("This build of the agent cannot load the MIBs of %s, only the generic traps and the translations are resolved", cfg.MIBsFolder)
+	}
+	r = append(r, tableResolver(genericTranslations))
+
+	server, err := snmpgo.NewTrapServer(snmpgo.ServerArguments{
+		LocalAddr: net.JoinHostPort(cfg.BindHost, fmt.Sprintf("%d", cfg.Port)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not listen to the SNMP traps: %s", err)
+	}
+	if cfg.CommunityString != "" {
+		if err := server.AddSecurity(&snmpgo.SecurityEntry{Version: snmpgo.V2c, Community: cfg.CommunityString}); err != nil {
+			server.Close()
+			return nil, err
+		}
+	}
+	for _, user := range cfg.Users {
+		entry, _ := user.securityEntry() // validated with the configuration
+		if err := server.AddSecurity(entry); err != nil {
+			server.Close()
+			return nil, fmt.Errorf("could not add the SNMPv3 user %s: %s", user.User, err)
+		}
+	}
+
+	l := &Listener{
+		server:          server,
+		eventOut:        eventOut,
+		serviceCheckOut: serviceCheckOut,
+		converter:       newConverter(cfg, r),
+	}
+	go func() {
+		if err := server.Serve(l); err != nil {
+			log.Errorf("The SNMP trap listener stopped: %s", err)
+		}
+	}()
+	log.Infof("SNMP trap listener is listening on %s:%d", cfg.BindHost, cfg.Port)
+	return l, nil
+}
+
+// Stop closes the socket of the listener
+func (l *Listener) Stop() {
+	l.server.Close()
+}
+
+// OnTRAP handles a trap received by the server
+func (l *Listener) OnTRAP(trap *snmpgo.TrapRequest) {
+	if trap.Error != nil {
+		// e.g. an unknown community or invalid credentials
+		snmpTrapsExpvar.Add("Errors", 1)
+		log.Debugf("Invalid SNMP trap from %s: %s", trap.Source, trap.Error)
+		return
+	}
+	snmpTrapsExpvar.Add("Traps", 1)
+
+	source := trap.Source.String()
+	if host, _, err := net.SplitHostPort(source); err == nil {
+		source = host
+	}
+	event, serviceChecks := l.converter.convert(source, trap.Pdu.VarBinds(), time.Now())
+	l.eventOut <- event
+	for _, sc := range serviceChecks {
+		l.serviceCheckOut <- sc
+	}
+}
+
+// converter turns the traps into events and service checks
+type converter struct {
+	resolver      resolver
+	tags          []string
+	deviceTags    map[string][]string
+	serviceChecks map[string][]ServiceCheckRule // by resolved trap name
+}
+
+func newConverter(cfg *Config, r resolver) *converter {
+	c := &converter{
+		resolver:      r,
+		tags:          cfg.Tags,
+		deviceTags:    cfg.DeviceTags,
+		serviceChecks: make(map[string][]ServiceCheckRule),
+	}
+	for _, rule := range cfg.ServiceChecks {
+		// the rules match the traps and their variables by name, their OIDs
+		// are resolved too
+		trap := resolveOID(r, rule.Trap)
+		rule.varbindKeys = make([]string, 0, len(rule.VarbindTags))
+		for _, v := range rule.VarbindTags {
+			rule.varbindKeys = append(rule.varbindKeys, variableKey(resolveOID(r, v)))
+		}
+		c.serviceChecks[trap] = append(c.serviceChecks[trap], rule)
+	}
+	return c
+}
+
+// convert returns the event of a trap sent by the device at source, and the
+// service checks of the rules matching it
+func (c *converter) convert(source string, varBinds snmpgo.VarBinds, now time.Time) (metrics.Event, []metrics.ServiceCheck) {
+	tags := append(append([]string{"snmp_device:" + source}, c.tags...), c.deviceTags[source]...)
+
+	trap := "unknown"
+	var text bytes.Buffer
+	variables := make(map[string]string, len(varBinds))
+	for _, vb := range varBinds {
+		name := resolveOID(c.resolver, vb.Oid.String())
+		value := vb.Variable.String()
+		key := variableKey(name)
+		switch key {
+		case "snmpTrapOID":
+			if oid, ok := vb.Variable.(*snmpgo.Oid); ok {
+				trap = resolveOID(c.resolver, oid.String())
+			}
+			continue
+		case "sysUpTime":
+			continue
+		}
+		variables[key] = value
+		fmt.Fprintf(&text, "%s = %s\n", name, value)
+	}
+
+	event := metrics.Event{
+		Title:          fmt.Sprintf("SNMP trap %s from %s", trap, source),
+		Text:           strings.TrimSuffix(text.String(), "\n"),
+		Ts:             now.Unix(),
+		Priority:       metrics.EventPriorityNormal,
+		Tags:           append(append([]string{}, tags...), "snmp_trap:"+trap),
+		AlertType:      metrics.EventAlertTypeInfo,
+		AggregationKey: source + ":" + trap,
+		SourceTypeName: sourceTypeName,
+		EventType:      eventType,
+	}
+
+	var serviceChecks []metrics.ServiceCheck
+	for _, rule := range c.serviceChecks[trap] {
+		scTags := append([]string{}, tags...)
+		for _, key := range rule.varbindKeys {
+			if value, ok := variables[key]; ok {
+				scTags = append(scTags, key+":"+value)
+			}
+		}
+		serviceChecks = append(serviceChecks, metrics.ServiceCheck{
+			CheckName: rule.Name,
+			Ts:        now.Unix(),
+			Status:    rule.status,
+			Message:   event.Title,
+			Tags:      scTags,
+		})
+		// the event of a trap reported critical by a service check is an error
+		switch {
+		case rule.status == metrics.ServiceCheckCritical:
+			event.AlertType = metrics.EventAlertTypeError
+		case rule.status == metrics.ServiceCheckWarning && event.AlertType != metrics.EventAlertTypeError:
+			event.AlertType = metrics.EventAlertTypeWarning
+		}
+	}
+	return event, serviceChecks
+}
@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package snmptraps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/k-sone/snmpgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// linkDownTrap returns the variables of a linkDown trap of the interface 2
+func linkDownTrap() snmpgo.VarBinds {
+	return snmpgo.VarBinds{
+		{Oid: snmpgo.MustNewOid("1.3.6.1.2.1.1.3.0"), Variable: snmpgo.NewTimeTicks(1234)},
+		{Oid: snmpgo.MustNewOid("1.3.6.1.6.3.1.1.4.1.0"), Variable: snmpgo.MustNewOid("1.3.6.1.6.3.1.1.5.3")},
+		{Oid: snmpgo.MustNewOid("1.3.6.1.2.1.2.2.1.1.2"), Variable: snmpgo.NewInteger(2)},
+		{Oid: snmpgo.MustNewOid("1.3.6.1.2.1.2.2.1.2.2"), Variable: snmpgo.NewOctetString([]byte("eth1"))},
+		{Oid: snmpgo.MustNewOid("1.3.6.1.4.1.2636.3.1.1"), Variable: snmpgo.NewInteger(7)},
+	}
+}
+
+func TestConvert(t *testing.T) {
+	cfg := &Config{
+		Tags:       []string{"network:core"},
+		DeviceTags: map[string][]string{"10.0.0.1": {"device:switch-1"}},
+		ServiceChecks: []ServiceCheckRule{
+			{Trap: "IF-MIB::linkDown", Name: "snmp.interface.up", Status: "critical", VarbindTags: []string{"IF-MIB::ifIndex", "1.3.6.1.2.1.2.2.1.2"}},
+			{Trap: "1.3.6.1.6.3.1.1.5.4", Name: "snmp.interface.up", Status: "ok"},
+		},
+	}
+	require.NoError(t, cfg.validate())
+	c := newConverter(cfg, tableResolver(genericTranslations))
+	now := time.Unix(1500000000, 0)
+
+	event, serviceChecks := c.convert("10.0.0.1", linkDownTrap(), now)
+	assert.Equal(t, metrics.Event{
+		Title:          "SNMP trap IF-MIB::linkDown from 10.0.0.1",
+		Text:           "IF-MIB::ifIndex.2 = 2\nIF-MIB::ifDescr.2 = eth1\n1.3.6.1.4.1.2636.3.1.1 = 7",
+		Ts:             1500000000,
+		Priority:       metrics.EventPriorityNormal,
+		Tags:           []string{"snmp_device:10.0.0.1", "network:core", "device:switch-1", "snmp_trap:IF-MIB::linkDown"},
+		AlertType:      metrics.EventAlertTypeError,
+		AggregationKey: "10.0.0.1:IF-MIB::linkDown",
+		SourceTypeName: "snmp",
+		EventType:      "snmp_trap",
+	}, event)
+	assert.Equal(t, []metrics.ServiceCheck{{
+		CheckName: "snmp.interface.up",
+		Ts:        1500000000,
+		Status:    metrics.ServiceCheckCritical,
+		Message:   "SNMP trap IF-MIB::linkDown from 10.0.0.1",
+		Tags:      []string{"snmp_device:10.0.0.1", "network:core", "device:switch-1", "ifIndex:2", "ifDescr:eth1"},
+	}}, serviceChecks)
+
+	// a trap without service check is an info event
+	event, serviceChecks = c.convert("10.0.0.2", snmpgo.VarBinds{
+		{Oid: snmpgo.MustNewOid("1.3.6.1.6.3.1.1.4.1.0"), Variable: snmpgo.MustNewOid("1.3.6.1.6.3.1.1.5.1")},
+	}, now)
+	assert.Equal(t, "SNMP trap SNMPv2-MIB::coldStart from 10.0.0.2", event.Title)
+	assert.Equal(t, metrics.EventAlertTypeInfo, event.AlertType)
+	assert.Equal(t, []string{"snmp_device:10.0.0.2", "network:core", "snmp_trap:SNMPv2-MIB::coldStart"}, event.Tags)
+	assert.Empty(t, serviceChecks)
+}
+
+func TestValidateConfig(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"no credentials":       {},
+		"user without name":    {Users: []UserConfig{{AuthKey: "secret"}}},
+		"privacy without auth": {Users: []UserConfig{{User: "u", PrivKey: "secret"}}},
+		"unknown auth":         {Users: []UserConfig{{User: "u", AuthKey: "secret", AuthProtocol: "SHA512"}}},
+		"unknown priv":         {Users: []UserConfig{{User: "u", AuthKey: "secret", PrivKey: "secret", PrivProtocol: "3DES"}}},
+		"service check status": {CommunityString: "public", ServiceChecks: []ServiceCheckRule{{Trap: "IF-MIB::linkUp", Name: "up", Status: "fine"}}},
+		"service check name":   {CommunityString: "public", ServiceChecks: []ServiceCheckRule{{Trap: "IF-MIB::linkUp"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, cfg.validate())
+		})
+	}
+}
+
+func TestSecurityEntry(t *testing.T) {
+	entry, err := UserConfig{User: "u"}.securityEntry()
+	require.NoError(t, err)
+	assert.Equal(t, snmpgo.NoAuthNoPriv, entry.SecurityLevel)
+
+	entry, err = UserConfig{User: "u", AuthKey: "auth-secret", AuthProtocol: "sha"}.securityEntry()
+	require.NoError(t, err)
+	assert.Equal(t, snmpgo.AuthNoPriv, entry.SecurityLevel)
+	assert.Equal(t, snmpgo.Sha, entry.AuthProtocol)
+
+	entry, err = UserConfig{User: "u", AuthKey: "auth-secret", PrivKey: "priv-secret", PrivProtocol: "AES", EngineID: "8000000001020304"}.securityEntry()
+	require.NoError(t, err)
+	assert.Equal(t, snmpgo.AuthPriv, entry.SecurityLevel)
+	assert.Equal(t, snmpgo.Md5, entry.AuthProtocol)
+	assert.Equal(t, snmpgo.Aes, entry.PrivProtocol)
+	assert.Equal(t, "8000000001020304", entry.SecurityEngineId)
+}
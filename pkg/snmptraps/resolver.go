@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package snmptraps
+
+import (
+	"strings"
+)
+
+// genericTranslations are the names of the generic traps of SNMPv2-MIB and
+// of the variables they carry, they are resolved without any MIB
+var genericTranslations = map[string]string{
+	"1.3.6.1.2.1.1.3":         "SNMPv2-MIB::sysUpTime",
+	"1.3.6.1.6.3.1.1.4.1":     "SNMPv2-MIB::snmpTrapOID",
+	"1.3.6.1.6.3.1.1.4.3":     "SNMPv2-MIB::snmpTrapEnterprise",
+	"1.3.6.1.6.3.1.1.5.1":     "SNMPv2-MIB::coldStart",
+	"1.3.6.1.6.3.1.1.5.2":     "SNMPv2-MIB::warmStart",
+	"1.3.6.1.6.3.1.1.5.3":     "IF-MIB::linkDown",
+	"1.3.6.1.6.3.1.1.5.4":     "IF-MIB::linkUp",
+	"1.3.6.1.6.3.1.1.5.5":     "SNMPv2-MIB::authenticationFailure",
+	"1.3.6.1.2.1.2.2.1.1":     "IF-MIB::ifIndex",
+	"1.3.6.1.2.1.2.2.1.2":     "IF-MIB::ifDescr",
+	"1.3.6.1.2.1.2.2.1.7":     "IF-MIB::ifAdminStatus",
+	"1.3.6.1.2.1.2.2.1.8":     "IF-MIB::ifOperStatus",
+	"1.3.6.1.2.1.31.1.1.1.1":  "IF-MIB::ifName",
+	"1.3.6.1.2.1.31.1.1.1.18": "IF-MIB::ifAlias",
+}
+
+// resolver translates the OIDs of the traps and of their variables to the
+// names of their MIB objects
+type resolver interface {
+	// resolve returns the name of oid, and false when it isn't known
+	resolve(oid string) (string, bool)
+}
+
+// newMIBResolver returns a resolver loading the MIBs of a folder, it is set
+// by the builds linking net-snmp
+var newMIBResolver func(mibsFolder string) (resolver, error)
+
+// tableResolver resolves the OIDs from a table, the OIDs of the table can be
+// prefixes: the instance of a column, e.g. IF-MIB::ifIndex.2, resolves to
+// the name of the column followed by its index
+type tableResolver map[string]string
+
+func (t tableResolver) resolve(oid string) (string, bool) {
+	oid = strings.TrimPrefix(oid, ".")
+	for prefix := oid; prefix != ""; {
+		if name, ok := t[prefix]; ok {
+			return name + oid[len(prefix):], true
+		}
+		i := strings.LastIndex(prefix, ".")
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	return "", false
+}
+
+// chainResolver returns the name of the first of its resolvers knowing an OID
+type chainResolver []resolver
+
+func (c chainResolver) resolve(oid string) (string, bool) {
+	for _, r := range c {
+		if name, ok := r.resolve(oid); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// resolveOID returns the name of oid, or oid when it isn't known
+func resolveOID(r resolver, oid string) string {
+	if name, ok := r.resolve(oid); ok {
+		return name
+	}
+	return strings.TrimPrefix(oid, ".")
+}
+
+// variableKey returns the key of the tags of a variable: its name without
+// the MIB module and the index, e.g. ifIndex for IF-MIB::ifIndex.2, or its
+// OID when it isn't resolved
+func variableKey(name string) string {
+	i := strings.LastIndex(name, "::")
+	if i < 0 {
+		return name
+	}
+	name = name[i+2:]
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !windows
+// +build snmp
+
+package snmptraps
+
+/*
+#cgo pkg-config: net-snmp-5.7.3
+
+#include <stdlib.h>
+#include <net-snmp/net-snmp-config.h>
+#include <net-snmp/net-snmp-includes.h>
+#include <net-snmp/mib_api.h>
+*/
+import "C"
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+const maxOIDLen = 128
+
+// the MIB tree of net-snmp is global, it is only read once it's initialized
+var (
+	initMIBs sync.Once
+	mibMutex sync.Mutex
+)
+
+func init() {
+	newMIBResolver = newNetSNMPResolver
+}
+
+// netSNMPResolver resolves the OIDs with the MIBs loaded by net-snmp
+type netSNMPResolver struct{}
+
+func newNetSNMPResolver(mibsFolder string) (resolver, error) {
+	initMIBs.Do(func() {
+		if mibsFolder != "" {
+			dir := C.CString(mibsFolder)
+			defer C.free(unsafe.Pointer(dir))
+			C.add_mibdir(dir)
+		}
+		C.netsnmp_init_mib()
+		C.read_all_mibs()
+	})
+	return netSNMPResolver{}, nil
+}
+
+func (netSNMPResolver) resolve(oid string) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(oid, "."), ".")
+	if len(parts) > maxOIDLen {
+		return "", false
+	}
+	var holder [maxOIDLen]C.oid
+	for i, part := range parts {
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return "", false
+		}
+		holder[i] = C.oid(id)
+	}
+
+	var buf [512]C.char
+	mibMutex.Lock()
+	C.snprint_objid(&buf[0], C.size_t(len(buf)-1), &holder[0], C.size_t(len(parts)))
+	mibMutex.Unlock()
+
+	// the OIDs not found in the MIBs are printed numerically
+	name := C.GoString(&buf[0])
+	if !strings.Contains(name, "::") {
+		return "", false
+	}
+	return name, true
+}
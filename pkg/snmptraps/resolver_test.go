@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package snmptraps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableResolver(t *testing.T) {
+	r := tableResolver(genericTranslations)
+
+	for oid, expected := range map[string]string{
+		"1.3.6.1.6.3.1.1.5.3":      "IF-MIB::linkDown",
+		".1.3.6.1.6.3.1.1.5.3":     "IF-MIB::linkDown",
+		"1.3.6.1.2.1.2.2.1.1.12":   "IF-MIB::ifIndex.12",
+		"1.3.6.1.6.3.1.1.4.1.0":    "SNMPv2-MIB::snmpTrapOID.0",
+		"1.3.6.1.4.1.9.9.43.2.0.1": "",
+	} {
+		name, ok := r.resolve(oid)
+		assert.Equal(t, expected != "", ok, oid)
+		assert.Equal(t, expected, name, oid)
+	}
+}
+
+func TestChainResolver(t *testing.T) {
+	r := chainResolver{
+		tableResolver{"1.3.6.1.4.1.9.9.43.2.0": "CISCO-CONFIG-MAN-MIB::ciscoConfigManMIBNotificationPrefix"},
+		tableResolver(genericTranslations),
+	}
+	assert.Equal(t, "CISCO-CONFIG-MAN-MIB::ciscoConfigManMIBNotificationPrefix.1", resolveOID(r, "1.3.6.1.4.1.9.9.43.2.0.1"))
+	assert.Equal(t, "IF-MIB::linkUp", resolveOID(r, "1.3.6.1.6.3.1.1.5.4"))
+	assert.Equal(t, "1.3.6.1.4.1.2636.4.1.1", resolveOID(r, ".1.3.6.1.4.1.2636.4.1.1"))
+}
+
+func TestVariableKey(t *testing.T) {
+	assert.Equal(t, "ifIndex", variableKey("IF-MIB::ifIndex.2"))
+	assert.Equal(t, "ifIndex", variableKey("IF-MIB::ifIndex"))
+	assert.Equal(t, "snmpTrapOID", variableKey("SNMPv2-MIB::snmpTrapOID.0"))
+	assert.Equal(t, "1.3.6.1.4.1.2636.4.1.1", variableKey("1.3.6.1.4.1.2636.4.1.1"))
+}
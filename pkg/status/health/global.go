@@ -7,17 +7,31 @@ package health
 
 var globalCatalog = newCatalog()
 
-// Register a component with the default 30 seconds timeout, returns a token
+// Register a component with the default 30 seconds timeout, returns a token.
+// The component is required for both the liveness and the readiness.
 func Register(name string) *Handle {
 	return globalCatalog.register(name)
 }
 
+// RegisterReadiness registers a component that is only required for the
+// readiness: the agent is not restarted when it is unhealthy.
+func RegisterReadiness(name string) *Handle {
+	return globalCatalog.registerReadiness(name)
+}
+
 // Deregister a component from the healthcheck
 func Deregister(handle *Handle) error {
 	return globalCatalog.deregister(handle)
 }
 
-// GetStatus allows to query the health status of the agent
-func GetStatus() Status {
+// GetLive returns the liveness of the agent, it is unhealthy when the agent
+// needs to be restarted
+func GetLive() Status {
+	return globalCatalog.getLiveStatus()
+}
+
+// GetReady returns the readiness of the agent, it is unhealthy when the agent
+// cannot process its inputs yet
+func GetReady() Status {
 	return globalCatalog.getStatus()
 }
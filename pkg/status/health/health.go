@@ -28,6 +28,8 @@ type component struct {
 	name       string
 	healthChan chan struct{}
 	healthy    bool
+	// readinessOnly components are not required for the liveness
+	readinessOnly bool
 }
 
 type catalog struct {
@@ -45,6 +47,15 @@ func newCatalog() *catalog {
 
 // register a component with the default 30 seconds timeout, returns a token
 func (c *catalog) register(name string) *Handle {
+	return c.registerComponent(name, false)
+}
+
+// registerReadiness registers a component that is only required for the readiness
+func (c *catalog) registerReadiness(name string) *Handle {
+	return c.registerComponent(name, true)
+}
+
+func (c *catalog) registerComponent(name string, readinessOnly bool) *Handle {
 	c.Lock()
 	defer c.Unlock()
 
@@ -53,9 +64,10 @@ func (c *catalog) register(name string) *Handle {
 	}
 
 	component := &component{
-		name:       name,
-		healthChan: make(chan struct{}, bufferSize),
-		healthy:    false,
+		name:          name,
+		healthChan:    make(chan struct{}, bufferSize),
+		healthy:       false,
+		readinessOnly: readinessOnly,
 	}
 	h := &Handle{
 		C: component.healthChan,
@@ -80,6 +92,7 @@ func (c *catalog) run() {
 		<-pingTicker.C
 		c.Lock()
 		if len(c.components) == 0 {
+			c.Unlock()
 			break
 		}
 		c.pingComponents()
@@ -115,14 +128,24 @@ func (c *catalog) deregister(handle *Handle) error {
 }
 
 // Status represents the current status of registered components
-// it is built and returned by GetStatus()
+// it is built and returned by GetLive() and GetReady()
 type Status struct {
 	Healthy   []string
 	Unhealthy []string
 }
 
-// getStatus allows to query the health status of the agent
+// getStatus returns the readiness of the agent: the status of every component
 func (c *catalog) getStatus() Status {
+	return c.status(false)
+}
+
+// getLiveStatus returns the liveness of the agent: the status of the
+// components that are not only required for the readiness
+func (c *catalog) getLiveStatus() Status {
+	return c.status(true)
+}
+
+func (c *catalog) status(liveness bool) Status {
 	status := Status{}
 	c.RLock()
 	defer c.RUnlock()
@@ -136,6 +159,9 @@ func (c *catalog) getStatus() Status {
 
 	// Check components
 	for _, component := range c.components {
+		if liveness && component.readinessOnly {
+			continue
+		}
 		if component.healthy {
 			status.Healthy = append(status.Healthy, component.name)
 		} else {
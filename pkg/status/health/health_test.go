@@ -126,3 +126,27 @@ func TestUnhealthyAndBack(t *testing.T) {
 	assert.Len(t, status.Healthy, 2)
 	assert.Len(t, status.Unhealthy, 0)
 }
+
+func TestReadinessOnly(t *testing.T) {
+	cat := newCatalog()
+	cat.register("live")
+	token := cat.registerReadiness("ready")
+
+	status := cat.getStatus()
+	assert.Contains(t, status.Unhealthy, "live")
+	assert.Contains(t, status.Unhealthy, "ready")
+
+	live := cat.getLiveStatus()
+	assert.Contains(t, live.Unhealthy, "live")
+	assert.NotContains(t, live.Unhealthy, "ready")
+	assert.NotContains(t, live.Healthy, "ready")
+
+	for i := 1; i < 10; i++ {
+		cat.pingComponents()
+		<-token.C
+	}
+
+	status = cat.getStatus()
+	assert.Contains(t, status.Healthy, "ready")
+	assert.Contains(t, status.Unhealthy, "live")
+}
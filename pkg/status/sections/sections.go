@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package sections holds the sections of the status that can be requested on
+// their own, each component registers the provider of its section.
+package sections
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Provider provides the structured status of a component.
+type Provider interface {
+	// Name is the name of the section, for instance forwarder
+	Name() string
+	// Status returns the status of the component, it is serialized to JSON
+	Status() (interface{}, error)
+}
+
+// SectionNotFoundError is returned for the sections that are not registered.
+type SectionNotFoundError struct {
+	name  string
+	names []string
+}
+
+func (e *SectionNotFoundError) Error() string {
+	return fmt.Sprintf("unknown status section %s, the sections are: %s", e.name, strings.Join(e.names, ", "))
+}
+
+var (
+	providers = map[string]Provider{}
+	mu        sync.RWMutex
+)
+
+// Register adds the section of a component.
+func Register(provider Provider) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, found := providers[provider.Name()]; found {
+		return fmt.Errorf("duplicated status section %s", provider.Name())
+	}
+	providers[provider.Name()] = provider
+	return nil
+}
+
+// Names returns the names of the sections registered, in order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the status of a section.
+func Get(name string) (interface{}, error) {
+	mu.RLock()
+	provider, found := providers[name]
+	mu.RUnlock()
+	if !found {
+		return nil, &SectionNotFoundError{name: name, names: Names()}
+	}
+	return provider.Status()
+}
+
+// ExpvarStatus returns the value of an expvar as a status, decoded from its JSON.
+func ExpvarStatus(name string) (interface{}, error) {
+	variable := expvar.Get(name)
+	if variable == nil {
+		return nil, fmt.Errorf("the expvar %s is not published", name)
+	}
+	var status interface{}
+	if err := json.Unmarshal([]byte(variable.String()), &status); err != nil {
+		return nil, fmt.Errorf("could not decode the expvar %s: %s", name, err)
+	}
+	return status, nil
+}
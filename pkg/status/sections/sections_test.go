@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package sections
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testProvider struct {
+	name string
+}
+
+func (p testProvider) Name() string { return p.name }
+
+func (p testProvider) Status() (interface{}, error) {
+	return map[string]int{"workers": 2}, nil
+}
+
+func TestRegisterAndGet(t *testing.T) {
+	require.Nil(t, Register(testProvider{name: "test-b"}))
+	require.Nil(t, Register(testProvider{name: "test-a"}))
+	assert.NotNil(t, Register(testProvider{name: "test-a"}))
+	defer func() {
+		delete(providers, "test-a")
+		delete(providers, "test-b")
+	}()
+
+	assert.Equal(t, []string{"test-a", "test-b"}, Names())
+
+	status, err := Get("test-a")
+	require.Nil(t, err)
+	assert.Equal(t, map[string]int{"workers": 2}, status)
+
+	_, err = Get("unknown")
+	require.NotNil(t, err)
+	assert.IsType(t, &SectionNotFoundError{}, err)
+	assert.Equal(t, "unknown status section unknown, the sections are: test-a, test-b", err.Error())
+}
+
+func TestExpvarStatus(t *testing.T) {
+	m := expvar.NewMap("sections_test")
+	m.Add("Transactions", 3)
+
+	status, err := ExpvarStatus("sections_test")
+	require.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"Transactions": float64(3)}, status)
+
+	_, err = ExpvarStatus("sections_test_unknown")
+	assert.NotNil(t, err)
+}
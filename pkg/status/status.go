@@ -16,7 +16,6 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
-	"github.com/DataDog/datadog-agent/pkg/logs"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/version"
@@ -56,7 +55,7 @@ func GetStatus() (map[string]interface{}, error) {
 
 	stats["JMXStatus"] = GetJMXStatus()
 
-	stats["logsStats"] = logs.GetStatus()
+	stats["logsStats"] = getLogsStatus()
 
 	return stats, nil
 }
@@ -0,0 +1,14 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !log
+
+package status
+
+// getLogsStatus returns the status of a stopped logs-agent, the agent is built
+// without it
+func getLogsStatus() interface{} {
+	return map[string]interface{}{"is_running": false}
+}
@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package collectors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StringToTagCardinality extracts a TagCardinality from a string.
+// In case of failure to parse, returns an error and defaults to LowCardinality.
+func StringToTagCardinality(c string) (TagCardinality, error) {
+	switch strings.ToLower(c) {
+	case "high":
+		return HighCardinality, nil
+	case "orchestrator":
+		return OrchestratorCardinality, nil
+	case "low":
+		return LowCardinality, nil
+	default:
+		return LowCardinality, fmt.Errorf("unsupported value %s received for tag cardinality", c)
+	}
+}
+
+// String returns a string representation of TagCardinality
+func (c TagCardinality) String() string {
+	switch c {
+	case HighCardinality:
+		return "high"
+	case OrchestratorCardinality:
+		return "orchestrator"
+	case LowCardinality:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
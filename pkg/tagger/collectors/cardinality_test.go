@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package collectors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringToTagCardinality(t *testing.T) {
+	for in, out := range map[string]TagCardinality{
+		"low":          LowCardinality,
+		"orchestrator": OrchestratorCardinality,
+		"High":         HighCardinality,
+	} {
+		card, err := StringToTagCardinality(in)
+		assert.NoError(t, err)
+		assert.Equal(t, out, card)
+		assert.Equal(t, strings.ToLower(in), card.String())
+	}
+
+	card, err := StringToTagCardinality("unknown")
+	assert.Error(t, err)
+	assert.Equal(t, LowCardinality, card)
+}
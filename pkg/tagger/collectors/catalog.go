@@ -5,6 +5,8 @@
 
 package collectors
 
+import log "github.com/cihub/seelog"
+
 // CollectorFactory is functions that return a Collector
 type CollectorFactory func() Collector
 
@@ -17,8 +19,15 @@ var DefaultCatalog = make(Catalog)
 // CollectorPriorities holds collector priorities
 var CollectorPriorities = make(map[string]CollectorPriority)
 
-// registerCollector is to be called by collectors to be added to the default catalog
-func registerCollector(name string, c CollectorFactory, p CollectorPriority) {
+// RegisterCollector adds a collector to the default catalog, with the priority
+// used to resolve duplicate tags with other collectors. It is to be called in
+// the init function of the collectors, including the ones built out of this
+// package. Collectors can implement EntityPrefixer to restrict the entities
+// they are queried for.
+func RegisterCollector(name string, c CollectorFactory, p CollectorPriority) {
+	if _, ok := DefaultCatalog[name]; ok {
+		log.Warnf("Tagger collector %s already registered, overriding it", name)
+	}
 	DefaultCatalog[name] = c
 	CollectorPriorities[name] = p
 }
@@ -20,6 +20,10 @@ func requireMatchInfo(t *testing.T, expected []*TagInfo, item *TagInfo) bool {
 		sort.Strings(item.LowCardTags)
 		require.Equal(t, template.LowCardTags, item.LowCardTags)
 
+		sort.Strings(template.OrchestratorCardTags)
+		sort.Strings(item.OrchestratorCardTags)
+		require.Equal(t, template.OrchestratorCardTags, item.OrchestratorCardTags)
+
 		sort.Strings(template.HighCardTags)
 		sort.Strings(item.HighCardTags)
 		require.Equal(t, template.HighCardTags, item.HighCardTags)
@@ -37,6 +41,9 @@ func assertTagInfoEqual(t *testing.T, expected *TagInfo, item *TagInfo) bool {
 	sort.Strings(expected.LowCardTags)
 	sort.Strings(item.LowCardTags)
 
+	sort.Strings(expected.OrchestratorCardTags)
+	sort.Strings(item.OrchestratorCardTags)
+
 	sort.Strings(expected.HighCardTags)
 	sort.Strings(item.HighCardTags)
 
@@ -18,7 +18,7 @@ import (
 )
 
 // extractFromInspect extract tags for a container inspect JSON
-func (c *DockerCollector) extractFromInspect(co types.ContainerJSON) ([]string, []string, error) {
+func (c *DockerCollector) extractFromInspect(co types.ContainerJSON) ([]string, []string, []string, error) {
 	tags := utils.NewTagList()
 
 	//TODO: remove when Inspect returns resolved image names
@@ -30,12 +30,14 @@ func (c *DockerCollector) extractFromInspect(co types.ContainerJSON) ([]string,
 	}
 	dockerExtractLabels(tags, co.Config.Labels, c.labelsAsTags)
 	dockerExtractEnvironmentVariables(tags, co.Config.Env, c.envAsTags)
+	// the kubelet sets the pod namespace as a container label
+	c.staticTags.AddTags(dockerImage, co.Config.Labels["io.kubernetes.pod.namespace"], co.Config.Labels, tags)
 
 	tags.AddHigh("container_name", strings.TrimPrefix(co.Name, "/"))
 	tags.AddHigh("container_id", co.ID)
 
-	low, high := tags.Compute()
-	return low, high, nil
+	low, orchestrator, high := tags.Compute()
+	return low, orchestrator, high, nil
 }
 
 func dockerExtractImage(tags *utils.TagList, dockerImage string) {
@@ -52,6 +54,7 @@ func dockerExtractImage(tags *utils.TagList, dockerImage string) {
 
 // dockerExtractLabels contain hard-coded labels from:
 // - Docker swarm
+// - Rancher 1.x
 func dockerExtractLabels(tags *utils.TagList, containerLabels map[string]string, labelsAsTags map[string]string) {
 
 	for labelName, labelValue := range containerLabels {
@@ -61,6 +64,10 @@ func dockerExtractLabels(tags *utils.TagList, containerLabels map[string]string,
 			tags.AddLow("swarm_service", labelValue)
 		case "com.docker.stack.namespace":
 			tags.AddLow("swarm_namespace", labelValue)
+		case "com.docker.swarm.task.name":
+			tags.AddOrchestrator("swarm_task", labelValue)
+		case "com.docker.swarm.node.id":
+			tags.AddLow("swarm_node", labelValue)
 
 		// Rancher 1.x
 		case "io.rancher.container.name":
@@ -80,6 +87,7 @@ func dockerExtractLabels(tags *utils.TagList, containerLabels map[string]string,
 
 // dockerExtractEnvironmentVariables contain hard-coded environment variables from:
 // - Mesos/DCOS tags (mesos, marathon, chronos)
+// - Nomad allocations
 func dockerExtractEnvironmentVariables(tags *utils.TagList, containerEnvVariables []string, envAsTags map[string]string) {
 	var envSplit []string
 	var envName, envValue string
@@ -100,7 +108,7 @@ func dockerExtractEnvironmentVariables(tags *utils.TagList, containerEnvVariable
 		case "CHRONOS_JOB_OWNER":
 			tags.AddLow("chronos_job_owner", envValue)
 		case "MESOS_TASK_ID":
-			tags.AddHigh("mesos_task", envValue)
+			tags.AddOrchestrator("mesos_task", envValue)
 
 		// Nomad
 		case "NOMAD_TASK_NAME":
@@ -109,6 +117,10 @@ func dockerExtractEnvironmentVariables(tags *utils.TagList, containerEnvVariable
 			tags.AddLow("nomad_job", envValue)
 		case "NOMAD_GROUP_NAME":
 			tags.AddLow("nomad_group", envValue)
+		case "NOMAD_DC":
+			tags.AddLow("nomad_dc", envValue)
+		case "NOMAD_ALLOC_ID":
+			tags.AddOrchestrator("nomad_alloc", envValue)
 
 		default:
 			if tagName, found := envAsTags[strings.ToLower(envSplit[0])]; found {
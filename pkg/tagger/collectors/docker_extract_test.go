@@ -25,6 +25,7 @@ func TestDockerRecordsFromInspect(t *testing.T) {
 		toRecordEnvAsTags    map[string]string
 		toRecordLabelsAsTags map[string]string
 		expectedLow          []string
+		expectedOrchestrator []string
 		expectedHigh         []string
 	}{
 		{
@@ -99,7 +100,8 @@ func TestDockerRecordsFromInspect(t *testing.T) {
 				"chronos_job:app1_process-orders",
 				"chronos_job_owner:qa",
 			},
-			expectedHigh: []string{"mesos_task:system_dd-agent.dcc75b42-4b87-11e7-9a62-70b3d5800001"},
+			expectedOrchestrator: []string{"mesos_task:system_dd-agent.dcc75b42-4b87-11e7-9a62-70b3d5800001"},
+			expectedHigh:         []string{},
 		},
 		{
 			testName: "NoValue",
@@ -202,6 +204,8 @@ func TestDockerRecordsFromInspect(t *testing.T) {
 						"NOMAD_TASK_NAME=test-task",
 						"NOMAD_JOB_NAME=test-job",
 						"NOMAD_GROUP_NAME=test-group",
+						"NOMAD_DC=dc1",
+						"NOMAD_ALLOC_ID=5d2f6f3e-5c0c-2b11-2d9a-2e3b4c5d6e7f",
 					},
 					Labels: map[string]string{},
 				},
@@ -212,8 +216,33 @@ func TestDockerRecordsFromInspect(t *testing.T) {
 				"nomad_task:test-task",
 				"nomad_job:test-job",
 				"nomad_group:test-group",
+				"nomad_dc:dc1",
+			},
+			expectedOrchestrator: []string{"nomad_alloc:5d2f6f3e-5c0c-2b11-2d9a-2e3b4c5d6e7f"},
+			expectedHigh:         []string{},
+		},
+		{
+			testName: "extractSwarm",
+			co: &types.ContainerJSON{
+				Config: &container.Config{
+					Env: []string{},
+					Labels: map[string]string{
+						"com.docker.swarm.service.name": "helloworld",
+						"com.docker.stack.namespace":    "mystack",
+						"com.docker.swarm.task.name":    "helloworld.1.6x8rm7y3cy2xmrjf4nll9ond3",
+						"com.docker.swarm.node.id":      "ycp4ttdqpmthr0b8invjsd9gh",
+					},
+				},
 			},
-			expectedHigh: []string{},
+			toRecordEnvAsTags:    map[string]string{},
+			toRecordLabelsAsTags: map[string]string{},
+			expectedLow: []string{
+				"swarm_service:helloworld",
+				"swarm_namespace:mystack",
+				"swarm_node:ycp4ttdqpmthr0b8invjsd9gh",
+			},
+			expectedOrchestrator: []string{"swarm_task:helloworld.1.6x8rm7y3cy2xmrjf4nll9ond3"},
+			expectedHigh:         []string{},
 		},
 	}
 
@@ -225,7 +254,7 @@ func TestDockerRecordsFromInspect(t *testing.T) {
 			tags := utils.NewTagList()
 			dockerExtractEnvironmentVariables(tags, test.co.Config.Env, test.toRecordEnvAsTags)
 			dockerExtractLabels(tags, test.co.Config.Labels, test.toRecordLabelsAsTags)
-			low, high := tags.Compute()
+			low, orchestrator, high := tags.Compute()
 
 			// Low card tags
 			assert.Equal(t, len(test.expectedLow), len(low), "test case %d", i)
@@ -233,6 +262,12 @@ func TestDockerRecordsFromInspect(t *testing.T) {
 				assert.Contains(t, low, lt, "test case %d", i)
 			}
 
+			// Orchestrator card tags
+			assert.Equal(t, len(test.expectedOrchestrator), len(orchestrator), "test case %d", i)
+			for _, ot := range test.expectedOrchestrator {
+				assert.Contains(t, orchestrator, ot, "test case %d", i)
+			}
+
 			// High card tags
 			assert.True(t, len(test.expectedHigh) == len(high))
 			for _, ht := range test.expectedHigh {
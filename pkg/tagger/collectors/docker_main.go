@@ -15,6 +15,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 )
 
@@ -31,6 +32,7 @@ type DockerCollector struct {
 	infoOut      chan<- []*TagInfo
 	labelsAsTags map[string]string
 	envAsTags    map[string]string
+	staticTags   *utils.StaticTags
 }
 
 // Detect tries to connect to the docker socket and returns success
@@ -58,6 +60,7 @@ func (c *DockerCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error)
 		envList[strings.ToLower(env)] = value
 	}
 	c.envAsTags = envList
+	c.staticTags = staticTagsFromConfig()
 	// TODO: list and inspect existing containers once docker utils are merged
 
 	return StreamCollection, nil
@@ -98,10 +101,10 @@ func (c *DockerCollector) Stop() error {
 }
 
 // Fetch inspect a given container to get its tags on-demand (cache miss)
-func (c *DockerCollector) Fetch(container string) ([]string, []string, error) {
+func (c *DockerCollector) Fetch(container string) ([]string, []string, []string, error) {
 	cID := strings.TrimPrefix(container, docker.DockerEntityPrefix)
 	if cID == container || len(cID) == 0 {
-		return nil, nil, nil
+		return nil, nil, nil, nil
 	}
 	return c.fetchForDockerID(cID)
 }
@@ -113,28 +116,33 @@ func (c *DockerCollector) processEvent(e *docker.ContainerEvent) {
 	case "die":
 		info = &TagInfo{Entity: e.ContainerEntityName(), Source: dockerCollectorName, DeleteEntity: true}
 	case "start":
-		low, high, _ := c.fetchForDockerID(e.ContainerID)
-		info = &TagInfo{Entity: e.ContainerEntityName(), Source: dockerCollectorName, LowCardTags: low, HighCardTags: high}
+		low, orchestrator, high, _ := c.fetchForDockerID(e.ContainerID)
+		info = &TagInfo{Entity: e.ContainerEntityName(), Source: dockerCollectorName, LowCardTags: low, OrchestratorCardTags: orchestrator, HighCardTags: high}
 	default:
 		return // Nothing to see here
 	}
 	c.infoOut <- []*TagInfo{info}
 }
 
-func (c *DockerCollector) fetchForDockerID(cID string) ([]string, []string, error) {
+func (c *DockerCollector) fetchForDockerID(cID string) ([]string, []string, []string, error) {
 	co, err := c.dockerUtil.Inspect(cID, false)
 	if err != nil {
 		// TODO separate "not found" and inspect error
 		log.Errorf("Failed to inspect container %s - %s", cID, err)
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	return c.extractFromInspect(co)
 }
 
+// EntityPrefixes returns the prefixes of the entities the collector knows about
+func (c *DockerCollector) EntityPrefixes() []string {
+	return []string{docker.DockerEntityPrefix}
+}
+
 func dockerFactory() Collector {
 	return &DockerCollector{}
 }
 
 func init() {
-	registerCollector(dockerCollectorName, dockerFactory, NodeRuntime)
+	RegisterCollector(dockerCollectorName, dockerFactory, NodeRuntime)
 }
@@ -28,6 +28,7 @@ var isBlacklisted = map[string]bool{
 
 // pullMetadata parses the the task metadata, and its container list, and returns a list of TagInfo for the new ones.
 // It also updates the lastSeen cache of the ECSFargateCollector and return the list of dead containers to be expired.
+// It must be called with c.m held.
 func (c *ECSFargateCollector) pullMetadata(meta ecs.TaskMetadata) ([]*TagInfo, []string, error) {
 	var output []*TagInfo
 	seen := make(map[string]interface{}, len(meta.Containers))
@@ -39,45 +40,7 @@ func (c *ECSFargateCollector) pullMetadata(meta ecs.TaskMetadata) ([]*TagInfo, [
 	for _, ctr := range meta.Containers {
 		seen[ctr.DockerID] = nil
 		if _, found := c.lastSeen[ctr.DockerID]; !found {
-			tags := utils.NewTagList()
-
-			// cluster
-			tags.AddLow("cluster_name", meta.ClusterName)
-
-			// task
-			tags.AddLow("task_family", meta.Family)
-			tags.AddLow("task_version", meta.Version)
-
-			// container
-			tags.AddLow("ecs_container_name", ctr.Name)
-			tags.AddHigh("container_name", ctr.DockerName)
-
-			// container image
-			image := ctr.Image
-			tags.AddLow("docker_image", image)
-			imageSplit := strings.Split(image, ":")
-			imageName := strings.Join(imageSplit[:len(imageSplit)-1], ":")
-			tags.AddLow("image_name", imageName)
-			if len(imageSplit) > 1 {
-				imageTag := imageSplit[len(imageSplit)-1]
-				tags.AddLow("image_tag", imageTag)
-			}
-
-			// container labels
-			for k, v := range ctr.Labels {
-				if isBlacklisted[k] {
-					tags.AddHigh(k, v)
-				}
-			}
-
-			low, high := tags.Compute()
-			info := &TagInfo{
-				Source:       ecsFargateCollectorName,
-				Entity:       docker.ContainerIDToEntityName(string(ctr.DockerID)),
-				HighCardTags: high,
-				LowCardTags:  low,
-			}
-			output = append(output, info)
+			output = append(output, c.extractTags(meta, ctr))
 		}
 	}
 
@@ -93,51 +56,60 @@ func (c *ECSFargateCollector) pullMetadata(meta ecs.TaskMetadata) ([]*TagInfo, [
 }
 
 // fetchMetadata looks for a given container in a TaskMetadata object and returns its tags if found.
-func (c *ECSFargateCollector) fetchMetadata(meta ecs.TaskMetadata, container string) ([]string, []string, error) {
+func (c *ECSFargateCollector) fetchMetadata(meta ecs.TaskMetadata, container string) ([]string, []string, []string, error) {
 	for _, ctr := range meta.Containers {
 		entity := docker.ContainerIDToEntityName(string(ctr.DockerID))
 		if entity != container {
 			continue
 		}
-		tags := utils.NewTagList()
-
-		// cluster
-		tags.AddLow("cluster_name", meta.ClusterName)
-
-		// task
-		tags.AddLow("task_family", meta.Family)
-		tags.AddLow("task_version", meta.Version)
-
-		// container
-		tags.AddLow("ecs_container_name", ctr.Name)
-		tags.AddHigh("container_name", ctr.DockerName)
-
-		// container image
-		image := ctr.Image
-		tags.AddLow("docker_image", image)
-		imageSplit := strings.Split(image, ":")
-		imageName := strings.Join(imageSplit[:len(imageSplit)-1], ":")
-		tags.AddLow("image_name", imageName)
-		if len(imageSplit) > 1 {
-			imageTag := imageSplit[len(imageSplit)-1]
-			tags.AddLow("image_tag", imageTag)
-		}
+		info := c.extractTags(meta, ctr)
+		return info.LowCardTags, info.OrchestratorCardTags, info.HighCardTags, nil
+	}
+	return nil, nil, nil, errors.NewNotFound(fmt.Sprintf("%s/%s", meta.TaskARN, container))
+}
 
-		// container labels
-		for k, v := range ctr.Labels {
-			if isBlacklisted[k] {
-				tags.AddHigh(k, v)
-			}
-		}
+// extractTags builds the TagInfo of a container from the task metadata
+func (c *ECSFargateCollector) extractTags(meta ecs.TaskMetadata, ctr ecs.Container) *TagInfo {
+	tags := utils.NewTagList()
+
+	// cluster
+	tags.AddLow("cluster_name", meta.ClusterName)
+
+	// task
+	tags.AddLow("task_family", meta.Family)
+	tags.AddLow("task_version", meta.Version)
+	tags.AddOrchestrator("task_arn", meta.TaskARN)
+
+	// container
+	tags.AddLow("ecs_container_name", ctr.Name)
+	tags.AddHigh("container_name", ctr.DockerName)
+	tags.AddHigh("container_id", ctr.DockerID)
+
+	// container image
+	image := ctr.Image
+	tags.AddLow("docker_image", image)
+	imageSplit := strings.Split(image, ":")
+	imageName := strings.Join(imageSplit[:len(imageSplit)-1], ":")
+	tags.AddLow("image_name", imageName)
+	if len(imageSplit) > 1 {
+		imageTag := imageSplit[len(imageSplit)-1]
+		tags.AddLow("image_tag", imageTag)
+	}
 
-		low, high := tags.Compute()
-		info := &TagInfo{
-			Source:       ecsFargateCollectorName,
-			Entity:       docker.ContainerIDToEntityName(string(ctr.DockerID)),
-			HighCardTags: high,
-			LowCardTags:  low,
+	// container labels, as configured in docker_labels_as_tags
+	for k, v := range ctr.Labels {
+		if isBlacklisted[k] {
+			continue
 		}
-		return info.LowCardTags, info.HighCardTags, nil
+		c.labelsAsTags.AddTags(k, v, tags)
+	}
+
+	low, orchestrator, high := tags.Compute()
+	return &TagInfo{
+		Source:               ecsFargateCollectorName,
+		Entity:               docker.ContainerIDToEntityName(string(ctr.DockerID)),
+		HighCardTags:         high,
+		OrchestratorCardTags: orchestrator,
+		LowCardTags:          low,
 	}
-	return nil, nil, errors.NewNotFound(fmt.Sprintf("%s/%s", meta.TaskARN, container))
 }
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build docker
+
+package collectors
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	taggerutil "github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	ecsutil "github.com/DataDog/datadog-agent/pkg/util/ecs"
+)
+
+func TestECSFargatePullMetadata(t *testing.T) {
+	collector := &ECSFargateCollector{
+		lastSeen: map[string]interface{}{
+			"3827da9d51f12276b4ed2d2a2dfb624b96b239b20d052b859e26c13853071e7c": nil,
+		},
+		labelsAsTags: taggerutil.NewMetadataAsTags(map[string]string{
+			"highlabel":  "+high",
+			"team-label": "team",
+		}),
+	}
+
+	meta := ecsutil.TaskMetadata{
+		ClusterName: "default",
+		TaskARN:     "arn:aws:ecs:eu-central-1:601427279990:task/5308d232-9002-4224-97b5-e1d4843b5244",
+		Family:      "redis-datadog",
+		Version:     "3",
+		KnownStatus: "RUNNING",
+		Containers: []ecsutil.Container{
+			{
+				Name:       "redis",
+				DockerID:   "b4e9d3ab1f3e90b26ef465b5e33e2fc0cacc4d5f1a9f1a3e2ae1a4a3c7b1f2e4",
+				DockerName: "ecs-redis-datadog-3-redis-c2b5fac7dcd9f3e1c501",
+				Image:      "redis:latest",
+				Labels: map[string]string{
+					"com.amazonaws.ecs.cluster":                "default",
+					"com.amazonaws.ecs.task-definition-family": "redis-datadog",
+					"highlabel":  "world",
+					"Team-Label": "Ops",
+					"ignored":    "value",
+				},
+			},
+		},
+	}
+
+	updates, dead, err := collector.pullMetadata(meta)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3827da9d51f12276b4ed2d2a2dfb624b96b239b20d052b859e26c13853071e7c"}, dead)
+	require.Len(t, updates, 1)
+
+	expected := []*TagInfo{
+		{
+			Source: "ecs_fargate",
+			Entity: "docker://b4e9d3ab1f3e90b26ef465b5e33e2fc0cacc4d5f1a9f1a3e2ae1a4a3c7b1f2e4",
+			LowCardTags: []string{
+				"cluster_name:default",
+				"task_family:redis-datadog",
+				"task_version:3",
+				"ecs_container_name:redis",
+				"docker_image:redis:latest",
+				"image_name:redis",
+				"image_tag:latest",
+				"team:Ops",
+			},
+			OrchestratorCardTags: []string{
+				"task_arn:arn:aws:ecs:eu-central-1:601427279990:task/5308d232-9002-4224-97b5-e1d4843b5244",
+			},
+			HighCardTags: []string{
+				"container_name:ecs-redis-datadog-3-redis-c2b5fac7dcd9f3e1c501",
+				"container_id:b4e9d3ab1f3e90b26ef465b5e33e2fc0cacc4d5f1a9f1a3e2ae1a4a3c7b1f2e4",
+				"high:world",
+			},
+		},
+	}
+	require.True(t, requireMatchInfo(t, expected, updates[0]))
+
+	// Already seen containers are not sent again
+	updates, dead, err = collector.pullMetadata(meta)
+	require.NoError(t, err)
+	assert.Len(t, updates, 0)
+	assert.Len(t, dead, 0)
+
+	// Fetch still returns the tags of a known container
+	low, orchestrator, high, err := collector.fetchMetadata(meta, "docker://b4e9d3ab1f3e90b26ef465b5e33e2fc0cacc4d5f1a9f1a3e2ae1a4a3c7b1f2e4")
+	require.NoError(t, err)
+	assert.Len(t, low, 8)
+	assert.Len(t, orchestrator, 1)
+	assert.Len(t, high, 3)
+
+	_, _, _, err = collector.fetchMetadata(meta, "docker://unknown")
+	assert.Error(t, err)
+
+	// Stopped tasks are skipped
+	meta.KnownStatus = "STOPPED"
+	_, _, err = collector.pullMetadata(meta)
+	assert.Error(t, err)
+}
+
+func TestECSFargateDeadContainersAccumulated(t *testing.T) {
+	out := make(chan []*TagInfo, 1)
+	collector := &ECSFargateCollector{
+		infoOut:        out,
+		lastSeen:       map[string]interface{}{"alive": nil},
+		deadContainers: make(map[string]struct{}),
+	}
+
+	// the dead containers of throttled pulls are kept until the next expiry
+	collector.addDeadContainers([]string{"dead1"})
+	collector.addDeadContainers([]string{"dead2", "alive"})
+	require.NoError(t, collector.expireDeadContainers())
+
+	expiries := <-out
+	var entities []string
+	for _, info := range expiries {
+		assert.True(t, info.DeleteEntity)
+		entities = append(entities, info.Entity)
+	}
+	assert.ElementsMatch(t, []string{"docker://dead1", "docker://dead2"}, entities)
+	assert.Len(t, collector.deadContainers, 0)
+}
+
+// TestECSFargateConcurrentRefresh must be run with -race, Pull and Fetch refresh
+// the collector concurrently from the tagger.
+func TestECSFargateConcurrentRefresh(t *testing.T) {
+	out := make(chan []*TagInfo, 100)
+	collector := &ECSFargateCollector{
+		infoOut:        out,
+		lastSeen:       make(map[string]interface{}),
+		deadContainers: make(map[string]struct{}),
+		expireFreq:     ecsFargateExpireFreq,
+		labelsAsTags:   taggerutil.NewMetadataAsTags(map[string]string{}),
+	}
+	go func() {
+		for range out {
+		}
+	}()
+	defer close(out)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			meta := ecsutil.TaskMetadata{
+				KnownStatus: "RUNNING",
+				Containers:  []ecsutil.Container{{DockerID: fmt.Sprintf("container-%d", i%3)}},
+			}
+			// even goroutines pull, odd ones fetch
+			assert.NoError(t, collector.refresh(meta, i%2 == 1))
+		}(i)
+	}
+	wg.Wait()
+}
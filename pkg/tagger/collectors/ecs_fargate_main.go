@@ -9,8 +9,10 @@ package collectors
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	taggerutil "github.com/DataDog/datadog-agent/pkg/tagger/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	ecsutil "github.com/DataDog/datadog-agent/pkg/util/ecs"
@@ -21,86 +23,109 @@ const (
 	ecsFargateExpireFreq    = 5 * time.Minute
 )
 
-// ECSFargateCollector polls the ecs metadata api.
+// ECSFargateCollector polls the ecs metadata api. As the docker socket is not
+// available on Fargate, it also handles container labels and deletions.
 type ECSFargateCollector struct {
 	infoOut    chan<- []*TagInfo
-	expire     *taggerutil.Expire
 	lastExpire time.Time
 	lastSeen   map[string]interface{}
-	expireFreq time.Duration
+	// deadContainers are the containers that disappeared since the last expiry
+	deadContainers map[string]struct{}
+	expireFreq     time.Duration
+	labelsAsTags   *taggerutil.MetadataAsTags
+	// m guards lastSeen, deadContainers and lastExpire, Pull and Fetch run concurrently
+	m sync.Mutex
 }
 
 // Detect tries to connect to the ECS metadata API
 func (c *ECSFargateCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error) {
-	var err error
-
-	if ecsutil.IsFargateInstance() {
-		c.infoOut = out
-		c.lastExpire = time.Now()
-		c.expireFreq = ecsFargateExpireFreq
-
-		c.expire, err = taggerutil.NewExpire(ecsFargateExpireFreq)
-
-		if err != nil {
-			return FetchOnlyCollection, fmt.Errorf("Failed to instantiate the container expiring process")
-		}
-		return FetchOnlyCollection, nil
+	if !ecsutil.IsFargateInstance() {
+		return NoCollection, fmt.Errorf("Failed to connect to task metadata API, ECS tagging will not work")
 	}
 
-	return NoCollection, fmt.Errorf("Failed to connect to task metadata API, ECS tagging will not work")
+	c.infoOut = out
+	c.lastExpire = time.Now()
+	c.expireFreq = ecsFargateExpireFreq
+	c.lastSeen = make(map[string]interface{})
+	c.deadContainers = make(map[string]struct{})
+	c.labelsAsTags = taggerutil.NewMetadataAsTags(config.Datadog.GetStringMapString("docker_labels_as_tags"))
+
+	return PullCollection, nil
 }
 
 // Pull triggers a container-list refresh and sends new info. It also triggers
 // container deletion computation every 'expireFreq'
 func (c *ECSFargateCollector) Pull() error {
-	// Compute new/updated containers
 	meta, err := ecsutil.GetTaskMetadata()
 	if err != nil {
 		return err
 	}
+	return c.refresh(meta, false)
+}
+
+// Fetch fetches ECS tags for a container on demand
+func (c *ECSFargateCollector) Fetch(container string) ([]string, []string, []string, error) {
+	meta, err := ecsutil.GetTaskMetadata()
+	if err != nil {
+		return []string{}, []string{}, []string{}, err
+	}
+
+	// since we download the metadata anyway might as well do a Pull refresh
+	if err := c.refresh(meta, true); err != nil {
+		return []string{}, []string{}, []string{}, err
+	}
+
+	return c.fetchMetadata(meta, container)
+}
+
+// refresh sends the tags of the new containers of the task metadata, and the
+// deletion of the dead ones every 'expireFreq', or right away if forceExpire.
+func (c *ECSFargateCollector) refresh(meta ecsutil.TaskMetadata, forceExpire bool) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	// Compute new/updated containers
 	updates, deadCo, err := c.pullMetadata(meta)
 	if err != nil {
 		return err
 	}
 	c.infoOut <- updates
+	c.addDeadContainers(deadCo)
 
 	// Throttle deletion computations
-	if time.Now().Sub(c.lastExpire) < c.expireFreq {
+	if !forceExpire && time.Now().Sub(c.lastExpire) < c.expireFreq {
 		return nil
 	}
 
-	expiries, err := c.parseExpires(deadCo)
-	if err != nil {
-		return err
-	}
-	c.infoOut <- expiries
-	c.lastExpire = time.Now()
-	return nil
+	return c.expireDeadContainers()
 }
 
-// Fetch fetches ECS tags for a container on demand
-func (c *ECSFargateCollector) Fetch(container string) ([]string, []string, error) {
-	meta, err := ecsutil.GetTaskMetadata()
-	if err != nil {
-		return []string{}, []string{}, err
+// addDeadContainers keeps the containers that disappeared until the next expiry,
+// the containers seen again are not expired. It must be called with c.m held.
+func (c *ECSFargateCollector) addDeadContainers(idList []string) {
+	for _, id := range idList {
+		c.deadContainers[id] = struct{}{}
 	}
-
-	// since we download the metadata anyway might as well do a Pull refresh
-	updates, deadCo, err := c.pullMetadata(meta)
-	if err != nil {
-		return []string{}, []string{}, err
+	for id := range c.lastSeen {
+		delete(c.deadContainers, id)
 	}
+}
 
-	c.infoOut <- updates
-
-	expiries, err := c.parseExpires(deadCo)
+// expireDeadContainers sends the deletion of the containers that disappeared
+// since the last expiry. It must be called with c.m held.
+func (c *ECSFargateCollector) expireDeadContainers() error {
+	idList := make([]string, 0, len(c.deadContainers))
+	for id := range c.deadContainers {
+		idList = append(idList, id)
+	}
+	expiries, err := c.parseExpires(idList)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 	c.infoOut <- expiries
+	c.deadContainers = make(map[string]struct{})
 	c.lastExpire = time.Now()
-
-	return c.fetchMetadata(meta, container)
+	return nil
 }
 
 // parseExpires transforms event from the PodWatcher to TagInfo objects
@@ -117,10 +142,15 @@ func (c *ECSFargateCollector) parseExpires(idList []string) ([]*TagInfo, error)
 	return output, nil
 }
 
+// EntityPrefixes returns the prefixes of the entities the collector knows about
+func (c *ECSFargateCollector) EntityPrefixes() []string {
+	return []string{docker.DockerEntityPrefix}
+}
+
 func ecsFargateFactory() Collector {
 	return &ECSFargateCollector{}
 }
 
 func init() {
-	registerCollector(ecsFargateCollectorName, ecsFargateFactory, NodeOrchestrator)
+	RegisterCollector(ecsFargateCollectorName, ecsFargateFactory, NodeOrchestrator)
 }
@@ -12,6 +12,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/errors"
 	taggerutil "github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/ecs"
 )
 
@@ -50,14 +51,14 @@ func (c *ECSCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error) {
 }
 
 // Fetch fetches ECS tags
-func (c *ECSCollector) Fetch(container string) ([]string, []string, error) {
+func (c *ECSCollector) Fetch(container string) ([]string, []string, []string, error) {
 	tasks_list, err := c.ecsUtil.GetTasks()
 	if err != nil {
-		return []string{}, []string{}, err
+		return []string{}, []string{}, []string{}, err
 	}
 	updates, err := c.parseTasks(tasks_list)
 	if err != nil {
-		return []string{}, []string{}, err
+		return []string{}, []string{}, []string{}, err
 	}
 	c.infoOut <- updates
 
@@ -71,11 +72,16 @@ func (c *ECSCollector) Fetch(container string) ([]string, []string, error) {
 
 	for _, info := range updates {
 		if info.Entity == container {
-			return info.LowCardTags, info.HighCardTags, nil
+			return info.LowCardTags, info.OrchestratorCardTags, info.HighCardTags, nil
 		}
 	}
 	// container not found in updates
-	return []string{}, []string{}, errors.NewNotFound(container)
+	return []string{}, []string{}, []string{}, errors.NewNotFound(container)
+}
+
+// EntityPrefixes returns the prefixes of the entities the collector knows about
+func (c *ECSCollector) EntityPrefixes() []string {
+	return []string{docker.DockerEntityPrefix}
 }
 
 func ecsFactory() Collector {
@@ -83,5 +89,5 @@ func ecsFactory() Collector {
 }
 
 func init() {
-	registerCollector(ecsCollectorName, ecsFactory, NodeRuntime)
+	RegisterCollector(ecsCollectorName, ecsFactory, NodeRuntime)
 }
@@ -14,6 +14,7 @@ import (
 	log "github.com/cihub/seelog"
 
 	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/clustername"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
@@ -33,21 +34,20 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 		tags := utils.NewTagList()
 
 		// Pod name
-		tags.AddHigh("pod_name", pod.Metadata.Name)
+		tags.AddOrchestrator("pod_name", pod.Metadata.Name)
 		tags.AddLow("kube_namespace", pod.Metadata.Namespace)
+		if c.clusterName != "" {
+			tags.AddLow(clustername.TagName, c.clusterName)
+		}
 
 		// Pod labels
 		for name, value := range pod.Metadata.Labels {
-			if tagName, found := c.labelsAsTags[strings.ToLower(name)]; found {
-				tags.AddAuto(tagName, value)
-			}
+			c.labelsAsTags.AddTags(name, value, tags)
 		}
 
 		// Pod annotations
 		for name, value := range pod.Metadata.Annotations {
-			if tagName, found := c.annotationsAsTags[strings.ToLower(name)]; found {
-				tags.AddAuto(tagName, value)
-			}
+			c.annotationsAsTags.AddTags(name, value, tags)
 		}
 
 		// OpenShift pod annotations
@@ -55,7 +55,10 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 			tags.AddLow("oshift_deployment_config", dc_name)
 		}
 		if deploy_name, found := pod.Metadata.Annotations["openshift.io/deployment.name"]; found {
-			tags.AddHigh("oshift_deployment", deploy_name)
+			tags.AddOrchestrator("oshift_deployment", deploy_name)
+		}
+		if scc, found := pod.Metadata.Annotations["openshift.io/scc"]; found {
+			tags.AddLow("oshift_scc", scc)
 		}
 
 		// Creator
@@ -72,11 +75,11 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 			case "StatefulSet":
 				tags.AddLow("kube_stateful_set", owner.Name)
 			case "Job":
-				tags.AddHigh("kube_job", owner.Name) // TODO detect if no from cronjob, then low card
+				tags.AddOrchestrator("kube_job", owner.Name) // TODO detect if no from cronjob, then low card
 			case "ReplicaSet":
 				deployment := c.parseDeploymentForReplicaset(owner.Name)
 				if len(deployment) > 0 {
-					tags.AddHigh("kube_replica_set", owner.Name)
+					tags.AddOrchestrator("kube_replica_set", owner.Name)
 					tags.AddLow("kube_deployment", deployment)
 				} else {
 					tags.AddLow("kube_replica_set", owner.Name)
@@ -86,13 +89,14 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 			}
 		}
 
-		low, high := tags.Compute()
+		low, orchestrator, high := tags.Compute()
 		if pod.Metadata.UID != "" {
 			podInfo := &TagInfo{
-				Source:       kubeletCollectorName,
-				Entity:       kubelet.PodUIDToEntityName(pod.Metadata.UID),
-				HighCardTags: high,
-				LowCardTags:  low,
+				Source:               kubeletCollectorName,
+				Entity:               kubelet.PodUIDToEntityName(pod.Metadata.UID),
+				HighCardTags:         high,
+				OrchestratorCardTags: orchestrator,
+				LowCardTags:          append(low, c.extractStaticTags("", pod)...),
 			}
 			output = append(output, podInfo)
 		}
@@ -100,9 +104,11 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 		// container tags
 		for _, container := range pod.Status.Containers {
 			lowC := append(low, fmt.Sprintf("kube_container_name:%s", container.Name))
+			image := ""
 			// check image tag in spec
 			for _, containerSpec := range pod.Spec.Containers {
 				if containerSpec.Name == container.Name {
+					image = containerSpec.Image
 					imageName, shortImage, imageTag, err := docker.SplitImageName(containerSpec.Image)
 					if err != nil {
 						log.Debugf("Cannot split %s: %s", containerSpec.Image, err)
@@ -118,12 +124,14 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 					break
 				}
 			}
+			lowC = append(lowC, c.extractStaticTags(image, pod)...)
 
 			info := &TagInfo{
-				Source:       kubeletCollectorName,
-				Entity:       container.ID,
-				HighCardTags: high,
-				LowCardTags:  lowC,
+				Source:               kubeletCollectorName,
+				Entity:               container.ID,
+				HighCardTags:         high,
+				OrchestratorCardTags: orchestrator,
+				LowCardTags:          lowC,
 			}
 			output = append(output, info)
 		}
@@ -131,6 +139,15 @@ func (c *KubeletCollector) parsePods(pods []*kubelet.Pod) ([]*TagInfo, error) {
 	return output, nil
 }
 
+// extractStaticTags returns the tags of the `tagger_static_tags` rules matching
+// the pod, and the container image if not empty
+func (c *KubeletCollector) extractStaticTags(image string, pod *kubelet.Pod) []string {
+	tags := utils.NewTagList()
+	c.staticTags.AddTags(image, pod.Metadata.Namespace, pod.Metadata.Labels, tags)
+	low, _, _ := tags.Compute()
+	return low
+}
+
 // parseDeploymentForReplicaset gets the deployment name from a replicaset,
 // or returns an empty string if no parent deployment is found.
 func (c *KubeletCollector) parseDeploymentForReplicaset(name string) string {
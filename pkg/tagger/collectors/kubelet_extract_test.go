@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
 
@@ -60,6 +61,8 @@ func TestParsePods(t *testing.T) {
 		pod               *kubelet.Pod
 		labelsAsTags      map[string]string
 		annotationsAsTags map[string]string
+		staticTags        []utils.StaticTagsRule
+		clusterName       string
 		expectedInfo      *TagInfo
 	}{
 		{
@@ -97,7 +100,34 @@ func TestParsePods(t *testing.T) {
 					"image_name:datadog/docker-dd-agent",
 					"short_image:docker-dd-agent",
 				},
-				HighCardTags: []string{"pod_name:dd-agent-rc-qd876"},
+				OrchestratorCardTags: []string{"pod_name:dd-agent-rc-qd876"},
+				HighCardTags:         []string{},
+			},
+		},
+		{
+			desc: "cluster name",
+			pod: &kubelet.Pod{
+				Metadata: kubelet.PodMetadata{
+					Name:      "dd-agent-rc-qd876",
+					Namespace: "default",
+				},
+				Status: dockerContainerStatus,
+				Spec:   dockerContainerSpec,
+			},
+			clusterName: "prod-eu",
+			expectedInfo: &TagInfo{
+				Source: "kubelet",
+				Entity: dockerEntityID,
+				LowCardTags: []string{
+					"kube_namespace:default",
+					"kube_cluster_name:prod-eu",
+					"kube_container_name:dd-agent",
+					"image_tag:latest5",
+					"image_name:datadog/docker-dd-agent",
+					"short_image:docker-dd-agent",
+				},
+				OrchestratorCardTags: []string{"pod_name:dd-agent-rc-qd876"},
+				HighCardTags:         []string{},
 			},
 		},
 		{
@@ -125,7 +155,8 @@ func TestParsePods(t *testing.T) {
 					"image_name:datadog/docker-dd-agent",
 					"short_image:docker-dd-agent",
 				},
-				HighCardTags: []string{},
+				OrchestratorCardTags: []string{},
+				HighCardTags:         []string{},
 			},
 		},
 		{
@@ -153,7 +184,8 @@ func TestParsePods(t *testing.T) {
 					"image_name:datadog/docker-dd-agent",
 					"short_image:docker-dd-agent",
 				},
-				HighCardTags: []string{"kube_replica_set:frontend-2891696001"},
+				OrchestratorCardTags: []string{"kube_replica_set:frontend-2891696001"},
+				HighCardTags:         []string{},
 			},
 		},
 		{
@@ -181,7 +213,8 @@ func TestParsePods(t *testing.T) {
 					"image_name:datadog/docker-dd-agent",
 					"short_image:docker-dd-agent",
 				},
-				HighCardTags: []string{"kube_replica_set:front-end-768dd754b7"},
+				OrchestratorCardTags: []string{"kube_replica_set:front-end-768dd754b7"},
+				HighCardTags:         []string{},
 			},
 		},
 		{
@@ -218,7 +251,8 @@ func TestParsePods(t *testing.T) {
 					"image_name:datadog/docker-dd-agent",
 					"short_image:docker-dd-agent",
 				},
-				HighCardTags: []string{"GitCommit:ea38b55f07e40b68177111a2bff1e918132fd5fb"},
+				OrchestratorCardTags: []string{},
+				HighCardTags:         []string{"GitCommit:ea38b55f07e40b68177111a2bff1e918132fd5fb"},
 			},
 		},
 		{
@@ -260,7 +294,48 @@ func TestParsePods(t *testing.T) {
 					"image_name:datadog/docker-dd-agent",
 					"short_image:docker-dd-agent",
 				},
-				HighCardTags: []string{"GitCommit:ea38b55f07e40b68177111a2bff1e918132fd5fb"},
+				OrchestratorCardTags: []string{},
+				HighCardTags:         []string{"GitCommit:ea38b55f07e40b68177111a2bff1e918132fd5fb"},
+			},
+		},
+		{
+			desc: "pod labels + annotations globs",
+			pod: &kubelet.Pod{
+				Metadata: kubelet.PodMetadata{
+					Labels: map[string]string{
+						"app.kubernetes.io/name":    "dd-agent",
+						"app.kubernetes.io/version": "6.2.0",
+						"tier":                      "node",
+					},
+					Annotations: map[string]string{
+						"ad.datadoghq.com/dd-agent.check_names": "[\"redisdb\"]",
+						"team.example.com/owner":                "Kenafeh",
+					},
+				},
+				Status: dockerContainerStatus,
+				Spec:   dockerContainerSpec,
+			},
+			labelsAsTags: map[string]string{
+				"app.kubernetes.io/*": "%%label%%",
+				"tier":                "tier",
+			},
+			annotationsAsTags: map[string]string{
+				"team.example.com/*": "+team",
+			},
+			expectedInfo: &TagInfo{
+				Source: "kubelet",
+				Entity: dockerEntityID,
+				LowCardTags: []string{
+					"kube_container_name:dd-agent",
+					"app.kubernetes.io/name:dd-agent",
+					"app.kubernetes.io/version:6.2.0",
+					"tier:node",
+					"image_tag:latest5",
+					"image_name:datadog/docker-dd-agent",
+					"short_image:docker-dd-agent",
+				},
+				OrchestratorCardTags: []string{},
+				HighCardTags:         []string{"team:Kenafeh"},
 			},
 		},
 		{
@@ -271,16 +346,18 @@ func TestParsePods(t *testing.T) {
 						"openshift.io/deployment-config.latest-version": "1",
 						"openshift.io/deployment-config.name":           "gitlab-ce",
 						"openshift.io/deployment.name":                  "gitlab-ce-1",
+						"openshift.io/scc":                              "restricted",
 					},
 				},
 				Status: dockerContainerStatus,
 			},
 			labelsAsTags: map[string]string{},
 			expectedInfo: &TagInfo{
-				Source:       "kubelet",
-				Entity:       dockerEntityID,
-				LowCardTags:  []string{"kube_container_name:dd-agent", "oshift_deployment_config:gitlab-ce"},
-				HighCardTags: []string{"oshift_deployment:gitlab-ce-1"},
+				Source:               "kubelet",
+				Entity:               dockerEntityID,
+				LowCardTags:          []string{"kube_container_name:dd-agent", "oshift_deployment_config:gitlab-ce", "oshift_scc:restricted"},
+				OrchestratorCardTags: []string{"oshift_deployment:gitlab-ce-1"},
+				HighCardTags:         []string{},
 			},
 		},
 		{
@@ -308,14 +385,61 @@ func TestParsePods(t *testing.T) {
 					"image_tag:e2e",
 					"short_image:redis",
 				},
-				HighCardTags: []string{"kube_replica_set:redis-master-546dc4865f"},
+				OrchestratorCardTags: []string{"kube_replica_set:redis-master-546dc4865f"},
+				HighCardTags:         []string{},
+			},
+		},
+		{
+			desc: "static tags",
+			pod: &kubelet.Pod{
+				Metadata: kubelet.PodMetadata{
+					Namespace: "infra",
+					Labels: map[string]string{
+						"tier": "node",
+					},
+				},
+				Status: dockerContainerStatus,
+				Spec:   dockerContainerSpec,
+			},
+			labelsAsTags: map[string]string{},
+			staticTags: []utils.StaticTagsRule{
+				{
+					Image: "datadog/*",
+					Tags:  []string{"team:monitoring"},
+				},
+				{
+					Namespace: "infra",
+					Labels:    map[string]string{"tier": "node"},
+					Tags:      []string{"owner:platform"},
+				},
+				{
+					Namespace: "default",
+					Tags:      []string{"owner:nobody"},
+				},
+			},
+			expectedInfo: &TagInfo{
+				Source: "kubelet",
+				Entity: dockerEntityID,
+				LowCardTags: []string{
+					"kube_namespace:infra",
+					"kube_container_name:dd-agent",
+					"image_tag:latest5",
+					"image_name:datadog/docker-dd-agent",
+					"short_image:docker-dd-agent",
+					"team:monitoring",
+					"owner:platform",
+				},
+				OrchestratorCardTags: []string{},
+				HighCardTags:         []string{},
 			},
 		},
 	} {
 		t.Run(fmt.Sprintf("case %d: %s", nb, tc.desc), func(t *testing.T) {
 			collector := &KubeletCollector{
-				labelsAsTags:      tc.labelsAsTags,
-				annotationsAsTags: tc.annotationsAsTags,
+				labelsAsTags:      utils.NewMetadataAsTags(tc.labelsAsTags),
+				annotationsAsTags: utils.NewMetadataAsTags(tc.annotationsAsTags),
+				staticTags:        utils.NewStaticTags(tc.staticTags),
+				clusterName:       tc.clusterName,
 			}
 			infos, err := collector.parsePods([]*kubelet.Pod{tc.pod})
 			assert.Nil(t, err)
@@ -8,11 +8,12 @@
 package collectors
 
 import (
-	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/clustername"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
 
@@ -29,8 +30,10 @@ type KubeletCollector struct {
 	infoOut           chan<- []*TagInfo
 	lastExpire        time.Time
 	expireFreq        time.Duration
-	labelsAsTags      map[string]string
-	annotationsAsTags map[string]string
+	labelsAsTags      *utils.MetadataAsTags
+	annotationsAsTags *utils.MetadataAsTags
+	staticTags        *utils.StaticTags
+	clusterName       string
 }
 
 // Detect tries to connect to the kubelet
@@ -44,20 +47,11 @@ func (c *KubeletCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error)
 	c.lastExpire = time.Now()
 	c.expireFreq = kubeletExpireFreq
 
-	// We lower-case the values collected by viper as well as the ones from inspecting the labels of containers.
-	labelsList := config.Datadog.GetStringMapString("kubernetes_pod_labels_as_tags")
-	for label, value := range labelsList {
-		delete(labelsList, label)
-		labelsList[strings.ToLower(label)] = value
-	}
-	c.labelsAsTags = labelsList
-
-	annotationsList := config.Datadog.GetStringMapString("kubernetes_pod_annotations_as_tags")
-	for annotation, value := range annotationsList {
-		delete(annotationsList, annotation)
-		annotationsList[strings.ToLower(annotation)] = value
-	}
-	c.annotationsAsTags = annotationsList
+	// Label and annotation names are matched case-insensitively, and can be globs
+	c.labelsAsTags = utils.NewMetadataAsTags(config.Datadog.GetStringMapString("kubernetes_pod_labels_as_tags"))
+	c.annotationsAsTags = utils.NewMetadataAsTags(config.Datadog.GetStringMapString("kubernetes_pod_annotations_as_tags"))
+	c.staticTags = staticTagsFromConfig()
+	c.clusterName = clustername.GetClusterName()
 	return PullCollection, nil
 }
 
@@ -97,26 +91,26 @@ func (c *KubeletCollector) Pull() error {
 
 // Fetch fetches tags for a given entity by iterating on the whole podlist
 // TODO: optimize if called too often on production
-func (c *KubeletCollector) Fetch(entity string) ([]string, []string, error) {
+func (c *KubeletCollector) Fetch(entity string) ([]string, []string, []string, error) {
 	pod, err := c.watcher.GetPodForEntityID(entity)
 	if err != nil {
-		return []string{}, []string{}, err
+		return []string{}, []string{}, []string{}, err
 	}
 
 	pods := []*kubelet.Pod{pod}
 	updates, err := c.parsePods(pods)
 	if err != nil {
-		return []string{}, []string{}, err
+		return []string{}, []string{}, []string{}, err
 	}
 	c.infoOut <- updates
 
 	for _, info := range updates {
 		if info.Entity == entity {
-			return info.LowCardTags, info.HighCardTags, nil
+			return info.LowCardTags, info.OrchestratorCardTags, info.HighCardTags, nil
 		}
 	}
 	// entity not found in updates
-	return []string{}, []string{}, errors.NewNotFound(entity)
+	return []string{}, []string{}, []string{}, errors.NewNotFound(entity)
 }
 
 // parseExpires transforms event from the PodWatcher to TagInfo objects
@@ -138,5 +132,5 @@ func kubeletFactory() Collector {
 }
 
 func init() {
-	registerCollector(kubeletCollectorName, kubeletFactory, NodeOrchestrator)
+	RegisterCollector(kubeletCollectorName, kubeletFactory, NodeOrchestrator)
 }
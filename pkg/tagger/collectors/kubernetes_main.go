@@ -9,6 +9,8 @@ package collectors
 
 import (
 	"fmt"
+	"reflect"
+	"sync"
 	"time"
 
 	log "github.com/cihub/seelog"
@@ -22,6 +24,7 @@ import (
 
 const (
 	kubeMetadataCollectorName = "kube-metadata-collector"
+	tagStreamRetryDelay       = 30 * time.Second
 )
 
 type KubeMetadataCollector struct {
@@ -32,6 +35,11 @@ type KubeMetadataCollector struct {
 	// used to set a custom delay
 	lastUpdate time.Time
 	updateFreq time.Duration
+	// streamed is the last metadata pushed by the DCA on the tag stream of the
+	// node, nil if the DCA is queried for each pod instead
+	streamed     *clusteragent.NodeMetadata
+	streamedLock sync.RWMutex
+	stop         chan struct{}
 }
 
 // Detect tries to connect to the kubelet and the API Server if the DCA is not used or the DCA.
@@ -62,9 +70,60 @@ func (c *KubeMetadataCollector) Detect(out chan<- []*TagInfo) (CollectionMode, e
 
 	c.infoOut = out
 	c.updateFreq = time.Duration(config.Datadog.GetInt("kubernetes_metadata_tag_update_freq")) * time.Second
+	if c.dcaClient != nil && config.Datadog.GetBool("cluster_agent.tag_stream") {
+		c.stop = make(chan struct{})
+		go c.streamMetadata()
+	}
 	return PullCollection, nil
 }
 
+// streamMetadata subscribes to the tag stream of the node on the DCA, and
+// subscribes again when it is interrupted. The DCA is queried for each pod
+// while the stream is interrupted, and if the DCA does not serve it.
+func (c *KubeMetadataCollector) streamMetadata() {
+	for {
+		_, nodeName, err := c.kubeUtil.GetNodeInfo()
+		if err == nil {
+			err = c.dcaClient.StreamNodeMetadata(nodeName, c.handleStreamedMetadata, c.stop)
+		}
+		// the metadata is not updated anymore
+		c.streamedLock.Lock()
+		c.streamed = nil
+		c.streamedLock.Unlock()
+		if err == clusteragent.ErrNotFound {
+			log.Infof("The Datadog Cluster Agent does not serve the tag stream, querying it for each pod")
+			return
+		}
+		if err != nil {
+			log.Debugf("Could not stream the cluster level tags from the Datadog Cluster Agent, retrying in %s: %s", tagStreamRetryDelay, err)
+		}
+		select {
+		case <-c.stop:
+			return
+		case <-time.After(tagStreamRetryDelay):
+		}
+	}
+}
+
+// handleStreamedMetadata stores the metadata pushed by the DCA, and updates the
+// tags of the pods if it changed.
+func (c *KubeMetadataCollector) handleStreamedMetadata(metadata clusteragent.NodeMetadata) {
+	c.streamedLock.Lock()
+	changed := c.streamed == nil || !reflect.DeepEqual(*c.streamed, metadata)
+	c.streamed = &metadata
+	c.streamedLock.Unlock()
+	if !changed {
+		return
+	}
+
+	pods, err := c.kubeUtil.GetLocalPodList()
+	if err != nil {
+		log.Debugf("Could not update the cluster level tags of the pods: %s", err)
+		return
+	}
+	c.infoOut <- c.getTagInfos(pods)
+}
+
 // Pull implements an additional time constraints to avoid exhausting the kube-apiserver
 func (c *KubeMetadataCollector) Pull() error {
 	// Time constraints, get the delta in seconds to display it in the logs:
@@ -92,16 +151,16 @@ func (c *KubeMetadataCollector) Pull() error {
 
 // Fetch fetches tags for a given entity by iterating on the whole podlist and
 // the metadataMapper
-func (c *KubeMetadataCollector) Fetch(entity string) ([]string, []string, error) {
-	var lowCards, highCards []string
+func (c *KubeMetadataCollector) Fetch(entity string) ([]string, []string, []string, error) {
+	var lowCards, orchestratorCards, highCards []string
 
 	pod, err := c.kubeUtil.GetPodForEntityID(entity)
 	if err != nil {
-		return lowCards, highCards, err
+		return lowCards, orchestratorCards, highCards, err
 	}
 
 	if kubelet.IsPodReady(pod) == false {
-		return lowCards, highCards, errors.NewNotFound(entity)
+		return lowCards, orchestratorCards, highCards, errors.NewNotFound(entity)
 	}
 
 	pods := []*kubelet.Pod{pod}
@@ -117,10 +176,10 @@ func (c *KubeMetadataCollector) Fetch(entity string) ([]string, []string, error)
 	c.infoOut <- tagInfos
 	for _, info := range tagInfos {
 		if info.Entity == entity {
-			return info.LowCardTags, info.HighCardTags, nil
+			return info.LowCardTags, info.OrchestratorCardTags, info.HighCardTags, nil
 		}
 	}
-	return lowCards, highCards, errors.NewNotFound(entity)
+	return lowCards, orchestratorCards, highCards, errors.NewNotFound(entity)
 }
 
 func kubernetesFactory() Collector {
@@ -128,5 +187,5 @@ func kubernetesFactory() Collector {
 }
 
 func init() {
-	registerCollector(kubeMetadataCollectorName, kubernetesFactory, ClusterOrchestrator)
+	RegisterCollector(kubeMetadataCollectorName, kubernetesFactory, ClusterOrchestrator)
 }
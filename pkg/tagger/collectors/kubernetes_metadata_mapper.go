@@ -8,6 +8,7 @@
 package collectors
 
 import (
+	"fmt"
 	"strings"
 
 	log "github.com/cihub/seelog"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
@@ -35,10 +37,11 @@ func (c *KubeMetadataCollector) getTagInfos(pods []*kubelet.Pod) []*TagInfo {
 		if po.Spec.HostNetwork == true {
 			for _, container := range po.Status.Containers {
 				info := &TagInfo{
-					Source:       kubeMetadataCollectorName,
-					Entity:       container.ID,
-					HighCardTags: []string{},
-					LowCardTags:  []string{},
+					Source:               kubeMetadataCollectorName,
+					Entity:               container.ID,
+					HighCardTags:         []string{},
+					OrchestratorCardTags: []string{},
+					LowCardTags:          []string{},
 				}
 				tagInfo = append(tagInfo, info)
 			}
@@ -53,7 +56,7 @@ func (c *KubeMetadataCollector) getTagInfos(pods []*kubelet.Pod) []*TagInfo {
 				continue
 			}
 		} else {
-			metadataNames, err = c.dcaClient.GetKubernetesMetadataNames(po.Spec.NodeName, po.Metadata.Name)
+			metadataNames, err = c.getMetadataNamesFromDCA(po)
 			if err != nil {
 				log.Tracef("Could not pull the metadata map of po %s on node %s from the Datadog Cluster Agent: %s", po.Metadata.Name, po.Spec.NodeName, err.Error())
 			}
@@ -67,13 +70,14 @@ func (c *KubeMetadataCollector) getTagInfos(pods []*kubelet.Pod) []*TagInfo {
 			tagList.AddLow(tag[0], tag[1])
 		}
 
-		low, high := tagList.Compute()
+		low, orchestrator, high := tagList.Compute()
 		for _, container := range po.Status.Containers {
 			info := &TagInfo{
-				Source:       kubeMetadataCollectorName,
-				Entity:       container.ID,
-				HighCardTags: high,
-				LowCardTags:  low,
+				Source:               kubeMetadataCollectorName,
+				Entity:               container.ID,
+				HighCardTags:         high,
+				OrchestratorCardTags: orchestrator,
+				LowCardTags:          low,
 			}
 			tagInfo = append(tagInfo, info)
 		}
@@ -81,6 +85,36 @@ func (c *KubeMetadataCollector) getTagInfos(pods []*kubelet.Pod) []*TagInfo {
 	return tagInfo
 }
 
+// getMetadataNamesFromDCA returns the cluster level tags of a pod from the services
+// selecting it, as pushed on the tag stream of the node, queried if the stream is
+// not used, falling back to the metadata map of its node if the DCA is older.
+func (c *KubeMetadataCollector) getMetadataNamesFromDCA(po *kubelet.Pod) ([]string, error) {
+	c.streamedLock.RLock()
+	streamed := c.streamed
+	c.streamedLock.RUnlock()
+	if streamed != nil {
+		services := streamed.PodServices[fmt.Sprintf("%s/%s", po.Metadata.Namespace, po.Metadata.Name)]
+		metadataNames := make([]string, 0, len(services)+len(streamed.ClusterTags))
+		for _, service := range services {
+			metadataNames = append(metadataNames, fmt.Sprintf("kube_service:%s", service))
+		}
+		return append(metadataNames, streamed.ClusterTags...), nil
+	}
+
+	services, err := c.dcaClient.GetPodServices(po.Metadata.Namespace, po.Metadata.Name)
+	if err == clusteragent.ErrNotFound {
+		return c.dcaClient.GetKubernetesMetadataNames(po.Spec.NodeName, po.Metadata.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	metadataNames := make([]string, 0, len(services))
+	for _, service := range services {
+		metadataNames = append(metadataNames, fmt.Sprintf("kube_service:%s", service))
+	}
+	return metadataNames, nil
+}
+
 // addToCacheMetadataMapping is acting like the DCA at the node level.
 func (c *KubeMetadataCollector) addToCacheMetadataMapping(kubeletPodList []*kubelet.Pod) error {
 	if len(kubeletPodList) == 0 {
@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver,kubelet
+
+package collectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+func TestGetStreamedMetadataNames(t *testing.T) {
+	// the DCA client is not used when the metadata is streamed
+	c := &KubeMetadataCollector{
+		streamed: &clusteragent.NodeMetadata{
+			PodServices: map[string][]string{"default/web-1": {"frontend", "web"}},
+			ClusterTags: []string{"kube_cluster_name:prod"},
+		},
+	}
+
+	names, err := c.getMetadataNamesFromDCA(&kubelet.Pod{Metadata: kubelet.PodMetadata{Namespace: "default", Name: "web-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kube_service:frontend", "kube_service:web", "kube_cluster_name:prod"}, names)
+
+	names, err = c.getMetadataNamesFromDCA(&kubelet.Pod{Metadata: kubelet.PodMetadata{Namespace: "default", Name: "db-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kube_cluster_name:prod"}, names)
+}
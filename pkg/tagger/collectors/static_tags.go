@@ -0,0 +1,23 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package collectors
+
+import (
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+)
+
+// staticTagsFromConfig loads the rules of the `tagger_static_tags` option
+func staticTagsFromConfig() *utils.StaticTags {
+	var rules []utils.StaticTagsRule
+	if err := config.Datadog.UnmarshalKey("tagger_static_tags", &rules); err != nil {
+		log.Errorf("Cannot parse the tagger_static_tags option, ignoring it: %s", err)
+		return nil
+	}
+	return utils.NewStaticTags(rules)
+}
@@ -5,16 +5,33 @@
 
 package collectors
 
+import "time"
+
 // TagInfo holds the tag information for a given entity and source. It's meant
 // to be created from collectors and read by the store.
 type TagInfo struct {
-	Source       string   // source collector's name
-	Entity       string   // entity name ready for lookup
-	HighCardTags []string // high cardinality tags that can create a lot of contexts
-	LowCardTags  []string // low cardinality tags safe for every pipeline
-	DeleteEntity bool     // true if the entity is to be deleted from the store
+	Source               string        // source collector's name
+	Entity               string        // entity name ready for lookup
+	HighCardTags         []string      // high cardinality tags that can create a lot of contexts
+	OrchestratorCardTags []string      // orchestrator cardinality tags that have as many contexts as pods/tasks
+	LowCardTags          []string      // low cardinality tags safe for every pipeline
+	DeleteEntity         bool          // true if the entity is to be deleted from the store
+	CacheTTL             time.Duration // if set, the tags of this source are garbage collected if not refreshed within the TTL
 }
 
+// TagCardinality indicates the cardinality-level of a tag.
+// It can be low cardinality (in the host count order of magnitude)
+// orchestrator cardinality (tags that change value for each pod, task, etc.)
+// high cardinality (typically tags that change value for each web request, each container, etc.)
+type TagCardinality int
+
+// List of possible container cardinality
+const (
+	LowCardinality TagCardinality = iota
+	OrchestratorCardinality
+	HighCardinality
+)
+
 // CollectionMode informs the Tagger of how to schedule a Collector
 type CollectionMode int
 
@@ -42,9 +59,18 @@ const (
 	ClusterOrchestrator
 )
 
-// Fetcher allows to fetch tags on-demand in case of cache miss
+// Fetcher allows to fetch tags on-demand in case of cache miss.
+// It returns the low, orchestrator and high cardinality tags of the entity.
 type Fetcher interface {
-	Fetch(string) ([]string, []string, error)
+	Fetch(string) ([]string, []string, []string, error)
+}
+
+// EntityPrefixer can be implemented by collectors to declare the prefixes of
+// the entities they know about (for example "docker://"). The tagger will only
+// query them for matching entities on cache misses. Collectors that do not
+// implement it are queried for every entity.
+type EntityPrefixer interface {
+	EntityPrefixes() []string
 }
 
 // Streamer feeds back TagInfo when detecting changes
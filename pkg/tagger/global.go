@@ -8,6 +8,9 @@ package tagger
 import (
 	"sync"
 
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 )
@@ -16,22 +19,59 @@ import (
 var defaultTagger *Tagger
 var initOnce sync.Once
 
-// fullCardinality caches the value of the full_cardinality_taggin option
-var fullCardinality bool
+// ChecksCardinality defines the cardinality of tags we should send for check metrics
+// this can still be overridden when calling Tag
+var ChecksCardinality = collectors.LowCardinality
+
+// DogstatsdCardinality defines the cardinality of tags we should send for origin detection
+// in dogstatsd, as configured by the `dogstatsd_tag_cardinality` option
+var DogstatsdCardinality = collectors.LowCardinality
 
 // Init must be called once config is available, call it in your cmd
 // defaultTagger.Init cannot fail for now, keeping the `error` for API stability
 func Init() error {
 	initOnce.Do(func() {
-		fullCardinality = config.Datadog.GetBool("full_cardinality_tagging")
+		ChecksCardinality = cardinalityFromConfig("checks_tag_cardinality")
+		DogstatsdCardinality = cardinalityFromConfig("dogstatsd_tag_cardinality")
 		defaultTagger.Init(collectors.DefaultCatalog)
 	})
 	return nil
 }
 
-// Tag queries the defaultTagger to get entity tags from cache or sources
-func Tag(entity string, highCard bool) ([]string, error) {
-	return defaultTagger.Tag(entity, highCard)
+// cardinalityFromConfig reads a cardinality option, defaulting to low on
+// invalid values. The deprecated full_cardinality_tagging option takes
+// precedence to keep its previous behaviour.
+func cardinalityFromConfig(key string) collectors.TagCardinality {
+	if config.Datadog.GetBool("full_cardinality_tagging") {
+		return collectors.HighCardinality
+	}
+	cardinality, err := collectors.StringToTagCardinality(config.Datadog.GetString(key))
+	if err != nil {
+		log.Warnf("invalid %s option, defaulting to low cardinality: %s", key, err)
+	}
+	return cardinality
+}
+
+// Tag queries the defaultTagger to get entity tags from cache or sources.
+// It can return tags at high cardinality (with tags about individual containers),
+// or at orchestrator cardinality (pod/task level)
+func Tag(entity string, cardinality collectors.TagCardinality) ([]string, error) {
+	return defaultTagger.Tag(entity, cardinality)
+}
+
+// Subscribe returns a channel receiving the entity events of the defaultTagger
+func Subscribe(cardinality collectors.TagCardinality) chan []EntityEvent {
+	return defaultTagger.Subscribe(cardinality)
+}
+
+// Unsubscribe ends a subscription to the defaultTagger
+func Unsubscribe(ch chan []EntityEvent) {
+	defaultTagger.Unsubscribe(ch)
+}
+
+// List returns the entities stored in the defaultTagger, with their tags per source
+func List(prefix string) response.TaggerListResponse {
+	return defaultTagger.List(prefix)
 }
 
 // Stop queues a stop signal to the defaultTagger
@@ -39,12 +79,6 @@ func Stop() error {
 	return defaultTagger.Stop()
 }
 
-// IsFullCardinality returns the full_cardinality_tagging option
-// this caches the call to viper, that would lookup and parse envvars
-func IsFullCardinality() bool {
-	return fullCardinality
-}
-
 func init() {
 	defaultTagger = newTagger()
 }
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package remote implements a client for the tagger of a running core agent,
+// for the other agent processes (process-agent, trace-agent) to get container
+// tags without running their own metadata collection.
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+)
+
+// Tagger queries the tagger of the core agent through its IPC API,
+// authenticated with the IPC token.
+type Tagger struct {
+	client  *http.Client
+	tagsURL string
+}
+
+// NewTagger returns a Tagger querying the core agent listening on the
+// configured `cmd_port`. The config package must be ready.
+func NewTagger() (*Tagger, error) {
+	err := util.SetAuthToken()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the IPC auth token: %s", err)
+	}
+	return newTagger(util.IPCURL("agent/tagger/tags")), nil
+}
+
+func newTagger(tagsURL string) *Tagger {
+	return &Tagger{
+		client:  util.GetClient(false), // FIX: get certificates right then make this true
+		tagsURL: tagsURL,
+	}
+}
+
+// Tag returns the tags of an entity at the desired cardinality, as
+// computed by the core agent.
+func (t *Tagger) Tag(entity string, cardinality collectors.TagCardinality) ([]string, error) {
+	if entity == "" {
+		return nil, fmt.Errorf("empty entity ID")
+	}
+
+	query := url.Values{}
+	query.Set("entity", entity)
+	query.Set("cardinality", cardinality.String())
+
+	r, err := util.DoGet(t.client, t.tagsURL+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("unable to query the agent tagger for %s: %s", entity, err)
+	}
+
+	var tags []string
+	if err = json.Unmarshal(r, &tags); err != nil {
+		return nil, fmt.Errorf("unable to parse the agent tagger response: %s", err)
+	}
+	return tags, nil
+}
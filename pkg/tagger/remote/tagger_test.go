@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+)
+
+func TestTag(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/agent/tagger/tags", r.URL.Path)
+		switch r.URL.Query().Get("entity") {
+		case "docker://abc":
+			assert.Equal(t, "orchestrator", r.URL.Query().Get("cardinality"))
+			w.Write([]byte(`["image_name:redis","pod_name:redis-1"]`))
+		default:
+			http.Error(w, "unknown entity", 500)
+		}
+	}))
+	defer ts.Close()
+
+	tagger := newTagger(ts.URL + "/agent/tagger/tags")
+
+	tags, err := tagger.Tag("docker://abc", collectors.OrchestratorCardinality)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"image_name:redis", "pod_name:redis-1"}, tags)
+
+	_, err = tagger.Tag("docker://unknown", collectors.LowCardinality)
+	assert.Error(t, err)
+
+	_, err = tagger.Tag("", collectors.LowCardinality)
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package tagger
+
+import (
+	"sync"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+)
+
+// subscriberBufferSize is the number of event bundles that can be queued
+// for a subscriber before it is disconnected
+const subscriberBufferSize = 100
+
+// EventType is the type of change an EntityEvent describes
+type EventType int
+
+// List of possible event types
+const (
+	EventTypeAdded EventType = iota
+	EventTypeModified
+	EventTypeDeleted
+)
+
+// EntityEvent is sent to subscribers when an entity is added to, modified
+// in, or deleted from the tagger
+type EntityEvent struct {
+	EventType EventType
+	Entity    string
+	Tags      []string // tags at the subscribed cardinality, empty on deletion
+}
+
+// entityChange is the store-side representation of an event, tags are
+// computed for each subscriber's cardinality when notifying
+type entityChange struct {
+	eventType EventType
+	entity    string
+	tags      *entityTags
+}
+
+// subscriber keeps track of the channels subscribed to tagger events
+type subscriber struct {
+	sync.RWMutex
+	channels map[chan []EntityEvent]collectors.TagCardinality
+}
+
+func newSubscriber() *subscriber {
+	return &subscriber{
+		channels: make(map[chan []EntityEvent]collectors.TagCardinality),
+	}
+}
+
+// subscribe registers a new channel, and sends it the initial bundle of
+// events returned by the snapshot function. The lock is held during the
+// snapshot so that no change is missed between the snapshot and the
+// registration.
+func (s *subscriber) subscribe(cardinality collectors.TagCardinality, snapshot func() []entityChange) chan []EntityEvent {
+	ch := make(chan []EntityEvent, subscriberBufferSize)
+
+	s.Lock()
+	defer s.Unlock()
+	if events := buildEvents(snapshot(), cardinality); len(events) > 0 {
+		ch <- events
+	}
+	s.channels[ch] = cardinality
+
+	return ch
+}
+
+// unsubscribe removes a channel from the subscribers and closes it
+func (s *subscriber) unsubscribe(ch chan []EntityEvent) {
+	s.Lock()
+	defer s.Unlock()
+	if _, found := s.channels[ch]; !found {
+		return
+	}
+	delete(s.channels, ch)
+	close(ch)
+}
+
+// hasSubscribers allows callers to skip building changes when nobody listens
+func (s *subscriber) hasSubscribers() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return len(s.channels) > 0
+}
+
+// notify sends the changes to every subscriber, at its cardinality. It never
+// blocks: a subscriber whose buffer is full is disconnected, its channel is
+// closed so that it can subscribe again and get a new snapshot.
+func (s *subscriber) notify(changes []entityChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	s.RLock()
+	cardinalities := make(map[chan []EntityEvent]collectors.TagCardinality, len(s.channels))
+	for ch, cardinality := range s.channels {
+		cardinalities[ch] = cardinality
+	}
+	s.RUnlock()
+
+	// the events are built without the lock
+	events := make(map[chan []EntityEvent][]EntityEvent, len(cardinalities))
+	for ch, cardinality := range cardinalities {
+		events[ch] = buildEvents(changes, cardinality)
+	}
+
+	// the sends are done under the lock so that a channel can not be closed
+	// by unsubscribe meanwhile, they do not block
+	var overflowed []chan []EntityEvent
+	s.RLock()
+	for ch := range events {
+		if _, found := s.channels[ch]; !found {
+			continue
+		}
+		select {
+		case ch <- events[ch]:
+		default:
+			overflowed = append(overflowed, ch)
+		}
+	}
+	s.RUnlock()
+
+	for _, ch := range overflowed {
+		log.Warnf("Disconnecting a tagger subscriber: its buffer of %d events is full", subscriberBufferSize)
+		s.unsubscribe(ch)
+	}
+}
+
+func buildEvents(changes []entityChange, cardinality collectors.TagCardinality) []EntityEvent {
+	events := make([]EntityEvent, 0, len(changes))
+	for _, change := range changes {
+		event := EntityEvent{
+			EventType: change.eventType,
+			Entity:    change.entity,
+		}
+		if change.eventType != EventTypeDeleted && change.tags != nil {
+			tags, _ := change.tags.get(cardinality)
+			event.Tags = copyArray(tags)
+		}
+		events = append(events, event)
+	}
+	return events
+}
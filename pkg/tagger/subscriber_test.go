@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package tagger
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+)
+
+func TestSubscribe(t *testing.T) {
+	store := newTagStore()
+	store.processTagInfo(&collectors.TagInfo{
+		Source:       "source",
+		Entity:       "existing",
+		LowCardTags:  []string{"low"},
+		HighCardTags: []string{"high"},
+	})
+
+	lowCh := store.subscribe(collectors.LowCardinality)
+	highCh := store.subscribe(collectors.HighCardinality)
+
+	// Initial snapshot
+	events := <-lowCh
+	require.Len(t, events, 1)
+	assert.Equal(t, EntityEvent{EventType: EventTypeAdded, Entity: "existing", Tags: []string{"low"}}, events[0])
+	events = <-highCh
+	require.Len(t, events, 1)
+	assert.ElementsMatch(t, []string{"low", "high"}, events[0].Tags)
+
+	// Addition
+	store.processTagInfo(&collectors.TagInfo{
+		Source:               "source",
+		Entity:               "new",
+		LowCardTags:          []string{"low"},
+		OrchestratorCardTags: []string{"orchestrator"},
+	})
+	events = <-lowCh
+	require.Len(t, events, 1)
+	assert.Equal(t, EntityEvent{EventType: EventTypeAdded, Entity: "new", Tags: []string{"low"}}, events[0])
+	events = <-highCh
+	require.Len(t, events, 1)
+	assert.ElementsMatch(t, []string{"low", "orchestrator"}, events[0].Tags)
+
+	// Modification
+	store.processTagInfo(&collectors.TagInfo{
+		Source:      "source",
+		Entity:      "existing",
+		LowCardTags: []string{"low2"},
+	})
+	events = <-lowCh
+	require.Len(t, events, 1)
+	assert.Equal(t, EntityEvent{EventType: EventTypeModified, Entity: "existing", Tags: []string{"low2"}}, events[0])
+	<-highCh
+
+	// Deletion is sent on prune
+	store.processTagInfo(&collectors.TagInfo{
+		Source:       "source",
+		Entity:       "existing",
+		DeleteEntity: true,
+	})
+	assert.Len(t, lowCh, 0)
+	store.prune()
+	events = <-lowCh
+	require.Len(t, events, 1)
+	assert.Equal(t, EntityEvent{EventType: EventTypeDeleted, Entity: "existing"}, events[0])
+	<-highCh
+
+	// Expired entities are also sent as deleted
+	store.processTagInfo(&collectors.TagInfo{
+		Source:      "source",
+		Entity:      "short-lived",
+		LowCardTags: []string{"low"},
+		CacheTTL:    time.Nanosecond,
+	})
+	<-lowCh
+	<-highCh
+	store.prune()
+	events = <-lowCh
+	require.Len(t, events, 1)
+	assert.Equal(t, EntityEvent{EventType: EventTypeDeleted, Entity: "short-lived"}, events[0])
+	<-highCh
+
+	// Unsubscribe closes the channel
+	store.unsubscribe(lowCh)
+	_, ok := <-lowCh
+	assert.False(t, ok)
+	store.unsubscribe(highCh)
+	assert.False(t, store.subscriber.hasSubscribers())
+}
+
+func TestSubscribeEmptyStore(t *testing.T) {
+	store := newTagStore()
+	ch := store.subscribe(collectors.LowCardinality)
+	assert.Len(t, ch, 0)
+	store.unsubscribe(ch)
+}
+
+func TestSubscriberOverflow(t *testing.T) {
+	store := newTagStore()
+	ch := store.subscribe(collectors.LowCardinality)
+
+	// nobody reads the channel, the store must not block on it
+	for i := 0; i <= subscriberBufferSize; i++ {
+		store.processTagInfo(&collectors.TagInfo{
+			Source:      "source",
+			Entity:      fmt.Sprintf("entity-%d", i),
+			LowCardTags: []string{"low"},
+		})
+	}
+
+	// the subscriber was disconnected, its channel is drained then closed
+	assert.False(t, store.subscriber.hasSubscribers())
+	received := 0
+	for range ch {
+		received++
+	}
+	assert.Equal(t, subscriberBufferSize, received)
+}
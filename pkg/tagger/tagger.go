@@ -7,11 +7,14 @@ package tagger
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	log "github.com/cihub/seelog"
 
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/errors"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
@@ -19,6 +22,12 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/retry"
 )
 
+// defaultFetchedTagsTTL is the time after which tags stored on cache misses are
+// garbage collected when the collector will not notify us of the entity deletion,
+// when tagger_fetched_tags_ttl is not valid. The tags will be fetched again on
+// the next lookup if the entity is still alive.
+const defaultFetchedTagsTTL = 10 * time.Minute
+
 // Tagger is the entry class for entity tagging. It holds collectors, memory store
 // and handles the query logic. One can use the package methods to use the default
 // tagger instead of instanciating one.
@@ -29,12 +38,16 @@ type Tagger struct {
 	pullers     map[string]collectors.Puller
 	streamers   map[string]collectors.Streamer
 	fetchers    map[string]collectors.Fetcher
+	prefixes    map[string][]string // entity prefixes declared by the fetchers, nil for all entities
 	infoIn      chan []*collectors.TagInfo
 	pullTicker  *time.Ticker
 	pruneTicker *time.Ticker
 	retryTicker *time.Ticker
 	stop        chan bool
 	health      *health.Handle
+
+	// fetchedTagsTTL is the TTL of the tags stored on cache misses, see defaultFetchedTagsTTL
+	fetchedTagsTTL time.Duration
 }
 
 type collectorReply struct {
@@ -54,12 +67,15 @@ func newTagger() *Tagger {
 		pullers:     make(map[string]collectors.Puller),
 		streamers:   make(map[string]collectors.Streamer),
 		fetchers:    make(map[string]collectors.Fetcher),
+		prefixes:    make(map[string][]string),
 		infoIn:      make(chan []*collectors.TagInfo, 5),
 		pullTicker:  time.NewTicker(5 * time.Second),
 		pruneTicker: time.NewTicker(5 * time.Minute),
 		retryTicker: time.NewTicker(30 * time.Second),
 		stop:        make(chan bool),
 		health:      health.Register("tagger"),
+
+		fetchedTagsTTL: defaultFetchedTagsTTL,
 	}
 }
 
@@ -73,6 +89,11 @@ func (t *Tagger) Init(catalog collectors.Catalog) {
 	for name, factory := range catalog {
 		t.candidates[name] = factory
 	}
+	if ttl := config.Datadog.GetInt("tagger_fetched_tags_ttl"); ttl > 0 {
+		t.fetchedTagsTTL = time.Duration(ttl) * time.Second
+	} else {
+		log.Warnf("Invalid tagger_fetched_tags_ttl %d, using the default %s", ttl, defaultFetchedTagsTTL)
+	}
 	t.Unlock()
 
 	log.Info("starting the tagging system")
@@ -165,6 +186,10 @@ func (t *Tagger) registerCollectors(replies []collectorReply) {
 		// Whatever the outcome, don't try this collector again
 		delete(t.candidates, c.name)
 
+		if prefixer, ok := c.instance.(collectors.EntityPrefixer); ok {
+			t.prefixes[c.name] = prefixer.EntityPrefixes()
+		}
+
 		switch c.mode {
 		case collectors.PullCollection:
 			pull, ok := c.instance.(collectors.Puller)
@@ -212,15 +237,19 @@ func (t *Tagger) Stop() error {
 	return nil
 }
 
-// Tag returns tags for a given entity. If highCard is false, high
-// cardinality tags are left out.
-func (t *Tagger) Tag(entity string, highCard bool) ([]string, error) {
+// Tag returns tags for a given entity at the desired cardinality: tags of
+// a higher cardinality than requested are left out.
+func (t *Tagger) Tag(entity string, cardinality collectors.TagCardinality) ([]string, error) {
 	if entity == "" {
 		return nil, fmt.Errorf("empty entity ID")
 	}
-	cachedTags, sources := t.tagStore.lookup(entity, highCard)
+	cachedTags, sources := t.tagStore.lookup(entity, cardinality)
 
-	if len(sources) == len(t.fetchers) {
+	t.RLock()
+	defer t.RUnlock()
+
+	missing := t.missingFetchers(entity, sources)
+	if len(missing) == 0 {
 		// All sources sent data to cache
 		return copyArray(cachedTags), nil
 	}
@@ -228,42 +257,109 @@ func (t *Tagger) Tag(entity string, highCard bool) ([]string, error) {
 	// TODO: get logging on that to make sure we should optimize
 	tagArrays := [][]string{cachedTags}
 
-	t.RLock()
-ITER_COLLECTORS:
-	for name, collector := range t.fetchers {
-		for _, s := range sources {
-			if s == name {
-				continue ITER_COLLECTORS // source was in cache, don't lookup again
-			}
-		}
+	for name, collector := range missing {
 		log.Debugf("cache miss for %s, collecting tags for %s", name, entity)
-		low, high, err := collector.Fetch(entity)
+		low, orchestrator, high, err := collector.Fetch(entity)
+		var cacheTTL time.Duration
 		switch {
 		case errors.IsNotFound(err):
 			log.Debugf("entity %s not found in %s, skipping: %v", entity, name, err)
+			cacheTTL = t.fetchedTagsTTL
 		case err != nil:
 			log.Warnf("error collecting from %s: %s", name, err)
 			continue // don't store empty tags, retry next time
 		}
+		if !t.notifiesDeletion(name) {
+			cacheTTL = t.fetchedTagsTTL
+		}
 		tagArrays = append(tagArrays, low)
-		if highCard {
+		if cardinality == collectors.OrchestratorCardinality {
+			tagArrays = append(tagArrays, orchestrator)
+		} else if cardinality == collectors.HighCardinality {
+			tagArrays = append(tagArrays, orchestrator)
 			tagArrays = append(tagArrays, high)
 		}
 		// Submit to cache for next lookup
 		t.tagStore.processTagInfo(&collectors.TagInfo{
-			Entity:       entity,
-			Source:       name,
-			LowCardTags:  low,
-			HighCardTags: high,
+			Entity:               entity,
+			Source:               name,
+			LowCardTags:          low,
+			OrchestratorCardTags: orchestrator,
+			HighCardTags:         high,
+			CacheTTL:             cacheTTL,
 		})
 	}
-	t.RUnlock()
 
 	computedTags := utils.ConcatenateTags(tagArrays)
 
 	return copyArray(computedTags), nil
 }
 
+// missingFetchers returns the fetchers that handle the entity but did not
+// send data for it to the cache, the caller is expected to hold the read lock
+func (t *Tagger) missingFetchers(entity string, sources []string) map[string]collectors.Fetcher {
+	missing := make(map[string]collectors.Fetcher)
+
+ITER_COLLECTORS:
+	for name, collector := range t.fetchers {
+		for _, s := range sources {
+			if s == name {
+				continue ITER_COLLECTORS // source was in cache, don't lookup again
+			}
+		}
+		if !t.handlesEntity(name, entity) {
+			continue
+		}
+		missing[name] = collector
+	}
+	return missing
+}
+
+// handlesEntity returns whether the entity matches the prefixes declared
+// by a collector, collectors without prefixes handle all entities
+func (t *Tagger) handlesEntity(name, entity string) bool {
+	prefixes, found := t.prefixes[name]
+	if !found {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(entity, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifiesDeletion returns whether a collector sends deletion messages for
+// the entities it knows, the caller is expected to hold the read lock
+func (t *Tagger) notifiesDeletion(name string) bool {
+	if _, found := t.pullers[name]; found {
+		return true
+	}
+	_, found := t.streamers[name]
+	return found
+}
+
+// Subscribe returns a channel that receives a slice of events whenever an entity is
+// added, modified or deleted, with its tags at the given cardinality. The first
+// bundle holds every entity already known. The channel must be consumed promptly:
+// it is closed when its buffer is full, the subscriber then has to subscribe
+// again. It is released with Unsubscribe.
+func (t *Tagger) Subscribe(cardinality collectors.TagCardinality) chan []EntityEvent {
+	return t.tagStore.subscribe(cardinality)
+}
+
+// Unsubscribe ends a subscription and closes its channel
+func (t *Tagger) Unsubscribe(ch chan []EntityEvent) {
+	t.tagStore.unsubscribe(ch)
+}
+
+// List returns the tags of the entities whose name starts with prefix, along
+// with the collector that provided each tag. It does not query the collectors.
+func (t *Tagger) List(prefix string) response.TaggerListResponse {
+	return t.tagStore.list(prefix)
+}
+
 // copyArray makes sure the tagger does not return internal slices
 // that could be modified by others, by explicitly copying the slice
 // contents to a new slice. As strings are references, the size of
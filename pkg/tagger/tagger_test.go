@@ -26,9 +26,9 @@ func (c *DummyCollector) Detect(out chan<- []*collectors.TagInfo) (collectors.Co
 	args := c.Called(out)
 	return args.Get(0).(collectors.CollectionMode), args.Error(1)
 }
-func (c *DummyCollector) Fetch(entity string) ([]string, []string, error) {
+func (c *DummyCollector) Fetch(entity string) ([]string, []string, []string, error) {
 	args := c.Called(entity)
-	return args.Get(0).([]string), args.Get(1).([]string), args.Error(2)
+	return args.Get(0).([]string), args.Get(1).([]string), args.Get(2).([]string), args.Error(3)
 }
 
 func (c *DummyCollector) Stream() error {
@@ -66,6 +66,20 @@ func NewDummyFetcher() collectors.Collector {
 	return c
 }
 
+type DummyPrefixedCollector struct {
+	DummyCollector
+}
+
+func (c *DummyPrefixedCollector) EntityPrefixes() []string {
+	return []string{"docker://"}
+}
+
+func NewDummyPrefixedFetcher() collectors.Collector {
+	c := new(DummyPrefixedCollector)
+	c.On("Detect", mock.Anything).Return(collectors.FetchOnlyCollection, nil)
+	return c
+}
+
 func NewDummyCollector() collectors.Collector {
 	c := new(DummyCollector)
 	return c
@@ -106,13 +120,13 @@ func TestFetchAllMiss(t *testing.T) {
 
 	streamer := tagger.streamers["stream"].(*DummyCollector)
 	assert.NotNil(t, streamer)
-	streamer.On("Fetch", "entity_name").Return([]string{"low1"}, []string{}, nil)
+	streamer.On("Fetch", "entity_name").Return([]string{"low1"}, []string{}, []string{}, nil)
 
 	puller := tagger.pullers["pull"].(*DummyCollector)
 	assert.NotNil(t, puller)
-	puller.On("Fetch", "entity_name").Return([]string{"low2"}, []string{}, nil)
+	puller.On("Fetch", "entity_name").Return([]string{"low2"}, []string{}, []string{}, nil)
 
-	tags, err := tagger.Tag("entity_name", false)
+	tags, err := tagger.Tag("entity_name", collectors.LowCardinality)
 	assert.NoError(t, err)
 	sort.Strings(tags)
 	assert.Equal(t, []string{"low1", "low2"}, tags)
@@ -127,10 +141,11 @@ func TestFetchAllCached(t *testing.T) {
 	tagger.Init(catalog)
 
 	tagger.tagStore.processTagInfo(&collectors.TagInfo{
-		Entity:       "entity_name",
-		Source:       "stream",
-		LowCardTags:  []string{"low1"},
-		HighCardTags: []string{"high"},
+		Entity:               "entity_name",
+		Source:               "stream",
+		LowCardTags:          []string{"low1"},
+		OrchestratorCardTags: []string{"orchestrator"},
+		HighCardTags:         []string{"high"},
 	})
 	tagger.tagStore.processTagInfo(&collectors.TagInfo{
 		Entity:      "entity_name",
@@ -140,23 +155,27 @@ func TestFetchAllCached(t *testing.T) {
 
 	streamer := tagger.streamers["stream"].(*DummyCollector)
 	assert.NotNil(t, streamer)
-	streamer.On("Fetch", "entity_name").Return([]string{"low1"}, []string{}, nil)
+	streamer.On("Fetch", "entity_name").Return([]string{"low1"}, []string{}, []string{}, nil)
 
 	puller := tagger.pullers["pull"].(*DummyCollector)
 	assert.NotNil(t, puller)
-	puller.On("Fetch", "entity_name").Return([]string{"low2"}, []string{}, nil)
+	puller.On("Fetch", "entity_name").Return([]string{"low2"}, []string{}, []string{}, nil)
 
-	tags, err := tagger.Tag("entity_name", true)
+	tags, err := tagger.Tag("entity_name", collectors.HighCardinality)
 	assert.NoError(t, err)
-	assert.ElementsMatch(t, []string{"high", "low1", "low2"}, tags)
+	assert.ElementsMatch(t, []string{"high", "orchestrator", "low1", "low2"}, tags)
 
 	streamer.AssertNotCalled(t, "Fetch", "entity_name")
 	puller.AssertNotCalled(t, "Fetch", "entity_name")
 
-	tags2, err := tagger.Tag("entity_name", false)
+	tags2, err := tagger.Tag("entity_name", collectors.LowCardinality)
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []string{"low1", "low2"}, tags2)
 
+	tags3, err := tagger.Tag("entity_name", collectors.OrchestratorCardinality)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"orchestrator", "low1", "low2"}, tags3)
+
 	streamer.AssertNotCalled(t, "Fetch", "entity_name")
 	puller.AssertNotCalled(t, "Fetch", "entity_name")
 }
@@ -178,17 +197,17 @@ func TestFetchOneCached(t *testing.T) {
 
 	streamer := tagger.streamers["stream"].(*DummyCollector)
 	assert.NotNil(t, streamer)
-	streamer.On("Fetch", "entity_name").Return([]string{"low1"}, []string{}, nil)
+	streamer.On("Fetch", "entity_name").Return([]string{"low1"}, []string{}, []string{}, nil)
 
 	puller := tagger.pullers["pull"].(*DummyCollector)
 	assert.NotNil(t, puller)
-	puller.On("Fetch", "entity_name").Return([]string{"low2"}, []string{}, nil)
+	puller.On("Fetch", "entity_name").Return([]string{"low2"}, []string{}, []string{}, nil)
 
 	fetcher := tagger.fetchers["fetcher"].(*DummyCollector)
 	assert.NotNil(t, fetcher)
-	fetcher.On("Fetch", "entity_name").Return([]string{"low3"}, []string{}, nil)
+	fetcher.On("Fetch", "entity_name").Return([]string{"low3"}, []string{}, []string{}, nil)
 
-	tags, err := tagger.Tag("entity_name", true)
+	tags, err := tagger.Tag("entity_name", collectors.HighCardinality)
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []string{"low1", "low2", "low3"}, tags)
 
@@ -197,6 +216,39 @@ func TestFetchOneCached(t *testing.T) {
 	fetcher.AssertCalled(t, "Fetch", "entity_name")
 }
 
+func TestFetchEntityPrefixes(t *testing.T) {
+	catalog := collectors.Catalog{
+		"fetcher":  NewDummyFetcher,
+		"prefixed": NewDummyPrefixedFetcher,
+	}
+	tagger := newTagger()
+	tagger.Init(catalog)
+	assert.Equal(t, []string{"docker://"}, tagger.prefixes["prefixed"])
+
+	fetcher := tagger.fetchers["fetcher"].(*DummyCollector)
+	fetcher.On("Fetch", mock.Anything).Return([]string{"low1"}, []string{}, []string{}, nil)
+	prefixed := tagger.fetchers["prefixed"].(*DummyPrefixedCollector)
+	prefixed.On("Fetch", mock.Anything).Return([]string{"low2"}, []string{}, []string{}, nil)
+
+	// Only the fetcher handling all entities is queried
+	tags, err := tagger.Tag("kubernetes_pod://abc", collectors.LowCardinality)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"low1"}, tags)
+	fetcher.AssertCalled(t, "Fetch", "kubernetes_pod://abc")
+	prefixed.AssertNotCalled(t, "Fetch", "kubernetes_pod://abc")
+
+	// The entity is complete in cache without the prefixed fetcher
+	_, err = tagger.Tag("kubernetes_pod://abc", collectors.LowCardinality)
+	assert.NoError(t, err)
+	fetcher.AssertNumberOfCalls(t, "Fetch", 1)
+
+	// Both are queried for matching entities
+	tags, err = tagger.Tag("docker://abc", collectors.LowCardinality)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"low1", "low2"}, tags)
+	prefixed.AssertCalled(t, "Fetch", "docker://abc")
+}
+
 func TestEmptyEntity(t *testing.T) {
 	catalog := collectors.Catalog{
 		"fetcher": NewDummyFetcher,
@@ -210,7 +262,7 @@ func TestEmptyEntity(t *testing.T) {
 		LowCardTags: []string{"low1"},
 	})
 
-	tags, err := tagger.Tag("", true)
+	tags, err := tagger.Tag("", collectors.HighCardinality)
 	assert.Nil(t, tags)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "empty entity ID")
@@ -268,20 +320,20 @@ func TestErrNotFound(t *testing.T) {
 	tagger.Init(catalog)
 
 	// Result should not be cached
-	c.On("Fetch", mock.Anything).Return([]string{}, []string{}, badErr).Once()
-	_, err := tagger.Tag("invalid", true)
+	c.On("Fetch", mock.Anything).Return([]string{}, []string{}, []string{}, badErr).Once()
+	_, err := tagger.Tag("invalid", collectors.HighCardinality)
 	assert.NoError(t, err)
 	c.AssertNumberOfCalls(t, "Fetch", 1)
 
 	// Nil result should be cached now
-	c.On("Fetch", mock.Anything).Return([]string{}, []string{}, errors.NewNotFound("")).Once()
-	_, err = tagger.Tag("invalid", true)
+	c.On("Fetch", mock.Anything).Return([]string{}, []string{}, []string{}, errors.NewNotFound("")).Once()
+	_, err = tagger.Tag("invalid", collectors.HighCardinality)
 	assert.NoError(t, err)
 	c.AssertNumberOfCalls(t, "Fetch", 2)
 
 	// Fetch will not be called again
-	c.On("Fetch", mock.Anything).Return([]string{}, []string{}, errors.NewNotFound("")).Once()
-	_, err = tagger.Tag("invalid", true)
+	c.On("Fetch", mock.Anything).Return([]string{}, []string{}, []string{}, errors.NewNotFound("")).Once()
+	_, err = tagger.Tag("invalid", collectors.HighCardinality)
 	assert.NoError(t, err)
 	c.AssertNumberOfCalls(t, "Fetch", 2)
 }
@@ -298,7 +350,7 @@ func TestSafeCache(t *testing.T) {
 	})
 
 	// First lookup
-	tags, err := tagger.Tag("entity_name", true)
+	tags, err := tagger.Tag("entity_name", collectors.HighCardinality)
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []string{"low1", "low2", "low3"}, tags)
 
@@ -306,7 +358,7 @@ func TestSafeCache(t *testing.T) {
 	tags[0] = "nope"
 
 	// Make sure the cache is not affected
-	tags2, err := tagger.Tag("entity_name", true)
+	tags2, err := tagger.Tag("entity_name", collectors.HighCardinality)
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []string{"low1", "low2", "low3"}, tags2)
 }
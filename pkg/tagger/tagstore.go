@@ -4,21 +4,27 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	log "github.com/cihub/seelog"
 
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/intern"
 )
 
 // entityTags holds the tag information for a given entity
 type entityTags struct {
 	sync.RWMutex
-	lowCardTags  map[string][]string
-	highCardTags map[string][]string
-	cacheValid   bool
-	cachedSource []string
-	cachedAll    []string // Low + high
-	cachedLow    []string // Sub-slice of cachedAll
+	lowCardTags          map[string][]string
+	orchestratorCardTags map[string][]string
+	highCardTags         map[string][]string
+	expiryDates          map[string]time.Time // per source, only set for sources with a CacheTTL
+	cacheValid           bool
+	cachedSource         []string
+	cachedAll            []string // Low + orchestrator + high
+	cachedOrchestrator   []string // Low + orchestrator (sub-slice of cachedAll)
+	cachedLow            []string // Sub-slice of cachedAll
 }
 
 // tagStore stores entity tags in memory and handles search and collation.
@@ -28,12 +34,14 @@ type tagStore struct {
 	store         map[string]*entityTags
 	toDeleteMutex sync.RWMutex
 	toDelete      map[string]struct{} // set emulation
+	subscriber    *subscriber
 }
 
 func newTagStore() *tagStore {
 	return &tagStore{
-		store:    make(map[string]*entityTags),
-		toDelete: make(map[string]struct{}),
+		store:      make(map[string]*entityTags),
+		toDelete:   make(map[string]struct{}),
+		subscriber: newSubscriber(),
 	}
 }
 
@@ -60,14 +68,23 @@ func (s *tagStore) processTagInfo(info *collectors.TagInfo) error {
 	s.storeMutex.RUnlock()
 	if exist == false {
 		storedTags = &entityTags{
-			lowCardTags:  make(map[string][]string),
-			highCardTags: make(map[string][]string),
+			lowCardTags:          make(map[string][]string),
+			orchestratorCardTags: make(map[string][]string),
+			highCardTags:         make(map[string][]string),
+			expiryDates:          make(map[string]time.Time),
 		}
 	}
 
 	storedTags.Lock()
-	storedTags.lowCardTags[info.Source] = info.LowCardTags
-	storedTags.highCardTags[info.Source] = info.HighCardTags
+	// the entities share most of their tags, they are interned
+	storedTags.lowCardTags[info.Source] = intern.Strings(info.LowCardTags)
+	storedTags.orchestratorCardTags[info.Source] = intern.Strings(info.OrchestratorCardTags)
+	storedTags.highCardTags[info.Source] = intern.Strings(info.HighCardTags)
+	if info.CacheTTL > 0 {
+		storedTags.expiryDates[info.Source] = time.Now().Add(info.CacheTTL)
+	} else {
+		delete(storedTags.expiryDates, info.Source)
+	}
 	storedTags.cacheValid = false
 	storedTags.Unlock()
 
@@ -77,12 +94,50 @@ func (s *tagStore) processTagInfo(info *collectors.TagInfo) error {
 		s.storeMutex.Unlock()
 	}
 
+	if s.subscriber.hasSubscribers() {
+		eventType := EventTypeModified
+		if exist == false {
+			eventType = EventTypeAdded
+		}
+		s.subscriber.notify([]entityChange{{eventType: eventType, entity: info.Entity, tags: storedTags}})
+	}
+
 	return nil
 }
 
+// subscribe returns a channel receiving the changes of the store, starting
+// with an EventTypeAdded event for every entity already stored
+func (s *tagStore) subscribe(cardinality collectors.TagCardinality) chan []EntityEvent {
+	return s.subscriber.subscribe(cardinality, func() []entityChange {
+		s.storeMutex.RLock()
+		defer s.storeMutex.RUnlock()
+		changes := make([]entityChange, 0, len(s.store))
+		for entity, tags := range s.store {
+			changes = append(changes, entityChange{eventType: EventTypeAdded, entity: entity, tags: tags})
+		}
+		return changes
+	})
+}
+
+// unsubscribe closes a channel returned by subscribe
+func (s *tagStore) unsubscribe(ch chan []EntityEvent) {
+	s.subscriber.unsubscribe(ch)
+}
+
 // prune will lock the store and delete tags for the entity previously
-// passed as delete. This is to be called regularly from the user class.
+// passed as delete, and the tags of sources that expired. This is to be
+// called regularly from the user class.
 func (s *tagStore) prune() error {
+	changes := s.pruneDeleted()
+	changes = append(changes, s.pruneExpired(time.Now())...)
+
+	s.subscriber.notify(changes)
+
+	return nil
+}
+
+// pruneDeleted deletes the entities previously passed as delete
+func (s *tagStore) pruneDeleted() []entityChange {
 	s.toDeleteMutex.Lock()
 	defer s.toDeleteMutex.Unlock()
 
@@ -90,24 +145,72 @@ func (s *tagStore) prune() error {
 		return nil
 	}
 
+	var changes []entityChange
 	s.storeMutex.Lock()
 	for entity := range s.toDelete {
+		if _, found := s.store[entity]; found {
+			changes = append(changes, entityChange{eventType: EventTypeDeleted, entity: entity})
+		}
 		delete(s.store, entity)
 	}
-	s.storeMutex.Unlock()
-
 	log.Debugf("pruned %d removed entites, %d remaining", len(s.toDelete), len(s.store))
+	s.storeMutex.Unlock()
 
 	// Start fresh
 	s.toDelete = make(map[string]struct{})
 
-	return nil
+	return changes
+}
+
+// pruneExpired removes the tags of the sources whose expiry date is before
+// now. Entities left without any source are deleted from the store.
+func (s *tagStore) pruneExpired(now time.Time) []entityChange {
+	var changes []entityChange
+
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	for entity, storedTags := range s.store {
+		storedTags.Lock()
+		expired := false
+		for source, expiryDate := range storedTags.expiryDates {
+			if expiryDate.After(now) {
+				continue
+			}
+			delete(storedTags.lowCardTags, source)
+			delete(storedTags.orchestratorCardTags, source)
+			delete(storedTags.highCardTags, source)
+			delete(storedTags.expiryDates, source)
+			expired = true
+		}
+		if expired {
+			storedTags.cacheValid = false
+		}
+		remaining := len(storedTags.lowCardTags)
+		storedTags.Unlock()
+
+		switch {
+		case !expired:
+			continue
+		case remaining == 0:
+			delete(s.store, entity)
+			changes = append(changes, entityChange{eventType: EventTypeDeleted, entity: entity})
+		default:
+			changes = append(changes, entityChange{eventType: EventTypeModified, entity: entity, tags: storedTags})
+		}
+	}
+
+	if len(changes) > 0 {
+		log.Debugf("garbage collected %d expired entities, %d remaining", len(changes), len(s.store))
+	}
+
+	return changes
 }
 
 // lookup gets tags from the store and returns them concatenated in a []string
 // array. It returns the source names in the second []string to allow the
 // client to trigger manual lookups on missing sources.
-func (s *tagStore) lookup(entity string, highCard bool) ([]string, []string) {
+func (s *tagStore) lookup(entity string, cardinality collectors.TagCardinality) ([]string, []string) {
 	s.storeMutex.RLock()
 	storedTags, present := s.store[entity]
 	s.storeMutex.RUnlock()
@@ -115,25 +218,56 @@ func (s *tagStore) lookup(entity string, highCard bool) ([]string, []string) {
 	if present == false {
 		return nil, nil
 	}
-	return storedTags.get(highCard)
+	return storedTags.get(cardinality)
+}
+
+// list returns the tags of every stored entity whose name starts with
+// prefix, keyed by source, for debugging purposes.
+func (s *tagStore) list(prefix string) response.TaggerListResponse {
+	r := response.TaggerListResponse{
+		Entities: make(map[string]response.TaggerListEntity),
+	}
+
+	s.storeMutex.RLock()
+	defer s.storeMutex.RUnlock()
+
+	for entity, et := range s.store {
+		if !strings.HasPrefix(entity, prefix) {
+			continue
+		}
+		r.Entities[entity] = et.list()
+	}
+
+	return r
+}
+
+// list returns all the tags of an entity, per source, without priority deduplication
+func (e *entityTags) list() response.TaggerListEntity {
+	e.RLock()
+	defer e.RUnlock()
+
+	tags := make(map[string][]string)
+	for _, cardTags := range []map[string][]string{e.lowCardTags, e.orchestratorCardTags, e.highCardTags} {
+		for source, t := range cardTags {
+			tags[source] = append(tags[source], t...)
+		}
+	}
+	return response.TaggerListEntity{Tags: tags}
 }
 
 type tagPriority struct {
-	tag        string                       // full tag
-	priority   collectors.CollectorPriority // collector priority
-	isHighCard bool                         // is the tag high cardinality
+	tag         string                       // full tag
+	priority    collectors.CollectorPriority // collector priority
+	cardinality collectors.TagCardinality    // cardinality level of the tag (low, orchestrator, high)
 }
 
-func (e *entityTags) get(highCard bool) ([]string, []string) {
+func (e *entityTags) get(cardinality collectors.TagCardinality) ([]string, []string) {
 	e.RLock()
 
 	// Cache hit
 	if e.cacheValid {
 		defer e.RUnlock()
-		if highCard {
-			return e.cachedAll, e.cachedSource
-		}
-		return e.cachedLow, e.cachedSource
+		return e.cachedTags(cardinality), e.cachedSource
 	}
 
 	// Cache miss
@@ -142,14 +276,19 @@ func (e *entityTags) get(highCard bool) ([]string, []string) {
 
 	for source, tags := range e.lowCardTags {
 		sources = append(sources, source)
-		insertWithPriority(tagPrioMapper, tags, source, false)
+		insertWithPriority(tagPrioMapper, tags, source, collectors.LowCardinality)
+	}
+
+	for source, tags := range e.orchestratorCardTags {
+		insertWithPriority(tagPrioMapper, tags, source, collectors.OrchestratorCardinality)
 	}
 
 	for source, tags := range e.highCardTags {
-		insertWithPriority(tagPrioMapper, tags, source, true)
+		insertWithPriority(tagPrioMapper, tags, source, collectors.HighCardinality)
 	}
 
 	lowCardTags := []string{}
+	orchestratorCardTags := []string{}
 	highCardTags := []string{}
 	for _, tags := range tagPrioMapper {
 		for i := 0; i < len(tags); i++ {
@@ -162,16 +301,22 @@ func (e *entityTags) get(highCard bool) ([]string, []string) {
 				}
 			}
 			if insert {
-				if tags[i].isHighCard {
+				switch tags[i].cardinality {
+				case collectors.HighCardinality:
 					highCardTags = append(highCardTags, tags[i].tag)
-				} else {
+				case collectors.OrchestratorCardinality:
+					orchestratorCardTags = append(orchestratorCardTags, tags[i].tag)
+				default:
 					lowCardTags = append(lowCardTags, tags[i].tag)
 				}
 			}
 		}
 	}
 
-	tags := append(lowCardTags, highCardTags...)
+	tags := make([]string, 0, len(lowCardTags)+len(orchestratorCardTags)+len(highCardTags))
+	tags = append(tags, lowCardTags...)
+	tags = append(tags, orchestratorCardTags...)
+	tags = append(tags, highCardTags...)
 
 	// Write cache
 	e.RUnlock()
@@ -180,15 +325,27 @@ func (e *entityTags) get(highCard bool) ([]string, []string) {
 	e.cachedSource = sources
 	e.cachedAll = tags
 	e.cachedLow = e.cachedAll[:len(lowCardTags)]
+	e.cachedOrchestrator = e.cachedAll[:len(lowCardTags)+len(orchestratorCardTags)]
+	cached := e.cachedTags(cardinality)
 	e.Unlock()
 
-	if highCard {
-		return tags, sources
+	return cached, sources
+}
+
+// cachedTags returns the cached tags up to the given cardinality level,
+// the caller is expected to hold the lock
+func (e *entityTags) cachedTags(cardinality collectors.TagCardinality) []string {
+	switch cardinality {
+	case collectors.HighCardinality:
+		return e.cachedAll
+	case collectors.OrchestratorCardinality:
+		return e.cachedOrchestrator
+	default:
+		return e.cachedLow
 	}
-	return lowCardTags, sources
 }
 
-func insertWithPriority(tagPrioMapper map[string][]tagPriority, tags []string, source string, isHighCard bool) {
+func insertWithPriority(tagPrioMapper map[string][]tagPriority, tags []string, source string, cardinality collectors.TagCardinality) {
 	priority, found := collectors.CollectorPriorities[source]
 	if !found {
 		log.Warnf("Tagger: %s collector has no defined priority, assuming low", source)
@@ -198,9 +355,9 @@ func insertWithPriority(tagPrioMapper map[string][]tagPriority, tags []string, s
 	for _, t := range tags {
 		tagName := strings.Split(t, ":")[0]
 		tagPrioMapper[tagName] = append(tagPrioMapper[tagName], tagPriority{
-			tag:        t,
-			priority:   priority,
-			isHighCard: isHighCard,
+			tag:         t,
+			priority:    priority,
+			cardinality: cardinality,
 		})
 	}
 }
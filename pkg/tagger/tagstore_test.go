@@ -7,6 +7,7 @@ package tagger
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -41,6 +42,7 @@ func (s *StoreTestSuite) TestIngest() {
 
 	assert.Len(s.T(), s.store.store, 1)
 	assert.Len(s.T(), s.store.store["test"].lowCardTags, 2)
+	assert.Len(s.T(), s.store.store["test"].orchestratorCardTags, 2)
 	assert.Len(s.T(), s.store.store["test"].highCardTags, 2)
 }
 
@@ -57,8 +59,8 @@ func (s *StoreTestSuite) TestLookup() {
 		LowCardTags: []string{"tag"},
 	})
 
-	tagsHigh, sourcesHigh := s.store.lookup("test", true)
-	tagsLow, sourcesLow := s.store.lookup("test", false)
+	tagsHigh, sourcesHigh := s.store.lookup("test", collectors.HighCardinality)
+	tagsLow, sourcesLow := s.store.lookup("test", collectors.LowCardinality)
 
 	assert.Len(s.T(), tagsHigh, 3)
 	assert.Len(s.T(), tagsLow, 2)
@@ -73,7 +75,7 @@ func (s *StoreTestSuite) TestLookup() {
 }
 
 func (s *StoreTestSuite) TestLookupNotPresent() {
-	tags, sources := s.store.lookup("test", false)
+	tags, sources := s.store.lookup("test", collectors.LowCardinality)
 	assert.Nil(s.T(), tags)
 	assert.Nil(s.T(), sources)
 }
@@ -114,10 +116,10 @@ func (s *StoreTestSuite) TestPrune() {
 	s.store.toDeleteMutex.RUnlock()
 
 	// Data should still be in the store
-	tagsHigh, sourcesHigh := s.store.lookup("test1", true)
+	tagsHigh, sourcesHigh := s.store.lookup("test1", collectors.HighCardinality)
 	assert.Len(s.T(), tagsHigh, 3)
 	assert.Len(s.T(), sourcesHigh, 2)
-	tagsHigh, sourcesHigh = s.store.lookup("test2", true)
+	tagsHigh, sourcesHigh = s.store.lookup("test2", collectors.HighCardinality)
 	assert.Len(s.T(), tagsHigh, 2)
 	assert.Len(s.T(), sourcesHigh, 1)
 
@@ -129,10 +131,10 @@ func (s *StoreTestSuite) TestPrune() {
 	s.store.toDeleteMutex.RUnlock()
 
 	// test1 should be removed, test2 still present
-	tagsHigh, sourcesHigh = s.store.lookup("test1", true)
+	tagsHigh, sourcesHigh = s.store.lookup("test1", collectors.HighCardinality)
 	assert.Nil(s.T(), tagsHigh)
 	assert.Nil(s.T(), sourcesHigh)
-	tagsHigh, sourcesHigh = s.store.lookup("test2", true)
+	tagsHigh, sourcesHigh = s.store.lookup("test2", collectors.HighCardinality)
 	assert.Len(s.T(), tagsHigh, 2)
 	assert.Len(s.T(), sourcesHigh, 1)
 
@@ -140,49 +142,141 @@ func (s *StoreTestSuite) TestPrune() {
 	assert.Nil(s.T(), err)
 
 	// No impact if nothing is queued
-	tagsHigh, sourcesHigh = s.store.lookup("test1", true)
+	tagsHigh, sourcesHigh = s.store.lookup("test1", collectors.HighCardinality)
 	assert.Nil(s.T(), tagsHigh)
 	assert.Nil(s.T(), sourcesHigh)
-	tagsHigh, sourcesHigh = s.store.lookup("test2", true)
+	tagsHigh, sourcesHigh = s.store.lookup("test2", collectors.HighCardinality)
 	assert.Len(s.T(), tagsHigh, 2)
 	assert.Len(s.T(), sourcesHigh, 1)
 
 }
 
+func (s *StoreTestSuite) TestPruneExpired() {
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "test1",
+		LowCardTags: []string{"tag1"},
+		CacheTTL:    time.Minute,
+	})
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source2",
+		Entity:      "test1",
+		LowCardTags: []string{"tag2"},
+	})
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "test2",
+		LowCardTags: []string{"tag1"},
+		CacheTTL:    time.Minute,
+	})
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "test3",
+		LowCardTags: []string{"tag1"},
+		CacheTTL:    time.Hour,
+	})
+
+	// Nothing expired yet
+	changes := s.store.pruneExpired(time.Now())
+	assert.Len(s.T(), changes, 0)
+
+	changes = s.store.pruneExpired(time.Now().Add(2 * time.Minute))
+	assert.Len(s.T(), changes, 2)
+
+	// test1 lost source1, test2 is deleted, test3 is untouched
+	tags, sources := s.store.lookup("test1", collectors.LowCardinality)
+	assert.Equal(s.T(), []string{"tag2"}, tags)
+	assert.Equal(s.T(), []string{"source2"}, sources)
+	tags, sources = s.store.lookup("test2", collectors.LowCardinality)
+	assert.Nil(s.T(), tags)
+	assert.Nil(s.T(), sources)
+	tags, _ = s.store.lookup("test3", collectors.LowCardinality)
+	assert.Equal(s.T(), []string{"tag1"}, tags)
+
+	// Refreshing without TTL removes the expiry date
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "test3",
+		LowCardTags: []string{"tag1"},
+	})
+	changes = s.store.pruneExpired(time.Now().Add(2 * time.Hour))
+	assert.Len(s.T(), changes, 0)
+	tags, _ = s.store.lookup("test3", collectors.LowCardinality)
+	assert.Equal(s.T(), []string{"tag1"}, tags)
+}
+
+func (s *StoreTestSuite) TestList() {
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:               "source1",
+		Entity:               "docker://abc",
+		LowCardTags:          []string{"low"},
+		OrchestratorCardTags: []string{"orchestrator"},
+		HighCardTags:         []string{"high"},
+	})
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source2",
+		Entity:      "docker://abc",
+		LowCardTags: []string{"low2"},
+	})
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "kubernetes_pod://def",
+		LowCardTags: []string{"pod"},
+	})
+
+	all := s.store.list("")
+	assert.Len(s.T(), all.Entities, 2)
+
+	docker := s.store.list("docker://")
+	assert.Len(s.T(), docker.Entities, 1)
+	tags := docker.Entities["docker://abc"].Tags
+	assert.Len(s.T(), tags, 2)
+	assert.ElementsMatch(s.T(), []string{"low", "orchestrator", "high"}, tags["source1"])
+	assert.ElementsMatch(s.T(), []string{"low2"}, tags["source2"])
+
+	assert.Len(s.T(), s.store.list("unknown://").Entities, 0)
+}
+
 func TestStoreSuite(t *testing.T) {
 	suite.Run(t, &StoreTestSuite{})
 }
 
 func TestGetEntityTags(t *testing.T) {
 	etags := entityTags{
-		lowCardTags:  make(map[string][]string),
-		highCardTags: make(map[string][]string),
-		cacheValid:   false,
+		lowCardTags:          make(map[string][]string),
+		orchestratorCardTags: make(map[string][]string),
+		highCardTags:         make(map[string][]string),
+		cacheValid:           false,
 	}
 	assert.False(t, etags.cacheValid)
 
 	// Get empty tags and make sure cache is now set to valid
-	tags, sources := etags.get(true)
+	tags, sources := etags.get(collectors.HighCardinality)
 	assert.Len(t, tags, 0)
 	assert.Len(t, sources, 0)
 	assert.True(t, etags.cacheValid)
 
 	// Add tags but don't invalidate the cache, we should return empty arrays
 	etags.lowCardTags["source"] = []string{"low1", "low2"}
+	etags.orchestratorCardTags["source"] = []string{"orchestrator1"}
 	etags.highCardTags["source"] = []string{"high1", "high2"}
-	tags, sources = etags.get(true)
+	tags, sources = etags.get(collectors.HighCardinality)
 	assert.Len(t, tags, 0)
 	assert.Len(t, sources, 0)
 	assert.True(t, etags.cacheValid)
 
 	// Invalidate the cache, we should now get the tags
 	etags.cacheValid = false
-	tags, sources = etags.get(true)
-	assert.Len(t, tags, 4)
-	assert.ElementsMatch(t, tags, []string{"low1", "low2", "high1", "high2"})
+	tags, sources = etags.get(collectors.HighCardinality)
+	assert.Len(t, tags, 5)
+	assert.ElementsMatch(t, tags, []string{"low1", "low2", "orchestrator1", "high1", "high2"})
 	assert.Len(t, sources, 1)
 	assert.True(t, etags.cacheValid)
-	tags, sources = etags.get(false)
+	tags, sources = etags.get(collectors.OrchestratorCardinality)
+	assert.Len(t, tags, 3)
+	assert.ElementsMatch(t, tags, []string{"low1", "low2", "orchestrator1"})
+	assert.Len(t, sources, 1)
+	tags, sources = etags.get(collectors.LowCardinality)
 	assert.Len(t, tags, 2)
 	assert.ElementsMatch(t, tags, []string{"low1", "low2"})
 	assert.Len(t, sources, 1)
@@ -190,14 +284,15 @@ func TestGetEntityTags(t *testing.T) {
 
 func TestDuplicateSourceTags(t *testing.T) {
 	etags := entityTags{
-		lowCardTags:  make(map[string][]string),
-		highCardTags: make(map[string][]string),
-		cacheValid:   false,
+		lowCardTags:          make(map[string][]string),
+		orchestratorCardTags: make(map[string][]string),
+		highCardTags:         make(map[string][]string),
+		cacheValid:           false,
 	}
 	assert.False(t, etags.cacheValid)
 
 	// Get empty tags and make sure cache is now set to valid
-	tags, sources := etags.get(true)
+	tags, sources := etags.get(collectors.HighCardinality)
 	assert.Len(t, tags, 0)
 	assert.Len(t, sources, 0)
 	assert.True(t, etags.cacheValid)
@@ -216,19 +311,19 @@ func TestDuplicateSourceTags(t *testing.T) {
 	etags.highCardTags["sourceNodeOrchestrator"] = []string{"tag3:sourceHigh", "tag4:sourceHigh"}
 	etags.highCardTags["sourceClusterOrchestrator"] = []string{"tag4:sourceClusterLow"}
 	etags.lowCardTags["sourceClusterOrchestrator"] = []string{"tag3:sourceClusterHigh", "tag1:sourceClusterLow"}
-	tags, sources = etags.get(true)
+	tags, sources = etags.get(collectors.HighCardinality)
 	assert.Len(t, tags, 0)
 	assert.Len(t, sources, 0)
 	assert.True(t, etags.cacheValid)
 
 	// Invalidate the cache, we should now get the tags
 	etags.cacheValid = false
-	tags, sources = etags.get(true)
+	tags, sources = etags.get(collectors.HighCardinality)
 	assert.Len(t, tags, 7)
 	assert.ElementsMatch(t, tags, []string{"foo", "bar", "tag1:sourceClusterLow", "tag2:sourceHigh", "tag3:sourceClusterHigh", "tag4:sourceClusterLow", "tag5:sourceLow"})
 	assert.Len(t, sources, 3)
 	assert.True(t, etags.cacheValid)
-	tags, sources = etags.get(false)
+	tags, sources = etags.get(collectors.LowCardinality)
 	assert.Len(t, sources, 3)
 	assert.Len(t, tags, 5)
 	assert.ElementsMatch(t, tags, []string{"foo", "bar", "tag1:sourceClusterLow", "tag2:sourceHigh", "tag3:sourceClusterHigh"})
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// metadataNamePlaceholder can be used in a tag name to insert the name of the
+// label or annotation matched by a glob, e.g. `app.kubernetes.io/*: kube_%%label%%`
+const metadataNamePlaceholder = "%%label%%"
+
+// MetadataAsTags holds the user-provided mapping of labels or annotations to tags.
+// Exact names are looked up directly, names containing a `*` wildcard are
+// matched as globs.
+type MetadataAsTags struct {
+	exact map[string]string
+	globs map[string]*regexp.Regexp
+	names map[string]string // glob name to tag name
+}
+
+// NewMetadataAsTags lower-cases the names of the user-provided mapping and
+// splits it between exact names and glob patterns
+func NewMetadataAsTags(mapping map[string]string) *MetadataAsTags {
+	m := &MetadataAsTags{
+		exact: make(map[string]string),
+		globs: make(map[string]*regexp.Regexp),
+		names: make(map[string]string),
+	}
+	for name, tagName := range mapping {
+		name = strings.ToLower(name)
+		if !strings.Contains(name, "*") {
+			m.exact[name] = tagName
+			continue
+		}
+		m.globs[name] = globToRegexp(name)
+		m.names[name] = tagName
+	}
+	return m
+}
+
+// AddTags adds a tag to the list if the metadata name matches the mapping.
+// Exact matches take precedence over globs.
+func (m *MetadataAsTags) AddTags(name, value string, tags *TagList) {
+	if m == nil {
+		return
+	}
+	name = strings.ToLower(name)
+	if tagName, found := m.exact[name]; found {
+		tags.AddAuto(tagName, value)
+		return
+	}
+	for glob, re := range m.globs {
+		if re.MatchString(name) {
+			tagName := strings.Replace(m.names[glob], metadataNamePlaceholder, name, -1)
+			tags.AddAuto(tagName, value)
+		}
+	}
+}
+
+// globToRegexp compiles a glob pattern where `*` matches any string
+func globToRegexp(glob string) *regexp.Regexp {
+	pattern := strings.Replace(regexp.QuoteMeta(glob), `\*`, ".*", -1)
+	return regexp.MustCompile("^" + pattern + "$")
+}
@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataAsTags(t *testing.T) {
+	m := NewMetadataAsTags(map[string]string{
+		"App":                    "kube_app",
+		"app.kubernetes.io/*":    "kube_%%label%%",
+		"team-*":                 "+team",
+		"pod-template-hash":      "+kube_pod-template-hash",
+		"app.kubernetes.io/name": "kube_app_name",
+	})
+
+	tags := NewTagList()
+	m.AddTags("app", "redis", tags)
+	m.AddTags("APP.kubernetes.io/name", "redis-master", tags)
+	m.AddTags("app.kubernetes.io/version", "4.0", tags)
+	m.AddTags("team-owner", "containers", tags)
+	m.AddTags("pod-template-hash", "490794276", tags)
+	m.AddTags("unknown", "value", tags)
+
+	low, _, high := tags.Compute()
+	assert.ElementsMatch(t, []string{
+		"kube_app:redis",
+		"kube_app_name:redis-master",
+		"kube_app.kubernetes.io/version:4.0",
+	}, low)
+	assert.ElementsMatch(t, []string{
+		"team:containers",
+		"kube_pod-template-hash:490794276",
+	}, high)
+}
+
+func TestMetadataAsTagsNil(t *testing.T) {
+	var m *MetadataAsTags
+	tags := NewTagList()
+	m.AddTags("app", "redis", tags)
+	low, orchestrator, high := tags.Compute()
+	assert.Empty(t, low)
+	assert.Empty(t, orchestrator)
+	assert.Empty(t, high)
+}
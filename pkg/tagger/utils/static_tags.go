@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StaticTagsRule holds a rule of the `tagger_static_tags` option: the tags are
+// added to the entities matching all the selectors that are set.
+type StaticTagsRule struct {
+	Image     string            `mapstructure:"image"`     // glob matched against the full image name
+	Namespace string            `mapstructure:"namespace"` // glob matched against the kubernetes namespace
+	Labels    map[string]string `mapstructure:"labels"`    // labels the entity must have, with the same values
+	Tags      []string          `mapstructure:"tags"`      // tags in the "name:value" format
+}
+
+type staticTagsMatcher struct {
+	image     *regexp.Regexp
+	namespace *regexp.Regexp
+	labels    map[string]string
+	tags      []string
+}
+
+// StaticTags matches entities against the rules of the `tagger_static_tags`
+// option and adds the extra tags of the matching rules.
+type StaticTags struct {
+	matchers []staticTagsMatcher
+}
+
+// NewStaticTags compiles the rules selectors. Rules without tags and rules
+// without any selector, that would match every entity, are ignored.
+func NewStaticTags(rules []StaticTagsRule) *StaticTags {
+	s := &StaticTags{}
+	for _, rule := range rules {
+		if len(rule.Tags) == 0 {
+			continue
+		}
+		if rule.Image == "" && rule.Namespace == "" && len(rule.Labels) == 0 {
+			continue
+		}
+		m := staticTagsMatcher{
+			labels: rule.Labels,
+			tags:   rule.Tags,
+		}
+		if rule.Image != "" {
+			m.image = globToRegexp(rule.Image)
+		}
+		if rule.Namespace != "" {
+			m.namespace = globToRegexp(rule.Namespace)
+		}
+		s.matchers = append(s.matchers, m)
+	}
+	return s
+}
+
+// AddTags adds the tags of every rule matching the entity metadata as low
+// cardinality tags. Empty metadata does not match rules selecting on it.
+func (s *StaticTags) AddTags(image, namespace string, labels map[string]string, tags *TagList) {
+	if s == nil {
+		return
+	}
+	for _, m := range s.matchers {
+		if !m.matches(image, namespace, labels) {
+			continue
+		}
+		for _, tag := range m.tags {
+			parts := strings.SplitN(tag, ":", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			tags.AddLow(parts[0], parts[1])
+		}
+	}
+}
+
+func (m *staticTagsMatcher) matches(image, namespace string, labels map[string]string) bool {
+	if m.image != nil && (image == "" || !m.image.MatchString(image)) {
+		return false
+	}
+	if m.namespace != nil && (namespace == "" || !m.namespace.MatchString(namespace)) {
+		return false
+	}
+	for name, value := range m.labels {
+		if v, found := labels[name]; !found || v != value {
+			return false
+		}
+	}
+	return true
+}
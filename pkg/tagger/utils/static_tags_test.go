@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticTags(t *testing.T) {
+	staticTags := NewStaticTags([]StaticTagsRule{
+		{
+			Image: "redis*",
+			Tags:  []string{"team:cache"},
+		},
+		{
+			Namespace: "payments-*",
+			Labels:    map[string]string{"tier": "frontend"},
+			Tags:      []string{"team:payments", "owner:alice"},
+		},
+		{
+			Labels: map[string]string{"app": "web"},
+			Tags:   []string{"service:web", "invalid"},
+		},
+		{
+			// no selector, ignored
+			Tags: []string{"everything:yes"},
+		},
+	})
+
+	for _, tc := range []struct {
+		name      string
+		image     string
+		namespace string
+		labels    map[string]string
+		expected  []string
+	}{
+		{
+			name:     "no metadata",
+			expected: []string{},
+		},
+		{
+			name:     "image glob",
+			image:    "redis:3.2",
+			expected: []string{"team:cache"},
+		},
+		{
+			name:     "image not matching",
+			image:    "library/redis:3.2",
+			expected: []string{},
+		},
+		{
+			name:      "namespace and labels",
+			namespace: "payments-prod",
+			labels:    map[string]string{"tier": "frontend", "app": "web"},
+			expected:  []string{"team:payments", "owner:alice", "service:web"},
+		},
+		{
+			name:      "label value not matching",
+			namespace: "payments-prod",
+			labels:    map[string]string{"tier": "backend"},
+			expected:  []string{},
+		},
+		{
+			name:     "namespace missing",
+			labels:   map[string]string{"tier": "frontend"},
+			expected: []string{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tags := NewTagList()
+			staticTags.AddTags(tc.image, tc.namespace, tc.labels, tags)
+			low, orchestrator, high := tags.Compute()
+			assert.ElementsMatch(t, tc.expected, low)
+			assert.Len(t, orchestrator, 0)
+			assert.Len(t, high, 0)
+		})
+	}
+}
+
+func TestStaticTagsNil(t *testing.T) {
+	var staticTags *StaticTags
+	tags := NewTagList()
+	staticTags.AddTags("redis", "default", nil, tags)
+	low, _, _ := tags.Compute()
+	assert.Len(t, low, 0)
+}
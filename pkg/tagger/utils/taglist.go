@@ -13,15 +13,17 @@ import (
 // TagList allows collector to incremental build a tag list
 // then export it easily to []string format
 type TagList struct {
-	lowCardTags  map[string]bool
-	highCardTags map[string]bool
+	lowCardTags          map[string]bool
+	orchestratorCardTags map[string]bool
+	highCardTags         map[string]bool
 }
 
 // NewTagList creates a new object ready to use
 func NewTagList() *TagList {
 	return &TagList{
-		lowCardTags:  make(map[string]bool),
-		highCardTags: make(map[string]bool),
+		lowCardTags:          make(map[string]bool),
+		orchestratorCardTags: make(map[string]bool),
+		highCardTags:         make(map[string]bool),
 	}
 }
 
@@ -33,6 +35,14 @@ func (l *TagList) AddHigh(name string, value string) {
 	}
 }
 
+// AddOrchestrator adds a new orchestrator-level cardinality tag to the map, or replace if already exists.
+// It will skip empty values/names, so it's safe to use without verifying the value is not empty.
+func (l *TagList) AddOrchestrator(name string, value string) {
+	if name != "" && value != "" {
+		l.orchestratorCardTags[fmt.Sprintf("%s:%s", name, value)] = true
+	}
+}
+
 // AddLow adds a new low cardinality tag to the list, or replace if already exists.
 // It will skip empty values/names, so it's safe to use without verifying the value is not empty.
 func (l *TagList) AddLow(name string, value string) {
@@ -51,21 +61,20 @@ func (l *TagList) AddAuto(name, value string) {
 	l.AddLow(name, value)
 }
 
-// Compute returns two string arrays in the format "tag:value"
-// first array is low cardinality tags, second is high card ones
-func (l *TagList) Compute() ([]string, []string) {
-	low := make([]string, len(l.lowCardTags))
-	high := make([]string, len(l.highCardTags))
+// Compute returns three string arrays in the format "tag:value"
+// - low cardinality
+// - orchestrator cardinality
+// - high cardinality
+func (l *TagList) Compute() ([]string, []string, []string) {
+	return toSlice(l.lowCardTags), toSlice(l.orchestratorCardTags), toSlice(l.highCardTags)
+}
 
+func toSlice(m map[string]bool) []string {
+	s := make([]string, len(m))
 	index := 0
-	for tag := range l.lowCardTags {
-		low[index] = tag
-		index++
-	}
-	index = 0
-	for tag := range l.highCardTags {
-		high[index] = tag
+	for tag := range m {
+		s[index] = tag
 		index++
 	}
-	return low, high
+	return s
 }
@@ -15,10 +15,13 @@ func TestNewTagList(t *testing.T) {
 	list := NewTagList()
 	require.NotNil(t, list)
 	require.NotNil(t, list.lowCardTags)
+	require.NotNil(t, list.orchestratorCardTags)
 	require.NotNil(t, list.highCardTags)
-	low, high := list.Compute()
+	low, orchestrator, high := list.Compute()
 	require.NotNil(t, low)
 	require.Empty(t, low)
+	require.NotNil(t, orchestrator)
+	require.Empty(t, orchestrator)
 	require.NotNil(t, high)
 	require.Empty(t, high)
 }
@@ -51,6 +54,21 @@ func TestAddHigh(t *testing.T) {
 	require.False(t, list.highCardTags["empty"])
 }
 
+func TestAddOrchestrator(t *testing.T) {
+	list := NewTagList()
+	list.AddOrchestrator("foo", "bar")
+	list.AddOrchestrator("faa", "baz")
+	list.AddOrchestrator("empty", "")
+	require.Empty(t, list.lowCardTags)
+	require.Empty(t, list.highCardTags)
+	require.Len(t, list.orchestratorCardTags, 2)
+	require.True(t, list.orchestratorCardTags["foo:bar"])
+	require.True(t, list.orchestratorCardTags["faa:baz"])
+
+	require.False(t, list.orchestratorCardTags["empty:"])
+	require.False(t, list.orchestratorCardTags["empty"])
+}
+
 func TestAddHighOrLow(t *testing.T) {
 	list := NewTagList()
 	list.AddAuto("foo", "bar")
@@ -71,6 +89,7 @@ func TestCompute(t *testing.T) {
 	list.AddHigh("foo", "bar")
 	list.AddLow("faa", "baz")
 	list.AddLow("low", "yes")
+	list.AddOrchestrator("orchestrator", "yes")
 	list.AddAuto("+high", "yes-high")
 	list.AddAuto("lowlow", "yes-low")
 	list.AddAuto("empty", "")
@@ -79,11 +98,13 @@ func TestCompute(t *testing.T) {
 	list.AddAuto("+", "empty")
 	list.AddAuto("", "")
 
-	low, high := list.Compute()
+	low, orchestrator, high := list.Compute()
 	require.Len(t, low, 3)
 	require.Contains(t, low, "faa:baz")
 	require.Contains(t, low, "low:yes")
 	require.Contains(t, low, "lowlow:yes-low")
+	require.Len(t, orchestrator, 1)
+	require.Contains(t, orchestrator, "orchestrator:yes")
 	require.Len(t, high, 2)
 	require.Contains(t, high, "foo:bar")
 	require.Contains(t, high, "high:yes-high")
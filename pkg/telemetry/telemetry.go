@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package telemetry exposes the internal metrics of the agent, published as
+// expvars by its components, in the Prometheus exposition format.
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	log "github.com/cihub/seelog"
+)
+
+// metricPrefix prefixes the names of the metrics
+const metricPrefix = "datadog_agent"
+
+// labelRule turns the keys of the maps at path into the values of label, and
+// the string fields of their entries into the values of fieldLabels. The
+// segments of path are separated by dots, `*` matches any key.
+type labelRule struct {
+	path        string
+	label       string
+	fieldLabels map[string]string
+}
+
+// source is an expvar exposed and the rules of its labels
+type source struct {
+	expvar string
+	labels []labelRule
+}
+
+// sources are the expvars exposed, the ones not published by the running
+// binary are skipped
+var sources = []source{
+	{expvar: "aggregator"},
+	{expvar: "forwarder"},
+	{expvar: "interner"},
+	{expvar: "memguard"},
+	{expvar: "autoprofile"},
+	{expvar: "dogstatsd"},
+	{expvar: "dogstatsd-udp"},
+	{expvar: "dogstatsd-uds"},
+	{expvar: "otlp"},
+	{expvar: "snmp-traps"},
+	{expvar: "logs-processor", labels: []labelRule{
+		{path: "SampledOutLines", label: "source"},
+		{path: "RateLimitedLines", label: "source"},
+	}},
+	{expvar: "logs-disk-buffer"},
+	{expvar: "runner", labels: []labelRule{
+		{path: "Checks", label: "check_id", fieldLabels: map[string]string{"CheckName": "check_name"}},
+	}},
+	{expvar: "scheduler"},
+	{expvar: "splitter"},
+}
+
+// metric is a sample of the exposition
+type metric struct {
+	name   string
+	labels []string
+	value  float64
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var metrics []metric
+		for _, s := range sources {
+			variable := expvar.Get(s.expvar)
+			if variable == nil {
+				continue
+			}
+			var value interface{}
+			if err := json.Unmarshal([]byte(variable.String()), &value); err != nil {
+				log.Warnf("Could not decode the expvar %s: %v", s.expvar, err)
+				continue
+			}
+			metrics = append(metrics, flatten(s, value)...)
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		write(w, metrics)
+	})
+}
+
+// flatten returns the numeric values of an expvar as metrics
+func flatten(s source, value interface{}) []metric {
+	var metrics []metric
+	var walk func(path []string, name []string, labels []string, value interface{})
+	walk = func(path []string, name []string, labels []string, value interface{}) {
+		switch v := value.(type) {
+		case float64:
+			metrics = append(metrics, metric{name: metricName(name), labels: labels, value: v})
+		case map[string]interface{}:
+			rule, labelled := matchRule(s.labels, path)
+			for _, key := range sortedKeys(v) {
+				childPath := append(append([]string{}, path...), key)
+				if !labelled {
+					walk(childPath, append(append([]string{}, name...), key), labels, v[key])
+					continue
+				}
+				childLabels := append(append([]string{}, labels...), label(rule.label, key))
+				if entry, ok := v[key].(map[string]interface{}); ok {
+					for _, field := range sortedFieldLabels(rule.fieldLabels) {
+						if fieldValue, ok := entry[field].(string); ok {
+							childLabels = append(childLabels, label(rule.fieldLabels[field], fieldValue))
+						}
+					}
+				}
+				walk(childPath, name, childLabels, v[key])
+			}
+		}
+		// the strings, the booleans and the arrays are not exposed
+	}
+	walk(nil, []string{s.expvar}, nil, value)
+	return metrics
+}
+
+// matchRule returns the rule of the map at path
+func matchRule(rules []labelRule, path []string) (labelRule, bool) {
+	for _, rule := range rules {
+		segments := strings.Split(rule.path, ".")
+		if len(segments) != len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range segments {
+			if segment != "*" && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule, true
+		}
+	}
+	return labelRule{}, false
+}
+
+// write writes the metrics sorted by name, with a type line for every name
+func write(w io.Writer, metrics []metric) {
+	sort.SliceStable(metrics, func(i, j int) bool { return metrics[i].name < metrics[j].name })
+	var buf bytes.Buffer
+	previous := ""
+	for _, m := range metrics {
+		if m.name != previous {
+			fmt.Fprintf(&buf, "# TYPE %s untyped\n", m.name)
+			previous = m.name
+		}
+		if len(m.labels) > 0 {
+			fmt.Fprintf(&buf, "%s{%s} %s\n", m.name, strings.Join(m.labels, ","), strconv.FormatFloat(m.value, 'f', -1, 64))
+		} else {
+			fmt.Fprintf(&buf, "%s %s\n", m.name, strconv.FormatFloat(m.value, 'f', -1, 64))
+		}
+	}
+	w.Write(buf.Bytes())
+}
+
+// metricName joins the segments of a name in snake case:
+// [forwarder Transactions RetryQueueSize] is datadog_agent_forwarder_transactions_retry_queue_size
+func metricName(segments []string) string {
+	parts := []string{metricPrefix}
+	for _, segment := range segments {
+		parts = append(parts, snakeCase(segment))
+	}
+	return strings.Join(parts, "_")
+}
+
+// snakeCase converts a CamelCase segment to snake case, the characters that
+// are not valid in a metric name are replaced by underscores
+func snakeCase(segment string) string {
+	var b bytes.Buffer
+	runes := []rune(segment)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// a new word starts at an upper case letter following a lower case
+			// one, or preceding one in an acronym: APIKeyStatus is api_key_status
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// labelValueEscaper escapes the values of the labels like the exposition format
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label formats a label, its value is quoted
+func label(name, value string) string {
+	return fmt.Sprintf(`%s="%s"`, name, labelValueEscaper.Replace(value))
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFieldLabels(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
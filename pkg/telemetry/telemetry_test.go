@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnakeCase(t *testing.T) {
+	for segment, expected := range map[string]string{
+		"RetryQueueSize":              "retry_queue_size",
+		"APIKeyStatus":                "api_key_status",
+		"MetricPackets":               "metric_packets",
+		"dogstatsd-udp":               "dogstatsd_udp",
+		"Flush2xx":                    "flush2xx",
+		"logs-processor":              "logs_processor",
+		"IntakeV1":                    "intake_v1",
+		"already_snake":               "already_snake",
+		"ChecksMetricSampleFlushTime": "checks_metric_sample_flush_time",
+	} {
+		assert.Equal(t, expected, snakeCase(segment), segment)
+	}
+}
+
+func decode(t *testing.T, data string) interface{} {
+	var value interface{}
+	require.Nil(t, json.Unmarshal([]byte(data), &value))
+	return value
+}
+
+func TestFlatten(t *testing.T) {
+	s := source{expvar: "runner", labels: []labelRule{
+		{path: "Checks", label: "check_id", fieldLabels: map[string]string{"CheckName": "check_name"}},
+	}}
+	value := decode(t, `{
+		"Workers": 4,
+		"Running": true,
+		"Checks": {
+			"cpu:abc": {"CheckName": "cpu", "TotalRuns": 12, "LastError": "", "ExecutionTimes": [1, 2]}
+		}
+	}`)
+
+	var buf bytes.Buffer
+	write(&buf, flatten(s, value))
+	assert.Equal(t, `# TYPE datadog_agent_runner_checks_total_runs untyped
+datadog_agent_runner_checks_total_runs{check_id="cpu:abc",check_name="cpu"} 12
+# TYPE datadog_agent_runner_workers untyped
+datadog_agent_runner_workers 4
+`, buf.String())
+}
+
+func TestFlattenEscapesLabels(t *testing.T) {
+	s := source{expvar: "logs-processor", labels: []labelRule{{path: "SampledOutLines", label: "source"}}}
+	value := decode(t, `{"SampledOutLines": {"my \"app\"": 3, "nginx": 1500000}}`)
+
+	var buf bytes.Buffer
+	write(&buf, flatten(s, value))
+	assert.Equal(t, `# TYPE datadog_agent_logs_processor_sampled_out_lines untyped
+datadog_agent_logs_processor_sampled_out_lines{source="my \"app\""} 3
+datadog_agent_logs_processor_sampled_out_lines{source="nginx"} 1500000
+`, buf.String())
+}
+
+func TestHandler(t *testing.T) {
+	dogstatsd := expvar.Get("dogstatsd")
+	if dogstatsd == nil {
+		dogstatsd = expvar.NewMap("dogstatsd")
+	}
+	dogstatsd.(*expvar.Map).Add("MetricPackets", 7)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/telemetry", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "datadog_agent_dogstatsd_metric_packets 7\n")
+}
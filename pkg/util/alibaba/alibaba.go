@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package alibaba queries the metadata endpoint of the Alibaba Cloud ECS
+// instances.
+package alibaba
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// declare these as vars not const to ease testing
+var (
+	metadataURL = "http://100.100.100.200"
+	timeout     = 300 * time.Millisecond
+)
+
+// GetHostAlias returns the ID of the instance from the Alibaba Cloud metadata api
+func GetHostAlias() (string, error) {
+	instanceID, err := getResponse(metadataURL + "/latest/meta-data/instance-id")
+	if err != nil {
+		return "", fmt.Errorf("Alibaba HostAliases: unable to query metadata endpoint: %s", err)
+	}
+	return instanceID, nil
+}
+
+// GetTags returns the region, the zone and the type of the instance from the
+// Alibaba Cloud metadata api
+func GetTags() ([]string, error) {
+	tags := []string{}
+	for _, t := range []struct {
+		name string
+		path string
+	}{
+		{"region", "/latest/meta-data/region-id"},
+		{"zone", "/latest/meta-data/zone-id"},
+		{"instance-type", "/latest/meta-data/instance/instance-type"},
+	} {
+		value, err := getResponse(metadataURL + t.path)
+		if err != nil {
+			return nil, fmt.Errorf("Alibaba Tags: unable to query metadata endpoint: %s", err)
+		}
+		if value != "" {
+			tags = append(tags, fmt.Sprintf("%s:%s", t.name, value))
+		}
+	}
+	return tags, nil
+}
+
+func getResponse(url string) (string, error) {
+	client := http.Client{
+		Timeout: timeout,
+	}
+
+	res, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return "", fmt.Errorf("status code %d trying to GET %s", res.StatusCode, url)
+	}
+
+	all, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("error while reading response from alibaba metadata endpoint: %s", err)
+	}
+	return strings.TrimSpace(string(all)), nil
+}
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package alibaba
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHostAlias(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "i-bp1dgezbxqxvjzp2uzsd")
+		lastRequest = r
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	val, err := GetHostAlias()
+	assert.Nil(t, err)
+	assert.Equal(t, "i-bp1dgezbxqxvjzp2uzsd", val)
+	assert.Equal(t, "/latest/meta-data/instance-id", lastRequest.URL.Path)
+}
+
+func TestGetTags(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/meta-data/region-id":
+			io.WriteString(w, "cn-hangzhou")
+		case "/latest/meta-data/zone-id":
+			io.WriteString(w, "cn-hangzhou-i")
+		case "/latest/meta-data/instance/instance-type":
+			io.WriteString(w, "ecs.g6.large")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	tags, err := GetTags()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"region:cn-hangzhou", "zone:cn-hangzhou-i", "instance-type:ecs.g6.large"}, tags)
+}
+
+func TestGetHostAliasNotOnAlibaba(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	_, err := GetHostAlias()
+	assert.NotNil(t, err)
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -34,6 +35,53 @@ func GetHostAlias() (string, error) {
 	return string(all), nil
 }
 
+// GetTags returns the tags of the VM from the Azure Metadata api, the
+// "key:value" pairs are separated by semicolons in the response
+func GetTags() ([]string, error) {
+	res, err := getResponse(metadataURL + "/metadata/instance/compute/tags?api-version=2017-08-01&format=text")
+	if err != nil {
+		return nil, fmt.Errorf("Azure Tags: unable to query metadata endpoint: %s", err)
+	}
+
+	defer res.Body.Close()
+	all, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading response from azure metadata endpoint: %s", err)
+	}
+
+	tags := []string{}
+	for _, tag := range strings.Split(string(all), ";") {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// GetClusterName returns the name of the AKS cluster of the VM, from its resource
+// group named MC_<resource group>_<cluster name>_<location> by AKS.
+func GetClusterName() (string, error) {
+	res, err := getResponse(metadataURL + "/metadata/instance/compute/resourceGroupName?api-version=2017-08-01&format=text")
+	if err != nil {
+		return "", fmt.Errorf("Azure ClusterName: unable to query metadata endpoint: %s", err)
+	}
+
+	defer res.Body.Close()
+	all, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("error while reading response from azure metadata endpoint: %s", err)
+	}
+	return parseClusterName(string(all))
+}
+
+func parseClusterName(resourceGroup string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(resourceGroup), "_")
+	if len(parts) < 4 || strings.ToLower(parts[0]) != "mc" {
+		return "", fmt.Errorf("cannot parse the cluster name from the resource group %q, it is not an AKS one", resourceGroup)
+	}
+	return parts[len(parts)-2], nil
+}
+
 func getResponse(url string) (*http.Response, error) {
 	client := http.Client{
 		Timeout: timeout,
@@ -31,3 +31,39 @@ func TestGetHostname(t *testing.T) {
 	assert.Equal(t, lastRequest.URL.Path, "/metadata/instance/compute/vmId")
 	assert.Equal(t, lastRequest.URL.RawQuery, "api-version=2017-04-02&format=text")
 }
+
+func TestGetTags(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "env:prod;team:payments;")
+		lastRequest = r
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	tags, err := GetTags()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"env:prod", "team:payments"}, tags)
+	assert.Equal(t, lastRequest.URL.Path, "/metadata/instance/compute/tags")
+	assert.Equal(t, lastRequest.URL.RawQuery, "api-version=2017-08-01&format=text")
+}
+
+func TestGetClusterName(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "MC_my-rg_prod-cluster_westeurope")
+		lastRequest = r
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	name, err := GetClusterName()
+	assert.Nil(t, err)
+	assert.Equal(t, "prod-cluster", name)
+	assert.Equal(t, lastRequest.URL.Path, "/metadata/instance/compute/resourceGroupName")
+
+	_, err = parseClusterName("my-rg")
+	assert.NotNil(t, err)
+}
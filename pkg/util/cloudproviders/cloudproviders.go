@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package cloudproviders detects the cloud provider the agent runs on and
+// collects the host aliases and the host tags it exposes. EC2 is not part of
+// it: its hostname and instance ID have their own fields in the host metadata.
+package cloudproviders
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/alibaba"
+	"github.com/DataDog/datadog-agent/pkg/util/azure"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/gce"
+	"github.com/DataDog/datadog-agent/pkg/util/ibm"
+	"github.com/DataDog/datadog-agent/pkg/util/oracle"
+)
+
+// CloudProvider is a cloud provider, queried through its metadata endpoint
+type CloudProvider interface {
+	// Name is the name of the cloud provider, used in the logs
+	Name() string
+	// GetHostAlias returns the alias of the host, usually the ID of the
+	// instance, or an empty string when the provider has none
+	GetHostAlias() (string, error)
+	// GetHostTags returns the tags of the host, nil when the provider
+	// reports none
+	GetHostTags() ([]string, error)
+}
+
+// provider is a CloudProvider built from the functions of a cloud package,
+// getTags is nil for the providers without host tags. The instance ID of the
+// hostIdentity providers is the hostname of the agent, see GetHostname.
+type provider struct {
+	name         string
+	getAlias     func() (string, error)
+	getTags      func() ([]string, error)
+	hostIdentity bool
+}
+
+func (p provider) Name() string {
+	return p.name
+}
+
+func (p provider) GetHostAlias() (string, error) {
+	if p.getAlias == nil {
+		return "", nil
+	}
+	return p.getAlias()
+}
+
+func (p provider) GetHostTags() ([]string, error) {
+	if p.getTags == nil {
+		return nil, nil
+	}
+	return p.getTags()
+}
+
+// getAzureTags returns the Azure tags when `collect_azure_tags` is enabled
+func getAzureTags() ([]string, error) {
+	if !config.Datadog.GetBool("collect_azure_tags") {
+		return nil, nil
+	}
+	return azure.GetTags()
+}
+
+// getOracleTags returns the Oracle Cloud tags, with the freeform tags when
+// `collect_oracle_tags` is enabled
+func getOracleTags() ([]string, error) {
+	return oracle.GetTags(config.Datadog.GetBool("collect_oracle_tags"))
+}
+
+var (
+	// providers are the cloud providers queried, in order. The tags of GCE
+	// have their own field in the host metadata, they are not collected here.
+	providers = []CloudProvider{
+		provider{name: "Azure", getAlias: azure.GetHostAlias, getTags: getAzureTags},
+		provider{name: "GCE", getAlias: gce.GetHostAlias},
+		provider{name: "Alibaba Cloud", getAlias: alibaba.GetHostAlias, getTags: alibaba.GetTags, hostIdentity: true},
+		provider{name: "Oracle Cloud", getAlias: oracle.GetHostAlias, getTags: getOracleTags, hostIdentity: true},
+		provider{name: "IBM Cloud", getAlias: ibm.GetHostAlias, getTags: ibm.GetTags, hostIdentity: true},
+	}
+	m sync.RWMutex
+)
+
+// RegisterCloudProvider adds a cloud provider, queried after the ones already
+// registered
+func RegisterCloudProvider(p CloudProvider) {
+	m.Lock()
+	defer m.Unlock()
+	providers = append(providers, p)
+}
+
+func getProviders() []CloudProvider {
+	m.RLock()
+	defer m.RUnlock()
+	return append([]CloudProvider{}, providers...)
+}
+
+// detection is the outcome of the detection of a cloud provider
+type detection struct {
+	detected bool
+	alias    string
+}
+
+// detect returns whether a cloud provider is detected, from the query of its
+// host alias. The outcome is cached for the life of the agent, so that the
+// metadata endpoints of the other clouds are not queried on every run.
+func detect(p CloudProvider) detection {
+	cacheKey := cache.BuildAgentKey("cloudproviders", p.Name())
+	if d, found := cache.Cache.Get(cacheKey); found {
+		return d.(detection)
+	}
+
+	alias, err := p.GetHostAlias()
+	if err != nil {
+		log.Debugf("no %s Host Alias: %s", p.Name(), err)
+	}
+	d := detection{detected: err == nil, alias: alias}
+	cache.Cache.Set(cacheKey, d, cache.NoExpiration)
+	return d
+}
+
+// GetHostAliases returns the host aliases of the cloud providers detected,
+// the providers which are not detected are skipped
+func GetHostAliases() []string {
+	aliases := []string{}
+	for _, p := range getProviders() {
+		if d := detect(p); d.detected && d.alias != "" {
+			aliases = append(aliases, d.alias)
+		}
+	}
+	return aliases
+}
+
+// GetHostname returns the instance ID of the first cloud provider detected
+// that identifies the host by it, the agents on these clouds use it instead of
+// the OS hostname
+func GetHostname() (string, error) {
+	for _, p := range getProviders() {
+		if pr, ok := p.(provider); !ok || !pr.hostIdentity {
+			continue
+		}
+		if d := detect(p); d.detected && d.alias != "" {
+			return d.alias, nil
+		}
+	}
+	return "", fmt.Errorf("no cloud provider identifying the host detected")
+}
+
+// GetHostTags returns the host tags of the cloud providers detected, the
+// providers which are not detected are skipped
+func GetHostTags() []string {
+	tags := []string{}
+	for _, p := range getProviders() {
+		if !detect(p).detected {
+			continue
+		}
+		providerTags, err := p.GetHostTags()
+		if err != nil {
+			log.Debugf("No %s host tags %v", p.Name(), err)
+		} else {
+			tags = append(tags, providerTags...)
+		}
+	}
+	return tags
+}
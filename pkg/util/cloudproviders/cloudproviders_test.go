@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package cloudproviders
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+)
+
+func setProviders(p ...CloudProvider) func() {
+	previous := providers
+	providers = p
+	return func() {
+		for _, p := range providers {
+			cache.Cache.Delete(cache.BuildAgentKey("cloudproviders", p.Name()))
+		}
+		providers = previous
+	}
+}
+
+func TestGetHostAliases(t *testing.T) {
+	defer setProviders(
+		provider{name: "detected", getAlias: func() (string, error) { return "i-1234", nil }},
+		provider{name: "undetected", getAlias: func() (string, error) { return "", errors.New("timeout") }},
+		provider{name: "no alias"},
+	)()
+	RegisterCloudProvider(provider{name: "registered", getAlias: func() (string, error) { return "vm-5678", nil }})
+
+	assert.Equal(t, []string{"i-1234", "vm-5678"}, GetHostAliases())
+}
+
+func TestDetectionCached(t *testing.T) {
+	queries := 0
+	defer setProviders(
+		provider{name: "undetected", getAlias: func() (string, error) { queries++; return "", errors.New("timeout") }, getTags: func() ([]string, error) { queries++; return nil, nil }},
+	)()
+
+	assert.Equal(t, []string{}, GetHostAliases())
+	assert.Equal(t, []string{}, GetHostTags())
+	assert.Equal(t, []string{}, GetHostAliases())
+	assert.Equal(t, 1, queries)
+}
+
+func TestGetHostname(t *testing.T) {
+	defer setProviders(
+		provider{name: "alias only", getAlias: func() (string, error) { return "vm-5678", nil }},
+		provider{name: "undetected", getAlias: func() (string, error) { return "", errors.New("timeout") }, hostIdentity: true},
+		provider{name: "identity", getAlias: func() (string, error) { return "i-1234", nil }, hostIdentity: true},
+	)()
+
+	hostname, err := GetHostname()
+	assert.Nil(t, err)
+	assert.Equal(t, "i-1234", hostname)
+}
+
+func TestGetHostTags(t *testing.T) {
+	defer setProviders(
+		provider{name: "detected", getTags: func() ([]string, error) { return []string{"region:eu-central-1", "zone:eu-central-1a"}, nil }},
+		provider{name: "undetected", getTags: func() ([]string, error) { return nil, errors.New("timeout") }},
+		provider{name: "no tags"},
+	)()
+
+	assert.Equal(t, []string{"region:eu-central-1", "zone:eu-central-1a"}, GetHostTags())
+}
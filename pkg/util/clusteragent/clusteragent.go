@@ -7,6 +7,7 @@ package clusteragent
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"os"
 
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/cihub/seelog"
@@ -34,6 +36,10 @@ const (
 
 var globalClusterAgentClient *DCAClient
 
+// ErrNotFound is returned when the cluster agent does not serve an endpoint,
+// for instance if it runs an older version than the node agent.
+var ErrNotFound = errors.New("endpoint not found on the cluster agent")
+
 type metadataNames []string
 
 // DCAClient is required to query the API of Datadog cluster agent
@@ -43,7 +49,9 @@ type DCAClient struct {
 
 	clusterAgentAPIEndpoint       string // ${SCHEME}://${clusterAgentHost}:${PORT}
 	clusterAgentAPIClient         *http.Client
+	clusterAgentStreamClient      *http.Client // without timeout, for the long-lived streams
 	clusterAgentAPIRequestHeaders *http.Header
+	headersLock                   sync.RWMutex
 }
 
 // GetClusterAgentClient returns or init the DCAClient
@@ -74,21 +82,73 @@ func (c *DCAClient) init() error {
 		return err
 	}
 
-	authToken, err := security.GetClusterAgentAuthToken()
+	err = c.refreshAuthToken()
 	if err != nil {
 		return err
 	}
 
-	c.clusterAgentAPIRequestHeaders = &http.Header{}
-	c.clusterAgentAPIRequestHeaders.Set(authorizationHeaderKey, fmt.Sprintf("Bearer %s", authToken))
-
 	// TODO remove insecure
 	c.clusterAgentAPIClient = util.GetClient(false)
 	c.clusterAgentAPIClient.Timeout = 2 * time.Second
+	c.clusterAgentStreamClient = util.GetClient(false)
+
+	return nil
+}
+
+// refreshAuthToken loads the authentication token and sets the headers of the requests.
+// It is called again when the DCA rejects the token, in case it was rotated: only
+// the dca_auth_token file is read again, a token set in cluster_agent.auth_token,
+// in datadog.yaml or DD_CLUSTER_AGENT_AUTH_TOKEN, needs a restart of the agent.
+func (c *DCAClient) refreshAuthToken() error {
+	authToken, err := security.GetClusterAgentAuthToken()
+	if err != nil {
+		return err
+	}
 
+	headers := &http.Header{}
+	headers.Set(authorizationHeaderKey, fmt.Sprintf("Bearer %s", authToken))
+	c.headersLock.Lock()
+	c.clusterAgentAPIRequestHeaders = headers
+	c.headersLock.Unlock()
 	return nil
 }
 
+// doRequest sends a request to the DCA with the authentication headers. If the token
+// is rejected it is reloaded, and the request is retried once with the new token.
+func (c *DCAClient) doRequest(req *http.Request) (*http.Response, error) {
+	return c.doRequestWith(c.clusterAgentAPIClient, req)
+}
+
+func (c *DCAClient) doRequestWith(client *http.Client, req *http.Request) (*http.Response, error) {
+	c.headersLock.RLock()
+	req.Header = *c.clusterAgentAPIRequestHeaders
+	c.headersLock.RUnlock()
+
+	resp, err := client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+
+	previousAuth := req.Header.Get(authorizationHeaderKey)
+	if err := c.refreshAuthToken(); err != nil {
+		log.Debugf("Could not reload the cluster agent auth token: %s", err)
+		return resp, nil
+	}
+	c.headersLock.RLock()
+	req.Header = *c.clusterAgentAPIRequestHeaders
+	c.headersLock.RUnlock()
+	if req.Header.Get(authorizationHeaderKey) == previousAuth {
+		if config.Datadog.GetString("cluster_agent.auth_token") != "" {
+			log.Debugf("The cluster agent rejected the token of cluster_agent.auth_token, restart the agent to use a rotated one")
+		}
+		return resp, nil
+	}
+
+	log.Infof("The cluster agent auth token was rotated, retrying the request to %s", req.URL.Path)
+	resp.Body.Close()
+	return client.Do(req)
+}
+
 // getClusterAgentEndpoint provides a validated https endpoint from configuration keys in datadog.yaml:
 // 1st. configuration key "cluster_agent_url", add the https prefix if the scheme isn't specified
 // 2nd. environment variables associated with "cluster_agent_kubernetes_service_name"
@@ -160,9 +220,7 @@ func (c *DCAClient) GetKubernetesMetadataNames(nodeName, podName string) ([]stri
 	if c == nil {
 		return nil, fmt.Errorf("cluster agent's client is not properly initialized")
 	}
-	req := &http.Request{
-		Header: *c.clusterAgentAPIRequestHeaders,
-	}
+	req := &http.Request{}
 	// https://host:port /api/v1/metadata/ {nodeName}/ {pod-[0-9a-z]+}
 	rawURL := fmt.Sprintf("%s/%s/%s/%s", c.clusterAgentAPIEndpoint, dcaMetadataPath, nodeName, podName)
 	req.URL, err = url.Parse(rawURL)
@@ -170,7 +228,7 @@ func (c *DCAClient) GetKubernetesMetadataNames(nodeName, podName string) ([]stri
 		return metadataNames, err
 	}
 
-	resp, err := c.clusterAgentAPIClient.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return metadataNames, err
 	}
@@ -190,3 +248,85 @@ func (c *DCAClient) GetKubernetesMetadataNames(nodeName, podName string) ([]stri
 
 	return metadataNames, nil
 }
+
+// GetPodServices queries the datadog cluster agent to get the names of the services
+// selecting a pod. ErrNotFound is returned if the cluster agent does not serve them.
+func (c *DCAClient) GetPodServices(namespace, podName string) ([]string, error) {
+	const dcaPodsPath = "api/v1/pods"
+	var services []string
+	var err error
+
+	if c == nil {
+		return nil, fmt.Errorf("cluster agent's client is not properly initialized")
+	}
+	req := &http.Request{}
+	// https://host:port /api/v1/pods/ {namespace}/ {podName}/services
+	rawURL := fmt.Sprintf("%s/%s/%s/%s/services", c.clusterAgentAPIEndpoint, dcaPodsPath, namespace, podName)
+	req.URL, err = url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from cluster agent: %d", resp.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, &services)
+	if err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// GetNodeLabels queries the datadog cluster agent to get the labels of a node
+func (c *DCAClient) GetNodeLabels(nodeName string) (map[string]string, error) {
+	const dcaNodesPath = "api/v1/nodes"
+	var labels map[string]string
+	var err error
+
+	if c == nil {
+		return nil, fmt.Errorf("cluster agent's client is not properly initialized")
+	}
+	req := &http.Request{}
+	// https://host:port /api/v1/nodes/ {nodeName}/labels
+	rawURL := fmt.Sprintf("%s/%s/%s/labels", c.clusterAgentAPIEndpoint, dcaNodesPath, nodeName)
+	req.URL, err = url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from cluster agent: %d", resp.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, &labels)
+	if err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
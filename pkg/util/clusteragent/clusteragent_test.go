@@ -18,6 +18,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	log "github.com/cihub/seelog"
 	"github.com/stretchr/testify/assert"
@@ -29,7 +30,10 @@ import (
 )
 
 type dummyClusterAgent struct {
-	responses map[string][]string
+	responses   map[string][]string
+	nodeLabels  map[string]map[string]string
+	podServices map[string][]string
+	streams     map[string][]NodeMetadata
 	sync.RWMutex
 	token string
 }
@@ -44,6 +48,24 @@ func newDummyClusterAgent() (*dummyClusterAgent, error) {
 			"node2/pod-00005": {"kube_service:svc3"},
 			"node2/pod-00006": {},
 		},
+		nodeLabels: map[string]map[string]string{
+			"node1": {"kubernetes.io/hostname": "node1", "cloud.google.com/gke-nodepool": "default-pool"},
+			"node2": {},
+		},
+		podServices: map[string][]string{
+			"default/pod-00001":     {"svc1"},
+			"default/pod-00002":     {"svc1", "svc2"},
+			"kube-system/pod-00001": {},
+		},
+		streams: map[string][]NodeMetadata{
+			"node1": {
+				{PodServices: map[string][]string{"default/pod-00001": {"svc1"}}, ClusterTags: []string{"kube_cluster_name:prod"}},
+				{PodServices: map[string][]string{"default/pod-00001": {"svc1", "svc2"}}, ClusterTags: []string{"kube_cluster_name:prod"}},
+			},
+			"node2": {
+				{PodServices: map[string][]string{}},
+			},
+		},
 		token: config.Datadog.GetString("cluster_agent.auth_token"),
 	}
 	return dca, nil
@@ -51,24 +73,72 @@ func newDummyClusterAgent() (*dummyClusterAgent, error) {
 
 func (d *dummyClusterAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debugf("dummyDCA received %s on %s", r.Method, r.URL.Path)
+	d.RLock()
+	expectedToken := d.token
+	d.RUnlock()
 	token := r.Header.Get("Authorization")
-	if token != fmt.Sprintf("Bearer %s", d.token) {
+	if token != fmt.Sprintf("Bearer %s", expectedToken) {
 		log.Errorf("wrong token %s", token)
 		w.WriteHeader(403)
 		return
 	}
 	// path should be like: /api/v1/metadata/{nodeName}/{pod-[0-9a-z]+}
 	s := strings.Split(r.URL.Path, "/")
+
+	// or like: /api/v1/pods/{namespace}/{podName}/services
+	if len(s) == 7 && s[3] == "pods" && s[6] == "services" {
+		d.RLock()
+		defer d.RUnlock()
+		svcs, found := d.podServices[fmt.Sprintf("%s/%s", s[4], s[5])]
+		if !found {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		b, _ := json.Marshal(svcs)
+		w.Write(b)
+		return
+	}
+
 	if len(s) != 6 {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Errorf("unexpected len 6 != %d", len(s))
 		return
 	}
+	d.RLock()
+	defer d.RUnlock()
+
+	// or like: /api/v1/tags/stream/{nodeName}, node2 keeps its stream open
+	if s[3] == "tags" && s[4] == "stream" {
+		stream, found := d.streams[s[5]]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for _, metadata := range stream {
+			json.NewEncoder(w).Encode(metadata)
+			w.(http.Flusher).Flush()
+		}
+		if s[5] == "node2" {
+			<-r.Context().Done()
+		}
+		return
+	}
+
+	// or like: /api/v1/nodes/{nodeName}/labels
+	if s[3] == "nodes" {
+		labels, found := d.nodeLabels[s[4]]
+		if !found {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		b, _ := json.Marshal(labels)
+		w.Write(b)
+		return
+	}
+
 	nodeName, podName := s[4], s[5]
 	key := fmt.Sprintf("%s/%s", nodeName, podName)
 
-	d.RLock()
-	defer d.RUnlock()
 	svcs, found := d.responses[key]
 	if found {
 		b, err := json.Marshal(svcs)
@@ -305,6 +375,136 @@ func (suite *clusterAgentSuite) TestGetKubernetesMetadataNames() {
 	}
 }
 
+func (suite *clusterAgentSuite) TestGetNodeLabels() {
+	dca, err := newDummyClusterAgent()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	ts, p, err := dca.StartTLS()
+	defer ts.Close()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	config.Datadog.Set("cluster_agent.url", fmt.Sprintf("https://127.0.0.1:%d", p))
+
+	globalClusterAgentClient = nil // force the client to use the new url
+	ca, err := GetClusterAgentClient()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	labels, err := ca.GetNodeLabels("node1")
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Equal(suite.T(), map[string]string{"kubernetes.io/hostname": "node1", "cloud.google.com/gke-nodepool": "default-pool"}, labels)
+
+	labels, err = ca.GetNodeLabels("node2")
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Len(suite.T(), labels, 0)
+
+	_, err = ca.GetNodeLabels("unknown")
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *clusterAgentSuite) TestGetPodServices() {
+	dca, err := newDummyClusterAgent()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	ts, p, err := dca.StartTLS()
+	defer ts.Close()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	config.Datadog.Set("cluster_agent.url", fmt.Sprintf("https://127.0.0.1:%d", p))
+
+	globalClusterAgentClient = nil // force the client to use the new url
+	ca, err := GetClusterAgentClient()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	services, err := ca.GetPodServices("default", "pod-00002")
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Equal(suite.T(), []string{"svc1", "svc2"}, services)
+
+	services, err = ca.GetPodServices("kube-system", "pod-00001")
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Len(suite.T(), services, 0)
+
+	_, err = ca.GetPodServices("default", "unknown")
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *clusterAgentSuite) TestStreamNodeMetadata() {
+	dca, err := newDummyClusterAgent()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	ts, p, err := dca.StartTLS()
+	defer ts.Close()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	config.Datadog.Set("cluster_agent.url", fmt.Sprintf("https://127.0.0.1:%d", p))
+
+	globalClusterAgentClient = nil // force the client to use the new url
+	ca, err := GetClusterAgentClient()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	var received []NodeMetadata
+	handler := func(metadata NodeMetadata) { received = append(received, metadata) }
+
+	// the end of the stream is an interruption
+	err = ca.StreamNodeMetadata("node1", handler, make(chan struct{}))
+	assert.NotNil(suite.T(), err)
+	require.Len(suite.T(), received, 2)
+	assert.Equal(suite.T(), []string{"svc1", "svc2"}, received[1].PodServices["default/pod-00001"])
+	assert.Equal(suite.T(), []string{"kube_cluster_name:prod"}, received[1].ClusterTags)
+
+	// the stream is closed on stop
+	received = nil
+	stop := make(chan struct{})
+	handler = func(metadata NodeMetadata) {
+		received = append(received, metadata)
+		close(stop)
+	}
+	err = ca.StreamNodeMetadata("node2", handler, stop)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), received, 1)
+
+	// or if the cluster agent stops pushing
+	nodeMetadataStreamTimeout = 100 * time.Millisecond
+	defer func() { nodeMetadataStreamTimeout = 3 * NodeMetadataStreamKeepAlive }()
+	err = ca.StreamNodeMetadata("node2", func(NodeMetadata) {}, make(chan struct{}))
+	assert.NotNil(suite.T(), err)
+
+	err = ca.StreamNodeMetadata("unknown", handler, make(chan struct{}))
+	assert.Equal(suite.T(), ErrNotFound, err)
+}
+
+func (suite *clusterAgentSuite) TestAuthTokenRotation() {
+	dca, err := newDummyClusterAgent()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	ts, p, err := dca.StartTLS()
+	defer ts.Close()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	config.Datadog.Set("cluster_agent.url", fmt.Sprintf("https://127.0.0.1:%d", p))
+
+	globalClusterAgentClient = nil // force the client to use the new url
+	ca, err := GetClusterAgentClient()
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+
+	// the token is rotated on both sides, the client reloads it when rejected
+	newToken := "abcdefghijabcdefghijabcdefghij01"
+	dca.Lock()
+	dca.token = newToken
+	dca.Unlock()
+	config.Datadog.Set("cluster_agent.auth_token", newToken)
+
+	labels, err := ca.GetNodeLabels("node1")
+	require.Nil(suite.T(), err, fmt.Sprintf("%v", err))
+	assert.Len(suite.T(), labels, 2)
+
+	// the token of the client is rejected
+	dca.Lock()
+	dca.token = clusterAgentTokenValue
+	dca.Unlock()
+	_, err = ca.GetNodeLabels("node1")
+	assert.NotNil(suite.T(), err)
+}
+
 func TestClusterAgentSuite(t *testing.T) {
 	clusterAgentAuthTokenFilename := "cluster_agent_auth_token"
 
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clusteragent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// NodeMetadataStreamKeepAlive is the longest interval between two pushes on the
+// metadata stream of a node, the metadata is sent again if it did not change.
+const NodeMetadataStreamKeepAlive = time.Minute
+
+// nodeMetadataStreamTimeout is how long the stream is kept open without push
+var nodeMetadataStreamTimeout = 3 * NodeMetadataStreamKeepAlive
+
+// NodeMetadata holds the cluster level tags of the pods of a node, pushed by the
+// cluster agent to the node agent subscribed to the node.
+type NodeMetadata struct {
+	// PodServices maps the namespace/name of the pods to the services selecting them
+	PodServices map[string][]string `json:"pod_services"`
+	// ClusterTags apply to all the pods
+	ClusterTags []string `json:"cluster_tags"`
+}
+
+// StreamNodeMetadata subscribes to the metadata of the pods of a node, handler is
+// called with every push of the datadog cluster agent. It blocks until the stream
+// is interrupted, returning an error, or until stop is closed. ErrNotFound is
+// returned if the cluster agent does not serve the stream.
+func (c *DCAClient) StreamNodeMetadata(nodeName string, handler func(NodeMetadata), stop <-chan struct{}) error {
+	const dcaTagStreamPath = "api/v1/tags/stream"
+	var err error
+
+	if c == nil {
+		return fmt.Errorf("cluster agent's client is not properly initialized")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := (&http.Request{}).WithContext(ctx)
+	// https://host:port /api/v1/tags/stream/ {nodeName}
+	rawURL := fmt.Sprintf("%s/%s/%s", c.clusterAgentAPIEndpoint, dcaTagStreamPath, nodeName)
+	req.URL, err = url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	// the request is cancelled on stop, or if the cluster agent stops pushing
+	watchdog := time.AfterFunc(nodeMetadataStreamTimeout, cancel)
+	defer watchdog.Stop()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	resp, err := c.doRequestWith(c.clusterAgentStreamClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from cluster agent: %d", resp.StatusCode)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var metadata NodeMetadata
+		if err = decoder.Decode(&metadata); err != nil {
+			select {
+			case <-stop:
+				return nil
+			default:
+				return fmt.Errorf("the metadata stream of the node %s was interrupted: %s", nodeName, err)
+			}
+		}
+		watchdog.Reset(nodeMetadataStreamTimeout)
+		handler(metadata)
+	}
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clustername
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/azure"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/ec2"
+	"github.com/DataDog/datadog-agent/pkg/util/gce"
+)
+
+// TagName is the tag holding the name of the cluster on the Kubernetes data
+const TagName = "kube_cluster_name"
+
+const maxLength = 40
+
+// failedDetectionTTL is how long a failed detection is cached before the name
+// is detected again, the metadata endpoints can be briefly unavailable.
+const failedDetectionTTL = 5 * time.Minute
+
+var validClusterName = regexp.MustCompile(`^[a-z0-9]([a-z0-9\-\.]*[a-z0-9])?$`)
+
+type provider struct {
+	name string
+	get  func() (string, error)
+}
+
+// providers are tried in order to detect the name of the cluster, the UID of
+// the kube-system namespace is appended when the apiserver support is compiled.
+var providers = []provider{
+	{"GKE", gce.GetClusterName},
+	{"EKS", ec2.GetClusterName},
+	{"AKS", azure.GetClusterName},
+}
+
+// GetClusterName returns the name of the Kubernetes cluster, read from the
+// `cluster_name` option, or detected from the metadata of the cloud providers,
+// then from the UID of the kube-system namespace. It returns an empty string
+// if no name could be found. A detected name is cached for the life of the
+// agent, a failed detection is retried after failedDetectionTTL.
+func GetClusterName() string {
+	cacheKey := cache.BuildAgentKey("clustername")
+	if clusterName, found := cache.Cache.Get(cacheKey); found {
+		return clusterName.(string)
+	}
+
+	clusterName := detectClusterName()
+	if clusterName == "" {
+		cache.Cache.Set(cacheKey, clusterName, failedDetectionTTL)
+	} else {
+		cache.Cache.Set(cacheKey, clusterName, cache.NoExpiration)
+	}
+	return clusterName
+}
+
+func detectClusterName() string {
+	if configured := config.Datadog.GetString("cluster_name"); configured != "" {
+		name, err := normalize(configured)
+		if err == nil {
+			return name
+		}
+		log.Warnf("Ignoring the cluster_name option: %s", err)
+	}
+
+	for _, p := range providers {
+		name, err := p.get()
+		if err != nil {
+			log.Debugf("Unable to get the cluster name from %s: %s", p.name, err)
+			continue
+		}
+		name, err = normalize(name)
+		if err != nil {
+			log.Debugf("Invalid cluster name from %s: %s", p.name, err)
+			continue
+		}
+		log.Infof("Got the cluster name %q from %s", name, p.name)
+		return name
+	}
+	return ""
+}
+
+// normalize lowercases a cluster name and checks it is usable in a tag.
+func normalize(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("empty cluster name")
+	}
+	if len(name) > maxLength {
+		return "", fmt.Errorf("%q is longer than %d characters", name, maxLength)
+	}
+	if !validClusterName.MatchString(name) {
+		return "", fmt.Errorf("%q must only contain alphanumerics, '-' and '.'", name)
+	}
+	return name, nil
+}
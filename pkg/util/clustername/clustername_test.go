@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package clustername
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+)
+
+func TestGetClusterName(t *testing.T) {
+	defer func(p []provider) { providers = p }(providers)
+	defer config.Datadog.Set("cluster_name", "")
+	cacheKey := cache.BuildAgentKey("clustername")
+	defer cache.Cache.Delete(cacheKey)
+
+	calls := 0
+	providers = []provider{
+		{"failing", func() (string, error) { calls++; return "", errors.New("not on this cloud") }},
+		{"invalid", func() (string, error) { calls++; return "my_cluster", nil }},
+		{"valid", func() (string, error) { calls++; return "Prod-EU", nil }},
+	}
+	assert.Equal(t, "prod-eu", GetClusterName())
+	assert.Equal(t, 3, calls)
+
+	// the name is only detected once
+	assert.Equal(t, "prod-eu", GetClusterName())
+	assert.Equal(t, 3, calls)
+
+	// the configuration wins over the detection
+	cache.Cache.Delete(cacheKey)
+	calls = 0
+	config.Datadog.Set("cluster_name", "staging")
+	assert.Equal(t, "staging", GetClusterName())
+	assert.Equal(t, 0, calls)
+
+	// an invalid configuration falls back to the detection
+	cache.Cache.Delete(cacheKey)
+	config.Datadog.Set("cluster_name", "my_cluster")
+	assert.Equal(t, "prod-eu", GetClusterName())
+
+	cache.Cache.Delete(cacheKey)
+	providers = providers[:2]
+	config.Datadog.Set("cluster_name", "")
+	assert.Equal(t, "", GetClusterName())
+}
+
+func TestNormalize(t *testing.T) {
+	for name, expected := range map[string]string{
+		"prod":        "prod",
+		" Prod.EU-1 ": "prod.eu-1",
+		"":            "",
+		"-prod":       "",
+		"prod_eu":     "",
+		"a-very-long-cluster-name-which-is-rejected": "",
+	} {
+		normalized, err := normalize(name)
+		assert.Equal(t, expected, normalized, name)
+		assert.Equal(t, expected == "", err != nil, name)
+	}
+}
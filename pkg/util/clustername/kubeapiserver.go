@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package clustername
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+)
+
+func init() {
+	providers = append(providers, provider{"the kube-system namespace", getKubeSystemUID})
+}
+
+func getKubeSystemUID() (string, error) {
+	client, err := apiserver.GetAPIClient()
+	if err != nil {
+		return "", err
+	}
+	return client.GetKubeSystemUID()
+}
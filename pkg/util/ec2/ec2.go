@@ -34,6 +34,35 @@ func GetHostname() (string, error) {
 	return getMetadataItem("/hostname")
 }
 
+// GetClusterName returns the name of the EKS cluster of the instance, from its
+// tags. The tags are only available if the agent is built with the ec2 tag.
+func GetClusterName() (string, error) {
+	tags, err := GetTags()
+	if err != nil {
+		return "", err
+	}
+	return parseClusterName(tags)
+}
+
+// parseClusterName looks for the `eks:cluster-name` tag set on the nodes of the
+// managed node groups, then for the `kubernetes.io/cluster/<name>` one.
+func parseClusterName(tags []string) (string, error) {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "eks:cluster-name:") {
+			return strings.TrimPrefix(tag, "eks:cluster-name:"), nil
+		}
+	}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "kubernetes.io/cluster/") {
+			name := strings.SplitN(strings.TrimPrefix(tag, "kubernetes.io/cluster/"), ":", 2)[0]
+			if name != "" {
+				return name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no EKS cluster tag found on the instance")
+}
+
 func getMetadataItem(endpoint string) (string, error) {
 	res, err := getResponse(metadataURL + endpoint)
 	if err != nil {
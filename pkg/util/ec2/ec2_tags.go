@@ -16,10 +16,31 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
 )
 
-// GetTags grabs the host tags from the EC2 api
+// tagsCacheKey is the cache key of the last tags fetched from the EC2 api
+var tagsCacheKey = cache.BuildAgentKey("ec2", "GetTags")
+
+// GetTags grabs the host tags from the EC2 api. The tags are refreshed
+// periodically, so the last tags fetched are returned when the api cannot be
+// reached rather than dropping them from the host.
 func GetTags() ([]string, error) {
+	tags, err := fetchTags()
+	if err != nil {
+		if x, found := cache.Cache.Get(tagsCacheKey); found {
+			log.Debugf("Unable to refresh the EC2 tags, using the last ones fetched: %s", err)
+			return x.([]string), nil
+		}
+		return tags, err
+	}
+	cache.Cache.Set(tagsCacheKey, tags, cache.NoExpiration)
+	return tags, nil
+}
+
+func fetchTags() ([]string, error) {
 	tags := []string{}
 
 	instanceIdentity, err := getInstanceIdentity()
@@ -17,6 +17,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
 )
 
 func TestGetIAMRole(t *testing.T) {
@@ -77,3 +79,20 @@ func TestGetInstanceIdentity(t *testing.T) {
 	assert.Equal(t, "us-east-1", val.Region)
 	assert.Equal(t, "i-aaaaaaaaaaaaaaaaa", val.InstanceId)
 }
+
+func TestGetTagsFallbackToCache(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+	instanceIdentityURL = ts.URL
+	defer cache.Cache.Delete(tagsCacheKey)
+
+	_, err := GetTags()
+	assert.NotNil(t, err)
+
+	cache.Cache.Set(tagsCacheKey, []string{"team:infra"}, cache.NoExpiration)
+	tags, err := GetTags()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"team:infra"}, tags)
+}
@@ -60,3 +60,17 @@ func TestGetHostname(t *testing.T) {
 	assert.Equal(t, "", val)
 	assert.Equal(t, lastRequest.URL.Path, "/hostname")
 }
+
+func TestParseClusterName(t *testing.T) {
+	name, err := parseClusterName([]string{"env:prod", "kubernetes.io/cluster/prod-eu:owned"})
+	assert.Nil(t, err)
+	assert.Equal(t, "prod-eu", name)
+
+	// the tag of the managed node groups takes precedence
+	name, err = parseClusterName([]string{"kubernetes.io/cluster/other:shared", "eks:cluster-name:prod-us"})
+	assert.Nil(t, err)
+	assert.Equal(t, "prod-us", name)
+
+	_, err = parseClusterName([]string{"env:prod"})
+	assert.NotNil(t, err)
+}
@@ -7,6 +7,11 @@
 
 package gce
 
+// TagsEnabled returns false, the agent is built without the GCE tags
+func TagsEnabled() bool {
+	return false
+}
+
 // GetTags gets the tags from the GCE api
 func GetTags() ([]string, error) {
 	tags := []string{}
@@ -17,6 +17,12 @@ import (
 var excludedAttributes = []string{"kube-env", "startup-script", "shutdown-script", "configure-sh",
 	"sshKeys", "user-data", "cli-cert", "ipsec-cert", "ssl-cert", "google-container-manifest", "bosh_settings"}
 
+// TagsEnabled returns whether the GCE tags are collected, the agent runs on GCE
+func TagsEnabled() bool {
+	_, err := GetHostAlias()
+	return err == nil
+}
+
 // GetTags gets the tags from the GCE api
 func GetTags() ([]string, error) {
 	tags := []string{}
@@ -51,3 +51,19 @@ func TestGetHostAliases(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "gce-hostname.gce-project", val)
 }
+
+func TestGetClusterName(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "prod-cluster")
+		lastRequest = r
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	name, err := GetClusterName()
+	assert.Nil(t, err)
+	assert.Equal(t, "prod-cluster", name)
+	assert.Equal(t, lastRequest.URL.Path, "/instance/attributes/cluster-name")
+}
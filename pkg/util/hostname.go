@@ -16,6 +16,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/cloudproviders"
 	"github.com/DataDog/datadog-agent/pkg/util/ecs"
 	"github.com/DataDog/datadog-agent/pkg/util/hostname"
 )
@@ -81,12 +82,50 @@ func Fqdn(hostname string) string {
 	return hostname
 }
 
+// The providers consulted to resolve the hostname
+const (
+	HostnameProviderConfiguration = "configuration"
+	HostnameProviderFargate       = "fargate"
+	HostnameProviderGCE           = "gce"
+	HostnameProviderDocker        = "docker"
+	HostnameProviderKubelet       = "kubelet"
+	HostnameProviderOS            = "os"
+	HostnameProviderCloud         = "cloud"
+	HostnameProviderEC2           = "ec2"
+)
+
+// HostnameProviderResult is the value returned by a hostname provider, or its error
+type HostnameProviderResult struct {
+	Provider string `json:"provider"`
+	Value    string `json:"value,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// HostnameResolution details how the hostname of the agent is resolved: the
+// results of the providers, in the order they are consulted, and the provider
+// of the hostname used.
+type HostnameResolution struct {
+	Hostname  string                   `json:"hostname"`
+	Provider  string                   `json:"provider,omitempty"`
+	Providers []HostnameProviderResult `json:"providers"`
+}
+
+// record adds the result of a provider
+func (r *HostnameResolution) record(provider, value string, err error) {
+	result := HostnameProviderResult{Provider: provider, Value: value}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	r.Providers = append(r.Providers, result)
+}
+
 // GetHostname retrieve the host name for the Agent, trying to query these
 // environments/api, in order:
 // * GCE
 // * Docker
 // * kubernetes
 // * os
+// * Alibaba, Oracle and IBM Cloud
 // * EC2
 func GetHostname() (string, error) {
 	cacheHostnameKey := cache.BuildAgentKey("hostname")
@@ -94,15 +133,25 @@ func GetHostname() (string, error) {
 		return cacheHostname.(string), nil
 	}
 
+	resolution, err := ResolveHostname()
+	cache.Cache.Set(cacheHostnameKey, resolution.Hostname, cache.NoExpiration)
+	return resolution.Hostname, err
+}
+
+// ResolveHostname resolves the host name like GetHostname without its cache,
+// it returns the details of the resolution.
+func ResolveHostname() (HostnameResolution, error) {
+	resolution := HostnameResolution{}
 	var hostName string
 	var err error
 
 	// try the name provided in the configuration file
 	name := config.Datadog.GetString("hostname")
 	err = ValidHostname(name)
+	resolution.record(HostnameProviderConfiguration, name, err)
 	if err == nil {
-		cache.Cache.Set(cacheHostnameKey, name, cache.NoExpiration)
-		return name, err
+		resolution.Hostname, resolution.Provider = name, HostnameProviderConfiguration
+		return resolution, err
 	}
 
 	log.Debugf("Unable to get the hostname from the config file: %s", err)
@@ -110,38 +159,60 @@ func GetHostname() (string, error) {
 
 	// if fargate we strip the hostname
 	if ecs.IsFargateInstance() {
-		cache.Cache.Set(cacheHostnameKey, "", cache.NoExpiration)
-		return "", nil
+		resolution.record(HostnameProviderFargate, "", nil)
+		resolution.Provider = HostnameProviderFargate
+		return resolution, nil
 	}
 
 	// GCE metadata
 	log.Debug("GetHostname trying GCE metadata...")
 	if getGCEHostname, found := hostname.ProviderCatalog["gce"]; found {
 		name, err = getGCEHostname(name)
+		resolution.record(HostnameProviderGCE, name, err)
 		if err == nil {
-			cache.Cache.Set(cacheHostnameKey, name, cache.NoExpiration)
-			return name, err
+			resolution.Hostname, resolution.Provider = name, HostnameProviderGCE
+			return resolution, err
 		}
 		log.Debug("Unable to get hostname from GCE: ", err)
 	}
 
-	isContainerized, name := getContainerHostname()
-	if isContainerized && name != "" {
+	provider, name := getContainerHostname(&resolution)
+	if provider != "" && name != "" {
 		hostName = name
+		resolution.Provider = provider
 	}
 
 	if hostName == "" {
 		// os
 		log.Debug("GetHostname trying os...")
 		name, err = os.Hostname()
+		resolution.record(HostnameProviderOS, name, err)
 		if err == nil {
 			hostName = name
+			resolution.Provider = HostnameProviderOS
 		} else {
 			log.Debug("Unable to get hostname from OS: ", err)
 		}
 	}
 
-	/* at this point we've either the hostname from the os or an empty string */
+	// the instance ID identifies the hosts of the clouds without hostname
+	// provider better than their OS hostname
+	if resolution.Provider == HostnameProviderOS {
+		log.Debug("GetHostname trying the cloud providers...")
+		instanceID, err := cloudproviders.GetHostname()
+		if err == nil {
+			err = ValidHostname(instanceID)
+		}
+		resolution.record(HostnameProviderCloud, instanceID, err)
+		if err == nil {
+			hostName = instanceID
+			resolution.Provider = HostnameProviderCloud
+		} else {
+			log.Debug("Unable to get hostname from the cloud providers: ", err)
+		}
+	}
+
+	/* at this point we've either the hostname from the os or the cloud, or an empty string */
 
 	// We use the instance id if we're on an ECS cluster or we're on EC2
 	// and the hostname is one of the default ones
@@ -152,22 +223,25 @@ func GetHostname() (string, error) {
 			err = ValidHostname(instanceID)
 			if err == nil {
 				hostName = instanceID
+				resolution.Provider = HostnameProviderEC2
 			} else {
 				log.Debug("EC2 instance ID is not a valid hostname: ", err)
 			}
 		} else {
 			log.Debug("Unable to determine hostname from EC2: ", err)
 		}
+		resolution.record(HostnameProviderEC2, instanceID, err)
 	}
 
 	// If at this point we don't have a name, bail out
 	if hostName == "" {
+		resolution.Provider = ""
 		err = fmt.Errorf("unable to reliably determine the host name. You can define one in the agent config file or in your hosts file")
 	} else {
 		// we got a hostname, residual errors are irrelevant now
 		err = nil
 	}
 
-	cache.Cache.Set(cacheHostnameKey, hostName, cache.NoExpiration)
-	return hostName, err
+	resolution.Hostname = hostName
+	return resolution, err
 }
@@ -4,6 +4,7 @@
 // Copyright 2018 Datadog, Inc.
 
 // +build linux windows darwin
+
 // I don't think windows and darwin can actually be docker hosts
 // but keeping it this way for build consistency (for now)
 
@@ -16,32 +17,42 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/hostname"
 )
 
-func getContainerHostname() (bool, string) {
+// getContainerHostname returns the hostname of the container runtime and its
+// provider, the provider is empty when it is not found
+func getContainerHostname(resolution *HostnameResolution) (string, string) {
 	var name string
 
 	if config.IsContainerized() == false {
-		return false, name
+		return "", name
 	}
 
 	// Docker
 	log.Debug("GetHostname trying Docker API...")
 	if getDockerHostname, found := hostname.ProviderCatalog["docker"]; found {
 		name, err := getDockerHostname(name)
-		if err == nil && ValidHostname(name) == nil {
-			return true, name
+		if err == nil {
+			err = ValidHostname(name)
+		}
+		resolution.record(HostnameProviderDocker, name, err)
+		if err == nil {
+			return HostnameProviderDocker, name
 		}
 	}
 
 	if config.IsKubernetes() == false {
-		return false, name
+		return "", name
 	}
 	// Kubernetes
 	log.Debug("GetHostname trying Kubernetes trough kubelet API...")
 	if getKubeletHostname, found := hostname.ProviderCatalog["kubelet"]; found {
 		name, err := getKubeletHostname(name)
-		if err == nil && ValidHostname(name) == nil {
-			return true, name
+		if err == nil {
+			err = ValidHostname(name)
+		}
+		resolution.record(HostnameProviderKubelet, name, err)
+		if err == nil {
+			return HostnameProviderKubelet, name
 		}
 	}
-	return false, name
+	return "", name
 }
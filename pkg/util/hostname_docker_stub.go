@@ -7,6 +7,6 @@
 
 package util
 
-func getContainerHostname() (bool, string) {
-	return false, ""
+func getContainerHostname(resolution *HostnameResolution) (string, string) {
+	return "", ""
 }
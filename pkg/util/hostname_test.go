@@ -10,6 +10,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 func TestIsLocal(t *testing.T) {
@@ -31,3 +34,14 @@ func TestValidHostname(t *testing.T) {
 	err = ValidHostname("data🐕hq.com")
 	assert.NotNil(t, err)
 }
+
+func TestResolveHostnameFromConfig(t *testing.T) {
+	config.Datadog.Set("hostname", "my-host.example.com")
+	defer config.Datadog.Set("hostname", "")
+
+	resolution, err := ResolveHostname()
+	require.Nil(t, err)
+	assert.Equal(t, "my-host.example.com", resolution.Hostname)
+	assert.Equal(t, HostnameProviderConfiguration, resolution.Provider)
+	assert.Equal(t, []HostnameProviderResult{{Provider: HostnameProviderConfiguration, Value: "my-host.example.com"}}, resolution.Providers)
+}
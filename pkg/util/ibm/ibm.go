@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package ibm queries the metadata service of the IBM Cloud VPC instances, it
+// must be enabled on the instances.
+package ibm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// apiVersion is the version of the metadata service api used
+const apiVersion = "2022-03-01"
+
+// declare these as vars not const to ease testing
+var (
+	metadataURL = "http://169.254.169.254"
+	timeout     = 300 * time.Millisecond
+)
+
+type instanceMetadata struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Zone struct {
+		Name string `json:"name"`
+	} `json:"zone"`
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+}
+
+// GetHostAlias returns the ID of the instance from the IBM Cloud metadata service
+func GetHostAlias() (string, error) {
+	metadata, err := getInstanceMetadata()
+	if err != nil {
+		return "", fmt.Errorf("IBM HostAliases: unable to query metadata service: %s", err)
+	}
+	return metadata.ID, nil
+}
+
+// GetTags returns the region, the zone and the profile of the instance from
+// the IBM Cloud metadata service
+func GetTags() ([]string, error) {
+	metadata, err := getInstanceMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("IBM Tags: unable to query metadata service: %s", err)
+	}
+
+	tags := []string{}
+	if zone := metadata.Zone.Name; zone != "" {
+		// the zones are named after their region, for instance us-south-1
+		if i := strings.LastIndex(zone, "-"); i > 0 {
+			tags = append(tags, fmt.Sprintf("region:%s", zone[:i]))
+		}
+		tags = append(tags, fmt.Sprintf("zone:%s", zone))
+	}
+	if metadata.Profile.Name != "" {
+		tags = append(tags, fmt.Sprintf("instance-type:%s", metadata.Profile.Name))
+	}
+	return tags, nil
+}
+
+func getInstanceMetadata() (*instanceMetadata, error) {
+	client := http.Client{
+		Timeout: timeout,
+	}
+
+	// the metadata are served to the holders of an instance identity token
+	token, err := getToken(client)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/metadata/v1/instance?version=%s", metadataURL, apiVersion)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	body, err := do(client, req)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &instanceMetadata{}
+	if err := json.Unmarshal(body, metadata); err != nil {
+		return nil, fmt.Errorf("unable to parse the metadata of the instance: %s", err)
+	}
+	if metadata.ID == "" {
+		return nil, fmt.Errorf("the metadata of the instance have no ID")
+	}
+	return metadata, nil
+}
+
+func getToken(client http.Client) (string, error) {
+	url := fmt.Sprintf("%s/instance_identity/v1/token?version=%s", metadataURL, apiVersion)
+	req, err := http.NewRequest("PUT", url, bytes.NewBufferString(`{"expires_in": 300}`))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Metadata-Flavor", "ibm")
+	req.Header.Add("Content-Type", "application/json")
+
+	body, err := do(client, req)
+	if err != nil {
+		return "", err
+	}
+	token := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("unable to parse the instance identity token: %s", err)
+	}
+	return token.AccessToken, nil
+}
+
+func do(client http.Client, req *http.Request) ([]byte, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("status code %d trying to %s %s", res.StatusCode, req.Method, req.URL)
+	}
+
+	all, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading response from ibm metadata service: %s", err)
+	}
+	return all, nil
+}
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package ibm
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMetadataServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/instance_identity/v1/token":
+			assert.Equal(t, "ibm", r.Header.Get("Metadata-Flavor"))
+			io.WriteString(w, `{"access_token": "secret-token"}`)
+		case r.Method == "GET" && r.URL.Path == "/metadata/v1/instance":
+			if r.Header.Get("Authorization") != "Bearer secret-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			io.WriteString(w, `{
+				"id": "0717_e21b7391-2ca2-4ab5-84a8-b92157a633b0",
+				"name": "my-instance",
+				"zone": {"name": "us-south-1"},
+				"profile": {"name": "bx2-2x8"}
+			}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGetHostAlias(t *testing.T) {
+	ts := newMetadataServer(t)
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	val, err := GetHostAlias()
+	assert.Nil(t, err)
+	assert.Equal(t, "0717_e21b7391-2ca2-4ab5-84a8-b92157a633b0", val)
+}
+
+func TestGetTags(t *testing.T) {
+	ts := newMetadataServer(t)
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	tags, err := GetTags()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"region:us-south", "zone:us-south-1", "instance-type:bx2-2x8"}, tags)
+}
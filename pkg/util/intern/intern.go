@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package intern deduplicates the strings kept in memory by the agent: the
+// tags parsed by dogstatsd, stored by the tagger and kept in the contexts of
+// the aggregator are mostly the same few values, interned they exist once.
+package intern
+
+import (
+	"expvar"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// shards is the number of independent parts of an Interner, the strings are
+// spread across them by hash so the goroutines rarely wait for each other
+const shards = 32
+
+var (
+	internerStats  = expvar.NewMap("interner")
+	internerMisses = expvar.Int{}
+	internerResets = expvar.Int{}
+
+	global     *Interner
+	globalOnce sync.Once
+)
+
+func init() {
+	internerStats.Set("Misses", &internerMisses)
+	internerStats.Set("Resets", &internerResets)
+}
+
+// Interner returns the same instance of equal strings. It holds about maxSize
+// strings: every shard keeps the strings of two generations, the strings seen
+// again are moved to the current one and the previous one is released when the
+// current one is full, so the strings of a former workload are released while
+// the ones in use stay interned.
+type Interner struct {
+	shards []*shard
+}
+
+type shard struct {
+	sync.RWMutex
+	current  map[string]string
+	previous map[string]string
+	maxSize  int
+}
+
+// NewInterner returns an Interner holding about maxSize strings, a maxSize
+// of 0 disables the interning
+func NewInterner(maxSize int) *Interner {
+	return newInterner(maxSize, shards)
+}
+
+func newInterner(maxSize int, shardCount int) *Interner {
+	if maxSize <= 0 {
+		return &Interner{}
+	}
+	if shardCount > maxSize {
+		shardCount = maxSize
+	}
+	// each shard holds two generations
+	generationSize := (maxSize + 2*shardCount - 1) / (2 * shardCount)
+	i := &Interner{shards: make([]*shard, shardCount)}
+	for n := range i.shards {
+		i.shards[n] = &shard{
+			current:  make(map[string]string),
+			previous: make(map[string]string),
+			maxSize:  generationSize,
+		}
+	}
+	return i
+}
+
+// LoadOrStoreBytes returns the interned copy of key, it only allocates the
+// string the first time key is seen
+func (i *Interner) LoadOrStoreBytes(key []byte) string {
+	if len(i.shards) == 0 {
+		return string(key)
+	}
+
+	// FNV-1a, computed inline to not allocate
+	hash := uint32(2166136261)
+	for _, c := range key {
+		hash ^= uint32(c)
+		hash *= 16777619
+	}
+	s := i.shards[hash%uint32(len(i.shards))]
+
+	// the conversion of a map key doesn't allocate
+	s.RLock()
+	interned, found := s.current[string(key)]
+	s.RUnlock()
+	if found {
+		return interned
+	}
+	return s.store(string(key))
+}
+
+// LoadOrStore returns the interned copy of s
+func (i *Interner) LoadOrStore(str string) string {
+	if len(i.shards) == 0 {
+		return str
+	}
+
+	hash := uint32(2166136261)
+	for n := 0; n < len(str); n++ {
+		hash ^= uint32(str[n])
+		hash *= 16777619
+	}
+	s := i.shards[hash%uint32(len(i.shards))]
+
+	s.RLock()
+	interned, found := s.current[str]
+	s.RUnlock()
+	if found {
+		return interned
+	}
+	return s.store(str)
+}
+
+// store adds str to the current generation, or moves it there when it is in
+// the previous one
+func (s *shard) store(str string) string {
+	s.Lock()
+	defer s.Unlock()
+	if interned, found := s.current[str]; found {
+		return interned
+	}
+	interned, found := s.previous[str]
+	if found {
+		delete(s.previous, str)
+	} else {
+		internerMisses.Add(1)
+		interned = str
+	}
+	if len(s.current) >= s.maxSize {
+		internerResets.Add(1)
+		s.previous = s.current
+		s.current = make(map[string]string)
+	}
+	s.current[interned] = interned
+	return interned
+}
+
+// Len returns the number of strings interned
+func (i *Interner) Len() int {
+	length := 0
+	for _, s := range i.shards {
+		s.RLock()
+		length += len(s.current) + len(s.previous)
+		s.RUnlock()
+	}
+	return length
+}
+
+// get returns the interner shared by the components of the agent, its size
+// is read from the configuration the first time it is used
+func get() *Interner {
+	globalOnce.Do(func() {
+		global = NewInterner(config.Datadog.GetInt("string_interner_size"))
+	})
+	return global
+}
+
+// Bytes returns the interned copy of b from the shared interner
+func Bytes(b []byte) string {
+	return get().LoadOrStoreBytes(b)
+}
+
+// String returns the interned copy of s from the shared interner
+func String(s string) string {
+	return get().LoadOrStore(s)
+}
+
+// Strings returns a copy of ss holding the interned copies of its strings
+// from the shared interner
+func Strings(ss []string) []string {
+	if ss == nil {
+		return nil
+	}
+	interner := get()
+	interned := make([]string, len(ss))
+	for n, s := range ss {
+		interned[n] = interner.LoadOrStore(s)
+	}
+	return interned
+}
@@ -0,0 +1,161 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package intern
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dataPointer returns the address of the bytes of s
+func dataPointer(s string) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&s))
+}
+
+func TestLoadOrStoreBytes(t *testing.T) {
+	interner := NewInterner(10)
+
+	first := interner.LoadOrStoreBytes([]byte("env:prod"))
+	second := interner.LoadOrStoreBytes([]byte("env:prod"))
+	assert.Equal(t, "env:prod", first)
+	assert.Equal(t, dataPointer(first), dataPointer(second))
+	assert.Equal(t, 1, interner.Len())
+
+	other := interner.LoadOrStore("env:staging")
+	assert.Equal(t, "env:staging", other)
+	assert.Equal(t, 2, interner.Len())
+}
+
+func TestLoadOrStore(t *testing.T) {
+	interner := NewInterner(10)
+
+	first := interner.LoadOrStore(string([]byte("service:web")))
+	second := interner.LoadOrStore(string([]byte("service:web")))
+	assert.Equal(t, dataPointer(first), dataPointer(second))
+}
+
+func TestGenerations(t *testing.T) {
+	interner := newInterner(2, 1)
+
+	interner.LoadOrStore("a")
+	interner.LoadOrStore("b")
+	assert.Equal(t, 2, interner.Len())
+
+	// a is seen again, it is moved to the current generation
+	a := interner.LoadOrStore(string([]byte("a")))
+	assert.Equal(t, 2, interner.Len())
+
+	// b was not seen again, it is released
+	interner.LoadOrStore("c")
+	assert.Equal(t, 2, interner.Len())
+	assert.Equal(t, dataPointer(a), dataPointer(interner.LoadOrStore(string([]byte("a")))))
+	assert.Equal(t, 2, interner.Len())
+}
+
+func TestShards(t *testing.T) {
+	interner := NewInterner(4096)
+
+	for n := 0; n < 1000; n++ {
+		interner.LoadOrStore(fmt.Sprintf("tag:%d", n))
+	}
+	assert.Equal(t, 1000, interner.Len())
+	for _, s := range interner.shards {
+		assert.NotEmpty(t, s.current)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	interner := NewInterner(0)
+
+	assert.Equal(t, "a", interner.LoadOrStoreBytes([]byte("a")))
+	assert.Equal(t, "a", interner.LoadOrStore("a"))
+	assert.Equal(t, 0, interner.Len())
+}
+
+func TestConcurrentLoadOrStore(t *testing.T) {
+	interner := NewInterner(4096)
+
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := 0; m < 1000; m++ {
+				tag := fmt.Sprintf("tag:%d", m%50)
+				assert.Equal(t, tag, interner.LoadOrStoreBytes([]byte(tag)))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 50, interner.Len())
+}
+
+func TestStrings(t *testing.T) {
+	assert.Nil(t, Strings(nil))
+
+	tags := []string{"env:prod", "role:db"}
+	interned := Strings(tags)
+	assert.Equal(t, tags, interned)
+	assert.Equal(t, dataPointer(String("env:prod")), dataPointer(interned[0]))
+}
+
+// the high cardinality workloads keep many contexts made of the same few tag
+// values, the benchmarks retain the tags of contexts and report the heap in
+// use once they are built
+const (
+	benchContexts  = 100000
+	benchTagValues = 100
+)
+
+func benchmarkRetainedTags(b *testing.B, parse func([]byte) string) {
+	rawTags := make([][]byte, benchTagValues)
+	for n := range rawTags {
+		rawTags[n] = []byte(fmt.Sprintf("kube_deployment:deployment-%d", n))
+	}
+
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		retained := make([]string, benchContexts)
+		for m := range retained {
+			retained[m] = parse(rawTags[m%benchTagValues])
+		}
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		b.Logf("heap in use for %d tags: %d kB", len(retained), (after.HeapInuse-before.HeapInuse)/1024)
+		runtime.KeepAlive(retained)
+	}
+}
+
+func BenchmarkRetainedTagsNotInterned(b *testing.B) {
+	benchmarkRetainedTags(b, func(tag []byte) string { return string(tag) })
+}
+
+func BenchmarkRetainedTagsInterned(b *testing.B) {
+	interner := NewInterner(benchTagValues)
+	benchmarkRetainedTags(b, interner.LoadOrStoreBytes)
+}
+
+func BenchmarkLoadOrStoreBytesParallel(b *testing.B) {
+	interner := NewInterner(benchTagValues)
+	tag := []byte("kube_deployment:deployment-1")
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			interner.LoadOrStoreBytes(tag)
+		}
+	})
+}
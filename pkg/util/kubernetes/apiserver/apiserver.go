@@ -8,6 +8,8 @@
 package apiserver
 
 import (
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -16,10 +18,13 @@ import (
 	"time"
 
 	log "github.com/cihub/seelog"
+	apps "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	k8scache "k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -42,6 +47,8 @@ const (
 	metadataPollIntl          = 20 * time.Second
 	metadataMapExpire         = 5 * time.Minute
 	metadataMapperCachePrefix = "KubernetesMetadataMapping"
+	// extensionAuthConfigMap holds the client CA of the aggregation layer
+	extensionAuthConfigMap = "extension-apiserver-authentication"
 )
 
 // APIClient provides authenticated access to the
@@ -52,15 +59,39 @@ type APIClient struct {
 
 	Client  *corev1.CoreV1Client
 	timeout time.Duration
+
+	// ClusterName is the name of the remote cluster the client connects to,
+	// it is empty for the cluster the agent runs in.
+	ClusterName       string
+	kubeconfigPath    string
+	kubeconfigContext string
+	// namespaces restricts the access to the apiserver, all of them if empty
+	namespaces []string
+
+	// informerClient has no request timeout as it holds the watch streams
+	informerClient *corev1.CoreV1Client
+	informers      map[string][]k8scache.SharedIndexInformer
+	informersStop  chan struct{}
+	informersLock  sync.RWMutex
+
+	// appsClient lists the deployments and replicasets
+	appsClient *appsv1.AppsV1Client
+
+	// endpointSlicesPath is the path of the EndpointSlices API, empty if it is not served
+	endpointSlicesPath     string
+	endpointSlicesDetected bool
+	endpointSlicesLock     sync.Mutex
 }
 
 // GetAPIClient returns the shared ApiClient instance.
 func GetAPIClient() (*APIClient, error) {
 	if globalAPIClient == nil {
 		globalAPIClient = &APIClient{
-			// TODO: make it configurable if requested
-			timeout: 5 * time.Second,
+			timeout:        clientTimeout(),
+			kubeconfigPath: config.Datadog.GetString("kubernetes_kubeconfig_path"),
+			namespaces:     config.Datadog.GetStringSlice("kubernetes_namespaces"),
 		}
+		globalTimeoutSeconds = config.Datadog.GetInt64("kubernetes_apiserver_list_timeout")
 		globalAPIClient.initRetry.SetupRetrier(&retry.Config{
 			Name:          "apiserver",
 			AttemptMethod: globalAPIClient.connect,
@@ -77,40 +108,85 @@ func GetAPIClient() (*APIClient, error) {
 	return globalAPIClient, nil
 }
 
-// getClient returns an official Kubernetes core v1 client
-func getClient() (*corev1.CoreV1Client, error) {
+// clientTimeout returns the timeout of the requests, `kubernetes_apiserver_client_timeout`.
+// It is raised above `kubernetes_apiserver_list_timeout` so the lists are ended by
+// the API server rather than cancelled by the client.
+func clientTimeout() time.Duration {
+	timeout := config.Datadog.GetInt64("kubernetes_apiserver_client_timeout")
+	if listTimeout := config.Datadog.GetInt64("kubernetes_apiserver_list_timeout"); timeout > 0 && timeout <= listTimeout {
+		timeout = listTimeout + 1
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// getClientConfig returns the configuration of the official Kubernetes clients,
+// requests are cancelled after timeout if it is not zero. The requests are rate
+// limited by `kubernetes_apiserver_client_qps` and `kubernetes_apiserver_client_burst`.
+func (c *APIClient) getClientConfig(timeout time.Duration) (*rest.Config, error) {
 	var k8sConfig *rest.Config
 	var err error
 
-	cfgPath := config.Datadog.GetString("kubernetes_kubeconfig_path")
-	if cfgPath == "" {
+	if c.kubeconfigPath == "" {
 		k8sConfig, err = rest.InClusterConfig()
 		if err != nil {
 			log.Debug("Can't create a config for the official client from the service account's token: %s", err)
 			return nil, err
 		}
 	} else {
-		// use the current context in kubeconfig
-		k8sConfig, err = clientcmd.BuildConfigFromFlags("", cfgPath)
+		// use the configured context in kubeconfig, or the current one if empty
+		k8sConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: c.kubeconfigPath},
+			&clientcmd.ConfigOverrides{CurrentContext: c.kubeconfigContext},
+		).ClientConfig()
 		if err != nil {
-			log.Debug("Can't create a config for the official client from the configured path to the kubeconfig: %s, ", cfgPath, err)
+			log.Debugf("Can't create a config for the official client from the configured path to the kubeconfig: %s, %s", c.kubeconfigPath, err)
 			return nil, err
 		}
 	}
-	k8sConfig.Timeout = 2 * time.Second
-	coreClient, err := corev1.NewForConfig(k8sConfig)
-	return coreClient, err
+	k8sConfig.Timeout = timeout
+	k8sConfig.QPS = float32(config.Datadog.GetFloat64("kubernetes_apiserver_client_qps"))
+	k8sConfig.Burst = config.Datadog.GetInt("kubernetes_apiserver_client_burst")
+	return k8sConfig, nil
+}
+
+// getClient returns an official Kubernetes core v1 client, requests are cancelled
+// after timeout if it is not zero.
+func (c *APIClient) getClient(timeout time.Duration) (*corev1.CoreV1Client, error) {
+	k8sConfig, err := c.getClientConfig(timeout)
+	if err != nil {
+		return nil, err
+	}
+	return corev1.NewForConfig(k8sConfig)
 }
 
 func (c *APIClient) connect() error {
 	var err error
 	if c.Client == nil {
-		c.Client, err = getClient()
+		c.Client, err = c.getClient(c.timeout)
 		if err != nil {
 			log.Errorf("Not Able to set up a client for the Leader Election: %s", err)
 			return err
 		}
 	}
+	if c.informerClient == nil {
+		c.informerClient, err = c.getClient(0)
+		if err != nil {
+			log.Errorf("Not Able to set up a client for the informers: %s", err)
+			return err
+		}
+	}
+	if c.appsClient == nil {
+		k8sConfig, err := c.getClientConfig(c.timeout)
+		if err != nil {
+			log.Errorf("Not Able to set up a client for the apps resources: %s", err)
+			return err
+		}
+		c.appsClient, err = appsv1.NewForConfig(k8sConfig)
+		if err != nil {
+			log.Errorf("Not Able to set up a client for the apps resources: %s", err)
+			return err
+		}
+	}
 
 	// Try to get apiserver version to confim connectivity
 	APIversion := c.Client.RESTClient().APIVersion()
@@ -152,7 +228,7 @@ func newMetadataMapperBundle() *MetadataMapperBundle {
 // node to the cache
 // Only called when the node agent computes the metadata mapper locally and does not rely on the DCA.
 func (c *APIClient) NodeMetadataMapping(nodeName string, podList *v1.PodList) error {
-	endpointList, err := c.Client.Endpoints("").List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+	endpointList, err := c.endpointsList()
 	if err != nil {
 		log.Errorf("Could not collect endpoints from the API Server: %q", err.Error())
 		return err
@@ -173,7 +249,7 @@ func (c *APIClient) NodeMetadataMapping(nodeName string, podList *v1.PodList) er
 	return nil
 }
 
-// ClusterMetadataMapping queries the Kubernetes apiserver to get the following resources:
+// ClusterMetadataMapping reads the following resources from the informers caches:
 // - all nodes
 // - all endpoints of all namespaces
 // - all pods of all namespaces
@@ -182,7 +258,7 @@ func (c *APIClient) ClusterMetadataMapping() error {
 	// A poll run should take less than the poll frequency.
 	// We fetch nodes to reliably use nodename as key in the cache.
 	// Avoiding to retrieve them from the endpoints/podList.
-	nodeList, err := c.Client.Nodes().List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+	nodeList, err := c.nodeList()
 	if err != nil {
 		log.Errorf("Could not collect nodes from the kube-apiserver: %q", err.Error())
 		return err
@@ -192,7 +268,7 @@ func (c *APIClient) ClusterMetadataMapping() error {
 		return nil
 	}
 
-	endpointList, err := c.Client.Endpoints("").List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+	endpointList, err := c.endpointsList()
 	if err != nil {
 		log.Errorf("Could not collect endpoints from the kube-apiserver: %q", err.Error())
 		return err
@@ -202,7 +278,7 @@ func (c *APIClient) ClusterMetadataMapping() error {
 		return nil
 	}
 
-	podList, err := c.Client.Pods("").List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+	podList, err := c.podList()
 	if err != nil {
 		log.Errorf("Could not collect pods from the kube-apiserver: %q", err.Error())
 		return err
@@ -238,9 +314,39 @@ func processKubeServices(nodeList *v1.NodeList, podList *v1.PodList, endpointLis
 	}
 }
 
+// PruneMetadataMapping removes from the metadataMapper cache the pods which are
+// not in podList, as mapServices only adds the pods it sees. It returns the
+// number of pods removed.
+func PruneMetadataMapping(podList *v1.PodList) int {
+	alive := make(map[string]map[string]struct{}) // node name -> pod names
+	for _, pod := range podList.Items {
+		if alive[pod.Spec.NodeName] == nil {
+			alive[pod.Spec.NodeName] = make(map[string]struct{})
+		}
+		alive[pod.Spec.NodeName][pod.Name] = struct{}{}
+	}
+
+	keyPrefix := cache.BuildAgentKey(metadataMapperCachePrefix) + "/"
+	pruned := 0
+	for key, item := range cache.Cache.Items() {
+		if !strings.HasPrefix(key, keyPrefix) {
+			continue
+		}
+		metaBundle, ok := item.Object.(*MetadataMapperBundle)
+		if !ok {
+			continue
+		}
+		pruned += metaBundle.prunePods(alive[strings.TrimPrefix(key, keyPrefix)])
+	}
+	return pruned
+}
+
 // StartMetadataMapping is only called once, when we have confirmed we could correctly connect to the API server.
 // The logic here is solely to retrieve Nodes, Pods and Endpoints. The processing part is in mapServices.
+// The resources are watched by shared informers, the apiserver is only polled until they are started.
+// It does not wait for the caches of the informers to be synced.
 func (c *APIClient) StartMetadataMapping() {
+	go c.runInformers()
 	tickerSvcProcess := time.NewTicker(metadataPollIntl)
 	go func() {
 		for {
@@ -263,31 +369,40 @@ func aggregateCheckResourcesErrors(errorMessages []string) error {
 // Depending on the user's config we only trigger an error if necessary.
 // The Event check requires getting Events data.
 // The MetadataMapper case, requires access to Services, Nodes and Pods.
+// If the access is restricted to namespaces, the resources are checked in each of
+// them and the Nodes are not checked.
 func (c *APIClient) checkResourcesAuth() error {
 	var errorMessages []string
 
 	resourceTimeoutSeconds := int64(2)
+	namespaces := c.WatchedNamespaces()
 
 	// We always want to collect events
-	_, err := c.Client.Events("").List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &resourceTimeoutSeconds})
-	if err != nil {
-		errorMessages = append(errorMessages, fmt.Sprintf("event collection: %q", err.Error()))
+	for _, namespace := range namespaces {
+		_, err := c.Client.Events(namespace).List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &resourceTimeoutSeconds})
+		if err != nil {
+			errorMessages = append(errorMessages, fmt.Sprintf("event collection: %q", err.Error()))
+		}
 	}
 
 	if config.Datadog.GetBool("kubernetes_collect_metadata_tags") == false {
 		return aggregateCheckResourcesErrors(errorMessages)
 	}
-	_, err = c.Client.Services("").List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &resourceTimeoutSeconds})
-	if err != nil {
-		errorMessages = append(errorMessages, fmt.Sprintf("service collection: %q", err.Error()))
-	}
-	_, err = c.Client.Pods("").List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &resourceTimeoutSeconds})
-	if err != nil {
-		errorMessages = append(errorMessages, fmt.Sprintf("pod collection: %q", err.Error()))
+	for _, namespace := range namespaces {
+		_, err := c.Client.Services(namespace).List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &resourceTimeoutSeconds})
+		if err != nil {
+			errorMessages = append(errorMessages, fmt.Sprintf("service collection: %q", err.Error()))
+		}
+		_, err = c.Client.Pods(namespace).List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &resourceTimeoutSeconds})
+		if err != nil {
+			errorMessages = append(errorMessages, fmt.Sprintf("pod collection: %q", err.Error()))
+		}
 	}
-	_, err = c.Client.Nodes().List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &resourceTimeoutSeconds})
-	if err != nil {
-		errorMessages = append(errorMessages, fmt.Sprintf("node collection: %q", err.Error()))
+	if !c.IsNamespaceScoped() {
+		_, err := c.Client.Nodes().List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &resourceTimeoutSeconds})
+		if err != nil {
+			errorMessages = append(errorMessages, fmt.Sprintf("node collection: %q", err.Error()))
+		}
 	}
 	return aggregateCheckResourcesErrors(errorMessages)
 }
@@ -297,6 +412,47 @@ func (c *APIClient) ComponentStatuses() (*v1.ComponentStatusList, error) {
 	return c.Client.ComponentStatuses().List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
 }
 
+// GetKubeSystemUID returns the UID of the kube-system namespace, which is unique
+// to each cluster.
+func (c *APIClient) GetKubeSystemUID() (string, error) {
+	namespace, err := c.Client.Namespaces().Get("kube-system", metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return string(namespace.UID), nil
+}
+
+// PodList returns the pods of the watched namespaces, from the informer cache if it is running
+func (c *APIClient) PodList() (*v1.PodList, error) {
+	return c.podList()
+}
+
+// DeploymentList returns the deployments of the watched namespaces from the APIServer
+func (c *APIClient) DeploymentList() (*apps.DeploymentList, error) {
+	deploymentList := &apps.DeploymentList{}
+	for _, namespace := range c.WatchedNamespaces() {
+		deployments, err := c.appsClient.Deployments(namespace).List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+		if err != nil {
+			return nil, err
+		}
+		deploymentList.Items = append(deploymentList.Items, deployments.Items...)
+	}
+	return deploymentList, nil
+}
+
+// ReplicaSetList returns the replicasets of the watched namespaces from the APIServer
+func (c *APIClient) ReplicaSetList() (*apps.ReplicaSetList, error) {
+	replicaSetList := &apps.ReplicaSetList{}
+	for _, namespace := range c.WatchedNamespaces() {
+		replicaSets, err := c.appsClient.ReplicaSets(namespace).List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+		if err != nil {
+			return nil, err
+		}
+		replicaSetList.Items = append(replicaSetList.Items, replicaSets.Items...)
+	}
+	return replicaSetList, nil
+}
+
 // GetTokenFromConfigmap returns the value of the `tokenValue` from the `tokenKey` in the ConfigMap `configMapDCAToken` if its timestamp is less than tokenTimeout old.
 func (c *APIClient) GetTokenFromConfigmap(token string, tokenTimeout int64) (string, bool, error) {
 	namespace := GetResourcesNamespace()
@@ -347,6 +503,9 @@ func (c *APIClient) UpdateTokenInConfigmap(token, tokenValue string) error {
 		return err
 	}
 
+	if tokenConfigMap.Data == nil {
+		tokenConfigMap.Data = make(map[string]string)
+	}
 	eventTokenKey := fmt.Sprintf("%s.%s", token, tokenKey)
 	tokenConfigMap.Data[eventTokenKey] = tokenValue
 
@@ -364,13 +523,39 @@ func (c *APIClient) UpdateTokenInConfigmap(token, tokenValue string) error {
 
 // NodeLabels is used to fetch the labels attached to a given node.
 func (c *APIClient) NodeLabels(nodeName string) (map[string]string, error) {
-	node, err := c.Client.Nodes().Get(nodeName, metav1.GetOptions{})
+	node, err := c.node(nodeName)
 	if err != nil {
 		return nil, err
 	}
 	return node.Labels, nil
 }
 
+// RequestHeaderClientCA returns the CA signing the client certificates of the
+// aggregation layer of the API Server, and the common names these certificates
+// can have, any if empty. They are read from the extension-apiserver-authentication
+// ConfigMap.
+func (c *APIClient) RequestHeaderClientCA() (*x509.CertPool, []string, error) {
+	cm, err := c.Client.ConfigMaps(metav1.NamespaceSystem).Get(extensionAuthConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	caPEM, found := cm.Data["requestheader-client-ca-file"]
+	if !found {
+		return nil, nil, fmt.Errorf("the ConfigMap %s has no requestheader-client-ca-file", extensionAuthConfigMap)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caPEM)) {
+		return nil, nil, fmt.Errorf("no valid certificate in the requestheader-client-ca-file of the ConfigMap %s", extensionAuthConfigMap)
+	}
+	var allowedNames []string
+	if names := cm.Data["requestheader-allowed-names"]; names != "" {
+		if err := json.Unmarshal([]byte(names), &allowedNames); err != nil {
+			return nil, nil, fmt.Errorf("invalid requestheader-allowed-names in the ConfigMap %s: %v", extensionAuthConfigMap, err)
+		}
+	}
+	return pool, allowedNames, nil
+}
+
 // GetMetadataMapBundleOnAllNodes is used for the CLI svcmap command to run fetch the metadata map of all nodes.
 func GetMetadataMapBundleOnAllNodes() (map[string]interface{}, error) {
 	nodePodMetadataMap := make(map[string]*MetadataMapperBundle)
@@ -431,7 +616,7 @@ func getNodeList() ([]v1.Node, error) {
 		log.Errorf("Can't create client to query the API Server: %s", err.Error())
 		return nil, err
 	}
-	nodes, err := cl.Client.Nodes().List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+	nodes, err := cl.nodeList()
 
 	if err != nil {
 		log.Errorf("Can't list nodes from the API server: %s", err.Error())
@@ -440,6 +625,23 @@ func getNodeList() ([]v1.Node, error) {
 	return nodes.Items, nil
 }
 
+// WatchedNamespaces returns the namespaces the resources are read from: all of them,
+// unless `kubernetes_namespaces`, or the `namespaces` of a remote cluster, restrict
+// the access to namespaced Roles.
+func (c *APIClient) WatchedNamespaces() []string {
+	if len(c.namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	return c.namespaces
+}
+
+// IsNamespaceScoped returns whether the access to the apiserver is restricted to a
+// list of namespaces, in which case the cluster-scoped resources (nodes, component
+// statuses) cannot be read.
+func (c *APIClient) IsNamespaceScoped() bool {
+	return len(c.namespaces) > 0
+}
+
 // GetResourcesNamespace is used to fetch the namespace of the resources used by the Kubernetes check (e.g. Leader Election, Event collection).
 func GetResourcesNamespace() string {
 	namespace := config.Datadog.GetString("kube_resources_namespace")
@@ -35,6 +35,18 @@ func GetPodMetadataNames(nodeName string, podName string) ([]string, error) {
 	return nil, nil
 }
 
+// GetPodServices is used when the API endpoint of the DCA to get the services of a pod is hit.
+func GetPodServices(namespace, podName string) ([]string, error) {
+	log.Errorf("GetPodServices not implemented %s", ErrNotCompiled.Error())
+	return nil, nil
+}
+
+// GetNodeLabels is used when the API endpoint of the DCA to get the labels of a node is hit.
+func GetNodeLabels(nodeName string) (map[string]string, error) {
+	log.Errorf("GetNodeLabels not implemented %s", ErrNotCompiled.Error())
+	return nil, nil
+}
+
 // GetMetadataMapBundleOnNode is used for the CLI svcmap command to output given a nodeName
 func GetMetadataMapBundleOnNode(nodeName string) (map[string]interface{}, error) {
 	log.Errorf("GetMetadataMapBundleOnNode not implemented %s", ErrNotCompiled.Error())
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+- name: remote
+  cluster:
+    server: https://10.0.0.1:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+- name: remote
+  context:
+    cluster: remote
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: abcd
+`
+
+func TestGetClientConfig(t *testing.T) {
+	kubeconfig, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err)
+	defer os.Remove(kubeconfig.Name())
+	_, err = kubeconfig.WriteString(testKubeconfig)
+	require.NoError(t, err)
+	kubeconfig.Close()
+
+	config.Datadog.Set("kubernetes_apiserver_client_qps", 50)
+	config.Datadog.Set("kubernetes_apiserver_client_burst", 100)
+	defer func() {
+		config.Datadog.Set("kubernetes_apiserver_client_qps", 5)
+		config.Datadog.Set("kubernetes_apiserver_client_burst", 10)
+	}()
+
+	c := &APIClient{kubeconfigPath: kubeconfig.Name()}
+	k8sConfig, err := c.getClientConfig(3 * time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "https://127.0.0.1:6443", k8sConfig.Host)
+	assert.Equal(t, 3*time.Second, k8sConfig.Timeout)
+	assert.Equal(t, float32(50), k8sConfig.QPS)
+	assert.Equal(t, 100, k8sConfig.Burst)
+
+	// remote clusters use the configured context of the kubeconfig
+	c = &APIClient{kubeconfigPath: kubeconfig.Name(), kubeconfigContext: "remote"}
+	k8sConfig, err = c.getClientConfig(0)
+	require.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.1:6443", k8sConfig.Host)
+
+	c = &APIClient{kubeconfigPath: kubeconfig.Name(), kubeconfigContext: "unknown"}
+	_, err = c.getClientConfig(0)
+	assert.Error(t, err)
+}
+
+func TestNewRemoteAPIClients(t *testing.T) {
+	clients, err := newRemoteAPIClients([]config.KubernetesRemoteCluster{
+		{Name: "prod", KubeconfigPath: "/etc/kubeconfig", Context: "prod"},
+		{Name: "staging", KubeconfigPath: "/etc/kubeconfig", Context: "staging", Namespaces: []string{"web"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, clients, 2)
+	assert.Equal(t, "prod", clients["prod"].ClusterName)
+	assert.Equal(t, "staging", clients["staging"].kubeconfigContext)
+	assert.False(t, clients["prod"].IsNamespaceScoped())
+	assert.Equal(t, []string{"web"}, clients["staging"].WatchedNamespaces())
+
+	_, err = newRemoteAPIClients([]config.KubernetesRemoteCluster{{Name: "prod"}})
+	assert.Error(t, err)
+
+	_, err = newRemoteAPIClients([]config.KubernetesRemoteCluster{
+		{Name: "prod", KubeconfigPath: "/etc/kubeconfig"},
+		{Name: "prod", KubeconfigPath: "/etc/kubeconfig"},
+	})
+	assert.Error(t, err)
+}
+
+func TestClientTimeout(t *testing.T) {
+	defer func() {
+		config.Datadog.Set("kubernetes_apiserver_client_timeout", 2)
+		config.Datadog.Set("kubernetes_apiserver_list_timeout", 5)
+	}()
+
+	// the lists are not cancelled before the API server ends them
+	assert.Equal(t, 6*time.Second, clientTimeout())
+
+	config.Datadog.Set("kubernetes_apiserver_client_timeout", 10)
+	assert.Equal(t, 10*time.Second, clientTimeout())
+
+	config.Datadog.Set("kubernetes_apiserver_client_timeout", 0)
+	assert.Equal(t, time.Duration(0), clientTimeout())
+}
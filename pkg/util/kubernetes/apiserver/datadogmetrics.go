@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DatadogMetricsGroupVersion is the API group and version of the DatadogMetric
+// custom resource, defined in the manifests.
+const DatadogMetricsGroupVersion = "datadoghq.com/v1alpha1"
+
+// DatadogMetric is a Datadog query which value is written to its status by the
+// cluster agent, to be served to the Horizontal Pod Autoscalers.
+type DatadogMetric struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              DatadogMetricSpec   `json:"spec"`
+	Status            DatadogMetricStatus `json:"status,omitempty"`
+}
+
+// DatadogMetricSpec holds the query of a DatadogMetric.
+type DatadogMetricSpec struct {
+	Query string `json:"query"`
+}
+
+// DatadogMetricStatus holds the last value of the query of a DatadogMetric.
+type DatadogMetricStatus struct {
+	Value string `json:"value,omitempty"`
+	Valid bool   `json:"valid"`
+	// UpdateTime is the time of the datapoint of the value
+	UpdateTime metav1.Time `json:"updateTime,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+type datadogMetricList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatadogMetric `json:"items"`
+}
+
+// ListDatadogMetrics lists the DatadogMetrics of the watched namespaces.
+func (c *APIClient) ListDatadogMetrics() ([]DatadogMetric, error) {
+	var metrics []DatadogMetric
+	for _, namespace := range c.WatchedNamespaces() {
+		body, err := c.Client.RESTClient().Get().AbsPath(datadogMetricsPath(namespace, "")).Timeout(c.timeout).DoRaw()
+		if err != nil {
+			return nil, err
+		}
+		list := &datadogMetricList{}
+		if err = json.Unmarshal(body, list); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, list.Items...)
+	}
+	return metrics, nil
+}
+
+// UpdateDatadogMetricStatus writes the status of a DatadogMetric, the update is
+// rejected if the DatadogMetric was modified since it was listed.
+func (c *APIClient) UpdateDatadogMetricStatus(metric *DatadogMetric) error {
+	body, err := json.Marshal(metric)
+	if err != nil {
+		return err
+	}
+	path := datadogMetricsPath(metric.Namespace, metric.Name) + "/status"
+	_, err = c.Client.RESTClient().Put().AbsPath(path).SetHeader("Content-Type", "application/json").Body(body).Timeout(c.timeout).DoRaw()
+	return err
+}
+
+// datadogMetricsPath returns the path of the DatadogMetrics of a namespace, or of
+// all namespaces if namespace is empty.
+func datadogMetricsPath(namespace, name string) string {
+	path := "/apis/" + DatadogMetricsGroupVersion
+	if namespace != "" {
+		path = fmt.Sprintf("%s/namespaces/%s", path, namespace)
+	}
+	path += "/datadogmetrics"
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
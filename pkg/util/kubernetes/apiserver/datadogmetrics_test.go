@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+const testDatadogMetrics = `{
+  "kind": "DatadogMetricList",
+  "items": [{
+    "metadata": {"name": "web-requests", "namespace": "app", "resourceVersion": "12"},
+    "spec": {"query": "sum:nginx.net.request_per_s{kube_service:web}"},
+    "status": {"value": "42", "valid": true}
+  }]
+}`
+
+func TestDatadogMetrics(t *testing.T) {
+	var updated DatadogMetric
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/apis/datadoghq.com/v1alpha1/datadogmetrics":
+			w.Write([]byte(testDatadogMetrics))
+		case r.Method == "PUT" && r.URL.Path == "/apis/datadoghq.com/v1alpha1/namespaces/app/datadogmetrics/web-requests/status":
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &updated)
+			w.Write(body)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	client, err := corev1.NewForConfig(&rest.Config{Host: ts.URL})
+	require.NoError(t, err)
+	c := &APIClient{Client: client}
+
+	metrics, err := c.ListDatadogMetrics()
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "sum:nginx.net.request_per_s{kube_service:web}", metrics[0].Spec.Query)
+	assert.Equal(t, "42", metrics[0].Status.Value)
+	assert.True(t, metrics[0].Status.Valid)
+
+	metrics[0].Status = DatadogMetricStatus{Error: "no datapoint"}
+	require.NoError(t, c.UpdateDatadogMetricStatus(&metrics[0]))
+	assert.Equal(t, "12", updated.ResourceVersion)
+	assert.Equal(t, "no datapoint", updated.Status.Error)
+	assert.False(t, updated.Status.Valid)
+
+	metrics[0].Name = "unknown"
+	assert.Error(t, c.UpdateDatadogMetricStatus(&metrics[0]))
+}
+
+func TestDatadogMetricsPath(t *testing.T) {
+	assert.Equal(t, "/apis/datadoghq.com/v1alpha1/datadogmetrics", datadogMetricsPath("", ""))
+	assert.Equal(t, "/apis/datadoghq.com/v1alpha1/namespaces/app/datadogmetrics/web", datadogMetricsPath("app", "web"))
+}
@@ -0,0 +1,382 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
+	"k8s.io/apimachinery/pkg/util/framer"
+	"k8s.io/apimachinery/pkg/watch"
+	restclientwatch "k8s.io/client-go/rest/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	// serviceNameLabel is the label of the EndpointSlices with the name of their service
+	serviceNameLabel = "kubernetes.io/service-name"
+	// hostnameTopologyKey holds the node of an endpoint in the v1beta1 API
+	hostnameTopologyKey = "kubernetes.io/hostname"
+
+	endpointSlicesPageSize = 500
+)
+
+// endpointSlicesVersions are the versions of the discovery.k8s.io API group
+// serving the EndpointSlices, by order of preference.
+var endpointSlicesVersions = []string{"v1", "v1beta1"}
+
+// EndpointSlice mirrors the EndpointSlice type of the discovery.k8s.io API, its
+// v1beta1 and v1 versions only differ by the fields of the node of the endpoints.
+type EndpointSlice struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	AddressType       string                  `json:"addressType"`
+	Endpoints         []EndpointSliceEndpoint `json:"endpoints"`
+	Ports             []EndpointSlicePort     `json:"ports"`
+}
+
+// EndpointSliceEndpoint is an endpoint of an EndpointSlice.
+type EndpointSliceEndpoint struct {
+	Addresses  []string            `json:"addresses"`
+	Conditions EndpointConditions  `json:"conditions,omitempty"`
+	Hostname   *string             `json:"hostname,omitempty"`
+	TargetRef  *v1.ObjectReference `json:"targetRef,omitempty"`
+	// NodeName is set by the v1 API, Topology by the v1beta1 one
+	NodeName *string           `json:"nodeName,omitempty"`
+	Topology map[string]string `json:"topology,omitempty"`
+}
+
+// EndpointConditions are the conditions of an endpoint, it is ready if unset.
+type EndpointConditions struct {
+	Ready *bool `json:"ready,omitempty"`
+}
+
+// EndpointSlicePort is a port of an EndpointSlice.
+type EndpointSlicePort struct {
+	Name     *string      `json:"name,omitempty"`
+	Protocol *v1.Protocol `json:"protocol,omitempty"`
+	Port     *int32       `json:"port,omitempty"`
+}
+
+type endpointSliceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EndpointSlice `json:"items"`
+}
+
+// endpointSlicesAPI returns the path of the API serving the EndpointSlices, or an
+// empty string if the apiserver does not serve them or they are disabled with
+// `kubernetes_use_endpoint_slices`. The API is detected again on the next call
+// when the apiserver could not be queried.
+func (c *APIClient) endpointSlicesAPI() string {
+	if !config.Datadog.GetBool("kubernetes_use_endpoint_slices") {
+		return ""
+	}
+
+	c.endpointSlicesLock.Lock()
+	defer c.endpointSlicesLock.Unlock()
+	if c.endpointSlicesDetected {
+		return c.endpointSlicesPath
+	}
+	for _, version := range endpointSlicesVersions {
+		path := "/apis/discovery.k8s.io/" + version
+		err := c.Client.RESTClient().Get().AbsPath(path).Timeout(c.timeout).Do().Error()
+		if err == nil {
+			log.Infof("Reading the endpoints from the EndpointSlices of %s", path)
+			c.endpointSlicesPath = path
+			c.endpointSlicesDetected = true
+			return path
+		}
+		if !errors.IsNotFound(err) {
+			log.Debugf("Could not detect the EndpointSlices API, reading the Endpoints until the next attempt: %s", err)
+			return ""
+		}
+	}
+	log.Debugf("The EndpointSlices API is not available, reading the Endpoints")
+	c.endpointSlicesDetected = true
+	return ""
+}
+
+// endpointSlicesResourcePath returns the path of the EndpointSlices of a
+// namespace, or of all namespaces if namespace is empty.
+func endpointSlicesResourcePath(apiPath, namespace string) string {
+	if namespace == "" {
+		return apiPath + "/endpointslices"
+	}
+	return fmt.Sprintf("%s/namespaces/%s/endpointslices", apiPath, namespace)
+}
+
+// endpointSliceList lists the EndpointSlices of the watched namespaces, it is
+// used when the informers are not running.
+func (c *APIClient) endpointSliceList(apiPath string) ([]EndpointSlice, error) {
+	var slices []EndpointSlice
+	for _, namespace := range c.WatchedNamespaces() {
+		list, err := c.listEndpointSlices(apiPath, namespace)
+		if err != nil {
+			return nil, err
+		}
+		slices = append(slices, list.Items...)
+	}
+	return slices, nil
+}
+
+// listEndpointSlices lists the EndpointSlices of a namespace by pages, as large
+// clusters have many of them. The list has the resource version of the last page.
+func (c *APIClient) listEndpointSlices(apiPath, namespace string) (*endpointSliceList, error) {
+	result := &endpointSliceList{}
+	continueToken := ""
+	for {
+		req := c.Client.RESTClient().Get().AbsPath(endpointSlicesResourcePath(apiPath, namespace)).
+			Param("limit", strconv.Itoa(endpointSlicesPageSize)).
+			Param("timeoutSeconds", strconv.FormatInt(globalTimeoutSeconds, 10))
+		if continueToken != "" {
+			req = req.Param("continue", continueToken)
+		}
+		body, err := req.Timeout(c.timeout).DoRaw()
+		if err != nil {
+			return nil, err
+		}
+		page := &endpointSliceList{}
+		if err = json.Unmarshal(body, page); err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, page.Items...)
+		result.ResourceVersion = page.ResourceVersion
+		continueToken = page.Continue
+		if continueToken == "" {
+			return result, nil
+		}
+	}
+}
+
+// watchEndpointSlices watches the EndpointSlices of a namespace from a resource version.
+func (c *APIClient) watchEndpointSlices(apiPath, namespace string, options metav1.ListOptions) (watch.Interface, error) {
+	req := c.informerClient.RESTClient().Get().AbsPath(endpointSlicesResourcePath(apiPath, namespace)).
+		Param("watch", "true").
+		Param("resourceVersion", options.ResourceVersion)
+	if options.TimeoutSeconds != nil {
+		req = req.Param("timeoutSeconds", strconv.FormatInt(*options.TimeoutSeconds, 10))
+	}
+	stream, err := req.Stream()
+	if err != nil {
+		return nil, err
+	}
+	decoder := streaming.NewDecoder(framer.NewJSONFramedReader(stream), endpointSliceDecoder{})
+	return watch.NewStreamWatcher(restclientwatch.NewDecoder(decoder, endpointSliceDecoder{})), nil
+}
+
+// newEndpointSlicesInformer returns a shared informer watching the EndpointSlices
+// of a namespace, indexed by the pods they target. The EndpointSlices are not
+// known to the typed clients of this version of client-go, they are listed and
+// watched with raw requests.
+func (c *APIClient) newEndpointSlicesInformer(apiPath, namespace string, resync time.Duration) cache.SharedIndexInformer {
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.listEndpointSlices(apiPath, namespace)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.watchEndpointSlices(apiPath, namespace, options)
+		},
+	}
+	return cache.NewSharedIndexInformer(listWatch, &EndpointSlice{}, resync, cache.Indexers{endpointsPodIndex: endpointSlicesPodIndexFunc})
+}
+
+// endpointSlicesPodIndexFunc returns the namespace/name of the pods targeted by
+// the ready endpoints of an EndpointSlice of a service.
+func endpointSlicesPodIndexFunc(obj interface{}) ([]string, error) {
+	slice, ok := obj.(*EndpointSlice)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	if slice.Labels[serviceNameLabel] == "" {
+		return nil, nil
+	}
+	var pods []string
+	for _, endpoint := range slice.Endpoints {
+		ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+		if ready && endpoint.TargetRef != nil && endpoint.TargetRef.Kind == "Pod" {
+			pods = append(pods, fmt.Sprintf("%s/%s", endpoint.TargetRef.Namespace, endpoint.TargetRef.Name))
+		}
+	}
+	return pods, nil
+}
+
+// endpointSliceDecoder decodes the EndpointSlices and the events of their watch
+// from JSON, as they are not registered in the scheme of the clients.
+type endpointSliceDecoder struct{}
+
+// Decode implements runtime.Decoder, the errors of a watch are decoded to a Status.
+func (endpointSliceDecoder) Decode(data []byte, _ *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	if into == nil {
+		typeMeta := metav1.TypeMeta{}
+		if err := json.Unmarshal(data, &typeMeta); err != nil {
+			return nil, nil, err
+		}
+		if typeMeta.Kind == "Status" {
+			into = &metav1.Status{}
+		} else {
+			into = &EndpointSlice{}
+		}
+	}
+	if err := json.Unmarshal(data, into); err != nil {
+		return nil, nil, err
+	}
+	return into, nil, nil
+}
+
+// DeepCopyObject implements runtime.Object.
+func (s *EndpointSlice) DeepCopyObject() runtime.Object {
+	out := &EndpointSlice{
+		TypeMeta:    s.TypeMeta,
+		AddressType: s.AddressType,
+	}
+	s.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if s.Endpoints != nil {
+		out.Endpoints = make([]EndpointSliceEndpoint, len(s.Endpoints))
+		for i, endpoint := range s.Endpoints {
+			out.Endpoints[i] = EndpointSliceEndpoint{
+				Addresses:  append([]string(nil), endpoint.Addresses...),
+				Conditions: EndpointConditions{Ready: copyBool(endpoint.Conditions.Ready)},
+				Hostname:   copyString(endpoint.Hostname),
+				NodeName:   copyString(endpoint.NodeName),
+			}
+			if endpoint.TargetRef != nil {
+				out.Endpoints[i].TargetRef = endpoint.TargetRef.DeepCopy()
+			}
+			if endpoint.Topology != nil {
+				out.Endpoints[i].Topology = make(map[string]string, len(endpoint.Topology))
+				for k, v := range endpoint.Topology {
+					out.Endpoints[i].Topology[k] = v
+				}
+			}
+		}
+	}
+	if s.Ports != nil {
+		out.Ports = make([]EndpointSlicePort, len(s.Ports))
+		for i, port := range s.Ports {
+			out.Ports[i] = EndpointSlicePort{Name: copyString(port.Name)}
+			if port.Protocol != nil {
+				protocol := *port.Protocol
+				out.Ports[i].Protocol = &protocol
+			}
+			if port.Port != nil {
+				number := *port.Port
+				out.Ports[i].Port = &number
+			}
+		}
+	}
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (l *endpointSliceList) DeepCopyObject() runtime.Object {
+	out := &endpointSliceList{TypeMeta: l.TypeMeta}
+	l.ListMeta.DeepCopyInto(&out.ListMeta)
+	if l.Items != nil {
+		out.Items = make([]EndpointSlice, len(l.Items))
+		for i := range l.Items {
+			out.Items[i] = *l.Items[i].DeepCopyObject().(*EndpointSlice)
+		}
+	}
+	return out
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+func copyBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	return &c
+}
+
+// endpointsFromSlices merges the EndpointSlices of each service into an Endpoints
+// object named after it, so that they are consumed like the Endpoints. Each slice
+// becomes a subset, the slices of FQDN addresses are ignored.
+func endpointsFromSlices(slices []EndpointSlice) *v1.EndpointsList {
+	endpointsList := &v1.EndpointsList{}
+	services := make(map[string]int) // namespace/name -> index in the list
+	for i := range slices {
+		slice := &slices[i]
+		service := slice.Labels[serviceNameLabel]
+		if service == "" || slice.AddressType == "FQDN" {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", slice.Namespace, service)
+		index, found := services[key]
+		if !found {
+			endpointsList.Items = append(endpointsList.Items, v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: service, Namespace: slice.Namespace},
+			})
+			index = len(endpointsList.Items) - 1
+			services[key] = index
+		}
+		endpointsList.Items[index].Subsets = append(endpointsList.Items[index].Subsets, slice.subset())
+	}
+	return endpointsList
+}
+
+// subset converts an EndpointSlice to an endpoints subset.
+func (s *EndpointSlice) subset() v1.EndpointSubset {
+	subset := v1.EndpointSubset{}
+	for _, endpoint := range s.Endpoints {
+		nodeName := endpoint.NodeName
+		if nodeName == nil {
+			if hostname, found := endpoint.Topology[hostnameTopologyKey]; found {
+				nodeName = &hostname
+			}
+		}
+		for _, ip := range endpoint.Addresses {
+			address := v1.EndpointAddress{
+				IP:        ip,
+				NodeName:  nodeName,
+				TargetRef: endpoint.TargetRef,
+			}
+			if endpoint.Hostname != nil {
+				address.Hostname = *endpoint.Hostname
+			}
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				subset.Addresses = append(subset.Addresses, address)
+			} else {
+				subset.NotReadyAddresses = append(subset.NotReadyAddresses, address)
+			}
+		}
+	}
+	for _, port := range s.Ports {
+		endpointPort := v1.EndpointPort{}
+		if port.Name != nil {
+			endpointPort.Name = *port.Name
+		}
+		if port.Port != nil {
+			endpointPort.Port = *port.Port
+		}
+		if port.Protocol != nil {
+			endpointPort.Protocol = *port.Protocol
+		}
+		subset.Ports = append(subset.Ports, endpointPort)
+	}
+	return subset
+}
@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+// the second page has a slice of the v1beta1 API, with the node in its topology
+var testEndpointSlicesPages = map[string]string{
+	"": `{
+  "kind": "EndpointSliceList",
+  "metadata": {"continue": "page2"},
+  "items": [{
+    "metadata": {"name": "web-abcde", "namespace": "default", "labels": {"kubernetes.io/service-name": "web"}},
+    "addressType": "IPv4",
+    "endpoints": [
+      {"addresses": ["10.0.0.1"], "conditions": {"ready": true}, "nodeName": "node1",
+       "targetRef": {"kind": "Pod", "namespace": "default", "name": "web-1"}},
+      {"addresses": ["10.0.0.2"], "conditions": {"ready": false}, "nodeName": "node2",
+       "targetRef": {"kind": "Pod", "namespace": "default", "name": "web-2"}}
+    ],
+    "ports": [{"name": "http", "protocol": "TCP", "port": 8080}]
+  }, {
+    "metadata": {"name": "external-xyz", "namespace": "default", "labels": {"kubernetes.io/service-name": "external"}},
+    "addressType": "FQDN",
+    "endpoints": [{"addresses": ["example.com"]}]
+  }]
+}`,
+	"page2": `{
+  "kind": "EndpointSliceList",
+  "items": [{
+    "metadata": {"name": "web-fghij", "namespace": "default", "labels": {"kubernetes.io/service-name": "web"}},
+    "addressType": "IPv4",
+    "endpoints": [
+      {"addresses": ["10.0.0.3"], "topology": {"kubernetes.io/hostname": "node1"},
+       "targetRef": {"kind": "Pod", "namespace": "default", "name": "web-3"}}
+    ]
+  }]
+}`,
+}
+
+func newEndpointSlicesTestClient(t *testing.T, version string) (*APIClient, func()) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/discovery.k8s.io/" + version:
+			w.Write([]byte("{}"))
+		case "/apis/discovery.k8s.io/" + version + "/endpointslices":
+			assert.Equal(t, "500", r.URL.Query().Get("limit"))
+			w.Write([]byte(testEndpointSlicesPages[r.URL.Query().Get("continue")]))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	client, err := corev1.NewForConfig(&rest.Config{Host: ts.URL})
+	require.NoError(t, err)
+	return &APIClient{Client: client}, ts.Close
+}
+
+func TestEndpointSlicesAPI(t *testing.T) {
+	c, stop := newEndpointSlicesTestClient(t, "v1beta1")
+	assert.Equal(t, "/apis/discovery.k8s.io/v1beta1", c.endpointSlicesAPI())
+	stop()
+
+	c, stop = newEndpointSlicesTestClient(t, "v1")
+	assert.Equal(t, "/apis/discovery.k8s.io/v1", c.endpointSlicesAPI())
+	stop()
+
+	c, stop = newEndpointSlicesTestClient(t, "v1alpha1")
+	assert.Equal(t, "", c.endpointSlicesAPI())
+	stop()
+}
+
+func TestEndpointsFromSlices(t *testing.T) {
+	c, stop := newEndpointSlicesTestClient(t, "v1")
+	defer stop()
+
+	endpointsList, err := c.endpointsList()
+	require.NoError(t, err)
+	// the slices of the service are merged, FQDN ones are ignored
+	require.Len(t, endpointsList.Items, 1)
+	web := endpointsList.Items[0]
+	assert.Equal(t, "web", web.Name)
+	assert.Equal(t, "default", web.Namespace)
+	require.Len(t, web.Subsets, 2)
+
+	require.Len(t, web.Subsets[0].Addresses, 1)
+	assert.Equal(t, "10.0.0.1", web.Subsets[0].Addresses[0].IP)
+	assert.Equal(t, "node1", *web.Subsets[0].Addresses[0].NodeName)
+	require.Len(t, web.Subsets[0].NotReadyAddresses, 1)
+	assert.Equal(t, "web-2", web.Subsets[0].NotReadyAddresses[0].TargetRef.Name)
+	assert.Equal(t, []v1.EndpointPort{{Name: "http", Protocol: v1.ProtocolTCP, Port: 8080}}, web.Subsets[0].Ports)
+
+	require.Len(t, web.Subsets[1].Addresses, 1)
+	assert.Equal(t, "node1", *web.Subsets[1].Addresses[0].NodeName)
+
+	services, err := c.PodServices("default", "web-3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, services)
+
+	// only the ready addresses are mapped to the services
+	services, err = c.PodServices("default", "web-2")
+	require.NoError(t, err)
+	assert.Len(t, services, 0)
+}
+
+func TestEndpointSlicesAPIRetried(t *testing.T) {
+	available := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+	client, err := corev1.NewForConfig(&rest.Config{Host: ts.URL})
+	require.NoError(t, err)
+	c := &APIClient{Client: client}
+
+	// a failure of the apiserver is not cached
+	assert.Equal(t, "", c.endpointSlicesAPI())
+	available = true
+	assert.Equal(t, "/apis/discovery.k8s.io/v1", c.endpointSlicesAPI())
+}
+
+func TestPodServicesFromEndpointSlicesInformer(t *testing.T) {
+	slices := &endpointSliceList{}
+	require.NoError(t, json.Unmarshal([]byte(testEndpointSlicesPages[""]), slices))
+	page2 := &endpointSliceList{}
+	require.NoError(t, json.Unmarshal([]byte(testEndpointSlicesPages["page2"]), page2))
+	slices.Items = append(slices.Items, page2.Items...)
+
+	var objects []interface{}
+	for i := range slices.Items {
+		objects = append(objects, slices.Items[i].DeepCopyObject())
+	}
+	c := &APIClient{
+		informers: map[string][]cache.SharedIndexInformer{
+			endpointSlicesInformer: {newFilledInformer(t, &EndpointSlice{}, cache.Indexers{endpointsPodIndex: endpointSlicesPodIndexFunc}, objects...)},
+		},
+	}
+
+	services, err := c.PodServices("default", "web-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, services)
+
+	// only the ready endpoints are mapped to the services
+	services, err = c.PodServices("default", "web-2")
+	require.NoError(t, err)
+	assert.Len(t, services, 0)
+
+	endpointsList, err := c.endpointsList()
+	require.NoError(t, err)
+	require.Len(t, endpointsList.Items, 1)
+	assert.Len(t, endpointsList.Items[0].Subsets, 2)
+}
+
+func TestEndpointSliceDecoder(t *testing.T) {
+	obj, _, err := endpointSliceDecoder{}.Decode([]byte(`{"metadata": {"name": "web-abcde", "namespace": "default"}, "addressType": "IPv4"}`), nil, nil)
+	require.NoError(t, err)
+	slice, ok := obj.(*EndpointSlice)
+	require.True(t, ok)
+	assert.Equal(t, "web-abcde", slice.Name)
+
+	obj, _, err = endpointSliceDecoder{}.Decode([]byte(`{"kind": "Status", "status": "Failure", "reason": "Expired"}`), nil, nil)
+	require.NoError(t, err)
+	status, ok := obj.(*metav1.Status)
+	require.True(t, ok)
+	assert.Equal(t, metav1.StatusReasonExpired, status.Reason)
+}
@@ -10,11 +10,13 @@ package apiserver
 //// Covered by test/integration/util/kube_apiserver/events_test.go
 
 import (
+	"encoding/json"
 	log "github.com/cihub/seelog"
 	"github.com/pkg/errors"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
+	"net/http"
 	"strconv"
 	"time"
 )
@@ -25,8 +27,77 @@ var eventReadTimeout = 100 * time.Millisecond
 // First slice is the new events, second slice the modified events.
 // If the `since` parameter is empty, we query the apiserver's cache to avoid
 // overloading it.
+// If the access is restricted to namespaces, the events of each of them are
+// watched from their own resversion: the token is then the JSON map of the
+// resversions by namespace, as a single resversion would either skip or
+// resubmit the events of the namespaces which were not the most recent.
 // https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.9/#watch-list-289
 func (c *APIClient) LatestEvents(since string) ([]*v1.Event, []*v1.Event, string, error) {
+	namespaces := c.WatchedNamespaces()
+	if len(namespaces) == 1 {
+		return c.latestNamespaceEvents(namespaces[0], since)
+	}
+
+	var addedEvents, modifiedEvents []*v1.Event
+	sinceTokens := parseNamespaceTokens(since, namespaces)
+	tokens := make(map[string]string, len(namespaces))
+	for _, namespace := range namespaces {
+		added, modified, token, err := c.latestNamespaceEvents(namespace, sinceTokens[namespace])
+		if err != nil {
+			return nil, nil, "0", err
+		}
+		addedEvents = append(addedEvents, added...)
+		modifiedEvents = append(modifiedEvents, modified...)
+		tokens[namespace] = token
+	}
+	return addedEvents, modifiedEvents, formatNamespaceTokens(tokens), nil
+}
+
+// parseNamespaceTokens returns the resversion to resume each namespace from.
+// A token which is not a map of resversions (the cache "0", or a checkpoint
+// stored before the access was restricted) is used for all the namespaces.
+func parseNamespaceTokens(since string, namespaces []string) map[string]string {
+	tokens := make(map[string]string, len(namespaces))
+	if err := json.Unmarshal([]byte(since), &tokens); err != nil || len(tokens) == 0 {
+		for _, namespace := range namespaces {
+			tokens[namespace] = since
+		}
+		return tokens
+	}
+	for _, namespace := range namespaces {
+		if _, found := tokens[namespace]; !found {
+			// Namespace added since the checkpoint, starting from the cache.
+			tokens[namespace] = "0"
+		}
+	}
+	return tokens
+}
+
+// formatNamespaceTokens serialises the resversions of the namespaces into a
+// single token, the keys are sorted so identical resversions give identical tokens.
+// It returns "0" when every namespace has to be resumed from the cache.
+func formatNamespaceTokens(tokens map[string]string) string {
+	fromCache := true
+	for _, token := range tokens {
+		if token != "0" {
+			fromCache = false
+			break
+		}
+	}
+	if fromCache {
+		return "0"
+	}
+	serialised, err := json.Marshal(tokens)
+	if err != nil {
+		log.Errorf("Could not serialise the event tokens %v: %s", tokens, err)
+		return "0"
+	}
+	return string(serialised)
+}
+
+// latestNamespaceEvents retrieves the events of a namespace happening after a given
+// token, or the events of all the namespaces if it is metav1.NamespaceAll.
+func (c *APIClient) latestNamespaceEvents(namespace, since string) ([]*v1.Event, []*v1.Event, string, error) {
 	var addedEvents, modifiedEvents []*v1.Event
 
 	// If `since` is "" strconv.Atoi(*latestResVersion) below will panic as we evaluate the error.
@@ -40,7 +111,7 @@ func (c *APIClient) LatestEvents(since string) ([]*v1.Event, []*v1.Event, string
 		log.Errorf("The cached event token could not be parsed: %s, pulling events from the API server's cache", err)
 	}
 
-	eventWatcher, errs := c.Client.Events("").Watch(metav1.ListOptions{Watch: true, ResourceVersion: since})
+	eventWatcher, errs := c.Client.Events(namespace).Watch(metav1.ListOptions{Watch: true, ResourceVersion: since})
 	if errs != nil {
 		log.Debugf("error getting watcher")
 	}
@@ -53,12 +124,18 @@ func (c *APIClient) LatestEvents(since string) ([]*v1.Event, []*v1.Event, string
 				if !ok {
 					return nil, nil, "0", errors.New("could not parse status of error event from the API Server.") // TODO custom error
 				}
-				if errEvent.Reason == "Expired" {
-					// Known issue with ETCD https://github.com/kubernetes/kubernetes/issues/45506
-					// Once we have started the event watcher, we do not expect the resversion to be "0"
-					// Except when initializing. Forcing to 0 so we can keep on watching without hitting the cache.
-					log.Tracef("Resversion expired: %s", errEvent.Message)
-					resVersionCached = 0
+				if errEvent.Code == http.StatusGone || errEvent.Reason == metav1.StatusReasonExpired || errEvent.Reason == metav1.StatusReasonGone {
+					// The resversion we resume from is older than the history kept by the API Server
+					// (known issue with ETCD https://github.com/kubernetes/kubernetes/issues/45506).
+					// Re-listing the events and using the resversion of the list as a bookmark
+					// so we neither miss the events since the checkpoint nor resubmit older ones.
+					log.Debugf("Resversion %d expired, listing the events: %s", resVersionCached, errEvent.Message)
+					eventWatcher.Stop()
+					listedEvents, listResVersion, err := c.listEventsSince(namespace, resVersionCached)
+					if err != nil {
+						return addedEvents, modifiedEvents, "0", err
+					}
+					return append(addedEvents, listedEvents...), modifiedEvents, listResVersion, nil
 				}
 				// We continue here to avoid casting into a *v1.Event.
 				// In this case, the event is of type *metav1.Status.
@@ -98,3 +175,30 @@ func (c *APIClient) LatestEvents(since string) ([]*v1.Event, []*v1.Event, string
 		}
 	}
 }
+
+// listEventsSince lists all the events of a namespace from the API Server and returns
+// the ones more recent than the `since` resversion, along with the resversion of the
+// list to resume watching from.
+func (c *APIClient) listEventsSince(namespace string, since int) ([]*v1.Event, string, error) {
+	eventList, err := c.Client.Events(namespace).List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+	if err != nil {
+		return nil, "0", err
+	}
+	return filterEventsSince(eventList.Items, since), eventList.ResourceVersion, nil
+}
+
+// filterEventsSince returns the events which resversion is more recent than `since`.
+func filterEventsSince(events []v1.Event, since int) []*v1.Event {
+	var filtered []*v1.Event
+	for i := range events {
+		evResVer, err := strconv.Atoi(events[i].ResourceVersion)
+		if err != nil {
+			log.Debugf("Could not parse the resversion of the event %s: %s", events[i].Name, err)
+			continue
+		}
+		if evResVer > since {
+			filtered = append(filtered, &events[i])
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFilterEventsSince(t *testing.T) {
+	events := []v1.Event{
+		{ObjectMeta: metav1.ObjectMeta{Name: "old", ResourceVersion: "100"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "checkpoint", ResourceVersion: "120"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "invalid", ResourceVersion: "foo"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "new", ResourceVersion: "135"}},
+	}
+
+	filtered := filterEventsSince(events, 120)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "new", filtered[0].Name)
+
+	assert.Len(t, filterEventsSince(events, 0), 3)
+	assert.Len(t, filterEventsSince(events, 200), 0)
+}
+
+func TestNamespaceTokens(t *testing.T) {
+	namespaces := []string{"default", "web"}
+
+	// The cache, or a checkpoint stored before the access was restricted
+	assert.Equal(t, map[string]string{"default": "0", "web": "0"}, parseNamespaceTokens("0", namespaces))
+	assert.Equal(t, map[string]string{"default": "1200", "web": "1200"}, parseNamespaceTokens("1200", namespaces))
+	assert.Equal(t, map[string]string{"default": "", "web": ""}, parseNamespaceTokens("", namespaces))
+
+	token := formatNamespaceTokens(map[string]string{"web": "1500", "default": "1200"})
+	assert.Equal(t, `{"default":"1200","web":"1500"}`, token)
+	assert.Equal(t, map[string]string{"default": "1200", "web": "1500"}, parseNamespaceTokens(token, namespaces))
+
+	// A namespace added since the checkpoint starts from the cache
+	assert.Equal(t, map[string]string{"default": "1200", "web": "1500", "staging": "0"}, parseNamespaceTokens(token, append(namespaces, "staging")))
+
+	assert.Equal(t, "0", formatNamespaceTokens(map[string]string{"default": "0", "web": "0"}))
+}
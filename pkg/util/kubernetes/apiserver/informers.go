@@ -0,0 +1,393 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	podsInformer      = "pods"
+	servicesInformer  = "services"
+	endpointsInformer = "endpoints"
+	nodesInformer     = "nodes"
+	// endpointSlicesInformer replaces the endpoints one when the EndpointSlices are served
+	endpointSlicesInformer = "endpointslices"
+
+	informerSyncTimeout = 30 * time.Second
+	// informerRetryInterval is the time between two attempts to start the
+	// informers when their caches could not be synced
+	informerRetryInterval = time.Minute
+
+	// endpointsPodIndex indexes the endpoints and the EndpointSlices by the pods they target
+	endpointsPodIndex = "pod"
+)
+
+// startInformers creates the shared informers watching pods, services, endpoints
+// (or the EndpointSlices when they are served) and nodes, and waits for their caches
+// to be filled. All consumers of the APIClient then read from these caches instead
+// of listing the resources from the apiserver.
+// Namespaced resources are watched by one informer per watched namespace, nodes are
+// not watched if the access is restricted to namespaces.
+func (c *APIClient) startInformers() error {
+	c.informersLock.Lock()
+	if c.informersStop != nil {
+		c.informersLock.Unlock()
+		return nil
+	}
+	c.informersStop = make(chan struct{})
+	c.informersLock.Unlock()
+
+	resync := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period")) * time.Second
+
+	// The EndpointSlices are watched instead of the Endpoints when they are served
+	endpointSlicesAPI := c.endpointSlicesAPI()
+
+	informers := make(map[string][]cache.SharedIndexInformer)
+	for _, namespace := range c.WatchedNamespaces() {
+		informers[podsInformer] = append(informers[podsInformer], c.newInformer("pods", namespace, &v1.Pod{}, resync, cache.Indexers{}))
+		informers[servicesInformer] = append(informers[servicesInformer], c.newInformer("services", namespace, &v1.Service{}, resync, cache.Indexers{}))
+		if endpointSlicesAPI == "" {
+			informers[endpointsInformer] = append(informers[endpointsInformer], c.newInformer("endpoints", namespace, &v1.Endpoints{}, resync, cache.Indexers{endpointsPodIndex: endpointsPodIndexFunc}))
+		} else {
+			informers[endpointSlicesInformer] = append(informers[endpointSlicesInformer], c.newEndpointSlicesInformer(endpointSlicesAPI, namespace, resync))
+		}
+	}
+	if !c.IsNamespaceScoped() {
+		informers[nodesInformer] = []cache.SharedIndexInformer{c.newInformer("nodes", metav1.NamespaceAll, &v1.Node{}, resync, cache.Indexers{})}
+	}
+
+	var synced []cache.InformerSynced
+	for _, resourceInformers := range informers {
+		for _, informer := range resourceInformers {
+			go informer.Run(c.informersStop)
+			synced = append(synced, informer.HasSynced)
+		}
+	}
+
+	syncStop := make(chan struct{})
+	syncTimer := time.AfterFunc(informerSyncTimeout, func() { close(syncStop) })
+	defer syncTimer.Stop()
+	if !cache.WaitForCacheSync(syncStop, synced...) {
+		// stop the informers and allow a new attempt
+		c.informersLock.Lock()
+		close(c.informersStop)
+		c.informersStop = nil
+		c.informersLock.Unlock()
+		return fmt.Errorf("informers caches could not be synced in %s", informerSyncTimeout)
+	}
+
+	c.informersLock.Lock()
+	c.informers = informers
+	c.informersLock.Unlock()
+	log.Debugf("Informers caches synced, resync period is %s", resync)
+	return nil
+}
+
+// runInformers starts the informers, and tries again every informerRetryInterval
+// until their caches are synced. The resources are polled from the apiserver
+// meanwhile.
+func (c *APIClient) runInformers() {
+	for {
+		err := c.startInformers()
+		if err == nil {
+			return
+		}
+		log.Errorf("Could not start the informers, polling the resources from the API Server, retrying in %s: %s", informerRetryInterval, err)
+		time.Sleep(informerRetryInterval)
+	}
+}
+
+// newInformer returns a shared informer watching the given resource on a namespace,
+// or on all namespaces if it is metav1.NamespaceAll.
+func (c *APIClient) newInformer(resource, namespace string, objType runtime.Object, resync time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	listWatch := cache.NewListWatchFromClient(c.informerClient.RESTClient(), resource, namespace, fields.Everything())
+	return cache.NewSharedIndexInformer(listWatch, objType, resync, indexers)
+}
+
+// endpointsPodIndexFunc returns the namespace/name of the pods targeted by the
+// ready addresses of an endpoints object.
+func endpointsPodIndexFunc(obj interface{}) ([]string, error) {
+	endpoints, ok := obj.(*v1.Endpoints)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	var pods []string
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+				pods = append(pods, fmt.Sprintf("%s/%s", address.TargetRef.Namespace, address.TargetRef.Name))
+			}
+		}
+	}
+	return pods, nil
+}
+
+// getInformers returns the running informers of a resource, if any.
+func (c *APIClient) getInformers(name string) ([]cache.SharedIndexInformer, bool) {
+	c.informersLock.RLock()
+	defer c.informersLock.RUnlock()
+	informers, found := c.informers[name]
+	return informers, found
+}
+
+// listInformers returns the objects of the stores of all the informers of a resource.
+func listInformers(informers []cache.SharedIndexInformer) []interface{} {
+	var objects []interface{}
+	for _, informer := range informers {
+		objects = append(objects, informer.GetStore().List()...)
+	}
+	return objects
+}
+
+// podList returns the pods from the informer cache, or from the apiserver if the
+// informers are not running.
+func (c *APIClient) podList() (*v1.PodList, error) {
+	informers, found := c.getInformers(podsInformer)
+	podList := &v1.PodList{}
+	if !found {
+		for _, namespace := range c.WatchedNamespaces() {
+			pods, err := c.Client.Pods(namespace).List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+			if err != nil {
+				return nil, err
+			}
+			podList.Items = append(podList.Items, pods.Items...)
+		}
+		return podList, nil
+	}
+	for _, obj := range listInformers(informers) {
+		podList.Items = append(podList.Items, *obj.(*v1.Pod))
+	}
+	return podList, nil
+}
+
+// serviceList returns the services from the informer cache, or from the apiserver if the
+// informers are not running.
+func (c *APIClient) serviceList() (*v1.ServiceList, error) {
+	informers, found := c.getInformers(servicesInformer)
+	serviceList := &v1.ServiceList{}
+	if !found {
+		for _, namespace := range c.WatchedNamespaces() {
+			services, err := c.Client.Services(namespace).List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+			if err != nil {
+				return nil, err
+			}
+			serviceList.Items = append(serviceList.Items, services.Items...)
+		}
+		return serviceList, nil
+	}
+	for _, obj := range listInformers(informers) {
+		serviceList.Items = append(serviceList.Items, *obj.(*v1.Service))
+	}
+	return serviceList, nil
+}
+
+// endpointsList returns the endpoints from the informer cache, or from the apiserver if the
+// informers are not running. When the apiserver serves the EndpointSlices, they are read
+// instead and merged by service, as the Endpoints of large services are truncated.
+func (c *APIClient) endpointsList() (*v1.EndpointsList, error) {
+	if informers, found := c.getInformers(endpointSlicesInformer); found {
+		var slices []EndpointSlice
+		for _, obj := range listInformers(informers) {
+			slices = append(slices, *obj.(*EndpointSlice))
+		}
+		return endpointsFromSlices(slices), nil
+	}
+	informers, found := c.getInformers(endpointsInformer)
+	endpointsList := &v1.EndpointsList{}
+	if !found {
+		if apiPath := c.endpointSlicesAPI(); apiPath != "" {
+			slices, err := c.endpointSliceList(apiPath)
+			if err == nil {
+				return endpointsFromSlices(slices), nil
+			}
+			log.Debugf("Could not list the EndpointSlices, listing the Endpoints: %s", err)
+		}
+		for _, namespace := range c.WatchedNamespaces() {
+			endpoints, err := c.Client.Endpoints(namespace).List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+			if err != nil {
+				return nil, err
+			}
+			endpointsList.Items = append(endpointsList.Items, endpoints.Items...)
+		}
+		return endpointsList, nil
+	}
+	for _, obj := range listInformers(informers) {
+		endpointsList.Items = append(endpointsList.Items, *obj.(*v1.Endpoints))
+	}
+	return endpointsList, nil
+}
+
+// nodeList returns the nodes from the informer cache, or from the apiserver if the
+// informers are not running. If the access is restricted to namespaces, the nodes
+// cannot be listed: only the names of the nodes running the pods are returned.
+func (c *APIClient) nodeList() (*v1.NodeList, error) {
+	if c.IsNamespaceScoped() {
+		podList, err := c.podList()
+		if err != nil {
+			return nil, err
+		}
+		return nodesOfPods(podList), nil
+	}
+	informers, found := c.getInformers(nodesInformer)
+	if !found {
+		return c.Client.Nodes().List(metav1.ListOptions{TimeoutSeconds: &globalTimeoutSeconds})
+	}
+	nodeList := &v1.NodeList{}
+	for _, obj := range listInformers(informers) {
+		nodeList.Items = append(nodeList.Items, *obj.(*v1.Node))
+	}
+	return nodeList, nil
+}
+
+// nodesOfPods returns the nodes the pods are scheduled on, only their names are set.
+func nodesOfPods(podList *v1.PodList) *v1.NodeList {
+	nodeList := &v1.NodeList{}
+	seen := make(map[string]bool)
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" || seen[pod.Spec.NodeName] {
+			continue
+		}
+		seen[pod.Spec.NodeName] = true
+		nodeList.Items = append(nodeList.Items, v1.Node{ObjectMeta: metav1.ObjectMeta{Name: pod.Spec.NodeName}})
+	}
+	return nodeList
+}
+
+// node returns a node from the informer cache, it falls back to the apiserver
+// if the informers are not running or the node is not in the cache yet. The
+// nodes are cluster-scoped, they can not be read when the access is restricted
+// to a list of namespaces.
+func (c *APIClient) node(nodeName string) (*v1.Node, error) {
+	if c.IsNamespaceScoped() {
+		log.Debugf("Not reading the node %s, the access is restricted to namespaces", nodeName)
+		return nil, fmt.Errorf("the nodes are not available when the access is restricted to namespaces")
+	}
+	if informers, found := c.getInformers(nodesInformer); found {
+		for _, informer := range informers {
+			// nodes are not namespaced, their key in the store is their name
+			obj, exists, err := informer.GetStore().GetByKey(nodeName)
+			if err == nil && exists {
+				return obj.(*v1.Node), nil
+			}
+		}
+	}
+	return c.Client.Nodes().Get(nodeName, metav1.GetOptions{})
+}
+
+// PodServices returns the names of the services selecting a pod, matched through the
+// endpoints or the EndpointSlices targeting it. They are read from the informer index,
+// or listed from the apiserver if the informers are not running.
+func (c *APIClient) PodServices(namespace, podName string) ([]string, error) {
+	podKey := fmt.Sprintf("%s/%s", namespace, podName)
+
+	if informers, found := c.getInformers(endpointSlicesInformer); found {
+		var services []string
+		for _, informer := range informers {
+			indexed, err := informer.GetIndexer().ByIndex(endpointsPodIndex, podKey)
+			if err != nil {
+				return nil, err
+			}
+			for _, obj := range indexed {
+				services = append(services, obj.(*EndpointSlice).Labels[serviceNameLabel])
+			}
+		}
+		// a service can have several slices targeting the pod
+		return uniqueSortedStrings(services), nil
+	}
+
+	var endpoints []interface{}
+	if informers, found := c.getInformers(endpointsInformer); found {
+		for _, informer := range informers {
+			indexed, err := informer.GetIndexer().ByIndex(endpointsPodIndex, podKey)
+			if err != nil {
+				return nil, err
+			}
+			endpoints = append(endpoints, indexed...)
+		}
+	} else {
+		endpointsList, err := c.endpointsList()
+		if err != nil {
+			return nil, err
+		}
+		for i := range endpointsList.Items {
+			pods, _ := endpointsPodIndexFunc(&endpointsList.Items[i])
+			for _, pod := range pods {
+				if pod == podKey {
+					endpoints = append(endpoints, &endpointsList.Items[i])
+					break
+				}
+			}
+		}
+	}
+
+	services := make([]string, 0, len(endpoints))
+	for _, obj := range endpoints {
+		// endpoints objects have the name of their service
+		services = append(services, obj.(*v1.Endpoints).Name)
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// NodesPodServices maps the namespace/name of the pods of each node to the names of
+// the services selecting them, matched through the ready addresses of the endpoints.
+func (c *APIClient) NodesPodServices() (map[string]map[string][]string, error) {
+	endpointsList, err := c.endpointsList()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]map[string][]string)
+	for _, endpoints := range endpointsList.Items {
+		for _, subset := range endpoints.Subsets {
+			for _, address := range subset.Addresses {
+				if address.NodeName == nil || address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+					continue
+				}
+				pods, found := nodes[*address.NodeName]
+				if !found {
+					pods = make(map[string][]string)
+					nodes[*address.NodeName] = pods
+				}
+				podKey := fmt.Sprintf("%s/%s", address.TargetRef.Namespace, address.TargetRef.Name)
+				pods[podKey] = append(pods[podKey], endpoints.Name)
+			}
+		}
+	}
+
+	// a pod is listed in every subset of the ports it serves
+	for _, pods := range nodes {
+		for podKey, services := range pods {
+			pods[podKey] = uniqueSortedStrings(services)
+		}
+	}
+	return nodes, nil
+}
+
+func uniqueSortedStrings(values []string) []string {
+	sort.Strings(values)
+	unique := values[:0]
+	for _, value := range values {
+		if len(unique) == 0 || value != unique[len(unique)-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
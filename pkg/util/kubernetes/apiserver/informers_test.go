@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// newFilledInformer returns an informer that is not running, with objects in its store
+func newFilledInformer(t *testing.T, objType runtime.Object, indexers cache.Indexers, objects ...interface{}) cache.SharedIndexInformer {
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, objType, 0, indexers)
+	for _, obj := range objects {
+		require.NoError(t, informer.GetStore().Add(obj))
+	}
+	return informer
+}
+
+func TestListsFromInformers(t *testing.T) {
+	c := &APIClient{
+		informers: map[string][]cache.SharedIndexInformer{
+			podsInformer: {newFilledInformer(t, &v1.Pod{}, cache.Indexers{},
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}},
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "kube-system"}},
+			)},
+			servicesInformer: {newFilledInformer(t, &v1.Service{}, cache.Indexers{},
+				&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "default"}},
+			)},
+			endpointsInformer: {newFilledInformer(t, &v1.Endpoints{}, cache.Indexers{endpointsPodIndex: endpointsPodIndexFunc},
+				&v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "default"}},
+			)},
+			nodesInformer: {newFilledInformer(t, &v1.Node{}, cache.Indexers{},
+				&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"zone": "a"}}},
+			)},
+		},
+	}
+
+	pods, err := c.podList()
+	require.NoError(t, err)
+	assert.Len(t, pods.Items, 2)
+
+	services, err := c.serviceList()
+	require.NoError(t, err)
+	require.Len(t, services.Items, 1)
+	assert.Equal(t, "svc1", services.Items[0].Name)
+
+	endpoints, err := c.endpointsList()
+	require.NoError(t, err)
+	require.Len(t, endpoints.Items, 1)
+	assert.Equal(t, "svc1", endpoints.Items[0].Name)
+
+	nodes, err := c.nodeList()
+	require.NoError(t, err)
+	require.Len(t, nodes.Items, 1)
+	assert.Equal(t, "node1", nodes.Items[0].Name)
+
+	labels, err := c.NodeLabels("node1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"zone": "a"}, labels)
+}
+
+func TestPodServices(t *testing.T) {
+	podRef := func(namespace, name string) v1.EndpointAddress {
+		return v1.EndpointAddress{TargetRef: &v1.ObjectReference{Kind: "Pod", Namespace: namespace, Name: name}}
+	}
+	c := &APIClient{
+		informers: map[string][]cache.SharedIndexInformer{
+			endpointsInformer: {newFilledInformer(t, &v1.Endpoints{}, cache.Indexers{endpointsPodIndex: endpointsPodIndexFunc},
+				&v1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Subsets:    []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{podRef("default", "web-1"), podRef("default", "web-2")}}},
+				},
+				&v1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "default"},
+					Subsets:    []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{podRef("default", "web-1")}}},
+				},
+				&v1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "staging"},
+					Subsets:    []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{podRef("staging", "web-1")}}},
+				},
+			)},
+		},
+	}
+
+	services, err := c.PodServices("default", "web-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend", "web"}, services)
+
+	services, err = c.PodServices("default", "web-2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, services)
+
+	services, err = c.PodServices("default", "unknown")
+	require.NoError(t, err)
+	assert.Len(t, services, 0)
+}
+
+func TestNodesPodServices(t *testing.T) {
+	podRef := func(nodeName, name string) v1.EndpointAddress {
+		return v1.EndpointAddress{NodeName: &nodeName, TargetRef: &v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: name}}
+	}
+	c := &APIClient{
+		informers: map[string][]cache.SharedIndexInformer{
+			endpointsInformer: {newFilledInformer(t, &v1.Endpoints{}, cache.Indexers{endpointsPodIndex: endpointsPodIndexFunc},
+				&v1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+					Subsets: []v1.EndpointSubset{
+						{Addresses: []v1.EndpointAddress{podRef("node1", "web-1"), podRef("node2", "web-2")}},
+						{Addresses: []v1.EndpointAddress{podRef("node1", "web-1")}},
+					},
+				},
+				&v1.Endpoints{
+					ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "default"},
+					Subsets: []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{
+						podRef("node1", "web-1"),
+						{IP: "10.0.0.1"}, // not a pod
+					}}},
+				},
+			)},
+		},
+	}
+
+	nodes, err := c.NodesPodServices()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string][]string{
+		"node1": {"default/web-1": {"frontend", "web"}},
+		"node2": {"default/web-2": {"web"}},
+	}, nodes)
+}
+
+func TestNamespaceScopedInformers(t *testing.T) {
+	c := &APIClient{
+		namespaces: []string{"default", "staging"},
+		informers: map[string][]cache.SharedIndexInformer{
+			podsInformer: {
+				newFilledInformer(t, &v1.Pod{}, cache.Indexers{},
+					&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}, Spec: v1.PodSpec{NodeName: "node1"}},
+					&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"}},
+				),
+				newFilledInformer(t, &v1.Pod{}, cache.Indexers{},
+					&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "staging"}, Spec: v1.PodSpec{NodeName: "node1"}},
+					&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "staging"}, Spec: v1.PodSpec{NodeName: "node2"}},
+				),
+			},
+		},
+	}
+
+	assert.True(t, c.IsNamespaceScoped())
+	assert.Equal(t, []string{"default", "staging"}, c.WatchedNamespaces())
+
+	pods, err := c.podList()
+	require.NoError(t, err)
+	assert.Len(t, pods.Items, 4)
+
+	// nodes cannot be listed, they are deduced from the pods
+	nodes, err := c.nodeList()
+	require.NoError(t, err)
+	require.Len(t, nodes.Items, 2)
+	assert.Equal(t, "node1", nodes.Items[0].Name)
+	assert.Equal(t, "node2", nodes.Items[1].Name)
+
+	// nodes cannot be read either, without querying the apiserver
+	_, err = c.NodeLabels("node1")
+	assert.Error(t, err)
+}
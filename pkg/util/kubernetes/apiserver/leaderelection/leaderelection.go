@@ -22,6 +22,7 @@ import (
 	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/retry"
 )
 
@@ -29,6 +30,7 @@ const (
 	defaultLeaderLeaseDuration = 60 * time.Second
 	defaultLeaseName           = "datadog-leader-election"
 	clientTimeout              = 2 * time.Second
+	healthCheckPeriod          = time.Second
 )
 
 var (
@@ -53,6 +55,11 @@ type LeaderEngine struct {
 
 	currentHolderIdentity string
 	currentHolderMutex    sync.RWMutex
+
+	// leaderIP is the IP of the pod of leaderIPHolder, the last resolved leader
+	leaderIP       string
+	leaderIPHolder string
+	leaderIPMutex  sync.Mutex
 }
 
 func newLeaderEngine() *LeaderEngine {
@@ -156,6 +163,7 @@ func (le *LeaderEngine) EnsureLeaderElectionRuns() error {
 		func() {
 			log.Infof("Starting Leader Election process for %q ...", le.HolderIdentity)
 			go le.leaderElector.Run()
+			go le.reportHealth()
 		},
 	)
 
@@ -181,6 +189,23 @@ func (le *LeaderEngine) EnsureLeaderElectionRuns() error {
 	}
 }
 
+// reportHealth reports the leader election as ready while a leader is known,
+// it runs as long as the leader elector.
+func (le *LeaderEngine) reportHealth() {
+	handle := health.RegisterReadiness("leader-election")
+	tick := time.NewTicker(healthCheckPeriod)
+	defer tick.Stop()
+	for range tick.C {
+		if le.CurrentLeaderName() == "" {
+			continue
+		}
+		select {
+		case <-handle.C:
+		default:
+		}
+	}
+}
+
 // CurrentLeaderName is the main interface that can be called to fetch the name of the current leader.
 func (le *LeaderEngine) CurrentLeaderName() string {
 	le.currentHolderMutex.RLock()
@@ -194,6 +219,31 @@ func (le *LeaderEngine) IsLeader() bool {
 	return le.CurrentLeaderName() == le.HolderIdentity
 }
 
+// GetLeaderIP returns the IP of the pod of the current leader, the holder identity
+// being its pod name. It is only queried from the apiserver when the leader changes.
+func (le *LeaderEngine) GetLeaderIP() (string, error) {
+	leaderName := le.CurrentLeaderName()
+	if leaderName == "" {
+		return "", fmt.Errorf("the leader is not elected yet")
+	}
+
+	le.leaderIPMutex.Lock()
+	defer le.leaderIPMutex.Unlock()
+	if le.leaderIPHolder == leaderName {
+		return le.leaderIP, nil
+	}
+	pod, err := le.coreClient.Pods(le.LeaderNamespace).Get(leaderName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if pod.Status.PodIP == "" {
+		return "", fmt.Errorf("the leader pod %s/%s has no IP", le.LeaderNamespace, leaderName)
+	}
+	le.leaderIP = pod.Status.PodIP
+	le.leaderIPHolder = leaderName
+	return le.leaderIP, nil
+}
+
 // GetLeaderDetails is used in for the Flare and for the Status commands.
 func GetLeaderDetails() (leaderDetails rl.LeaderElectionRecord, err error) {
 	var led rl.LeaderElectionRecord
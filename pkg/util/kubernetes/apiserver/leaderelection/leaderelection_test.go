@@ -6,10 +6,15 @@
 package leaderelection
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
 )
 
 type testSuite struct {
@@ -25,3 +30,38 @@ func TestSuite(t *testing.T) {
 	s := &testSuite{}
 	suite.Run(t, s)
 }
+
+func TestGetLeaderIP(t *testing.T) {
+	queries := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/default/pods/dca-1":
+			queries++
+			w.Write([]byte(`{"kind": "Pod", "apiVersion": "v1", "metadata": {"name": "dca-1"}, "status": {"podIP": "10.0.0.1"}}`))
+		case "/api/v1/namespaces/default/pods/dca-2":
+			w.Write([]byte(`{"kind": "Pod", "apiVersion": "v1", "metadata": {"name": "dca-2"}, "status": {}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	client, err := corev1.NewForConfig(&rest.Config{Host: ts.URL})
+	require.NoError(t, err)
+	le := &LeaderEngine{LeaderNamespace: "default", coreClient: client}
+
+	_, err = le.GetLeaderIP()
+	assert.Error(t, err)
+
+	le.currentHolderIdentity = "dca-1"
+	for i := 0; i < 2; i++ {
+		ip, err := le.GetLeaderIP()
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.1", ip)
+	}
+	// the IP is only queried when the leader changes
+	assert.Equal(t, 1, queries)
+
+	le.currentHolderIdentity = "dca-2"
+	_, err = le.GetLeaderIP()
+	assert.Error(t, err)
+}
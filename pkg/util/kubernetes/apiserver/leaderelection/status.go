@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package leaderelection
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/status/sections"
+)
+
+// leaderElectionStatus is the leader-election section of the status.
+type leaderElectionStatus struct {
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+	LeaderName   string `json:"leaderName,omitempty"`
+	AcquiredTime string `json:"acquiredTime,omitempty"`
+	RenewedTime  string `json:"renewedTime,omitempty"`
+	Transitions  int    `json:"transitions"`
+}
+
+type statusProvider struct{}
+
+func (statusProvider) Name() string { return "leader-election" }
+
+func (statusProvider) Status() (interface{}, error) {
+	if !config.Datadog.GetBool("leader_election") {
+		return leaderElectionStatus{Status: "Disabled"}, nil
+	}
+	details, err := GetLeaderDetails()
+	if err != nil {
+		return leaderElectionStatus{Status: "Failing", Error: err.Error()}, nil
+	}
+	return leaderElectionStatus{
+		Status:       "Running",
+		LeaderName:   details.HolderIdentity,
+		AcquiredTime: details.AcquireTime.Format(time.RFC1123),
+		RenewedTime:  details.RenewTime.Format(time.RFC1123),
+		Transitions:  details.LeaderTransitions,
+	}, nil
+}
+
+func init() {
+	sections.Register(statusProvider{})
+}
@@ -44,3 +44,21 @@ func GetPodMetadataNames(nodeName string, podName string) ([]string, error) {
 
 	return metaList, nil
 }
+
+// GetPodServices is used when the API endpoint of the DCA to get the services of a pod is hit.
+func GetPodServices(namespace, podName string) ([]string, error) {
+	client, err := GetAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.PodServices(namespace, podName)
+}
+
+// GetNodeLabels is used when the API endpoint of the DCA to get the labels of a node is hit.
+func GetNodeLabels(nodeName string) (map[string]string, error) {
+	client, err := GetAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.NodeLabels(nodeName)
+}
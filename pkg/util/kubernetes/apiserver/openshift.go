@@ -0,0 +1,185 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/cihub/seelog"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpenShiftAPILevel describes what level of the OpenShift APIs is served by the apiserver
+type OpenShiftAPILevel string
+
+const (
+	// OpenShiftAPIGroups are served by OpenShift 3.6 and later
+	OpenShiftAPIGroups OpenShiftAPILevel = "apiGroups"
+	// OpenShiftOAPI is the legacy endpoint of OpenShift
+	OpenShiftOAPI OpenShiftAPILevel = "oapi"
+	// NotOpenShift means the apiserver is not an OpenShift one
+	NotOpenShift OpenShiftAPILevel = ""
+)
+
+// The OpenShift types are mirrored as their API is not vendored, only the fields
+// we use are kept.
+
+// ClusterResourceQuota is a quota shared by a set of namespaces
+type ClusterResourceQuota struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ClusterResourceQuotaSpec   `json:"spec"`
+	Status            ClusterResourceQuotaStatus `json:"status"`
+}
+
+// ClusterResourceQuotaSpec holds the hard limits of the quota
+type ClusterResourceQuotaSpec struct {
+	Quota v1.ResourceQuotaSpec `json:"quota"`
+}
+
+// ClusterResourceQuotaStatus holds the usage of the quota, in total and per namespace
+type ClusterResourceQuotaStatus struct {
+	Total      v1.ResourceQuotaStatus           `json:"total"`
+	Namespaces []ResourceQuotaStatusByNamespace `json:"namespaces"`
+}
+
+// ResourceQuotaStatusByNamespace is the usage of the quota in a namespace
+type ResourceQuotaStatusByNamespace struct {
+	Namespace string                 `json:"namespace"`
+	Status    v1.ResourceQuotaStatus `json:"status"`
+}
+
+type clusterResourceQuotaList struct {
+	Items []ClusterResourceQuota `json:"items"`
+}
+
+// Route exposes a service on a host of the OpenShift routers
+type Route struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              RouteSpec   `json:"spec"`
+	Status            RouteStatus `json:"status"`
+}
+
+// RouteSpec holds the host and the target service of the route
+type RouteSpec struct {
+	Host string               `json:"host"`
+	To   RouteTargetReference `json:"to"`
+}
+
+// RouteTargetReference is the backend of the route
+type RouteTargetReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// RouteStatus holds the status of the route in the routers
+type RouteStatus struct {
+	Ingress []RouteIngress `json:"ingress"`
+}
+
+// RouteIngress is the status of the route in a router
+type RouteIngress struct {
+	Host       string                  `json:"host"`
+	RouterName string                  `json:"routerName"`
+	Conditions []RouteIngressCondition `json:"conditions"`
+}
+
+// RouteIngressCondition is a condition of the route in a router, e.g. Admitted
+type RouteIngressCondition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+type routeList struct {
+	Items []Route `json:"items"`
+}
+
+// DetectOpenShiftAPILevel looks for the OpenShift API groups, then for the legacy
+// /oapi endpoint, to know whether the apiserver is an OpenShift one. It returns
+// an error when the apiserver can not tell, the detection must then be retried.
+func (c *APIClient) DetectOpenShiftAPILevel() (OpenShiftAPILevel, error) {
+	err := c.Client.RESTClient().Get().AbsPath("/apis/quota.openshift.io").Do().Error()
+	if err == nil {
+		log.Debugf("Found the OpenShift API groups")
+		return OpenShiftAPIGroups, nil
+	}
+	if !errors.IsNotFound(err) {
+		return NotOpenShift, fmt.Errorf("could not look for the OpenShift API groups: %s", err)
+	}
+	log.Tracef("Cannot access the OpenShift API groups: %s", err)
+
+	err = c.Client.RESTClient().Get().AbsPath("/oapi").Do().Error()
+	if err == nil {
+		log.Debugf("Found the legacy OpenShift API")
+		return OpenShiftOAPI, nil
+	}
+	if !errors.IsNotFound(err) {
+		return NotOpenShift, fmt.Errorf("could not look for the legacy OpenShift API: %s", err)
+	}
+	log.Tracef("Cannot access the legacy OpenShift API: %s", err)
+	return NotOpenShift, nil
+}
+
+// ListOShiftClusterQuotas lists the ClusterResourceQuotas of an OpenShift cluster,
+// they are cluster-scoped.
+func (c *APIClient) ListOShiftClusterQuotas(apiLevel OpenShiftAPILevel) ([]ClusterResourceQuota, error) {
+	path, err := openShiftPath(apiLevel, "quota.openshift.io", "", "clusterresourcequotas")
+	if err != nil {
+		return nil, err
+	}
+	list := &clusterResourceQuotaList{}
+	if err = c.getOpenShiftList(path, list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ListOShiftRoutes lists the routes of the watched namespaces of an OpenShift cluster.
+func (c *APIClient) ListOShiftRoutes(apiLevel OpenShiftAPILevel) ([]Route, error) {
+	var routes []Route
+	for _, namespace := range c.WatchedNamespaces() {
+		path, err := openShiftPath(apiLevel, "route.openshift.io", namespace, "routes")
+		if err != nil {
+			return nil, err
+		}
+		list := &routeList{}
+		if err = c.getOpenShiftList(path, list); err != nil {
+			return nil, err
+		}
+		routes = append(routes, list.Items...)
+	}
+	return routes, nil
+}
+
+func (c *APIClient) getOpenShiftList(path string, list interface{}) error {
+	body, err := c.Client.RESTClient().Get().AbsPath(path).Timeout(c.timeout).DoRaw()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, list)
+}
+
+// openShiftPath returns the path of a resource of an OpenShift API group,
+// on all namespaces if namespace is empty.
+func openShiftPath(apiLevel OpenShiftAPILevel, group, namespace, resource string) (string, error) {
+	var prefix string
+	switch apiLevel {
+	case OpenShiftAPIGroups:
+		prefix = fmt.Sprintf("/apis/%s/v1", group)
+	case OpenShiftOAPI:
+		prefix = "/oapi/v1"
+	default:
+		return "", fmt.Errorf("the OpenShift APIs are not available")
+	}
+	if namespace == "" {
+		return fmt.Sprintf("%s/%s", prefix, resource), nil
+	}
+	return fmt.Sprintf("%s/namespaces/%s/%s", prefix, namespace, resource), nil
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+const testClusterQuotas = `{
+  "kind": "ClusterResourceQuotaList",
+  "items": [{
+    "metadata": {"name": "for-user"},
+    "spec": {"quota": {"hard": {"pods": "10", "limits.cpu": "2"}}},
+    "status": {
+      "total": {"hard": {"pods": "10", "limits.cpu": "2"}, "used": {"pods": "3", "limits.cpu": "500m"}},
+      "namespaces": [{"namespace": "app", "status": {"hard": {"pods": "10"}, "used": {"pods": "3"}}}]
+    }
+  }]
+}`
+
+func newOpenShiftTestClient(t *testing.T, paths map[string]string) (*APIClient, func()) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if paths == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, found := paths[r.URL.Path]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	client, err := corev1.NewForConfig(&rest.Config{Host: ts.URL})
+	require.NoError(t, err)
+	return &APIClient{Client: client}, ts.Close
+}
+
+func TestDetectOpenShiftAPILevel(t *testing.T) {
+	c, stop := newOpenShiftTestClient(t, map[string]string{"/apis/quota.openshift.io": "{}"})
+	level, err := c.DetectOpenShiftAPILevel()
+	assert.NoError(t, err)
+	assert.Equal(t, OpenShiftAPIGroups, level)
+	stop()
+
+	c, stop = newOpenShiftTestClient(t, map[string]string{"/oapi": "{}"})
+	level, err = c.DetectOpenShiftAPILevel()
+	assert.NoError(t, err)
+	assert.Equal(t, OpenShiftOAPI, level)
+	stop()
+
+	c, stop = newOpenShiftTestClient(t, map[string]string{})
+	level, err = c.DetectOpenShiftAPILevel()
+	assert.NoError(t, err)
+	assert.Equal(t, NotOpenShift, level)
+	stop()
+
+	// the detection fails when the apiserver is unavailable
+	c, stop = newOpenShiftTestClient(t, nil)
+	_, err = c.DetectOpenShiftAPILevel()
+	assert.Error(t, err)
+	stop()
+}
+
+func TestListOShiftClusterQuotas(t *testing.T) {
+	c, stop := newOpenShiftTestClient(t, map[string]string{
+		"/apis/quota.openshift.io/v1/clusterresourcequotas": testClusterQuotas,
+	})
+	defer stop()
+
+	quotas, err := c.ListOShiftClusterQuotas(OpenShiftAPIGroups)
+	require.NoError(t, err)
+	require.Len(t, quotas, 1)
+	assert.Equal(t, "for-user", quotas[0].Name)
+	used := quotas[0].Status.Total.Used["limits.cpu"]
+	assert.Equal(t, int64(500), used.MilliValue())
+	require.Len(t, quotas[0].Status.Namespaces, 1)
+	assert.Equal(t, "app", quotas[0].Status.Namespaces[0].Namespace)
+
+	// the legacy API is not served
+	_, err = c.ListOShiftClusterQuotas(OpenShiftOAPI)
+	assert.Error(t, err)
+	_, err = c.ListOShiftClusterQuotas(NotOpenShift)
+	assert.Error(t, err)
+}
+
+func TestOpenShiftPath(t *testing.T) {
+	path, err := openShiftPath(OpenShiftAPIGroups, "route.openshift.io", "default", "routes")
+	require.NoError(t, err)
+	assert.Equal(t, "/apis/route.openshift.io/v1/namespaces/default/routes", path)
+
+	path, err = openShiftPath(OpenShiftOAPI, "route.openshift.io", "", "routes")
+	require.NoError(t, err)
+	assert.Equal(t, "/oapi/v1/routes", path)
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/retry"
+)
+
+var (
+	remoteAPIClients     map[string]*APIClient
+	remoteAPIClientsLock sync.Mutex
+)
+
+// GetRemoteClusterNames returns the names of the clusters of `kubernetes_remote_clusters`.
+func GetRemoteClusterNames() ([]string, error) {
+	clients, err := getRemoteAPIClients()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetRemoteAPIClient returns the shared APIClient of a cluster of `kubernetes_remote_clusters`,
+// connected through the context of its kubeconfig.
+func GetRemoteAPIClient(name string) (*APIClient, error) {
+	clients, err := getRemoteAPIClients()
+	if err != nil {
+		return nil, err
+	}
+	c, found := clients[name]
+	if !found {
+		return nil, fmt.Errorf("unknown remote cluster %q, it must be configured in kubernetes_remote_clusters", name)
+	}
+	if err := c.initRetry.TriggerRetry(); err != nil {
+		log.Debugf("init error for the remote cluster %s: %s", name, err)
+		return nil, err
+	}
+	return c, nil
+}
+
+func getRemoteAPIClients() (map[string]*APIClient, error) {
+	remoteAPIClientsLock.Lock()
+	defer remoteAPIClientsLock.Unlock()
+	if remoteAPIClients != nil {
+		return remoteAPIClients, nil
+	}
+
+	var clusters []config.KubernetesRemoteCluster
+	if err := config.Datadog.UnmarshalKey("kubernetes_remote_clusters", &clusters); err != nil {
+		return nil, fmt.Errorf("could not parse kubernetes_remote_clusters: %s", err)
+	}
+	clients, err := newRemoteAPIClients(clusters)
+	if err != nil {
+		return nil, err
+	}
+	remoteAPIClients = clients
+	return remoteAPIClients, nil
+}
+
+// newRemoteAPIClients returns the APIClients of the remote clusters, they are
+// only connected when first requested.
+func newRemoteAPIClients(clusters []config.KubernetesRemoteCluster) (map[string]*APIClient, error) {
+	clients := make(map[string]*APIClient, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.Name == "" || cluster.KubeconfigPath == "" {
+			return nil, fmt.Errorf("the remote clusters must have a name and a kubeconfig_path")
+		}
+		if _, found := clients[cluster.Name]; found {
+			return nil, fmt.Errorf("the remote cluster %q is configured twice", cluster.Name)
+		}
+		c := &APIClient{
+			timeout:           clientTimeout(),
+			ClusterName:       cluster.Name,
+			kubeconfigPath:    cluster.KubeconfigPath,
+			kubeconfigContext: cluster.Context,
+			namespaces:        cluster.Namespaces,
+		}
+		c.initRetry.SetupRetrier(&retry.Config{
+			Name:          fmt.Sprintf("apiserver_%s", cluster.Name),
+			AttemptMethod: c.connect,
+			Strategy:      retry.RetryCount,
+			RetryCount:    10,
+			RetryDelay:    30 * time.Second,
+		})
+		clients[cluster.Name] = c
+	}
+	return clients, nil
+}
@@ -64,3 +64,18 @@ func (metaBundle *MetadataMapperBundle) ServicesForPod(podName string) ([]string
 	metaBundle.m.RUnlock()
 	return svc, found
 }
+
+// prunePods removes the pods which are not in alive from the mapping, and returns
+// the number of pods removed. This call is thread-safe.
+func (metaBundle *MetadataMapperBundle) prunePods(alive map[string]struct{}) int {
+	metaBundle.m.Lock()
+	defer metaBundle.m.Unlock()
+	pruned := 0
+	for name := range metaBundle.PodNameToService {
+		if _, found := alive[name]; !found {
+			delete(metaBundle.PodNameToService, name)
+			pruned++
+		}
+	}
+	return pruned
+}
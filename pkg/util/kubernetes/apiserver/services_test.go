@@ -15,6 +15,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
 )
 
 type podTest struct {
@@ -169,3 +171,27 @@ func TestMapServices(t *testing.T) {
 	defer allBundleMu.RUnlock()
 	assert.Equal(t, expectedAllPodNameToService, allCasesBundle.PodNameToService)
 }
+
+func TestPruneMetadataMapping(t *testing.T) {
+	cacheKey := cache.BuildAgentKey(metadataMapperCachePrefix, "node1")
+	metaBundle := newMetadataMapperBundle()
+	metaBundle.PodNameToService = map[string][]string{
+		"alive": {"svc1"},
+		"gone":  {"svc1", "svc2"},
+	}
+	cache.Cache.Set(cacheKey, metaBundle, metadataMapExpire)
+	defer cache.Cache.Delete(cacheKey)
+
+	podList := &v1.PodList{Items: []v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "alive"}, Spec: v1.PodSpec{NodeName: "node1"}},
+		// a pod of the same name on another node does not keep the mapping
+		{ObjectMeta: metav1.ObjectMeta{Name: "gone"}, Spec: v1.PodSpec{NodeName: "node2"}},
+	}}
+	assert.Equal(t, 1, PruneMetadataMapping(podList))
+
+	_, found := metaBundle.ServicesForPod("gone")
+	assert.False(t, found)
+	services, found := metaBundle.ServicesForPod("alive")
+	assert.True(t, found)
+	assert.Equal(t, []string{"svc1"}, services)
+}
@@ -0,0 +1,14 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !kubelet !kubeapiserver
+
+package hostinfo
+
+// GetNodeInfo returns nil, the Kubernetes info of the node requires the kubelet
+// and the apiserver support
+func GetNodeInfo() (*NodeInfo, error) {
+	return nil, nil
+}
@@ -0,0 +1,18 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package hostinfo
+
+// NodeInfo is the Kubernetes info of the node the agent runs on, sent in the
+// host metadata
+type NodeInfo struct {
+	Name                    string            `json:"name"`
+	KubeletVersion          string            `json:"kubelet_version,omitempty"`
+	ContainerRuntimeVersion string            `json:"container_runtime_version,omitempty"`
+	OSImage                 string            `json:"os_image,omitempty"`
+	KernelVersion           string            `json:"kernel_version,omitempty"`
+	Capacity                map[string]string `json:"capacity,omitempty"`
+	Labels                  map[string]string `json:"labels,omitempty"`
+}
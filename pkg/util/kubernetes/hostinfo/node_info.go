@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet,kubeapiserver
+
+package hostinfo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+// The kubelet endpoints the info of the node is read from
+const (
+	kubeletSpecPath           = "/spec/"
+	kubeletMetricsPath        = "/metrics"
+	kubeletCadvisorMetricPath = "/metrics/cadvisor"
+)
+
+var promLabel = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// machineInfo holds the fields of the cadvisor machine info served by the kubelet
+type machineInfo struct {
+	NumCores       int    `json:"num_cores"`
+	MemoryCapacity uint64 `json:"memory_capacity"`
+}
+
+// kubeletQuerier queries the kubelet, implemented by the KubeUtil
+type kubeletQuerier interface {
+	QueryKubelet(path string) ([]byte, int, error)
+}
+
+// GetNodeInfo returns the Kubernetes info of the node, or nil when the agent
+// does not run on Kubernetes. The versions and the capacity are read from the
+// kubelet, the labels from the Cluster Agent when `cluster_agent` is enabled,
+// from the apiserver otherwise.
+func GetNodeInfo() (*NodeInfo, error) {
+	if !config.IsKubernetes() {
+		return nil, nil
+	}
+	ku, err := kubelet.GetKubeUtil()
+	if err != nil {
+		return nil, err
+	}
+	nodeName, err := ku.GetHostname()
+	if err != nil {
+		return nil, err
+	}
+	info, err := nodeInfo(ku, nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeLabels, err := getNodeLabels(nodeName)
+	if err != nil {
+		log.Debugf("Could not get the labels of the node %s: %s", nodeName, err)
+	} else {
+		info.Labels = filterLabels(nodeLabels, config.Datadog.GetStringSlice("kubernetes_metadata_node_labels"))
+	}
+	return info, nil
+}
+
+// nodeInfo reads the versions and the capacity of the node from the kubelet
+func nodeInfo(ku kubeletQuerier, nodeName string) (*NodeInfo, error) {
+	info := &NodeInfo{Name: nodeName}
+
+	spec, err := queryKubelet(ku, kubeletSpecPath)
+	if err != nil {
+		return nil, err
+	}
+	machine := machineInfo{}
+	if err = json.Unmarshal(spec, &machine); err != nil {
+		return nil, fmt.Errorf("could not parse the machine info of the kubelet: %s", err)
+	}
+	info.Capacity = map[string]string{
+		"cpu":    strconv.Itoa(machine.NumCores),
+		"memory": strconv.FormatUint(machine.MemoryCapacity, 10),
+	}
+
+	if metrics, err := queryKubelet(ku, kubeletMetricsPath); err == nil {
+		info.KubeletVersion = findPromLabels(metrics, "kubernetes_build_info")["gitVersion"]
+	} else {
+		log.Debugf("Could not get the version of the kubelet: %s", err)
+	}
+	if metrics, err := queryKubelet(ku, kubeletCadvisorMetricPath); err == nil {
+		versions := findPromLabels(metrics, "cadvisor_version_info")
+		info.OSImage = versions["osVersion"]
+		info.KernelVersion = versions["kernelVersion"]
+		if dockerVersion := versions["dockerVersion"]; dockerVersion != "" && dockerVersion != "Unknown" {
+			info.ContainerRuntimeVersion = "docker://" + dockerVersion
+		}
+	} else {
+		log.Debugf("Could not get the versions of the node: %s", err)
+	}
+	return info, nil
+}
+
+func queryKubelet(ku kubeletQuerier, path string) ([]byte, error) {
+	body, code, err := ku.QueryKubelet(path)
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d on %s", code, path)
+	}
+	return body, nil
+}
+
+// findPromLabels returns the labels of the first sample of a metric in the
+// prometheus text format, the info metrics hold their values in labels
+func findPromLabels(body []byte, metric string) map[string]string {
+	prefix := []byte(metric + "{")
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.HasPrefix(line, prefix) {
+			continue
+		}
+		labels := map[string]string{}
+		for _, match := range promLabel.FindAllSubmatch(line, -1) {
+			labels[string(match[1])] = string(match[2])
+		}
+		return labels
+	}
+	return nil
+}
+
+// filterLabels keeps the labels in the `kubernetes_metadata_node_labels` list
+func filterLabels(labels map[string]string, include []string) map[string]string {
+	filtered := map[string]string{}
+	for _, name := range include {
+		if value, found := labels[name]; found {
+			filtered[name] = value
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build kubelet,kubeapiserver
+
+package hostinfo
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKubelet map[string]string
+
+func (k fakeKubelet) QueryKubelet(path string) ([]byte, int, error) {
+	body, found := k[path]
+	if !found {
+		return nil, http.StatusNotFound, nil
+	}
+	return []byte(body), http.StatusOK, nil
+}
+
+func TestNodeInfo(t *testing.T) {
+	ku := fakeKubelet{
+		kubeletSpecPath: `{"num_cores":2,"cpu_frequency_khz":2200000,"memory_capacity":7837306880}`,
+		kubeletMetricsPath: `# HELP kubernetes_build_info A metric with a constant '1' value labeled by major, minor, git version, git commit, git tree state, build date, Go version, and compiler from which Kubernetes was built, and platform on which it is running.
+# TYPE kubernetes_build_info gauge
+kubernetes_build_info{buildDate="2018-07-26T19:44:51Z",compiler="gc",gitCommit="f2bc1c6b0b3bb3c4ba8b3b5b7c8d21b6a3ab1a0d",gitTreeState="clean",gitVersion="v1.10.5-gke.3",goVersion="go1.9.3b4",major="1",minor="10+",platform="linux/amd64"} 1
+`,
+		kubeletCadvisorMetricPath: `# HELP cadvisor_version_info A metric with a constant '1' value labeled by kernel version, OS version, docker version, cadvisor version & cadvisor revision.
+# TYPE cadvisor_version_info gauge
+cadvisor_version_info{cadvisorRevision="",cadvisorVersion="",dockerVersion="17.03.2-ce",kernelVersion="4.14.22+",osVersion="Container-Optimized OS from Google"} 1
+`,
+	}
+
+	info, err := nodeInfo(ku, "gke-dummy-18-default-pool-6888842e-hcv0")
+	require.NoError(t, err)
+	assert.Equal(t, &NodeInfo{
+		Name:                    "gke-dummy-18-default-pool-6888842e-hcv0",
+		KubeletVersion:          "v1.10.5-gke.3",
+		ContainerRuntimeVersion: "docker://17.03.2-ce",
+		OSImage:                 "Container-Optimized OS from Google",
+		KernelVersion:           "4.14.22+",
+		Capacity: map[string]string{
+			"cpu":    "2",
+			"memory": "7837306880",
+		},
+	}, info)
+
+	// the versions are optional
+	delete(ku, kubeletMetricsPath)
+	ku[kubeletCadvisorMetricPath] = `cadvisor_version_info{dockerVersion="Unknown",kernelVersion="4.15.0",osVersion="Ubuntu 18.04"} 1`
+	info, err = nodeInfo(ku, "node")
+	require.NoError(t, err)
+	assert.Empty(t, info.KubeletVersion)
+	assert.Empty(t, info.ContainerRuntimeVersion)
+	assert.Equal(t, "4.15.0", info.KernelVersion)
+
+	// the capacity is not
+	delete(ku, kubeletSpecPath)
+	_, err = nodeInfo(ku, "node")
+	assert.Equal(t, fmt.Errorf("unexpected status code 404 on /spec/"), err)
+}
+
+func TestFilterLabels(t *testing.T) {
+	labels := map[string]string{
+		"beta.kubernetes.io/instance-type": "n1-standard-2",
+		"cloud.google.com/gke-nodepool":    "default-pool",
+		"kubernetes.io/hostname":           "gke-dummy-18-default-pool-6888842e-hcv0",
+	}
+	assert.Equal(t, map[string]string{
+		"beta.kubernetes.io/instance-type": "n1-standard-2",
+		"cloud.google.com/gke-nodepool":    "default-pool",
+	}, filterLabels(labels, []string{"beta.kubernetes.io/instance-type", "cloud.google.com/gke-nodepool", "kubernetes.io/role"}))
+	assert.Empty(t, filterLabels(labels, nil))
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 )
@@ -34,15 +35,29 @@ func GetTags() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	client, err := apiserver.GetAPIClient()
+	nodeLabels, err := getNodeLabels(nodeName)
 	if err != nil {
 		return nil, err
 	}
-	nodeLabels, err := client.NodeLabels(nodeName)
+	return extractTags(nodeLabels, labelsToTags), nil
+}
+
+// getNodeLabels queries the cluster agent if enabled, as it allows node agents
+// to run without access to the apiserver, or the apiserver directly
+func getNodeLabels(nodeName string) (map[string]string, error) {
+	if config.Datadog.GetBool("cluster_agent") {
+		dcaClient, err := clusteragent.GetClusterAgentClient()
+		if err != nil {
+			return nil, err
+		}
+		return dcaClient.GetNodeLabels(nodeName)
+	}
+
+	client, err := apiserver.GetAPIClient()
 	if err != nil {
 		return nil, err
 	}
-	return extractTags(nodeLabels, labelsToTags), nil
+	return client.NodeLabels(nodeName)
 }
 
 func extractTags(nodeLabels, labelsToTags map[string]string) []string {
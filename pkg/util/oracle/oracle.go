@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package oracle queries the metadata endpoint of the Oracle Cloud
+// Infrastructure instances.
+package oracle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// declare these as vars not const to ease testing
+var (
+	metadataURL = "http://169.254.169.254"
+	timeout     = 300 * time.Millisecond
+)
+
+type instanceMetadata struct {
+	ID                  string            `json:"id"`
+	CanonicalRegionName string            `json:"canonicalRegionName"`
+	AvailabilityDomain  string            `json:"availabilityDomain"`
+	Shape               string            `json:"shape"`
+	FreeformTags        map[string]string `json:"freeformTags"`
+}
+
+// GetHostAlias returns the OCID of the instance from the Oracle Cloud metadata api
+func GetHostAlias() (string, error) {
+	id, err := getResponse(metadataURL + "/opc/v2/instance/id")
+	if err != nil {
+		return "", fmt.Errorf("Oracle HostAliases: unable to query metadata endpoint: %s", err)
+	}
+	return strings.TrimSpace(string(id)), nil
+}
+
+// GetTags returns the region, the availability domain and the shape of the
+// instance from the Oracle Cloud metadata api, and its freeform tags when
+// withFreeformTags is set
+func GetTags(withFreeformTags bool) ([]string, error) {
+	body, err := getResponse(metadataURL + "/opc/v2/instance/")
+	if err != nil {
+		return nil, fmt.Errorf("Oracle Tags: unable to query metadata endpoint: %s", err)
+	}
+
+	metadata := instanceMetadata{}
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("Oracle Tags: unable to parse the metadata of the instance: %s", err)
+	}
+
+	tags := []string{}
+	if metadata.CanonicalRegionName != "" {
+		tags = append(tags, fmt.Sprintf("region:%s", metadata.CanonicalRegionName))
+	}
+	if metadata.AvailabilityDomain != "" {
+		tags = append(tags, fmt.Sprintf("availability-domain:%s", metadata.AvailabilityDomain))
+	}
+	if metadata.Shape != "" {
+		tags = append(tags, fmt.Sprintf("instance-type:%s", metadata.Shape))
+	}
+	if !withFreeformTags {
+		return tags, nil
+	}
+	freeformTags := []string{}
+	for k, v := range metadata.FreeformTags {
+		freeformTags = append(freeformTags, fmt.Sprintf("%s:%s", k, v))
+	}
+	sort.Strings(freeformTags)
+	return append(tags, freeformTags...), nil
+}
+
+func getResponse(url string) ([]byte, error) {
+	client := http.Client{
+		Timeout: timeout,
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// the v2 endpoints require this header
+	req.Header.Add("Authorization", "Bearer Oracle")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("status code %d trying to GET %s", res.StatusCode, url)
+	}
+
+	all, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading response from oracle metadata endpoint: %s", err)
+	}
+	return all, nil
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package oracle
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHostAlias(t *testing.T) {
+	var lastRequest *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ocid1.instance.oc1.phx.abyhqljt")
+		lastRequest = r
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	val, err := GetHostAlias()
+	assert.Nil(t, err)
+	assert.Equal(t, "ocid1.instance.oc1.phx.abyhqljt", val)
+	assert.Equal(t, "/opc/v2/instance/id", lastRequest.URL.Path)
+	assert.Equal(t, "Bearer Oracle", lastRequest.Header.Get("Authorization"))
+}
+
+func TestGetTags(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{
+			"id": "ocid1.instance.oc1.phx.abyhqljt",
+			"canonicalRegionName": "us-phoenix-1",
+			"availabilityDomain": "EMIr:PHX-AD-1",
+			"shape": "VM.Standard2.1",
+			"freeformTags": {"team": "payments", "env": "prod"}
+		}`)
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+
+	tags, err := GetTags(true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"region:us-phoenix-1",
+		"availability-domain:EMIr:PHX-AD-1",
+		"instance-type:VM.Standard2.1",
+		"env:prod",
+		"team:payments",
+	}, tags)
+
+	// the freeform tags are opt-in
+	tags, err = GetTags(false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"region:us-phoenix-1",
+		"availability-domain:EMIr:PHX-AD-1",
+		"instance-type:VM.Standard2.1",
+	}, tags)
+}
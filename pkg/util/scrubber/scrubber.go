@@ -0,0 +1,194 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package scrubber removes the credentials from the files added to the flares
+// and from the logs of the agent, so they can be shared with the support.
+package scrubber
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+)
+
+// Replacer replaces the matches of Regex by Repl, or by the result of
+// ReplFunc when it is set. Repl can refer to the submatches of Regex.
+// Filter, when set, is a cheap check of the lines Regex can match, the other
+// lines are not matched against Regex.
+type Replacer struct {
+	Regex    *regexp.Regexp
+	Filter   func(b []byte) bool
+	Repl     []byte
+	ReplFunc func(b []byte) []byte
+}
+
+var commentRegex = regexp.MustCompile(`^\s*#.*$`)
+var blankRegex = regexp.MustCompile(`^\s*$`)
+
+var (
+	// defaultReplacers remove the API and APP keys, the passwords of the URIs,
+	// the tokens, the passwords and the SNMP credentials
+	defaultReplacers []Replacer
+	// customReplacers are applied after the default ones, they are the rules
+	// of the configuration
+	customReplacers []Replacer
+	replacersMutex  sync.RWMutex
+)
+
+func init() {
+	apiKeyReplacer := Replacer{
+		Regex:  regexp.MustCompile(`\b[a-fA-F0-9]{27}([a-fA-F0-9]{5})\b`),
+		Filter: hasHexRun(32),
+		Repl:   []byte(`***************************$1`),
+	}
+	appKeyReplacer := Replacer{
+		Regex:  regexp.MustCompile(`\b[a-fA-F0-9]{35}([a-fA-F0-9]{5})\b`),
+		Filter: hasHexRun(40),
+		Repl:   []byte(`***********************************$1`),
+	}
+	uriPasswordReplacer := Replacer{
+		Regex:  regexp.MustCompile(`\:\/\/([A-Za-z0-9_]+)\:(.+)\@`),
+		Filter: containsAny("://"),
+		Repl:   []byte(`://$1:********@`),
+	}
+	passwordReplacer := Replacer{
+		Regex:  matchYAMLKeyPart(`pass(word)?`),
+		Filter: containsAny("pass"),
+		Repl:   []byte(`$1 ********`),
+	}
+	// only the options named like a token are scrubbed, at the start of a
+	// line: the options counting tokens and the messages about them are kept
+	tokenReplacer := Replacer{
+		Regex:  regexp.MustCompile(`(?m)^(\s*(?:-\s+)?\w*token\s*:).+`),
+		Filter: containsAny("token"),
+		Repl:   []byte(`$1 ********`),
+	}
+	bearerReplacer := Replacer{
+		Regex:  regexp.MustCompile(`\b(Bearer)\s+[^\s"']+`),
+		Filter: containsAny("Bearer"),
+		Repl:   []byte(`$1 ********`),
+	}
+	snmpReplacer := Replacer{
+		Regex:  matchYAMLKey(`(community_string|authKey|privKey|auth_key|priv_key)`),
+		Filter: containsAny("community_string", "authKey", "privKey", "auth_key", "priv_key"),
+		Repl:   []byte(`$1 ********`),
+	}
+	defaultReplacers = []Replacer{apiKeyReplacer, appKeyReplacer, uriPasswordReplacer, passwordReplacer, tokenReplacer, bearerReplacer, snmpReplacer}
+}
+
+func matchYAMLKeyPart(part string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(\s*(\w|_)*%s(\w|_)*\s*:).+`, part))
+}
+
+func matchYAMLKey(key string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(\s*%s\s*:).+`, key))
+}
+
+// containsAny returns a filter of the lines containing one of the substrings
+func containsAny(substrings ...string) func(b []byte) bool {
+	patterns := make([][]byte, 0, len(substrings))
+	for _, s := range substrings {
+		patterns = append(patterns, []byte(s))
+	}
+	return func(b []byte) bool {
+		for _, pattern := range patterns {
+			if bytes.Contains(b, pattern) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// hasHexRun returns a filter of the lines with at least n consecutive
+// hexadecimal characters, the length of the keys
+func hasHexRun(n int) func(b []byte) bool {
+	return func(b []byte) bool {
+		run := 0
+		for _, c := range b {
+			if ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F') {
+				if run++; run >= n {
+					return true
+				}
+			} else {
+				run = 0
+			}
+		}
+		return false
+	}
+}
+
+// SetCustomReplacers sets the replacers applied after the default ones, they
+// replace the ones set before.
+func SetCustomReplacers(replacers []Replacer) {
+	replacersMutex.Lock()
+	defer replacersMutex.Unlock()
+	customReplacers = replacers
+}
+
+// ScrubFile returns the content of a file without the credentials, its
+// comments and its blank lines are removed.
+func ScrubFile(filePath string) ([]byte, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return scrub(file)
+}
+
+// ScrubBytes returns data without the credentials, its comments and its blank
+// lines are removed.
+func ScrubBytes(data []byte) ([]byte, error) {
+	return scrub(bytes.NewReader(data))
+}
+
+// ScrubLine returns a message without the credentials, it is used for the
+// logs: the comments and the blank lines are kept.
+func ScrubLine(message string) string {
+	return string(replace([]byte(message)))
+}
+
+func scrub(file io.Reader) ([]byte, error) {
+	var finalFile string
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		b := scanner.Bytes()
+		if !commentRegex.Match(b) && !blankRegex.Match(b) && string(b) != "" {
+			finalFile += string(replace(b)) + "\n"
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return []byte(finalFile), nil
+}
+
+// replace applies the default replacers then the custom ones.
+func replace(b []byte) []byte {
+	replacersMutex.RLock()
+	defer replacersMutex.RUnlock()
+	for _, replacers := range [][]Replacer{defaultReplacers, customReplacers} {
+		for _, repl := range replacers {
+			if repl.Filter != nil && !repl.Filter(b) {
+				continue
+			}
+			if repl.ReplFunc != nil {
+				b = repl.Regex.ReplaceAllFunc(b, repl.ReplFunc)
+			} else {
+				b = repl.Regex.ReplaceAll(b, repl.Repl)
+			}
+		}
+	}
+	return b
+}
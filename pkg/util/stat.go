@@ -32,10 +32,15 @@ type Stats struct {
 
 // NewStats constructor for Stats
 func NewStats(sz uint32) (*Stats, error) {
+	// the expvar is shared by the stats created by the successive servers
+	valExpvar, ok := expvar.Get("pktsec").(*expvar.Int)
+	if !ok {
+		valExpvar = expvar.NewInt("pktsec")
+	}
 	s := &Stats{
 		size:       sz,
 		running:    0,
-		valExpvar:  expvar.NewInt("pktsec"),
+		valExpvar:  valExpvar,
 		last:       time.Now(),
 		incoming:   make(chan int64, sz),
 		Aggregated: make(chan Stat, 3),
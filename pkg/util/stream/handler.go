@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package stream
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+// streamHeader starts the chunked response of a stream
+const streamHeader = "HTTP/1.1 200 OK\r\nContent-Type: text/plain; charset=utf-8\r\nTransfer-Encoding: chunked\r\nConnection: close\r\n\r\n"
+
+// Handler returns an HTTP handler streaming the lines of b, one per line,
+// until the client disconnects. The filter is built from the parameters of the
+// query named filterKeys.
+//
+// The connection is hijacked so the stream is not interrupted by the write
+// timeout of the server.
+func Handler(b *Broadcaster, filterKeys ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter := Filter{}
+		for _, key := range filterKeys {
+			if value := r.URL.Query().Get(key); value != "" {
+				filter[key] = value
+			}
+		}
+
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "the server does not support streaming", http.StatusInternalServerError)
+			return
+		}
+		conn, rw, err := hijacker.Hijack()
+		if err != nil {
+			log.Errorf("Could not start the stream of %s: %v", r.URL.Path, err)
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Time{})
+
+		// the client does not send anything once the request is sent, the
+		// read only returns when it disconnects
+		disconnected := make(chan struct{})
+		go func() {
+			io.Copy(ioutil.Discard, conn)
+			close(disconnected)
+		}()
+
+		s := b.Subscribe(filter)
+		defer b.Unsubscribe(s)
+		log.Infof("Streaming %s to a client, filtered by %v", r.URL.Path, filter)
+
+		if _, err := rw.WriteString(streamHeader); err != nil {
+			return
+		}
+		chunked := httputil.NewChunkedWriter(rw)
+		for {
+			if err := rw.Flush(); err != nil {
+				return
+			}
+			select {
+			case line := <-s.C:
+				if dropped := s.Dropped(); dropped > 0 {
+					io.WriteString(chunked, droppedLine(dropped)+"\n")
+				}
+				if _, err := io.WriteString(chunked, line+"\n"); err != nil {
+					return
+				}
+			case <-disconnected:
+				log.Infof("The client of the stream of %s disconnected", r.URL.Path)
+				return
+			}
+		}
+	}
+}
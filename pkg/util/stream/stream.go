@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// Package stream broadcasts copies of the data flowing through the pipelines
+// of the agent to the clients of the debug stream commands.
+package stream
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// subscriptionSize is the number of lines buffered for a client, the lines
+// are dropped when it is full so a slow client never blocks a pipeline
+const subscriptionSize = 100
+
+// Filter selects the lines whose fields have the given values, an empty
+// Filter selects every line.
+type Filter map[string]string
+
+// Match returns whether fields match the filter.
+func (f Filter) Match(fields map[string]string) bool {
+	for key, value := range f {
+		if fields[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Subscription receives the lines matching its filter on C.
+type Subscription struct {
+	C <-chan string
+
+	c       chan string
+	filter  Filter
+	dropped int64
+}
+
+// Dropped returns the number of lines dropped since its last call because
+// the subscription was full.
+func (s *Subscription) Dropped() int64 {
+	return atomic.SwapInt64(&s.dropped, 0)
+}
+
+// Broadcaster sends the lines of a pipeline to its subscriptions.
+type Broadcaster struct {
+	m             sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+	active        int32
+}
+
+// NewBroadcaster returns a Broadcaster without subscriptions.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Active returns whether a client is subscribed, the pipelines check it
+// before formatting their lines.
+func (b *Broadcaster) Active() bool {
+	return atomic.LoadInt32(&b.active) > 0
+}
+
+// Subscribe returns a subscription to the lines matching filter, it must be
+// closed with Unsubscribe.
+func (b *Broadcaster) Subscribe(filter Filter) *Subscription {
+	c := make(chan string, subscriptionSize)
+	s := &Subscription{C: c, c: c, filter: filter}
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.subscriptions[s] = struct{}{}
+	atomic.StoreInt32(&b.active, int32(len(b.subscriptions)))
+	return s
+}
+
+// Unsubscribe stops sending the lines to a subscription.
+func (b *Broadcaster) Unsubscribe(s *Subscription) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	delete(b.subscriptions, s)
+	atomic.StoreInt32(&b.active, int32(len(b.subscriptions)))
+}
+
+// Send sends a line to the subscriptions whose filter matches its fields,
+// it never blocks.
+func (b *Broadcaster) Send(fields map[string]string, line string) {
+	b.m.RLock()
+	defer b.m.RUnlock()
+	for s := range b.subscriptions {
+		if !s.filter.Match(fields) {
+			continue
+		}
+		select {
+		case s.c <- line:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+		}
+	}
+}
+
+// droppedLine is sent to the clients when lines were dropped
+func droppedLine(count int64) string {
+	return fmt.Sprintf("[%d lines dropped, the client does not read the stream fast enough]", count)
+}
@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+package stream
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterMatch(t *testing.T) {
+	fields := map[string]string{"source": "nginx", "service": "web"}
+	assert.True(t, Filter{}.Match(fields))
+	assert.True(t, Filter{"source": "nginx"}.Match(fields))
+	assert.True(t, Filter{"source": "nginx", "service": "web"}.Match(fields))
+	assert.False(t, Filter{"source": "redis"}.Match(fields))
+	assert.False(t, Filter{"name": "nginx"}.Match(fields))
+}
+
+func TestBroadcaster(t *testing.T) {
+	b := NewBroadcaster()
+	assert.False(t, b.Active())
+
+	nginx := b.Subscribe(Filter{"source": "nginx"})
+	all := b.Subscribe(Filter{})
+	assert.True(t, b.Active())
+
+	b.Send(map[string]string{"source": "nginx"}, "line 1")
+	b.Send(map[string]string{"source": "redis"}, "line 2")
+	assert.Equal(t, "line 1", <-nginx.C)
+	assert.Equal(t, "line 1", <-all.C)
+	assert.Equal(t, "line 2", <-all.C)
+	assert.Len(t, nginx.C, 0)
+
+	b.Unsubscribe(nginx)
+	b.Unsubscribe(all)
+	assert.False(t, b.Active())
+}
+
+func TestBroadcasterDrops(t *testing.T) {
+	b := NewBroadcaster()
+	s := b.Subscribe(Filter{})
+	for i := 0; i < subscriptionSize+10; i++ {
+		b.Send(nil, "line")
+	}
+	assert.Len(t, s.C, subscriptionSize)
+	assert.Equal(t, int64(10), s.Dropped())
+	assert.Equal(t, int64(0), s.Dropped())
+}
+
+func TestHandler(t *testing.T) {
+	b := NewBroadcaster()
+	server := httptest.NewServer(Handler(b, "source"))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?source=nginx&name=ignored")
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// wait for the subscription of the handler
+	for i := 0; i < 100 && !b.Active(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.True(t, b.Active())
+
+	b.Send(map[string]string{"source": "redis", "name": "redis"}, "redis line")
+	b.Send(map[string]string{"source": "nginx", "name": "nginx"}, "nginx line")
+	scanner := bufio.NewScanner(resp.Body)
+	require.True(t, scanner.Scan())
+	assert.Equal(t, "nginx line", scanner.Text())
+
+	// the handler unsubscribes once the client disconnects
+	resp.Body.Close()
+	for i := 0; i < 100 && b.Active(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.False(t, b.Active())
+}
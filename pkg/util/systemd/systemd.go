@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+// Package systemd notifies systemd of the state of a Type=notify service and
+// pings its watchdog. The notifications are dropped when the process isn't
+// started by systemd.
+package systemd
+
+import (
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/coreos/go-systemd/daemon"
+)
+
+// the notification states, see sd_notify(3)
+const (
+	stateReady    = "READY=1"
+	stateStopping = "STOPPING=1"
+	stateWatchdog = "WATCHDOG=1"
+)
+
+// for testing purpose
+var (
+	sdNotify          = daemon.SdNotify
+	sdWatchdogEnabled = daemon.SdWatchdogEnabled
+)
+
+// NotifyReady tells systemd that the service finished starting up, the units
+// ordered after it are started then
+func NotifyReady() {
+	notify(stateReady)
+}
+
+// NotifyStopping tells systemd that the service is shutting down
+func NotifyStopping() {
+	notify(stateStopping)
+}
+
+func notify(state string) {
+	if _, err := sdNotify(false, state); err != nil {
+		log.Warnf("Could not notify systemd of %s: %s", state, err)
+	}
+}
+
+// Watchdog pings the systemd watchdog while the process is live, systemd
+// restarts the service when it stops pinging for WatchdogSec
+type Watchdog struct {
+	interval time.Duration
+	isLive   func() bool
+	stop     chan struct{}
+}
+
+// NewWatchdog returns a Watchdog pinging twice per WatchdogSec, it is nil
+// when the watchdog of the service isn't enabled
+func NewWatchdog(isLive func() bool) (*Watchdog, error) {
+	timeout, err := sdWatchdogEnabled(false)
+	if err != nil || timeout == 0 {
+		return nil, err
+	}
+	return &Watchdog{
+		interval: timeout / 2,
+		isLive:   isLive,
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+// Start pings the watchdog in the background until Stop is called
+func (w *Watchdog) Start() {
+	log.Infof("Pinging the systemd watchdog every %s", w.interval)
+	go w.run()
+}
+
+// Stop stops pinging the watchdog
+func (w *Watchdog) Stop() {
+	close(w.stop)
+}
+
+func (w *Watchdog) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.ping()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// ping pings the watchdog if the process is live, a wedged process is then
+// restarted by systemd
+func (w *Watchdog) ping() {
+	if !w.isLive() {
+		log.Warnf("The agent is not live, not pinging the systemd watchdog")
+		return
+	}
+	notify(stateWatchdog)
+}
@@ -0,0 +1,28 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build !linux
+
+package systemd
+
+// NotifyReady is a NOP, systemd is only available on linux
+func NotifyReady() {}
+
+// NotifyStopping is a NOP, systemd is only available on linux
+func NotifyStopping() {}
+
+// Watchdog is not implemented, systemd is only available on linux
+type Watchdog struct{}
+
+// NewWatchdog always returns nil, systemd is only available on linux
+func NewWatchdog(isLive func() bool) (*Watchdog, error) {
+	return nil, nil
+}
+
+// Start is a NOP
+func (w *Watchdog) Start() {}
+
+// Stop is a NOP
+func (w *Watchdog) Stop() {}
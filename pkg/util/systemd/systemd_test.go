@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2018 Datadog, Inc.
+
+// +build linux
+
+package systemd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/daemon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockNotify() *[]string {
+	states := []string{}
+	sdNotify = func(unsetEnvironment bool, state string) (bool, error) {
+		states = append(states, state)
+		return true, nil
+	}
+	return &states
+}
+
+func mockWatchdogEnabled(timeout time.Duration) {
+	sdWatchdogEnabled = func(unsetEnvironment bool) (time.Duration, error) {
+		return timeout, nil
+	}
+}
+
+func resetMocks() {
+	sdNotify = daemon.SdNotify
+	sdWatchdogEnabled = daemon.SdWatchdogEnabled
+}
+
+func TestNotify(t *testing.T) {
+	defer resetMocks()
+	states := mockNotify()
+
+	NotifyReady()
+	NotifyStopping()
+	assert.Equal(t, []string{"READY=1", "STOPPING=1"}, *states)
+}
+
+func TestNewWatchdogDisabled(t *testing.T) {
+	defer resetMocks()
+	mockWatchdogEnabled(0)
+
+	w, err := NewWatchdog(func() bool { return true })
+	require.NoError(t, err)
+	assert.Nil(t, w)
+}
+
+func TestWatchdogPingsWhileLive(t *testing.T) {
+	defer resetMocks()
+	states := mockNotify()
+	mockWatchdogEnabled(time.Minute)
+
+	live := true
+	w, err := NewWatchdog(func() bool { return live })
+	require.NoError(t, err)
+	require.NotNil(t, w)
+	assert.Equal(t, 30*time.Second, w.interval)
+
+	w.ping()
+	assert.Equal(t, []string{"WATCHDOG=1"}, *states)
+
+	live = false
+	w.ping()
+	assert.Equal(t, []string{"WATCHDOG=1"}, *states)
+}
@@ -231,6 +231,12 @@ func (p *PdhCounterSet) GetSingleValue() (val float64, err error) {
 	return vals[singleInstanceKey], nil
 }
 
+// IsSingleInstance returns true when the counter set has a single counter,
+// the value returned by GetAllValues isn't keyed by an instance name then
+func (p *PdhCounterSet) IsSingleInstance() bool {
+	return p.singleCounter != PDH_HCOUNTER(0)
+}
+
 // Close closes the query handle, freeing the underlying windows resources.
 func (p *PdhCounterSet) Close() {
 	PdhCloseQuery(p.query)
@@ -20,6 +20,8 @@ from .go import deps
 
 # constants
 BIN_PATH = os.path.join(".", "bin", "agent")
+IOT_BIN_PATH = os.path.join(".", "bin", "iot")
+MAX_IOT_BINARY_SIZE = 30 * 1024
 AGENT_TAG = "datadog/agent:master"
 DEFAULT_BUILD_TAGS = [
     "apm",
@@ -33,9 +35,11 @@ DEFAULT_BUILD_TAGS = [
     "kubeapiserver",
     "kubelet",
     "log",
+    "otlp",
     "systemd",
     "process",
     "snmp",
+    "snmptraps",
     "zk",
     "zlib",
 ]
@@ -43,12 +47,15 @@ DEFAULT_BUILD_TAGS = [
 
 @task
 def build(ctx, rebuild=False, race=False, build_include=None, build_exclude=None,
-          puppy=False, use_embedded_libs=False, development=True, precompile_only=False,
+          puppy=False, iot=False, use_embedded_libs=False, development=True, precompile_only=False,
           skip_assets=False):
     """
     Build the agent. If the bits to include in the build are not specified,
     the values from `invoke.yaml` will be used.
 
+    The IoT agent has the components of the Puppy Agent, it is a static binary
+    built without cgo so it can be cross compiled, e.g. with GOARCH=arm.
+
     Example invokation:
         inv agent.build --build-exclude=snmp,systemd
     """
@@ -87,19 +94,26 @@ def build(ctx, rebuild=False, race=False, build_include=None, build_exclude=None
         command += "-i cmd/agent/agent.rc --target=pe-x86-64 -O coff -o cmd/agent/rsrc.syso"
         ctx.run(command, env=env)
 
-    if puppy:
+    if puppy or iot:
         # Puppy mode overrides whatever passed through `--build-exclude` and `--build-include`
         build_tags = get_default_build_tags(puppy=True)
     else:
         build_tags = get_build_tags(build_include, build_exclude)
 
+    bin_path = BIN_PATH
+    if iot:
+        bin_path = IOT_BIN_PATH
+        env["CGO_ENABLED"] = "0"
+        ldflags += "-s -w "
+        skip_assets = True
+
     cmd = "go build {race_opt} {build_type} -tags \"{go_build_tags}\" "
     cmd += "-o {agent_bin} -gcflags=\"{gcflags}\" -ldflags=\"{ldflags}\" {REPO_PATH}/cmd/agent"
     args = {
         "race_opt": "-race" if race else "",
         "build_type": "-a" if rebuild else ("-i" if precompile_only else ""),
         "go_build_tags": " ".join(build_tags),
-        "agent_bin": os.path.join(BIN_PATH, bin_name("agent")),
+        "agent_bin": os.path.join(bin_path, bin_name("agent")),
         "gcflags": gcflags,
         "ldflags": ldflags,
         "REPO_PATH": REPO_PATH,
@@ -158,6 +172,27 @@ def run(ctx, rebuild=False, race=False, build_include=None, build_exclude=None,
     ctx.run(os.path.join(BIN_PATH, bin_name("agent")))
 
 
+@task
+def iot_size_test(ctx, skip_build=False):
+    """
+    Run the size test for the IoT agent binary
+    """
+    if not skip_build:
+        print("Building the IoT agent...")
+        build(ctx, iot=True)
+
+    bin_path = os.path.join(IOT_BIN_PATH, bin_name("agent"))
+    stat_info = os.stat(bin_path)
+    size = stat_info.st_size / 1024
+
+    if size > MAX_IOT_BINARY_SIZE:
+        print("IoT agent build size too big: {} kB".format(size))
+        print("This means your PR added big dependencies to the packages built without build tags")
+        raise Exit(code=1)
+
+    print("IoT agent build size OK: {} kB".format(size))
+
+
 @task
 def system_tests(ctx):
     """
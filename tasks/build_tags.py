@@ -17,9 +17,11 @@ ALL_TAGS = set([
     "jmx",
     "kubelet",
     "log",
+    "otlp",
     "systemd",
     "process",
     "snmp",
+    "snmptraps",
     "zk",
     "zlib",
     "kubeapiserver",
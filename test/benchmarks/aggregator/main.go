@@ -104,6 +104,15 @@ func (f *forwarderBenchStub) SubmitHostMetadata(payload forwarder.Payloads, extr
 func (f *forwarderBenchStub) SubmitMetadata(payload forwarder.Payloads, extraHeaders http.Header) error {
 	return nil
 }
+func (f *forwarderBenchStub) SubmitOrchestratorPayload(payload forwarder.Payloads, extraHeaders http.Header) error {
+	return nil
+}
+func (f *forwarderBenchStub) SubmitRemoteConfigState(payload forwarder.Payloads, extraHeaders http.Header) error {
+	return nil
+}
+func (f *forwarderBenchStub) FetchRemoteConfig(version uint64) ([]byte, error) {
+	return nil, nil
+}
 
 type aggregatorStats struct {
 	Flush map[string]aggregator.Stats
@@ -112,6 +112,17 @@ func (f *forwarderBenchStub) SubmitMetadata(payloads forwarder.Payloads, extraHe
 	f.computeStats(payloads)
 	return nil
 }
+func (f *forwarderBenchStub) SubmitOrchestratorPayload(payloads forwarder.Payloads, extraHeaders http.Header) error {
+	f.computeStats(payloads)
+	return nil
+}
+func (f *forwarderBenchStub) SubmitRemoteConfigState(payloads forwarder.Payloads, extraHeaders http.Header) error {
+	f.computeStats(payloads)
+	return nil
+}
+func (f *forwarderBenchStub) FetchRemoteConfig(version uint64) ([]byte, error) {
+	return nil, nil
+}
 
 // NewStatsdGenerator returns a generator server
 // We could use datadog-go, but I want as little overhead as possible.